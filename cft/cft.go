@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 
 	"github.com/aws-cloudformation/rain/internal/node"
 	"github.com/aws-cloudformation/rain/internal/s11n"
@@ -125,7 +126,17 @@ func (t Template) GetSection(section Section) (*yaml.Node, error) {
 	return s, nil
 }
 
-// GetTypes returns all unique type names for resources in the template
+// ForEachPrefix starts the keys of Fn::ForEach loops from the AWS::LanguageExtensions transform,
+// which can take the place of resources, outputs, conditions and properties
+const ForEachPrefix = "Fn::ForEach::"
+
+// IsForEach returns true if key is the key of a Fn::ForEach loop
+func IsForEach(key string) bool {
+	return strings.HasPrefix(key, ForEachPrefix)
+}
+
+// GetTypes returns all unique type names for resources in the template.
+// Resources inside Fn::ForEach loops are not included.
 func (t Template) GetTypes() ([]string, error) {
 	resources, err := t.GetSection(Resources)
 	if err != nil {
@@ -136,6 +147,9 @@ func (t Template) GetTypes() ([]string, error) {
 	for i := 0; i < len(resources.Content); i += 2 {
 		logicalId := resources.Content[i].Value
 		resource := resources.Content[i+1]
+		if IsForEach(logicalId) {
+			continue
+		}
 		_, typ, _ := s11n.GetMapValue(resource, "Type")
 		if typ == nil {
 			return nil, fmt.Errorf("expected %s to have Type", logicalId)
@@ -0,0 +1,394 @@
+// Package cost estimates the monthly cost of the resources in a CloudFormation template.
+//
+// Resource types that have a fixed hourly or monthly price, like EC2 instances
+// and NAT gateways, are mapped from their properties to a Pricing API query.
+// Resources that are billed by usage, like Lambda functions and S3 buckets,
+// are listed but not priced, since their cost depends on traffic that
+// can't be known from the template.
+package cost
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// HoursPerMonth is the average number of hours in a month
+const HoursPerMonth = 730
+
+// Assumptions are the inputs to an estimate that don't come from the template
+type Assumptions struct {
+	// Region is the region the template will be deployed to
+	Region string `json:"region"`
+
+	// Hours is the number of hours a month that resources run for
+	Hours float64 `json:"hours"`
+}
+
+// Query identifies the price of a product in the Pricing API
+type Query struct {
+	// ServiceCode is the Pricing API service code, e.g. AmazonEC2
+	ServiceCode string
+
+	// Filters are the product attributes that must match
+	Filters map[string]string
+
+	// Unit is the unit the product is priced in, e.g. Hrs or GB-Mo
+	Unit string
+
+	// Quantity is the number of units used in a month
+	Quantity float64
+}
+
+// Pricer returns the USD price per unit of the product matched by q
+type Pricer func(q Query) (float64, error)
+
+// Estimate is the estimated monthly cost of a single resource
+type Estimate struct {
+	LogicalId string  `json:"logicalId"`
+	Type      string  `json:"type"`
+	Monthly   float64 `json:"monthly"`
+
+	// Priced is false if the resource's cost could not be estimated
+	Priced bool `json:"priced"`
+
+	// Note explains how the estimate was made, or why there isn't one
+	Note string `json:"note,omitempty"`
+}
+
+// Report is the estimated monthly cost of a template
+type Report struct {
+	Assumptions Assumptions `json:"assumptions"`
+	Resources   []Estimate  `json:"resources"`
+
+	// Total is the sum of the monthly cost of every priced resource, in USD
+	Total float64 `json:"total"`
+
+	// Unpriced is the number of resources that might cost money but were not priced
+	Unpriced int `json:"unpriced"`
+}
+
+// mapper turns a resource's properties into the queries that price it,
+// and a note that describes what the estimate covers
+type mapper func(r resource) ([]Query, string, error)
+
+// resource is a resource in the template, with the context needed to map it
+type resource struct {
+	t           cft.Template
+	props       *yaml.Node
+	assumptions Assumptions
+}
+
+// mappers are the resource types rain knows how to price
+var mappers = map[string]mapper{
+	"AWS::EC2::Instance":                        ec2Instance,
+	"AWS::EC2::NatGateway":                      natGateway,
+	"AWS::EC2::Volume":                          ebsVolume,
+	"AWS::RDS::DBInstance":                      rdsInstance,
+	"AWS::ElastiCache::CacheCluster":            cacheCluster,
+	"AWS::ElasticLoadBalancingV2::LoadBalancer": loadBalancer,
+}
+
+// usageBased are resource types that only cost money when they are used
+var usageBased = map[string]bool{
+	"AWS::ApiGateway::RestApi":                true,
+	"AWS::ApiGatewayV2::Api":                  true,
+	"AWS::DynamoDB::Table":                    true,
+	"AWS::Events::Rule":                       true,
+	"AWS::Lambda::Function":                   true,
+	"AWS::Logs::LogGroup":                     true,
+	"AWS::S3::Bucket":                         true,
+	"AWS::Serverless::Function":               true,
+	"AWS::SNS::Topic":                         true,
+	"AWS::SQS::Queue":                         true,
+	"AWS::StepFunctions::StateMachine":        true,
+	"AWS::CloudFront::Distribution":           true,
+	"AWS::KMS::Key":                           true,
+	"AWS::SecretsManager::Secret":             true,
+	"AWS::ECR::Repository":                    true,
+	"AWS::Kinesis::Stream":                    true,
+	"AWS::CloudWatch::Alarm":                  true,
+	"AWS::Route53::HostedZone":                true,
+	"AWS::ElasticLoadBalancing::LoadBalancer": true,
+}
+
+// Template estimates the monthly cost of every resource in the template
+func Template(t cft.Template, a Assumptions, price Pricer) (Report, error) {
+	report := Report{
+		Assumptions: a,
+		Resources:   make([]Estimate, 0),
+	}
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil {
+		return report, err
+	}
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		logicalId := resources.Content[i].Value
+		node := resources.Content[i+1]
+
+		_, typ, _ := s11n.GetMapValue(node, "Type")
+		if typ == nil {
+			continue
+		}
+
+		estimate := Estimate{
+			LogicalId: logicalId,
+			Type:      typ.Value,
+		}
+
+		m, ok := mappers[typ.Value]
+		if !ok {
+			if usageBased[typ.Value] {
+				estimate.Note = "billed by usage"
+				report.Unpriced++
+				report.Resources = append(report.Resources, estimate)
+			}
+			continue
+		}
+
+		_, props, _ := s11n.GetMapValue(node, "Properties")
+
+		queries, note, err := m(resource{t: t, props: props, assumptions: a})
+		if err != nil {
+			estimate.Note = err.Error()
+			report.Unpriced++
+			report.Resources = append(report.Resources, estimate)
+			continue
+		}
+
+		estimate.Priced = true
+		estimate.Note = note
+
+		for _, q := range queries {
+			p, err := price(q)
+			if err != nil {
+				estimate.Priced = false
+				estimate.Monthly = 0
+				estimate.Note = err.Error()
+				break
+			}
+			estimate.Monthly += p * q.Quantity
+		}
+
+		if estimate.Priced {
+			report.Total += estimate.Monthly
+		} else {
+			report.Unpriced++
+		}
+
+		report.Resources = append(report.Resources, estimate)
+	}
+
+	sort.SliceStable(report.Resources, func(i, j int) bool {
+		return report.Resources[i].Monthly > report.Resources[j].Monthly
+	})
+
+	return report, nil
+}
+
+// prop returns the literal value of a resource property, or def if it is not set.
+// A Ref to a parameter is resolved to the parameter's default value.
+func (r resource) prop(name string, def string) (string, error) {
+	_, n, _ := s11n.GetMapValue(r.props, name)
+	if n == nil {
+		if def == "" {
+			return "", fmt.Errorf("%s is not set", name)
+		}
+		return def, nil
+	}
+
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 && n.Content[0].Value == "Ref" {
+		param, err := r.t.GetParameter(n.Content[1].Value)
+		if err == nil {
+			_, d, _ := s11n.GetMapValue(param, "Default")
+			if d != nil && d.Kind == yaml.ScalarNode {
+				return d.Value, nil
+			}
+		}
+	}
+
+	if n.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("%s is not a literal value", name)
+	}
+
+	return n.Value, nil
+}
+
+// number returns the value of a numeric resource property, or def if it is not set
+func (r resource) number(name string, def float64) (float64, error) {
+	s, err := r.prop(name, strconv.FormatFloat(def, 'f', -1, 64))
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.ParseFloat(s, 64)
+}
+
+func (r resource) hourly(serviceCode string, filters map[string]string, count float64) Query {
+	filters["regionCode"] = r.assumptions.Region
+
+	return Query{
+		ServiceCode: serviceCode,
+		Filters:     filters,
+		Unit:        "Hrs",
+		Quantity:    r.assumptions.Hours * count,
+	}
+}
+
+func ec2Instance(r resource) ([]Query, string, error) {
+	instanceType, err := r.prop("InstanceType", "m1.small")
+	if err != nil {
+		return nil, "", err
+	}
+
+	return []Query{
+		r.hourly("AmazonEC2", map[string]string{
+			"instanceType":    instanceType,
+			"operatingSystem": "Linux",
+			"tenancy":         "Shared",
+			"preInstalledSw":  "NA",
+			"capacitystatus":  "Used",
+			"licenseModel":    "No License required",
+		}, 1),
+	}, fmt.Sprintf("%s, Linux, on-demand; storage not included", instanceType), nil
+}
+
+func natGateway(r resource) ([]Query, string, error) {
+	return []Query{
+		r.hourly("AmazonEC2", map[string]string{
+			"productFamily": "NAT Gateway",
+			"group":         "NGW:NatGateway",
+		}, 1),
+	}, "hourly charge; data processing not included", nil
+}
+
+func ebsVolume(r resource) ([]Query, string, error) {
+	volumeType, err := r.prop("VolumeType", "gp2")
+	if err != nil {
+		return nil, "", err
+	}
+
+	s, err := r.prop("Size", "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	size, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return []Query{
+		{
+			ServiceCode: "AmazonEC2",
+			Filters: map[string]string{
+				"productFamily": "Storage",
+				"volumeApiName": volumeType,
+				"regionCode":    r.assumptions.Region,
+			},
+			Unit:     "GB-Mo",
+			Quantity: size,
+		},
+	}, fmt.Sprintf("%v GB %s; IOPS and throughput not included", size, volumeType), nil
+}
+
+// rdsEngines maps RDS Engine values to the Pricing API's databaseEngine
+var rdsEngines = map[string]string{
+	"mysql":             "MySQL",
+	"mariadb":           "MariaDB",
+	"postgres":          "PostgreSQL",
+	"aurora-mysql":      "Aurora MySQL",
+	"aurora-postgresql": "Aurora PostgreSQL",
+}
+
+func rdsInstance(r resource) ([]Query, string, error) {
+	class, err := r.prop("DBInstanceClass", "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	engine, err := r.prop("Engine", "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	databaseEngine, ok := rdsEngines[engine]
+	if !ok {
+		return nil, "", fmt.Errorf("engine %s is not supported", engine)
+	}
+
+	multiAZ, err := r.prop("MultiAZ", "false")
+	if err != nil {
+		return nil, "", err
+	}
+
+	deployment := "Single-AZ"
+	if multiAZ == "true" {
+		deployment = "Multi-AZ"
+	}
+
+	return []Query{
+		r.hourly("AmazonRDS", map[string]string{
+			"instanceType":     class,
+			"databaseEngine":   databaseEngine,
+			"deploymentOption": deployment,
+		}, 1),
+	}, fmt.Sprintf("%s, %s, %s; storage not included", class, databaseEngine, deployment), nil
+}
+
+func cacheCluster(r resource) ([]Query, string, error) {
+	nodeType, err := r.prop("CacheNodeType", "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	engine, err := r.prop("Engine", "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	nodes, err := r.number("NumCacheNodes", 1)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cacheEngine := map[string]string{"redis": "Redis", "memcached": "Memcached", "valkey": "Valkey"}[engine]
+	if cacheEngine == "" {
+		return nil, "", fmt.Errorf("engine %s is not supported", engine)
+	}
+
+	return []Query{
+		r.hourly("AmazonElastiCache", map[string]string{
+			"instanceType": nodeType,
+			"cacheEngine":  cacheEngine,
+		}, nodes),
+	}, fmt.Sprintf("%v x %s, %s", nodes, nodeType, cacheEngine), nil
+}
+
+func loadBalancer(r resource) ([]Query, string, error) {
+	lbType, err := r.prop("Type", "application")
+	if err != nil {
+		return nil, "", err
+	}
+
+	family := map[string]string{
+		"application": "Load Balancer-Application",
+		"network":     "Load Balancer-Network",
+		"gateway":     "Load Balancer-Gateway",
+	}[lbType]
+	if family == "" {
+		return nil, "", fmt.Errorf("load balancer type %s is not supported", lbType)
+	}
+
+	return []Query{
+		r.hourly("AWSELB", map[string]string{
+			"productFamily": family,
+		}, 1),
+	}, fmt.Sprintf("%s load balancer hourly charge; capacity units not included", lbType), nil
+}
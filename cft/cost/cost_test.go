@@ -0,0 +1,105 @@
+package cost_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/cost"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+const template = `
+Parameters:
+  Size:
+    Type: String
+    Default: t3.micro
+Resources:
+  Instance:
+    Type: AWS::EC2::Instance
+    Properties:
+      InstanceType: !Ref Size
+  Nat:
+    Type: AWS::EC2::NatGateway
+  Volume:
+    Type: AWS::EC2::Volume
+    Properties:
+      Size: 100
+      VolumeType: gp3
+  Database:
+    Type: AWS::RDS::DBInstance
+    Properties:
+      DBInstanceClass: !GetAtt Something.Class
+      Engine: postgres
+  Function:
+    Type: AWS::Lambda::Function
+  Role:
+    Type: AWS::IAM::Role
+`
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := parse.String(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prices := map[string]float64{
+		"t3.micro":    0.01,
+		"NAT Gateway": 0.05,
+		"gp3":         0.1,
+	}
+
+	pricer := func(q cost.Query) (float64, error) {
+		if q.Filters["regionCode"] != "us-west-2" {
+			t.Errorf("unexpected region %s", q.Filters["regionCode"])
+		}
+
+		for _, v := range q.Filters {
+			if p, ok := prices[v]; ok {
+				return p, nil
+			}
+		}
+
+		return 0, fmt.Errorf("no price")
+	}
+
+	report, err := cost.Template(tmpl, cost.Assumptions{Region: "us-west-2", Hours: 100}, pricer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]float64{
+		"Volume":   10,
+		"Nat":      5,
+		"Instance": 1,
+		"Database": 0,
+		"Function": 0,
+	}
+
+	if len(report.Resources) != len(expected) {
+		t.Fatalf("expected %d resources, got %d: %v", len(expected), len(report.Resources), report.Resources)
+	}
+
+	for _, r := range report.Resources {
+		e, ok := expected[r.LogicalId]
+		if !ok {
+			t.Errorf("unexpected resource %s", r.LogicalId)
+			continue
+		}
+
+		if r.Monthly < e-0.001 || r.Monthly > e+0.001 {
+			t.Errorf("%s: expected %v, got %v", r.LogicalId, e, r.Monthly)
+		}
+	}
+
+	if report.Resources[0].LogicalId != "Volume" {
+		t.Errorf("expected the most expensive resource first, got %s", report.Resources[0].LogicalId)
+	}
+
+	if report.Total < 15.999 || report.Total > 16.001 {
+		t.Errorf("expected a total of 16, got %v", report.Total)
+	}
+
+	if report.Unpriced != 2 {
+		t.Errorf("expected 2 unpriced resources, got %d", report.Unpriced)
+	}
+}
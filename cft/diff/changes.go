@@ -0,0 +1,65 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// modeNames are the names that modes are written with in JSON
+var modeNames = map[Mode]string{
+	Added:     "added",
+	Removed:   "removed",
+	Changed:   "changed",
+	Involved:  "involved",
+	Unchanged: "unchanged",
+}
+
+// MarshalText writes the mode's name, e.g. "added", so that JSON output doesn't need the symbols
+func (m Mode) MarshalText() ([]byte, error) {
+	name, ok := modeNames[m]
+	if !ok {
+		return nil, fmt.Errorf("unknown diff mode '%s'", string(m))
+	}
+
+	return []byte(name), nil
+}
+
+// Change is a value that was added, removed or changed
+type Change struct {
+	// Path is the location of the value, e.g. Resources/Bucket/Properties/Tags/0
+	Path string `json:"path"`
+
+	Mode Mode `json:"mode"`
+
+	// Value is the new value, or the old one if it was removed
+	Value interface{} `json:"value"`
+}
+
+// Changes lists the values that differ, in the order of their paths.
+// Maps and lists that only contain changes are not listed themselves.
+func Changes(d Diff) []Change {
+	changes := make([]Change, 0)
+	collectChanges(d, nil, &changes)
+	return changes
+}
+
+func collectChanges(d Diff, path []string, changes *[]Change) {
+	switch v := d.(type) {
+	case slice:
+		for i, e := range v {
+			collectChanges(e, append(path, fmt.Sprint(i)), changes)
+		}
+	case dmap:
+		keys := v.keys()
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			collectChanges(v[k], append(path, k), changes)
+		}
+	case value:
+		if v.mode != Unchanged {
+			*changes = append(*changes, Change{strings.Join(path, "/"), v.mode, v.val})
+		}
+	}
+}
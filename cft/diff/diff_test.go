@@ -1,6 +1,7 @@
 package diff
 
 import (
+	"encoding/json"
 	"reflect"
 	"testing"
 )
@@ -174,3 +175,41 @@ func TestCompare(t *testing.T) {
 		},
 	})
 }
+
+func TestChanges(t *testing.T) {
+	d := CompareMaps(map[string]interface{}{
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{"Type": "AWS::S3::Bucket"},
+			"Queue":  map[string]interface{}{"Type": "AWS::SQS::Queue"},
+		},
+		"Outputs": map[string]interface{}{
+			"Arns": []interface{}{"a", "b"},
+		},
+	}, map[string]interface{}{
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{"Type": "AWS::S3::Bucket", "DeletionPolicy": "Retain"},
+		},
+		"Outputs": map[string]interface{}{
+			"Arns": []interface{}{"a", "c"},
+		},
+	})
+
+	expected := []Change{
+		{"Outputs/Arns/1", Changed, "c"},
+		{"Resources/Bucket/DeletionPolicy", Added, "Retain"},
+		{"Resources/Queue", Removed, map[string]interface{}{"Type": "AWS::SQS::Queue"}},
+	}
+
+	if actual := Changes(d); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+
+	out, err := json.Marshal(expected[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != `{"path":"Resources/Bucket/DeletionPolicy","mode":"added","value":"Retain"}` {
+		t.Errorf("unexpected JSON: %s", out)
+	}
+}
@@ -0,0 +1,227 @@
+// Package docs documents a CloudFormation template for the people who deploy it:
+// its parameters and their constraints, its outputs and exports, and the resources it creates.
+//
+// The documentation can be written as Markdown, to check into a repository next to the template,
+// or as an HTML page to publish to a wiki.
+package docs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// Parameter is a parameter of the template
+type Parameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	HasDefault  bool   `json:"hasDefault"`
+	NoEcho      bool   `json:"noEcho,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// Constraints describe the values the parameter allows, e.g. "Allowed values: dev, prod"
+	Constraints []string `json:"constraints,omitempty"`
+}
+
+// Output is an output of the template
+type Output struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Value is the output's value, with intrinsic functions in their short form
+	Value string `json:"value"`
+
+	// Export is the name the value is exported as, if it is exported
+	Export string `json:"export,omitempty"`
+
+	Condition string `json:"condition,omitempty"`
+}
+
+// Resource is a resource in the template
+type Resource struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// Doc is the documentation of a template
+type Doc struct {
+	Title       string      `json:"title"`
+	Description string      `json:"description,omitempty"`
+	Parameters  []Parameter `json:"parameters"`
+	Outputs     []Output    `json:"outputs"`
+	Resources   []Resource  `json:"resources"`
+}
+
+// constraints are the parameter properties that limit its values, with how they are described
+var constraints = []struct {
+	key   string
+	label string
+}{
+	{"AllowedValues", "Allowed values"},
+	{"AllowedPattern", "Pattern"},
+	{"MinLength", "Min length"},
+	{"MaxLength", "Max length"},
+	{"MinValue", "Min value"},
+	{"MaxValue", "Max value"},
+	{"ConstraintDescription", "Constraint"},
+}
+
+// New returns the documentation of a template
+func New(title string, t cft.Template) Doc {
+	d := Doc{
+		Title:      title,
+		Parameters: make([]Parameter, 0),
+		Outputs:    make([]Output, 0),
+		Resources:  make([]Resource, 0),
+	}
+
+	if _, desc, _ := s11n.GetMapValue(t.Node.Content[0], string(cft.Description)); desc != nil {
+		d.Description = strings.TrimSpace(desc.Value)
+	}
+
+	each(t, cft.Parameters, func(name string, n *yaml.Node) {
+		p := Parameter{Name: name, Constraints: make([]string, 0)}
+		p.Type = scalar(n, "Type")
+		p.Description = scalar(n, "Description")
+		p.NoEcho = strings.EqualFold(scalar(n, "NoEcho"), "true")
+
+		if _, def, _ := s11n.GetMapValue(n, "Default"); def != nil {
+			p.HasDefault = true
+			p.Default = scalar(n, "Default")
+		}
+
+		for _, c := range constraints {
+			if v := scalar(n, c.key); v != "" {
+				p.Constraints = append(p.Constraints, fmt.Sprintf("%s: %s", c.label, v))
+			}
+		}
+
+		d.Parameters = append(d.Parameters, p)
+	})
+
+	each(t, cft.Outputs, func(name string, n *yaml.Node) {
+		o := Output{Name: name}
+		o.Description = scalar(n, "Description")
+		o.Condition = scalar(n, "Condition")
+
+		if _, v, _ := s11n.GetMapValue(n, "Value"); v != nil {
+			o.Value = Expression(v)
+		}
+
+		if _, export, _ := s11n.GetMapValue(n, "Export"); export != nil {
+			if _, exportName, _ := s11n.GetMapValue(export, "Name"); exportName != nil {
+				o.Export = Expression(exportName)
+			}
+		}
+
+		d.Outputs = append(d.Outputs, o)
+	})
+
+	each(t, cft.Resources, func(name string, n *yaml.Node) {
+		r := Resource{Name: name}
+
+		if cft.IsForEach(name) {
+			r.Name = strings.TrimPrefix(name, cft.ForEachPrefix)
+			r.Type = "Fn::ForEach"
+		} else if _, typ, _ := s11n.GetMapValue(n, "Type"); typ != nil {
+			r.Type = typ.Value
+			// Rain modules have a mapping for a type
+			if typ.Kind == yaml.MappingNode && len(typ.Content) > 0 {
+				r.Type = typ.Content[0].Value
+			}
+		}
+
+		r.Condition = scalar(n, "Condition")
+
+		d.Resources = append(d.Resources, r)
+	})
+
+	return d
+}
+
+// Exports returns the outputs that are exported
+func (d Doc) Exports() []Output {
+	out := make([]Output, 0)
+	for _, o := range d.Outputs {
+		if o.Export != "" {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// each calls fn with the name and value of each entry in a section of the template, in order
+func each(t cft.Template, section cft.Section, fn func(name string, n *yaml.Node)) {
+	s, err := t.GetSection(section)
+	if err != nil || s.Kind != yaml.MappingNode {
+		return
+	}
+
+	for i := 0; i < len(s.Content)-1; i += 2 {
+		fn(s.Content[i].Value, s.Content[i+1])
+	}
+}
+
+// scalar returns the value of a key in a mapping, with lists joined by commas
+func scalar(n *yaml.Node, key string) string {
+	_, v, _ := s11n.GetMapValue(n, key)
+	if v == nil {
+		return ""
+	}
+
+	if v.Kind == yaml.SequenceNode {
+		values := make([]string, 0, len(v.Content))
+		for _, item := range v.Content {
+			values = append(values, Expression(item))
+		}
+		return strings.Join(values, ", ")
+	}
+
+	return Expression(v)
+}
+
+// Expression returns a value from the template as a single line,
+// with the intrinsic functions that are usually in outputs written in their short form
+func Expression(n *yaml.Node) string {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value
+	}
+
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 {
+		key, value := n.Content[0].Value, n.Content[1]
+
+		switch key {
+		case "Ref":
+			return "!Ref " + value.Value
+		case "Fn::GetAtt":
+			if value.Kind == yaml.SequenceNode {
+				parts := make([]string, 0, len(value.Content))
+				for _, part := range value.Content {
+					parts = append(parts, Expression(part))
+				}
+				return "!GetAtt " + strings.Join(parts, ".")
+			}
+			return "!GetAtt " + value.Value
+		case "Fn::Sub":
+			if value.Kind == yaml.ScalarNode {
+				return "!Sub " + value.Value
+			}
+		case "Fn::ImportValue":
+			return "!ImportValue " + Expression(value)
+		}
+	}
+
+	out, err := json.Marshal(format.Jsonise(n))
+	if err != nil {
+		return ""
+	}
+
+	return string(out)
+}
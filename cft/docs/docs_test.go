@@ -0,0 +1,124 @@
+package docs_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/docs"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+const source = `
+Description: Stores the app's data
+Parameters:
+  Env:
+    Type: String
+    Default: dev
+    AllowedValues: [dev, prod]
+    Description: The environment | stage
+  Password:
+    Type: String
+    NoEcho: true
+    Default: secret
+    MinLength: 8
+Conditions:
+  IsProd: !Equals [!Ref Env, prod]
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+  Backup:
+    Type: AWS::Backup::BackupVault
+    Condition: IsProd
+    Properties:
+      BackupVaultName: !Sub ${Env}-vault
+Outputs:
+  BucketArn:
+    Description: The bucket's ARN
+    Value: !GetAtt Bucket.Arn
+    Export:
+      Name: !Sub ${AWS::StackName}-BucketArn
+  BucketName:
+    Value: !Ref Bucket
+`
+
+func TestNew(t *testing.T) {
+	tmpl, err := parse.String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := docs.Doc{
+		Title:       "data",
+		Description: "Stores the app's data",
+		Parameters: []docs.Parameter{
+			{Name: "Env", Type: "String", Default: "dev", HasDefault: true, Description: "The environment | stage",
+				Constraints: []string{"Allowed values: dev, prod"}},
+			{Name: "Password", Type: "String", Default: "secret", HasDefault: true, NoEcho: true,
+				Constraints: []string{"Min length: 8"}},
+		},
+		Outputs: []docs.Output{
+			{Name: "BucketArn", Description: "The bucket's ARN", Value: "!GetAtt Bucket.Arn", Export: "!Sub ${AWS::StackName}-BucketArn"},
+			{Name: "BucketName", Value: "!Ref Bucket"},
+		},
+		Resources: []docs.Resource{
+			{Name: "Bucket", Type: "AWS::S3::Bucket"},
+			{Name: "Backup", Type: "AWS::Backup::BackupVault", Condition: "IsProd"},
+		},
+	}
+
+	if d := cmp.Diff(expected, docs.New("data", tmpl)); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	tmpl, err := parse.String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := docs.Markdown(docs.New("data", tmpl))
+
+	for _, expected := range []string{
+		"# data\n\nStores the app's data\n",
+		"| `Env` | String | `dev` | Allowed values: dev, prod | The environment \\| stage |",
+		"| `Password` | String | `(hidden)` | Min length: 8 |  |",
+		"## Exports\n\n| Export name | Output |\n| --- | --- |\n| `!Sub ${AWS::StackName}-BucketArn` | `BucketArn` |",
+		"| `Backup` | AWS::Backup::BackupVault | IsProd |",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected '%s' in:\n%s", expected, out)
+		}
+	}
+
+	if strings.Contains(out, "secret") {
+		t.Error("expected the NoEcho default to be hidden")
+	}
+}
+
+func TestHTML(t *testing.T) {
+	tmpl, err := parse.String("Resources:\n  Bucket:\n    Type: AWS::S3::Bucket\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := docs.HTML(docs.New("<data>", tmpl))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"<h1>&lt;data&gt;</h1>",
+		"<p>The template has no parameters.</p>",
+		"<tr><td><code>Bucket</code></td><td>AWS::S3::Bucket</td><td></td></tr>",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected '%s' in:\n%s", expected, out)
+		}
+	}
+
+	if strings.Contains(out, "Exports") {
+		t.Error("expected no Exports section without exports")
+	}
+}
@@ -0,0 +1,67 @@
+package docs
+
+import (
+	"html/template"
+	"strings"
+)
+
+var page = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"default": defaultValue,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 2em; }
+th, td { border: 1px solid #ccc; padding: 0.4em 0.8em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+code { white-space: pre-wrap; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Description}}<p>{{.Description}}</p>
+{{end}}
+<h2>Parameters</h2>
+{{if .Parameters}}<table>
+<tr><th>Name</th><th>Type</th><th>Default</th><th>Constraints</th><th>Description</th></tr>
+{{range .Parameters}}<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{with default .}}<code>{{.}}</code>{{end}}</td><td>{{range $i, $c := .Constraints}}{{if $i}}<br>{{end}}{{$c}}{{end}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{else}}<p>The template has no parameters.</p>
+{{end}}
+<h2>Outputs</h2>
+{{if .Outputs}}<table>
+<tr><th>Name</th><th>Value</th><th>Condition</th><th>Description</th></tr>
+{{range .Outputs}}<tr><td><code>{{.Name}}</code></td><td><code>{{.Value}}</code></td><td>{{.Condition}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{else}}<p>The template has no outputs.</p>
+{{end}}
+{{with .Exports}}<h2>Exports</h2>
+<table>
+<tr><th>Export name</th><th>Output</th></tr>
+{{range .}}<tr><td><code>{{.Export}}</code></td><td><code>{{.Name}}</code></td></tr>
+{{end}}</table>
+{{end}}
+<h2>Resources</h2>
+{{if .Resources}}<table>
+<tr><th>Logical ID</th><th>Type</th><th>Condition</th></tr>
+{{range .Resources}}<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{.Condition}}</td></tr>
+{{end}}</table>
+{{else}}<p>The template has no resources.</p>
+{{end}}
+</body>
+</html>
+`))
+
+// HTML returns the documentation as a standalone HTML page
+func HTML(d Doc) (string, error) {
+	out := &strings.Builder{}
+
+	if err := page.Execute(out, d); err != nil {
+		return "", err
+	}
+
+	return out.String(), nil
+}
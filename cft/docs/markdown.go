@@ -0,0 +1,108 @@
+package docs
+
+import (
+	"fmt"
+	"strings"
+)
+
+// cell escapes a value for a Markdown table cell
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(strings.TrimSpace(s), "\n", "<br>")
+}
+
+// code formats a value as inline code in a Markdown table cell, or returns an empty cell for no value
+func code(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + cell(s) + "`"
+}
+
+// table writes a Markdown table
+func table(out *strings.Builder, header []string, rows [][]string) {
+	out.WriteString("| " + strings.Join(header, " | ") + " |\n")
+
+	separators := make([]string, len(header))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	out.WriteString("| " + strings.Join(separators, " | ") + " |\n")
+
+	for _, row := range rows {
+		out.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+}
+
+// defaultValue returns how the parameter's default value is shown
+func defaultValue(p Parameter) string {
+	switch {
+	case !p.HasDefault:
+		return ""
+	case p.NoEcho:
+		return "(hidden)"
+	default:
+		return p.Default
+	}
+}
+
+// Markdown returns the documentation as Markdown, with a table for each section
+func Markdown(d Doc) string {
+	out := &strings.Builder{}
+
+	fmt.Fprintf(out, "# %s\n\n", d.Title)
+
+	if d.Description != "" {
+		fmt.Fprintf(out, "%s\n\n", d.Description)
+	}
+
+	out.WriteString("## Parameters\n\n")
+	if len(d.Parameters) == 0 {
+		out.WriteString("The template has no parameters.\n\n")
+	} else {
+		rows := make([][]string, 0)
+		for _, p := range d.Parameters {
+			rows = append(rows, []string{
+				code(p.Name), cell(p.Type), code(defaultValue(p)),
+				cell(strings.Join(p.Constraints, "\n")), cell(p.Description),
+			})
+		}
+		table(out, []string{"Name", "Type", "Default", "Constraints", "Description"}, rows)
+		out.WriteString("\n")
+	}
+
+	out.WriteString("## Outputs\n\n")
+	if len(d.Outputs) == 0 {
+		out.WriteString("The template has no outputs.\n\n")
+	} else {
+		rows := make([][]string, 0)
+		for _, o := range d.Outputs {
+			rows = append(rows, []string{code(o.Name), code(o.Value), cell(o.Condition), cell(o.Description)})
+		}
+		table(out, []string{"Name", "Value", "Condition", "Description"}, rows)
+		out.WriteString("\n")
+	}
+
+	if exports := d.Exports(); len(exports) > 0 {
+		out.WriteString("## Exports\n\n")
+		rows := make([][]string, 0)
+		for _, o := range exports {
+			rows = append(rows, []string{code(o.Export), code(o.Name)})
+		}
+		table(out, []string{"Export name", "Output"}, rows)
+		out.WriteString("\n")
+	}
+
+	out.WriteString("## Resources\n\n")
+	if len(d.Resources) == 0 {
+		out.WriteString("The template has no resources.\n")
+	} else {
+		rows := make([][]string, 0)
+		for _, r := range d.Resources {
+			rows = append(rows, []string{code(r.Name), cell(r.Type), cell(r.Condition)})
+		}
+		table(out, []string{"Logical ID", "Type", "Condition"}, rows)
+	}
+
+	return out.String()
+}
@@ -0,0 +1,44 @@
+package eval
+
+import (
+	"fmt"
+	"math/big"
+	"net/netip"
+)
+
+// Cidr returns the same CIDR blocks as Fn::Cidr: count consecutive blocks
+// of ipBlock, each with cidrBits host bits
+func Cidr(ipBlock netip.Prefix, count int, cidrBits int) ([]netip.Prefix, error) {
+	ipBlock = ipBlock.Masked()
+	bits := ipBlock.Addr().BitLen()
+
+	if count < 1 || count > 256 {
+		return nil, fmt.Errorf("count must be between 1 and 256, got %d", count)
+	}
+
+	prefixLen := bits - cidrBits
+	if cidrBits < 1 || prefixLen < ipBlock.Bits() {
+		return nil, fmt.Errorf("cannot make blocks with %d host bits from %s", cidrBits, ipBlock)
+	}
+
+	available := new(big.Int).Lsh(big.NewInt(1), uint(prefixLen-ipBlock.Bits()))
+	if available.Cmp(big.NewInt(int64(count))) < 0 {
+		return nil, fmt.Errorf("%s only has room for %s blocks of /%d, not %d", ipBlock, available, prefixLen, count)
+	}
+
+	base := new(big.Int).SetBytes(ipBlock.Addr().AsSlice())
+	size := new(big.Int).Lsh(big.NewInt(1), uint(cidrBits))
+
+	blocks := make([]netip.Prefix, count)
+	for i := range blocks {
+		n := new(big.Int).Add(base, new(big.Int).Mul(size, big.NewInt(int64(i))))
+
+		b := make([]byte, bits/8)
+		n.FillBytes(b)
+
+		addr, _ := netip.AddrFromSlice(b)
+		blocks[i] = netip.PrefixFrom(addr, prefixLen)
+	}
+
+	return blocks, nil
+}
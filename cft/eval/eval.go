@@ -0,0 +1,279 @@
+// Package eval evaluates string expressions in a template symbolically,
+// without deploying it, so that different ways of writing the same string
+// (e.g. Fn::Join and Fn::Sub) can be compared.
+//
+// An expression evaluates to an Expr: a sequence of literal text,
+// Refs and Fn::GetAtts. Values that eval does not understand,
+// such as the result of Fn::Select, are kept as opaque parts
+// that are only equal to identical expressions.
+//
+// Resolve evaluates a whole template for a given set of parameter values,
+// to preview what would be deployed.
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is the kind of a Part
+type Kind int
+
+const (
+	// Literal is literal text
+	Literal Kind = iota
+
+	// Ref is a Ref to a parameter, resource, or pseudo parameter
+	Ref
+
+	// GetAtt is an Fn::GetAtt, written as "LogicalId.Attribute"
+	GetAtt
+
+	// Opaque is any other expression that results in a string
+	Opaque
+)
+
+// Part is one piece of an evaluated string
+type Part struct {
+	Kind Kind
+
+	// Value is the text of a Literal, the name of a Ref,
+	// the "LogicalId.Attribute" of a GetAtt,
+	// or a canonical representation of an Opaque expression
+	Value string
+
+	// Node is the original expression of an Opaque part
+	Node *yaml.Node
+}
+
+// Expr is an evaluated string
+type Expr []Part
+
+// ErrNotString is returned for expressions that cannot evaluate to a string
+var ErrNotString = errors.New("expression does not evaluate to a string")
+
+// Dynamic returns true if the expression contains anything other than literal text
+func (e Expr) Dynamic() bool {
+	for _, p := range e {
+		if p.Kind != Literal {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasOpaque returns true if the expression contains parts that eval does not understand
+func (e Expr) HasOpaque() bool {
+	for _, p := range e {
+		if p.Kind == Opaque {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Equal returns true if both expressions always evaluate to the same string
+func (e Expr) Equal(other Expr) bool {
+	if len(e) != len(other) {
+		return false
+	}
+
+	for i := range e {
+		if e[i].Kind != other[i].Kind || e[i].Value != other[i].Value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e Expr) String() string {
+	parts := make([]string, len(e))
+	for i, p := range e {
+		switch p.Kind {
+		case Literal:
+			parts[i] = p.Value
+		case Opaque:
+			parts[i] = "${?}"
+		default:
+			parts[i] = "${" + p.Value + "}"
+		}
+	}
+
+	return strings.Join(parts, "")
+}
+
+// add appends p to the expression, merging adjacent literals and dropping empty ones
+func (e Expr) add(p Part) Expr {
+	if p.Kind == Literal {
+		if p.Value == "" {
+			return e
+		}
+
+		if len(e) > 0 && e[len(e)-1].Kind == Literal {
+			e[len(e)-1].Value += p.Value
+			return e
+		}
+	}
+
+	return append(e, p)
+}
+
+// String evaluates n, which must be an expression that results in a single string
+func String(n *yaml.Node) (Expr, error) {
+	e := make(Expr, 0)
+
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return e.add(Part{Kind: Literal, Value: n.Value}), nil
+	case yaml.MappingNode:
+		if len(n.Content) != 2 {
+			return nil, ErrNotString
+		}
+	default:
+		return nil, ErrNotString
+	}
+
+	fn, arg := n.Content[0].Value, n.Content[1]
+
+	switch fn {
+	case "Ref":
+		if arg.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("invalid Ref: %w", ErrNotString)
+		}
+		return e.add(Part{Kind: Ref, Value: arg.Value}), nil
+	case "Fn::GetAtt":
+		name, err := getAtt(arg)
+		if err != nil {
+			return nil, err
+		}
+		return e.add(Part{Kind: GetAtt, Value: name}), nil
+	case "Fn::Join":
+		return join(n)
+	case "Fn::Sub":
+		return sub(arg)
+	}
+
+	if strings.HasPrefix(fn, "Fn::") {
+		return e.add(opaque(n)), nil
+	}
+
+	return nil, ErrNotString
+}
+
+// opaque returns an Opaque part for n
+func opaque(n *yaml.Node) Part {
+	return Part{Kind: Opaque, Value: node.ToSJson(n), Node: n}
+}
+
+// getAtt returns the "LogicalId.Attribute" form of the argument to Fn::GetAtt
+func getAtt(arg *yaml.Node) (string, error) {
+	switch arg.Kind {
+	case yaml.ScalarNode:
+		if strings.Contains(arg.Value, ".") {
+			return arg.Value, nil
+		}
+	case yaml.SequenceNode:
+		if len(arg.Content) == 2 && arg.Content[0].Kind == yaml.ScalarNode && arg.Content[1].Kind == yaml.ScalarNode {
+			return arg.Content[0].Value + "." + arg.Content[1].Value, nil
+		}
+	}
+
+	return "", fmt.Errorf("invalid Fn::GetAtt: %w", ErrNotString)
+}
+
+// join evaluates an Fn::Join
+func join(n *yaml.Node) (Expr, error) {
+	arg := n.Content[1]
+	if arg.Kind != yaml.SequenceNode || len(arg.Content) != 2 {
+		return nil, fmt.Errorf("invalid Fn::Join: %w", ErrNotString)
+	}
+
+	delimiter, list := arg.Content[0], arg.Content[1]
+	if delimiter.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("Fn::Join delimiter is not a string: %w", ErrNotString)
+	}
+
+	// A list that is the result of a function could have any number of elements
+	if list.Kind != yaml.SequenceNode {
+		return Expr{opaque(n)}, nil
+	}
+
+	e := make(Expr, 0)
+	for i, item := range list.Content {
+		if i > 0 {
+			e = e.add(Part{Kind: Literal, Value: delimiter.Value})
+		}
+
+		value, err := String(item)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, p := range value {
+			e = e.add(p)
+		}
+	}
+
+	return e, nil
+}
+
+// sub evaluates the arguments of an Fn::Sub
+func sub(arg *yaml.Node) (Expr, error) {
+	s := arg
+	vars := make(map[string]*yaml.Node)
+
+	if arg.Kind == yaml.SequenceNode {
+		if len(arg.Content) != 2 || arg.Content[1].Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("invalid Fn::Sub: %w", ErrNotString)
+		}
+
+		s = arg.Content[0]
+		m := arg.Content[1]
+		for i := 0; i < len(m.Content)-1; i += 2 {
+			vars[m.Content[i].Value] = m.Content[i+1]
+		}
+	}
+
+	if s.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("invalid Fn::Sub: %w", ErrNotString)
+	}
+
+	words, err := parse.ParseSub(s.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	e := make(Expr, 0)
+	for _, w := range words {
+		switch w.T {
+		case parse.STR:
+			e = e.add(Part{Kind: Literal, Value: w.W})
+		case parse.AWS:
+			e = e.add(Part{Kind: Ref, Value: "AWS::" + w.W})
+		case parse.REF, parse.GETATT:
+			if v, ok := vars[w.W]; ok {
+				value, err := String(v)
+				if err != nil {
+					return nil, err
+				}
+				for _, p := range value {
+					e = e.add(p)
+				}
+			} else if w.T == parse.REF {
+				e = e.add(Part{Kind: Ref, Value: w.W})
+			} else {
+				e = e.add(Part{Kind: GetAtt, Value: w.W})
+			}
+		}
+	}
+
+	return e, nil
+}
@@ -0,0 +1,99 @@
+package eval_test
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/eval"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"gopkg.in/yaml.v3"
+)
+
+func value(t *testing.T, src string) *yaml.Node {
+	tmpl, err := parse.String("Value: " + src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tmpl.Node.Content[0].Content[1]
+}
+
+func TestEquivalent(t *testing.T) {
+	cases := [][]string{
+		{`!Join ["", ["arn:", !Ref AWS::Partition, ":s3:::", !Ref Bucket]]`, `!Sub "arn:${AWS::Partition}:s3:::${Bucket}"`},
+		{`!Join ["-", [a, !GetAtt Queue.Arn]]`, `!Sub "a-${Queue.Arn}"`},
+		{`!Join ["-", [a, !GetAtt [Queue, Arn]]]`, `!Join ["", [a-, !GetAtt Queue.Arn]]`},
+		{`!Sub ["${A}-b", {A: !Ref X}]`, `!Sub "${X}-b"`},
+		{`!Sub "${!Literal}"`, `"${Literal}"`},
+		{`!Join ["", [a, !Join [",", [b, c]]]]`, `ab,c`},
+		{`!Sub ["${A}", {A: !Select [0, !GetAZs ""]}]`, `!Select [0, !GetAZs ""]`},
+	}
+
+	for _, c := range cases {
+		a, err := eval.String(value(t, c[0]))
+		if err != nil {
+			t.Fatalf("%s: %s", c[0], err)
+		}
+
+		b, err := eval.String(value(t, c[1]))
+		if err != nil {
+			t.Fatalf("%s: %s", c[1], err)
+		}
+
+		if !a.Equal(b) {
+			t.Errorf("expected %s (%s) to equal %s (%s)", c[0], a, c[1], b)
+		}
+	}
+}
+
+func TestDifferent(t *testing.T) {
+	cases := [][]string{
+		{`!Sub "${A}"`, `!Sub "${B}"`},
+		{`!Sub "${A.Arn}"`, `!Ref A`},
+		{`!Join ["", [a, !Select [0, [x]]]]`, `!Join ["", [a, !Select [1, [x]]]]`},
+	}
+
+	for _, c := range cases {
+		a, _ := eval.String(value(t, c[0]))
+		b, _ := eval.String(value(t, c[1]))
+
+		if a.Equal(b) {
+			t.Errorf("expected %s to differ from %s", c[0], c[1])
+		}
+	}
+}
+
+func TestNotString(t *testing.T) {
+	for _, src := range []string{`[a, b]`, `{Bucket: b}`, `!Ref [a]`} {
+		if _, err := eval.String(value(t, src)); err == nil {
+			t.Errorf("%s: expected an error", src)
+		}
+	}
+}
+
+func TestCidr(t *testing.T) {
+	blocks, err := eval.Cidr(netip.MustParsePrefix("10.0.0.0/16"), 3, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24"}
+	for i, b := range blocks {
+		if b.String() != expected[i] {
+			t.Errorf("expected %s, got %s", expected[i], b)
+		}
+	}
+
+	blocks, err = eval.Cidr(netip.MustParsePrefix("2001:db8::/56"), 2, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if blocks[1].String() != "2001:db8:0:1::/64" {
+		t.Errorf("unexpected IPv6 block: %s", blocks[1])
+	}
+
+	if _, err := eval.Cidr(netip.MustParsePrefix("10.0.0.0/24"), 4, 7); err == nil {
+		t.Error("expected an error for insufficient address space")
+	}
+}
@@ -0,0 +1,609 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// Values are what Resolve knows about the deployment that is being previewed
+type Values struct {
+	// Parameters are the values of the template's parameters,
+	// which take the place of their defaults
+	Parameters map[string]string
+
+	// Pseudo are the values of pseudo parameters, keyed by their full name, e.g. "AWS::Region".
+	// Pseudo parameters that are not set are left as they are.
+	Pseudo map[string]string
+}
+
+// resolver holds the state of a call to Resolve
+type resolver struct {
+	params     map[string]*yaml.Node
+	pseudo     map[string]string
+	mappings   *yaml.Node
+	conditions map[string]*yaml.Node
+
+	// results are the conditions that have been evaluated
+	results map[string]bool
+
+	// evaluating are the conditions that are being evaluated, to detect cycles
+	evaluating map[string]bool
+}
+
+// Resolve returns a copy of the template as it would be deployed with the given values.
+//
+// Refs to parameters and pseudo parameters are replaced with their values,
+// and Fn::Sub, Fn::Join, Fn::If, Fn::Select, Fn::FindInMap and Fn::Split are evaluated.
+// Each condition in the Conditions section is replaced with true or false,
+// and resources and outputs whose conditions are false are removed.
+//
+// Values that can only be known once the stack is deployed, such as Refs to resources
+// and Fn::GetAtt, are left as they are, along with any functions that use them.
+func Resolve(t cft.Template, values Values) (cft.Template, error) {
+	out := cft.Template{Node: node.Clone(t.Node)}
+
+	r, err := newResolver(out, values, true)
+	if err != nil {
+		return out, err
+	}
+
+	conditions, _ := out.GetSection(cft.Conditions)
+	if conditions != nil {
+		for i := 0; i < len(conditions.Content)-1; i += 2 {
+			name := conditions.Content[i].Value
+			result, err := r.condition(name)
+			if err != nil {
+				return out, err
+			}
+			conditions.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(result)}
+		}
+	}
+
+	for _, section := range []cft.Section{cft.Resources, cft.Outputs} {
+		s, _ := out.GetSection(section)
+		if s == nil || s.Kind != yaml.MappingNode {
+			continue
+		}
+
+		content := make([]*yaml.Node, 0, len(s.Content))
+		for i := 0; i < len(s.Content)-1; i += 2 {
+			name, item := s.Content[i], s.Content[i+1]
+
+			if _, c, _ := s11n.GetMapValue(item, "Condition"); c != nil && c.Kind == yaml.ScalarNode {
+				result, err := r.condition(c.Value)
+				if err != nil {
+					return out, fmt.Errorf("%s %s: %w", section, name.Value, err)
+				}
+				if !result {
+					continue
+				}
+			}
+
+			resolved, err := r.value(item)
+			if err != nil {
+				return out, fmt.Errorf("%s %s: %w", section, name.Value, err)
+			}
+			if resolved == nil {
+				continue
+			}
+
+			content = append(content, name, resolved)
+		}
+		s.Content = content
+	}
+
+	return out, nil
+}
+
+// Conditions returns the result of each condition in the template for the given values.
+// Unlike Resolve, a parameter that has no value is only an error if a condition uses it.
+func Conditions(t cft.Template, values Values) (map[string]bool, error) {
+	r, err := newResolver(t, values, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range r.conditions {
+		if _, err := r.condition(name); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.results, nil
+}
+
+// newResolver returns a resolver for the template. If strict is set,
+// every parameter must have a value.
+func newResolver(t cft.Template, values Values, strict bool) (*resolver, error) {
+	r := &resolver{
+		params:     make(map[string]*yaml.Node),
+		pseudo:     values.Pseudo,
+		conditions: make(map[string]*yaml.Node),
+		results:    make(map[string]bool),
+		evaluating: make(map[string]bool),
+	}
+
+	if r.pseudo == nil {
+		r.pseudo = make(map[string]string)
+	}
+
+	if err := r.setParams(t, values.Parameters, strict); err != nil {
+		return nil, err
+	}
+
+	r.mappings, _ = t.GetSection(cft.Mappings)
+
+	if conditions, _ := t.GetSection(cft.Conditions); conditions != nil {
+		for i := 0; i < len(conditions.Content)-1; i += 2 {
+			r.conditions[conditions.Content[i].Value] = conditions.Content[i+1]
+		}
+	}
+
+	return r, nil
+}
+
+// setParams sets the value of each parameter from values or its default
+func (r *resolver) setParams(t cft.Template, values map[string]string, strict bool) error {
+	params, _ := t.GetSection(cft.Parameters)
+
+	missing := make([]string, 0)
+	known := make([]string, 0)
+
+	if params != nil {
+		for i := 0; i < len(params.Content)-1; i += 2 {
+			name, p := params.Content[i].Value, params.Content[i+1]
+			known = append(known, name)
+
+			typ := ""
+			if _, n, _ := s11n.GetMapValue(p, "Type"); n != nil {
+				typ = n.Value
+			}
+
+			value, ok := values[name]
+			if !ok {
+				_, def, _ := s11n.GetMapValue(p, "Default")
+				switch {
+				case def != nil:
+					value = def.Value
+				case strings.HasPrefix(typ, "AWS::SSM::Parameter::Value"):
+					// The value is read from Parameter Store when the stack is deployed
+					continue
+				default:
+					missing = append(missing, name)
+					continue
+				}
+			}
+
+			if strings.HasPrefix(typ, "List<") || typ == "CommaDelimitedList" {
+				list := &yaml.Node{Kind: yaml.SequenceNode}
+				for _, item := range strings.Split(value, ",") {
+					list.Content = append(list.Content, scalar(strings.TrimSpace(item)))
+				}
+				r.params[name] = list
+			} else {
+				r.params[name] = scalar(value)
+			}
+		}
+	}
+
+	unknown := make([]string, 0)
+	for name := range values {
+		if !slices.Contains(known, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+
+	if len(unknown) > 0 {
+		return fmt.Errorf("the template has no parameters named %s", strings.Join(unknown, ", "))
+	}
+
+	if strict && len(missing) > 0 {
+		return fmt.Errorf("no values for parameters %s", strings.Join(missing, ", "))
+	}
+
+	return nil
+}
+
+// scalar returns a string node
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// condition returns the result of the named condition
+func (r *resolver) condition(name string) (bool, error) {
+	if result, ok := r.results[name]; ok {
+		return result, nil
+	}
+
+	n, ok := r.conditions[name]
+	if !ok {
+		return false, fmt.Errorf("condition %s does not exist", name)
+	}
+
+	if r.evaluating[name] {
+		return false, fmt.Errorf("condition %s refers to itself", name)
+	}
+	r.evaluating[name] = true
+	defer delete(r.evaluating, name)
+
+	result, err := r.truth(n)
+	if err != nil {
+		return false, fmt.Errorf("unable to evaluate condition %s: %w", name, err)
+	}
+
+	r.results[name] = result
+
+	return result, nil
+}
+
+// truth evaluates a condition function
+func (r *resolver) truth(n *yaml.Node) (bool, error) {
+	if n.Kind != yaml.MappingNode || len(n.Content) != 2 {
+		return false, fmt.Errorf("%s is not a condition function", describe(n))
+	}
+
+	fn, arg := n.Content[0].Value, n.Content[1]
+
+	switch fn {
+	case "Condition":
+		return r.condition(arg.Value)
+	case "Fn::Equals":
+		if arg.Kind != yaml.SequenceNode || len(arg.Content) != 2 {
+			return false, errors.New("Fn::Equals needs two values")
+		}
+		left, err := r.known(arg.Content[0])
+		if err != nil {
+			return false, err
+		}
+		right, err := r.known(arg.Content[1])
+		if err != nil {
+			return false, err
+		}
+		return left == right, nil
+	case "Fn::Not":
+		if arg.Kind != yaml.SequenceNode || len(arg.Content) != 1 {
+			return false, errors.New("Fn::Not needs one condition")
+		}
+		result, err := r.truth(arg.Content[0])
+		return !result, err
+	case "Fn::And", "Fn::Or":
+		if arg.Kind != yaml.SequenceNode {
+			return false, fmt.Errorf("%s needs a list of conditions", fn)
+		}
+		for _, c := range arg.Content {
+			result, err := r.truth(c)
+			if err != nil {
+				return false, err
+			}
+			if fn == "Fn::And" && !result {
+				return false, nil
+			}
+			if fn == "Fn::Or" && result {
+				return true, nil
+			}
+		}
+		return fn == "Fn::And", nil
+	}
+
+	return false, fmt.Errorf("%s is not a condition function", fn)
+}
+
+// known resolves n, which must result in a value that is known before the stack is deployed
+func (r *resolver) known(n *yaml.Node) (string, error) {
+	v, err := r.value(n)
+	if err != nil {
+		return "", err
+	}
+
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return "", fmt.Errorf("the value of %s is not known before the stack is deployed", describe(n))
+	}
+
+	return v.Value, nil
+}
+
+// describe returns a short description of n for error messages
+func describe(n *yaml.Node) string {
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 && n.Content[1].Kind == yaml.ScalarNode {
+		return n.Content[0].Value + " " + n.Content[1].Value
+	}
+
+	out, err := json.Marshal(format.Jsonise(n))
+	if err != nil {
+		return n.Value
+	}
+
+	return string(out)
+}
+
+// isFunction returns true if n is a call to an intrinsic function
+func isFunction(n *yaml.Node) bool {
+	if n.Kind != yaml.MappingNode || len(n.Content) != 2 {
+		return false
+	}
+
+	key := n.Content[0].Value
+	return key == "Ref" || strings.HasPrefix(key, "Fn::")
+}
+
+// value returns a resolved copy of n, or nil if it resolves to AWS::NoValue
+func (r *resolver) value(n *yaml.Node) (*yaml.Node, error) {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		out := &yaml.Node{Kind: yaml.SequenceNode, Style: n.Style, Tag: n.Tag}
+		for _, item := range n.Content {
+			v, err := r.value(item)
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				out.Content = append(out.Content, v)
+			}
+		}
+		return out, nil
+	case yaml.MappingNode:
+		if isFunction(n) {
+			return r.function(n)
+		}
+
+		out := &yaml.Node{Kind: yaml.MappingNode, Style: n.Style, Tag: n.Tag}
+		for i := 0; i < len(n.Content)-1; i += 2 {
+			v, err := r.value(n.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			if v != nil {
+				out.Content = append(out.Content, node.Clone(n.Content[i]), v)
+			}
+		}
+		return out, nil
+	}
+
+	return node.Clone(n), nil
+}
+
+// function resolves a call to an intrinsic function
+func (r *resolver) function(n *yaml.Node) (*yaml.Node, error) {
+	fn, arg := n.Content[0].Value, n.Content[1]
+
+	switch fn {
+	case "Ref":
+		return r.ref(n)
+	case "Fn::If":
+		if arg.Kind != yaml.SequenceNode || len(arg.Content) != 3 {
+			return nil, errors.New("Fn::If needs a condition and two values")
+		}
+		result, err := r.condition(arg.Content[0].Value)
+		if err != nil {
+			return nil, err
+		}
+		if result {
+			return r.value(arg.Content[1])
+		}
+		return r.value(arg.Content[2])
+	case "Fn::Sub":
+		return r.sub(arg)
+	}
+
+	args, err := r.value(arg)
+	if err != nil {
+		return nil, err
+	}
+
+	unresolved := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{node.Clone(n.Content[0]), args}}
+
+	switch fn {
+	case "Fn::Join":
+		if args.Kind != yaml.SequenceNode || len(args.Content) != 2 {
+			return nil, errors.New("Fn::Join needs a delimiter and a list")
+		}
+		delimiter, list := args.Content[0], args.Content[1]
+		if delimiter.Kind != yaml.ScalarNode || !allScalars(list) {
+			return unresolved, nil
+		}
+		items := make([]string, 0, len(list.Content))
+		for _, item := range list.Content {
+			items = append(items, item.Value)
+		}
+		return scalar(strings.Join(items, delimiter.Value)), nil
+	case "Fn::Select":
+		if args.Kind != yaml.SequenceNode || len(args.Content) != 2 {
+			return nil, errors.New("Fn::Select needs an index and a list")
+		}
+		index, list := args.Content[0], args.Content[1]
+		if index.Kind != yaml.ScalarNode || list.Kind != yaml.SequenceNode || isFunction(list) {
+			return unresolved, nil
+		}
+		i, err := strconv.Atoi(index.Value)
+		if err != nil {
+			return nil, fmt.Errorf("Fn::Select index '%s' is not a number", index.Value)
+		}
+		if i < 0 || i >= len(list.Content) {
+			return nil, fmt.Errorf("Fn::Select index %d is out of range for a list of %d", i, len(list.Content))
+		}
+		return list.Content[i], nil
+	case "Fn::Split":
+		if args.Kind != yaml.SequenceNode || len(args.Content) != 2 {
+			return nil, errors.New("Fn::Split needs a delimiter and a string")
+		}
+		delimiter, source := args.Content[0], args.Content[1]
+		if delimiter.Kind != yaml.ScalarNode || source.Kind != yaml.ScalarNode {
+			return unresolved, nil
+		}
+		list := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, item := range strings.Split(source.Value, delimiter.Value) {
+			list.Content = append(list.Content, scalar(item))
+		}
+		return list, nil
+	case "Fn::FindInMap":
+		return r.findInMap(args, unresolved)
+	}
+
+	return unresolved, nil
+}
+
+// allScalars returns true if n is a list of plain values
+func allScalars(n *yaml.Node) bool {
+	if n.Kind != yaml.SequenceNode {
+		return false
+	}
+
+	for _, item := range n.Content {
+		if item.Kind != yaml.ScalarNode {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ref resolves a Ref to a parameter or pseudo parameter
+func (r *resolver) ref(n *yaml.Node) (*yaml.Node, error) {
+	name := n.Content[1].Value
+
+	if name == "AWS::NoValue" {
+		return nil, nil
+	}
+
+	if v, ok := r.pseudo[name]; ok {
+		return scalar(v), nil
+	}
+
+	if v, ok := r.params[name]; ok {
+		return node.Clone(v), nil
+	}
+
+	return node.Clone(n), nil
+}
+
+// findInMap resolves the already resolved arguments of an Fn::FindInMap
+func (r *resolver) findInMap(args *yaml.Node, unresolved *yaml.Node) (*yaml.Node, error) {
+	if args.Kind != yaml.SequenceNode || len(args.Content) < 3 {
+		return nil, errors.New("Fn::FindInMap needs a map name and two keys")
+	}
+
+	keys := args.Content[:3]
+	for _, k := range keys {
+		if k.Kind != yaml.ScalarNode {
+			return unresolved, nil
+		}
+	}
+
+	if r.mappings != nil {
+		if _, m, _ := s11n.GetMapValue(r.mappings, keys[0].Value); m != nil {
+			if _, top, _ := s11n.GetMapValue(m, keys[1].Value); top != nil {
+				if _, v, _ := s11n.GetMapValue(top, keys[2].Value); v != nil {
+					return r.value(v)
+				}
+			}
+		}
+	}
+
+	// The language extensions transform allows a default value
+	if len(args.Content) == 4 {
+		if _, def, _ := s11n.GetMapValue(args.Content[3], "DefaultValue"); def != nil {
+			return def, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Fn::FindInMap: %s has no value for %s, %s",
+		keys[0].Value, keys[1].Value, keys[2].Value)
+}
+
+// sub resolves the arguments of an Fn::Sub. If any variables can't be resolved,
+// the result is an Fn::Sub of what is left.
+func (r *resolver) sub(arg *yaml.Node) (*yaml.Node, error) {
+	s := arg
+	vars := make(map[string]*yaml.Node)
+
+	if arg.Kind == yaml.SequenceNode {
+		if len(arg.Content) != 2 || arg.Content[1].Kind != yaml.MappingNode {
+			return nil, errors.New("invalid Fn::Sub")
+		}
+
+		s = arg.Content[0]
+		m := arg.Content[1]
+		for i := 0; i < len(m.Content)-1; i += 2 {
+			v, err := r.value(m.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			vars[m.Content[i].Value] = v
+		}
+	}
+
+	if s.Kind != yaml.ScalarNode {
+		return nil, errors.New("invalid Fn::Sub")
+	}
+
+	words, err := parse.ParseSub(s.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &strings.Builder{}
+	remaining := &strings.Builder{}
+	remainingVars := &yaml.Node{Kind: yaml.MappingNode}
+	complete := true
+
+	for _, w := range words {
+		var value *yaml.Node
+		name := w.W
+
+		switch w.T {
+		case parse.STR:
+			resolved.WriteString(w.W)
+			remaining.WriteString(strings.ReplaceAll(w.W, "${", "${!"))
+			continue
+		case parse.AWS:
+			name = "AWS::" + w.W
+			if v, ok := r.pseudo[name]; ok {
+				value = scalar(v)
+			}
+		case parse.REF, parse.GETATT:
+			if v, ok := vars[name]; ok {
+				if v == nil {
+					return nil, fmt.Errorf("Fn::Sub variable %s has no value", name)
+				}
+				value = v
+				if v.Kind != yaml.ScalarNode {
+					remainingVars.Content = append(remainingVars.Content, scalar(name), v)
+				}
+			} else if v, ok := r.params[name]; ok && w.T == parse.REF {
+				value = v
+			}
+		}
+
+		if value != nil && value.Kind == yaml.ScalarNode {
+			resolved.WriteString(value.Value)
+			remaining.WriteString(strings.ReplaceAll(value.Value, "${", "${!"))
+		} else {
+			complete = false
+			remaining.WriteString("${" + name + "}")
+		}
+	}
+
+	if complete {
+		return scalar(resolved.String()), nil
+	}
+
+	subArg := scalar(remaining.String())
+	if len(remainingVars.Content) > 0 {
+		subArg = &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{subArg, remainingVars}}
+	}
+
+	return &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("Fn::Sub"), subArg}}, nil
+}
@@ -0,0 +1,208 @@
+package eval_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/eval"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+const resolveSource = `
+Parameters:
+  Env:
+    Type: String
+    Default: dev
+  Subnets:
+    Type: CommaDelimitedList
+  Ami:
+    Type: AWS::SSM::Parameter::Value<AWS::EC2::Image::Id>
+    Default: /aws/service/ami
+Mappings:
+  Sizes:
+    dev:
+      Instance: t3.micro
+    prod:
+      Instance: m5.large
+Conditions:
+  IsProd: !Equals [!Ref Env, prod]
+  IsDev: !Not [!Condition IsProd]
+  IsEast: !And
+    - !Condition IsDev
+    - !Equals [!Ref AWS::Region, us-east-1]
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !Sub ${Env}-${AWS::Region}-${AWS::AccountId}-${!Literal}
+      Tags:
+        - Key: Size
+          Value: !FindInMap [Sizes, !Ref Env, Instance]
+      VersioningConfiguration: !If
+        - IsProd
+        - Status: Enabled
+        - !Ref AWS::NoValue
+  Backup:
+    Type: AWS::Backup::BackupVault
+    Condition: IsProd
+  Instance:
+    Type: AWS::EC2::Instance
+    Properties:
+      ImageId: !Ref Ami
+      SubnetId: !Select [1, !Ref Subnets]
+      UserData: !Join [",", [!Select [0, !Split ["/", a/b]], !Ref Bucket]]
+      KeyName: !Sub ["${Name}-${Arn}", {Name: !Ref Env, Arn: !GetAtt Bucket.Arn}]
+Outputs:
+  BackupName:
+    Condition: IsProd
+    Value: !Ref Backup
+  Zone:
+    Value: !Join ["-", [!Ref Env, !Ref AWS::Region]]
+`
+
+const resolveExpected = `Parameters:
+  Env:
+    Type: String
+    Default: dev
+
+  Subnets:
+    Type: CommaDelimitedList
+
+  Ami:
+    Type: AWS::SSM::Parameter::Value<AWS::EC2::Image::Id>
+    Default: /aws/service/ami
+
+Mappings:
+  Sizes:
+    dev:
+      Instance: t3.micro
+    prod:
+      Instance: m5.large
+
+Conditions:
+  IsProd: false
+
+  IsDev: true
+
+  IsEast: true
+
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !Sub dev-us-east-1-${AWS::AccountId}-${!Literal}
+      Tags:
+        - Key: Size
+          Value: t3.micro
+
+  Instance:
+    Type: AWS::EC2::Instance
+    Properties:
+      ImageId: /aws/service/ami
+      SubnetId: subnet-b
+      UserData: !Join
+        - ','
+        - - a
+          - !Ref Bucket
+      KeyName: !Sub
+        - dev-${Arn}
+        - Arn: !GetAtt Bucket.Arn
+
+Outputs:
+  Zone:
+    Value: dev-us-east-1
+`
+
+func TestResolve(t *testing.T) {
+	tmpl, err := parse.String(resolveSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := eval.Resolve(tmpl, eval.Values{
+		Parameters: map[string]string{"Subnets": "subnet-a, subnet-b"},
+		Pseudo:     map[string]string{"AWS::Region": "us-east-1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := cmp.Diff(resolveExpected, format.String(resolved, format.Options{})); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestResolveProd(t *testing.T) {
+	tmpl, err := parse.String(resolveSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resolved, err := eval.Resolve(tmpl, eval.Values{
+		Parameters: map[string]string{"Env": "prod", "Subnets": "a,b"},
+		Pseudo:     map[string]string{"AWS::Region": "us-east-1", "AWS::AccountId": "123456789012"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := format.String(resolved, format.Options{})
+	for _, expected := range []string{
+		"IsProd: true",
+		"IsEast: false",
+		"BucketName: prod-us-east-1-123456789012-${Literal}",
+		"Status: Enabled",
+		"Value: m5.large",
+		"  Backup:\n",
+		"  BackupName:\n",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected '%s' in:\n%s", expected, out)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	tmpl, err := parse.String(resolveSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		params   map[string]string
+		expected string
+	}{
+		{map[string]string{}, "no values for parameters Subnets"},
+		{map[string]string{"Subnets": "a", "Size": "1"}, "no parameters named Size"},
+		{map[string]string{"Subnets": "a"}, "Fn::Select index 1 is out of range"},
+		{map[string]string{"Subnets": "a,b", "Env": "test"}, "Sizes has no value for test, Instance"},
+	}
+
+	for _, c := range cases {
+		_, err := eval.Resolve(tmpl, eval.Values{
+			Parameters: c.params,
+			Pseudo:     map[string]string{"AWS::Region": "us-east-1"},
+		})
+		if err == nil || !strings.Contains(err.Error(), c.expected) {
+			t.Errorf("%v: expected an error containing '%s', got %v", c.params, c.expected, err)
+		}
+	}
+
+	// Conditions can only use values that are known before deployment
+	tmpl, err = parse.String(`
+Conditions:
+  HasBucket: !Equals [!Ref Bucket, x]
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := eval.Resolve(tmpl, eval.Values{}); err == nil || !strings.Contains(err.Error(), "the value of Ref Bucket is not known") {
+		t.Errorf("expected an error for an unknown value in a condition, got %v", err)
+	}
+}
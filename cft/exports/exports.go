@@ -0,0 +1,213 @@
+// Package exports implements naming conventions for exported template outputs.
+//
+// A convention has a name template such as "${StackName}:${OutputName}"
+// and an optional list of output names that are expected to be exported.
+// The convention can be applied to a template to fill in export names,
+// and the export names in a template can be resolved ahead of a deployment
+// so that they can be checked for conflicts.
+package exports
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultNameTemplate is used when a convention does not specify a name template
+const DefaultNameTemplate = "${StackName}:${OutputName}"
+
+// Convention describes how exported outputs should be named
+type Convention struct {
+	// NameTemplate is the template for export names.
+	// ${StackName} and ${OutputName} are replaced by the stack and output names.
+	NameTemplate string `yaml:"NameTemplate,omitempty"`
+
+	// Exported is a list of glob patterns matching the names of
+	// outputs that should be exported. If it is empty, rain
+	// has no opinion about which outputs are exported.
+	Exported []string `yaml:"Exported,omitempty"`
+}
+
+func (c Convention) nameTemplate() string {
+	if c.NameTemplate == "" {
+		return DefaultNameTemplate
+	}
+
+	return c.NameTemplate
+}
+
+// Name returns the export name for an output in the named stack
+func (c Convention) Name(stackName, outputName string) string {
+	name := strings.ReplaceAll(c.nameTemplate(), "${StackName}", stackName)
+	return strings.ReplaceAll(name, "${OutputName}", outputName)
+}
+
+// Sub returns an Fn::Sub string that evaluates to the export name
+// for the output, for use when the stack name is not yet known
+func (c Convention) Sub(outputName string) string {
+	return c.Name("${AWS::StackName}", outputName)
+}
+
+// ShouldExport reports whether the output name matches one of the Exported patterns
+func (c Convention) ShouldExport(outputName string) bool {
+	for _, pattern := range c.Exported {
+		if ok, _ := path.Match(pattern, outputName); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Output is an output from a template along with its export, if it has one
+type Output struct {
+	Name   string
+	Node   *yaml.Node
+	Export *yaml.Node
+
+	// ExportName is the node holding the export's Name, if it has one
+	ExportName *yaml.Node
+}
+
+// Outputs returns every output in the template
+func Outputs(t cft.Template) []Output {
+	outputs := make([]Output, 0)
+
+	section, err := t.GetSection(cft.Outputs)
+	if err != nil || section.Kind != yaml.MappingNode {
+		return outputs
+	}
+
+	for i := 0; i < len(section.Content)-1; i += 2 {
+		o := Output{
+			Name: section.Content[i].Value,
+			Node: section.Content[i+1],
+		}
+
+		_, o.Export, _ = s11n.GetMapValue(o.Node, "Export")
+		if o.Export != nil {
+			_, o.ExportName, _ = s11n.GetMapValue(o.Export, "Name")
+		}
+
+		outputs = append(outputs, o)
+	}
+
+	return outputs
+}
+
+// Apply fills in the name of every export in the template that
+// does not already have one, according to the convention.
+// It returns the names of the outputs that were changed.
+func Apply(t cft.Template, c Convention) []string {
+	changed := make([]string, 0)
+
+	for _, o := range Outputs(t) {
+		if o.Export == nil || o.ExportName != nil {
+			continue
+		}
+
+		name := &yaml.Node{Kind: yaml.MappingNode}
+		node.Add(name, "Fn::Sub", c.Sub(o.Name))
+
+		if o.Export.Kind != yaml.MappingNode {
+			// Export: true, or Export: with no value
+			*o.Export = yaml.Node{Kind: yaml.MappingNode}
+		}
+
+		node.SetMapValue(o.Export, "Name", name)
+
+		changed = append(changed, o.Name)
+	}
+
+	return changed
+}
+
+// Resolve returns the export name as it will be after deployment to the named stack.
+// Only literal strings, Fn::Sub strings that refer to AWS::StackName,
+// and Fn::Join of such values can be resolved; ok is false for anything else.
+func Resolve(n *yaml.Node, stackName string) (string, bool) {
+	if n == nil {
+		return "", false
+	}
+
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Value, true
+	case yaml.MappingNode:
+		if len(n.Content) != 2 {
+			return "", false
+		}
+
+		fn, arg := n.Content[0].Value, n.Content[1]
+		switch fn {
+		case "Ref":
+			if arg.Value == "AWS::StackName" {
+				return stackName, true
+			}
+		case "Fn::Sub":
+			if arg.Kind != yaml.ScalarNode {
+				return "", false
+			}
+			if strings.Contains(strings.ReplaceAll(arg.Value, "${AWS::StackName}", ""), "${") {
+				return "", false
+			}
+			return strings.ReplaceAll(arg.Value, "${AWS::StackName}", stackName), true
+		case "Fn::Join":
+			if arg.Kind != yaml.SequenceNode || len(arg.Content) != 2 || arg.Content[1].Kind != yaml.SequenceNode {
+				return "", false
+			}
+			parts := make([]string, 0)
+			for _, part := range arg.Content[1].Content {
+				s, ok := Resolve(part, stackName)
+				if !ok {
+					return "", false
+				}
+				parts = append(parts, s)
+			}
+			return strings.Join(parts, arg.Content[0].Value), true
+		}
+	}
+
+	return "", false
+}
+
+// Names returns the export names that the template will create
+// when deployed as the named stack, keyed by output name.
+// Exports whose names cannot be resolved are left out.
+func Names(t cft.Template, stackName string) map[string]string {
+	names := make(map[string]string)
+
+	for _, o := range Outputs(t) {
+		if name, ok := Resolve(o.ExportName, stackName); ok {
+			names[o.Name] = name
+		}
+	}
+
+	return names
+}
+
+// CheckUnique returns an error if two outputs in the template
+// would be exported with the same name
+func CheckUnique(t cft.Template, stackName string) error {
+	seen := make(map[string]string)
+
+	for _, o := range Outputs(t) {
+		name, ok := Resolve(o.ExportName, stackName)
+		if !ok {
+			continue
+		}
+
+		if other, ok := seen[name]; ok {
+			return fmt.Errorf("outputs '%s' and '%s' are both exported as '%s'", other, o.Name, name)
+		}
+
+		seen[name] = o.Name
+	}
+
+	return nil
+}
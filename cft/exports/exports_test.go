@@ -0,0 +1,103 @@
+package exports_test
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/exports"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+const source = `
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+Outputs:
+  BucketName:
+    Value: !Ref Bucket
+    Export: {}
+  BucketArn:
+    Value: !GetAtt Bucket.Arn
+    Export:
+      Name: !Sub ${AWS::StackName}-arn
+  Joined:
+    Value: !Ref Bucket
+    Export:
+      Name: !Join [":", [!Ref AWS::StackName, joined]]
+  Unknown:
+    Value: !Ref Bucket
+    Export:
+      Name: !Sub ${AWS::Region}-unknown
+  NotExported:
+    Value: !Ref Bucket
+`
+
+func TestConvention(t *testing.T) {
+	c := exports.Convention{Exported: []string{"Bucket*"}}
+
+	if name := c.Name("app", "BucketName"); name != "app:BucketName" {
+		t.Errorf("unexpected name: %s", name)
+	}
+
+	if sub := c.Sub("BucketName"); sub != "${AWS::StackName}:BucketName" {
+		t.Errorf("unexpected sub: %s", sub)
+	}
+
+	if !c.ShouldExport("BucketArn") || c.ShouldExport("Other") {
+		t.Error("unexpected ShouldExport result")
+	}
+}
+
+func TestApplyAndNames(t *testing.T) {
+	tmpl, err := parse.String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed := exports.Apply(tmpl, exports.Convention{})
+	if len(changed) != 1 || changed[0] != "BucketName" {
+		t.Errorf("unexpected changes: %v", changed)
+	}
+
+	names := exports.Names(tmpl, "app")
+
+	expected := map[string]string{
+		"BucketName": "app:BucketName",
+		"BucketArn":  "app-arn",
+		"Joined":     "app:joined",
+	}
+
+	if len(names) != len(expected) {
+		t.Errorf("unexpected names: %v", names)
+	}
+
+	for output, name := range expected {
+		if names[output] != name {
+			t.Errorf("%s: expected '%s', got '%s'", output, name, names[output])
+		}
+	}
+}
+
+func TestCheckUnique(t *testing.T) {
+	tmpl, err := parse.String(`
+Outputs:
+  A:
+    Value: a
+    Export:
+      Name: !Sub ${AWS::StackName}-x
+  B:
+    Value: b
+    Export:
+      Name: app-x
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := exports.CheckUnique(tmpl, "other"); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if err := exports.CheckUnique(tmpl, "app"); err == nil {
+		t.Error("expected a duplicate export error")
+	}
+}
@@ -0,0 +1,179 @@
+// Package features lets a project pin which rain features its templates can use,
+// so that platform teams can gate experimental directives and templates
+// fail fast when they use something that has not been enabled for the project.
+//
+// Features are set in the project's rain.yaml:
+//
+//	Features:
+//	  Directives:
+//	    - Rain::Embed
+//	    - Rain::Module
+//	  Modules:
+//	    - modules/
+//	    - https://github.com/my-org/
+//	  Transforms:
+//	    - AWS::LanguageExtensions
+//
+// Local module paths are relative to the directory of rain.yaml.
+package features
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"gopkg.in/yaml.v3"
+)
+
+// Features lists the features that are enabled.
+// A nil list enables every feature of that kind; an empty list enables none.
+type Features struct {
+	// Directives are the Rain:: directives templates can use, e.g. Rain::Embed
+	Directives []string `yaml:"Directives,omitempty"`
+
+	// Modules are the paths or URLs that modules can be loaded from, as prefixes.
+	// A prefix only matches whole path segments, so my-org does not enable my-org-test.
+	Modules []string `yaml:"Modules,omitempty"`
+
+	// Transforms are the CloudFormation transforms templates can declare
+	Transforms []string `yaml:"Transforms,omitempty"`
+
+	// Dir is the directory that local Modules are relative to,
+	// or "" for the current directory
+	Dir string `yaml:"-"`
+}
+
+// NotEnabledError is returned when a template uses a feature that is not enabled
+type NotEnabledError struct {
+	Kind string
+	Name string
+}
+
+func (e NotEnabledError) Error() string {
+	return fmt.Sprintf("%s '%s' is not enabled for this project; add it to Features/%s in rain.yaml",
+		strings.ToLower(strings.TrimSuffix(e.Kind, "s")), e.Name, e.Kind)
+}
+
+// CheckDirective returns an error if the directive is not enabled
+func (f *Features) CheckDirective(name string) error {
+	if f == nil || f.Directives == nil || slices.Contains(f.Directives, name) {
+		return nil
+	}
+
+	return NotEnabledError{"Directives", name}
+}
+
+// CheckModule returns an error if modules can't be loaded from uri,
+// which is either a URL or the path of a local module
+func (f *Features) CheckModule(uri string) error {
+	if f == nil || f.Modules == nil {
+		return nil
+	}
+
+	if isURL(uri) {
+		for _, prefix := range f.Modules {
+			if isURL(prefix) && hasPathPrefix(uri, strings.TrimSuffix(prefix, "/"), "/?") {
+				return nil
+			}
+		}
+
+		return NotEnabledError{"Modules", uri}
+	}
+
+	path, err := f.relative(uri)
+	if err != nil {
+		return NotEnabledError{"Modules", uri}
+	}
+
+	for _, prefix := range f.Modules {
+		if isURL(prefix) {
+			continue
+		}
+
+		prefix = filepath.ToSlash(filepath.Clean(prefix))
+		if prefix == "." || hasPathPrefix(path, prefix, "/") {
+			return nil
+		}
+	}
+
+	return NotEnabledError{"Modules", path}
+}
+
+// relative returns the path of a local module relative to Dir, with forward slashes.
+// It returns an error if the module is outside Dir.
+func (f *Features) relative(path string) (string, error) {
+	dir, err := filepath.Abs(f.Dir)
+	if err != nil {
+		return "", err
+	}
+
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return "", err
+	}
+
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return "", fmt.Errorf("%s is outside %s", path, dir)
+	}
+
+	return rel, nil
+}
+
+// isURL returns true if uri is a remote location, such as https://, s3:// or git::
+func isURL(uri string) bool {
+	return strings.Contains(uri, "://")
+}
+
+// hasPathPrefix returns true if s is prefix, or starts with prefix
+// followed by one of the separators
+func hasPathPrefix(s, prefix, separators string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+
+	return len(s) == len(prefix) || strings.ContainsRune(separators, rune(s[len(prefix)]))
+}
+
+// CheckTransforms returns an error if the template declares a transform that is not enabled
+func (f *Features) CheckTransforms(t cft.Template) error {
+	if f == nil || f.Transforms == nil {
+		return nil
+	}
+
+	section, err := t.GetSection(cft.Transform)
+	if err != nil {
+		// No transforms
+		return nil
+	}
+
+	names := []*yaml.Node{section}
+	if section.Kind == yaml.SequenceNode {
+		names = section.Content
+	}
+
+	for _, n := range names {
+		// Transforms with parameters, like AWS::Include, are mappings with a Name
+		if n.Kind == yaml.MappingNode {
+			for i := 0; i < len(n.Content)-1; i += 2 {
+				if n.Content[i].Value == "Name" {
+					n = n.Content[i+1]
+					break
+				}
+			}
+		}
+
+		if n.Kind == yaml.ScalarNode && !slices.Contains(f.Transforms, n.Value) {
+			return NotEnabledError{"Transforms", n.Value}
+		}
+	}
+
+	return nil
+}
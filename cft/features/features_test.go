@@ -0,0 +1,132 @@
+package features_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/features"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+func TestNil(t *testing.T) {
+	var f *features.Features
+
+	if f.CheckDirective("Rain::Module") != nil || f.CheckModule("https://example.com/m.yaml") != nil {
+		t.Error("expected everything to be enabled without features")
+	}
+}
+
+func TestDirectives(t *testing.T) {
+	f := &features.Features{Directives: []string{"Rain::Embed"}}
+
+	if err := f.CheckDirective("Rain::Embed"); err != nil {
+		t.Error(err)
+	}
+
+	err := f.CheckDirective("Rain::Module")
+
+	var notEnabled features.NotEnabledError
+	if !errors.As(err, &notEnabled) || notEnabled.Name != "Rain::Module" {
+		t.Errorf("expected Rain::Module not to be enabled, got %v", err)
+	}
+
+	// An empty list enables nothing
+	f = &features.Features{Directives: []string{}}
+	if f.CheckDirective("Rain::Embed") == nil {
+		t.Error("expected Rain::Embed not to be enabled")
+	}
+}
+
+func TestModules(t *testing.T) {
+	f := &features.Features{Modules: []string{"./modules/", "https://github.com/my-org/"}}
+
+	for _, uri := range []string{"modules/bucket.yaml", "./modules/bucket.yaml", "https://github.com/my-org/m.yaml"} {
+		if err := f.CheckModule(uri); err != nil {
+			t.Errorf("%s: %v", uri, err)
+		}
+	}
+
+	for _, uri := range []string{"other/bucket.yaml", "https://github.com/someone-else/m.yaml"} {
+		if f.CheckModule(uri) == nil {
+			t.Errorf("%s: expected module not to be enabled", uri)
+		}
+	}
+}
+
+func TestModuleBoundaries(t *testing.T) {
+	f := &features.Features{Modules: []string{"modules", "https://github.com/my-org", "git::https://github.com/my-org/lib.git"}}
+
+	enabled := []string{
+		"modules/bucket.yaml",
+		"https://github.com/my-org/m.yaml",
+		"git::https://github.com/my-org/lib.git//bucket.yaml?ref=v1",
+	}
+	for _, uri := range enabled {
+		if err := f.CheckModule(uri); err != nil {
+			t.Errorf("%s: %v", uri, err)
+		}
+	}
+
+	disabled := []string{
+		"modules-old/bucket.yaml",
+		"https://github.com/my-org-evil/m.yaml",
+		"git::https://github.com/my-org/lib.git.evil//bucket.yaml",
+	}
+	for _, uri := range disabled {
+		if f.CheckModule(uri) == nil {
+			t.Errorf("%s: expected module not to be enabled", uri)
+		}
+	}
+}
+
+func TestModulesRelativeToDir(t *testing.T) {
+	project := t.TempDir()
+	f := &features.Features{Modules: []string{"./modules/"}, Dir: project}
+
+	// A template packaged by absolute path, or from a subdirectory of the project
+	for _, path := range []string{
+		filepath.Join(project, "modules", "bucket.yaml"),
+		filepath.Join(project, "templates", "..", "modules", "bucket.yaml"),
+	} {
+		if err := f.CheckModule(path); err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+
+	for _, path := range []string{
+		filepath.Join(project, "other", "modules", "bucket.yaml"),
+		filepath.Join(filepath.Dir(project), "modules", "bucket.yaml"),
+		"modules/bucket.yaml",
+	} {
+		if f.CheckModule(path) == nil {
+			t.Errorf("%s: expected module not to be enabled", path)
+		}
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	f := &features.Features{Transforms: []string{"AWS::LanguageExtensions"}}
+
+	cases := map[string]bool{
+		"Resources: {}":                                     true,
+		"Transform: AWS::LanguageExtensions":                true,
+		"Transform: AWS::Serverless-2016-10-31":             false,
+		"Transform: [AWS::LanguageExtensions, MyMacro]":     false,
+		"Transform: [{Name: AWS::Include, Parameters: {}}]": false,
+	}
+
+	for source, ok := range cases {
+		tmpl, err := parse.String(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = f.CheckTransforms(tmpl)
+		if ok && err != nil {
+			t.Errorf("%s: %v", source, err)
+		} else if !ok && err == nil {
+			t.Errorf("%s: expected an error", source)
+		}
+	}
+}
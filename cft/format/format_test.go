@@ -760,3 +760,102 @@ Resources:
 	}
 
 }
+
+func TestYAML11Scalars(t *testing.T) {
+	source, err := parse.String(`Parameters:
+  AccountId:
+    Type: String
+    Default: 012345678901
+  Enabled:
+    Type: String
+    Default: yes
+  Quoted:
+    Type: String
+    Default: "on"
+  Time:
+    Type: String
+    Default: 1:30
+  Name:
+    Type: String
+    Default: !Sub off
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pitfalls := format.Pitfalls(source)
+	if len(pitfalls) != 3 {
+		t.Fatalf("expected 3 pitfalls, got %v", pitfalls)
+	}
+
+	if pitfalls[0].Line != 4 || pitfalls[0].Value != "012345678901" {
+		t.Errorf("unexpected pitfall: %v", pitfalls[0])
+	}
+
+	if normalized := format.NormalizeScalars(source); len(normalized) != 3 {
+		t.Errorf("expected 3 scalars to be normalized, got %v", normalized)
+	}
+
+	out := format.String(source, format.Options{})
+
+	for _, expected := range []string{
+		`Default: "012345678901"`,
+		`Default: "yes"`,
+		`Default: "on"`,
+		`Default: "1:30"`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in:\n%s", expected, out)
+		}
+	}
+
+	if err := parse.Verify(source, out); err != nil {
+		t.Error(err)
+	}
+
+	if len(format.Pitfalls(source)) != 0 {
+		t.Error("expected no pitfalls after normalizing")
+	}
+}
+
+func TestLanguageExtensionsJson(t *testing.T) {
+	input := `
+Transform: AWS::LanguageExtensions
+Resources:
+  Fn::ForEach::Topics:
+    - TopicName
+    - [Orders, Payments]
+    - Topic${TopicName}:
+        Type: AWS::SNS::Topic
+        Properties:
+          DisplayName: !ToJsonString
+            Count: !Length [a, b]
+`
+
+	source, err := parse.String(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := format.String(source, format.Options{
+		JSON:     true,
+		Unsorted: true,
+	})
+
+	if err = parse.Verify(source, output); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{`"Fn::ForEach::Topics"`, `"Fn::ToJsonString"`, `"Fn::Length"`} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %s in:\n%s", expected, output)
+		}
+	}
+
+	yamlOutput := format.String(source, format.Options{Unsorted: true})
+	for _, expected := range []string{"!ToJsonString", "!Length"} {
+		if !strings.Contains(yamlOutput, expected) {
+			t.Errorf("Expected %s in:\n%s", expected, yamlOutput)
+		}
+	}
+}
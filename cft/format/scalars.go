@@ -0,0 +1,132 @@
+package format
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"gopkg.in/yaml.v3"
+)
+
+// CloudFormation reads templates as YAML 1.1, but rain, like most modern tools, uses YAML 1.2.
+// A few unquoted scalars mean something different in each version:
+//
+//   - yes, no, on and off are booleans in YAML 1.1 but strings in YAML 1.2
+//   - numbers with a leading zero, like account IDs, are read as numbers and lose the zero
+//   - numbers separated by colons, like 1:30, are base 60 numbers in YAML 1.1
+
+// yaml11Booleans are the words that YAML 1.1 reads as booleans, other than true and false
+var yaml11Booleans = map[string]bool{
+	"yes": true, "Yes": true, "YES": true,
+	"on": true, "On": true, "ON": true,
+	"no": false, "No": false, "NO": false,
+	"off": false, "Off": false, "OFF": false,
+}
+
+var leadingZero = regexp.MustCompile(`^[-+]?0[0-9_]+$`)
+
+var sexagesimal = regexp.MustCompile(`^[-+]?[1-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?$`)
+
+// Pitfall is an unquoted scalar that CloudFormation will not read as the author expects
+type Pitfall struct {
+	Line    int
+	Column  int
+	Value   string
+	Message string
+}
+
+func (p Pitfall) String() string {
+	return fmt.Sprintf("line %d: %s", p.Line, p.Message)
+}
+
+// ambiguous returns a description of how YAML 1.1 reads value differently from YAML 1.2,
+// or an empty string if it doesn't
+func ambiguous(value string) string {
+	if b, ok := yaml11Booleans[value]; ok {
+		return fmt.Sprintf("'%s' is the boolean %t in YAML 1.1, which CloudFormation uses", value, b)
+	}
+
+	if leadingZero.MatchString(value) {
+		return fmt.Sprintf("'%s' is a number, so CloudFormation may drop its leading zero", value)
+	}
+
+	if sexagesimal.MatchString(value) {
+		return fmt.Sprintf("'%s' is a base 60 number in YAML 1.1, which CloudFormation uses", value)
+	}
+
+	return ""
+}
+
+// isPlainValue returns true if n is an unquoted scalar that is not a key or an intrinsic function
+func isPlainValue(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Style == 0 &&
+		(n.Tag == "" || strings.HasPrefix(n.Tag, "!!"))
+}
+
+// walkValues calls fn for every scalar in n that is not a mapping key
+func walkValues(n *yaml.Node, fn func(*yaml.Node)) {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, c := range n.Content {
+			walkValues(c, fn)
+		}
+	case yaml.MappingNode:
+		for i := 1; i < len(n.Content); i += 2 {
+			walkValues(n.Content[i], fn)
+		}
+	case yaml.ScalarNode:
+		fn(n)
+	}
+}
+
+// Pitfalls returns the unquoted scalars in the template that CloudFormation
+// will read differently than rain does
+func Pitfalls(t cft.Template) []Pitfall {
+	pitfalls := make([]Pitfall, 0)
+
+	if t.Node == nil {
+		return pitfalls
+	}
+
+	walkValues(t.Node, func(n *yaml.Node) {
+		if !isPlainValue(n) {
+			return
+		}
+
+		if message := ambiguous(n.Value); message != "" {
+			pitfalls = append(pitfalls, Pitfall{
+				Line:    n.Line,
+				Column:  n.Column,
+				Value:   n.Value,
+				Message: message,
+			})
+		}
+	})
+
+	return pitfalls
+}
+
+// NormalizeScalars quotes the unquoted scalars in the template that CloudFormation
+// would read differently, and returns the scalars it quoted.
+// They become strings, as rain reads them, so that the template means the same
+// to CloudFormation as it does to rain; a yes that was meant as a boolean is
+// reported, rather than silently rewritten as true.
+func NormalizeScalars(t cft.Template) []Pitfall {
+	pitfalls := Pitfalls(t)
+
+	if len(pitfalls) == 0 {
+		return pitfalls
+	}
+
+	walkValues(t.Node, func(n *yaml.Node) {
+		if !isPlainValue(n) || ambiguous(n.Value) == "" {
+			return
+		}
+
+		n.Tag = "!!str"
+		n.Style = yaml.DoubleQuotedStyle
+	})
+
+	return pitfalls
+}
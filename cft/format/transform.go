@@ -128,5 +128,10 @@ func formatNode(n *yaml.Node) *yaml.Node {
 		panic("invalid --node-style: " + NodeStyle)
 	}
 
+	// Quote strings that CloudFormation would read as something else
+	if NodeStyle == "" && n.Kind == yaml.ScalarNode && n.Tag == "!!str" && ambiguous(n.Value) != "" {
+		n.Style = yaml.DoubleQuotedStyle
+	}
+
 	return n
 }
@@ -0,0 +1,230 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/config"
+)
+
+// Expr is a node in the boolean logic of a condition
+type Expr struct {
+	// Op is the name of the intrinsic function, e.g. Fn::And or Fn::Equals,
+	// Condition for a reference to another condition, Ref for a reference
+	// to a parameter, or "" for a plain value
+	Op string
+
+	// Args are the arguments of the function
+	Args []Expr
+
+	// Value is the plain value, or the name that a Condition or Ref refers to
+	Value string
+}
+
+// ParseCondition returns the logic tree of a condition from the decoded template
+func ParseCondition(value interface{}) Expr {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if len(v) != 1 {
+			break
+		}
+
+		for key, arg := range v {
+			switch key {
+			case "Condition", "Ref":
+				return Expr{Op: key, Value: fmt.Sprint(arg)}
+			}
+
+			e := Expr{Op: key, Args: make([]Expr, 0)}
+			if args, ok := arg.([]interface{}); ok {
+				for _, a := range args {
+					e.Args = append(e.Args, ParseCondition(a))
+				}
+			} else {
+				e.Args = append(e.Args, ParseCondition(arg))
+			}
+
+			return e
+		}
+	case []interface{}:
+		e := Expr{Op: "List", Args: make([]Expr, 0)}
+		for _, a := range v {
+			e.Args = append(e.Args, ParseCondition(a))
+		}
+		return e
+	}
+
+	return Expr{Value: fmt.Sprint(value)}
+}
+
+func (e Expr) label() string {
+	switch e.Op {
+	case "":
+		return fmt.Sprintf("%q", e.Value)
+	case "Condition":
+		return fmt.Sprintf("Condition %s", e.Value)
+	case "Ref":
+		return fmt.Sprintf("Ref %s", e.Value)
+	}
+
+	return strings.TrimPrefix(e.Op, "Fn::")
+}
+
+// String returns the condition on one line, e.g. And(Condition IsProd, Not(Equals(Ref Env, "dev")))
+func (e Expr) String() string {
+	if len(e.Args) == 0 {
+		return e.label()
+	}
+
+	args := make([]string, len(e.Args))
+	for i, a := range e.Args {
+		args[i] = a.String()
+	}
+
+	return fmt.Sprintf("%s(%s)", e.label(), strings.Join(args, ", "))
+}
+
+// Lines returns the condition as a tree, one line per node
+func (e Expr) Lines() []string {
+	lines := []string{e.label()}
+
+	for i, a := range e.Args {
+		branch, indent := "├── ", "│   "
+		if i == len(e.Args)-1 {
+			branch, indent = "└── ", "    "
+		}
+
+		for j, line := range a.Lines() {
+			if j == 0 {
+				lines = append(lines, branch+line)
+			} else {
+				lines = append(lines, indent+line)
+			}
+		}
+	}
+
+	return lines
+}
+
+// Refs returns the names of the parameters and conditions that the condition refers to
+func (e Expr) Refs() (parameters []string, conditions []string) {
+	switch e.Op {
+	case "Ref":
+		return []string{e.Value}, nil
+	case "Condition":
+		return nil, []string{e.Value}
+	}
+
+	for _, a := range e.Args {
+		p, c := a.Refs()
+		parameters = append(parameters, p...)
+		conditions = append(conditions, c...)
+	}
+
+	return parameters, conditions
+}
+
+// ConditionNames returns the names of the template's conditions in the order they are declared
+func ConditionNames(t cft.Template) []string {
+	names := make([]string, 0)
+
+	section, err := t.GetSection(cft.Conditions)
+	if err != nil || section == nil {
+		return names
+	}
+
+	for i := 0; i < len(section.Content); i += 2 {
+		names = append(names, section.Content[i].Value)
+	}
+
+	return names
+}
+
+// Conditions returns the logic tree of each of the template's conditions
+func Conditions(t cft.Template) map[string]Expr {
+	exprs := make(map[string]Expr)
+
+	if conditions, ok := t.Map()["Conditions"].(map[string]interface{}); ok {
+		for name, value := range conditions {
+			exprs[name] = ParseCondition(value)
+		}
+	}
+
+	return exprs
+}
+
+// NewConditions returns a Graph of the connections between the template's
+// conditions, the parameters and conditions that they use,
+// and the resources and outputs that use them
+func NewConditions(t cft.Template) Graph {
+	graph := Empty()
+
+	for _, name := range ConditionNames(t) {
+		graph.add(Node{"Conditions", name})
+	}
+
+	for name, expr := range Conditions(t) {
+		from := Node{"Conditions", name}
+
+		parameters, conditions := expr.Refs()
+		for _, p := range parameters {
+			graph.Link(from, Node{"Parameters", p})
+		}
+		for _, c := range conditions {
+			graph.Link(from, Node{"Conditions", c})
+		}
+	}
+
+	for _, typeName := range []string{"Resources", "Outputs"} {
+		entityTree, ok := t.Map()[typeName].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		for name, entity := range entityTree {
+			e, ok := entity.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			from := Node{typeName, name}
+
+			if c, ok := e["Condition"].(string); ok {
+				graph.Link(from, Node{"Conditions", c})
+			}
+
+			for _, c := range findIfs(e) {
+				graph.Link(from, Node{"Conditions", c})
+			}
+		}
+	}
+
+	return graph
+}
+
+// findIfs returns the names of the conditions used by Fn::If in the tree
+func findIfs(t map[string]interface{}) []string {
+	names := make([]string, 0)
+
+	for key, value := range t {
+		if key == "Fn::If" {
+			if args, ok := value.([]interface{}); ok && len(args) > 0 {
+				if name, ok := args[0].(string); ok {
+					names = append(names, name)
+				} else {
+					config.Debugf("Malformed If: %v", value)
+				}
+			}
+		}
+
+		for _, tree := range findTrees(value) {
+			names = append(names, findIfs(tree)...)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
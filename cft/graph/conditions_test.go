@@ -0,0 +1,82 @@
+package graph_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/graph"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+const conditionsTemplate = `
+Parameters:
+  Env:
+    Type: String
+  Replicas:
+    Type: Number
+Conditions:
+  IsProd: !Equals [!Ref Env, prod]
+  HasReplicas: !Not [!Equals [!Ref Replicas, 0]]
+  ReplicateProd: !And
+    - !Condition IsProd
+    - !Condition HasReplicas
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !If [IsProd, prod-bucket, !Ref AWS::NoValue]
+  Replica:
+    Type: AWS::S3::Bucket
+    Condition: ReplicateProd
+Outputs:
+  ReplicaName:
+    Condition: ReplicateProd
+    Value: !Ref Replica
+`
+
+func conditionsGraph() (graph.Graph, map[string]graph.Expr) {
+	t, err := parse.String(conditionsTemplate)
+	if err != nil {
+		panic(err)
+	}
+
+	return graph.NewConditions(t), graph.Conditions(t)
+}
+
+func Example_conditions() {
+	g, exprs := conditionsGraph()
+
+	fmt.Println(exprs["ReplicateProd"])
+	fmt.Println(exprs["HasReplicas"])
+	fmt.Println(g.Get(graph.Node{"Conditions", "ReplicateProd"}))
+	fmt.Println(g.GetReverse(graph.Node{"Conditions", "IsProd"}))
+	fmt.Println(g.GetReverse(graph.Node{"Conditions", "ReplicateProd"}))
+	// Output:
+	// And(Condition IsProd, Condition HasReplicas)
+	// Not(Equals(Ref Replicas, "0"))
+	// [Conditions/HasReplicas Conditions/IsProd]
+	// [Conditions/ReplicateProd Resources/Bucket]
+	// [Outputs/ReplicaName Resources/Replica]
+}
+
+func ExampleExpr_Lines() {
+	_, exprs := conditionsGraph()
+
+	fmt.Println(strings.Join(exprs["HasReplicas"].Lines(), "\n"))
+	// Output:
+	// Not
+	// └── Equals
+	//     ├── Ref Replicas
+	//     └── "0"
+}
+
+func ExampleConditionNames() {
+	t, err := parse.String(conditionsTemplate)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(graph.ConditionNames(t))
+	// Output:
+	// [IsProd HasReplicas ReplicateProd]
+}
@@ -80,3 +80,12 @@ func Example_getReverse() {
 	// [Outputs/BucketArn Outputs/BucketName]
 	// []
 }
+
+func Example_levels() {
+	for i, level := range g.Levels("Resources") {
+		fmt.Println(i, level)
+	}
+	// Output:
+	// 0 [Resources/LogBucket]
+	// 1 [Resources/Bucket]
+}
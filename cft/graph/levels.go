@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Levels groups the nodes of a type, e.g. Resources, by how deep their dependencies
+// on other nodes of the same type go. The first level has the nodes that don't depend on
+// any others, and each later level has the nodes that depend on the level before it.
+// The nodes in each level are sorted by name.
+func (g Graph) Levels(typeFilter string) [][]Node {
+	levelOf := make(map[Node]int)
+	visiting := make(map[Node]bool)
+
+	var level func(Node) int
+	level = func(n Node) int {
+		if l, ok := levelOf[n]; ok {
+			return l
+		}
+
+		// Templates shouldn't have cycles, but don't loop forever if they do
+		if visiting[n] {
+			return 0
+		}
+		visiting[n] = true
+
+		l := 0
+		for to := range g.nodes[n] {
+			if to.Type == typeFilter && to != n {
+				if d := level(to) + 1; d > l {
+					l = d
+				}
+			}
+		}
+
+		levelOf[n] = l
+
+		return l
+	}
+
+	levels := make([][]Node, 0)
+
+	for _, n := range g.order {
+		if n.Type != typeFilter {
+			continue
+		}
+
+		l := level(n)
+		for len(levels) <= l {
+			levels = append(levels, make([]Node, 0))
+		}
+
+		levels[l] = append(levels[l], n)
+	}
+
+	for _, nodes := range levels {
+		sort.Slice(nodes, func(i, j int) bool {
+			return fmt.Sprint(nodes[i]) < fmt.Sprint(nodes[j])
+		})
+	}
+
+	return levels
+}
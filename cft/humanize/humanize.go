@@ -0,0 +1,167 @@
+// Package humanize makes machine-generated templates, such as the output of
+// cdk synth or sam build, easier for people to review.
+//
+// It strips metadata that only the generating tool uses,
+// rewrites Fn::Join expressions as Fn::Sub where that is equivalent,
+// and moves elements whose names end in a generated hash
+// after the elements that were named by a person.
+package humanize
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/rewrite"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// noisyMetadata are the metadata keys that are only used by the tools that generate templates
+var noisyMetadata = []string{
+	"aws:cdk:path",
+	"aws:asset:path",
+	"aws:asset:property",
+	"aws:asset:is-bundled",
+	"aws:asset:dockerfile-path",
+	"aws:asset:docker-build-args",
+	"aws:asset:docker-build-target",
+	"SamResourceId",
+}
+
+// cdkMetadataType is the type of the resource CDK adds to every stack to collect usage data
+const cdkMetadataType = "AWS::CDK::Metadata"
+
+// hashSuffix matches the hashes CDK (8 upper case hex digits)
+// and SAM (10 lower case hex digits) append to generated names
+var hashSuffix = regexp.MustCompile(`^(.+?)([0-9A-F]{8}|[0-9a-f]{10})$`)
+
+// HasHash returns true if name ends in a generated hash
+func HasHash(name string) bool {
+	m := hashSuffix.FindStringSubmatch(name)
+	if m == nil {
+		return false
+	}
+
+	// A hash almost certainly contains at least one digit;
+	// this avoids treating words like "DEADBEEF" as hashes
+	return strings.ContainsAny(m[2], "0123456789")
+}
+
+// Template rewrites t in place so that it is easier to read
+func Template(t cft.Template) {
+	stripMetadata(t)
+	rewrite.Template(t, rewrite.Sub)
+
+	for _, section := range []cft.Section{cft.Parameters, cft.Resources, cft.Outputs} {
+		if s, err := t.GetSection(section); err == nil {
+			hashesLast(s)
+		}
+	}
+}
+
+// stripMetadata removes CDK and SAM metadata from resources,
+// and the CDKMetadata resource along with its condition
+func stripMetadata(t cft.Template) {
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil {
+		return
+	}
+
+	conditions := make(map[string]bool)
+
+	for i := len(resources.Content) - 2; i >= 0; i -= 2 {
+		name := resources.Content[i].Value
+		resource := resources.Content[i+1]
+
+		_, typ, _ := s11n.GetMapValue(resource, "Type")
+		if typ != nil && typ.Value == cdkMetadataType {
+			if _, c, _ := s11n.GetMapValue(resource, "Condition"); c != nil {
+				conditions[c.Value] = true
+			}
+			node.RemoveFromMap(resources, name)
+			continue
+		}
+
+		_, metadata, _ := s11n.GetMapValue(resource, "Metadata")
+		if metadata == nil || metadata.Kind != yaml.MappingNode {
+			continue
+		}
+
+		for _, key := range noisyMetadata {
+			node.RemoveFromMap(metadata, key)
+		}
+
+		if len(metadata.Content) == 0 {
+			node.RemoveFromMap(resource, "Metadata")
+		}
+	}
+
+	// Only remove the condition if nothing else uses it
+	for name := range conditions {
+		if !conditionUsed(t, name) {
+			if section, err := t.GetSection(cft.Conditions); err == nil {
+				node.RemoveFromMap(section, name)
+				if len(section.Content) == 0 {
+					node.RemoveFromMap(t.Node.Content[0], string(cft.Conditions))
+				}
+			}
+		}
+	}
+}
+
+// conditionUsed returns true if anything in the template refers to the named condition
+func conditionUsed(t cft.Template, name string) bool {
+	var used func(n *yaml.Node) bool
+	used = func(n *yaml.Node) bool {
+		if n.Kind == yaml.MappingNode {
+			for i := 0; i < len(n.Content)-1; i += 2 {
+				key, value := n.Content[i], n.Content[i+1]
+				if (key.Value == "Condition" || key.Value == "Fn::If") && refersTo(value, name) {
+					return true
+				}
+			}
+		}
+
+		for _, child := range n.Content {
+			if used(child) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return used(t.Node)
+}
+
+// refersTo returns true if n is, or is a sequence starting with, the named condition
+func refersTo(n *yaml.Node, name string) bool {
+	if n.Kind == yaml.ScalarNode {
+		return n.Value == name
+	}
+
+	return n.Kind == yaml.SequenceNode && len(n.Content) > 0 && n.Content[0].Value == name
+}
+
+// hashesLast moves the elements of a mapping whose names end in a hash
+// after the other elements, keeping the order within each group
+func hashesLast(m *yaml.Node) {
+	if m.Kind != yaml.MappingNode {
+		return
+	}
+
+	named := make([]*yaml.Node, 0, len(m.Content))
+	hashed := make([]*yaml.Node, 0)
+
+	for i := 0; i < len(m.Content)-1; i += 2 {
+		if HasHash(m.Content[i].Value) {
+			hashed = append(hashed, m.Content[i], m.Content[i+1])
+		} else {
+			named = append(named, m.Content[i], m.Content[i+1])
+		}
+	}
+
+	m.Content = append(named, hashed...)
+}
@@ -0,0 +1,89 @@
+package humanize_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/humanize"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+const generated = `
+Conditions:
+  CDKMetadataAvailable: !Equals [!Ref AWS::Region, us-east-1]
+Resources:
+  MyBucketF68F3FF0:
+    Type: AWS::S3::Bucket
+    Metadata:
+      aws:cdk:path: Stack/MyBucket/Resource
+  Role:
+    Type: AWS::IAM::Role
+    Metadata:
+      aws:cdk:path: Stack/Role/Resource
+      Owner: me
+    Properties:
+      Path: !Join
+        - ""
+        - - "/"
+          - !Ref AWS::StackName
+          - "-"
+          - !GetAtt MyBucketF68F3FF0.Arn
+          - "/${literal}/"
+      Description: !Join [",", [a, !Select [0, !GetAZs ""]]]
+  CDKMetadata:
+    Type: AWS::CDK::Metadata
+    Condition: CDKMetadataAvailable
+    Properties:
+      Analytics: v2:deflate64:H4sIAAAAAAAA
+`
+
+const expected = `Resources:
+  Role:
+    Type: AWS::IAM::Role
+    Metadata:
+      Owner: me
+    Properties:
+      Path: !Sub /${AWS::StackName}-${MyBucketF68F3FF0.Arn}/${!literal}/
+      Description: !Join
+        - ','
+        - - a
+          - !Select
+            - 0
+            - !GetAZs
+
+  MyBucketF68F3FF0:
+    Type: AWS::S3::Bucket
+`
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := parse.String(generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	humanize.Template(tmpl)
+
+	actual := format.String(tmpl, format.Options{})
+
+	if d := cmp.Diff(strings.TrimSpace(expected), strings.TrimSpace(actual)); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestHasHash(t *testing.T) {
+	cases := map[string]bool{
+		"MyBucketF68F3FF0":                      true,
+		"ServerlessRestApiDeployment47fc2d5f9d": true,
+		"MyBucket":                              false,
+		"F68F3FF0":                              false,
+		"CacheDEADBEEF":                         false,
+	}
+
+	for name, expected := range cases {
+		if humanize.HasHash(name) != expected {
+			t.Errorf("%s: expected %t", name, expected)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+// Package iam analyzes the IAM policy documents in a template without calling AWS:
+// the inline policies of roles, users and groups, managed policies,
+// the trust policies of roles, and the resource policies of buckets, queues, topics, keys and others.
+//
+// It looks for statements that allow more than they probably should:
+// wildcards in actions and resources, public principals,
+// and trust policies that let other accounts or identity providers assume a role without conditions.
+package iam
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/sarif"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity indicates how serious a finding is
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Info    Severity = "info"
+)
+
+// Kind is the kind of a policy document, which decides what is checked
+type Kind string
+
+const (
+	// Identity policies are attached to roles, users and groups
+	Identity Kind = "identity"
+
+	// ResourcePolicy policies are attached to resources such as buckets and queues
+	ResourcePolicy Kind = "resource"
+
+	// Trust policies decide who can assume a role
+	Trust Kind = "trust"
+)
+
+// Check is a group of related findings
+type Check struct {
+	Name        string
+	Description string
+}
+
+// Checks are the checks that Analyze runs
+var Checks = []Check{
+	{"iam-wildcard-actions", "IAM policy statements don't allow every action, or every action of a service"},
+	{"iam-wildcard-resources", "Identity policy statements that allow changes name the resources they apply to instead of *"},
+	{"iam-public-principals", "Resource and trust policies don't allow anyone (Principal *) without a condition"},
+	{"iam-trust-conditions", "Roles that other accounts or identity providers can assume have conditions, such as sts:ExternalId"},
+}
+
+// Document is a policy document in the template
+type Document struct {
+	// Resource is the logical ID of the resource that has the policy
+	Resource string
+
+	// Path is where the document is in the resource, e.g. "Properties/Policies/0/PolicyDocument"
+	Path string
+
+	Kind Kind
+	Node *yaml.Node
+}
+
+// Finding is a problem with a statement in a policy document
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Resource string   `json:"resource"`
+	Path     string   `json:"path"`
+
+	// Statement is the statement's Sid, or its position in the document, starting at 1
+	Statement string `json:"statement"`
+
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s %s, statement %s: %s", f.Resource, f.Path, f.Statement, f.Message)
+}
+
+// properties are the properties that hold policy documents for each resource type.
+// "Policies" is a list of inline policies, each with a PolicyDocument.
+var properties = map[string]map[string]Kind{
+	"AWS::IAM::Role":                      {"AssumeRolePolicyDocument": Trust, "Policies": Identity},
+	"AWS::IAM::User":                      {"Policies": Identity},
+	"AWS::IAM::Group":                     {"Policies": Identity},
+	"AWS::IAM::Policy":                    {"PolicyDocument": Identity},
+	"AWS::IAM::ManagedPolicy":             {"PolicyDocument": Identity},
+	"AWS::IAM::RolePolicy":                {"PolicyDocument": Identity},
+	"AWS::IAM::UserPolicy":                {"PolicyDocument": Identity},
+	"AWS::IAM::GroupPolicy":               {"PolicyDocument": Identity},
+	"AWS::S3::BucketPolicy":               {"PolicyDocument": ResourcePolicy},
+	"AWS::SQS::QueuePolicy":               {"PolicyDocument": ResourcePolicy},
+	"AWS::SNS::TopicPolicy":               {"PolicyDocument": ResourcePolicy},
+	"AWS::KMS::Key":                       {"KeyPolicy": ResourcePolicy},
+	"AWS::SecretsManager::ResourcePolicy": {"ResourcePolicy": ResourcePolicy},
+	"AWS::ECR::Repository":                {"RepositoryPolicyText": ResourcePolicy},
+}
+
+// Documents returns the policy documents in the template
+func Documents(t cft.Template) []Document {
+	docs := make([]Document, 0)
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil || resources.Kind != yaml.MappingNode {
+		return docs
+	}
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		name, resource := resources.Content[i].Value, resources.Content[i+1]
+
+		_, typ, _ := s11n.GetMapValue(resource, "Type")
+		_, props, _ := s11n.GetMapValue(resource, "Properties")
+		if typ == nil || props == nil {
+			continue
+		}
+
+		kinds, ok := properties[typ.Value]
+		if !ok {
+			continue
+		}
+
+		// Visit the properties in order so that the documents are too
+		for j := 0; j < len(props.Content)-1; j += 2 {
+			prop, value := props.Content[j].Value, props.Content[j+1]
+			kind, ok := kinds[prop]
+			if !ok {
+				continue
+			}
+
+			if prop != "Policies" {
+				docs = append(docs, Document{Resource: name, Path: "Properties/" + prop, Kind: kind, Node: document(value)})
+				continue
+			}
+
+			if value.Kind != yaml.SequenceNode {
+				continue
+			}
+
+			for k, policy := range value.Content {
+				if _, doc, _ := s11n.GetMapValue(policy, "PolicyDocument"); doc != nil {
+					path := fmt.Sprintf("Properties/Policies/%d/PolicyDocument", k)
+					docs = append(docs, Document{Resource: name, Path: path, Kind: kind, Node: document(doc)})
+				}
+			}
+		}
+	}
+
+	return docs
+}
+
+// document returns a policy document, decoding it if it is a JSON string
+func document(n *yaml.Node) *yaml.Node {
+	if n.Kind != yaml.ScalarNode {
+		return n
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(n.Value), &doc); err != nil || len(doc.Content) == 0 {
+		return n
+	}
+
+	// Findings in the decoded document are reported on the line of the string
+	decoded := doc.Content[0]
+	setLine(decoded, n.Line)
+
+	return decoded
+}
+
+func setLine(n *yaml.Node, line int) {
+	n.Line = line
+	for _, child := range n.Content {
+		setLine(child, line)
+	}
+}
+
+// Analyze checks every policy document in the template and returns the findings,
+// with the most serious first
+func Analyze(t cft.Template) []Finding {
+	findings := make([]Finding, 0)
+
+	for _, doc := range Documents(t) {
+		findings = append(findings, doc.Analyze()...)
+	}
+
+	rank := map[Severity]int{Error: 0, Warning: 1, Info: 2}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return rank[findings[i].Severity] < rank[findings[j].Severity]
+	})
+
+	return findings
+}
+
+// Analyze checks the statements in the policy document
+func (doc Document) Analyze() []Finding {
+	findings := make([]Finding, 0)
+
+	_, statements, _ := s11n.GetMapValue(doc.Node, "Statement")
+	if statements == nil {
+		return findings
+	}
+
+	list := []*yaml.Node{statements}
+	if statements.Kind == yaml.SequenceNode {
+		list = statements.Content
+	}
+
+	for i, s := range list {
+		if s.Kind != yaml.MappingNode {
+			continue
+		}
+
+		id := fmt.Sprint(i + 1)
+		if sid := scalar(s, "Sid"); sid != "" {
+			id = sid
+		}
+
+		for _, f := range checkStatement(doc.Kind, s) {
+			f.Resource = doc.Resource
+			f.Path = doc.Path
+			f.Statement = id
+			f.Line = s.Line
+			findings = append(findings, f)
+		}
+	}
+
+	return findings
+}
+
+// checkStatement checks a single statement
+func checkStatement(kind Kind, s *yaml.Node) []Finding {
+	findings := make([]Finding, 0)
+
+	add := func(check string, severity Severity, format string, args ...any) {
+		findings = append(findings, Finding{Check: check, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Deny statements can't grant too much
+	if scalar(s, "Effect") != "Allow" {
+		return findings
+	}
+
+	actions := strs(s, "Action")
+	_, conditions, _ := s11n.GetMapValue(s, "Condition")
+
+	if has(s, "NotAction") {
+		add("iam-wildcard-actions", Warning, "Allow with NotAction allows every action that isn't listed, including ones added in future")
+	}
+
+	for _, action := range actions {
+		switch {
+		case action == "*":
+			add("iam-wildcard-actions", Error, "allows every action")
+		case strings.HasSuffix(action, ":*"):
+			// Resource policies only apply to their own resource, like the default key policy's kms:*
+			severity := Warning
+			if kind == ResourcePolicy {
+				severity = Info
+			}
+			add("iam-wildcard-actions", severity, "allows every %s action", strings.TrimSuffix(action, ":*"))
+		}
+	}
+
+	if kind == Identity {
+		if has(s, "NotResource") {
+			add("iam-wildcard-resources", Warning, "Allow with NotResource applies to every resource that isn't listed")
+		}
+
+		for _, resource := range strs(s, "Resource") {
+			if resource != "*" {
+				continue
+			}
+			if readOnly(actions) {
+				add("iam-wildcard-resources", Info, "applies to every resource, which some read-only actions need")
+			} else {
+				add("iam-wildcard-resources", Warning, "applies to every resource; name the resources the actions need")
+			}
+		}
+	}
+
+	if kind == Identity {
+		return findings
+	}
+
+	_, principal, _ := s11n.GetMapValue(s, "Principal")
+	if principal == nil {
+		return findings
+	}
+
+	public := principal.Kind == yaml.ScalarNode && principal.Value == "*"
+	for _, p := range strs(principal, "AWS") {
+		if p == "*" {
+			public = true
+		}
+	}
+
+	if public {
+		if conditions == nil {
+			add("iam-public-principals", Error, "allows anyone (Principal *) with no condition")
+		} else {
+			add("iam-public-principals", Info, "allows any principal that meets its conditions; check that they limit access as you expect")
+		}
+	}
+
+	if kind != Trust || conditions != nil || public || !assumesRole(actions) {
+		return findings
+	}
+
+	if has(principal, "Federated") {
+		add("iam-trust-conditions", Error, "lets any identity from %s assume the role; add conditions on the provider's claims, such as the audience and subject",
+			describe(principal, "Federated", "the identity provider"))
+	}
+
+	if has(principal, "AWS") {
+		add("iam-trust-conditions", Warning, "lets %s assume the role with no condition; add one such as sts:ExternalId or aws:PrincipalOrgID",
+			describe(principal, "AWS", "the AWS principals"))
+	}
+
+	return findings
+}
+
+// readOnly returns true if every action only reads
+func readOnly(actions []string) bool {
+	if len(actions) == 0 {
+		return false
+	}
+
+	for _, action := range actions {
+		_, name, _ := strings.Cut(action, ":")
+		if !strings.HasPrefix(name, "Describe") && !strings.HasPrefix(name, "List") && !strings.HasPrefix(name, "Get") {
+			return false
+		}
+	}
+
+	return true
+}
+
+// assumesRole returns true if the actions include assuming a role
+func assumesRole(actions []string) bool {
+	for _, action := range actions {
+		if action == "*" || action == "sts:*" || strings.HasPrefix(action, "sts:AssumeRole") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// has returns true if the mapping has the key
+func has(n *yaml.Node, key string) bool {
+	_, v, _ := s11n.GetMapValue(n, key)
+	return v != nil
+}
+
+// scalar returns the value of a key in a mapping, if it is a plain value
+func scalar(n *yaml.Node, key string) string {
+	_, v, _ := s11n.GetMapValue(n, key)
+	if v == nil || v.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return v.Value
+}
+
+// strs returns the plain values of a key that is a string or a list of strings,
+// ignoring values that are intrinsic functions
+func strs(n *yaml.Node, key string) []string {
+	out := make([]string, 0)
+
+	_, v, _ := s11n.GetMapValue(n, key)
+	if v == nil {
+		return out
+	}
+
+	items := []*yaml.Node{v}
+	if v.Kind == yaml.SequenceNode {
+		items = v.Content
+	}
+
+	for _, item := range items {
+		if item.Kind == yaml.ScalarNode {
+			out = append(out, item.Value)
+		}
+	}
+
+	return out
+}
+
+// describe returns the principals of a key for a message, or fallback if they are intrinsic functions
+func describe(principal *yaml.Node, key string, fallback string) string {
+	values := strs(principal, key)
+	if len(values) == 0 {
+		return fallback
+	}
+	return strings.Join(values, ", ")
+}
+
+// SARIF returns the findings for the template in the file as a SARIF 2.1.0 log
+func SARIF(fn string, findings []Finding) ([]byte, error) {
+	rules := make([]sarif.Rule, 0, len(Checks))
+	for _, c := range Checks {
+		rules = append(rules, sarif.Rule{Id: c.Name, Description: c.Description})
+	}
+
+	results := make([]sarif.Result, 0, len(findings))
+	for _, f := range findings {
+		results = append(results, sarif.Result{
+			Rule:    f.Check,
+			Level:   sarif.LevelOf(string(f.Severity)),
+			Message: fmt.Sprintf("statement %s: %s", f.Statement, f.Message),
+			File:    fn,
+			Line:    f.Line,
+			Element: fmt.Sprintf("%s/%s/%s", cft.Resources, f.Resource, f.Path),
+		})
+	}
+
+	return sarif.Log(rules, results)
+}
@@ -0,0 +1,96 @@
+package iam_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/iam"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+const source = `
+Resources:
+  Role:
+    Type: AWS::IAM::Role
+    Properties:
+      AssumeRolePolicyDocument:
+        Statement:
+          - Effect: Allow
+            Principal:
+              Service: lambda.amazonaws.com
+            Action: sts:AssumeRole
+          - Sid: Partner
+            Effect: Allow
+            Principal:
+              AWS: arn:aws:iam::111122223333:root
+            Action: sts:AssumeRole
+          - Sid: GitHub
+            Effect: Allow
+            Principal:
+              Federated: !Sub arn:aws:iam::${AWS::AccountId}:oidc-provider/token.actions.githubusercontent.com
+            Action: sts:AssumeRoleWithWebIdentity
+      Policies:
+        - PolicyName: app
+          PolicyDocument:
+            Statement:
+              - Effect: Allow
+                Action: "*"
+                Resource: "*"
+              - Effect: Allow
+                Action:
+                  - ec2:DescribeInstances
+                Resource: "*"
+              - Effect: Allow
+                Action: s3:*
+                Resource: !Sub ${Bucket.Arn}/*
+              - Effect: Deny
+                Action: "*"
+                Resource: "*"
+  BucketPolicy:
+    Type: AWS::S3::BucketPolicy
+    Properties:
+      Bucket: !Ref Bucket
+      PolicyDocument: '{"Statement": [{"Effect": "Allow", "Principal": "*", "Action": "s3:GetObject", "Resource": "*"}]}'
+  QueuePolicy:
+    Type: AWS::SQS::QueuePolicy
+    Properties:
+      Queues: [!Ref Queue]
+      PolicyDocument:
+        Statement:
+          Effect: Allow
+          Principal:
+            AWS: "*"
+          Action: sqs:SendMessage
+          Resource: "*"
+          Condition:
+            ArnEquals:
+              aws:SourceArn: !Ref Topic
+`
+
+func TestAnalyze(t *testing.T) {
+	tmpl, err := parse.String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0)
+	for _, f := range iam.Analyze(tmpl) {
+		actual = append(actual, fmt.Sprintf("%s %d %s [%s]", f.Severity, f.Line, f, f.Check))
+	}
+
+	expected := []string{
+		"error 17 Role Properties/AssumeRolePolicyDocument, statement GitHub: lets any identity from the identity provider assume the role; add conditions on the provider's claims, such as the audience and subject [iam-trust-conditions]",
+		"error 26 Role Properties/Policies/0/PolicyDocument, statement 1: allows every action [iam-wildcard-actions]",
+		"error 43 BucketPolicy Properties/PolicyDocument, statement 1: allows anyone (Principal *) with no condition [iam-public-principals]",
+		"warning 12 Role Properties/AssumeRolePolicyDocument, statement Partner: lets arn:aws:iam::111122223333:root assume the role with no condition; add one such as sts:ExternalId or aws:PrincipalOrgID [iam-trust-conditions]",
+		"warning 26 Role Properties/Policies/0/PolicyDocument, statement 1: applies to every resource; name the resources the actions need [iam-wildcard-resources]",
+		"warning 33 Role Properties/Policies/0/PolicyDocument, statement 3: allows every s3 action [iam-wildcard-actions]",
+		"info 29 Role Properties/Policies/0/PolicyDocument, statement 2: applies to every resource, which some read-only actions need [iam-wildcard-resources]",
+		"info 50 QueuePolicy Properties/PolicyDocument, statement 1: allows any principal that meets its conditions; check that they limit access as you expect [iam-public-principals]",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
@@ -0,0 +1,224 @@
+// Package langext expands the parts of the AWS::LanguageExtensions transform that can be
+// evaluated without deploying the template, so that Fn::ForEach loops can be linted and diffed
+// as the resources they create.
+//
+// Loops can be in Resources, Outputs, Conditions or any mapping inside them:
+//
+//	Fn::ForEach::Topics:
+//	  - TopicName
+//	  - [Orders, Payments]
+//	  - Topic${TopicName}:
+//	      Type: AWS::SNS::Topic
+//	      Properties:
+//	        TopicName: !Ref TopicName
+//
+// The collection must be a list, or a Ref to a list parameter with a known value.
+// In the output, ${Identifier} and &{Identifier} in keys and in Fn::Sub strings,
+// and Refs to the identifier, are replaced with each item in turn.
+// Fn::Length of a known list is replaced with its length.
+package langext
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"gopkg.in/yaml.v3"
+)
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]`)
+
+// Expand returns a copy of the template with its Fn::ForEach loops expanded.
+// params sets the values of list parameters that loops use as collections;
+// parameters that aren't in params use their defaults.
+func Expand(t cft.Template, params map[string]string) (cft.Template, error) {
+	out := cft.Template{Node: node.Clone(t.Node)}
+
+	lists := listParameters(out, params)
+
+	if err := expand(out.Node, lists); err != nil {
+		return t, err
+	}
+
+	return out, nil
+}
+
+// listParameters returns the values of the template's parameters as lists
+func listParameters(t cft.Template, params map[string]string) map[string][]string {
+	values := make(map[string]string)
+
+	if parameters, err := t.GetSection(cft.Parameters); err == nil && parameters.Kind == yaml.MappingNode {
+		for i := 0; i < len(parameters.Content)-1; i += 2 {
+			p := parameters.Content[i+1]
+			if p.Kind != yaml.MappingNode {
+				continue
+			}
+
+			for j := 0; j < len(p.Content)-1; j += 2 {
+				if p.Content[j].Value == "Default" && p.Content[j+1].Kind == yaml.ScalarNode {
+					values[parameters.Content[i].Value] = p.Content[j+1].Value
+				}
+			}
+		}
+	}
+
+	for k, v := range params {
+		values[k] = v
+	}
+
+	lists := make(map[string][]string)
+	for k, v := range values {
+		items := strings.Split(v, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		lists[k] = items
+	}
+
+	return lists
+}
+
+// expand replaces the loops in n and its children
+func expand(n *yaml.Node, lists map[string][]string) error {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i < len(n.Content)-1; {
+			key := n.Content[i].Value
+			if !cft.IsForEach(key) {
+				i += 2
+				continue
+			}
+
+			pairs, err := expandLoop(key, n.Content[i+1], lists)
+			if err != nil {
+				return err
+			}
+
+			for j := 0; j < len(pairs); j += 2 {
+				for k := 0; k < len(n.Content)-1; k += 2 {
+					if k != i && n.Content[k].Value == pairs[j].Value {
+						return fmt.Errorf("%s creates %s, which already exists", key, pairs[j].Value)
+					}
+				}
+			}
+
+			// Replace the loop with its output, which is checked again for nested loops
+			content := append([]*yaml.Node{}, n.Content[:i]...)
+			content = append(content, pairs...)
+			n.Content = append(content, n.Content[i+2:]...)
+		}
+	}
+
+	for _, child := range n.Content {
+		if err := expand(child, lists); err != nil {
+			return err
+		}
+	}
+
+	// Evaluate Fn::Length once any loops inside it have been expanded
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 && n.Content[0].Value == "Fn::Length" {
+		if items, ok := collection(n.Content[1], lists); ok {
+			*n = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(len(items))}
+		}
+	}
+
+	return nil
+}
+
+// collection returns the items of a literal list or a Ref to a known list parameter
+func collection(n *yaml.Node, lists map[string][]string) ([]string, bool) {
+	switch n.Kind {
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(n.Content))
+		for _, item := range n.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, false
+			}
+			items = append(items, item.Value)
+		}
+		return items, true
+	case yaml.MappingNode:
+		if len(n.Content) == 2 && n.Content[0].Value == "Ref" {
+			items, ok := lists[n.Content[1].Value]
+			return items, ok
+		}
+	}
+
+	return nil, false
+}
+
+// expandLoop returns the keys and values that a loop creates
+func expandLoop(key string, loop *yaml.Node, lists map[string][]string) ([]*yaml.Node, error) {
+	if loop.Kind != yaml.SequenceNode || len(loop.Content) != 3 {
+		return nil, fmt.Errorf("%s must be a list of an identifier, a collection and an output", key)
+	}
+
+	identifier := loop.Content[0]
+	if identifier.Kind != yaml.ScalarNode || identifier.Value == "" {
+		return nil, fmt.Errorf("%s must have an identifier", key)
+	}
+
+	items, ok := collection(loop.Content[1], lists)
+	if !ok {
+		return nil, fmt.Errorf("unable to expand %s: its collection must be a list or a Ref to a list parameter with a value", key)
+	}
+
+	output := loop.Content[2]
+	if output.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s must have a mapping as its output", key)
+	}
+
+	pairs := make([]*yaml.Node, 0)
+	for _, item := range items {
+		for i := 0; i < len(output.Content)-1; i += 2 {
+			k := node.Clone(output.Content[i])
+			k.Value = replace(k.Value, identifier.Value, item)
+
+			v := node.Clone(output.Content[i+1])
+			substitute(v, identifier.Value, item)
+
+			pairs = append(pairs, k, v)
+		}
+	}
+
+	return pairs, nil
+}
+
+// replace replaces ${identifier} and &{identifier} in s
+func replace(s string, identifier string, item string) string {
+	s = strings.ReplaceAll(s, "${"+identifier+"}", item)
+	return strings.ReplaceAll(s, "&{"+identifier+"}", nonAlphanumeric.ReplaceAllString(item, ""))
+}
+
+// substitute replaces the identifier in the keys, Fn::Sub strings and Refs of n
+func substitute(n *yaml.Node, identifier string, item string) {
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 {
+		switch n.Content[0].Value {
+		case "Ref":
+			if n.Content[1].Kind == yaml.ScalarNode && n.Content[1].Value == identifier {
+				*n = yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: item}
+				return
+			}
+		case "Fn::Sub":
+			sub := n.Content[1]
+			if sub.Kind == yaml.SequenceNode && len(sub.Content) > 0 {
+				sub = sub.Content[0]
+			}
+			if sub.Kind == yaml.ScalarNode {
+				sub.Value = replace(sub.Value, identifier, item)
+			}
+		}
+	}
+
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i < len(n.Content)-1; i += 2 {
+			n.Content[i].Value = replace(n.Content[i].Value, identifier, item)
+		}
+	}
+
+	for _, child := range n.Content {
+		substitute(child, identifier, item)
+	}
+}
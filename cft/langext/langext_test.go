@@ -0,0 +1,119 @@
+package langext_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/langext"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestExpand(t *testing.T) {
+	source, err := parse.String(`
+Transform: AWS::LanguageExtensions
+Parameters:
+  Environments:
+    Type: CommaDelimitedList
+    Default: dev, prod
+Resources:
+  Fn::ForEach::Environments:
+    - Env
+    - !Ref Environments
+    - Fn::ForEach::Topics:
+        - TopicName
+        - [order-events, Payments]
+        - Topic&{TopicName}${Env}:
+            Type: AWS::SNS::Topic
+            Properties:
+              TopicName: !Sub ${Env}-${TopicName}
+              DisplayName: !Ref TopicName
+Outputs:
+  Count:
+    Value: !Length [a, b, c]
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expanded, err := langext.Expand(source, map[string]string{"Environments": "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Transform: AWS::LanguageExtensions
+
+Parameters:
+  Environments:
+    Type: CommaDelimitedList
+    Default: dev, prod
+
+Resources:
+  Topicordereventstest:
+    Type: AWS::SNS::Topic
+    Properties:
+      TopicName: !Sub test-order-events
+      DisplayName: order-events
+
+  TopicPaymentstest:
+    Type: AWS::SNS::Topic
+    Properties:
+      TopicName: !Sub test-Payments
+      DisplayName: Payments
+
+Outputs:
+  Count:
+    Value: 3
+`
+
+	actual := format.String(expanded, format.Options{Unsorted: true})
+	if d := cmp.Diff(expected, actual); d != "" {
+		t.Error(d)
+	}
+
+	// The original template is unchanged
+	if !strings.Contains(format.String(source, format.Options{Unsorted: true}), "Fn::ForEach::Environments") {
+		t.Error("Expand modified its input")
+	}
+}
+
+func TestExpandErrors(t *testing.T) {
+	cases := map[string]string{
+		"unknown collection": `
+Resources:
+  Fn::ForEach::Topics:
+    - Name
+    - !Ref Unknown
+    - Topic${Name}:
+        Type: AWS::SNS::Topic
+`,
+		"duplicate key": `
+Resources:
+  TopicA:
+    Type: AWS::SNS::Topic
+  Fn::ForEach::Topics:
+    - Name
+    - [A]
+    - Topic${Name}:
+        Type: AWS::SNS::Topic
+`,
+		"malformed loop": `
+Resources:
+  Fn::ForEach::Topics:
+    - Name
+    - [A]
+`,
+	}
+
+	for name, input := range cases {
+		source, err := parse.String(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := langext.Expand(source, nil); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
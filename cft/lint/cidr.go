@@ -0,0 +1,351 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/eval"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	register(Rule{
+		Name:        "cidr",
+		Description: "VPC and subnet CIDR blocks are valid, fit in their VPC, and do not overlap, using parameter defaults",
+		Check:       checkCidrs,
+	})
+}
+
+// errUnknown means that the value of a CIDR block can't be known before deployment
+var errUnknown = errors.New("unknown")
+
+// maxDepth stops resolveCidr following references forever
+const maxDepth = 10
+
+// subnet is a subnet whose CIDR block is known
+type subnet struct {
+	name  string
+	block netip.Prefix
+}
+
+func checkCidrs(t cft.Template, opts Options) []Finding {
+	findings := make([]Finding, 0)
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil {
+		return findings
+	}
+
+	// Every CIDR block of each VPC in the template
+	vpcs := make(map[string][]netip.Prefix)
+
+	// Subnets, grouped by their VpcId
+	subnets := make(map[string][]subnet)
+	vpcIds := make([]string, 0)
+
+	// inTemplate is the VPC in the template each group of subnets belongs to
+	inTemplate := make(map[string]string)
+
+	resolve := func(name string, props *yaml.Node, prop string) (netip.Prefix, bool) {
+		_, n, _ := s11n.GetMapValue(props, prop)
+		if n == nil {
+			return netip.Prefix{}, false
+		}
+
+		block, err := resolveCidr(t, n, 0)
+		if err != nil {
+			if err != errUnknown {
+				findings = append(findings, Finding{
+					Severity: Error,
+					Element:  fmt.Sprintf("Resources/%s", name),
+					Message:  fmt.Sprintf("invalid %s: %s", prop, err),
+				})
+			}
+			return netip.Prefix{}, false
+		}
+
+		return block, true
+	}
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		name := resources.Content[i].Value
+		resource := resources.Content[i+1]
+
+		_, typ, _ := s11n.GetMapValue(resource, "Type")
+		_, props, _ := s11n.GetMapValue(resource, "Properties")
+		if typ == nil || props == nil {
+			continue
+		}
+
+		switch typ.Value {
+		case "AWS::EC2::VPC":
+			if block, ok := resolve(name, props, "CidrBlock"); ok {
+				vpcs[name] = append(vpcs[name], block)
+			}
+		case "AWS::EC2::VPCCidrBlock":
+			_, vpcId, _ := s11n.GetMapValue(props, "VpcId")
+			if block, ok := resolve(name, props, "CidrBlock"); ok && vpcId != nil {
+				if vpc, ok := refersToVpc(t, vpcId); ok {
+					vpcs[vpc] = append(vpcs[vpc], block)
+				}
+			}
+		case "AWS::EC2::Subnet":
+			resolve(name, props, "Ipv6CidrBlock")
+
+			block, ok := resolve(name, props, "CidrBlock")
+			if !ok {
+				continue
+			}
+
+			if block.Addr().Is4() && (block.Bits() < 16 || block.Bits() > 28) {
+				findings = append(findings, Finding{
+					Severity: Error,
+					Element:  fmt.Sprintf("Resources/%s", name),
+					Message:  fmt.Sprintf("subnet CIDR block %s must be between /16 and /28", block),
+				})
+			}
+
+			_, vpcId, _ := s11n.GetMapValue(props, "VpcId")
+			if vpcId == nil {
+				continue
+			}
+
+			id := node.ToSJson(vpcId)
+			if _, ok := subnets[id]; !ok {
+				vpcIds = append(vpcIds, id)
+			}
+			subnets[id] = append(subnets[id], subnet{name: name, block: block})
+
+			if vpc, ok := refersToVpc(t, vpcId); ok {
+				inTemplate[id] = vpc
+			}
+		}
+	}
+
+	for _, id := range vpcIds {
+		group := subnets[id]
+
+		for i, s := range group {
+			element := fmt.Sprintf("Resources/%s", s.name)
+
+			if vpc, ok := inTemplate[id]; ok && len(vpcs[vpc]) > 0 && !containedBy(s.block, vpcs[vpc]) {
+				findings = append(findings, Finding{
+					Severity: Error,
+					Element:  element,
+					Message:  fmt.Sprintf("subnet CIDR block %s is outside the CIDR blocks of %s (%s)", s.block, vpc, joinPrefixes(vpcs[vpc])),
+				})
+			}
+
+			for _, other := range group[:i] {
+				if s.block.Overlaps(other.block) {
+					findings = append(findings, Finding{
+						Severity: Error,
+						Element:  element,
+						Message:  fmt.Sprintf("subnet CIDR block %s overlaps with %s (%s)", s.block, other.name, other.block),
+					})
+				}
+			}
+		}
+	}
+
+	return findings
+}
+
+// containedBy returns true if block is entirely inside one of the blocks in parents
+func containedBy(block netip.Prefix, parents []netip.Prefix) bool {
+	for _, p := range parents {
+		if p.Bits() <= block.Bits() && p.Contains(block.Addr()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinPrefixes(prefixes []netip.Prefix) string {
+	s := make([]string, len(prefixes))
+	for i, p := range prefixes {
+		s[i] = p.String()
+	}
+
+	return strings.Join(s, ", ")
+}
+
+// isVpc returns true if the template has a VPC with the logical id name
+func isVpc(t cft.Template, name string) bool {
+	resource, err := t.GetResource(name)
+	if err != nil {
+		return false
+	}
+
+	_, typ, _ := s11n.GetMapValue(resource, "Type")
+
+	return typ != nil && typ.Value == "AWS::EC2::VPC"
+}
+
+// refersToVpc returns the name of the VPC in the template that n refers to
+func refersToVpc(t cft.Template, n *yaml.Node) (string, bool) {
+	if n.Kind != yaml.MappingNode || len(n.Content) != 2 || n.Content[0].Value != "Ref" {
+		return "", false
+	}
+
+	name := n.Content[1].Value
+
+	return name, isVpc(t, name)
+}
+
+// resolveCidr returns the CIDR block n will have if the template
+// is deployed with the default values of its parameters
+func resolveCidr(t cft.Template, n *yaml.Node, depth int) (netip.Prefix, error) {
+	if depth > maxDepth {
+		return netip.Prefix{}, errUnknown
+	}
+
+	if n.Kind == yaml.ScalarNode {
+		return netip.ParsePrefix(n.Value)
+	}
+
+	if n.Kind != yaml.MappingNode || len(n.Content) != 2 {
+		return netip.Prefix{}, errUnknown
+	}
+
+	fn, arg := n.Content[0].Value, n.Content[1]
+
+	switch fn {
+	case "Ref":
+		value, err := parameterDefault(t, arg)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return resolveCidr(t, value, depth+1)
+
+	case "Fn::GetAtt":
+		var name, attr string
+		if arg.Kind == yaml.ScalarNode {
+			name, attr, _ = strings.Cut(arg.Value, ".")
+		} else if arg.Kind == yaml.SequenceNode && len(arg.Content) == 2 {
+			name, attr = arg.Content[0].Value, arg.Content[1].Value
+		}
+
+		if attr != "CidrBlock" || !isVpc(t, name) {
+			return netip.Prefix{}, errUnknown
+		}
+
+		resource, _ := t.GetResource(name)
+		_, props, _ := s11n.GetMapValue(resource, "Properties")
+		if props == nil {
+			return netip.Prefix{}, errUnknown
+		}
+
+		_, block, _ := s11n.GetMapValue(props, "CidrBlock")
+		if block == nil {
+			return netip.Prefix{}, errUnknown
+		}
+
+		return resolveCidr(t, block, depth+1)
+
+	case "Fn::Select":
+		if arg.Kind != yaml.SequenceNode || len(arg.Content) != 2 {
+			return netip.Prefix{}, errUnknown
+		}
+
+		index, err := resolveInt(t, arg.Content[0])
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+
+		list := arg.Content[1]
+		if list.Kind != yaml.MappingNode || len(list.Content) != 2 || list.Content[0].Value != "Fn::Cidr" {
+			return netip.Prefix{}, errUnknown
+		}
+
+		blocks, err := resolveCidrFn(t, list.Content[1], depth+1)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+
+		if index < 0 || index >= len(blocks) {
+			return netip.Prefix{}, fmt.Errorf("Fn::Select index %d is out of range for the %d blocks returned by Fn::Cidr", index, len(blocks))
+		}
+
+		return blocks[index], nil
+	}
+
+	return netip.Prefix{}, errUnknown
+}
+
+// resolveCidrFn returns the result of Fn::Cidr
+func resolveCidrFn(t cft.Template, arg *yaml.Node, depth int) ([]netip.Prefix, error) {
+	if arg.Kind != yaml.SequenceNode || len(arg.Content) != 3 {
+		return nil, errUnknown
+	}
+
+	ipBlock, err := resolveCidr(t, arg.Content[0], depth)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := resolveInt(t, arg.Content[1])
+	if err != nil {
+		return nil, err
+	}
+
+	cidrBits, err := resolveInt(t, arg.Content[2])
+	if err != nil {
+		return nil, err
+	}
+
+	blocks, err := eval.Cidr(ipBlock, count, cidrBits)
+	if err != nil {
+		return nil, fmt.Errorf("Fn::Cidr: %w", err)
+	}
+
+	return blocks, nil
+}
+
+// resolveInt returns the value of n, which is a number or a Ref to a parameter with a default
+func resolveInt(t cft.Template, n *yaml.Node) (int, error) {
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 && n.Content[0].Value == "Ref" {
+		value, err := parameterDefault(t, n.Content[1])
+		if err != nil {
+			return 0, err
+		}
+		n = value
+	}
+
+	if n.Kind != yaml.ScalarNode {
+		return 0, errUnknown
+	}
+
+	i, err := strconv.Atoi(n.Value)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a number", n.Value)
+	}
+
+	return i, nil
+}
+
+// parameterDefault returns the default value of the parameter named by n
+func parameterDefault(t cft.Template, n *yaml.Node) (*yaml.Node, error) {
+	if n.Kind != yaml.ScalarNode {
+		return nil, errUnknown
+	}
+
+	param, err := t.GetParameter(n.Value)
+	if err != nil {
+		return nil, errUnknown
+	}
+
+	_, value, _ := s11n.GetMapValue(param, "Default")
+	if value == nil {
+		return nil, errUnknown
+	}
+
+	return value, nil
+}
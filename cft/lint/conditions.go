@@ -0,0 +1,211 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/eval"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	register(Rule{
+		Name:        "conditions",
+		Description: "Conditions can be both true and false for some parameter values, and conditional resources and outputs can be created",
+		Check:       checkConditions,
+	})
+}
+
+// maxCombinations stops checkConditions trying too many combinations of parameter values
+const maxCombinations = 4096
+
+// otherValue stands for any value that a condition does not compare a parameter with
+const otherValue = "rain-lint-any-other-value"
+
+func checkConditions(t cft.Template, opts Options) []Finding {
+	findings := make([]Finding, 0)
+
+	conditions, err := t.GetSection(cft.Conditions)
+	if err != nil || conditions.Kind != yaml.MappingNode || len(conditions.Content) == 0 {
+		return findings
+	}
+
+	domains := conditionDomains(t, conditions)
+
+	names := make([]string, 0, len(domains))
+	combinations := 1
+	for name, values := range domains {
+		names = append(names, name)
+		combinations *= len(values)
+		if combinations > maxCombinations {
+			return findings
+		}
+	}
+	sort.Strings(names)
+
+	// Whether each condition has been seen to be true and false
+	seen := make(map[string][2]bool)
+
+	for i := 0; i < combinations; i++ {
+		values := eval.Values{
+			Parameters: make(map[string]string),
+			Pseudo:     make(map[string]string),
+		}
+
+		n := i
+		for _, name := range names {
+			value := domains[name][n%len(domains[name])]
+			n /= len(domains[name])
+
+			if strings.HasPrefix(name, "AWS::") {
+				values.Pseudo[name] = value
+			} else {
+				values.Parameters[name] = value
+			}
+		}
+
+		results, err := eval.Conditions(t, values)
+		if err != nil {
+			// Conditions that can't be evaluated before deployment are reported by cfn-lint
+			return findings
+		}
+
+		for name, result := range results {
+			s := seen[name]
+			if result {
+				s[0] = true
+			} else {
+				s[1] = true
+			}
+			seen[name] = s
+		}
+	}
+
+	always := func(name string) (bool, bool) {
+		s := seen[name]
+		return s[0] && !s[1], s[1] && !s[0]
+	}
+
+	for i := 0; i < len(conditions.Content)-1; i += 2 {
+		name := conditions.Content[i].Value
+		alwaysTrue, alwaysFalse := always(name)
+		if alwaysTrue || alwaysFalse {
+			findings = append(findings, Finding{
+				Severity: Warning,
+				Element:  fmt.Sprintf("Conditions/%s", name),
+				Message:  fmt.Sprintf("condition %s is always %t, whatever the values of the parameters it uses", name, alwaysTrue),
+			})
+		}
+	}
+
+	for _, section := range []cft.Section{cft.Resources, cft.Outputs} {
+		s, err := t.GetSection(section)
+		if err != nil || s.Kind != yaml.MappingNode {
+			continue
+		}
+
+		for i := 0; i < len(s.Content)-1; i += 2 {
+			name := s.Content[i].Value
+
+			_, c, _ := s11n.GetMapValue(s.Content[i+1], "Condition")
+			if c == nil || c.Kind != yaml.ScalarNode {
+				continue
+			}
+
+			if _, alwaysFalse := always(c.Value); alwaysFalse {
+				what := "resource can never be created"
+				if section == cft.Outputs {
+					what = "output can never be shown"
+				}
+
+				findings = append(findings, Finding{
+					Severity: Warning,
+					Element:  fmt.Sprintf("%s/%s", section, name),
+					Message:  fmt.Sprintf("the %s because condition %s is always false", what, c.Value),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// conditionDomains returns the values to try for each parameter and pseudo parameter that conditions refer to:
+// a parameter's allowed values if it has them, or otherwise the values it is compared with and any other value
+func conditionDomains(t cft.Template, conditions *yaml.Node) map[string][]string {
+	refs := make(map[string]bool)
+	compared := make(map[string][]string)
+
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.MappingNode && len(n.Content) == 2 {
+			switch n.Content[0].Value {
+			case "Ref":
+				refs[n.Content[1].Value] = true
+			case "Fn::Equals":
+				if args := n.Content[1]; args.Kind == yaml.SequenceNode && len(args.Content) == 2 {
+					for j, side := range args.Content {
+						other := args.Content[1-j]
+						if name, ok := ref(side); ok && other.Kind == yaml.ScalarNode {
+							compared[name] = append(compared[name], other.Value)
+						}
+					}
+				}
+			}
+		}
+
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+
+	for i := 1; i < len(conditions.Content); i += 2 {
+		walk(conditions.Content[i])
+	}
+
+	params, _ := t.GetSection(cft.Parameters)
+
+	domains := make(map[string][]string)
+	for name := range refs {
+		var p *yaml.Node
+		if params != nil {
+			_, p, _ = s11n.GetMapValue(params, name)
+		}
+
+		if p == nil && (!strings.HasPrefix(name, "AWS::") || name == "AWS::NoValue") {
+			continue
+		}
+
+		if p != nil {
+			if _, allowed, _ := s11n.GetMapValue(p, "AllowedValues"); allowed != nil && allowed.Kind == yaml.SequenceNode {
+				for _, v := range allowed.Content {
+					domains[name] = append(domains[name], v.Value)
+				}
+				continue
+			}
+		}
+
+		values := []string{otherValue}
+		for _, v := range compared[name] {
+			if !slices.Contains(values, v) {
+				values = append(values, v)
+			}
+		}
+		domains[name] = values
+	}
+
+	return domains
+}
+
+// ref returns the name that n refers to, if it is a Ref
+func ref(n *yaml.Node) (string, bool) {
+	if n.Kind == yaml.MappingNode && len(n.Content) == 2 && n.Content[0].Value == "Ref" {
+		return n.Content[1].Value, true
+	}
+
+	return "", false
+}
@@ -0,0 +1,380 @@
+package lint
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"plugin"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"gopkg.in/yaml.v3"
+)
+
+// RuleFile is a file of custom rules written in YAML:
+//
+//	Rules:
+//	  - Name: bucket-encryption
+//	    Description: Buckets are encrypted
+//	    Severity: error
+//	    Match:
+//	      Type: AWS::S3::Bucket
+//	    Assert:
+//	      - Path: Properties/BucketEncryption
+//	        Exists: true
+//	      - Path: Properties/BucketName
+//	        Pattern: ^acme-
+type RuleFile struct {
+	Rules []CustomRule `yaml:"Rules"`
+}
+
+// CustomRule checks each resource that it matches against its assertions
+type CustomRule struct {
+	Name        string   `yaml:"Name"`
+	Description string   `yaml:"Description"`
+	Severity    Severity `yaml:"Severity"`
+
+	Match Match `yaml:"Match"`
+
+	Assert []Assertion `yaml:"Assert"`
+
+	// Message replaces the generated message of a failed assertion
+	Message string `yaml:"Message"`
+}
+
+// Match selects the resources a custom rule applies to
+type Match struct {
+	// Type is a resource type, which can use * as a wildcard, e.g. AWS::S3::*
+	Type string `yaml:"Type"`
+
+	// Where limits the rule to resources that pass every one of these assertions
+	Where []Assertion `yaml:"Where"`
+}
+
+// Assertion checks the value at a path in a resource, e.g. Properties/BucketName.
+// Values that are intrinsic functions are not known until the stack is deployed,
+// so only Exists is checked for them.
+type Assertion struct {
+	Path string `yaml:"Path"`
+
+	// Exists requires the path to be set, or to be missing if it is false
+	Exists *bool `yaml:"Exists"`
+
+	// Equals requires the value to be this string
+	Equals *string `yaml:"Equals"`
+
+	// OneOf requires the value to be one of these strings
+	OneOf []string `yaml:"OneOf"`
+
+	// Pattern requires the value to match this regular expression
+	Pattern string `yaml:"Pattern"`
+
+	pattern *regexp.Regexp
+}
+
+// Register adds a rule to Rules, so that it runs with the built-in rules
+func Register(rule Rule) error {
+	if rule.Name == "" || rule.Check == nil {
+		return errors.New("a rule needs a name and a check")
+	}
+
+	for _, r := range Rules {
+		if r.Name == rule.Name {
+			return fmt.Errorf("there is already a rule called %s", rule.Name)
+		}
+	}
+
+	register(rule)
+
+	return nil
+}
+
+// LoadRules registers the custom rules in a file or directory.
+// Files ending in .yaml, .yml or .json are read as rule files,
+// and files ending in .so are loaded as Go plugins.
+//
+// A plugin must be built with go build -buildmode=plugin against the same version of rain,
+// and export a variable called Rules of type []lint.Rule.
+func LoadRules(p string) error {
+	info, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+
+	files := []string{p}
+	if info.IsDir() {
+		entries, err := os.ReadDir(p)
+		if err != nil {
+			return err
+		}
+
+		files = make([]string, 0)
+		for _, e := range entries {
+			if !e.IsDir() {
+				files = append(files, filepath.Join(p, e.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+
+	for _, fn := range files {
+		var rules []Rule
+
+		switch strings.ToLower(filepath.Ext(fn)) {
+		case ".yaml", ".yml", ".json":
+			rules, err = readRuleFile(fn)
+		case ".so":
+			rules, err = openPlugin(fn)
+		default:
+			if !info.IsDir() {
+				err = errors.New("rules must be in a .yaml, .yml, .json or .so file")
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("unable to load rules from '%s': %w", fn, err)
+		}
+
+		for _, rule := range rules {
+			if err := Register(rule); err != nil {
+				return fmt.Errorf("unable to load rules from '%s': %w", fn, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// openPlugin reads the Rules variable of a Go plugin
+func openPlugin(fn string) ([]Rule, error) {
+	p, err := plugin.Open(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	sym, err := p.Lookup("Rules")
+	if err != nil {
+		return nil, err
+	}
+
+	rules, ok := sym.(*[]Rule)
+	if !ok {
+		return nil, fmt.Errorf("Rules is a %T, not a []lint.Rule", sym)
+	}
+
+	return *rules, nil
+}
+
+// readRuleFile reads custom rules written in YAML
+func readRuleFile(fn string) ([]Rule, error) {
+	content, err := os.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseRules(content)
+}
+
+// ParseRules reads custom rules written in YAML
+func ParseRules(content []byte) ([]Rule, error) {
+	var f RuleFile
+	err := yaml.Unmarshal(content, &f)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := make([]Rule, 0, len(f.Rules))
+
+	for _, c := range f.Rules {
+		c := c
+
+		if c.Name == "" {
+			return nil, errors.New("every rule needs a Name")
+		}
+
+		if c.Match.Type == "" {
+			return nil, fmt.Errorf("%s: Match needs a Type", c.Name)
+		}
+
+		switch c.Severity {
+		case "":
+			c.Severity = Warning
+		case Error, Warning, Info:
+		default:
+			return nil, fmt.Errorf("%s: Severity must be %s, %s or %s", c.Name, Error, Warning, Info)
+		}
+
+		for _, list := range [][]Assertion{c.Match.Where, c.Assert} {
+			for i := range list {
+				if list[i].Path == "" {
+					return nil, fmt.Errorf("%s: every assertion needs a Path", c.Name)
+				}
+
+				if list[i].Pattern != "" {
+					list[i].pattern, err = regexp.Compile(list[i].Pattern)
+					if err != nil {
+						return nil, fmt.Errorf("%s: %w", c.Name, err)
+					}
+				}
+			}
+		}
+
+		description := c.Description
+		if description == "" {
+			description = fmt.Sprintf("Custom rule for %s", c.Match.Type)
+		}
+
+		rules = append(rules, Rule{
+			Name:        c.Name,
+			Description: description,
+			Check: func(t cft.Template, opts Options) []Finding {
+				return c.check(t)
+			},
+		})
+	}
+
+	return rules, nil
+}
+
+func (c CustomRule) check(t cft.Template) []Finding {
+	findings := make([]Finding, 0)
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil || resources.Kind != yaml.MappingNode {
+		return findings
+	}
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		name := resources.Content[i].Value
+		resource := resources.Content[i+1]
+
+		typeName := resourceType(resource)
+		if ok, _ := path.Match(c.Match.Type, typeName); !ok {
+			continue
+		}
+
+		matches := true
+		for _, a := range c.Match.Where {
+			if a.failure(resource) != "" {
+				matches = false
+				break
+			}
+		}
+		if !matches {
+			continue
+		}
+
+		for _, a := range c.Assert {
+			message := a.failure(resource)
+			if message == "" {
+				continue
+			}
+
+			if c.Message != "" {
+				message = c.Message
+			}
+
+			findings = append(findings, Finding{
+				Severity: c.Severity,
+				Element:  fmt.Sprintf("Resources/%s", name),
+				Line:     resources.Content[i].Line,
+				Message:  message,
+			})
+		}
+	}
+
+	return findings
+}
+
+// resourceType returns the Type of a resource
+func resourceType(resource *yaml.Node) string {
+	if resource.Kind != yaml.MappingNode {
+		return ""
+	}
+
+	for i := 0; i < len(resource.Content)-1; i += 2 {
+		if resource.Content[i].Value == "Type" {
+			return resource.Content[i+1].Value
+		}
+	}
+
+	return ""
+}
+
+// lookup returns the node at a path like Properties/Tags/0/Key, or nil
+func lookup(n *yaml.Node, p string) *yaml.Node {
+	for _, part := range strings.Split(p, "/") {
+		switch n.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i < len(n.Content)-1; i += 2 {
+				if n.Content[i].Value == part {
+					next = n.Content[i+1]
+					break
+				}
+			}
+			if next == nil {
+				return nil
+			}
+			n = next
+		case yaml.SequenceNode:
+			var index int
+			if _, err := fmt.Sscanf(part, "%d", &index); err != nil || index < 0 || index >= len(n.Content) {
+				return nil
+			}
+			n = n.Content[index]
+		default:
+			return nil
+		}
+	}
+
+	return n
+}
+
+// failure returns why the resource fails the assertion, or "" if it passes
+func (a Assertion) failure(resource *yaml.Node) string {
+	v := lookup(resource, a.Path)
+
+	if a.Exists != nil {
+		if *a.Exists && v == nil {
+			return fmt.Sprintf("%s is required", a.Path)
+		}
+		if !*a.Exists && v != nil {
+			return fmt.Sprintf("%s must not be set", a.Path)
+		}
+	}
+
+	// The other checks need a value that is known before deployment
+	if v == nil || v.Kind != yaml.ScalarNode || strings.HasPrefix(v.Tag, "!") && !strings.HasPrefix(v.Tag, "!!") {
+		if v == nil && (a.Equals != nil || len(a.OneOf) > 0 || a.pattern != nil) {
+			return fmt.Sprintf("%s is required", a.Path)
+		}
+		return ""
+	}
+
+	if a.Equals != nil && v.Value != *a.Equals {
+		return fmt.Sprintf("%s must be %s, not %s", a.Path, *a.Equals, v.Value)
+	}
+
+	if len(a.OneOf) > 0 {
+		found := false
+		for _, option := range a.OneOf {
+			if v.Value == option {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Sprintf("%s must be one of %s, not %s", a.Path, strings.Join(a.OneOf, ", "), v.Value)
+		}
+	}
+
+	if a.pattern != nil && !a.pattern.MatchString(v.Value) {
+		return fmt.Sprintf("%s must match %s, not %s", a.Path, a.Pattern, v.Value)
+	}
+
+	return ""
+}
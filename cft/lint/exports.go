@@ -0,0 +1,93 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/exports"
+)
+
+func init() {
+	register(Rule{
+		Name:        "export-names",
+		Description: "Exported outputs follow the export naming convention",
+		Check:       checkExportNames,
+	})
+}
+
+func checkExportNames(t cft.Template, opts Options) []Finding {
+	findings := make([]Finding, 0)
+
+	if err := exports.CheckUnique(t, "${AWS::StackName}"); err != nil {
+		findings = append(findings, Finding{
+			Severity: Error,
+			Element:  "Outputs",
+			Message:  err.Error(),
+		})
+	}
+
+	if opts.Exports == nil {
+		return findings
+	}
+
+	c := *opts.Exports
+
+	for _, o := range exports.Outputs(t) {
+		element := fmt.Sprintf("Outputs/%s", o.Name)
+
+		if o.Export == nil {
+			if c.ShouldExport(o.Name) {
+				findings = append(findings, Finding{
+					Severity: Warning,
+					Element:  element,
+					Message:  "output should be exported according to the naming convention",
+				})
+			}
+			continue
+		}
+
+		if len(c.Exported) > 0 && !c.ShouldExport(o.Name) {
+			findings = append(findings, Finding{
+				Severity: Warning,
+				Element:  element,
+				Message:  "output is exported but does not match any of the Exported patterns",
+			})
+		}
+
+		if o.ExportName == nil {
+			findings = append(findings, Finding{
+				Severity: Info,
+				Element:  element,
+				Message:  fmt.Sprintf("export name will be set to '%s' when the stack is deployed", c.Sub(o.Name)),
+			})
+			continue
+		}
+
+		// Compare the names with a placeholder stack name
+		// unless we know what the stack will be called
+		stackName := opts.StackName
+		if stackName == "" {
+			stackName = "${AWS::StackName}"
+		}
+
+		actual, ok := exports.Resolve(o.ExportName, stackName)
+		if !ok {
+			findings = append(findings, Finding{
+				Severity: Info,
+				Element:  element,
+				Message:  "unable to check the export name against the naming convention",
+			})
+			continue
+		}
+
+		if expected := c.Name(stackName, o.Name); actual != expected {
+			findings = append(findings, Finding{
+				Severity: Warning,
+				Element:  element,
+				Message:  fmt.Sprintf("export name '%s' does not follow the naming convention '%s'", actual, expected),
+			})
+		}
+	}
+
+	return findings
+}
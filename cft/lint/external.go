@@ -0,0 +1,297 @@
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+)
+
+// ExternalLinter is a linter that rain runs as a subprocess, such as cfn-lint.
+// Its findings are reported alongside the rules' findings, with Rule set to
+// the linter's name and the id of its rule, e.g. "cfn-lint/E3012",
+// so that they can be suppressed in the same way.
+type ExternalLinter struct {
+	Name string
+
+	// Binary is the program that is run, which must be on the PATH
+	Binary string
+
+	Description string
+
+	// args returns the arguments that lint the template at path and write JSON to stdout
+	args func(path string) []string
+
+	// parse reads the findings from the linter's output
+	parse func(out []byte) ([]Finding, error)
+}
+
+// ExternalLinters are the linters that rain lint --external can run
+var ExternalLinters = []ExternalLinter{
+	{
+		Name:        "cfn-lint",
+		Binary:      "cfn-lint",
+		Description: "checks templates against the resource specifications and best practices",
+		args: func(path string) []string {
+			return []string{"--format", "json", "--", path}
+		},
+		parse: parseCfnLint,
+	},
+	{
+		Name:        "cfn-nag",
+		Binary:      "cfn_nag_scan",
+		Description: "looks for patterns that indicate insecure infrastructure",
+		args: func(path string) []string {
+			return []string{"--input-path", path, "--output-format", "json"}
+		},
+		parse: parseCfnNag,
+	},
+	{
+		Name:        "checkov",
+		Binary:      "checkov",
+		Description: "checks resources against security and compliance policies",
+		args: func(path string) []string {
+			return []string{"--file", path, "--framework", "cloudformation", "--output", "json", "--quiet", "--compact"}
+		},
+		parse: parseCheckov,
+	},
+}
+
+// FindExternalLinter returns the external linter with the name,
+// which can be written with an underscore as the cfn_nag project does
+func FindExternalLinter(name string) (ExternalLinter, error) {
+	name = strings.ReplaceAll(strings.ToLower(name), "_", "-")
+
+	names := make([]string, 0, len(ExternalLinters))
+	for _, l := range ExternalLinters {
+		if l.Name == name {
+			return l, nil
+		}
+		names = append(names, l.Name)
+	}
+
+	return ExternalLinter{}, fmt.Errorf("unknown external linter '%s'; use one of %s", name, strings.Join(names, ", "))
+}
+
+// Run lints the template in the file and returns the linter's findings.
+// The linters exit with a non-zero status when they find problems,
+// so the status is ignored if the linter wrote a report.
+func (l ExternalLinter) Run(ctx context.Context, path string) ([]Finding, error) {
+	bin, err := exec.LookPath(l.Binary)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not installed or is not on the PATH", l.Binary)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, l.args(path)...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+
+	var exitErr *exec.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && len(bytes.TrimSpace(stdout.Bytes())) > 0) {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%s failed: %s", l.Name, message)
+		}
+		return nil, fmt.Errorf("%s failed: %w", l.Name, err)
+	}
+
+	findings, err := l.parse(stdout.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s output: %w", l.Name, err)
+	}
+
+	for i := range findings {
+		findings[i].Source = l.Name
+		findings[i].Rule = l.Name + "/" + findings[i].Rule
+	}
+
+	return findings, nil
+}
+
+// isExternalRule returns true if the name is one that an external linter's findings have
+func isExternalRule(name string) bool {
+	prefix, _, found := strings.Cut(name, "/")
+	if !found {
+		return false
+	}
+
+	_, err := FindExternalLinter(prefix)
+	return err == nil
+}
+
+// resourceElement returns the element of a resource, e.g. "Resources/Bucket"
+func resourceElement(logicalId string) string {
+	return fmt.Sprintf("%s/%s", cft.Resources, logicalId)
+}
+
+// parseCfnLint reads the output of cfn-lint --format json
+func parseCfnLint(out []byte) ([]Finding, error) {
+	var matches []struct {
+		Level    string `json:"Level"`
+		Message  string `json:"Message"`
+		Location struct {
+			Start struct {
+				LineNumber int `json:"LineNumber"`
+			} `json:"Start"`
+			Path []any `json:"Path"`
+		} `json:"Location"`
+		Rule struct {
+			Id string `json:"Id"`
+		} `json:"Rule"`
+	}
+
+	if err := json.Unmarshal(out, &matches); err != nil {
+		return nil, err
+	}
+
+	findings := make([]Finding, 0, len(matches))
+	for _, m := range matches {
+		f := Finding{
+			Rule:    m.Rule.Id,
+			Message: m.Message,
+			Line:    m.Location.Start.LineNumber,
+		}
+
+		switch m.Level {
+		case "Error":
+			f.Severity = Error
+		case "Warning":
+			f.Severity = Warning
+		default:
+			f.Severity = Info
+		}
+
+		// The element is the section and the name in it, such as the resource
+		path := make([]string, 0, 2)
+		for _, p := range m.Location.Path {
+			if len(path) == 2 {
+				break
+			}
+			path = append(path, fmt.Sprint(p))
+		}
+		if len(path) == 0 {
+			path = append(path, fmt.Sprintf("line %d", f.Line))
+		}
+		f.Element = strings.Join(path, "/")
+
+		findings = append(findings, f)
+	}
+
+	return findings, nil
+}
+
+// parseCfnNag reads the output of cfn_nag_scan --output-format json,
+// reporting each violation once for each resource it applies to
+func parseCfnNag(out []byte) ([]Finding, error) {
+	var files []struct {
+		FileResults struct {
+			Violations []struct {
+				Id          string   `json:"id"`
+				Type        string   `json:"type"`
+				Message     string   `json:"message"`
+				LogicalIds  []string `json:"logical_resource_ids"`
+				LineNumbers []int    `json:"line_numbers"`
+			} `json:"violations"`
+		} `json:"file_results"`
+	}
+
+	if err := json.Unmarshal(out, &files); err != nil {
+		return nil, err
+	}
+
+	findings := make([]Finding, 0)
+	for _, file := range files {
+		for _, v := range file.FileResults.Violations {
+			severity := Warning
+			if v.Type == "FAIL" {
+				severity = Error
+			}
+
+			for i, id := range v.LogicalIds {
+				f := Finding{
+					Rule:     v.Id,
+					Severity: severity,
+					Element:  resourceElement(id),
+					Message:  v.Message,
+				}
+				if i < len(v.LineNumbers) && v.LineNumbers[i] > 0 {
+					f.Line = v.LineNumbers[i]
+				}
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	return findings, nil
+}
+
+// checkovReport is the part of checkov's JSON report that rain needs
+type checkovReport struct {
+	Results struct {
+		FailedChecks []struct {
+			CheckId   string `json:"check_id"`
+			CheckName string `json:"check_name"`
+			Resource  string `json:"resource"`
+			LineRange []int  `json:"file_line_range"`
+			Severity  string `json:"severity"`
+		} `json:"failed_checks"`
+	} `json:"results"`
+}
+
+// parseCheckov reads the output of checkov --output json, which is a report,
+// or a list of reports when more than one framework ran
+func parseCheckov(out []byte) ([]Finding, error) {
+	var reports []checkovReport
+
+	if trimmed := bytes.TrimSpace(out); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(out, &reports); err != nil {
+			return nil, err
+		}
+	} else {
+		var report checkovReport
+		if err := json.Unmarshal(out, &report); err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	findings := make([]Finding, 0)
+	for _, report := range reports {
+		for _, c := range report.Results.FailedChecks {
+			// Checkov fails for every check that fails, so checks without a severity are errors
+			severity := Error
+			switch strings.ToUpper(c.Severity) {
+			case "MEDIUM":
+				severity = Warning
+			case "LOW", "INFO":
+				severity = Info
+			}
+
+			// Resources are named by their type and logical id, e.g. AWS::S3::Bucket.Bucket
+			_, logicalId, found := strings.Cut(c.Resource, ".")
+			if !found {
+				logicalId = c.Resource
+			}
+
+			f := Finding{
+				Rule:     c.CheckId,
+				Severity: severity,
+				Element:  resourceElement(logicalId),
+				Message:  c.CheckName,
+			}
+			if len(c.LineRange) > 0 {
+				f.Line = c.LineRange[0]
+			}
+			findings = append(findings, f)
+		}
+	}
+
+	return findings, nil
+}
@@ -0,0 +1,41 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/iam"
+)
+
+// Each of the IAM policy analyzer's checks is a rule, so that they can be suppressed separately
+func init() {
+	for _, check := range iam.Checks {
+		name := check.Name
+		register(Rule{
+			Name:        name,
+			Description: check.Description,
+			Check: func(t cft.Template, opts Options) []Finding {
+				return checkIAM(t, name)
+			},
+		})
+	}
+}
+
+func checkIAM(t cft.Template, check string) []Finding {
+	findings := make([]Finding, 0)
+
+	for _, f := range iam.Analyze(t) {
+		if f.Check != check {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Severity: Severity(f.Severity),
+			Element:  fmt.Sprintf("Resources/%s", f.Resource),
+			Line:     f.Line,
+			Message:  fmt.Sprintf("%s, statement %s: %s", f.Path, f.Statement, f.Message),
+		})
+	}
+
+	return findings
+}
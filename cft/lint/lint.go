@@ -0,0 +1,155 @@
+// Package lint checks CloudFormation templates against a set of rules
+// that enforce project conventions, complementing the syntax and schema
+// checks that cfn-lint performs.
+//
+// Each rule is registered with a name and a Check function.
+// Rules that need configuration read it from Options,
+// and should do nothing if their configuration is missing.
+//
+// A finding can be accepted with a comment on the line before it,
+// naming the rules to suppress and the reason:
+//
+//	# rain-disable-next-line cidr reason=the range is shared with another VPC
+//
+// Findings can also be accepted in the template's or a resource's Metadata,
+// as described in package suppress:
+//
+//	Metadata:
+//	  Rain::Suppressions:
+//	    - Rule: cidr
+//	      Reason: the range is shared with another VPC
+//	      Expires: 2025-06-30
+//
+// The findings of external linters such as cfn-lint, cfn_nag and checkov,
+// which ExternalLinter runs, can be passed in Options. They are suppressed
+// in the same way, with rule names such as cfn-lint/E3012.
+//
+// Suppressed findings are still returned, marked as Suppressed,
+// but they are not counted by HasErrors.
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/exports"
+	"github.com/aws-cloudformation/rain/cft/suppress"
+)
+
+// Severity indicates how serious a finding is
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Info    Severity = "info"
+)
+
+// Finding is a single problem reported by a rule
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+
+	// Source is the external linter that reported the finding, or empty for rain's own rules
+	Source string `json:"source,omitempty"`
+
+	// Element is the template element the finding is about, e.g. "Outputs/BucketArn"
+	Element string `json:"element"`
+
+	Message string `json:"message"`
+
+	// Line is the line of the template the finding is about, if it is known
+	Line int `json:"line,omitempty"`
+
+	// Suppressed is set if a suppression comment or Metadata accepts the finding
+	Suppressed bool `json:"suppressed,omitempty"`
+
+	// Reason is the reason the suppression gives
+	Reason string `json:"reason,omitempty"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s [%s]", f.Element, f.Message, f.Rule)
+}
+
+// Options configure the rules
+type Options struct {
+	// StackName is the name the template will be deployed as, if known
+	StackName string
+
+	// Exports is the export naming convention, if there is one
+	Exports *exports.Convention
+
+	// Schema returns the registry schema of a resource type as JSON,
+	// including private types registered in the account
+	Schema func(typeName string) (string, error)
+
+	// External are the findings of external linters, which are merged
+	// with the rules' findings and can be suppressed in the same way
+	External []Finding
+}
+
+// Rule is a named check that can be run against a template
+type Rule struct {
+	Name        string
+	Description string
+	Check       func(t cft.Template, opts Options) []Finding
+}
+
+// Rules contains every registered rule, in the order they were registered
+var Rules = make([]Rule, 0)
+
+func register(rule Rule) {
+	Rules = append(Rules, rule)
+}
+
+// Template runs every rule against the template and returns the findings,
+// with those of external linters, sorted by severity and then by element.
+// Findings accepted by suppression comments or Metadata are marked.
+func Template(t cft.Template, opts Options) []Finding {
+	findings := make([]Finding, 0)
+
+	for _, rule := range Rules {
+		for _, f := range rule.Check(t, opts) {
+			f.Rule = rule.Name
+			if f.Line == 0 {
+				f.Line = elementLine(t, f.Element)
+			}
+			findings = append(findings, f)
+		}
+	}
+
+	for _, f := range opts.External {
+		if f.Line == 0 {
+			f.Line = elementLine(t, f.Element)
+		}
+		findings = append(findings, f)
+	}
+
+	findings = suppressComments(findings, Suppressions(t))
+	findings = suppressMetadata(findings, suppress.Template(t, time.Now()))
+
+	rank := map[Severity]int{Error: 0, Warning: 1, Info: 2}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Severity != findings[j].Severity {
+			return rank[findings[i].Severity] < rank[findings[j].Severity]
+		}
+		return findings[i].Element < findings[j].Element
+	})
+
+	return findings
+}
+
+// HasErrors returns true if any of the findings that are not suppressed is an Error
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == Error && !f.Suppressed {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,758 @@
+package lint_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/exports"
+	"github.com/aws-cloudformation/rain/cft/lint"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+func TestExportNames(t *testing.T) {
+	tmpl, err := parse.String(`
+Outputs:
+  VpcId:
+    Value: vpc-1234
+    Export:
+      Name: !Sub ${AWS::StackName}:VpcId
+  SubnetId:
+    Value: subnet-1234
+  Secret:
+    Value: secret
+    Export:
+      Name: !Sub ${AWS::StackName}-Secret
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings := lint.Template(tmpl, lint.Options{
+		Exports: &exports.Convention{
+			Exported: []string{"VpcId", "Subnet*"},
+		},
+	})
+
+	expected := map[string]int{
+		"Outputs/SubnetId": 1, // Should be exported
+		"Outputs/Secret":   2, // Shouldn't be exported, and doesn't follow the convention
+	}
+
+	actual := make(map[string]int)
+	for _, f := range findings {
+		if f.Rule != "export-names" {
+			continue
+		}
+		actual[f.Element]++
+	}
+
+	for element, count := range expected {
+		if actual[element] != count {
+			t.Errorf("%s: expected %d findings, got %d: %v", element, count, actual[element], findings)
+		}
+	}
+
+	if len(actual) != len(expected) {
+		t.Errorf("unexpected findings: %v", findings)
+	}
+
+	if lint.HasErrors(findings) {
+		t.Error("expected warnings only")
+	}
+}
+
+func TestDuplicateExports(t *testing.T) {
+	tmpl, err := parse.String(`
+Outputs:
+  A:
+    Value: a
+    Export:
+      Name: same
+  B:
+    Value: b
+    Export:
+      Name: same
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !lint.HasErrors(lint.Template(tmpl, lint.Options{})) {
+		t.Error("expected an error for duplicate export names")
+	}
+}
+
+func TestCidr(t *testing.T) {
+	tmpl, err := parse.String(`
+Parameters:
+  VpcCidr:
+    Type: String
+    Default: 10.0.0.0/16
+Resources:
+  Vpc:
+    Type: AWS::EC2::VPC
+    Properties:
+      CidrBlock: !Ref VpcCidr
+  Public:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: !Select [0, !Cidr [!GetAtt Vpc.CidrBlock, 4, 8]]
+  Private:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: 10.0.0.128/25
+  Outside:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: 10.1.0.0/24
+  TooMany:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: !Select [0, !Cidr [10.0.0.0/24, 4, 7]]
+  OutOfRange:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: !Select [4, !Cidr [!Ref VpcCidr, 4, 8]]
+  Elsewhere:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !ImportValue SharedVpc
+      CidrBlock: 10.0.0.0/24
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]int{
+		"Resources/Private":    1, // Overlaps with Public
+		"Resources/Outside":    1,
+		"Resources/TooMany":    1,
+		"Resources/OutOfRange": 1,
+	}
+
+	actual := make(map[string]int)
+	for _, f := range lint.Template(tmpl, lint.Options{}) {
+		if f.Rule != "cidr" {
+			continue
+		}
+		actual[f.Element]++
+	}
+
+	for element, count := range expected {
+		if actual[element] != count {
+			t.Errorf("%s: expected %d findings, got %d", element, count, actual[element])
+		}
+	}
+
+	if len(actual) != len(expected) {
+		t.Errorf("unexpected findings: %v", actual)
+	}
+}
+
+func TestYAMLScalars(t *testing.T) {
+	tmpl, err := parse.String(`
+Parameters:
+  AccountId:
+    Type: String
+    Default: 012345678901
+  Enabled:
+    Type: String
+    Default: "yes"
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      Tags:
+        - Key: backup
+          Value: on
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0)
+	for _, f := range lint.Template(tmpl, lint.Options{}) {
+		if f.Rule == "yaml-scalars" {
+			actual = append(actual, f.Element)
+		}
+	}
+
+	// Findings are sorted by element
+	if len(actual) != 2 || actual[0] != "line 15" || actual[1] != "line 5" {
+		t.Errorf("unexpected findings: %v", actual)
+	}
+}
+
+func TestSuppressions(t *testing.T) {
+	tmpl, err := parse.String(`
+Resources:
+  Vpc:
+    Type: AWS::EC2::VPC
+    Properties:
+      CidrBlock: 10.0.0.0/16
+  # rain-disable-next-line cidr reason=peered with the old network
+  Outside:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: 10.1.0.0/24
+  # rain-disable-next-line cidr
+  Unreasoned:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: 10.2.0.0/24
+  # rain-disable-next-line export-names reason=nothing to suppress
+  Inside:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: 10.0.1.0/24
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings := lint.Template(tmpl, lint.Options{})
+
+	suppressed := make(map[string]string)
+	other := make([]string, 0)
+	for _, f := range findings {
+		if f.Suppressed {
+			suppressed[f.Element] = f.Reason
+		} else {
+			other = append(other, fmt.Sprintf("%s %s %d", f.Rule, f.Severity, f.Line))
+		}
+	}
+
+	if len(suppressed) != 2 || suppressed["Resources/Outside"] != "peered with the old network" || suppressed["Resources/Unreasoned"] != "" {
+		t.Errorf("unexpected suppressed findings: %v", suppressed)
+	}
+
+	// The suppression without a reason is reported, as is the one that doesn't match anything
+	expected := []string{"suppressions warning 13", "suppressions info 19"}
+	if strings.Join(other, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, other)
+	}
+
+	if lint.HasErrors(findings) {
+		t.Error("suppressed findings should not be errors")
+	}
+}
+
+func TestMetadataSuppressions(t *testing.T) {
+	tmpl, err := parse.String(`
+Resources:
+  Vpc:
+    Type: AWS::EC2::VPC
+    Properties:
+      CidrBlock: 10.0.0.0/16
+  Outside:
+    Type: AWS::EC2::Subnet
+    Metadata:
+      Rain::Suppressions:
+        - Rule: cidr
+          Reason: peered with the old network
+        - Rule: s3-logging
+          Reason: a scan rule
+        - Rule: no-such-rule
+          Reason: a typo
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: 10.1.0.0/24
+  Expired:
+    Type: AWS::EC2::Subnet
+    Metadata:
+      Rain::Suppressions:
+        - Rule: cidr
+          Reason: until the migration
+          Expires: 2020-01-31
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: 10.2.0.0/24
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0)
+	for _, f := range lint.Template(tmpl, lint.Options{}) {
+		actual = append(actual, fmt.Sprintf("%s %t %s", f.Severity, f.Suppressed, f))
+	}
+
+	expected := []string{
+		"error false Resources/Expired: subnet CIDR block 10.2.0.0/24 is outside the CIDR blocks of Vpc (10.0.0.0/16) [cidr]",
+		"error true Resources/Outside: subnet CIDR block 10.1.0.0/24 is outside the CIDR blocks of Vpc (10.0.0.0/16) [cidr]",
+		"warning false Resources/Expired: suppression expired on 2020-01-31 [suppressions]",
+		"warning false Resources/Outside: suppression of no-such-rule does not name a lint or scan rule [suppressions]",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestCustomRules(t *testing.T) {
+	rules, err := lint.ParseRules([]byte(`
+Rules:
+  - Name: test-bucket-names
+    Severity: error
+    Match:
+      Type: AWS::S3::*
+      Where:
+        - Path: Properties/Public
+          Exists: false
+    Assert:
+      - Path: Properties/BucketName
+        Pattern: ^acme-
+      - Path: Properties/Tier
+        OneOf: [gold, silver]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registered := lint.Rules
+	t.Cleanup(func() { lint.Rules = registered })
+
+	for _, rule := range rules {
+		if err := lint.Register(rule); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := lint.Register(rules[0]); err == nil {
+		t.Error("expected an error for a duplicate rule")
+	}
+
+	tmpl, err := parse.String(`
+Resources:
+  Good:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: acme-good
+      Tier: gold
+  Bad:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: other
+  Intrinsic:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !Sub ${AWS::StackName}-bucket
+      Tier: silver
+  Public:
+    Type: AWS::S3::Bucket
+    Properties:
+      Public: true
+  Queue:
+    Type: AWS::SQS::Queue
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0)
+	for _, f := range lint.Template(tmpl, lint.Options{}) {
+		if f.Rule == "test-bucket-names" {
+			if f.Severity != lint.Error {
+				t.Errorf("expected an error, got %s", f.Severity)
+			}
+			actual = append(actual, f.Element+": "+f.Message)
+		}
+	}
+
+	expected := []string{
+		"Resources/Bad: Properties/BucketName must match ^acme-, not other",
+		"Resources/Bad: Properties/Tier is required",
+	}
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+
+	for _, bad := range []string{
+		"Rules: [{Match: {Type: AWS::S3::Bucket}}]",
+		"Rules: [{Name: x}]",
+		"Rules: [{Name: x, Severity: fatal, Match: {Type: AWS::S3::Bucket}}]",
+		"Rules: [{Name: x, Match: {Type: AWS::S3::Bucket}, Assert: [{Path: A, Pattern: '('}]}]",
+	} {
+		if _, err := lint.ParseRules([]byte(bad)); err == nil {
+			t.Errorf("expected an error for %s", bad)
+		}
+	}
+}
+
+func TestResourceProperties(t *testing.T) {
+	tmpl, err := parse.String(`
+Resources:
+  Vpc:
+    Type: MyOrg::Networking::VPC
+    Properties:
+      CidrBlock: 10.0.0.0/16
+      Colour: blue
+      VpcId: vpc-1234
+  Missing:
+    Type: MyOrg::Networking::VPC
+  Conditional:
+    Type: MyOrg::Networking::VPC
+    Properties: !If [Prod, {CidrBlock: 10.0.0.0/16}, {CidrBlock: 10.1.0.0/16}]
+  Custom:
+    Type: Custom::Thing
+    Properties:
+      Anything: true
+  Unknown:
+    Type: MyOrg::Networking::Subnet
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := func(typeName string) (string, error) {
+		if typeName != "MyOrg::Networking::VPC" {
+			return "", fmt.Errorf("type not found")
+		}
+
+		return `{
+			"typeName": "MyOrg::Networking::VPC",
+			"properties": {"CidrBlock": {"type": "string"}, "VpcId": {"type": "string"}},
+			"required": ["CidrBlock"],
+			"readOnlyProperties": ["/properties/VpcId"]
+		}`, nil
+	}
+
+	actual := make([]string, 0)
+	for _, f := range lint.Template(tmpl, lint.Options{Schema: schema}) {
+		if f.Rule == "resource-properties" {
+			actual = append(actual, fmt.Sprintf("%s %s: %s", f.Severity, f.Element, f.Message))
+		}
+	}
+
+	expected := []string{
+		"error Resources/Missing: MyOrg::Networking::VPC requires the CidrBlock property",
+		"error Resources/Vpc/Properties/Colour: Colour is not a property of MyOrg::Networking::VPC",
+		"warning Resources/Unknown: unable to get the schema for MyOrg::Networking::Subnet: type not found",
+		"warning Resources/Vpc/Properties/VpcId: VpcId is read-only, so CloudFormation ignores it",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+
+	// The rule does nothing without a schema lookup
+	for _, f := range lint.Template(tmpl, lint.Options{}) {
+		if f.Rule == "resource-properties" {
+			t.Errorf("unexpected finding %s", f)
+		}
+	}
+}
+
+func TestConditions(t *testing.T) {
+	tmpl, err := parse.String(`
+Parameters:
+  Env:
+    Type: String
+    AllowedValues: [dev, prod]
+  Size:
+    Type: String
+Conditions:
+  IsProd: !Equals [!Ref Env, prod]
+  IsTest: !Equals [!Ref Env, test]
+  IsDevOrProd: !Or
+    - !Condition IsProd
+    - !Equals [!Ref Env, dev]
+  IsLarge: !Equals [!Ref Size, large]
+  IsEast: !Equals [!Ref AWS::Region, us-east-1]
+  IsLargeProd: !And
+    - !Condition IsProd
+    - !Condition IsLarge
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Condition: IsTest
+  Queue:
+    Type: AWS::SQS::Queue
+    Condition: IsLargeProd
+Outputs:
+  BucketName:
+    Condition: IsTest
+    Value: !Ref Bucket
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0)
+	for _, f := range lint.Template(tmpl, lint.Options{}) {
+		if f.Rule == "conditions" {
+			actual = append(actual, f.String())
+		}
+	}
+
+	expected := []string{
+		"Conditions/IsDevOrProd: condition IsDevOrProd is always true, whatever the values of the parameters it uses [conditions]",
+		"Conditions/IsTest: condition IsTest is always false, whatever the values of the parameters it uses [conditions]",
+		"Outputs/BucketName: the output can never be shown because condition IsTest is always false [conditions]",
+		"Resources/Bucket: the resource can never be created because condition IsTest is always false [conditions]",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+func TestSubVariables(t *testing.T) {
+	tmpl, err := parse.String(`
+Parameters:
+  Env:
+    Type: String
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !Sub ${Env}-${AWS::AcountId}-${!Literal}
+  Queue:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: !Sub
+        - ${Name}-${Bucket.Arn}-${Evn}
+        - Name: !Sub ${Buckt.Arn}
+Outputs:
+  Url:
+    Value: !Sub https://${Bucket.DomainName}/${AWS::Region}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0)
+	for _, f := range lint.Template(tmpl, lint.Options{}) {
+		if f.Rule == "sub-variables" {
+			actual = append(actual, fmt.Sprintf("%d %s", f.Line, f))
+		}
+	}
+
+	expected := []string{
+		"9 Resources/Bucket: ${AWS::AcountId} is not a pseudo parameter that Fn::Sub can use; did you mean ${AWS::AccountId}? [sub-variables]",
+		"15 Resources/Queue: ${Buckt.Arn} refers to Buckt, which is not a resource; did you mean Bucket? [sub-variables]",
+		"14 Resources/Queue: ${Evn} is not a parameter, resource or variable; did you mean ${Env}? [sub-variables]",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+
+	// Templates with transforms that add resources aren't checked
+	tmpl, err = parse.String(`
+Transform: AWS::Serverless-2016-10-31
+Resources:
+  Function:
+    Type: AWS::Serverless::Function
+    Properties:
+      Description: !Sub ${FunctionRole}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range lint.Template(tmpl, lint.Options{}) {
+		if f.Rule == "sub-variables" {
+			t.Errorf("unexpected finding: %s", f)
+		}
+	}
+}
+
+func TestIAM(t *testing.T) {
+	tmpl, err := parse.String(`
+Resources:
+  Role:
+    Type: AWS::IAM::Role
+    Properties:
+      AssumeRolePolicyDocument:
+        Statement:
+          - Effect: Allow
+            Principal:
+              Service: lambda.amazonaws.com
+            Action: sts:AssumeRole
+      Policies:
+        - PolicyName: admin
+          PolicyDocument:
+            Statement:
+              # rain-disable-next-line iam-wildcard-resources reason=break glass role
+              - Effect: Allow
+                Action: "*"
+                Resource: "*"
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings := lint.Template(tmpl, lint.Options{})
+
+	actual := make([]string, 0)
+	for _, f := range findings {
+		actual = append(actual, fmt.Sprintf("%s %t %s", f.Severity, f.Suppressed, f))
+	}
+
+	expected := []string{
+		"error false Resources/Role: Properties/Policies/0/PolicyDocument, statement 1: allows every action [iam-wildcard-actions]",
+		"warning true Resources/Role: Properties/Policies/0/PolicyDocument, statement 1: applies to every resource; name the resources the actions need [iam-wildcard-resources]",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
+
+// fakeLinter writes a script to dir that prints out and exits with code, in place of an external linter
+func fakeLinter(t *testing.T, dir, name, out string, code int) {
+	script := fmt.Sprintf("#!/bin/sh\nprintf '%%s\\n' '%s'\nexit %d\n", out, code)
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExternalLinters(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake linters are shell scripts")
+	}
+
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+
+	fakeLinter(t, dir, "cfn-lint", `[{"Level": "Error", "Message": "Property Tags should be a list",
+  "Location": {"Start": {"LineNumber": 6}, "Path": ["Resources", "Bucket", "Properties", "Tags"]},
+  "Rule": {"Id": "E3012"}}]`, 2)
+	fakeLinter(t, dir, "cfn_nag_scan", `[{"filename": "template.yaml", "file_results": {"violations": [
+  {"id": "W35", "type": "WARN", "message": "S3 Bucket should have access logging configured",
+   "logical_resource_ids": ["Bucket", "Logs"], "line_numbers": [4, 8]}]}}]`, 0)
+	fakeLinter(t, dir, "checkov", `{"check_type": "cloudformation", "results": {"failed_checks": [
+  {"check_id": "CKV_AWS_18", "check_name": "Ensure the S3 bucket has access logging enabled",
+   "resource": "AWS::S3::Bucket.Logs", "file_line_range": [8, 9], "severity": null}]}}`, 1)
+
+	tmpl, err := parse.String(`
+Resources:
+  # rain-disable-next-line cfn-nag/W35 reason=logs are in CloudTrail
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      Tags: {}
+  Logs:
+    Type: AWS::S3::Bucket
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	external := make([]lint.Finding, 0)
+	for _, name := range []string{"cfn-lint", "cfn_nag", "checkov"} {
+		linter, err := lint.FindExternalLinter(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		findings, err := linter.Run(context.Background(), "template.yaml")
+		if err != nil {
+			t.Fatal(err)
+		}
+		external = append(external, findings...)
+	}
+
+	actual := make([]string, 0)
+	for _, f := range lint.Template(tmpl, lint.Options{External: external}) {
+		actual = append(actual, fmt.Sprintf("%s %t %s %d %s", f.Severity, f.Suppressed, f.Source, f.Line, f))
+	}
+
+	expected := []string{
+		"error false cfn-lint 6 Resources/Bucket: Property Tags should be a list [cfn-lint/E3012]",
+		"error false checkov 8 Resources/Logs: Ensure the S3 bucket has access logging enabled [checkov/CKV_AWS_18]",
+		"warning true cfn-nag 4 Resources/Bucket: S3 Bucket should have access logging configured [cfn-nag/W35]",
+		"warning false cfn-nag 8 Resources/Logs: S3 Bucket should have access logging configured [cfn-nag/W35]",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+
+	if _, err := lint.FindExternalLinter("tflint"); err == nil {
+		t.Error("expected an error for an unknown linter")
+	}
+
+	os.Remove(filepath.Join(dir, "checkov"))
+	checkov, _ := lint.FindExternalLinter("checkov")
+	if _, err := checkov.Run(context.Background(), "template.yaml"); err == nil || !strings.Contains(err.Error(), "not installed") {
+		t.Errorf("expected an error for a missing linter, got %v", err)
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	findings := []lint.Finding{
+		{Rule: "cidr", Severity: lint.Error, Element: "Resources/Subnet", Message: "outside the VPC", Line: 7},
+		{Rule: "cfn-nag/W35", Source: "cfn-nag", Severity: lint.Warning, Element: "Resources/Bucket",
+			Message: "no access logging", Suppressed: true, Reason: "logs are in CloudTrail"},
+	}
+
+	out, err := lint.SARIF("templates/app.yaml", findings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						Id string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleId    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							Uri string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+				Suppressions []struct {
+					Justification string `json:"justification"`
+				} `json:"suppressions"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out, &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) != 2 || len(log.Runs[0].Tool.Driver.Rules) != 2 {
+		t.Fatalf("unexpected log:\n%s", out)
+	}
+
+	first, second := log.Runs[0].Results[0], log.Runs[0].Results[1]
+
+	if first.Level != "error" || first.Locations[0].PhysicalLocation.ArtifactLocation.Uri != "templates/app.yaml" ||
+		first.Locations[0].PhysicalLocation.Region.StartLine != 7 || len(first.Suppressions) != 0 {
+		t.Errorf("unexpected result for cidr:\n%s", out)
+	}
+
+	if second.RuleId != "cfn-nag/W35" || second.Level != "warning" || second.Locations[0].PhysicalLocation.Region != nil ||
+		len(second.Suppressions) != 1 || second.Suppressions[0].Justification != "logs are in CloudTrail" {
+		t.Errorf("unexpected result for cfn-nag/W35:\n%s", out)
+	}
+}
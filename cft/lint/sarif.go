@@ -0,0 +1,34 @@
+package lint
+
+import "github.com/aws-cloudformation/rain/cft/sarif"
+
+// SARIF returns the findings for the template in the file as a SARIF 2.1.0 log,
+// which code scanning tools such as GitHub's read.
+// Every finding is reported by one run of rain, including those of external linters,
+// whose rules are named after the linter and have its name as their source property.
+func SARIF(fn string, findings []Finding) ([]byte, error) {
+	rules := make([]sarif.Rule, 0, len(Rules))
+	for _, r := range Rules {
+		rules = append(rules, sarif.Rule{Id: r.Name, Description: r.Description})
+	}
+
+	results := make([]sarif.Result, 0, len(findings))
+	for _, f := range findings {
+		if f.Source != "" {
+			rules = append(rules, sarif.Rule{Id: f.Rule, Source: f.Source})
+		}
+
+		results = append(results, sarif.Result{
+			Rule:          f.Rule,
+			Level:         sarif.LevelOf(string(f.Severity)),
+			Message:       f.Message,
+			File:          fn,
+			Line:          f.Line,
+			Element:       f.Element,
+			Suppressed:    f.Suppressed,
+			Justification: f.Reason,
+		})
+	}
+
+	return sarif.Log(rules, results)
+}
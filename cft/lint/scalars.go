@@ -0,0 +1,31 @@
+package lint
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/format"
+)
+
+func init() {
+	register(Rule{
+		Name:        "yaml-scalars",
+		Description: "Unquoted values are not read differently by CloudFormation's YAML 1.1 parser, e.g. yes, 0123 or 1:30",
+		Check:       checkScalars,
+	})
+}
+
+func checkScalars(t cft.Template, opts Options) []Finding {
+	findings := make([]Finding, 0)
+
+	for _, p := range format.Pitfalls(t) {
+		findings = append(findings, Finding{
+			Severity: Warning,
+			Element:  fmt.Sprintf("line %d", p.Line),
+			Line:     p.Line,
+			Message:  p.Message + "; quote it, or run rain fmt to fix it",
+		})
+	}
+
+	return findings
+}
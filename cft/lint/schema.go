@@ -0,0 +1,142 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	register(Rule{
+		Name:        "resource-properties",
+		Description: "Resources set only the properties in their type's registry schema, including all required properties",
+		Check:       checkResourceProperties,
+	})
+}
+
+// resourceSchema is the part of a registry schema that the rule needs
+type resourceSchema struct {
+	Properties         map[string]json.RawMessage `json:"properties"`
+	Required           []string                   `json:"required"`
+	ReadOnlyProperties []string                   `json:"readOnlyProperties"`
+}
+
+// hasSchema returns false for types that aren't in the registry
+func hasSchema(typeName string) bool {
+	if typeName == "AWS::CloudFormation::CustomResource" ||
+		strings.HasPrefix(typeName, "Custom::") ||
+		strings.HasPrefix(typeName, "AWS::Serverless::") {
+		return false
+	}
+
+	return len(strings.Split(typeName, "::")) == 3
+}
+
+func checkResourceProperties(t cft.Template, opts Options) []Finding {
+	findings := make([]Finding, 0)
+
+	if opts.Schema == nil {
+		return findings
+	}
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil || resources.Kind != yaml.MappingNode {
+		return findings
+	}
+
+	schemas := make(map[string]*resourceSchema)
+	failed := make(map[string]bool)
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		name := resources.Content[i].Value
+		resource := resources.Content[i+1]
+		element := fmt.Sprintf("Resources/%s", name)
+
+		// Modules and other directives don't have a plain type
+		typeNode := lookup(resource, "Type")
+		if typeNode == nil || typeNode.Kind != yaml.ScalarNode || strings.HasPrefix(typeNode.Tag, "!") && !strings.HasPrefix(typeNode.Tag, "!!") {
+			continue
+		}
+
+		typeName := typeNode.Value
+		if !hasSchema(typeName) || failed[typeName] {
+			continue
+		}
+
+		schema, ok := schemas[typeName]
+		if !ok {
+			source, err := opts.Schema(typeName)
+			if err == nil {
+				schema = &resourceSchema{}
+				err = json.Unmarshal([]byte(source), schema)
+			}
+			if err != nil {
+				failed[typeName] = true
+				findings = append(findings, Finding{
+					Severity: Warning,
+					Element:  element,
+					Message:  fmt.Sprintf("unable to get the schema for %s: %s", typeName, err),
+				})
+				continue
+			}
+			schemas[typeName] = schema
+		}
+
+		props := lookup(resource, "Properties")
+		if props == nil {
+			props = &yaml.Node{Kind: yaml.MappingNode}
+		}
+
+		// Properties that are set with an intrinsic function can't be checked
+		if props.Kind != yaml.MappingNode || len(props.Content) == 2 && strings.HasPrefix(props.Content[0].Value, "Fn::") {
+			continue
+		}
+
+		set := make(map[string]bool)
+		for j := 0; j < len(props.Content)-1; j += 2 {
+			prop := props.Content[j].Value
+			set[prop] = true
+
+			if _, ok := schema.Properties[prop]; !ok {
+				findings = append(findings, Finding{
+					Severity: Error,
+					Element:  element + "/Properties/" + prop,
+					Message:  fmt.Sprintf("%s is not a property of %s", prop, typeName),
+				})
+				continue
+			}
+
+			for _, readOnly := range schema.ReadOnlyProperties {
+				if readOnly == "/properties/"+prop {
+					findings = append(findings, Finding{
+						Severity: Warning,
+						Element:  element + "/Properties/" + prop,
+						Message:  fmt.Sprintf("%s is read-only, so CloudFormation ignores it", prop),
+					})
+				}
+			}
+		}
+
+		missing := make([]string, 0)
+		for _, required := range schema.Required {
+			if !set[required] {
+				missing = append(missing, required)
+			}
+		}
+		sort.Strings(missing)
+
+		for _, prop := range missing {
+			findings = append(findings, Finding{
+				Severity: Error,
+				Element:  element,
+				Message:  fmt.Sprintf("%s requires the %s property", typeName, prop),
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,215 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/summary"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	register(Rule{
+		Name:        "sub-variables",
+		Description: "Every ${Var} in an Fn::Sub string is a parameter, resource, pseudo parameter or a key of the Sub's map",
+		Check:       checkSubVariables,
+	})
+}
+
+// subPseudoParameters are the pseudo parameters that can be used in Fn::Sub strings
+var subPseudoParameters = []string{
+	"AWS::AccountId",
+	"AWS::Partition",
+	"AWS::Region",
+	"AWS::StackId",
+	"AWS::StackName",
+	"AWS::URLSuffix",
+}
+
+func checkSubVariables(t cft.Template, opts Options) []Finding {
+	findings := make([]Finding, 0)
+
+	// Transforms like AWS::Serverless-2016-10-31 add resources that aren't in the template
+	for _, name := range summary.Transforms(t) {
+		if name != "AWS::LanguageExtensions" {
+			return findings
+		}
+	}
+
+	parameters := names(t, cft.Parameters)
+	resources := names(t, cft.Resources)
+
+	// Fn::ForEach loops create resources whose names depend on the collection,
+	// and their fragments can use the loop's identifier
+	loops := false
+	for _, name := range resources {
+		if cft.IsForEach(name) {
+			loops = true
+		}
+	}
+
+	check := func(element string, s *yaml.Node, vars map[string]bool) {
+		words, err := parse.ParseSub(s.Value)
+		if err != nil {
+			findings = append(findings, Finding{
+				Severity: Error,
+				Element:  element,
+				Line:     s.Line,
+				Message:  fmt.Sprintf("invalid Fn::Sub string '%s': %s", s.Value, err),
+			})
+			return
+		}
+
+		for _, w := range words {
+			message := ""
+
+			switch w.T {
+			case parse.AWS:
+				name := "AWS::" + w.W
+				if !slices.Contains(subPseudoParameters, name) {
+					message = fmt.Sprintf("${%s} is not a pseudo parameter that Fn::Sub can use", name)
+					if suggestion := closest(name, subPseudoParameters); suggestion != "" {
+						message += fmt.Sprintf("; did you mean ${%s}?", suggestion)
+					}
+				}
+			case parse.REF:
+				if !vars[w.W] && !slices.Contains(parameters, w.W) && !slices.Contains(resources, w.W) && !loops {
+					message = fmt.Sprintf("${%s} is not a parameter, resource or variable", w.W)
+					if suggestion := closest(w.W, append(append(keys(vars), parameters...), resources...)); suggestion != "" {
+						message += fmt.Sprintf("; did you mean ${%s}?", suggestion)
+					}
+				}
+			case parse.GETATT:
+				name, _, _ := strings.Cut(w.W, ".")
+				if !vars[w.W] && !slices.Contains(resources, name) && !loops {
+					message = fmt.Sprintf("${%s} refers to %s, which is not a resource", w.W, name)
+					if suggestion := closest(name, resources); suggestion != "" {
+						message += fmt.Sprintf("; did you mean %s?", suggestion)
+					}
+				}
+			}
+
+			if message != "" {
+				findings = append(findings, Finding{
+					Severity: Error,
+					Element:  element,
+					Line:     s.Line,
+					Message:  message,
+				})
+			}
+		}
+	}
+
+	var walk func(element string, n *yaml.Node)
+	walk = func(element string, n *yaml.Node) {
+		if n.Kind == yaml.MappingNode && len(n.Content) == 2 && n.Content[0].Value == "Fn::Sub" {
+			arg := n.Content[1]
+			vars := make(map[string]bool)
+
+			if arg.Kind == yaml.SequenceNode && len(arg.Content) == 2 {
+				if m := arg.Content[1]; m.Kind == yaml.MappingNode {
+					for i := 0; i < len(m.Content)-1; i += 2 {
+						vars[m.Content[i].Value] = true
+						walk(element, m.Content[i+1])
+					}
+				}
+				arg = arg.Content[0]
+			}
+
+			if arg.Kind == yaml.ScalarNode {
+				check(element, arg, vars)
+			}
+
+			return
+		}
+
+		for _, child := range n.Content {
+			walk(element, child)
+		}
+	}
+
+	for _, section := range []cft.Section{cft.Resources, cft.Outputs, cft.Conditions} {
+		s, err := t.GetSection(section)
+		if err != nil || s.Kind != yaml.MappingNode {
+			continue
+		}
+
+		for i := 0; i < len(s.Content)-1; i += 2 {
+			walk(fmt.Sprintf("%s/%s", section, s.Content[i].Value), s.Content[i+1])
+		}
+	}
+
+	return findings
+}
+
+// names returns the names of the entries in a section of the template
+func names(t cft.Template, section cft.Section) []string {
+	out := make([]string, 0)
+
+	s, err := t.GetSection(section)
+	if err != nil || s.Kind != yaml.MappingNode {
+		return out
+	}
+
+	for i := 0; i < len(s.Content)-1; i += 2 {
+		out = append(out, s.Content[i].Value)
+	}
+
+	return out
+}
+
+// keys returns the keys of a Sub's map in order
+func keys(m map[string]bool) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// closest returns the candidate that is most like name, if it is close enough to be a typo
+func closest(name string, candidates []string) string {
+	best := ""
+	bestDistance := 3
+
+	for _, c := range candidates {
+		if strings.EqualFold(c, name) {
+			return c
+		}
+
+		if d := distance(strings.ToLower(name), strings.ToLower(c)); d < bestDistance {
+			best = c
+			bestDistance = d
+		}
+	}
+
+	return best
+}
+
+// distance returns the Levenshtein distance between two strings
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
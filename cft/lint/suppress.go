@@ -0,0 +1,223 @@
+package lint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/scan"
+	"github.com/aws-cloudformation/rain/cft/suppress"
+	"gopkg.in/yaml.v3"
+)
+
+// SuppressRule is the name of the findings about suppression comments themselves
+const SuppressRule = "suppressions"
+
+// suppressRe matches a comment that accepts findings on the line after it:
+//
+//	# rain-disable-next-line cidr,export-names reason=the range is shared with another VPC
+var suppressRe = regexp.MustCompile(`^#\s*rain-disable-next-line\s+([\w\-/,*]+)(?:\s+reason=(.*))?$`)
+
+// Suppression accepts the findings of some rules on one line of the template
+type Suppression struct {
+	// Rules are the names of the rules, or * for all of them
+	Rules []string `json:"rules"`
+
+	// Line is the line of the template that the suppression applies to
+	Line int `json:"line"`
+
+	Reason string `json:"reason,omitempty"`
+
+	// Comment is the line of the comment
+	Comment int `json:"comment"`
+}
+
+func (s Suppression) matches(f Finding) bool {
+	if f.Line != s.Line || f.Rule == SuppressRule {
+		return false
+	}
+
+	for _, rule := range s.Rules {
+		if rule == "*" || rule == f.Rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Suppressions returns the suppression comments in the template
+func Suppressions(t cft.Template) []Suppression {
+	out := make([]Suppression, 0)
+
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.HeadComment != "" && n.Kind != yaml.DocumentNode {
+			lines := strings.Split(n.HeadComment, "\n")
+			for i, line := range lines {
+				m := suppressRe.FindStringSubmatch(strings.TrimSpace(line))
+				if m == nil {
+					continue
+				}
+
+				out = append(out, Suppression{
+					Rules:   strings.Split(m[1], ","),
+					Line:    n.Line,
+					Reason:  strings.Trim(strings.TrimSpace(m[2]), `"'`),
+					Comment: n.Line - len(lines) + i,
+				})
+			}
+		}
+
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+
+	if t.Node != nil {
+		walk(t.Node)
+	}
+
+	return out
+}
+
+// suppressComments marks the findings that a suppression comment accepts,
+// and reports suppressions that have no reason or that don't accept anything
+func suppressComments(findings []Finding, suppressions []Suppression) []Finding {
+	used := make([]bool, len(suppressions))
+
+	for i := range findings {
+		for j, s := range suppressions {
+			if s.matches(findings[i]) {
+				findings[i].Suppressed = true
+				findings[i].Reason = s.Reason
+				used[j] = true
+				break
+			}
+		}
+	}
+
+	for j, s := range suppressions {
+		element := fmt.Sprintf("line %d", s.Comment)
+
+		if s.Reason == "" {
+			findings = append(findings, Finding{
+				Rule:     SuppressRule,
+				Severity: Warning,
+				Element:  element,
+				Line:     s.Comment,
+				Message:  "suppression has no reason; add reason=... to explain why the finding is accepted",
+			})
+		}
+
+		if !used[j] {
+			findings = append(findings, Finding{
+				Rule:     SuppressRule,
+				Severity: Info,
+				Element:  element,
+				Line:     s.Comment,
+				Message:  fmt.Sprintf("suppression of %s does not match any finding on the next line", strings.Join(s.Rules, ",")),
+			})
+		}
+	}
+
+	return findings
+}
+
+// suppressMetadata marks the findings that the Rain::Suppressions in the template's Metadata
+// and its resources' Metadata accept, and reports suppressions that don't apply.
+// The same suppressions are read by rain scan, so ones that match nothing here are not reported.
+func suppressMetadata(findings []Finding, suppressions []suppress.Suppression) []Finding {
+	for i := range findings {
+		if findings[i].Suppressed || findings[i].Rule == SuppressRule {
+			continue
+		}
+
+		resource := ""
+		if parts := strings.Split(findings[i].Element, "/"); len(parts) > 1 && parts[0] == string(cft.Resources) {
+			resource = parts[1]
+		}
+
+		for _, s := range suppressions {
+			if s.Matches(findings[i].Rule, resource) {
+				findings[i].Suppressed = true
+				findings[i].Reason = s.Reason
+				break
+			}
+		}
+	}
+
+	for _, s := range suppressions {
+		element := string(cft.Metadata)
+		if s.Resource != "" {
+			element = fmt.Sprintf("%s/%s", cft.Resources, s.Resource)
+		}
+
+		if !s.Active() {
+			findings = append(findings, Finding{
+				Rule:     SuppressRule,
+				Severity: Warning,
+				Element:  element,
+				Line:     s.Line,
+				Message:  s.Problem,
+			})
+			continue
+		}
+
+		for _, rule := range s.Rules {
+			if rule != "*" && !knownRule(rule) {
+				findings = append(findings, Finding{
+					Rule:     SuppressRule,
+					Severity: Warning,
+					Element:  element,
+					Line:     s.Line,
+					Message:  fmt.Sprintf("suppression of %s does not name a lint or scan rule", rule),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// knownRule returns true if a lint rule or a scan rule has the name,
+// or if it names a rule of an external linter
+func knownRule(name string) bool {
+	return isExternalRule(name) ||
+		slices.ContainsFunc(Rules, func(r Rule) bool { return r.Name == name }) ||
+		slices.ContainsFunc(scan.Rules, func(r scan.Rule) bool { return r.Name == name })
+}
+
+// elementLine returns the line of a template element like "Resources/Bucket", or 0
+func elementLine(t cft.Template, element string) int {
+	if t.Node == nil || len(t.Node.Content) == 0 {
+		return 0
+	}
+
+	n := t.Node.Content[0]
+	line := 0
+
+	for _, name := range strings.Split(element, "/") {
+		if n.Kind != yaml.MappingNode {
+			break
+		}
+
+		found := false
+		for i := 0; i < len(n.Content)-1; i += 2 {
+			if n.Content[i].Value == name {
+				line = n.Content[i].Line
+				n = n.Content[i+1]
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			break
+		}
+	}
+
+	return line
+}
@@ -1,6 +1,7 @@
 package pkg
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -12,12 +13,17 @@ import (
 
 func init() {
 	registry["Resources/*|Type==AWS::ApiGateway::RestApi/Properties/BodyS3Location"] = wrapObject("Bucket", "Key", false)
+	registry["Resources/*|Type==AWS::AppSync::FunctionConfiguration/Properties/CodeS3Location"] = wrapS3URI
 	registry["Resources/*|Type==AWS::AppSync::FunctionConfiguration/Properties/RequestMappingTemplateS3Location"] = wrapS3URI
 	registry["Resources/*|Type==AWS::AppSync::FunctionConfiguration/Properties/ResponseMappingTemplateS3Location"] = wrapS3URI
 	registry["Resources/*|Type==AWS::AppSync::GraphQLSchema/Properties/DefinitionS3Location"] = wrapS3URI
+	registry["Resources/*|Type==AWS::AppSync::Resolver/Properties/CodeS3Location"] = wrapS3URI
 	registry["Resources/*|Type==AWS::AppSync::Resolver/Properties/RequestMappingTemplateS3Location"] = wrapS3URI
 	registry["Resources/*|Type==AWS::AppSync::Resolver/Properties/ResponseMappingTemplateS3Location"] = wrapS3URI
+	registry["Resources/*|Type==AWS::CloudFormation::ModuleVersion/Properties/ModulePackage"] = wrapS3ZipURI
+	registry["Resources/*|Type==AWS::CloudFormation::ResourceVersion/Properties/SchemaHandlerPackage"] = wrapS3ZipURI
 	registry["Resources/*|Type==AWS::CloudFormation::Stack/Properties/TemplateURL"] = wrapTemplate
+	registry["Resources/*|Type==AWS::CodeCommit::Repository/Properties/Code/S3"] = wrapObject("Bucket", "Key", true)
 	registry["Resources/*|Type==AWS::ElasticBeanstalk::ApplicationVersion/Properties/SourceBundle"] = wrapObject("S3Bucket", "S3Key", false)
 	registry["Resources/*|Type==AWS::Glue::Job/Properties/Command/ScriptLocation"] = wrapS3URI
 	registry["Resources/*|Type==AWS::Lambda::Function/Properties/Code"] = wrapObject("S3Bucket", "S3Key", true)
@@ -25,7 +31,9 @@ func init() {
 	registry["Resources/*|Type==AWS::Serverless::Api/Properties/DefinitionUri"] = wrapS3URI
 	registry["Resources/*|Type==AWS::Serverless::Application/Properties/Location"] = wrapTemplate
 	registry["Resources/*|Type==AWS::Serverless::Function/Properties/CodeUri"] = wrapS3ZipURI
+	registry["Resources/*|Type==AWS::Serverless::HttpApi/Properties/DefinitionUri"] = wrapS3URI
 	registry["Resources/*|Type==AWS::Serverless::LayerVersion/Properties/ContentUri"] = wrapS3ZipURI
+	registry["Resources/*|Type==AWS::Serverless::StateMachine/Properties/DefinitionUri"] = wrapS3URI
 	registry["Resources/*|Type==AWS::ServerlessRepo::Application/Properties/LicenseUrl"] = wrapS3URI
 	registry["Resources/*|Type==AWS::ServerlessRepo::Application/Properties/ReadmeUrl"] = wrapS3URI
 	registry["Resources/*|Type==AWS::StepFunctions::StateMachine/Properties/DefinitionS3Location"] = wrapObject("Bucket", "Key", false)
@@ -51,6 +59,10 @@ func wrapS3ZipURI(ctx *directiveContext) (bool, error) {
 		return false, nil
 	}
 
+	if strings.HasPrefix(n.Value, "s3://") {
+		return false, nil // Already an s3 uri
+	}
+
 	return wrapS3(n, root, s3Options{
 		Path:   n.Value,
 		Format: s3URI,
@@ -94,6 +106,14 @@ func wrapObject(bucket, key string, forceZip bool) directiveFunc {
 	}
 }
 
+// nestedTemplates holds the absolute paths of the nested stack templates
+// that are currently being packaged, so that cycles can be detected
+var nestedTemplates = map[string]bool{}
+
+// wrapTemplate packages a nested stack's local template, recursively,
+// and replaces the reference to it with the template's S3 URL.
+// CloudFormation only accepts nested stack templates from S3,
+// so even small templates have to be uploaded rather than inlined.
 func wrapTemplate(ctx *directiveContext) (bool, error) {
 	n := ctx.n
 	root := ctx.rootDir
@@ -102,8 +122,8 @@ func wrapTemplate(ctx *directiveContext) (bool, error) {
 		return false, nil
 	}
 
-	if strings.HasPrefix(n.Value, "http://") || strings.HasPrefix(n.Value, "https://") {
-		return false, nil // Already http
+	if strings.HasPrefix(n.Value, "http://") || strings.HasPrefix(n.Value, "https://") || strings.HasPrefix(n.Value, "s3://") {
+		return false, nil // Already uploaded
 	}
 
 	path := n.Value
@@ -111,9 +131,28 @@ func wrapTemplate(ctx *directiveContext) (bool, error) {
 		path = filepath.Join(root, path)
 	}
 
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	// The same template can be nested more than once
+	artifactName := "template:" + path
+	if result, ok := uploads[artifactName]; ok {
+		config.Debugf("Using existing upload for nested template: %s\n", path)
+		*n = yaml.Node{Kind: yaml.ScalarNode, Value: result.HTTP()}
+		return true, nil
+	}
+
+	if nestedTemplates[path] {
+		return false, fmt.Errorf("nested stack template '%s' includes itself", n.Value)
+	}
+
+	nestedTemplates[path] = true
+	defer delete(nestedTemplates, path)
+
 	tmpl, err := File(path)
 	if err != nil {
-		return false, err
+		return false, fmt.Errorf("unable to package nested stack template '%s': %w", n.Value, err)
 	}
 
 	f, err := os.CreateTemp(os.TempDir(), "*.template")
@@ -130,8 +169,14 @@ func wrapTemplate(ctx *directiveContext) (bool, error) {
 		return false, err
 	}
 
-	return wrapS3(n, root, s3Options{
-		Path:   f.Name(),
-		Format: s3Http,
-	}), nil
+	result, err := upload(root, f.Name(), false)
+	if err != nil {
+		return false, fmt.Errorf("unable to upload nested stack template '%s': %w", n.Value, err)
+	}
+
+	uploads[artifactName] = result
+
+	*n = yaml.Node{Kind: yaml.ScalarNode, Value: result.HTTP()}
+
+	return true, nil
 }
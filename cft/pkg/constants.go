@@ -0,0 +1,136 @@
+package pkg
+
+// This file implements the Rain::Constants section
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/node"
+	"gopkg.in/yaml.v3"
+)
+
+// ConstantsSection is the template section that declares constants.
+// Its values replace ${Rain::Name} anywhere in the template when it is packaged,
+// so that values that can't be parameters, like literals in policy documents,
+// can still be kept in one place. A constant can use the constants declared before it.
+const ConstantsSection = "Rain::Constants"
+
+// Constants are set outside the template, e.g. with rain pkg --constant,
+// and take the place of the template's constants with the same names
+var Constants map[string]string
+
+var constantRe = regexp.MustCompile(`\$\{Rain::([A-Za-z0-9_]+)\}`)
+
+// readConstants removes the constants section from the template and returns its values,
+// with references to other constants replaced
+func readConstants(templateNode *yaml.Node) (map[string]*yaml.Node, error) {
+	constants := make(map[string]*yaml.Node)
+	for name, value := range Constants {
+		constants[name] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	}
+
+	root := templateNode
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	if root.Kind == yaml.MappingNode {
+		for i := 0; i < len(root.Content)-1; i += 2 {
+			if root.Content[i].Value != ConstantsSection {
+				continue
+			}
+
+			section := root.Content[i+1]
+			if section.Kind != yaml.MappingNode {
+				return nil, fmt.Errorf("expected %s to be a mapping", ConstantsSection)
+			}
+
+			for j := 0; j < len(section.Content)-1; j += 2 {
+				name := section.Content[j].Value
+				if _, ok := Constants[name]; ok {
+					continue
+				}
+
+				value := node.Clone(section.Content[j+1])
+
+				// Only the constants declared earlier can be used
+				if err := replaceConstants(value, constants, true); err != nil {
+					return nil, fmt.Errorf("constant %s: %v", name, err)
+				}
+
+				constants[name] = value
+			}
+
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			break
+		}
+	}
+
+	return constants, nil
+}
+
+// replaceConstants replaces ${Rain::Name} in the scalars of n.
+// A scalar that is only a reference takes the constant's value, which can be a list or a mapping.
+// If strict is set, references to undeclared constants are an error; otherwise they are left for later.
+func replaceConstants(n *yaml.Node, constants map[string]*yaml.Node, strict bool) error {
+	switch n.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode, yaml.MappingNode:
+		for _, child := range n.Content {
+			if err := replaceConstants(child, constants, strict); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.ScalarNode:
+	default:
+		return nil
+	}
+
+	if !strings.Contains(n.Value, "${Rain::") {
+		return nil
+	}
+
+	if m := constantRe.FindStringSubmatch(n.Value); m != nil && m[0] == n.Value {
+		value, ok := constants[m[1]]
+		if !ok {
+			if strict {
+				return fmt.Errorf("unknown constant: %s", m[1])
+			}
+			return nil
+		}
+
+		*n = *node.Clone(value)
+		return nil
+	}
+
+	var err error
+	replaced := constantRe.ReplaceAllStringFunc(n.Value, func(ref string) string {
+		name := constantRe.FindStringSubmatch(ref)[1]
+
+		value, ok := constants[name]
+		if !ok {
+			if strict && err == nil {
+				err = fmt.Errorf("unknown constant: %s", name)
+			}
+			return ref
+		}
+
+		if value.Kind != yaml.ScalarNode {
+			if err == nil {
+				err = fmt.Errorf("constant %s is not a string, so it can only be used on its own", name)
+			}
+			return ref
+		}
+
+		return value.Value
+	})
+	if err != nil {
+		return err
+	}
+
+	n.Value = replaced
+
+	return nil
+}
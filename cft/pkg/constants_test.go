@@ -0,0 +1,92 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestConstants(t *testing.T) {
+	source := `
+Rain::Constants:
+  Prefix: app
+  BucketName: ${Rain::Prefix}-assets
+  Actions:
+    - s3:GetObject
+    - s3:ListBucket
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: ${Rain::BucketName}
+  Policy:
+    Type: AWS::IAM::ManagedPolicy
+    Properties:
+      PolicyDocument:
+        Statement:
+          - Action: ${Rain::Actions}
+            Resource: !Sub arn:${AWS::Partition}:s3:::${Rain::BucketName}/*
+`
+
+	expected := `Resources:
+    Bucket:
+        Type: AWS::S3::Bucket
+        Properties:
+            BucketName: prod-assets
+    Policy:
+        Type: AWS::IAM::ManagedPolicy
+        Properties:
+            PolicyDocument:
+                Statement:
+                    - Action:
+                        - s3:GetObject
+                        - s3:ListBucket
+                      Resource: !Sub arn:${AWS::Partition}:s3:::prod-assets/*
+`
+
+	Constants = map[string]string{"Prefix": "prod"}
+	defer func() { Constants = nil }()
+
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(source), &n); err != nil {
+		t.Fatal(err)
+	}
+
+	constants, err := readConstants(&n)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := replaceConstants(&n, constants, true); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _ := yaml.Marshal(&n)
+	if string(out) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestConstantErrors(t *testing.T) {
+	for source, message := range map[string]string{
+		"Rain::Constants:\n  A: ${Rain::B}\n  B: b\n":         "unknown constant: B",
+		"Rain::Constants:\n  L: [a, b]\nName: x-${Rain::L}\n": "not a string",
+		"Name: ${Rain::Missing}\n":                            "unknown constant: Missing",
+		"Rain::Constants: [a]\n":                              "expected Rain::Constants to be a mapping",
+	} {
+		var n yaml.Node
+		if err := yaml.Unmarshal([]byte(source), &n); err != nil {
+			t.Fatal(err)
+		}
+
+		constants, err := readConstants(&n)
+		if err == nil {
+			err = replaceConstants(&n, constants, true)
+		}
+
+		if err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%q: expected an error containing %q, got %v", source, message, err)
+		}
+	}
+}
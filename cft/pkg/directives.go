@@ -3,7 +3,10 @@ package pkg
 // This file contains implementations for `!Rain::` directives
 
 import (
+	"bytes"
 	"embed"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -34,6 +37,18 @@ type s3Options struct {
 	Format         s3Format `yaml:"Format"`
 }
 
+// Encodings for !Rain::Embed
+const (
+	embedRaw    = "raw"
+	embedBase64 = "base64"
+	embedJSON   = "json"
+)
+
+type embedOptions struct {
+	Path     string `yaml:"Path"`
+	Encoding string `yaml:"Encoding"`
+}
+
 type directiveContext struct {
 	n       *yaml.Node
 	rootDir string
@@ -60,13 +75,34 @@ func init() {
 }
 
 func includeString(ctx *directiveContext) (bool, error) {
+	n := ctx.n
+
+	options := embedOptions{Encoding: embedRaw}
+
+	if len(n.Content) == 2 && n.Content[1].Kind == yaml.MappingNode {
+		err := n.Content[1].Decode(&options)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		path, err := expectString(n)
+		if err != nil {
+			return false, err
+		}
+		options.Path = path
+	}
+
+	content, _, err := readFile(options.Path, ctx.rootDir)
+	if err != nil {
+		return false, err
+	}
 
-	content, _, err := expectFile(ctx.n, ctx.rootDir)
+	value, err := encodeEmbed(content, options.Encoding)
 	if err != nil {
 		return false, err
 	}
 
-	err = ctx.n.Encode(strings.TrimSpace(string(content)))
+	err = n.Encode(value)
 	if err != nil {
 		return false, err
 	}
@@ -74,6 +110,33 @@ func includeString(ctx *directiveContext) (bool, error) {
 	return true, nil
 }
 
+// encodeEmbed returns the content of an embedded file as a string.
+// Raw content is trimmed; base64 keeps every byte of the file, as user data
+// and binary files need; json escapes the content so that it can be placed
+// inside a JSON string, for example in a policy or state machine definition.
+func encodeEmbed(content []byte, encoding string) (string, error) {
+	switch strings.ToLower(encoding) {
+	case "", embedRaw:
+		return strings.TrimSpace(string(content)), nil
+	case embedBase64:
+		return base64.StdEncoding.EncodeToString(content), nil
+	case embedJSON:
+		var buf bytes.Buffer
+		enc := json.NewEncoder(&buf)
+		enc.SetEscapeHTML(false)
+		err := enc.Encode(strings.TrimSpace(string(content)))
+		if err != nil {
+			return "", err
+		}
+
+		// Drop the quotes and newline that surround the encoded string
+		escaped := strings.TrimSpace(buf.String())
+		return escaped[1 : len(escaped)-1], nil
+	default:
+		return "", fmt.Errorf("unknown encoding '%s' for Rain::Embed; use %s, %s, or %s", encoding, embedRaw, embedBase64, embedJSON)
+	}
+}
+
 func includeLiteral(ctx *directiveContext) (bool, error) {
 	content, path, err := expectFile(ctx.n, ctx.rootDir)
 	if err != nil {
@@ -0,0 +1,29 @@
+package pkg
+
+import "testing"
+
+func TestEncodeEmbed(t *testing.T) {
+	content := []byte("#!/bin/bash\necho \"<ready>\"\n")
+
+	cases := map[string]string{
+		"":       "#!/bin/bash\necho \"<ready>\"",
+		"raw":    "#!/bin/bash\necho \"<ready>\"",
+		"base64": "IyEvYmluL2Jhc2gKZWNobyAiPHJlYWR5PiIK",
+		"JSON":   `#!/bin/bash\necho \"<ready>\"`,
+	}
+
+	for encoding, expected := range cases {
+		actual, err := encodeEmbed(content, encoding)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != expected {
+			t.Errorf("%q: expected %q, got %q", encoding, expected, actual)
+		}
+	}
+
+	if _, err := encodeEmbed(content, "hex"); err == nil {
+		t.Error("expected an error for an unknown encoding")
+	}
+}
@@ -6,12 +6,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"path/filepath"
 	"strings"
 
 	"github.com/aws-cloudformation/rain/cft"
 	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/s3"
 	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/gitmodule"
 	"github.com/aws-cloudformation/rain/internal/node"
 	"github.com/aws-cloudformation/rain/internal/s11n"
 	"gopkg.in/yaml.v3"
@@ -731,6 +734,37 @@ func downloadModule(uri string) ([]byte, error) {
 	return content, nil
 }
 
+// splitS3URI returns the bucket and key of an s3://bucket/key URI
+func splitS3URI(uri string) (string, string, error) {
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(uri, "s3://"), "/")
+	if !ok || bucket == "" || key == "" {
+		return "", "", fmt.Errorf("expected s3://bucket/key: %s", uri)
+	}
+
+	return bucket, key, nil
+}
+
+// isRemote returns true if the module is downloaded from a URL rather than read from a file
+func isRemote(uri string) bool {
+	return strings.HasPrefix(uri, "https://") || strings.HasPrefix(uri, "s3://")
+}
+
+// downloadRemoteModule downloads a module from an https:// or s3:// URI
+func downloadRemoteModule(uri string) ([]byte, error) {
+	if !strings.HasPrefix(uri, "s3://") {
+		return downloadModule(uri)
+	}
+
+	bucket, key, err := splitS3URI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Debugf("Downloading %s", uri)
+
+	return s3.GetObject(bucket, key)
+}
+
 // Type: !Rain::Module
 func module(ctx *directiveContext) (bool, error) {
 
@@ -751,6 +785,19 @@ func module(ctx *directiveContext) (bool, error) {
 	}
 
 	uri := n.Content[1].Value
+
+	// Modules referenced by an allowed remote module are allowed too
+	if templateFiles == nil && ctx.baseUri == "" && !gitmodule.InCache(root) {
+		source := uri
+		if !isRemote(uri) && !gitmodule.IsSource(uri) {
+			source = filepath.Join(root, uri)
+		}
+
+		if err := Features.CheckModule(source); err != nil {
+			return false, err
+		}
+	}
+
 	var content []byte
 	var err error
 	var path string
@@ -758,10 +805,23 @@ func module(ctx *directiveContext) (bool, error) {
 
 	baseUri := ctx.baseUri
 
-	// Is this a local file or a URL?
-	if strings.HasPrefix(uri, "https://") {
+	// Is this a local file, a git repository, or a URL?
+	if gitmodule.IsSource(uri) {
+		path, err = gitmodule.Fetch(uri)
+		if err != nil {
+			return false, fmt.Errorf("unable to fetch module %s: %v", uri, err)
+		}
+
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return false, err
+		}
+
+		// Relative paths in the module refer to files in the same repository
+		newRootDir = filepath.Dir(path)
+	} else if isRemote(uri) {
 
-		content, err = downloadModule(uri)
+		content, err = downloadRemoteModule(uri)
 		if err != nil {
 			return false, err
 		}
@@ -777,7 +837,7 @@ func module(ctx *directiveContext) (bool, error) {
 		if baseUri != "" {
 			// If we have a base URL, prepend it to the relative path
 			uri = baseUri + "/" + uri
-			content, err = downloadModule(uri)
+			content, err = downloadRemoteModule(uri)
 			if err != nil {
 				return false, err
 			}
@@ -0,0 +1,20 @@
+package pkg
+
+import "testing"
+
+func TestSplitS3URI(t *testing.T) {
+	bucket, key, err := splitS3URI("s3://my-modules/bucket/module.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bucket != "my-modules" || key != "bucket/module.yaml" {
+		t.Errorf("unexpected bucket %q and key %q", bucket, key)
+	}
+
+	for _, uri := range []string{"s3://my-modules", "s3://my-modules/", "s3:///module.yaml"} {
+		if _, _, err := splitS3URI(uri); err == nil {
+			t.Errorf("expected an error for %s", uri)
+		}
+	}
+}
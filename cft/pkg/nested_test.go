@@ -0,0 +1,17 @@
+package pkg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNestedCycle(t *testing.T) {
+	_, err := File("./tmpl/nested-cycle-template.yaml")
+	if err == nil {
+		t.Fatal("expected an error for a nested stack that includes itself")
+	}
+
+	if !strings.Contains(err.Error(), "includes itself") {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
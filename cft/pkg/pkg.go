@@ -22,6 +22,13 @@
 //	must be called "ModuleExtension", and it must have a Metadata entry called
 //	"Extends" that supplies the existing type to be extended. The Parameters section
 //	of the module can be used to define additional properties for the extension.
+//
+// `Rain::Constants`: a section of the template whose values replace ${Rain::Name} anywhere
+// in the template. Constants can be used where parameters can't, like in policy documents.
+//
+// Local templates referenced by the TemplateURL of an AWS::CloudFormation::Stack
+// (or the Location of an AWS::Serverless::Application) are packaged recursively
+// and uploaded to S3, and the reference is replaced with the uploaded template's URL.
 package pkg
 
 import (
@@ -34,6 +41,8 @@ import (
 	rainpkl "github.com/aws-cloudformation/rain/pkl"
 
 	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/exports"
+	"github.com/aws-cloudformation/rain/cft/features"
 	"github.com/aws-cloudformation/rain/cft/parse"
 	"github.com/aws-cloudformation/rain/cft/visitor"
 	"github.com/aws-cloudformation/rain/internal/config"
@@ -45,6 +54,14 @@ import (
 // Experimental must be set to true to enable !Rain::Module
 var Experimental bool
 
+// Features restricts the directives, modules, and transforms templates can use;
+// if it is nil, everything is allowed
+var Features *features.Features
+
+// Exports is the naming convention that fills in the Name of exports that don't have one;
+// if it is nil, exports are left as they are
+var Exports *exports.Convention
+
 type transformContext struct {
 	nodeToTransform *yaml.Node
 	rootDir         string // Using normal files
@@ -62,6 +79,14 @@ func transform(ctx *transformContext) (bool, error) {
 	// registry is a map of functions defined in rain.go
 	for path, fn := range registry {
 		for found := range s11n.MatchAll(ctx.nodeToTransform, path) {
+			// Features only apply to the project's own templates,
+			// not to those embedded in rain
+			if _, name, ok := strings.Cut(path, "|Rain::"); ok && ctx.fs == nil {
+				if err := Features.CheckDirective("Rain::" + name); err != nil {
+					return false, err
+				}
+			}
+
 			nodeParent := node.GetParent(found, ctx.nodeToTransform, nil)
 			nodeParent.Parent = ctx.parent
 			c, err := fn(&directiveContext{found, ctx.rootDir, ctx.t, nodeParent, ctx.fs, ctx.baseUri})
@@ -83,9 +108,26 @@ func transform(ctx *transformContext) (bool, error) {
 func Template(t cft.Template, rootDir string, fs *embed.FS) (cft.Template, error) {
 	templateNode := t.Node
 
+	if fs == nil {
+		if err := Features.CheckTransforms(t); err != nil {
+			return t, err
+		}
+	}
+
 	//config.Debugf("Original template short: %v", node.ToSJson(t.Node))
 	//config.Debugf("Original template long: %v", node.ToJson(t.Node))
 
+	// Constants are replaced before directives, so that directives can use them,
+	// and again afterwards, for the references that modules bring in
+	constants, err := readConstants(templateNode)
+	if err != nil {
+		return t, err
+	}
+
+	if err := replaceConstants(templateNode, constants, false); err != nil {
+		return t, err
+	}
+
 	ctx := &transformContext{
 		nodeToTransform: templateNode,
 		rootDir:         rootDir,
@@ -118,7 +160,10 @@ func Template(t cft.Template, rootDir string, fs *embed.FS) (cft.Template, error
 		}
 	}
 
-	var err error
+	if err := replaceConstants(templateNode, constants, true); err != nil {
+		return t, err
+	}
+
 	if changed {
 		t, err = parse.Node(templateNode)
 		if err != nil {
@@ -126,6 +171,11 @@ func Template(t cft.Template, rootDir string, fs *embed.FS) (cft.Template, error
 		}
 	}
 
+	// Name the exports that don't have a name yet
+	if Exports != nil {
+		exports.Apply(cft.Template{Node: templateNode}, *Exports)
+	}
+
 	// Collect Anchors & Replace Alias Nodes
 	//
 	// 1. find alias nodes and save them in map with anchor name as key
@@ -167,7 +217,11 @@ func Template(t cft.Template, rootDir string, fs *embed.FS) (cft.Template, error
 		return t, fmt.Errorf("failed to unmarshal template: %v", err)
 	}
 
-	// We lose the Document node here
+	// Unmarshalling gives back a Document node, which is only wrapped in another if it was lost
+	if templateNode.Kind == yaml.DocumentNode {
+		return cft.Template{Node: templateNode}, nil
+	}
+
 	retval := cft.Template{}
 	retval.Node = &yaml.Node{Kind: yaml.DocumentNode, Content: make([]*yaml.Node, 0)}
 	retval.Node.Content = append(retval.Node.Content, templateNode)
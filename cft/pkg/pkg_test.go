@@ -0,0 +1,61 @@
+package pkg
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/exports"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+func TestExports(t *testing.T) {
+	source := `
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+Outputs:
+  BucketName:
+    Value: !Ref Bucket
+    Export: {}
+  BucketArn:
+    Value: !GetAtt Bucket.Arn
+    Export:
+      Name: custom-arn
+  Internal:
+    Value: !Ref Bucket
+`
+
+	defer func() { Exports = nil }()
+
+	for _, c := range []struct {
+		convention *exports.Convention
+		expected   map[string]string
+	}{
+		{nil, map[string]string{"BucketArn": "custom-arn"}},
+		{&exports.Convention{NameTemplate: "${StackName}-${OutputName}"}, map[string]string{
+			"BucketName": "app-BucketName",
+			"BucketArn":  "custom-arn",
+		}},
+	} {
+		Exports = c.convention
+
+		tmpl, err := parse.String(source)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		packaged, err := Template(tmpl, ".", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		names := exports.Names(packaged, "app")
+		if len(names) != len(c.expected) {
+			t.Errorf("expected %v, got %v", c.expected, names)
+		}
+		for output, name := range c.expected {
+			if names[output] != name {
+				t.Errorf("%s: expected export '%s', got '%s'", output, name, names[output])
+			}
+		}
+	}
+}
@@ -25,8 +25,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
 	"github.com/aws-cloudformation/rain/internal/aws/s3"
 	"github.com/aws-cloudformation/rain/internal/config"
 	"gopkg.in/yaml.v3"
@@ -43,11 +45,18 @@ func (s *s3Path) URI() string {
 }
 
 func (s *s3Path) HTTP() string {
-	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, s.key)
+	return fmt.Sprintf("https://%s.s3.%s.%s/%s", s.bucket, s.region, partition.ForRegion(s.region).URLSuffix, s.key)
 }
 
 var uploads = map[string]*s3Path{}
 
+// zipTime is the modification time given to every file in a zip,
+// which is the earliest time the zip format can represent
+var zipTime = time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// zipPath zips the file or directory at root into a temporary file
+// and returns its path. Files are added in lexical order with fixed
+// timestamps, so the zip is identical for identical content.
 func zipPath(root string) (string, error) {
 	tmpFile, err := os.CreateTemp(os.TempDir(), "*.zip")
 	if err != nil {
@@ -97,6 +106,15 @@ func zipPath(root string) (string, error) {
 		fh.Name = zPath
 		fh.Method = zip.Deflate
 
+		// Use a fixed timestamp and permissions so that zipping
+		// the same content always produces the same hash
+		fh.Modified = zipTime
+		if info.Mode()&0111 != 0 {
+			fh.SetMode(0755)
+		} else {
+			fh.SetMode(0644)
+		}
+
 		out, err := w.CreateHeader(fh)
 		if err != nil {
 			return err
@@ -186,6 +204,11 @@ func expectFile(n *yaml.Node, root string) ([]byte, string, error) {
 
 	//config.Debugf("root: %v, path: %v", root, path)
 
+	return readFile(path, root)
+}
+
+// readFile reads a file that is not a directory, relative to root
+func readFile(path string, root string) ([]byte, string, error) {
 	if !filepath.IsAbs(path) {
 		path = filepath.Join(root, path)
 	}
@@ -0,0 +1,43 @@
+package pkg
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestZipIsDeterministic(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "index.js"), []byte("exports.handler = () => {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	zip := func() []byte {
+		fn, err := zipPath(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.Remove(fn)
+
+		content, err := os.ReadFile(fn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return content
+	}
+
+	first := zip()
+
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "index.js"), later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(first, zip()) {
+		t.Error("zipping the same content twice should produce the same bytes")
+	}
+}
@@ -0,0 +1,233 @@
+package policy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// writeDoc writes doc to a temporary JSON file for an external engine to read.
+// The caller must remove the file.
+func writeDoc(doc any) (string, error) {
+	f, err := os.CreateTemp("", "rain-policy-*.json")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(doc); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// run runs an engine's binary and returns its standard output.
+// okCodes are the exit codes, other than 0, that do not mean the engine failed.
+func run(name string, args []string, okCodes ...int) ([]byte, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s is not installed or is not on the PATH", name)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command(path, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err = cmd.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		for _, code := range okCodes {
+			if exitErr.ExitCode() == code {
+				return stdout.Bytes(), nil
+			}
+		}
+		return nil, fmt.Errorf("%s failed: %s", name, strings.TrimSpace(stderr.String()))
+	}
+
+	return stdout.Bytes(), err
+}
+
+// Guard evaluates cfn-guard rules with the cfn-guard binary
+type Guard struct{}
+
+// guardFailed is the exit code cfn-guard uses when rules are not compliant
+const guardFailed = 19
+
+// Evaluate implements Evaluator
+func (Guard) Evaluate(files []string, doc any) ([]Result, error) {
+	data, err := writeDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(data)
+
+	results := make([]Result, 0)
+
+	// Each rules file is validated on its own so that denials can be traced to it
+	for _, file := range files {
+		out, err := run("cfn-guard", []string{
+			"validate",
+			"--rules", file,
+			"--data", data,
+			"--output-format", "json",
+			"--show-summary", "none",
+		}, guardFailed)
+		if err != nil {
+			return nil, err
+		}
+
+		r, err := parseGuard(out, file)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, r...)
+	}
+
+	return results, nil
+}
+
+// guardReport is the part of cfn-guard's JSON output that rain needs
+type guardReport struct {
+	Status       string `json:"status"`
+	NotCompliant []struct {
+		Rule struct {
+			Name     string `json:"name"`
+			Messages struct {
+				CustomMessage string `json:"custom_message"`
+				ErrorMessage  string `json:"error_message"`
+			} `json:"messages"`
+		} `json:"Rule"`
+	} `json:"not_compliant"`
+}
+
+// parseGuard returns the denials in cfn-guard's output,
+// which is a stream of JSON reports
+func parseGuard(out []byte, file string) ([]Result, error) {
+	results := make([]Result, 0)
+
+	dec := json.NewDecoder(bytes.NewReader(out))
+	for dec.More() {
+		var report guardReport
+		if err := dec.Decode(&report); err != nil {
+			return nil, fmt.Errorf("unable to parse cfn-guard output: %w", err)
+		}
+
+		if report.Status != "FAIL" {
+			continue
+		}
+
+		for _, nc := range report.NotCompliant {
+			message := strings.TrimSpace(nc.Rule.Messages.CustomMessage)
+			if message == "" {
+				message = strings.TrimSpace(nc.Rule.Messages.ErrorMessage)
+			}
+
+			results = append(results, Result{
+				Policy:  file,
+				Rule:    nc.Rule.Name,
+				Message: message,
+			})
+		}
+	}
+
+	return results, nil
+}
+
+// Rego evaluates OPA policies with the opa binary.
+// Every rule named deny is collected; its values can be
+// strings or objects with a msg field, as with conftest.
+type Rego struct{}
+
+// Evaluate implements Evaluator
+func (Rego) Evaluate(files []string, doc any) ([]Result, error) {
+	input, err := writeDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(input)
+
+	args := []string{"eval", "--format", "json", "--input", input}
+	for _, file := range files {
+		args = append(args, "--data", file)
+	}
+	args = append(args, "data")
+
+	out, err := run("opa", args)
+	if err != nil {
+		return nil, err
+	}
+
+	var res struct {
+		Result []struct {
+			Expressions []struct {
+				Value any `json:"value"`
+			} `json:"expressions"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(out, &res); err != nil {
+		return nil, fmt.Errorf("unable to parse opa output: %w", err)
+	}
+
+	results := make([]Result, 0)
+	for _, r := range res.Result {
+		for _, e := range r.Expressions {
+			results = append(results, denials(e.Value, nil)...)
+		}
+	}
+
+	return results, nil
+}
+
+// denials walks the document that opa evaluated and collects the values of deny rules.
+// The policy of each result is the package the rule is in.
+func denials(value any, pkg []string) []Result {
+	m, ok := value.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	results := make([]Result, 0)
+	for _, k := range keys {
+		if k != "deny" {
+			results = append(results, denials(m[k], append(pkg[:len(pkg):len(pkg)], k))...)
+			continue
+		}
+
+		values, ok := m[k].([]any)
+		if !ok {
+			continue
+		}
+
+		for _, v := range values {
+			r := Result{Policy: strings.Join(pkg, "."), Rule: "deny"}
+
+			switch d := v.(type) {
+			case string:
+				r.Message = d
+			case map[string]any:
+				r.Message, _ = d["msg"].(string)
+			default:
+				r.Message = fmt.Sprint(d)
+			}
+
+			results = append(results, r)
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,153 @@
+// Package policy evaluates policies against templates and change sets,
+// so that a deployment can be blocked when a policy denies it.
+//
+// Policies are files in a directory. Each file is evaluated by the Evaluator
+// registered for its extension: cfn-guard rules (.guard) and OPA policies (.rego)
+// are supported out of the box, and other engines can be added with Register.
+//
+// Policies in the top level of the directory are evaluated against the template.
+// Policies in a "changeset" subdirectory are evaluated against the change set,
+// as returned by DescribeChangeSet.
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/sarif"
+)
+
+// ChangeSetDir is the subdirectory that holds policies for change sets
+const ChangeSetDir = "changeset"
+
+// Result is a single denial
+type Result struct {
+	// Policy is the file that holds the rule
+	Policy string `json:"policy"`
+
+	// Rule is the name of the rule that denied the document, if the engine reports it
+	Rule string `json:"rule,omitempty"`
+
+	Message string `json:"message"`
+}
+
+func (r Result) String() string {
+	name := r.name()
+
+	if r.Message == "" {
+		return name
+	}
+
+	return fmt.Sprintf("%s: %s", name, r.Message)
+}
+
+// name identifies the rule that denied the document, e.g. "tags.guard/bucket_tags"
+func (r Result) name() string {
+	name := filepath.Base(r.Policy)
+	if r.Rule != "" {
+		name += "/" + r.Rule
+	}
+
+	return name
+}
+
+// SARIF returns the denials of the template in the file as a SARIF 2.1.0 log.
+// The engines don't report where in the template a denial is, so the results are located in the file.
+func SARIF(fn string, results []Result) ([]byte, error) {
+	out := make([]sarif.Result, 0, len(results))
+	for _, r := range results {
+		message := r.Message
+		if message == "" {
+			message = fmt.Sprintf("denied by %s", r.name())
+		}
+
+		out = append(out, sarif.Result{
+			Rule:    r.name(),
+			Level:   sarif.Error,
+			Message: message,
+			File:    fn,
+		})
+	}
+
+	return sarif.Log(nil, out)
+}
+
+// Evaluator evaluates the policies in files against doc,
+// and returns a Result for every denial
+type Evaluator interface {
+	Evaluate(files []string, doc any) ([]Result, error)
+}
+
+var evaluators = map[string]Evaluator{
+	".guard": Guard{},
+	".rego":  Rego{},
+}
+
+// Register sets the Evaluator for policy files with the extension, e.g. ".rego"
+func Register(ext string, e Evaluator) {
+	evaluators[ext] = e
+}
+
+// files returns the policy files in dir, grouped by extension
+func files(dir string) (map[string][]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	out := make(map[string][]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if _, ok := evaluators[ext]; ok {
+			out[ext] = append(out[ext], filepath.Join(dir, entry.Name()))
+		}
+	}
+
+	return out, nil
+}
+
+// evaluate runs every policy in dir against doc
+func evaluate(dir string, doc any) ([]Result, error) {
+	byExt, err := files(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	exts := make([]string, 0, len(byExt))
+	for ext := range byExt {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+
+	results := make([]Result, 0)
+	for _, ext := range exts {
+		r, err := evaluators[ext].Evaluate(byExt[ext], doc)
+		if err != nil {
+			return nil, fmt.Errorf("unable to evaluate %s policies in %s: %w", ext, dir, err)
+		}
+		results = append(results, r...)
+	}
+
+	return results, nil
+}
+
+// Template evaluates the template policies in dir against the template,
+// which should be decoded from YAML, as returned by cft.Template.Map
+func Template(dir string, template map[string]any) ([]Result, error) {
+	return evaluate(dir, template)
+}
+
+// ChangeSet evaluates the change set policies in dir against the change set
+func ChangeSet(dir string, changeSet any) ([]Result, error) {
+	return evaluate(filepath.Join(dir, ChangeSetDir), changeSet)
+}
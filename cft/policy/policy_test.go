@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type fakeEvaluator struct {
+	files []string
+}
+
+func (f *fakeEvaluator) Evaluate(files []string, doc any) ([]Result, error) {
+	f.files = append(f.files, files...)
+
+	results := make([]Result, 0)
+	if m, ok := doc.(map[string]any); ok && m["Deny"] == true {
+		results = append(results, Result{Policy: files[0], Message: "denied"})
+	}
+
+	return results, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	dir := t.TempDir()
+
+	for _, name := range []string{"a.fake", "b.fake", "ignored.txt", ChangeSetDir + "/c.fake"} {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte{}, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fake := &fakeEvaluator{}
+	Register(".fake", fake)
+	defer delete(evaluators, ".fake")
+
+	results, err := Template(dir, map[string]any{"Deny": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.files) != 2 || len(results) != 1 || results[0].Message != "denied" {
+		t.Errorf("unexpected results %v for files %v", results, fake.files)
+	}
+
+	fake.files = nil
+	results, err = ChangeSet(dir, map[string]any{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fake.files) != 1 || filepath.Base(fake.files[0]) != "c.fake" || len(results) != 0 {
+		t.Errorf("unexpected results %v for files %v", results, fake.files)
+	}
+
+	// A missing change set directory is not an error
+	if _, err := ChangeSet(t.TempDir(), nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseGuard(t *testing.T) {
+	out := []byte(`{"name":"data.json","status":"FAIL","not_compliant":[{"Rule":{"name":"bucket_encryption","messages":{"custom_message":"Buckets must be encrypted","error_message":null}}}]}
+{"name":"data.json","status":"PASS","not_compliant":[]}`)
+
+	results, err := parseGuard(out, "rules/s3.guard")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(results) != 1 || results[0].Rule != "bucket_encryption" || results[0].Message != "Buckets must be encrypted" {
+		t.Errorf("unexpected results: %v", results)
+	}
+
+	if results[0].String() != "s3.guard/bucket_encryption: Buckets must be encrypted" {
+		t.Errorf("unexpected string: %s", results[0])
+	}
+}
+
+func TestDenials(t *testing.T) {
+	value := map[string]any{
+		"rain": map[string]any{
+			"s3": map[string]any{
+				"deny":  []any{"Bucket has no encryption"},
+				"allow": true,
+			},
+			"iam": map[string]any{
+				"deny": []any{map[string]any{"msg": "Role is too broad"}},
+			},
+		},
+	}
+
+	results := denials(value, nil)
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 denials, got %v", results)
+	}
+
+	if results[0].Policy != "rain.iam" || results[0].Message != "Role is too broad" {
+		t.Errorf("unexpected result: %v", results[0])
+	}
+
+	if results[1].Policy != "rain.s3" || results[1].Message != "Bucket has no encryption" {
+		t.Errorf("unexpected result: %v", results[1])
+	}
+}
+
+func TestSARIF(t *testing.T) {
+	out, err := SARIF("app.yaml", []Result{
+		{Policy: "policies/tags.guard", Rule: "bucket_tags", Message: "buckets must have a team tag"},
+		{Policy: "policies/regions.rego", Rule: "deny"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Runs []struct {
+			Results []struct {
+				RuleId  string `json:"ruleId"`
+				Level   string `json:"level"`
+				Message struct {
+					Text string `json:"text"`
+				} `json:"message"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(out, &log); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{
+		"tags.guard/bucket_tags error buckets must have a team tag",
+		"regions.rego/deny error denied by regions.rego/deny",
+	}
+
+	actual := make([]string, 0)
+	for _, r := range log.Runs[0].Results {
+		actual = append(actual, strings.Join([]string{r.RuleId, r.Level, r.Message.Text}, " "))
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
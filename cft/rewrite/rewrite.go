@@ -0,0 +1,186 @@
+// Package rewrite converts between the equivalent ways of building a string
+// in a template, so that a codebase can use one style consistently.
+//
+// With the Sub style, Fn::Join expressions are rewritten as Fn::Sub:
+//
+//	!Join ["", ["arn:aws:s3:::", !Ref Bucket, "/*"]]
+//
+// becomes
+//
+//	!Sub arn:aws:s3:::${Bucket}/*
+//
+// With the Join style, Fn::Sub expressions are rewritten as Fn::Join.
+// In both styles, expressions that do not refer to anything
+// become plain strings, and expressions that only contain a single
+// Ref or Fn::GetAtt are replaced by it.
+//
+// Every rewrite is checked with the evaluator in package eval,
+// and expressions whose meaning would change are left alone.
+package rewrite
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/eval"
+	"gopkg.in/yaml.v3"
+)
+
+// Style is the form that strings should be written in
+type Style string
+
+const (
+	// Sub prefers Fn::Sub
+	Sub Style = "sub"
+
+	// Join prefers Fn::Join
+	Join Style = "join"
+)
+
+// Styles are the valid values for Style
+var Styles = []Style{Sub, Join}
+
+// ParseStyle returns the Style named by s
+func ParseStyle(s string) (Style, error) {
+	for _, style := range Styles {
+		if string(style) == strings.ToLower(s) {
+			return style, nil
+		}
+	}
+
+	return "", fmt.Errorf("unknown style '%s'; expected one of: %s, %s", s, Sub, Join)
+}
+
+// Template rewrites every string expression in t in place to use style
+// and returns the number of expressions that were changed
+func Template(t cft.Template, style Style) int {
+	return rewriteNode(t.Node, style)
+}
+
+// Node rewrites n, if it is a string expression, to use style.
+// It returns the rewritten node and true, or nil and false
+// if n cannot be rewritten or is already in style.
+func Node(n *yaml.Node, style Style) (*yaml.Node, bool) {
+	if n.Kind != yaml.MappingNode || len(n.Content) != 2 {
+		return nil, false
+	}
+
+	fn := n.Content[0].Value
+	if fn != "Fn::Join" && fn != "Fn::Sub" {
+		return nil, false
+	}
+
+	e, err := eval.String(n)
+	if err != nil {
+		return nil, false
+	}
+
+	// The evaluator did not understand n, so there is nothing to rewrite
+	if len(e) == 1 && e[0].Node == n {
+		return nil, false
+	}
+
+	var out *yaml.Node
+
+	switch {
+	case !e.Dynamic():
+		out = scalar(e.String())
+	case len(e) == 1:
+		// There is nothing to join or substitute, so use the value itself
+		out = toJoin(e)
+	case fn == "Fn::Join" && style == Sub:
+		if e.HasOpaque() {
+			return nil, false
+		}
+		out = toSub(e)
+	case fn == "Fn::Sub" && style == Join:
+		out = toJoin(e)
+	default:
+		return nil, false
+	}
+
+	// Make sure nothing was lost in translation
+	check, err := eval.String(out)
+	if err != nil || !check.Equal(e) {
+		return nil, false
+	}
+
+	return out, true
+}
+
+// rewriteNode rewrites n and its children in place
+func rewriteNode(n *yaml.Node, style Style) int {
+	count := 0
+
+	for i, child := range n.Content {
+		count += rewriteNode(child, style)
+
+		if out, ok := Node(child, style); ok {
+			n.Content[i] = out
+			count++
+		}
+	}
+
+	return count
+}
+
+func scalar(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+func function(name string, arg *yaml.Node) *yaml.Node {
+	return &yaml.Node{
+		Kind:    yaml.MappingNode,
+		Content: []*yaml.Node{scalar(name), arg},
+	}
+}
+
+// toSub returns an Fn::Sub equivalent to e, which must not contain opaque parts
+func toSub(e eval.Expr) *yaml.Node {
+	parts := make([]string, len(e))
+	for i, p := range e {
+		if p.Kind == eval.Literal {
+			// Stop Fn::Sub from treating literal text as a variable
+			parts[i] = strings.ReplaceAll(p.Value, "${", "${!")
+		} else {
+			parts[i] = "${" + p.Value + "}"
+		}
+	}
+
+	return function("Fn::Sub", scalar(strings.Join(parts, "")))
+}
+
+// toJoin returns an Fn::Join equivalent to e,
+// or the only part of e if there is nothing to join
+func toJoin(e eval.Expr) *yaml.Node {
+	items := make([]*yaml.Node, len(e))
+	for i, p := range e {
+		switch p.Kind {
+		case eval.Literal:
+			items[i] = scalar(p.Value)
+		case eval.Ref:
+			items[i] = function("Ref", scalar(p.Value))
+		case eval.GetAtt:
+			name, attr, _ := strings.Cut(p.Value, ".")
+			items[i] = function("Fn::GetAtt", &yaml.Node{
+				Kind:    yaml.SequenceNode,
+				Content: []*yaml.Node{scalar(name), scalar(attr)},
+			})
+		case eval.Opaque:
+			items[i] = p.Node
+		}
+	}
+
+	if len(items) == 1 {
+		return items[0]
+	}
+
+	return function("Fn::Join", &yaml.Node{
+		Kind: yaml.SequenceNode,
+		Content: []*yaml.Node{
+			scalar(""),
+			{Kind: yaml.SequenceNode, Content: items},
+		},
+	})
+}
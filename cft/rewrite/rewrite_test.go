@@ -0,0 +1,126 @@
+package rewrite_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/rewrite"
+	"github.com/google/go-cmp/cmp"
+)
+
+const input = `
+Outputs:
+  Arn:
+    Value: !Join ["", ["arn:", !Ref AWS::Partition, ":s3:::", !Ref Bucket, "/${literal}"]]
+  Name:
+    Value: !Sub "${Bucket.Arn}-${Suffix}"
+  Vars:
+    Value: !Sub
+      - "${Zone}-${AWS::Region}"
+      - Zone: !Select [0, !GetAZs ""]
+  Plain:
+    Value: !Join ["-", [a, b]]
+  Single:
+    Value: !Sub "${Bucket}"
+  List:
+    Value: !Join [",", !Ref Subnets]
+`
+
+func TestSub(t *testing.T) {
+	expected := `Outputs:
+  Arn:
+    Value: !Sub arn:${AWS::Partition}:s3:::${Bucket}/${!literal}
+
+  Name:
+    Value: !Sub ${Bucket.Arn}-${Suffix}
+
+  Vars:
+    Value: !Sub
+      - ${Zone}-${AWS::Region}
+      - Zone: !Select
+          - 0
+          - !GetAZs
+
+  Plain:
+    Value: a-b
+
+  Single:
+    Value: !Ref Bucket
+
+  List:
+    Value: !Join
+      - ','
+      - !Ref Subnets`
+
+	check(t, rewrite.Sub, 3, expected)
+}
+
+func TestJoin(t *testing.T) {
+	expected := `Outputs:
+  Arn:
+    Value: !Join
+      - ""
+      - - 'arn:'
+        - !Ref AWS::Partition
+        - ':s3:::'
+        - !Ref Bucket
+        - /${literal}
+
+  Name:
+    Value: !Join
+      - ""
+      - - !GetAtt Bucket.Arn
+        - '-'
+        - !Ref Suffix
+
+  Vars:
+    Value: !Join
+      - ""
+      - - !Select
+          - 0
+          - !GetAZs
+        - '-'
+        - !Ref AWS::Region
+
+  Plain:
+    Value: a-b
+
+  Single:
+    Value: !Ref Bucket
+
+  List:
+    Value: !Join
+      - ','
+      - !Ref Subnets`
+
+	check(t, rewrite.Join, 4, expected)
+}
+
+func check(t *testing.T, style rewrite.Style, count int, expected string) {
+	tmpl, err := parse.String(input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := rewrite.Template(tmpl, style); n != count {
+		t.Errorf("expected %d rewrites, got %d", count, n)
+	}
+
+	actual := format.String(tmpl, format.Options{})
+
+	if d := cmp.Diff(strings.TrimSpace(expected), strings.TrimSpace(actual)); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	if s, err := rewrite.ParseStyle("Join"); err != nil || s != rewrite.Join {
+		t.Errorf("expected join, got %q, %v", s, err)
+	}
+
+	if _, err := rewrite.ParseStyle("concat"); err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,217 @@
+// Package sarif writes the findings of rain lint, rain scan and policy checks
+// as SARIF 2.1.0 logs, which GitHub code scanning and other security dashboards read.
+//
+// Each finding is located in the template file, at the line that the parser recorded
+// for the element it is about, and logically at the element, e.g. "Resources/Bucket".
+package sarif
+
+import (
+	"encoding/json"
+	"path/filepath"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+)
+
+// Version is the version of SARIF that is written
+const Version = "2.1.0"
+
+const schema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+// InformationUri is the tool's home page in the log
+const InformationUri = "https://github.com/aws-cloudformation/rain"
+
+// Level is how serious a result is
+type Level string
+
+const (
+	Error   Level = "error"
+	Warning Level = "warning"
+	Note    Level = "note"
+)
+
+// LevelOf returns the level of a severity used by rain's checks: error, warning or info
+func LevelOf(severity string) Level {
+	switch severity {
+	case "error":
+		return Error
+	case "warning":
+		return Warning
+	default:
+		return Note
+	}
+}
+
+// Rule describes the rule that results refer to
+type Rule struct {
+	Id          string
+	Description string
+
+	// Source is the external tool that the rule belongs to, if it isn't one of rain's own
+	Source string
+}
+
+// Result is a single finding
+type Result struct {
+	Rule    string
+	Level   Level
+	Message string
+
+	// File is the template that the finding is in
+	File string
+
+	// Line is the line of the template that the finding is about, or 0 if it isn't known
+	Line int
+
+	// Element is the template element that the finding is about, e.g. "Resources/Bucket"
+	Element string
+
+	// Suppressed is set if the finding was accepted in the template, with Justification as the reason
+	Suppressed    bool
+	Justification string
+}
+
+type sarifLog struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []run  `json:"runs"`
+}
+
+type run struct {
+	Tool    tool     `json:"tool"`
+	Results []result `json:"results"`
+}
+
+type tool struct {
+	Driver driver `json:"driver"`
+}
+
+type driver struct {
+	Name           string `json:"name"`
+	Version        string `json:"version"`
+	InformationUri string `json:"informationUri"`
+	Rules          []rule `json:"rules"`
+}
+
+type rule struct {
+	Id               string      `json:"id"`
+	ShortDescription *message    `json:"shortDescription,omitempty"`
+	Properties       *properties `json:"properties,omitempty"`
+}
+
+type properties struct {
+	Source string `json:"source"`
+}
+
+type message struct {
+	Text string `json:"text"`
+}
+
+type result struct {
+	RuleId       string        `json:"ruleId"`
+	RuleIndex    int           `json:"ruleIndex"`
+	Level        Level         `json:"level"`
+	Message      message       `json:"message"`
+	Locations    []location    `json:"locations"`
+	Suppressions []suppression `json:"suppressions,omitempty"`
+}
+
+type location struct {
+	PhysicalLocation physicalLocation  `json:"physicalLocation"`
+	LogicalLocations []logicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type physicalLocation struct {
+	ArtifactLocation artifactLocation `json:"artifactLocation"`
+	Region           *region          `json:"region,omitempty"`
+}
+
+type artifactLocation struct {
+	Uri string `json:"uri"`
+}
+
+type region struct {
+	StartLine int `json:"startLine"`
+}
+
+type logicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+type suppression struct {
+	Kind          string `json:"kind"`
+	Justification string `json:"justification,omitempty"`
+}
+
+// Log returns the results as a SARIF log with one run of rain.
+// rules describes the rules that the results refer to; rules that results refer to
+// but that aren't described are listed with only their ids.
+func Log(rules []Rule, results []Result) ([]byte, error) {
+	d := driver{
+		Name:           "rain",
+		Version:        config.VERSION,
+		InformationUri: InformationUri,
+		Rules:          make([]rule, 0),
+	}
+
+	described := make(map[string]Rule)
+	for _, r := range rules {
+		described[r.Id] = r
+	}
+
+	index := make(map[string]int)
+
+	out := make([]result, 0, len(results))
+	for _, r := range results {
+		if _, ok := index[r.Rule]; !ok {
+			index[r.Rule] = len(d.Rules)
+			d.Rules = append(d.Rules, newRule(r.Rule, described[r.Rule]))
+		}
+
+		res := result{
+			RuleId:    r.Rule,
+			RuleIndex: index[r.Rule],
+			Level:     r.Level,
+			Message:   message{Text: r.Message},
+			Locations: []location{{
+				PhysicalLocation: physicalLocation{
+					ArtifactLocation: artifactLocation{Uri: filepath.ToSlash(r.File)},
+				},
+			}},
+		}
+
+		if r.Line > 0 {
+			res.Locations[0].PhysicalLocation.Region = &region{StartLine: r.Line}
+		}
+
+		if r.Element != "" {
+			res.Locations[0].LogicalLocations = []logicalLocation{{FullyQualifiedName: r.Element}}
+		}
+
+		if r.Suppressed {
+			res.Suppressions = []suppression{{Kind: "inSource", Justification: r.Justification}}
+		}
+
+		out = append(out, res)
+	}
+
+	return json.MarshalIndent(sarifLog{
+		Schema:  schema,
+		Version: Version,
+		Runs:    []run{{Tool: tool{Driver: d}, Results: out}},
+	}, "", "  ")
+}
+
+// newRule returns the rule with the id, with its description if there is one
+func newRule(id string, r Rule) rule {
+	out := rule{Id: id}
+
+	if r.Description != "" {
+		out.ShortDescription = &message{Text: r.Description}
+	}
+
+	if r.Source != "" {
+		out.Properties = &properties{Source: r.Source}
+	}
+
+	return out
+}
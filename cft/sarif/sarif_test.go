@@ -0,0 +1,85 @@
+package sarif
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLog(t *testing.T) {
+	rules := []Rule{
+		{Id: "cidr", Description: "Subnets are inside their VPC"},
+		{Id: "unused", Description: "not reported, so not listed"},
+	}
+
+	results := []Result{
+		{Rule: "cidr", Level: Error, Message: "outside the VPC", File: "templates/app.yaml", Line: 7, Element: "Resources/Subnet"},
+		{Rule: "cfn-lint/E3012", Level: Warning, Message: "wrong type", File: "app.yaml"},
+		{Rule: "cidr", Level: LevelOf("info"), Message: "accepted", File: "app.yaml", Line: 9,
+			Suppressed: true, Justification: "peered"},
+	}
+
+	out, err := Log(rules, results)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log sarifLog
+	if err := json.Unmarshal(out, &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != Version || len(log.Runs) != 1 || log.Runs[0].Tool.Driver.Name != "rain" {
+		t.Fatalf("unexpected log:\n%s", out)
+	}
+
+	expectedRules := []rule{
+		{Id: "cidr", ShortDescription: &message{Text: "Subnets are inside their VPC"}},
+		{Id: "cfn-lint/E3012"},
+	}
+
+	if diff := cmp.Diff(expectedRules, log.Runs[0].Tool.Driver.Rules); diff != "" {
+		t.Error(diff)
+	}
+
+	expected := []result{
+		{
+			RuleId:  "cidr",
+			Level:   Error,
+			Message: message{Text: "outside the VPC"},
+			Locations: []location{{
+				PhysicalLocation: physicalLocation{
+					ArtifactLocation: artifactLocation{Uri: "templates/app.yaml"},
+					Region:           &region{StartLine: 7},
+				},
+				LogicalLocations: []logicalLocation{{FullyQualifiedName: "Resources/Subnet"}},
+			}},
+		},
+		{
+			RuleId:    "cfn-lint/E3012",
+			RuleIndex: 1,
+			Level:     Warning,
+			Message:   message{Text: "wrong type"},
+			Locations: []location{{
+				PhysicalLocation: physicalLocation{ArtifactLocation: artifactLocation{Uri: "app.yaml"}},
+			}},
+		},
+		{
+			RuleId:  "cidr",
+			Level:   Note,
+			Message: message{Text: "accepted"},
+			Locations: []location{{
+				PhysicalLocation: physicalLocation{
+					ArtifactLocation: artifactLocation{Uri: "app.yaml"},
+					Region:           &region{StartLine: 9},
+				},
+			}},
+			Suppressions: []suppression{{Kind: "inSource", Justification: "peered"}},
+		},
+	}
+
+	if diff := cmp.Diff(expected, log.Runs[0].Results); diff != "" {
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,310 @@
+package scan
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+func init() {
+	register(Rule{
+		Name:        "s3-encryption",
+		Description: "S3 buckets set a default encryption configuration",
+		Types:       []string{"AWS::S3::Bucket"},
+		Check: func(_ string, props *yaml.Node) []Problem {
+			if get(props, "BucketEncryption") == nil {
+				return warn("does not set BucketEncryption; choose the default encryption for new objects, such as aws:kms")
+			}
+			return nil
+		},
+	})
+
+	register(Rule{
+		Name:        "s3-public-access-block",
+		Description: "S3 buckets block public access with all four PublicAccessBlockConfiguration settings",
+		Types:       []string{"AWS::S3::Bucket"},
+		Check:       checkPublicAccessBlock,
+	})
+
+	register(Rule{
+		Name:        "s3-logging",
+		Description: "S3 buckets log server access",
+		Types:       []string{"AWS::S3::Bucket"},
+		Check: func(_ string, props *yaml.Node) []Problem {
+			if get(props, "LoggingConfiguration") == nil {
+				return info("does not log server access (LoggingConfiguration)")
+			}
+			return nil
+		},
+	})
+
+	register(Rule{
+		Name:        "ebs-encryption",
+		Description: "EBS volumes, and the block devices of instances and launch templates, are encrypted",
+		Types:       []string{"AWS::EC2::Volume", "AWS::EC2::Instance", "AWS::EC2::LaunchTemplate"},
+		Check:       checkEBSEncryption,
+	})
+
+	register(Rule{
+		Name:        "rds-encryption",
+		Description: "RDS database instances and clusters encrypt their storage",
+		Types:       []string{"AWS::RDS::DBInstance", "AWS::RDS::DBCluster"},
+		Check: func(typ string, props *yaml.Node) []Problem {
+			// Instances in a cluster use the cluster's storage;
+			// a cluster's own DBClusterIdentifier is just its name
+			if typ == "AWS::RDS::DBInstance" && get(props, "DBClusterIdentifier") != nil {
+				return nil
+			}
+			if !isTrue(get(props, "StorageEncrypted")) {
+				return fail("does not encrypt its storage (StorageEncrypted)")
+			}
+			return nil
+		},
+	})
+
+	register(Rule{
+		Name:        "rds-public",
+		Description: "RDS database instances are not publicly accessible",
+		Types:       []string{"AWS::RDS::DBInstance"},
+		Check: func(_ string, props *yaml.Node) []Problem {
+			if isLiteral(get(props, "PubliclyAccessible"), "true") {
+				return fail("is publicly accessible (PubliclyAccessible)")
+			}
+			return nil
+		},
+	})
+
+	register(Rule{
+		Name:        "efs-encryption",
+		Description: "EFS file systems are encrypted",
+		Types:       []string{"AWS::EFS::FileSystem"},
+		Check: func(_ string, props *yaml.Node) []Problem {
+			if !isTrue(get(props, "Encrypted")) {
+				return fail("is not encrypted (Encrypted)")
+			}
+			return nil
+		},
+	})
+
+	register(Rule{
+		Name:        "sns-encryption",
+		Description: "SNS topics are encrypted with a KMS key",
+		Types:       []string{"AWS::SNS::Topic"},
+		Check: func(_ string, props *yaml.Node) []Problem {
+			if get(props, "KmsMasterKeyId") == nil {
+				return warn("is not encrypted (KmsMasterKeyId)")
+			}
+			return nil
+		},
+	})
+
+	register(Rule{
+		Name:        "security-group-open-ingress",
+		Description: "Security groups don't allow ingress from anywhere (0.0.0.0/0 or ::/0), except to HTTP and HTTPS",
+		Types:       []string{"AWS::EC2::SecurityGroup", "AWS::EC2::SecurityGroupIngress"},
+		Check:       checkOpenIngress,
+	})
+
+	register(Rule{
+		Name:        "cloudtrail-logging",
+		Description: "CloudTrail trails are logging, validate their log files, and cover every region",
+		Types:       []string{"AWS::CloudTrail::Trail"},
+		Check:       checkCloudTrail,
+	})
+
+	register(Rule{
+		Name:        "load-balancer-logging",
+		Description: "Load balancers write access logs",
+		Types:       []string{"AWS::ElasticLoadBalancingV2::LoadBalancer", "AWS::ElasticLoadBalancing::LoadBalancer"},
+		Check:       checkLoadBalancerLogging,
+	})
+
+	register(Rule{
+		Name:        "cloudfront-logging",
+		Description: "CloudFront distributions write access logs",
+		Types:       []string{"AWS::CloudFront::Distribution"},
+		Check: func(_ string, props *yaml.Node) []Problem {
+			if get(props, "DistributionConfig", "Logging") == nil {
+				return info("does not write access logs (DistributionConfig/Logging)")
+			}
+			return nil
+		},
+	})
+}
+
+func checkPublicAccessBlock(_ string, props *yaml.Node) []Problem {
+	block := get(props, "PublicAccessBlockConfiguration")
+	if block == nil {
+		return warn("does not set PublicAccessBlockConfiguration; block public access unless the bucket must be public")
+	}
+
+	off := make([]string, 0)
+	for _, setting := range []string{"BlockPublicAcls", "BlockPublicPolicy", "IgnorePublicAcls", "RestrictPublicBuckets"} {
+		v := get(block, setting)
+		if v == nil || isLiteral(v, "false") {
+			off = append(off, setting)
+		}
+	}
+
+	if len(off) > 0 {
+		return fail(fmt.Sprintf("does not block public access with %s", strings.Join(off, ", ")))
+	}
+
+	return nil
+}
+
+func checkEBSEncryption(typ string, props *yaml.Node) []Problem {
+	if typ == "AWS::EC2::Volume" {
+		if !isTrue(get(props, "Encrypted")) {
+			return fail("is not encrypted (Encrypted)")
+		}
+		return nil
+	}
+
+	path := []string{"BlockDeviceMappings"}
+	if typ == "AWS::EC2::LaunchTemplate" {
+		path = []string{"LaunchTemplateData", "BlockDeviceMappings"}
+	}
+	mappings := get(props, path...)
+
+	if mappings == nil || mappings.Kind != yaml.SequenceNode {
+		return nil
+	}
+
+	problems := make([]Problem, 0)
+	for i, m := range mappings.Content {
+		ebs := get(m, "Ebs")
+		if ebs == nil {
+			// Instance store volumes and NoDevice entries
+			continue
+		}
+		if !isTrue(get(ebs, "Encrypted")) {
+			problems = append(problems, fail(fmt.Sprintf("does not encrypt the EBS volume in %s/%d", strings.Join(path, "/"), i))...)
+		}
+	}
+
+	return problems
+}
+
+func checkOpenIngress(typ string, props *yaml.Node) []Problem {
+	// A security group has a list of rules, and SecurityGroupIngress is one rule
+	rules := []*yaml.Node{props}
+	if typ == "AWS::EC2::SecurityGroup" {
+		rules = nil
+		if ingress := get(props, "SecurityGroupIngress"); ingress != nil && ingress.Kind == yaml.SequenceNode {
+			rules = ingress.Content
+		}
+	}
+
+	problems := make([]Problem, 0)
+	for _, rule := range rules {
+		cidr := ""
+		if isLiteral(get(rule, "CidrIp"), "0.0.0.0/0") {
+			cidr = "0.0.0.0/0"
+		} else if isLiteral(get(rule, "CidrIpv6"), "::/0") {
+			cidr = "::/0"
+		}
+		if cidr == "" {
+			continue
+		}
+
+		protocol := scalar(get(rule, "IpProtocol"))
+		from, to := scalar(get(rule, "FromPort")), scalar(get(rule, "ToPort"))
+
+		switch {
+		case protocol == "-1" || protocol == "all":
+			problems = append(problems, fail(fmt.Sprintf("allows all traffic from %s", cidr))...)
+		case from != "" && from == to && (from == "80" || from == "443"):
+			problems = append(problems, info(fmt.Sprintf("allows %s from %s on port %s, as a public web server needs", protocol, cidr, from))...)
+		case from != "" && from == to:
+			problems = append(problems, fail(fmt.Sprintf("allows %s from %s on port %s", protocol, cidr, from))...)
+		default:
+			problems = append(problems, fail(fmt.Sprintf("allows %s from %s on ports %s-%s", protocol, cidr, from, to))...)
+		}
+	}
+
+	return problems
+}
+
+func checkCloudTrail(_ string, props *yaml.Node) []Problem {
+	problems := make([]Problem, 0)
+
+	if isLiteral(get(props, "IsLogging"), "false") {
+		problems = append(problems, fail("is not logging (IsLogging)")...)
+	}
+
+	if !isTrue(get(props, "EnableLogFileValidation")) {
+		problems = append(problems, warn("does not validate its log files (EnableLogFileValidation)")...)
+	}
+
+	if !isTrue(get(props, "IsMultiRegionTrail")) {
+		problems = append(problems, info("only logs events in its own region (IsMultiRegionTrail)")...)
+	}
+
+	return problems
+}
+
+func checkLoadBalancerLogging(typ string, props *yaml.Node) []Problem {
+	// Classic load balancers have AccessLoggingPolicy
+	if typ == "AWS::ElasticLoadBalancing::LoadBalancer" {
+		if policy := get(props, "AccessLoggingPolicy"); policy == nil || isLiteral(get(policy, "Enabled"), "false") {
+			return warn("does not write access logs (AccessLoggingPolicy)")
+		}
+		return nil
+	}
+
+	attributes := get(props, "LoadBalancerAttributes")
+	if attributes != nil && attributes.Kind == yaml.SequenceNode {
+		for _, a := range attributes.Content {
+			if isLiteral(get(a, "Key"), "access_logs.s3.enabled") && !isLiteral(get(a, "Value"), "false") {
+				return nil
+			}
+		}
+	}
+
+	return warn("does not write access logs (LoadBalancerAttributes access_logs.s3.enabled)")
+}
+
+// get returns the value at a path of keys in a mapping, or nil
+func get(n *yaml.Node, path ...string) *yaml.Node {
+	for _, key := range path {
+		if n == nil || n.Kind != yaml.MappingNode {
+			return nil
+		}
+		_, n, _ = s11n.GetMapValue(n, key)
+	}
+
+	return n
+}
+
+// scalar returns the value of n if it is a plain value
+func scalar(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
+
+// isLiteral returns true if n is the plain value v
+func isLiteral(n *yaml.Node, v string) bool {
+	return n != nil && n.Kind == yaml.ScalarNode && strings.EqualFold(n.Value, v)
+}
+
+// isTrue returns true if n is true or an intrinsic function, which could be true
+func isTrue(n *yaml.Node) bool {
+	return n != nil && (n.Kind != yaml.ScalarNode || strings.EqualFold(n.Value, "true"))
+}
+
+func fail(message string) []Problem {
+	return []Problem{{Severity: Error, Message: message}}
+}
+
+func warn(message string) []Problem {
+	return []Problem{{Severity: Warning, Message: message}}
+}
+
+func info(message string) []Problem {
+	return []Problem{{Severity: Info, Message: message}}
+}
@@ -0,0 +1,180 @@
+// Package scan checks the resources in a template for common security problems,
+// such as unencrypted storage, resources open to the internet, and missing logs.
+//
+// A finding can be accepted with Rain::Suppressions in the resource's or the template's Metadata,
+// as described in package suppress:
+//
+//	Metadata:
+//	  Rain::Suppressions:
+//	    - Rule: s3-logging
+//	      Reason: access is logged by CloudTrail data events
+//
+// Suppressed findings are still returned, marked as Suppressed.
+package scan
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/sarif"
+	"github.com/aws-cloudformation/rain/cft/suppress"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// Severity indicates how serious a finding is
+type Severity string
+
+const (
+	Error   Severity = "error"
+	Warning Severity = "warning"
+	Info    Severity = "info"
+)
+
+// Finding is a problem with a resource
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	Resource string   `json:"resource"`
+	Message  string   `json:"message"`
+	Line     int      `json:"line,omitempty"`
+
+	// Suppressed is set if the resource's Metadata accepts the finding
+	Suppressed bool `json:"suppressed,omitempty"`
+
+	// Reason is the reason the suppression gives
+	Reason string `json:"reason,omitempty"`
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s [%s]", f.Resource, f.Message, f.Rule)
+}
+
+// Problem is something a rule found wrong with a resource
+type Problem struct {
+	Severity Severity
+	Message  string
+}
+
+// Rule checks resources of some types
+type Rule struct {
+	Name        string
+	Description string
+
+	// Types are the resource types the rule checks
+	Types []string
+
+	// Check returns the problems with a resource, given its type and its properties, which may be nil
+	Check func(typ string, props *yaml.Node) []Problem
+}
+
+// Rules contains every rule, in the order they were registered
+var Rules = make([]Rule, 0)
+
+func register(rule Rule) {
+	Rules = append(Rules, rule)
+}
+
+// Template runs every rule against the resources in the template and returns the findings,
+// sorted by severity. Findings accepted by the resources' Metadata are marked.
+func Template(t cft.Template) []Finding {
+	findings := make([]Finding, 0)
+	suppressions := suppress.Template(t, time.Now())
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil || resources.Kind != yaml.MappingNode {
+		return findings
+	}
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		name, resource := resources.Content[i], resources.Content[i+1]
+
+		_, typ, _ := s11n.GetMapValue(resource, "Type")
+		if typ == nil {
+			continue
+		}
+
+		_, props, _ := s11n.GetMapValue(resource, "Properties")
+
+		for _, rule := range Rules {
+			if !contains(rule.Types, typ.Value) {
+				continue
+			}
+
+			for _, p := range rule.Check(typ.Value, props) {
+				f := Finding{
+					Rule:     rule.Name,
+					Severity: p.Severity,
+					Resource: name.Value,
+					Message:  p.Message,
+					Line:     name.Line,
+				}
+
+				for _, s := range suppressions {
+					if s.Matches(rule.Name, name.Value) {
+						f.Suppressed = true
+						f.Reason = s.Reason
+						break
+					}
+				}
+
+				findings = append(findings, f)
+			}
+		}
+	}
+
+	rank := map[Severity]int{Error: 0, Warning: 1, Info: 2}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return rank[findings[i].Severity] < rank[findings[j].Severity]
+	})
+
+	return findings
+}
+
+// HasErrors returns true if any of the findings that are not suppressed is an Error
+func HasErrors(findings []Finding) bool {
+	for _, f := range findings {
+		if f.Severity == Error && !f.Suppressed {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+// SARIF returns the findings for the template in the file as a SARIF 2.1.0 log
+func SARIF(fn string, findings []Finding) ([]byte, error) {
+	rules := make([]sarif.Rule, 0, len(Rules))
+	for _, r := range Rules {
+		rules = append(rules, sarif.Rule{Id: r.Name, Description: r.Description})
+	}
+
+	results := make([]sarif.Result, 0, len(findings))
+	for _, f := range findings {
+		results = append(results, sarif.Result{
+			Rule:          f.Rule,
+			Level:         sarif.LevelOf(string(f.Severity)),
+			Message:       f.Message,
+			File:          fn,
+			Line:          f.Line,
+			Element:       fmt.Sprintf("%s/%s", cft.Resources, f.Resource),
+			Suppressed:    f.Suppressed,
+			Justification: f.Reason,
+		})
+	}
+
+	return sarif.Log(rules, results)
+}
@@ -0,0 +1,161 @@
+package scan_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/scan"
+)
+
+const source = `
+Parameters:
+  Encrypt:
+    Type: String
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      PublicAccessBlockConfiguration:
+        BlockPublicAcls: true
+        BlockPublicPolicy: false
+        IgnorePublicAcls: true
+        RestrictPublicBuckets: true
+  LogBucket:
+    Type: AWS::S3::Bucket
+    Metadata:
+      Rain::Suppressions:
+        - Rule: s3-logging
+          Reason: this is the log bucket
+        - Rule: s3-encryption
+          Reason: encrypted by default
+          Expires: 2000-01-01
+    Properties:
+      PublicAccessBlockConfiguration:
+        BlockPublicAcls: true
+        BlockPublicPolicy: true
+        IgnorePublicAcls: true
+        RestrictPublicBuckets: true
+  Volume:
+    Type: AWS::EC2::Volume
+    Properties:
+      AvailabilityZone: us-east-1a
+      Size: 10
+      Encrypted: !Equals [!Ref Encrypt, "yes"]
+  Instance:
+    Type: AWS::EC2::Instance
+    Properties:
+      ImageId: ami-12345678
+      BlockDeviceMappings:
+        - DeviceName: /dev/xvda
+          Ebs:
+            VolumeSize: 20
+        - DeviceName: /dev/sdb
+          VirtualName: ephemeral0
+  Database:
+    Type: AWS::RDS::DBInstance
+    Properties:
+      Engine: postgres
+      PubliclyAccessible: true
+      StorageEncrypted: true
+  SecurityGroup:
+    Type: AWS::EC2::SecurityGroup
+    Properties:
+      GroupDescription: web
+      SecurityGroupIngress:
+        - IpProtocol: tcp
+          FromPort: 443
+          ToPort: 443
+          CidrIp: 0.0.0.0/0
+        - IpProtocol: tcp
+          FromPort: 22
+          ToPort: 22
+          CidrIpv6: ::/0
+        - IpProtocol: tcp
+          FromPort: 22
+          ToPort: 22
+          CidrIp: 10.0.0.0/8
+  Ingress:
+    Type: AWS::EC2::SecurityGroupIngress
+    Properties:
+      GroupId: !Ref SecurityGroup
+      IpProtocol: "-1"
+      CidrIp: 0.0.0.0/0
+  LoadBalancer:
+    Type: AWS::ElasticLoadBalancingV2::LoadBalancer
+    Properties:
+      LoadBalancerAttributes:
+        - Key: access_logs.s3.enabled
+          Value: "true"
+`
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := parse.String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0)
+	for _, f := range scan.Template(tmpl) {
+		line := fmt.Sprintf("%s %d %s", f.Severity, f.Line, f)
+		if f.Suppressed {
+			line += fmt.Sprintf(" (suppressed: %s)", f.Reason)
+		}
+		actual = append(actual, line)
+	}
+
+	// LogBucket's suppression of s3-encryption has expired
+	expected := []string{
+		"error 6 Bucket: does not block public access with BlockPublicPolicy [s3-public-access-block]",
+		"error 35 Instance: does not encrypt the EBS volume in BlockDeviceMappings/0 [ebs-encryption]",
+		"error 45 Database: is publicly accessible (PubliclyAccessible) [rds-public]",
+		"error 51 SecurityGroup: allows tcp from ::/0 on port 22 [security-group-open-ingress]",
+		"error 68 Ingress: allows all traffic from 0.0.0.0/0 [security-group-open-ingress]",
+		"warning 6 Bucket: does not set BucketEncryption; choose the default encryption for new objects, such as aws:kms [s3-encryption]",
+		"warning 14 LogBucket: does not set BucketEncryption; choose the default encryption for new objects, such as aws:kms [s3-encryption]",
+		"info 6 Bucket: does not log server access (LoggingConfiguration) [s3-logging]",
+		"info 14 LogBucket: does not log server access (LoggingConfiguration) [s3-logging] (suppressed: this is the log bucket)",
+		"info 51 SecurityGroup: allows tcp from 0.0.0.0/0 on port 443, as a public web server needs [security-group-open-ingress]",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+
+	if !scan.HasErrors(scan.Template(tmpl)) {
+		t.Error("expected errors")
+	}
+}
+
+func TestClusterEncryption(t *testing.T) {
+	tmpl, err := parse.String(`
+Resources:
+  Cluster:
+    Type: AWS::RDS::DBCluster
+    Properties:
+      DBClusterIdentifier: orders
+      Engine: aurora-postgresql
+  Instance:
+    Type: AWS::RDS::DBInstance
+    Properties:
+      DBClusterIdentifier: !Ref Cluster
+      Engine: aurora-postgresql
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0)
+	for _, f := range scan.Template(tmpl) {
+		actual = append(actual, f.String())
+	}
+
+	expected := []string{
+		"Cluster: does not encrypt its storage (StorageEncrypted) [rds-encryption]",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
@@ -0,0 +1,258 @@
+// Package score computes a maintainability score for a CloudFormation template,
+// along with concrete hints about how the template could be refactored.
+//
+// The score starts at 100 and penalties are subtracted for overall size,
+// deeply nested intrinsic functions, complex conditions, and resources
+// that look like copy-and-paste duplicates of each other.
+package score
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/similar"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// MaxResources is the number of resources above which
+	// a template starts to be penalised for its size
+	MaxResources = 50
+
+	// MaxNesting is the deepest level of intrinsic function nesting
+	// that is considered readable
+	MaxNesting = 3
+
+	// MaxConditionOps is the number of operators a single
+	// condition can contain before it is considered complex
+	MaxConditionOps = 4
+
+	// SimilarityThreshold is the ratio of shared properties
+	// above which two resources are reported as similar
+	SimilarityThreshold = similar.DefaultThreshold
+)
+
+// Metrics are the raw measurements that go into a score
+type Metrics struct {
+	Lines      int `json:"lines"`
+	Resources  int `json:"resources"`
+	Parameters int `json:"parameters"`
+	Conditions int `json:"conditions"`
+	Outputs    int `json:"outputs"`
+
+	// MaxNesting is the deepest intrinsic function nesting found in the template
+	MaxNesting int `json:"maxNesting"`
+
+	// MaxConditionOps is the largest number of operators in a single condition
+	MaxConditionOps int `json:"maxConditionOps"`
+
+	// SimilarPairs is the number of resource pairs that look like duplicates
+	SimilarPairs int `json:"similarPairs"`
+}
+
+// Hint is a suggestion for improving the template
+type Hint struct {
+	// Element is the name of the template element the hint is about
+	Element string `json:"element"`
+
+	Message string `json:"message"`
+
+	// Penalty is the number of points this issue costs
+	Penalty int `json:"penalty"`
+}
+
+func (h Hint) String() string {
+	return fmt.Sprintf("%s: %s (-%d)", h.Element, h.Message, h.Penalty)
+}
+
+// Result is the outcome of scoring a template
+type Result struct {
+	Score   int     `json:"score"`
+	Metrics Metrics `json:"metrics"`
+	Hints   []Hint  `json:"hints"`
+}
+
+func (r *Result) penalise(element string, penalty int, message string, parts ...interface{}) {
+	r.Hints = append(r.Hints, Hint{
+		Element: element,
+		Message: fmt.Sprintf(message, parts...),
+		Penalty: penalty,
+	})
+	r.Score -= penalty
+}
+
+// Template scores the template and returns the result.
+// lines is the number of lines in the source file; pass 0 if it is not known.
+// It returns an error if the template is empty.
+func Template(t cft.Template, lines int) (Result, error) {
+	if t.Node == nil || len(t.Node.Content) == 0 {
+		return Result{}, errors.New("the template is empty")
+	}
+
+	result := Result{
+		Score: 100,
+		Hints: make([]Hint, 0),
+	}
+
+	result.Metrics.Lines = lines
+
+	root := t.Node.Content[0]
+
+	resources := sectionPairs(root, cft.Resources)
+	result.Metrics.Resources = len(resources)
+	result.Metrics.Parameters = len(sectionPairs(root, cft.Parameters))
+	result.Metrics.Outputs = len(sectionPairs(root, cft.Outputs))
+
+	// Size
+	if over := len(resources) - MaxResources; over > 0 {
+		result.penalise("Resources", min(20, over/5+1),
+			"%d resources; consider splitting the template into nested stacks or modules",
+			len(resources))
+	}
+
+	// Intrinsic function nesting
+	for _, section := range []cft.Section{cft.Resources, cft.Outputs} {
+		for _, pair := range sectionPairs(root, section) {
+			depth := nesting(pair.value)
+			result.Metrics.MaxNesting = max(result.Metrics.MaxNesting, depth)
+
+			if depth > MaxNesting {
+				result.penalise(pair.name, 3*(depth-MaxNesting),
+					"intrinsic functions are nested %d deep; consider Fn::Sub, a Mapping, or a Condition",
+					depth)
+			}
+		}
+	}
+
+	// Condition complexity
+	conditions := sectionPairs(root, cft.Conditions)
+	result.Metrics.Conditions = len(conditions)
+	for _, pair := range conditions {
+		ops := conditionOps(pair.value)
+		result.Metrics.MaxConditionOps = max(result.Metrics.MaxConditionOps, ops)
+
+		if ops > MaxConditionOps {
+			result.penalise(pair.name, 2*(ops-MaxConditionOps),
+				"condition uses %d operators; consider composing it from smaller named conditions",
+				ops)
+		}
+	}
+
+	// Copy and paste
+	similarPenalty := 0
+	for i := 0; i < len(resources); i++ {
+		for j := i + 1; j < len(resources); j++ {
+			a, b := resources[i], resources[j]
+
+			if similar.ResourceType(a.value) != similar.ResourceType(b.value) {
+				continue
+			}
+
+			ratio := similar.Similarity(a.value, b.value)
+			if ratio < SimilarityThreshold {
+				continue
+			}
+
+			result.Metrics.SimilarPairs++
+
+			penalty := 0
+			if similarPenalty < 20 {
+				penalty = 2
+				similarPenalty += penalty
+			}
+
+			result.penalise(fmt.Sprintf("%s, %s", a.name, b.name), penalty,
+				"resources are %d%% similar; consider a Rain module or Fn::ForEach",
+				int(ratio*100))
+		}
+	}
+
+	if result.Score < 0 {
+		result.Score = 0
+	}
+
+	sort.SliceStable(result.Hints, func(i, j int) bool {
+		return result.Hints[i].Penalty > result.Hints[j].Penalty
+	})
+
+	return result, nil
+}
+
+type pair struct {
+	name  string
+	value *yaml.Node
+}
+
+// sectionPairs returns the name and value of each entry in a template section
+func sectionPairs(root *yaml.Node, section cft.Section) []pair {
+	pairs := make([]pair, 0)
+
+	if root == nil || root.Kind != yaml.MappingNode {
+		return pairs
+	}
+
+	for i := 0; i < len(root.Content)-1; i += 2 {
+		if root.Content[i].Value != string(section) {
+			continue
+		}
+
+		s := root.Content[i+1]
+		if s.Kind != yaml.MappingNode {
+			return pairs
+		}
+
+		for j := 0; j < len(s.Content)-1; j += 2 {
+			pairs = append(pairs, pair{s.Content[j].Value, s.Content[j+1]})
+		}
+	}
+
+	return pairs
+}
+
+// isIntrinsic returns the function name if n is a single-key intrinsic function map
+func isIntrinsic(n *yaml.Node) (string, bool) {
+	if n.Kind != yaml.MappingNode || len(n.Content) != 2 {
+		return "", false
+	}
+
+	name := n.Content[0].Value
+	if name == "Ref" || name == "Condition" || strings.HasPrefix(name, "Fn::") {
+		return name, true
+	}
+
+	return "", false
+}
+
+// nesting returns the deepest level of nested intrinsic functions in n
+func nesting(n *yaml.Node) int {
+	deepest := 0
+	for _, child := range n.Content {
+		deepest = max(deepest, nesting(child))
+	}
+
+	if _, ok := isIntrinsic(n); ok {
+		return deepest + 1
+	}
+
+	return deepest
+}
+
+// conditionOps counts the condition functions used in a condition expression
+func conditionOps(n *yaml.Node) int {
+	count := 0
+	if name, ok := isIntrinsic(n); ok {
+		switch name {
+		case "Fn::And", "Fn::Or", "Fn::Not", "Fn::Equals", "Condition":
+			count++
+		}
+	}
+
+	for _, child := range n.Content {
+		count += conditionOps(child)
+	}
+
+	return count
+}
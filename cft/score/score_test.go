@@ -0,0 +1,92 @@
+package score_test
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/score"
+)
+
+const source = `
+Parameters:
+  Env:
+    Type: String
+Conditions:
+  IsComplex: !Or
+    - !And
+      - !Equals [!Ref Env, prod]
+      - !Not [!Equals [!Ref AWS::Region, us-east-1]]
+    - !And
+      - !Equals [!Ref Env, test]
+      - !Not [!Equals [!Ref AWS::Region, eu-west-1]]
+Resources:
+  Bucket1:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: !Join ["-", [!Select [0, !Split ["-", !Join ["-", [!Ref Env, a]]]], b]]
+      VersioningConfiguration:
+        Status: Enabled
+      Tags:
+        - Key: Team
+          Value: platform
+  Bucket2:
+    Type: AWS::S3::Bucket
+    Properties:
+      VersioningConfiguration:
+        Status: Enabled
+      Tags:
+        - Key: Team
+          Value: platform
+  Bucket3:
+    Type: AWS::S3::Bucket
+    Properties:
+      VersioningConfiguration:
+        Status: Enabled
+      Tags:
+        - Key: Team
+          Value: platform
+`
+
+func TestScore(t *testing.T) {
+	tmpl, err := parse.String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := score.Template(tmpl, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Metrics.Resources != 3 {
+		t.Errorf("expected 3 resources, got %d", result.Metrics.Resources)
+	}
+
+	if result.Metrics.MaxNesting != 5 {
+		t.Errorf("expected nesting of 5, got %d", result.Metrics.MaxNesting)
+	}
+
+	if result.Metrics.MaxConditionOps != 9 {
+		t.Errorf("expected 9 condition ops, got %d", result.Metrics.MaxConditionOps)
+	}
+
+	if result.Metrics.SimilarPairs != 1 {
+		t.Errorf("expected 1 similar pair, got %d", result.Metrics.SimilarPairs)
+	}
+
+	// 6 for nesting, 10 for the condition, 2 for Bucket2/Bucket3
+	if result.Score != 82 {
+		t.Errorf("expected a score of 82, got %d: %v", result.Score, result.Hints)
+	}
+}
+
+func TestScoreEmpty(t *testing.T) {
+	tmpl, err := parse.String("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := score.Template(tmpl, 0); err == nil {
+		t.Error("expected an error for an empty template")
+	}
+}
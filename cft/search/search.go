@@ -0,0 +1,228 @@
+// Package search finds resources in templates by their type and property values,
+// to answer questions about a fleet of stacks like "which databases still run Postgres 11?"
+package search
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"gopkg.in/yaml.v3"
+)
+
+// Condition is a test on the value of a resource property
+type Condition struct {
+	// Path is the path to the property inside Properties, e.g. BucketEncryption/ServerSideEncryptionConfiguration
+	Path string
+
+	// Value is a pattern the value must match, which can use * as a wildcard.
+	// Values are compared without regard to case.
+	Value string
+
+	// Exists is set if the property only needs to be set, whatever its value
+	Exists bool
+}
+
+func (c Condition) String() string {
+	if c.Exists {
+		return c.Path
+	}
+
+	return fmt.Sprintf("%s=%s", c.Path, c.Value)
+}
+
+// ParseCondition reads a condition like Engine=postgres, EngineVersion=11*, or DeletionProtection
+func ParseCondition(s string) (Condition, error) {
+	name, value, found := strings.Cut(s, "=")
+
+	name = strings.Trim(strings.TrimSpace(name), "/")
+	if name == "" {
+		return Condition{}, fmt.Errorf("invalid property condition '%s'", s)
+	}
+
+	if !found {
+		return Condition{Path: name, Exists: true}, nil
+	}
+
+	if _, err := path.Match(value, ""); err != nil {
+		return Condition{}, fmt.Errorf("invalid pattern in '%s': %w", s, err)
+	}
+
+	return Condition{Path: name, Value: value}, nil
+}
+
+// Query selects resources by type and property values
+type Query struct {
+	// Type is a resource type, which can use * as a wildcard, e.g. AWS::RDS::*
+	Type string
+
+	// Conditions must all be true for a resource to match
+	Conditions []Condition
+}
+
+// Match is a resource that matches a query
+type Match struct {
+	LogicalId string `json:"logicalId"`
+
+	Type string `json:"type"`
+
+	// Values are the values of the properties in the query's conditions
+	Values map[string]string `json:"values,omitempty"`
+
+	// Line is the line of the resource in the template
+	Line int `json:"line,omitempty"`
+}
+
+// ErrNoResources is returned by Template if the template has no resources
+var ErrNoResources = errors.New("template has no resources")
+
+// Template returns the resources in the template that match the query, sorted by logical id.
+// Refs to parameters are resolved with params, or with the parameter's default if it is not in params.
+func (q Query) Template(t cft.Template, params map[string]string) ([]Match, error) {
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil || resources.Kind != yaml.MappingNode {
+		return nil, ErrNoResources
+	}
+
+	typePattern := q.Type
+	if typePattern == "" {
+		typePattern = "*"
+	}
+
+	params = parameterValues(t, params)
+
+	matches := make([]Match, 0)
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		name := resources.Content[i]
+		resource := resources.Content[i+1]
+
+		typeName := value(resource, "Type")
+		if ok, _ := path.Match(typePattern, typeName); !ok {
+			continue
+		}
+
+		m := Match{
+			LogicalId: name.Value,
+			Type:      typeName,
+			Line:      name.Line,
+		}
+
+		matched := true
+		for _, c := range q.Conditions {
+			v, ok := lookup(resource, "Properties/"+c.Path, params)
+			if !ok {
+				matched = false
+				break
+			}
+
+			if !c.Exists {
+				if ok, _ := path.Match(strings.ToLower(c.Value), strings.ToLower(v)); !ok {
+					matched = false
+					break
+				}
+			}
+
+			if m.Values == nil {
+				m.Values = make(map[string]string)
+			}
+			m.Values[c.Path] = v
+		}
+
+		if matched {
+			matches = append(matches, m)
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].LogicalId < matches[j].LogicalId
+	})
+
+	return matches, nil
+}
+
+// parameterValues returns the values of the template's parameters,
+// taken from params or from the parameters' defaults
+func parameterValues(t cft.Template, params map[string]string) map[string]string {
+	values := make(map[string]string)
+
+	if parameters, err := t.GetSection(cft.Parameters); err == nil && parameters.Kind == yaml.MappingNode {
+		for i := 0; i < len(parameters.Content)-1; i += 2 {
+			if d := value(parameters.Content[i+1], "Default"); d != "" {
+				values[parameters.Content[i].Value] = d
+			}
+		}
+	}
+
+	for k, v := range params {
+		values[k] = v
+	}
+
+	return values
+}
+
+// value returns the scalar value of a key in a mapping, or ""
+func value(n *yaml.Node, key string) string {
+	if n.Kind != yaml.MappingNode {
+		return ""
+	}
+
+	for i := 0; i < len(n.Content)-1; i += 2 {
+		if n.Content[i].Value == key && n.Content[i+1].Kind == yaml.ScalarNode {
+			return n.Content[i+1].Value
+		}
+	}
+
+	return ""
+}
+
+// lookup returns the value at a path like Properties/Tags/0/Key, and whether it is set.
+// A Ref to a parameter is replaced by the parameter's value.
+// Other values that aren't scalars are summarised, e.g. {Fn::GetAtt} or [3 items].
+func lookup(n *yaml.Node, p string, params map[string]string) (string, bool) {
+	for _, part := range strings.Split(p, "/") {
+		var next *yaml.Node
+
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i < len(n.Content)-1; i += 2 {
+				if n.Content[i].Value == part {
+					next = n.Content[i+1]
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			var index int
+			if _, err := fmt.Sscanf(part, "%d", &index); err == nil && index >= 0 && index < len(n.Content) {
+				next = n.Content[index]
+			}
+		}
+
+		if next == nil {
+			return "", false
+		}
+		n = next
+	}
+
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Value, true
+	case yaml.SequenceNode:
+		return fmt.Sprintf("[%d items]", len(n.Content)), true
+	case yaml.MappingNode:
+		if len(n.Content) == 2 && (n.Content[0].Value == "Ref" || strings.HasPrefix(n.Content[0].Value, "Fn::")) {
+			if n.Content[0].Value == "Ref" {
+				if v, ok := params[n.Content[1].Value]; ok {
+					return v, true
+				}
+			}
+			return fmt.Sprintf("{%s}", n.Content[0].Value), true
+		}
+		return "{...}", true
+	}
+
+	return "", false
+}
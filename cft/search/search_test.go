@@ -0,0 +1,104 @@
+package search_test
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/search"
+)
+
+const source = `
+Parameters:
+  Version:
+    Type: String
+    Default: "15.4"
+Resources:
+  Legacy:
+    Type: AWS::RDS::DBInstance
+    Properties:
+      Engine: Postgres
+      EngineVersion: "11.22"
+  Current:
+    Type: AWS::RDS::DBInstance
+    Properties:
+      Engine: postgres
+      EngineVersion: !Ref Version
+  MySQL:
+    Type: AWS::RDS::DBInstance
+    Properties:
+      Engine: mysql
+      EngineVersion: "5.7"
+  Cluster:
+    Type: AWS::RDS::DBCluster
+    Properties:
+      Engine: aurora-postgresql
+      DeletionProtection: true
+`
+
+func query(t *testing.T, typeName string, conditions ...string) search.Query {
+	q := search.Query{Type: typeName}
+	for _, s := range conditions {
+		c, err := search.ParseCondition(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		q.Conditions = append(q.Conditions, c)
+	}
+	return q
+}
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := parse.String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		query    search.Query
+		params   map[string]string
+		expected []string
+	}{
+		{query(t, "AWS::RDS::DBInstance"), nil, []string{"Current", "Legacy", "MySQL"}},
+		{query(t, "AWS::RDS::*", "Engine=*postgres*"), nil, []string{"Cluster", "Current", "Legacy"}},
+		{query(t, "AWS::RDS::DBInstance", "Engine=postgres", "EngineVersion=11*"), nil, []string{"Legacy"}},
+		{query(t, "AWS::RDS::DBInstance", "Engine=postgres", "EngineVersion=11*"), map[string]string{"Version": "11.1"}, []string{"Current", "Legacy"}},
+		{query(t, "", "DeletionProtection"), nil, []string{"Cluster"}},
+		{query(t, "AWS::S3::Bucket"), nil, []string{}},
+	}
+
+	for i, c := range cases {
+		matches, err := c.query.Template(tmpl, c.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := make([]string, 0)
+		for _, m := range matches {
+			actual = append(actual, m.LogicalId)
+		}
+
+		if len(actual) != len(c.expected) {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, actual)
+			continue
+		}
+		for j := range actual {
+			if actual[j] != c.expected[j] {
+				t.Errorf("case %d: expected %v, got %v", i, c.expected, actual)
+				break
+			}
+		}
+	}
+
+	matches, _ := query(t, "AWS::RDS::DBInstance", "EngineVersion").Template(tmpl, nil)
+	if matches[0].LogicalId != "Current" || matches[0].Values["EngineVersion"] != "15.4" {
+		t.Errorf("expected the parameter's default, got %+v", matches[0])
+	}
+}
+
+func TestParseCondition(t *testing.T) {
+	for _, s := range []string{"", "=x", "Engine=[", " / =x"} {
+		if _, err := search.ParseCondition(s); err == nil {
+			t.Errorf("expected an error for '%s'", s)
+		}
+	}
+}
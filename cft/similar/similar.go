@@ -0,0 +1,364 @@
+// Package similar finds resources that are near-identical copies of each other,
+// within one template or across several, and drafts Rain modules or
+// Fn::ForEach blocks that could replace the copies.
+package similar
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultThreshold is the similarity above which resources are clustered together
+const DefaultThreshold = 0.8
+
+// Member is a resource that belongs to a cluster
+type Member struct {
+	// File is the template the resource was found in
+	File string
+
+	LogicalId string
+
+	Resource *yaml.Node
+}
+
+func (m Member) String() string {
+	if m.File == "" {
+		return m.LogicalId
+	}
+
+	return fmt.Sprintf("%s:%s", m.File, m.LogicalId)
+}
+
+// Cluster is a group of resources of the same type
+// that are all similar to at least one other member
+type Cluster struct {
+	Type    string
+	Members []Member
+
+	// Similarity is the lowest similarity between any two linked members
+	Similarity float64
+}
+
+// Resources returns a Member for every resource in the template
+func Resources(file string, t cft.Template) []Member {
+	members := make([]Member, 0)
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil {
+		return members
+	}
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		members = append(members, Member{
+			File:      file,
+			LogicalId: resources.Content[i].Value,
+			Resource:  resources.Content[i+1],
+		})
+	}
+
+	return members
+}
+
+// flatten adds the set of leaf path=value strings found in n to out
+func flatten(n *yaml.Node, path string, out map[string]bool) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		for i := 0; i < len(n.Content)-1; i += 2 {
+			flatten(n.Content[i+1], path+"/"+n.Content[i].Value, out)
+		}
+	case yaml.SequenceNode:
+		for i, child := range n.Content {
+			flatten(child, fmt.Sprintf("%s/%d", path, i), out)
+		}
+	case yaml.AliasNode:
+		if n.Alias != nil {
+			flatten(n.Alias, path, out)
+		}
+	default:
+		out[path+"="+n.Value] = true
+	}
+}
+
+// Similarity returns the Jaccard similarity of the leaf values of a and b,
+// which is 1.0 if they are identical and 0.0 if they have nothing in common
+func Similarity(a, b *yaml.Node) float64 {
+	left := make(map[string]bool)
+	right := make(map[string]bool)
+	flatten(a, "", left)
+	flatten(b, "", right)
+
+	if len(left) == 0 && len(right) == 0 {
+		return 1
+	}
+
+	shared := 0
+	for k := range left {
+		if right[k] {
+			shared++
+		}
+	}
+
+	return float64(shared) / float64(len(left)+len(right)-shared)
+}
+
+// ResourceType returns the Type of a resource node
+func ResourceType(resource *yaml.Node) string {
+	for i := 0; i < len(resource.Content)-1; i += 2 {
+		if resource.Content[i].Value == "Type" {
+			return resource.Content[i+1].Value
+		}
+	}
+
+	return ""
+}
+
+// Clusters groups members of the same type whose similarity is at least threshold.
+// Clusters are linked transitively, so A and C can share a cluster
+// if both are similar to B. Only clusters with more than one member are returned.
+func Clusters(members []Member, threshold float64) []Cluster {
+	parent := make([]int, len(members))
+	lowest := make([]float64, len(members))
+	for i := range parent {
+		parent[i] = i
+		lowest[i] = 1
+	}
+
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+
+	for i := 0; i < len(members); i++ {
+		for j := i + 1; j < len(members); j++ {
+			t := ResourceType(members[i].Resource)
+			if t == "" || t != ResourceType(members[j].Resource) {
+				continue
+			}
+
+			s := Similarity(members[i].Resource, members[j].Resource)
+			if s < threshold {
+				continue
+			}
+
+			a, b := find(i), find(j)
+			if a != b {
+				parent[b] = a
+			}
+			lowest[a] = min(lowest[a], lowest[b], s)
+		}
+	}
+
+	groups := make(map[int][]Member)
+	order := make([]int, 0)
+	for i, m := range members {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], m)
+	}
+
+	clusters := make([]Cluster, 0)
+	for _, root := range order {
+		if len(groups[root]) < 2 {
+			continue
+		}
+
+		clusters = append(clusters, Cluster{
+			Type:       ResourceType(groups[root][0].Resource),
+			Members:    groups[root],
+			Similarity: lowest[root],
+		})
+	}
+
+	// Biggest clusters first
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return len(clusters[i].Members) > len(clusters[j].Members)
+	})
+
+	return clusters
+}
+
+func getMap(n *yaml.Node, key string) *yaml.Node {
+	for i := 0; i < len(n.Content)-1; i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func equal(a, b *yaml.Node) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return Similarity(a, b) == 1 && a.Kind == b.Kind
+}
+
+// Varying returns the names of the top-level properties that
+// are not the same for every member of the cluster, in sorted order
+func (c Cluster) Varying() []string {
+	names := make(map[string]bool)
+	for _, m := range c.Members {
+		props := getMap(m.Resource, "Properties")
+		if props == nil {
+			continue
+		}
+		for i := 0; i < len(props.Content)-1; i += 2 {
+			names[props.Content[i].Value] = true
+		}
+	}
+
+	varying := make([]string, 0)
+	for name := range names {
+		first := propValue(c.Members[0], name)
+		for _, m := range c.Members[1:] {
+			if !equal(first, propValue(m, name)) {
+				varying = append(varying, name)
+				break
+			}
+		}
+	}
+
+	sort.Strings(varying)
+
+	return varying
+}
+
+func propValue(m Member, name string) *yaml.Node {
+	props := getMap(m.Resource, "Properties")
+	if props == nil {
+		return nil
+	}
+
+	return getMap(props, name)
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+func mapping(pairs ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Content: pairs}
+}
+
+// DraftModule returns a draft Rain module for the cluster,
+// and a Resources section showing how each member would use it.
+// Properties that vary between members become module parameters.
+func (c Cluster) DraftModule(modulePath string) (*yaml.Node, *yaml.Node) {
+	varying := c.Varying()
+	isVarying := make(map[string]bool)
+	for _, name := range varying {
+		isVarying[name] = true
+	}
+
+	// The module
+	params := mapping()
+	for _, name := range varying {
+		params.Content = append(params.Content, scalar(name), mapping(scalar("Type"), scalar("String")))
+	}
+
+	first := node.Clone(c.Members[0].Resource)
+	if props := getMap(first, "Properties"); props != nil {
+		for i := 0; i < len(props.Content)-1; i += 2 {
+			name := props.Content[i].Value
+			if isVarying[name] {
+				props.Content[i+1] = mapping(scalar("Ref"), scalar(name))
+			}
+		}
+		for _, name := range varying {
+			if getMap(props, name) == nil {
+				props.Content = append(props.Content, scalar(name), mapping(scalar("Ref"), scalar(name)))
+			}
+		}
+	}
+
+	module := mapping()
+	if len(params.Content) > 0 {
+		module.Content = append(module.Content, scalar("Parameters"), params)
+	}
+	module.Content = append(module.Content, scalar("Resources"), mapping(scalar("Resource"), first))
+
+	// The usage
+	usage := mapping()
+	for _, m := range c.Members {
+		props := mapping()
+		for _, name := range varying {
+			if v := propValue(m, name); v != nil {
+				props.Content = append(props.Content, scalar(name), node.Clone(v))
+			}
+		}
+
+		resource := mapping(scalar("Type"), &yaml.Node{Kind: yaml.ScalarNode, Tag: "!Rain::Module", Value: modulePath})
+		if len(props.Content) > 0 {
+			resource.Content = append(resource.Content, scalar("Properties"), props)
+		}
+
+		usage.Content = append(usage.Content, scalar(m.LogicalId), resource)
+	}
+
+	return module, usage
+}
+
+// commonPrefix returns the longest prefix shared by all of the logical ids
+func (c Cluster) commonPrefix() string {
+	prefix := c.Members[0].LogicalId
+	for _, m := range c.Members[1:] {
+		for !strings.HasPrefix(m.LogicalId, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+
+	return prefix
+}
+
+// DraftForEach returns a draft Fn::ForEach block that could replace the cluster,
+// or nil if the members differ in more than one scalar property
+func (c Cluster) DraftForEach() *yaml.Node {
+	varying := c.Varying()
+	if len(varying) != 1 {
+		return nil
+	}
+
+	name := varying[0]
+
+	values := &yaml.Node{Kind: yaml.SequenceNode}
+	for _, m := range c.Members {
+		v := propValue(m, name)
+		if v == nil || v.Kind != yaml.ScalarNode {
+			return nil
+		}
+		values.Content = append(values.Content, scalar(v.Value))
+	}
+
+	prefix := c.commonPrefix()
+	if prefix == "" {
+		prefix = "Resource"
+	}
+
+	resource := node.Clone(c.Members[0].Resource)
+	props := getMap(resource, "Properties")
+	for i := 0; i < len(props.Content)-1; i += 2 {
+		if props.Content[i].Value == name {
+			props.Content[i+1] = mapping(scalar("Ref"), scalar("Value"))
+		}
+	}
+
+	loop := &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{
+		scalar("Value"),
+		values,
+		mapping(scalar(prefix+"&{Value}"), resource),
+	}}
+
+	return mapping(scalar("Fn::ForEach::"+prefix), loop)
+}
@@ -0,0 +1,152 @@
+package similar_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/similar"
+	"gopkg.in/yaml.v3"
+)
+
+const source = `
+Resources:
+  QueueA:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: a
+      VisibilityTimeout: 60
+      MessageRetentionPeriod: 1209600
+      DelaySeconds: 5
+      ReceiveMessageWaitTimeSeconds: 20
+      MaximumMessageSize: 262144
+      KmsMasterKeyId: alias/aws/sqs
+      KmsDataKeyReusePeriodSeconds: 300
+      FifoQueue: false
+  QueueB:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: b
+      VisibilityTimeout: 60
+      MessageRetentionPeriod: 1209600
+      DelaySeconds: 5
+      ReceiveMessageWaitTimeSeconds: 20
+      MaximumMessageSize: 262144
+      KmsMasterKeyId: alias/aws/sqs
+      KmsDataKeyReusePeriodSeconds: 300
+      FifoQueue: false
+  QueueC:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: c
+      VisibilityTimeout: 60
+      MessageRetentionPeriod: 1209600
+      DelaySeconds: 5
+      ReceiveMessageWaitTimeSeconds: 20
+      MaximumMessageSize: 262144
+      KmsMasterKeyId: alias/aws/sqs
+      KmsDataKeyReusePeriodSeconds: 300
+      FifoQueue: false
+  Other:
+    Type: AWS::SQS::Queue
+    Properties:
+      QueueName: other
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName: a
+`
+
+func clusters(t *testing.T) []similar.Cluster {
+	tmpl, err := parse.String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return similar.Clusters(similar.Resources("", tmpl), similar.DefaultThreshold)
+}
+
+func TestSimilarity(t *testing.T) {
+	c := clusters(t)
+
+	a := c[0].Members[0].Resource
+	if s := similar.Similarity(a, a); s != 1 {
+		t.Errorf("identical resources should be 1.0, got %f", s)
+	}
+
+	tmpl, _ := parse.String(source)
+	for _, m := range similar.Resources("", tmpl) {
+		if m.LogicalId == "Other" {
+			if s := similar.Similarity(a, m.Resource); s >= similar.DefaultThreshold {
+				t.Errorf("dissimilar resources should be below the threshold, got %f", s)
+			}
+		}
+	}
+}
+
+func TestClusters(t *testing.T) {
+	c := clusters(t)
+
+	if len(c) != 1 {
+		t.Fatalf("expected 1 cluster, got %d", len(c))
+	}
+
+	ids := make([]string, 0)
+	for _, m := range c[0].Members {
+		ids = append(ids, m.String())
+	}
+
+	if strings.Join(ids, ",") != "QueueA,QueueB,QueueC" {
+		t.Errorf("unexpected members: %v", ids)
+	}
+
+	if v := c[0].Varying(); len(v) != 1 || v[0] != "QueueName" {
+		t.Errorf("unexpected varying properties: %v", v)
+	}
+}
+
+func toString(t *testing.T, n *yaml.Node) string {
+	tmpl, err := parse.Node(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{n}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return format.String(tmpl, format.Options{Unsorted: true})
+}
+
+func TestDraftModule(t *testing.T) {
+	c := clusters(t)
+
+	module, usage := c[0].DraftModule("./queue.yaml")
+
+	m := toString(t, module)
+	for _, expected := range []string{"Parameters:", "QueueName:", "Type: String", "QueueName: !Ref QueueName", "VisibilityTimeout: 60"} {
+		if !strings.Contains(m, expected) {
+			t.Errorf("module is missing '%s':\n%s", expected, m)
+		}
+	}
+
+	u := toString(t, usage)
+	for _, expected := range []string{"QueueB:", "Type: !Rain::Module ./queue.yaml", "QueueName: b"} {
+		if !strings.Contains(u, expected) {
+			t.Errorf("usage is missing '%s':\n%s", expected, u)
+		}
+	}
+}
+
+func TestDraftForEach(t *testing.T) {
+	c := clusters(t)
+
+	f := c[0].DraftForEach()
+	if f == nil {
+		t.Fatal("expected a ForEach block")
+	}
+
+	out := toString(t, f)
+	for _, expected := range []string{"Fn::ForEach::Queue:", "Queue&{Value}:", "QueueName: !Ref Value"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("ForEach is missing '%s':\n%s", expected, out)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+// Package spec reads the CloudFormation resource specification
+// and reports the differences between two versions of it,
+// so that users notice when CloudFormation starts to support
+// a resource type or property that they have been working around.
+package spec
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Spec is the part of the CloudFormation resource specification that rain compares
+type Spec struct {
+	ResourceSpecificationVersion string
+
+	// ResourceTypes are keyed by type name, e.g. AWS::S3::Bucket
+	ResourceTypes map[string]Type
+
+	// PropertyTypes are keyed by the resource type and property type, e.g. AWS::S3::Bucket.Rule
+	PropertyTypes map[string]Type
+}
+
+// Type is a resource type or property type
+type Type struct {
+	Properties map[string]Property
+	Attributes map[string]json.RawMessage
+}
+
+// Property is a property of a resource type or property type
+type Property struct {
+	Required   bool
+	UpdateType string
+}
+
+// Parse reads a resource specification
+func Parse(content []byte) (*Spec, error) {
+	var s Spec
+	if err := json.Unmarshal(content, &s); err != nil {
+		return nil, err
+	}
+
+	if s.ResourceSpecificationVersion == "" {
+		return nil, errors.New("the file is not a CloudFormation resource specification")
+	}
+
+	return &s, nil
+}
+
+// Kind is the kind of a Change
+type Kind string
+
+const (
+	AddedResourceType   Kind = "new resource type"
+	RemovedResourceType Kind = "removed resource type"
+	AddedProperty       Kind = "new property"
+	RemovedProperty     Kind = "removed property"
+	AddedAttribute      Kind = "new attribute"
+	RemovedAttribute    Kind = "removed attribute"
+	NoLongerRequired    Kind = "no longer required"
+	NowRequired         Kind = "now required"
+)
+
+// Change is a difference between two versions of the specification
+type Change struct {
+	Kind Kind
+
+	// TypeName is a resource type, e.g. AWS::S3::Bucket,
+	// or a property type, e.g. AWS::S3::Bucket.Rule
+	TypeName string
+
+	// Name is the property or attribute that changed, if any
+	Name string
+}
+
+// ResourceType returns the resource type the change belongs to
+func (c Change) ResourceType() string {
+	resourceType, _, _ := strings.Cut(c.TypeName, ".")
+	return resourceType
+}
+
+func (c Change) String() string {
+	if c.Name == "" {
+		return fmt.Sprintf("%s: %s", c.Kind, c.TypeName)
+	}
+
+	return fmt.Sprintf("%s: %s.%s", c.Kind, c.TypeName, c.Name)
+}
+
+// Diff returns the changes from one version of the specification to another, sorted by type name
+func Diff(from, to *Spec) []Change {
+	changes := make([]Change, 0)
+
+	changes = append(changes, diffTypes(from.ResourceTypes, to.ResourceTypes, true)...)
+	changes = append(changes, diffTypes(from.PropertyTypes, to.PropertyTypes, false)...)
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].ResourceType() != changes[j].ResourceType() {
+			return changes[i].ResourceType() < changes[j].ResourceType()
+		}
+
+		if changes[i].TypeName != changes[j].TypeName {
+			return changes[i].TypeName < changes[j].TypeName
+		}
+
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+// diffTypes compares resource types, or property types
+func diffTypes(from, to map[string]Type, resourceTypes bool) []Change {
+	changes := make([]Change, 0)
+
+	for name, n := range to {
+		o, ok := from[name]
+		if !ok {
+			// Properties of new resource types are implied
+			if resourceTypes {
+				changes = append(changes, Change{Kind: AddedResourceType, TypeName: name})
+			}
+			continue
+		}
+
+		for prop, np := range n.Properties {
+			op, ok := o.Properties[prop]
+			switch {
+			case !ok:
+				changes = append(changes, Change{Kind: AddedProperty, TypeName: name, Name: prop})
+			case op.Required && !np.Required:
+				changes = append(changes, Change{Kind: NoLongerRequired, TypeName: name, Name: prop})
+			case !op.Required && np.Required:
+				changes = append(changes, Change{Kind: NowRequired, TypeName: name, Name: prop})
+			}
+		}
+
+		for prop := range o.Properties {
+			if _, ok := n.Properties[prop]; !ok {
+				changes = append(changes, Change{Kind: RemovedProperty, TypeName: name, Name: prop})
+			}
+		}
+
+		for attr := range n.Attributes {
+			if _, ok := o.Attributes[attr]; !ok {
+				changes = append(changes, Change{Kind: AddedAttribute, TypeName: name, Name: attr})
+			}
+		}
+
+		for attr := range o.Attributes {
+			if _, ok := n.Attributes[attr]; !ok {
+				changes = append(changes, Change{Kind: RemovedAttribute, TypeName: name, Name: attr})
+			}
+		}
+	}
+
+	if resourceTypes {
+		for name := range from {
+			if _, ok := to[name]; !ok {
+				changes = append(changes, Change{Kind: RemovedResourceType, TypeName: name})
+			}
+		}
+	}
+
+	return changes
+}
+
+// service returns the namespace of a resource type, e.g. AWS::S3 for AWS::S3::Bucket
+func service(resourceType string) string {
+	i := strings.LastIndex(resourceType, "::")
+	if i < 0 {
+		return resourceType
+	}
+
+	return resourceType[:i]
+}
+
+// Relevant returns the changes to the resource types that are used,
+// and the new resource types of the services that they belong to
+func Relevant(changes []Change, used []string) []Change {
+	types := make(map[string]bool)
+	services := make(map[string]bool)
+	for _, t := range used {
+		types[t] = true
+		services[service(t)] = true
+	}
+
+	out := make([]Change, 0)
+	for _, c := range changes {
+		if types[c.ResourceType()] || (c.Kind == AddedResourceType && services[service(c.TypeName)]) {
+			out = append(out, c)
+		}
+	}
+
+	return out
+}
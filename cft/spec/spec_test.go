@@ -0,0 +1,117 @@
+package spec_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/spec"
+)
+
+const oldSpec = `{
+  "ResourceSpecificationVersion": "180.0.0",
+  "PropertyTypes": {
+    "AWS::S3::Bucket.Rule": {
+      "Properties": {
+        "Status": {"Required": true},
+        "Prefix": {"Required": false}
+      }
+    }
+  },
+  "ResourceTypes": {
+    "AWS::S3::Bucket": {
+      "Attributes": {"Arn": {}},
+      "Properties": {
+        "BucketName": {"Required": false},
+        "LegacyThing": {"Required": false}
+      }
+    },
+    "AWS::SQS::Queue": {
+      "Properties": {"QueueName": {"Required": false}}
+    },
+    "AWS::Old::Thing": {}
+  }
+}`
+
+const newSpec = `{
+  "ResourceSpecificationVersion": "181.0.0",
+  "PropertyTypes": {
+    "AWS::S3::Bucket.Rule": {
+      "Properties": {
+        "Status": {"Required": false},
+        "Prefix": {"Required": false},
+        "ObjectSizeGreaterThan": {"Required": false}
+      }
+    }
+  },
+  "ResourceTypes": {
+    "AWS::S3::Bucket": {
+      "Attributes": {"Arn": {}, "DualStackDomainName": {}},
+      "Properties": {
+        "BucketName": {"Required": false},
+        "MetadataConfiguration": {"Required": false}
+      }
+    },
+    "AWS::S3::TableBucket": {
+      "Properties": {"TableBucketName": {"Required": true}}
+    },
+    "AWS::SQS::Queue": {
+      "Properties": {"QueueName": {"Required": false}, "Tags": {"Required": false}}
+    },
+    "AWS::EC2::NewThing": {}
+  }
+}`
+
+func parse(t *testing.T, content string) *spec.Spec {
+	s, err := spec.Parse([]byte(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func lines(changes []spec.Change) string {
+	out := make([]string, len(changes))
+	for i, c := range changes {
+		out[i] = c.String()
+	}
+	return strings.Join(out, "\n")
+}
+
+func TestDiff(t *testing.T) {
+	changes := spec.Diff(parse(t, oldSpec), parse(t, newSpec))
+
+	expected := `new resource type: AWS::EC2::NewThing
+removed resource type: AWS::Old::Thing
+new attribute: AWS::S3::Bucket.DualStackDomainName
+removed property: AWS::S3::Bucket.LegacyThing
+new property: AWS::S3::Bucket.MetadataConfiguration
+new property: AWS::S3::Bucket.Rule.ObjectSizeGreaterThan
+no longer required: AWS::S3::Bucket.Rule.Status
+new resource type: AWS::S3::TableBucket
+new property: AWS::SQS::Queue.Tags`
+
+	if got := lines(changes); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestRelevant(t *testing.T) {
+	changes := spec.Diff(parse(t, oldSpec), parse(t, newSpec))
+
+	expected := `new attribute: AWS::S3::Bucket.DualStackDomainName
+removed property: AWS::S3::Bucket.LegacyThing
+new property: AWS::S3::Bucket.MetadataConfiguration
+new property: AWS::S3::Bucket.Rule.ObjectSizeGreaterThan
+no longer required: AWS::S3::Bucket.Rule.Status
+new resource type: AWS::S3::TableBucket`
+
+	if got := lines(spec.Relevant(changes, []string{"AWS::S3::Bucket"})); got != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, got)
+	}
+}
+
+func TestParseNotASpec(t *testing.T) {
+	if _, err := spec.Parse([]byte(`{"Resources": {}}`)); err == nil {
+		t.Error("expected an error for a file that is not a specification")
+	}
+}
@@ -0,0 +1,231 @@
+// Package summary describes a CloudFormation template at a glance:
+// its transforms, the capabilities it requires, its parameters,
+// and how many resources of each type it declares.
+//
+// The summary is worked out from the template itself. CloudFormation's
+// GetTemplateSummary is authoritative about transforms and capabilities,
+// so callers that can reach it should overwrite those fields.
+package summary
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// Parameter is a parameter of the template
+type Parameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	HasDefault  bool   `json:"hasDefault"`
+	NoEcho      bool   `json:"noEcho,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ResourceType is the number of resources of a type
+type ResourceType struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// Summary describes a template
+type Summary struct {
+	Description string `json:"description,omitempty"`
+
+	Transforms []string `json:"transforms"`
+
+	Capabilities []string `json:"capabilities"`
+
+	// CapabilitiesReason explains why the capabilities are required
+	CapabilitiesReason string `json:"capabilitiesReason,omitempty"`
+
+	Parameters []Parameter `json:"parameters"`
+
+	// ResourceTypes are sorted by count, most common first
+	ResourceTypes []ResourceType `json:"resourceTypes"`
+
+	Resources  int `json:"resources"`
+	Outputs    int `json:"outputs"`
+	Conditions int `json:"conditions"`
+
+	// Warnings are reported by CloudFormation, e.g. about unrecognized resource types
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// iamTypes are the resource types that require an IAM capability,
+// with the property that gives them a custom name, which requires CAPABILITY_NAMED_IAM
+var iamTypes = map[string]string{
+	"AWS::IAM::AccessKey":           "",
+	"AWS::IAM::Group":               "GroupName",
+	"AWS::IAM::GroupPolicy":         "",
+	"AWS::IAM::InstanceProfile":     "InstanceProfileName",
+	"AWS::IAM::ManagedPolicy":       "ManagedPolicyName",
+	"AWS::IAM::Policy":              "",
+	"AWS::IAM::Role":                "RoleName",
+	"AWS::IAM::RolePolicy":          "",
+	"AWS::IAM::User":                "UserName",
+	"AWS::IAM::UserPolicy":          "",
+	"AWS::IAM::UserToGroupAddition": "",
+}
+
+// Transforms returns the names of the transforms that the template declares
+func Transforms(t cft.Template) []string {
+	out := make([]string, 0)
+
+	section, err := t.GetSection(cft.Transform)
+	if err != nil {
+		return out
+	}
+
+	names := []*yaml.Node{section}
+	if section.Kind == yaml.SequenceNode {
+		names = section.Content
+	}
+
+	for _, n := range names {
+		// Transforms with parameters, like AWS::Include, are mappings with a Name
+		if n.Kind == yaml.MappingNode {
+			if _, name, _ := s11n.GetMapValue(n, "Name"); name != nil {
+				n = name
+			}
+		}
+
+		if n.Kind == yaml.ScalarNode {
+			out = append(out, n.Value)
+		}
+	}
+
+	return out
+}
+
+// scalar returns the value of a key in a mapping, with lists joined by commas
+func scalar(n *yaml.Node, key string) (string, bool) {
+	_, v, _ := s11n.GetMapValue(n, key)
+	if v == nil {
+		return "", false
+	}
+
+	if v.Kind == yaml.SequenceNode {
+		values := make([]string, 0, len(v.Content))
+		for _, item := range v.Content {
+			values = append(values, item.Value)
+		}
+		return strings.Join(values, ","), true
+	}
+
+	return v.Value, true
+}
+
+// entries returns the names and values of a section of the template
+func entries(t cft.Template, section cft.Section) ([]string, []*yaml.Node) {
+	s, err := t.GetSection(section)
+	if err != nil || s.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+
+	names := make([]string, 0)
+	values := make([]*yaml.Node, 0)
+	for i := 0; i < len(s.Content)-1; i += 2 {
+		names = append(names, s.Content[i].Value)
+		values = append(values, s.Content[i+1])
+	}
+
+	return names, values
+}
+
+// New returns the summary of a template
+func New(t cft.Template) Summary {
+	s := Summary{
+		Transforms:    Transforms(t),
+		Capabilities:  make([]string, 0),
+		Parameters:    make([]Parameter, 0),
+		ResourceTypes: make([]ResourceType, 0),
+	}
+
+	if _, d, _ := s11n.GetMapValue(t.Node.Content[0], string(cft.Description)); d != nil {
+		s.Description = strings.TrimSpace(d.Value)
+	}
+
+	names, values := entries(t, cft.Parameters)
+	for i, name := range names {
+		p := Parameter{Name: name}
+		p.Type, _ = scalar(values[i], "Type")
+		p.Default, p.HasDefault = scalar(values[i], "Default")
+		p.Description, _ = scalar(values[i], "Description")
+		noEcho, _ := scalar(values[i], "NoEcho")
+		p.NoEcho = strings.EqualFold(noEcho, "true")
+		s.Parameters = append(s.Parameters, p)
+	}
+
+	counts := make(map[string]int)
+	needsIAM, needsNamedIAM := false, false
+
+	names, values = entries(t, cft.Resources)
+	for i, name := range names {
+		typeName, _ := scalar(values[i], "Type")
+		if typeName == "" {
+			// Rain modules have a mapping for a type
+			if _, typ, _ := s11n.GetMapValue(values[i], "Type"); typ != nil && typ.Kind == yaml.MappingNode && len(typ.Content) > 0 {
+				typeName = typ.Content[0].Value
+			} else {
+				typeName = "(unknown)"
+			}
+		}
+
+		counts[typeName]++
+
+		if nameProp, ok := iamTypes[typeName]; ok {
+			needsIAM = true
+
+			if _, props, _ := s11n.GetMapValue(values[i], "Properties"); props != nil && nameProp != "" {
+				if _, v, _ := s11n.GetMapValue(props, nameProp); v != nil {
+					needsNamedIAM = true
+					s.CapabilitiesReason = "The template has an IAM resource with a custom name: " + name
+				}
+			}
+
+			if s.CapabilitiesReason == "" {
+				s.CapabilitiesReason = "The template has an IAM resource: " + name
+			}
+		}
+	}
+
+	s.Resources = len(names)
+
+	for typeName, count := range counts {
+		s.ResourceTypes = append(s.ResourceTypes, ResourceType{Type: typeName, Count: count})
+	}
+
+	sort.Slice(s.ResourceTypes, func(i, j int) bool {
+		a, b := s.ResourceTypes[i], s.ResourceTypes[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Type < b.Type
+	})
+
+	if needsNamedIAM {
+		s.Capabilities = append(s.Capabilities, "CAPABILITY_NAMED_IAM")
+	} else if needsIAM {
+		s.Capabilities = append(s.Capabilities, "CAPABILITY_IAM")
+	}
+
+	if len(s.Transforms) > 0 {
+		s.Capabilities = append(s.Capabilities, "CAPABILITY_AUTO_EXPAND")
+		if s.CapabilitiesReason == "" {
+			s.CapabilitiesReason = "The template declares transforms"
+		}
+	}
+
+	names, _ = entries(t, cft.Outputs)
+	s.Outputs = len(names)
+
+	names, _ = entries(t, cft.Conditions)
+	s.Conditions = len(names)
+
+	return s
+}
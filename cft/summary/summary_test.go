@@ -0,0 +1,91 @@
+package summary_test
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/summary"
+	"github.com/google/go-cmp/cmp"
+)
+
+const source = `
+Description: |
+  An app
+Transform: AWS::Serverless-2016-10-31
+Parameters:
+  Name:
+    Type: String
+    Default: app
+    Description: The name of the app
+  Password:
+    Type: String
+    NoEcho: true
+  Subnets:
+    Type: CommaDelimitedList
+    Default: [a, b]
+Conditions:
+  IsProd: !Equals [!Ref Name, prod]
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+  LogBucket:
+    Type: AWS::S3::Bucket
+  Role:
+    Type: AWS::IAM::Role
+    Properties:
+      RoleName: !Sub ${Name}-role
+  Function:
+    Type: AWS::Serverless::Function
+Outputs:
+  BucketName:
+    Value: !Ref Bucket
+`
+
+func TestNew(t *testing.T) {
+	tmpl, err := parse.String(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := summary.Summary{
+		Description:        "An app",
+		Transforms:         []string{"AWS::Serverless-2016-10-31"},
+		Capabilities:       []string{"CAPABILITY_NAMED_IAM", "CAPABILITY_AUTO_EXPAND"},
+		CapabilitiesReason: "The template has an IAM resource with a custom name: Role",
+		Parameters: []summary.Parameter{
+			{Name: "Name", Type: "String", Default: "app", HasDefault: true, Description: "The name of the app"},
+			{Name: "Password", Type: "String", NoEcho: true},
+			{Name: "Subnets", Type: "CommaDelimitedList", Default: "a,b", HasDefault: true},
+		},
+		ResourceTypes: []summary.ResourceType{
+			{Type: "AWS::S3::Bucket", Count: 2},
+			{Type: "AWS::IAM::Role", Count: 1},
+			{Type: "AWS::Serverless::Function", Count: 1},
+		},
+		Resources:  4,
+		Outputs:    1,
+		Conditions: 1,
+	}
+
+	if d := cmp.Diff(expected, summary.New(tmpl)); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestTransforms(t *testing.T) {
+	tmpl, err := parse.String(`
+Transform:
+  - AWS::LanguageExtensions
+  - Name: AWS::Include
+    Parameters:
+      Location: s3://bucket/snippet.yaml
+Resources: {}
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := cmp.Diff([]string{"AWS::LanguageExtensions", "AWS::Include"}, summary.Transforms(tmpl)); d != "" {
+		t.Error(d)
+	}
+}
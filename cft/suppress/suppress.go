@@ -0,0 +1,160 @@
+// Package suppress reads the suppressions that a template declares in its Metadata,
+// which accept the findings of lint and scan rules.
+//
+// Suppressions in a resource's Metadata apply to the findings about that resource,
+// and suppressions in the template's Metadata apply to every finding in the template.
+// Each one names the rules it suppresses and must give a reason; it can also expire:
+//
+//	Metadata:
+//	  Rain::Suppressions:
+//	    - Rule: s3-logging
+//	      Reason: this bucket receives the other buckets' access logs
+//	    - Rule: [cidr, security-group-open-ingress]
+//	      Reason: the office VPN is being replaced
+//	      Expires: 2025-06-30
+//
+// Suppressions without a reason, with an expiry date that can't be read, or that have expired
+// don't suppress anything.
+package suppress
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// MetadataKey is the key in a resource's or the template's Metadata that lists its suppressions
+const MetadataKey = "Rain::Suppressions"
+
+// DateFormat is the format of expiry dates
+const DateFormat = "2006-01-02"
+
+// Suppression accepts the findings of some rules
+type Suppression struct {
+	// Rules are the names of the rules, or * for all of them
+	Rules []string `json:"rules"`
+
+	// Resource is the logical ID of the resource whose Metadata has the suppression,
+	// or empty if it is in the template's Metadata
+	Resource string `json:"resource,omitempty"`
+
+	Reason string `json:"reason,omitempty"`
+
+	// Expires is the last day that the suppression applies, if it has one
+	Expires string `json:"expires,omitempty"`
+
+	// Line is the line of the suppression in the template
+	Line int `json:"line"`
+
+	// Problem explains why the suppression doesn't apply, if it doesn't, at the time it was read
+	Problem string `json:"problem,omitempty"`
+}
+
+// Active returns true if the suppression applies
+func (s Suppression) Active() bool {
+	return s.Problem == ""
+}
+
+// Matches returns true if the suppression is active and accepts findings of a rule about a resource,
+// where the resource is empty for findings that aren't about a resource
+func (s Suppression) Matches(rule string, resource string) bool {
+	if !s.Active() || (s.Resource != "" && s.Resource != resource) {
+		return false
+	}
+
+	for _, r := range s.Rules {
+		if r == "*" || r == rule {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Template returns the suppressions in the template's Metadata and its resources' Metadata,
+// checking their expiry dates against now
+func Template(t cft.Template, now time.Time) []Suppression {
+	out := make([]Suppression, 0)
+
+	if metadata, err := t.GetSection(cft.Metadata); err == nil {
+		out = append(out, read(metadata, "", now)...)
+	}
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil || resources.Kind != yaml.MappingNode {
+		return out
+	}
+
+	for i := 0; i < len(resources.Content)-1; i += 2 {
+		if _, metadata, _ := s11n.GetMapValue(resources.Content[i+1], "Metadata"); metadata != nil {
+			out = append(out, read(metadata, resources.Content[i].Value, now)...)
+		}
+	}
+
+	return out
+}
+
+// read returns the suppressions in a Metadata node
+func read(metadata *yaml.Node, resource string, now time.Time) []Suppression {
+	out := make([]Suppression, 0)
+
+	_, list, _ := s11n.GetMapValue(metadata, MetadataKey)
+	if list == nil {
+		return out
+	}
+
+	if list.Kind != yaml.SequenceNode {
+		return append(out, Suppression{
+			Resource: resource,
+			Line:     list.Line,
+			Problem:  fmt.Sprintf("%s must be a list of suppressions", MetadataKey),
+		})
+	}
+
+	for _, item := range list.Content {
+		s := Suppression{
+			Rules:    make([]string, 0),
+			Resource: resource,
+			Line:     item.Line,
+		}
+
+		if _, rules, _ := s11n.GetMapValue(item, "Rule"); rules != nil {
+			if rules.Kind == yaml.ScalarNode {
+				s.Rules = append(s.Rules, rules.Value)
+			} else {
+				for _, r := range rules.Content {
+					s.Rules = append(s.Rules, r.Value)
+				}
+			}
+		}
+
+		if _, reason, _ := s11n.GetMapValue(item, "Reason"); reason != nil {
+			s.Reason = reason.Value
+		}
+
+		if _, expires, _ := s11n.GetMapValue(item, "Expires"); expires != nil {
+			s.Expires = expires.Value
+		}
+
+		switch {
+		case len(s.Rules) == 0:
+			s.Problem = "suppression has no Rule"
+		case s.Reason == "":
+			s.Problem = "suppression has no Reason; explain why the findings are accepted"
+		case s.Expires != "":
+			date, err := time.Parse(DateFormat, s.Expires)
+			if err != nil {
+				s.Problem = fmt.Sprintf("suppression has an invalid Expires date '%s'; use YYYY-MM-DD", s.Expires)
+			} else if !now.Before(date.AddDate(0, 0, 1)) {
+				s.Problem = fmt.Sprintf("suppression expired on %s", s.Expires)
+			}
+		}
+
+		out = append(out, s)
+	}
+
+	return out
+}
@@ -0,0 +1,67 @@
+package suppress_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/suppress"
+)
+
+func TestTemplate(t *testing.T) {
+	tmpl, err := parse.String(`
+Metadata:
+  Rain::Suppressions:
+    - Rule: export-names
+      Reason: exports are named by the platform team
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Metadata:
+      Rain::Suppressions:
+        - Rule: [s3-logging, s3-encryption]
+          Reason: a temporary bucket
+          Expires: 2025-06-30
+        - Rule: s3-public-access-block
+        - Rule: cidr
+          Reason: no date
+          Expires: soon
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+
+	actual := make([]string, 0)
+	for _, s := range suppress.Template(tmpl, now) {
+		actual = append(actual, fmt.Sprintf("%d %s %s %t %s", s.Line, s.Resource, strings.Join(s.Rules, ","), s.Active(), s.Problem))
+	}
+
+	expected := []string{
+		"4  export-names true ",
+		"11 Bucket s3-logging,s3-encryption true ",
+		"14 Bucket s3-public-access-block false suppression has no Reason; explain why the findings are accepted",
+		"15 Bucket cidr false suppression has an invalid Expires date 'soon'; use YYYY-MM-DD",
+	}
+
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+
+	// The suppression applies until the end of its expiry date
+	s := suppress.Template(tmpl, now.AddDate(0, 0, 1))[1]
+	if s.Active() || s.Problem != "suppression expired on 2025-06-30" {
+		t.Errorf("expected the suppression to have expired: %v", s)
+	}
+
+	all := suppress.Template(tmpl, now)
+	if !all[0].Matches("export-names", "Bucket") || !all[1].Matches("s3-encryption", "Bucket") {
+		t.Error("expected the suppressions to match")
+	}
+	if all[1].Matches("s3-encryption", "Other") || all[2].Matches("s3-public-access-block", "Bucket") {
+		t.Error("expected the suppressions not to match")
+	}
+}
@@ -12,11 +12,13 @@ var Tags = map[string]string{
 	"!If":            "Fn::If",
 	"!ImportValue":   "Fn::ImportValue",
 	"!Join":          "Fn::Join",
+	"!Length":        "Fn::Length",
 	"!Not":           "Fn::Not",
 	"!Or":            "Fn::Or",
 	"!Select":        "Fn::Select",
 	"!Split":         "Fn::Split",
 	"!Sub":           "Fn::Sub",
+	"!ToJsonString":  "Fn::ToJsonString",
 	"!Ref":           "Ref",
 	"!Condition":     "Condition",
 	"!Rain::Embed":   "Rain::Embed",
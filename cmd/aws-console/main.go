@@ -29,7 +29,7 @@ Unless you specify the --name/-n flag, your AWS console user name will be derive
 			service = args[0]
 		}
 
-		console.Open(printOnly, logout, service, "", userName)
+		console.Open(printOnly, logout, service, "", "", userName)
 	},
 }
 
@@ -31,13 +31,21 @@ require (
 require (
 	github.com/apple/pkl-go v0.8.0
 	github.com/aws/aws-sdk-go-v2/service/acm v1.28.5
+	github.com/aws/aws-sdk-go-v2/service/cloudtrail v1.42.4
 	github.com/aws/aws-sdk-go-v2/service/codeartifact v1.30.4
+	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.34.5
 	github.com/aws/aws-sdk-go-v2/service/kms v1.35.4
 	github.com/aws/aws-sdk-go-v2/service/lightsail v1.40.4
+	github.com/aws/aws-sdk-go-v2/service/organizations v1.31.0
+	github.com/aws/aws-sdk-go-v2/service/pricing v1.30.4
 	github.com/aws/aws-sdk-go-v2/service/rds v1.82.1
+	github.com/aws/aws-sdk-go-v2/service/route53 v1.42.4
 	github.com/aws/aws-sdk-go-v2/service/sagemaker v1.154.0
+	github.com/aws/aws-sdk-go-v2/service/secretsmanager v1.32.5
 	github.com/aws/aws-sdk-go-v2/service/servicequotas v1.23.4
+	github.com/aws/aws-sdk-go-v2/service/sns v1.31.4
 	github.com/aws/aws-sdk-go-v2/service/ssm v1.52.5
+	github.com/aws/aws-sdk-go-v2/service/ssooidc v1.26.5
 	github.com/fatih/color v1.17.0
 	github.com/manifoldco/promptui v0.9.0
 	github.com/mattn/go-runewidth v0.0.15
@@ -46,7 +54,7 @@ require (
 
 require (
 	github.com/aws/aws-sdk-go-v2/internal/v4a v1.3.16 // indirect
-	github.com/aws/aws-sdk-go-v2/service/ssooidc v1.26.5 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/endpoint-discovery v1.9.17 // indirect
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
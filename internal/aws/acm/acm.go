@@ -1,9 +1,9 @@
 package acm
 
 import (
-	"context"
 	rainaws "github.com/aws-cloudformation/rain/internal/aws"
 	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/service/acm"
 	"time"
 )
@@ -15,7 +15,7 @@ func getClient() *acm.Client {
 // CheckCertificate checks if the certificate exists and is valid
 func CheckCertificate(arn string) (bool, error) {
 	client := getClient()
-	_, err := client.GetCertificate(context.Background(), &acm.GetCertificateInput{
+	_, err := client.GetCertificate(interrupt.Context(), &acm.GetCertificateInput{
 		CertificateArn: &arn,
 	})
 
@@ -23,7 +23,7 @@ func CheckCertificate(arn string) (bool, error) {
 		return false, err
 	}
 
-	res, err := client.DescribeCertificate(context.Background(), &acm.DescribeCertificateInput{
+	res, err := client.DescribeCertificate(interrupt.Context(), &acm.DescribeCertificateInput{
 		CertificateArn: &arn,
 	})
 	if err != nil {
@@ -11,10 +11,12 @@ import (
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/aws/middleware"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	smithymiddleware "github.com/aws/smithy-go/middleware"
 )
 
@@ -73,7 +75,7 @@ func loadConfig(ctx context.Context, sessionName string) *aws.Config {
 		config.Region = r
 	}
 
-	cfg, err := awsconfig.LoadDefaultConfig(context.Background(), configs...)
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, configs...)
 	if err != nil {
 		panic(errors.New("unable to find valid credentials"))
 	}
@@ -82,8 +84,29 @@ func loadConfig(ctx context.Context, sessionName string) *aws.Config {
 		panic(errors.New("a region was not specified. You can run 'aws configure' or choose a profile with a region"))
 	}
 
+	configureRetries(&cfg)
+
+	profile, shared := sharedProfile(ctx)
+	if shared {
+		cacheSession(&cfg, profile, sessionName)
+	}
+
 	// Check for validity
-	creds, err = cfg.Credentials.Retrieve(context.Background())
+	creds, err = cfg.Credentials.Retrieve(ctx)
+
+	// Log in again if the profile's IAM Identity Center session has expired
+	if err != nil && shared {
+		if s, ok := ssoSession(&profile); ok {
+			config.Debugf("unable to get credentials with IAM Identity Center: %s", err)
+
+			if err := ssoLogin(ctx, s); err != nil {
+				panic(err)
+			}
+
+			creds, err = cfg.Credentials.Retrieve(ctx)
+		}
+	}
+
 	if err != nil {
 		config.Debugf("Error retreiving creds: %s", err.Error())
 		panic(errors.New("could not establish AWS credentials; please run 'aws configure' or choose a profile"))
@@ -113,13 +136,26 @@ func NamedConfig(sessionName string) aws.Config {
 
 	if awsCfg == nil {
 		spinner.Push(message)
-		awsCfg = loadConfig(context.Background(), sessionName)
+		awsCfg = loadConfig(interrupt.Context(), sessionName)
 		spinner.Pop()
 	}
 
 	return *awsCfg
 }
 
+// AssumeRole returns a copy of the current config that uses the credentials of an IAM role,
+// such as a role in another account. The role is assumed when the credentials are first used.
+func AssumeRole(roleArn string) aws.Config {
+	cfg := Config().Copy()
+
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleArn, func(options *stscreds.AssumeRoleOptions) {
+		options.RoleSessionName = defaultSessionName
+	})
+	cfg.Credentials = aws.NewCredentialsCache(provider)
+
+	return cfg
+}
+
 // SetRegion is used to set the current AWS region
 func SetRegion(region string) {
 	awsCfg.Region = region
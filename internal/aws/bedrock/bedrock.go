@@ -1,12 +1,12 @@
 package bedrock
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 
 	rainaws "github.com/aws-cloudformation/rain/internal/aws"
 	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 )
@@ -89,7 +89,7 @@ func Invoke(p string) (string, error) {
 	}
 
 	// Make the SDK call to the API
-	output, err := getClient().InvokeModel(context.Background(),
+	output, err := getClient().InvokeModel(interrupt.Context(),
 		&bedrockruntime.InvokeModelInput{
 			Body:        payloadBytes,
 			ModelId:     aws.String(claudeV2ModelID),
@@ -149,7 +149,7 @@ func InvokeClaude3(p string, model string, system string) (string, error) {
 	config.Debugf("About to invoke bedrock with Body: %s", string(payloadBytes))
 
 	// Make the SDK call to the API
-	output, err := getClient().InvokeModel(context.Background(),
+	output, err := getClient().InvokeModel(interrupt.Context(),
 		&bedrockruntime.InvokeModelInput{
 			Body:        payloadBytes,
 			ModelId:     aws.String(model),
@@ -1,7 +1,6 @@
 package ccapi
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"sort"
@@ -10,6 +9,7 @@ import (
 	"github.com/aws-cloudformation/rain/cft/format"
 	"github.com/aws-cloudformation/rain/internal/aws"
 	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws-cloudformation/rain/internal/node"
 	"github.com/aws-cloudformation/rain/internal/s11n"
 	"github.com/aws/aws-sdk-go-v2/service/cloudcontrol"
@@ -38,7 +38,7 @@ func ResourceExists(typeName string, identifier []string) bool {
 
 	config.Debugf("ResourceExists %v %v", typeName, id)
 
-	_, err := getClient().GetResource(context.Background(), &cloudcontrol.GetResourceInput{
+	_, err := getClient().GetResource(interrupt.Context(), &cloudcontrol.GetResourceInput{
 		Identifier: &id,
 		TypeName:   &typeName,
 	})
@@ -91,7 +91,7 @@ func CreateResource(logicalId string, resource *yaml.Node) (identifier string, m
 		DesiredState: &props,
 		TypeName:     &typeName,
 	}
-	output, err := getClient().CreateResource(context.Background(), &input)
+	output, err := getClient().CreateResource(interrupt.Context(), &input)
 
 	if err != nil {
 		return identifier, model, err
@@ -124,7 +124,7 @@ func GetResource(identifier string, typeName string) (string, error) {
 		TypeName:   &typeName,
 	}
 
-	result, err := getClient().GetResource(context.Background(), input)
+	result, err := getClient().GetResource(interrupt.Context(), input)
 
 	if err != nil {
 		return "", err
@@ -176,7 +176,7 @@ func pollForCompletion(progress *types.ProgressEvent) (string, string, error) {
 		}
 
 		if !done {
-			status, statusErr := getClient().GetResourceRequestStatus(context.Background(),
+			status, statusErr := getClient().GetResourceRequestStatus(interrupt.Context(),
 				&cloudcontrol.GetResourceRequestStatusInput{
 					RequestToken: progress.RequestToken,
 				})
@@ -280,7 +280,7 @@ func UpdateResource(
 		TypeName:      &typeName,
 		Identifier:    &identifier,
 	}
-	output, err := getClient().UpdateResource(context.Background(), &input)
+	output, err := getClient().UpdateResource(interrupt.Context(), &input)
 	if err != nil {
 		return model, err
 	}
@@ -328,7 +328,7 @@ func DeleteResource(logicalId string, identifier string, resource *yaml.Node) er
 		TypeName:    &typeName,
 		Identifier:  &identifier,
 	}
-	output, err := getClient().DeleteResource(context.Background(), &input)
+	output, err := getClient().DeleteResource(interrupt.Context(), &input)
 
 	if err != nil {
 		return err
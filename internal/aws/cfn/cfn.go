@@ -16,11 +16,14 @@ import (
 	"github.com/aws-cloudformation/rain/cft/format"
 	"github.com/aws-cloudformation/rain/internal/aws"
 	"github.com/aws-cloudformation/rain/internal/aws/ccapi"
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
 	"github.com/aws-cloudformation/rain/internal/aws/s3"
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws-cloudformation/rain/internal/s11n"
 	"github.com/aws-cloudformation/rain/plugins/deployconfig"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/aws/smithy-go/ptr"
@@ -75,11 +78,7 @@ func checkTemplate(template cft.Template) (string, error) {
 
 		key, err := s3.Upload(bucket, []byte(templateBody))
 		region := aws.Config().Region
-		if strings.HasPrefix(region, "cn-") {
-			return fmt.Sprintf("https://%s.s3.%s.amazonaws.com.cn/%s", bucket, region, key), err
-		} else {
-			return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", bucket, key), err
-		}
+		return fmt.Sprintf("https://%s.s3.%s.%s/%s", bucket, region, partition.ForRegion(region).URLSuffix, key), err
 	}
 
 	return templateBody, nil
@@ -96,7 +95,7 @@ func GetStackTemplate(stackName string, processed bool) (string, error) {
 		templateStage = "Processed"
 	}
 
-	res, err := getClient().GetTemplate(context.Background(), &cloudformation.GetTemplateInput{
+	res, err := getClient().GetTemplate(interrupt.Context(), &cloudformation.GetTemplateInput{
 		StackName:     &stackName,
 		TemplateStage: types.TemplateStage(templateStage),
 	})
@@ -107,9 +106,37 @@ func GetStackTemplate(stackName string, processed bool) (string, error) {
 	return *res.TemplateBody, nil
 }
 
+// GetTemplateSummary returns CloudFormation's summary of a template
+func GetTemplateSummary(template cft.Template) (*cloudformation.GetTemplateSummaryOutput, error) {
+	templateBody, err := checkTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &cloudformation.GetTemplateSummaryInput{}
+	if strings.HasPrefix(templateBody, "http") {
+		input.TemplateURL = ptr.String(templateBody)
+	} else {
+		input.TemplateBody = ptr.String(templateBody)
+	}
+
+	return getClient().GetTemplateSummary(interrupt.Context(), input)
+}
+
+// GetStackTemplateSummary returns CloudFormation's summary of the template of the named stack
+func GetStackTemplateSummary(stackName string) (*cloudformation.GetTemplateSummaryOutput, error) {
+	return getClient().GetTemplateSummary(interrupt.Context(), &cloudformation.GetTemplateSummaryInput{
+		StackName: ptr.String(stackName),
+	})
+}
+
 // StackExists checks whether the named stack currently exists
 func StackExists(stackName string) (bool, error) {
-	stacks, err := ListStacks()
+	return stackExists(interrupt.Context(), stackName)
+}
+
+func stackExists(ctx context.Context, stackName string) (bool, error) {
+	stacks, err := listStacks(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -128,7 +155,7 @@ func ListChangeSets(stackName string) ([]types.ChangeSetSummary, error) {
 	var token *string
 	retval := make([]types.ChangeSetSummary, 0)
 	for {
-		res, err := getClient().ListChangeSets(context.Background(), &cloudformation.ListChangeSetsInput{
+		res, err := getClient().ListChangeSets(interrupt.Context(), &cloudformation.ListChangeSetsInput{
 			StackName: &stackName,
 			NextToken: token,
 		})
@@ -152,12 +179,16 @@ func ListChangeSets(stackName string) ([]types.ChangeSetSummary, error) {
 
 // ListStacks returns a list of all existing stacks
 func ListStacks() ([]types.StackSummary, error) {
+	return listStacks(interrupt.Context())
+}
+
+func listStacks(ctx context.Context) ([]types.StackSummary, error) {
 	stacks := make([]types.StackSummary, 0)
 
 	var token *string
 
 	for {
-		res, err := getClient().ListStacks(context.Background(), &cloudformation.ListStacksInput{
+		res, err := getClient().ListStacks(ctx, &cloudformation.ListStacksInput{
 			NextToken:         token,
 			StackStatusFilter: liveStatuses,
 		})
@@ -178,18 +209,109 @@ func ListStacks() ([]types.StackSummary, error) {
 	return stacks, nil
 }
 
-// DeleteStack deletes a stack
-func DeleteStack(stackName string, roleArn string) error {
-	input := &cloudformation.DeleteStackInput{
-		StackName: &stackName,
+// DescribeStacks returns the details of every stack in a region, including their tags and drift status.
+// Unlike the rest of this package, it does not use the current region,
+// so that several regions can be listed at the same time.
+func DescribeStacks(region string) ([]types.Stack, error) {
+	return DescribeStacksWithConfig(aws.Config(), region)
+}
+
+// DescribeStacksWithConfig is DescribeStacks with credentials from cfg,
+// such as a config from aws.AssumeRole for another account
+func DescribeStacksWithConfig(cfg awssdk.Config, region string) ([]types.Stack, error) {
+	client := cloudformation.NewFromConfig(cfg, func(o *cloudformation.Options) {
+		o.Region = region
+	})
+
+	stacks := make([]types.Stack, 0)
+
+	var token *string
+
+	for {
+		res, err := client.DescribeStacks(interrupt.Context(), &cloudformation.DescribeStacksInput{
+			NextToken: token,
+		})
+
+		if err != nil {
+			return stacks, err
+		}
+
+		stacks = append(stacks, res.Stacks...)
+
+		if res.NextToken == nil {
+			break
+		}
+
+		token = res.NextToken
+	}
+
+	return stacks, nil
+}
+
+// ListExports returns a list of all exported output values in the region
+func ListExports() ([]types.Export, error) {
+	exports := make([]types.Export, 0)
+
+	var token *string
+
+	for {
+		res, err := getClient().ListExports(interrupt.Context(), &cloudformation.ListExportsInput{
+			NextToken: token,
+		})
+
+		if err != nil {
+			return exports, err
+		}
+
+		exports = append(exports, res.Exports...)
+
+		if res.NextToken == nil {
+			break
+		}
+
+		token = res.NextToken
 	}
 
-	// roleArn is optional
-	if roleArn != "" {
-		input.RoleARN = ptr.String(roleArn)
+	return exports, nil
+}
+
+// ListImports returns the names of the stacks that import the named export
+func ListImports(exportName string) ([]string, error) {
+	stacks := make([]string, 0)
+
+	var token *string
+
+	for {
+		res, err := getClient().ListImports(interrupt.Context(), &cloudformation.ListImportsInput{
+			ExportName: &exportName,
+			NextToken:  token,
+		})
+
+		if err != nil {
+			// CloudFormation returns an error rather than an empty list
+			if strings.Contains(err.Error(), "is not imported by any stack") {
+				return stacks, nil
+			}
+			return stacks, err
+		}
+
+		stacks = append(stacks, res.Imports...)
+
+		if res.NextToken == nil {
+			break
+		}
+
+		token = res.NextToken
 	}
 
-	_, err := getClient().DeleteStack(context.Background(), input)
+	return stacks, nil
+}
+
+// DeleteStack deletes a stack, leaving retainResources in place if supplied
+func DeleteStack(stackName string, roleArn string, retainResources ...string) error {
+	err := Region("").DeleteStack(stackName, roleArn, retainResources...)
+
+	InvalidateStackOutputs(stackName)
 
 	return err
 }
@@ -197,7 +319,7 @@ func DeleteStack(stackName string, roleArn string) error {
 // SetTerminationProtection enables or disables termination protection for a stack
 func SetTerminationProtection(stackName string, protectionEnabled bool) error {
 	// Set termination protection
-	_, err := getClient().UpdateTerminationProtection(context.Background(), &cloudformation.UpdateTerminationProtectionInput{
+	_, err := getClient().UpdateTerminationProtection(interrupt.Context(), &cloudformation.UpdateTerminationProtectionInput{
 		StackName:                   &stackName,
 		EnableTerminationProtection: ptr.Bool(protectionEnabled),
 	})
@@ -205,22 +327,41 @@ func SetTerminationProtection(stackName string, protectionEnabled bool) error {
 	return err
 }
 
-// GetStack returns a cloudformation.Stack representing the named stack
-func GetStack(stackName string) (types.Stack, error) {
-	// Get the stack properties
-	res, err := getClient().DescribeStacks(context.Background(), &cloudformation.DescribeStacksInput{
-		StackName: &stackName,
+// SetStackPolicy replaces the stack's policy with the JSON policy document
+func SetStackPolicy(stackName string, policy string) error {
+	_, err := getClient().SetStackPolicy(interrupt.Context(), &cloudformation.SetStackPolicyInput{
+		StackName:       ptr.String(stackName),
+		StackPolicyBody: ptr.String(policy),
+	})
+
+	return err
+}
+
+// GetStackPolicy returns the stack's policy document, or "" if it has none
+func GetStackPolicy(stackName string) (string, error) {
+	res, err := getClient().GetStackPolicy(interrupt.Context(), &cloudformation.GetStackPolicyInput{
+		StackName: ptr.String(stackName),
 	})
 	if err != nil {
-		return types.Stack{}, err
+		return "", err
 	}
 
-	return res.Stacks[0], nil
+	return ptr.ToString(res.StackPolicyBody), nil
+}
+
+// CancelUpdateStack cancels an update that is in progress and rolls the stack back
+func CancelUpdateStack(stackName string) error {
+	return Region("").CancelUpdateStack(stackName)
+}
+
+// GetStack returns a cloudformation.Stack representing the named stack
+func GetStack(stackName string) (types.Stack, error) {
+	return Region("").GetStack(stackName)
 }
 
 // GetStackResource gets a single deployed stack resource
 func GetStackResource(stackName string, logicalId string) (*types.StackResourceDetail, error) {
-	res, err := getClient().DescribeStackResource(context.Background(),
+	res, err := getClient().DescribeStackResource(interrupt.Context(),
 		&cloudformation.DescribeStackResourceInput{
 			StackName:         &stackName,
 			LogicalResourceId: &logicalId,
@@ -234,7 +375,7 @@ func GetStackResource(stackName string, logicalId string) (*types.StackResourceD
 // GetStackResources returns a list of the resources in the named stack
 func GetStackResources(stackName string) ([]types.StackResource, error) {
 	// Get the stack resources
-	res, err := getClient().DescribeStackResources(context.Background(), &cloudformation.DescribeStackResourcesInput{
+	res, err := getClient().DescribeStackResources(interrupt.Context(), &cloudformation.DescribeStackResourcesInput{
 		StackName: &stackName,
 	})
 	if err != nil {
@@ -251,7 +392,7 @@ func GetStackEvents(stackName string) ([]types.StackEvent, error) {
 	var token *string
 
 	for {
-		res, err := getClient().DescribeStackEvents(context.Background(), &cloudformation.DescribeStackEventsInput{
+		res, err := getClient().DescribeStackEvents(interrupt.Context(), &cloudformation.DescribeStackEventsInput{
 			NextToken: token,
 			StackName: &stackName,
 		})
@@ -272,6 +413,36 @@ func GetStackEvents(stackName string) ([]types.StackEvent, error) {
 	return events, nil
 }
 
+// ChangeSetOptions are the optional settings for a change set
+type ChangeSetOptions struct {
+	// RoleArn is the IAM role that CloudFormation assumes to deploy the stack
+	RoleArn string
+
+	// Capabilities are acknowledged by the change set.
+	// If they are not set, CAPABILITY_NAMED_IAM and CAPABILITY_AUTO_EXPAND are acknowledged.
+	Capabilities []string
+
+	// NotificationArns are the SNS topics that the stack's events are sent to
+	NotificationArns []string
+}
+
+// capabilities returns the capabilities that the change set acknowledges
+func (o ChangeSetOptions) capabilities() []types.Capability {
+	if len(o.Capabilities) == 0 {
+		return []types.Capability{
+			"CAPABILITY_NAMED_IAM",
+			"CAPABILITY_AUTO_EXPAND",
+		}
+	}
+
+	capabilities := make([]types.Capability, len(o.Capabilities))
+	for i, c := range o.Capabilities {
+		capabilities[i] = types.Capability(c)
+	}
+
+	return capabilities
+}
+
 // CreateChangeSet creates a changeset
 //
 // changeSetName is optional, if "" is passed in, the name will be the stack name plus a timestamp
@@ -281,7 +452,20 @@ func CreateChangeSet(
 	tags map[string]string,
 	stackName string,
 	changeSetName string,
-	roleArn string) (string, error) {
+	opts ChangeSetOptions) (string, error) {
+
+	return CreateChangeSetContext(interrupt.Context(), template, params, tags, stackName, changeSetName, opts)
+}
+
+// CreateChangeSetContext is CreateChangeSet for callers that have their own context
+func CreateChangeSetContext(
+	ctx context.Context,
+	template cft.Template,
+	params []types.Parameter,
+	tags map[string]string,
+	stackName string,
+	changeSetName string,
+	opts ChangeSetOptions) (string, error) {
 
 	templateBody, err := checkTemplate(template)
 	if err != nil {
@@ -290,7 +474,7 @@ func CreateChangeSet(
 
 	changeSetType := "CREATE"
 
-	exists, err := StackExists(stackName)
+	exists, err := stackExists(ctx, stackName)
 	if err != nil {
 		return "", err
 	}
@@ -310,14 +494,12 @@ func CreateChangeSet(
 		Tags:                dc.MakeTags(tags),
 		IncludeNestedStacks: ptr.Bool(true),
 		Parameters:          params,
-		Capabilities: []types.Capability{
-			"CAPABILITY_NAMED_IAM",
-			"CAPABILITY_AUTO_EXPAND",
-		},
+		Capabilities:        opts.capabilities(),
+		NotificationARNs:    opts.NotificationArns,
 	}
 
-	if roleArn != "" {
-		input.RoleARN = ptr.String(roleArn)
+	if opts.RoleArn != "" {
+		input.RoleARN = ptr.String(opts.RoleArn)
 	}
 
 	if strings.HasPrefix(templateBody, "http") {
@@ -337,35 +519,110 @@ func CreateChangeSet(
 		}
 	}
 
-	_, err = getClient().CreateChangeSet(context.Background(), input)
+	_, err = getClient().CreateChangeSet(ctx, input)
 	if err != nil {
 		return changeSetName, err
 	}
 
+	return changeSetName, waitForChangeSet(ctx, stackName, changeSetName)
+}
+
+// ChangeSetHasNoChanges returns true if err is the error that CreateChangeSet returns
+// when the change set would not change the stack
+func ChangeSetHasNoChanges(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	// mesages returned as error when the change set is empty
+	noChangeFoundMsg := []string{
+		"The submitted information didn't contain changes. Submit different information to create a change set.",
+		"No updates are to be performed.",
+	}
+	for _, m := range noChangeFoundMsg {
+		if m == err.Error() {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForChangeSet waits until the change set has been created
+func waitForChangeSet(ctx context.Context, stackName, changeSetName string) error {
 	for {
-		res, err := getClient().DescribeChangeSet(context.Background(), &cloudformation.DescribeChangeSetInput{
+		res, err := getClient().DescribeChangeSet(ctx, &cloudformation.DescribeChangeSetInput{
 			ChangeSetName: &changeSetName,
 			StackName:     &stackName,
 		})
 		if err != nil {
-			return changeSetName, err
+			return err
 		}
 
 		status := string(res.Status)
 		config.Debugf("ChangeSet status: %s", status)
 
 		if status == "FAILED" {
-			return changeSetName, errors.New(ptr.ToString(res.StatusReason))
+			return errors.New(ptr.ToString(res.StatusReason))
 		}
 
 		if strings.HasSuffix(status, "_COMPLETE") {
-			break
+			return nil
 		}
 
-		time.Sleep(time.Second * WaitPeriodInSeconds)
+		if err := interrupt.SleepContext(ctx, time.Second*WaitPeriodInSeconds); err != nil {
+			return err
+		}
 	}
+}
 
-	return changeSetName, nil
+// CreateImportChangeSet creates a change set that imports existing resources into the stack.
+// Every other resource in the template must already be in the stack, unchanged.
+// The parameters of an existing stack keep their previous values.
+func CreateImportChangeSet(
+	template cft.Template,
+	stackName string,
+	imports []types.ResourceToImport) (string, error) {
+
+	templateBody, err := checkTemplate(template)
+	if err != nil {
+		return "", err
+	}
+
+	changeSetName := stackName + "-import-" + fmt.Sprint(time.Now().Unix())
+
+	input := &cloudformation.CreateChangeSetInput{
+		ChangeSetType:     types.ChangeSetTypeImport,
+		ChangeSetName:     ptr.String(changeSetName),
+		StackName:         ptr.String(stackName),
+		ResourcesToImport: imports,
+		Capabilities: []types.Capability{
+			"CAPABILITY_NAMED_IAM",
+			"CAPABILITY_AUTO_EXPAND",
+		},
+	}
+
+	if strings.HasPrefix(templateBody, "http") {
+		input.TemplateURL = ptr.String(templateBody)
+	} else {
+		input.TemplateBody = ptr.String(templateBody)
+	}
+
+	stack, err := GetStack(stackName)
+	if err == nil {
+		for _, p := range stack.Parameters {
+			input.Parameters = append(input.Parameters, types.Parameter{
+				ParameterKey:     p.ParameterKey,
+				UsePreviousValue: ptr.Bool(true),
+			})
+		}
+	}
+
+	_, err = getClient().CreateChangeSet(interrupt.Context(), input)
+	if err != nil {
+		return changeSetName, err
+	}
+
+	return changeSetName, waitForChangeSet(interrupt.Context(), stackName, changeSetName)
 }
 
 // GetChangeSet returns the named changeset
@@ -380,23 +637,78 @@ func GetChangeSet(stackName, changeSetName string) (*cloudformation.DescribeChan
 		input.StackName = ptr.String(stackName)
 	}
 
-	return getClient().DescribeChangeSet(context.Background(), input)
+	return getClient().DescribeChangeSet(interrupt.Context(), input)
+}
+
+// GetFullChangeSet returns the named changeset with all of its changes,
+// following NextToken for change sets with more changes than fit in one response
+func GetFullChangeSet(stackName, changeSetName string) (*cloudformation.DescribeChangeSetOutput, error) {
+	return GetFullChangeSetContext(interrupt.Context(), stackName, changeSetName)
+}
+
+// GetFullChangeSetContext is GetFullChangeSet for callers that have their own context
+func GetFullChangeSetContext(ctx context.Context, stackName, changeSetName string) (*cloudformation.DescribeChangeSetOutput, error) {
+	input := &cloudformation.DescribeChangeSetInput{
+		ChangeSetName:         ptr.String(changeSetName),
+		IncludePropertyValues: ptr.Bool(true),
+	}
+
+	if stackName != "" {
+		input.StackName = ptr.String(stackName)
+	}
+
+	var full *cloudformation.DescribeChangeSetOutput
+	for {
+		res, err := getClient().DescribeChangeSet(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		if full == nil {
+			full = res
+		} else {
+			full.Changes = append(full.Changes, res.Changes...)
+		}
+
+		if res.NextToken == nil {
+			break
+		}
+
+		input.NextToken = res.NextToken
+	}
+
+	full.NextToken = nil
+
+	return full, nil
 }
 
 // ExecuteChangeSet executes the named changeset
 func ExecuteChangeSet(stackName, changeSetName string, disableRollback bool) error {
-	_, err := getClient().ExecuteChangeSet(context.Background(), &cloudformation.ExecuteChangeSetInput{
+	return ExecuteChangeSetContext(interrupt.Context(), stackName, changeSetName, disableRollback)
+}
+
+// ExecuteChangeSetContext is ExecuteChangeSet for callers that have their own context
+func ExecuteChangeSetContext(ctx context.Context, stackName, changeSetName string, disableRollback bool) error {
+	_, err := getClient().ExecuteChangeSet(ctx, &cloudformation.ExecuteChangeSetInput{
 		ChangeSetName:   &changeSetName,
 		StackName:       &stackName,
 		DisableRollback: &disableRollback,
 	})
 
+	// The outputs will change once the change set has been executed
+	InvalidateStackOutputs(stackName)
+
 	return err
 }
 
 // DeleteChangeSet deletes the named changeset
 func DeleteChangeSet(stackName, changeSetName string) error {
-	_, err := getClient().DeleteChangeSet(context.Background(), &cloudformation.DeleteChangeSetInput{
+	return DeleteChangeSetContext(interrupt.Context(), stackName, changeSetName)
+}
+
+// DeleteChangeSetContext is DeleteChangeSet for callers that have their own context
+func DeleteChangeSetContext(ctx context.Context, stackName, changeSetName string) error {
+	_, err := getClient().DeleteChangeSet(ctx, &cloudformation.DeleteChangeSetInput{
 		ChangeSetName: &changeSetName,
 		StackName:     &stackName,
 	})
@@ -407,7 +719,7 @@ func DeleteChangeSet(stackName, changeSetName string) error {
 // WaitUntilStackExists pauses execution until the named stack exists
 func WaitUntilStackExists(stackName string) error {
 	for {
-		_, err := getClient().DescribeStacks(context.Background(), &cloudformation.DescribeStacksInput{
+		_, err := getClient().DescribeStacks(interrupt.Context(), &cloudformation.DescribeStacksInput{
 			StackName: ptr.String(stackName),
 		})
 
@@ -420,7 +732,9 @@ func WaitUntilStackExists(stackName string) error {
 			return err
 		}
 
-		time.Sleep(time.Second * WaitPeriodInSeconds)
+		if err := interrupt.Sleep(time.Second * WaitPeriodInSeconds); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -429,7 +743,7 @@ func WaitUntilStackExists(stackName string) error {
 // WaitUntilStackCreateComplete pauses execution until the stack is completed (or fails)
 func WaitUntilStackCreateComplete(stackName string) error {
 	for {
-		res, err := getClient().DescribeStacks(context.Background(), &cloudformation.DescribeStacksInput{
+		res, err := getClient().DescribeStacks(interrupt.Context(), &cloudformation.DescribeStacksInput{
 			StackName: ptr.String(stackName),
 		})
 
@@ -448,13 +762,21 @@ func WaitUntilStackCreateComplete(stackName string) error {
 			break
 		}
 
-		time.Sleep(time.Second * WaitPeriodInSeconds)
+		if err := interrupt.Sleep(time.Second * WaitPeriodInSeconds); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
-// GetTypeSchema gets the schema for a CloudFormation resource type
+// GetTypeSchema gets the schema for a CloudFormation resource type.
+// Schemas are read from memory, the schema cache, or the schemas embedded in rain,
+// before downloading them from the registry. If noCache is set, the schema is always downloaded,
+// and the cache is updated.
+//
+// name can be the name of a public or private type, or the ARN of a type in the registry,
+// which can include the version, e.g. arn:aws:cloudformation:us-east-1:123456789012:type/resource/MyOrg-Networking-VPC/00000003
 func GetTypeSchema(name string, noCache bool) (string, error) {
 
 	// Check for a schema in memory
@@ -463,8 +785,16 @@ func GetTypeSchema(name string, noCache bool) (string, error) {
 		return schema, nil
 	}
 
-	// Look in the embedded file system next
+	// Then in the schema cache
 	if !noCache {
+		if s, ok := readSchemaCache(schemaCacheName(name)); ok {
+			Schemas[name] = s
+			return s, nil
+		}
+	}
+
+	// Look in the embedded file system next
+	if !noCache && !IsTypeArn(name) {
 		path := strings.Replace(name, "::", "/", -1)
 		path = strings.ToLower(path)
 		path = "schemas/" + path + ".json"
@@ -478,21 +808,34 @@ func GetTypeSchema(name string, noCache bool) (string, error) {
 		}
 	}
 
+	if config.Offline {
+		return "", fmt.Errorf("no cached schema for %s: %w", name, ErrOffline)
+	}
+
 	// Go ahead and download the schema from the registry
-	res, err := getClient().DescribeType(context.Background(), &cloudformation.DescribeTypeInput{
+	input := &cloudformation.DescribeTypeInput{
 		Type: "RESOURCE", TypeName: &name,
-	})
+	}
+	if IsTypeArn(name) {
+		input = &cloudformation.DescribeTypeInput{Arn: &name}
+	}
+
+	res, err := getClient().DescribeType(interrupt.Context(), input)
 	if err != nil {
 		config.Debugf("GetTypeSchema SDK error: %v", err)
 		return "", err
 	}
+	if res.Schema == nil {
+		return "", fmt.Errorf("the registry has no schema for %s", name)
+	}
 	Schemas[name] = *res.Schema
+	writeSchemaCache(schemaCacheName(name), *res.Schema)
 	return *res.Schema, nil
 }
 
 // IsCCAPI returns true if the type is fully supported by CCAPI
 func IsCCAPI(name string) (bool, error) {
-	res, err := getClient().DescribeType(context.Background(), &cloudformation.DescribeTypeInput{
+	res, err := getClient().DescribeType(interrupt.Context(), &cloudformation.DescribeTypeInput{
 		Type: "RESOURCE", TypeName: &name,
 	})
 	if err != nil {
@@ -850,9 +1193,16 @@ func ResourceAlreadyExists(
 func ListResourceTypes(noCache bool) ([]string, error) {
 
 	if !noCache {
+		if types, ok := readSchemaCache(typesFile); ok {
+			return strings.Split(types, "\n"), nil
+		}
 		return strings.Split(AllTypes, "\n"), nil
 	}
 
+	if config.Offline {
+		return nil, ErrOffline
+	}
+
 	input := &cloudformation.ListTypesInput{
 		DeprecatedStatus: types.DeprecatedStatusLive,
 		Type:             types.RegistryTypeResource,
@@ -865,7 +1215,7 @@ func ListResourceTypes(noCache bool) ([]string, error) {
 		hasMore := true
 		for hasMore {
 			input.Visibility = v
-			res, err := getClient().ListTypes(context.Background(), input)
+			res, err := getClient().ListTypes(interrupt.Context(), input)
 			if err != nil {
 				return retval, err
 			}
@@ -884,6 +1234,8 @@ func ListResourceTypes(noCache bool) ([]string, error) {
 		}
 	}
 
+	writeSchemaCache(typesFile, strings.Join(retval, "\n"))
+
 	return retval, nil
 
 }
@@ -1,5 +1,7 @@
 package cfn
 
+import "strings"
+
 // uniqueStrings returns a unique subset of the string slice provided.
 func UniqueStrings(input []string) []string {
 	u := make([]string, 0, len(input))
@@ -14,3 +16,20 @@ func UniqueStrings(input []string) []string {
 
 	return u
 }
+
+// StackNameFromId returns the stack name from a stack id of the form
+// arn:aws:cloudformation:<region>:<account>:stack/<name>/<uuid>.
+// Anything that is not a stack id is returned unchanged.
+func StackNameFromId(stackId string) string {
+	i := strings.Index(stackId, ":stack/")
+	if i < 0 {
+		return stackId
+	}
+
+	name := stackId[i+len(":stack/"):]
+	if j := strings.Index(name, "/"); j >= 0 {
+		name = name[:j]
+	}
+
+	return name
+}
@@ -14,3 +14,16 @@ func TestUniqueStrings(t *testing.T) {
 		t.Error()
 	}
 }
+
+func TestStackNameFromId(t *testing.T) {
+	cases := map[string]string{
+		"arn:aws:cloudformation:us-east-1:123456789012:stack/my-stack/c3a9a6b0-1234": "my-stack",
+		"my-stack": "my-stack",
+	}
+
+	for in, expected := range cases {
+		if actual := cfn.StackNameFromId(in); actual != expected {
+			t.Errorf("%s: expected '%s', got '%s'", in, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package cfn
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// DetectStackDrift starts drift detection on the stack and waits for it to finish.
+// It returns the stack's drift status.
+func DetectStackDrift(stackName string) (types.StackDriftStatus, error) {
+	res, err := getClient().DetectStackDrift(interrupt.Context(), &cloudformation.DetectStackDriftInput{
+		StackName: &stackName,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		status, err := getClient().DescribeStackDriftDetectionStatus(interrupt.Context(),
+			&cloudformation.DescribeStackDriftDetectionStatusInput{
+				StackDriftDetectionId: res.StackDriftDetectionId,
+			})
+		if err != nil {
+			return "", err
+		}
+
+		switch status.DetectionStatus {
+		case types.StackDriftDetectionStatusDetectionComplete:
+			return status.StackDriftStatus, nil
+		case types.StackDriftDetectionStatusDetectionFailed:
+			// Detection fails if some resources don't support it,
+			// but the resources that do support it still have results
+			if status.StackDriftStatus != "" {
+				return status.StackDriftStatus, nil
+			}
+			return "", fmt.Errorf("drift detection failed: %s", ptr.ToString(status.DetectionStatusReason))
+		}
+
+		if err := interrupt.Sleep(time.Second * WaitPeriodInSeconds); err != nil {
+			return "", err
+		}
+	}
+}
+
+// GetStackResourceDrifts returns the results of the most recent drift detection
+// for each resource in the stack
+func GetStackResourceDrifts(stackName string) ([]types.StackResourceDrift, error) {
+	drifts := make([]types.StackResourceDrift, 0)
+	var token *string
+
+	for {
+		res, err := getClient().DescribeStackResourceDrifts(interrupt.Context(),
+			&cloudformation.DescribeStackResourceDriftsInput{
+				StackName: &stackName,
+				NextToken: token,
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		drifts = append(drifts, res.StackResourceDrifts...)
+
+		if res.NextToken == nil {
+			return drifts, nil
+		}
+
+		token = res.NextToken
+	}
+}
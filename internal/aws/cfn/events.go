@@ -0,0 +1,207 @@
+package cfn
+
+import (
+	"context"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// EventPollInterval is how often StreamStackEvents calls DescribeStackEvents
+var EventPollInterval = time.Second * WaitPeriodInSeconds
+
+// maxEventPollInterval is the longest StreamStackEvents will back off
+// for when DescribeStackEvents fails, e.g. because it is being throttled
+const maxEventPollInterval = 30 * time.Second
+
+// eventSource is a stack, or nested stack, whose events are being streamed
+type eventSource struct {
+	stackId string
+
+	// since is the time the stack's part of the operation started;
+	// older events are ignored
+	since time.Time
+
+	seen map[string]bool
+
+	// settled is true once a nested stack has finished its part of the operation
+	settled bool
+}
+
+// newEvents returns the events, which are newest first as returned by DescribeStackEvents,
+// that have not been seen and are not older than since, oldest first.
+// It also returns false if the remaining pages of events can be skipped.
+func (s *eventSource) newEvents(events []types.StackEvent) ([]types.StackEvent, bool) {
+	out := make([]types.StackEvent, 0)
+
+	for _, e := range events {
+		id := ptr.ToString(e.EventId)
+		if s.seen[id] || ptr.ToTime(e.Timestamp).Before(s.since) {
+			reverse(out)
+			return out, false
+		}
+
+		s.seen[id] = true
+		out = append(out, e)
+	}
+
+	reverse(out)
+	return out, true
+}
+
+func reverse(events []types.StackEvent) {
+	for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+		events[i], events[j] = events[j], events[i]
+	}
+}
+
+// poll returns the stack's events that have happened since it was last polled, oldest first
+func (s *eventSource) poll(ctx context.Context) ([]types.StackEvent, error) {
+	pages := make([][]types.StackEvent, 0)
+
+	var token *string
+	for {
+		res, err := getClient().DescribeStackEvents(ctx, &cloudformation.DescribeStackEventsInput{
+			StackName: &s.stackId,
+			NextToken: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		events, more := s.newEvents(res.StackEvents)
+		pages = append(pages, events)
+
+		if !more || res.NextToken == nil {
+			break
+		}
+
+		token = res.NextToken
+	}
+
+	// Later pages hold older events
+	out := make([]types.StackEvent, 0)
+	for i := len(pages) - 1; i >= 0; i-- {
+		out = append(out, pages[i]...)
+	}
+
+	return out, nil
+}
+
+// isStackEvent returns true if the event is about the stack itself rather than one of its resources
+func isStackEvent(e types.StackEvent) bool {
+	return ptr.ToString(e.PhysicalResourceId) == ptr.ToString(e.StackId)
+}
+
+// isNestedStackEvent returns true if the event is about a nested stack resource
+func isNestedStackEvent(e types.StackEvent) bool {
+	return !isStackEvent(e) &&
+		ptr.ToString(e.ResourceType) == "AWS::CloudFormation::Stack" &&
+		ptr.ToString(e.PhysicalResourceId) != ""
+}
+
+// StreamStackEvents sends the events of the named stack, and of its nested stacks,
+// that happen after it is called to the returned channel, oldest first.
+// The channel is closed once the stack settles or ctx is cancelled.
+//
+// DescribeStackEvents is called at most once per stack every EventPollInterval,
+// and less often if CloudFormation is throttling requests.
+func StreamStackEvents(ctx context.Context, stackName string) (<-chan types.StackEvent, error) {
+	stack, err := GetStack(stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &eventSource{
+		stackId: ptr.ToString(stack.StackId),
+		seen:    make(map[string]bool),
+	}
+
+	// Everything that has already happened is not part of the stream
+	if _, err := root.poll(ctx); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan types.StackEvent, 100)
+
+	go func() {
+		defer close(ch)
+
+		sources := []*eventSource{root}
+		byId := map[string]*eventSource{root.stackId: root}
+		interval := EventPollInterval
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+			}
+
+			failed := false
+			done := false
+
+			for i := 0; i < len(sources); i++ {
+				source := sources[i]
+				if source.settled {
+					continue
+				}
+
+				events, err := source.poll(ctx)
+				if err != nil {
+					config.Debugf("unable to get events for stack '%s': %s", source.stackId, err)
+					failed = true
+					continue
+				}
+
+				for _, e := range events {
+					select {
+					case ch <- e:
+					case <-ctx.Done():
+						return
+					}
+
+					if isNestedStackEvent(e) {
+						nestedId := ptr.ToString(e.PhysicalResourceId)
+
+						if nested, ok := byId[nestedId]; ok {
+							// The nested stack is being worked on again, e.g. during a rollback
+							nested.settled = false
+						} else {
+							nested = &eventSource{
+								stackId: nestedId,
+								// The nested stack's first events can be a little older
+								// than the event that tells us about it
+								since: ptr.ToTime(e.Timestamp).Add(-time.Minute),
+								seen:  make(map[string]bool),
+							}
+							sources = append(sources, nested)
+							byId[nestedId] = nested
+						}
+					}
+
+					if isStackEvent(e) && StatusIsSettled(string(e.ResourceStatus)) {
+						// Finish polling the nested stacks so that none of their events are missed
+						done = done || source == root
+						source.settled = true
+					}
+				}
+			}
+
+			if done {
+				return
+			}
+
+			if failed {
+				interval = min(interval*2, maxEventPollInterval)
+			} else {
+				interval = EventPollInterval
+			}
+		}
+	}()
+
+	return ch, nil
+}
@@ -0,0 +1,42 @@
+package cfn
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestNewEvents(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	event := func(id string, minutes int) types.StackEvent {
+		return types.StackEvent{
+			EventId:   ptr.String(id),
+			Timestamp: ptr.Time(start.Add(time.Duration(minutes) * time.Minute)),
+		}
+	}
+
+	s := &eventSource{
+		since: start,
+		seen:  map[string]bool{"b": true},
+	}
+
+	// Newest first, as returned by DescribeStackEvents
+	events, more := s.newEvents([]types.StackEvent{event("d", 3), event("c", 2), event("b", 1), event("a", 0)})
+
+	if more {
+		t.Error("expected to stop at the first event that was already seen")
+	}
+
+	if len(events) != 2 || *events[0].EventId != "c" || *events[1].EventId != "d" {
+		t.Errorf("expected c and d, oldest first; got %v", events)
+	}
+
+	events, more = s.newEvents([]types.StackEvent{event("e", 4), event("old", -1)})
+
+	if more || len(events) != 1 || *events[0].EventId != "e" {
+		t.Errorf("expected only e; got %v", events)
+	}
+}
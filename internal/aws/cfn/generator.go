@@ -0,0 +1,176 @@
+package cfn
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// LatestResourceScan returns the most recent resource scan that completed,
+// or nil if the account has none
+func LatestResourceScan() (*types.ResourceScanSummary, error) {
+	var latest *types.ResourceScanSummary
+	var token *string
+
+	for {
+		res, err := getClient().ListResourceScans(interrupt.Context(), &cloudformation.ListResourceScansInput{
+			NextToken: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for i, s := range res.ResourceScanSummaries {
+			if s.Status != types.ResourceScanStatusComplete || s.EndTime == nil {
+				continue
+			}
+
+			if latest == nil || s.EndTime.After(*latest.EndTime) {
+				latest = &res.ResourceScanSummaries[i]
+			}
+		}
+
+		if res.NextToken == nil {
+			return latest, nil
+		}
+
+		token = res.NextToken
+	}
+}
+
+// StartResourceScan starts a scan of the resources in the account and region,
+// and returns its ID
+func StartResourceScan() (string, error) {
+	res, err := getClient().StartResourceScan(interrupt.Context(), &cloudformation.StartResourceScanInput{})
+	if err != nil {
+		return "", err
+	}
+
+	return ptr.ToString(res.ResourceScanId), nil
+}
+
+// WaitForResourceScan waits for the resource scan to finish.
+// progress is called with the percentage completed each time the scan is checked.
+func WaitForResourceScan(scanId string, progress func(float64)) error {
+	for {
+		res, err := getClient().DescribeResourceScan(interrupt.Context(), &cloudformation.DescribeResourceScanInput{
+			ResourceScanId: &scanId,
+		})
+		if err != nil {
+			return err
+		}
+
+		switch res.Status {
+		case types.ResourceScanStatusComplete:
+			return nil
+		case types.ResourceScanStatusInProgress:
+			if progress != nil {
+				progress(ptr.ToFloat64(res.PercentageCompleted))
+			}
+		default:
+			return fmt.Errorf("resource scan %s: %s", res.Status, ptr.ToString(res.StatusReason))
+		}
+
+		if err := interrupt.Sleep(time.Second * WaitPeriodInSeconds); err != nil {
+			return err
+		}
+	}
+}
+
+// ListScannedResources returns the resources found by the scan.
+// typePrefix, tagKey and tagValue are optional filters.
+func ListScannedResources(scanId, typePrefix, tagKey, tagValue string) ([]types.ScannedResource, error) {
+	resources := make([]types.ScannedResource, 0)
+	var token *string
+
+	input := &cloudformation.ListResourceScanResourcesInput{
+		ResourceScanId: &scanId,
+	}
+
+	if typePrefix != "" {
+		input.ResourceTypePrefix = &typePrefix
+	}
+
+	if tagKey != "" {
+		input.TagKey = &tagKey
+	}
+
+	if tagValue != "" {
+		input.TagValue = &tagValue
+	}
+
+	for {
+		input.NextToken = token
+
+		res, err := getClient().ListResourceScanResources(interrupt.Context(), input)
+		if err != nil {
+			return nil, err
+		}
+
+		resources = append(resources, res.Resources...)
+
+		if res.NextToken == nil {
+			return resources, nil
+		}
+
+		token = res.NextToken
+	}
+}
+
+// CreateGeneratedTemplate asks the IaC generator for a template of the resources,
+// and waits until it is ready
+func CreateGeneratedTemplate(name string, resources []types.ResourceDefinition) (*cloudformation.DescribeGeneratedTemplateOutput, error) {
+	_, err := getClient().CreateGeneratedTemplate(interrupt.Context(), &cloudformation.CreateGeneratedTemplateInput{
+		GeneratedTemplateName: &name,
+		Resources:             resources,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		res, err := getClient().DescribeGeneratedTemplate(interrupt.Context(), &cloudformation.DescribeGeneratedTemplateInput{
+			GeneratedTemplateName: &name,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		switch res.Status {
+		case types.GeneratedTemplateStatusComplete:
+			return res, nil
+		case types.GeneratedTemplateStatusFailed:
+			return res, fmt.Errorf("template generation failed: %s", ptr.ToString(res.StatusReason))
+		}
+
+		if err := interrupt.Sleep(time.Second * WaitPeriodInSeconds); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// GetGeneratedTemplate returns the body of the generated template in YAML
+func GetGeneratedTemplate(name string) (string, error) {
+	res, err := getClient().GetGeneratedTemplate(interrupt.Context(), &cloudformation.GetGeneratedTemplateInput{
+		GeneratedTemplateName: &name,
+		Format:                types.TemplateFormatYaml,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return ptr.ToString(res.TemplateBody), nil
+}
+
+// DeleteGeneratedTemplate deletes the generated template
+func DeleteGeneratedTemplate(name string) error {
+	_, err := getClient().DeleteGeneratedTemplate(interrupt.Context(), &cloudformation.DeleteGeneratedTemplateInput{
+		GeneratedTemplateName: &name,
+	})
+
+	return err
+}
@@ -0,0 +1,105 @@
+package cfn
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// LockTag is the stack tag that rain protect sets to lock a stack,
+// so that rain deploy and rain rm refuse to change it unless --unlock is given
+const LockTag = "rain:protected"
+
+// IsLocked returns true if the stack has been locked with rain protect
+func IsLocked(stack types.Stack) bool {
+	for _, tag := range stack.Tags {
+		if ptr.ToString(tag.Key) == LockTag {
+			return ptr.ToString(tag.Value) == "true"
+		}
+	}
+
+	return false
+}
+
+// lockTags returns the tags with LockTag added, or removed if locked is false
+func lockTags(tags []types.Tag, locked bool) []types.Tag {
+	out := make([]types.Tag, 0)
+
+	for _, tag := range tags {
+		if ptr.ToString(tag.Key) != LockTag {
+			out = append(out, tag)
+		}
+	}
+
+	if locked {
+		out = append(out, types.Tag{
+			Key:   ptr.String(LockTag),
+			Value: ptr.String("true"),
+		})
+	}
+
+	return out
+}
+
+// SetLocked locks or unlocks the stack by adding or removing LockTag.
+// Stacks can't be tagged on their own, so the stack is updated with its previous template and parameters,
+// and CloudFormation passes the tag on to the stack's resources that support tags.
+func SetLocked(stackName string, locked bool) error {
+	stack, err := GetStack(stackName)
+	if err != nil {
+		return err
+	}
+
+	if IsLocked(stack) == locked {
+		return nil
+	}
+
+	params := make([]types.Parameter, 0)
+	for _, p := range stack.Parameters {
+		params = append(params, types.Parameter{
+			ParameterKey:     p.ParameterKey,
+			UsePreviousValue: ptr.Bool(true),
+		})
+	}
+
+	input := &cloudformation.UpdateStackInput{
+		StackName:           ptr.String(stackName),
+		UsePreviousTemplate: ptr.Bool(true),
+		Parameters:          params,
+		Tags:                lockTags(stack.Tags, locked),
+		Capabilities:        ChangeSetOptions{}.capabilities(),
+		NotificationARNs:    stack.NotificationARNs,
+		RoleARN:             stack.RoleARN,
+	}
+
+	if _, err := getClient().UpdateStack(interrupt.Context(), input); err != nil {
+		return err
+	}
+
+	status, messages := WaitForStackToSettle(stackName)
+	if status != "UPDATE_COMPLETE" {
+		if len(messages) > 0 {
+			return fmt.Errorf("stack '%s' is %s: %s", stackName, status, messages[0])
+		}
+		return fmt.Errorf("stack '%s' is %s", stackName, status)
+	}
+
+	return nil
+}
+
+// ErrLocked is returned by CheckUnlocked for a stack that has been locked with rain protect
+var ErrLocked = errors.New("the stack has been locked with rain protect")
+
+// CheckUnlocked returns an error that wraps ErrLocked if the stack is locked, unless unlock is true
+func CheckUnlocked(stack types.Stack, unlock bool) error {
+	if unlock || !IsLocked(stack) {
+		return nil
+	}
+
+	return fmt.Errorf("refusing to change stack '%s': %w; use --unlock to change it anyway, or rain protect %s unlock",
+		ptr.ToString(stack.StackName), ErrLocked, ptr.ToString(stack.StackName))
+}
@@ -0,0 +1,35 @@
+package cfn
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestLockTags(t *testing.T) {
+	tags := []types.Tag{{Key: ptr.String("team"), Value: ptr.String("data")}}
+
+	locked := types.Stack{StackName: ptr.String("prod"), Tags: lockTags(tags, true)}
+	if !IsLocked(locked) || len(locked.Tags) != 2 {
+		t.Errorf("expected the stack to be locked and keep its tags: %v", locked.Tags)
+	}
+
+	unlocked := types.Stack{StackName: ptr.String("prod"), Tags: lockTags(locked.Tags, false)}
+	if IsLocked(unlocked) || len(unlocked.Tags) != 1 {
+		t.Errorf("expected the stack to be unlocked and keep its tags: %v", unlocked.Tags)
+	}
+
+	if err := CheckUnlocked(locked, false); !errors.Is(err, ErrLocked) {
+		t.Errorf("expected ErrLocked, got %v", err)
+	}
+
+	if err := CheckUnlocked(locked, true); err != nil {
+		t.Errorf("expected --unlock to allow changes, got %v", err)
+	}
+
+	if err := CheckUnlocked(unlocked, false); err != nil {
+		t.Errorf("expected an unlocked stack to allow changes, got %v", err)
+	}
+}
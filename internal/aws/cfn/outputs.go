@@ -0,0 +1,217 @@
+package cfn
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/sts"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// OutputCacheTTL is how long stack outputs are cached on disk between invocations of rain.
+// Outputs are always cached in memory for the rest of the invocation,
+// but are only written to disk if OutputCacheTTL is greater than zero.
+// It can be set with the RAIN_OUTPUT_CACHE_TTL environment variable, e.g. "10m".
+var OutputCacheTTL = envDuration("RAIN_OUTPUT_CACHE_TTL")
+
+func envDuration(name string) time.Duration {
+	if d, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return d
+	}
+
+	return 0
+}
+
+// cachedOutputs is an entry in the on-disk output cache
+type cachedOutputs struct {
+	Fetched time.Time         `json:"fetched"`
+	Outputs map[string]string `json:"outputs"`
+}
+
+var outputCache = make(map[string]map[string]string)
+var outputCacheLock sync.Mutex
+
+// callerAccount returns the account of the current credentials; it is replaced in tests
+var callerAccount = sts.GetAccountID
+
+var outputAccount string
+var outputAccountOnce sync.Once
+
+// outputCacheAccount returns the account that rain is working in, so that outputs cached on disk
+// aren't mixed up between stacks of the same name in different accounts, which can be reached
+// with the same profile, or without one. Rain works in one account for the whole invocation,
+// so the account is only looked up once, and only if outputs are cached on disk.
+// It returns an empty string if the account can't be found, and then outputs aren't cached on disk.
+func outputCacheAccount() string {
+	if OutputCacheTTL <= 0 {
+		return ""
+	}
+
+	outputAccountOnce.Do(func() {
+		id, err := callerAccount()
+		if err != nil {
+			config.Debugf("unable to get the account to cache outputs for: %s", err)
+			return
+		}
+
+		outputAccount = id
+	})
+
+	return outputAccount
+}
+
+// outputCacheKey identifies a stack across accounts, profiles and regions
+func outputCacheKey(stackName string) string {
+	return fmt.Sprintf("%s|%s|%s|%s", outputCacheAccount(), config.Profile, aws.Config().Region, stackName)
+}
+
+// useDiskCache returns true if outputs are cached on disk
+func useDiskCache() bool {
+	return OutputCacheTTL > 0 && outputCacheAccount() != ""
+}
+
+// outputCachePath returns the path of the file the stack's outputs are cached in
+func outputCachePath(key string) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "rain", "outputs", fmt.Sprintf("%x.json", sha256.Sum256([]byte(key)))), nil
+}
+
+// readOutputCache returns the stack's outputs from disk if they are younger than OutputCacheTTL
+func readOutputCache(key string) (map[string]string, bool) {
+	path, err := outputCachePath(key)
+	if err != nil {
+		return nil, false
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var cached cachedOutputs
+	if err := json.Unmarshal(content, &cached); err != nil {
+		return nil, false
+	}
+
+	if time.Since(cached.Fetched) > OutputCacheTTL {
+		return nil, false
+	}
+
+	return cached.Outputs, true
+}
+
+// writeOutputCache saves the stack's outputs to disk.
+// Failing to write the cache is not an error; the outputs will be fetched again next time.
+func writeOutputCache(key string, outputs map[string]string) {
+	path, err := outputCachePath(key)
+	if err != nil {
+		return
+	}
+
+	content, err := json.Marshal(cachedOutputs{Fetched: time.Now(), Outputs: outputs})
+	if err != nil {
+		return
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err == nil {
+		err = os.WriteFile(path, content, 0600)
+	}
+
+	if err != nil {
+		config.Debugf("unable to cache outputs: %s", err)
+	}
+}
+
+// GetStackOutputs returns the outputs of the named stack as a map of output key to value.
+// Outputs are cached for the rest of the invocation, and on disk for OutputCacheTTL,
+// so that many lookups of the same stack only call DescribeStacks once.
+// Call InvalidateStackOutputs after changing the stack.
+func GetStackOutputs(stackName string) (map[string]string, error) {
+	key := outputCacheKey(stackName)
+
+	outputCacheLock.Lock()
+	defer outputCacheLock.Unlock()
+
+	if outputs, ok := outputCache[key]; ok {
+		return outputs, nil
+	}
+
+	if useDiskCache() {
+		if outputs, ok := readOutputCache(key); ok {
+			config.Debugf("using cached outputs for stack '%s'", stackName)
+			outputCache[key] = outputs
+			return outputs, nil
+		}
+	}
+
+	stack, err := GetStack(stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make(map[string]string)
+	for _, o := range stack.Outputs {
+		outputs[ptr.ToString(o.OutputKey)] = ptr.ToString(o.OutputValue)
+	}
+
+	outputCache[key] = outputs
+
+	if useDiskCache() {
+		writeOutputCache(key, outputs)
+	}
+
+	return outputs, nil
+}
+
+// GetStackOutputValue returns the value of a single output of the named stack
+func GetStackOutputValue(stackName, outputKey string) (string, error) {
+	outputs, err := GetStackOutputs(stackName)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := outputs[outputKey]
+	if !ok {
+		return "", fmt.Errorf("stack '%s' has no output named '%s'", stackName, outputKey)
+	}
+
+	return value, nil
+}
+
+// InvalidateStackOutputs removes the named stack's outputs from the cache,
+// in memory and on disk, so that the next lookup fetches them again
+func InvalidateStackOutputs(stackName string) {
+	key := outputCacheKey(stackName)
+
+	outputCacheLock.Lock()
+	defer outputCacheLock.Unlock()
+
+	delete(outputCache, key)
+
+	if path, err := outputCachePath(key); err == nil {
+		os.Remove(path)
+	}
+}
+
+func init() {
+	// Resolve !StackOutput values in deploy config files
+	dc.LookupStackOutput = GetStackOutputValue
+
+	// Choose the values for the region in deploy config files
+	dc.CurrentRegion = func() string {
+		return aws.Config().Region
+	}
+}
@@ -0,0 +1,40 @@
+package cfn
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOutputCacheAccount(t *testing.T) {
+	defer func(ttl time.Duration, lookup func() (string, error)) {
+		OutputCacheTTL, callerAccount = ttl, lookup
+		outputAccount, outputAccountOnce = "", sync.Once{}
+	}(OutputCacheTTL, callerAccount)
+
+	calls := 0
+	callerAccount = func() (string, error) {
+		calls++
+		return "123456789012", nil
+	}
+
+	OutputCacheTTL = 0
+	if a := outputCacheAccount(); a != "" || calls != 0 {
+		t.Errorf("expected no account lookup without a disk cache, got %q after %d calls", a, calls)
+	}
+
+	OutputCacheTTL = time.Minute
+	outputCacheAccount()
+	if a := outputCacheAccount(); a != "123456789012" || calls != 1 {
+		t.Errorf("expected the account to be looked up once, got %q after %d calls", a, calls)
+	}
+
+	outputAccount, outputAccountOnce = "", sync.Once{}
+	callerAccount = func() (string, error) {
+		return "", errors.New("no credentials")
+	}
+	if useDiskCache() {
+		t.Error("expected outputs not to be cached on disk when the account is unknown")
+	}
+}
@@ -0,0 +1,88 @@
+package cfn
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// StackSetQueueTimeout is the longest rain will wait for other
+// stack set operations to finish before giving up
+var StackSetQueueTimeout = time.Hour
+
+// queueBackoff returns how long to wait before retrying
+// a stack set operation for the given attempt
+func queueBackoff(attempt int) time.Duration {
+	wait := 5 * time.Second
+	for i := 0; i < attempt && wait < time.Minute; i++ {
+		wait *= 2
+	}
+
+	return min(wait, time.Minute)
+}
+
+// pendingStackSetOperations returns the number of operations
+// that are running or queued on the stack set
+func pendingStackSetOperations(stackSetName string, callAs types.CallAs) int {
+	res, err := getClient().ListStackSetOperations(interrupt.Context(), &cloudformation.ListStackSetOperationsInput{
+		StackSetName: &stackSetName,
+		CallAs:       callAs,
+	})
+	if err != nil {
+		config.Debugf("unable to list stack set operations: %v", err)
+		return 0
+	}
+
+	count := 0
+	for _, op := range res.Summaries {
+		switch op.Status {
+		case types.StackSetOperationStatusRunning,
+			types.StackSetOperationStatusQueued,
+			types.StackSetOperationStatusStopping:
+			count++
+		}
+	}
+
+	return count
+}
+
+// queueStackSetOperation calls start, and if it fails because another
+// operation is already in progress on the stack set, waits for the
+// other operations to finish and tries again, with backoff,
+// until StackSetQueueTimeout has passed
+func queueStackSetOperation(stackSetName string, callAs types.CallAs, start func() error) error {
+	deadline := time.Now().Add(StackSetQueueTimeout)
+
+	for attempt := 0; ; attempt++ {
+		err := start()
+
+		var inProgress *types.OperationInProgressException
+		if !errors.As(err, &inProgress) {
+			return err
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("gave up waiting for other operations on stack set '%s' after %s: %w",
+				stackSetName, StackSetQueueTimeout, err)
+		}
+
+		message := fmt.Sprintf("Another operation is in progress on stack set '%s'; waiting to start", stackSetName)
+		if pending := pendingStackSetOperations(stackSetName, callAs); pending > 0 {
+			message = fmt.Sprintf("Waiting for %d operation(s) on stack set '%s' to finish (queue position %d)",
+				pending, stackSetName, pending+1)
+		}
+
+		spinner.Push(message)
+		err = interrupt.Sleep(queueBackoff(attempt))
+		spinner.Pop()
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package cfn
+
+import (
+	"testing"
+	"time"
+)
+
+func TestQueueBackoff(t *testing.T) {
+	if d := queueBackoff(0); d != 5*time.Second {
+		t.Errorf("unexpected first backoff: %s", d)
+	}
+
+	if d := queueBackoff(1); d != 10*time.Second {
+		t.Errorf("unexpected second backoff: %s", d)
+	}
+
+	if d := queueBackoff(20); d != time.Minute {
+		t.Errorf("backoff should be capped at a minute, got %s", d)
+	}
+}
@@ -0,0 +1,73 @@
+package cfn
+
+import (
+	"context"
+
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// Region calls CloudFormation in a region other than the current one,
+// so that stacks in several regions can be watched at the same time.
+// The zero value calls the current region.
+type Region string
+
+func (r Region) client() *cloudformation.Client {
+	if r == "" {
+		return getClient()
+	}
+
+	return cloudformation.NewFromConfig(aws.Config(), func(o *cloudformation.Options) {
+		o.Region = string(r)
+	})
+}
+
+// GetStack returns the named stack in the region
+func (r Region) GetStack(stackName string) (types.Stack, error) {
+	return r.GetStackContext(interrupt.Context(), stackName)
+}
+
+// GetStackContext is GetStack for callers that have their own context
+func (r Region) GetStackContext(ctx context.Context, stackName string) (types.Stack, error) {
+	res, err := r.client().DescribeStacks(ctx, &cloudformation.DescribeStacksInput{
+		StackName: &stackName,
+	})
+	if err != nil {
+		return types.Stack{}, err
+	}
+
+	return res.Stacks[0], nil
+}
+
+// CancelUpdateStack cancels the update of the named stack in the region, which rolls it back
+func (r Region) CancelUpdateStack(stackName string) error {
+	_, err := r.client().CancelUpdateStack(interrupt.Context(), &cloudformation.CancelUpdateStackInput{
+		StackName: ptr.String(stackName),
+	})
+
+	return err
+}
+
+// DeleteStack deletes the named stack in the region
+func (r Region) DeleteStack(stackName string, roleArn string, retainResources ...string) error {
+	input := &cloudformation.DeleteStackInput{
+		StackName: &stackName,
+	}
+
+	// roleArn is optional
+	if roleArn != "" {
+		input.RoleARN = ptr.String(roleArn)
+	}
+
+	// CloudFormation only accepts retainResources for stacks in DELETE_FAILED
+	if len(retainResources) > 0 {
+		input.RetainResources = retainResources
+	}
+
+	_, err := r.client().DeleteStack(interrupt.Context(), input)
+
+	return err
+}
@@ -0,0 +1,148 @@
+package cfn
+
+import (
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// Failure is the event that caused a stack operation to fail
+type Failure struct {
+	Event types.StackEvent
+
+	// Path holds the logical IDs of the nested stacks
+	// between the root stack and the stack that contains the failed resource
+	Path []string
+}
+
+// operationStarts are the statuses a stack has at the start of an operation
+var operationStarts = map[types.ResourceStatus]bool{
+	types.ResourceStatusCreateInProgress: true,
+	types.ResourceStatusUpdateInProgress: true,
+	types.ResourceStatusDeleteInProgress: true,
+	types.ResourceStatusImportInProgress: true,
+}
+
+// isOperationStart returns true if the event marks the start of an operation on its stack
+func isOperationStart(e types.StackEvent) bool {
+	return isStackEvent(e) && operationStarts[e.ResourceStatus]
+}
+
+// isCancellation returns true if the event is a resource that failed
+// because a different resource failed first
+func isCancellation(e types.StackEvent) bool {
+	return strings.HasSuffix(ptr.ToString(e.ResourceStatusReason), " cancelled")
+}
+
+// firstFailure returns the earliest event of the most recent operation in events,
+// which are newest first as returned by DescribeStackEvents, in which a resource failed.
+// It also returns false if the events do not go back to the start of the operation
+// and no failure has been found yet.
+func firstFailure(events []types.StackEvent) (*types.StackEvent, bool) {
+	var first *types.StackEvent
+
+	for i, e := range events {
+		if isOperationStart(e) {
+			return first, true
+		}
+
+		if isStackEvent(e) || isCancellation(e) || !strings.HasSuffix(string(e.ResourceStatus), "_FAILED") {
+			continue
+		}
+
+		first = &events[i]
+	}
+
+	return first, false
+}
+
+// operationEvents returns the events in events, which are newest first,
+// that belong to the most recent operation on the stack.
+// It also returns false if the events do not go back to the start of the operation.
+func operationEvents(events []types.StackEvent) ([]types.StackEvent, bool) {
+	for i, e := range events {
+		if isOperationStart(e) {
+			return events[:i+1], true
+		}
+	}
+
+	return events, false
+}
+
+// LastOperationEvents returns the events of the most recent operation on the stack, newest first
+func LastOperationEvents(stackName string) ([]types.StackEvent, error) {
+	out := make([]types.StackEvent, 0)
+	var token *string
+
+	for {
+		res, err := getClient().DescribeStackEvents(interrupt.Context(), &cloudformation.DescribeStackEventsInput{
+			StackName: &stackName,
+			NextToken: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		events, complete := operationEvents(res.StackEvents)
+		out = append(out, events...)
+
+		if complete || res.NextToken == nil {
+			return out, nil
+		}
+
+		token = res.NextToken
+	}
+}
+
+// stackFailure finds the first failure in the most recent operation on the stack
+func stackFailure(stackName string) (*types.StackEvent, error) {
+	var failure *types.StackEvent
+	var token *string
+
+	for {
+		res, err := getClient().DescribeStackEvents(interrupt.Context(), &cloudformation.DescribeStackEventsInput{
+			StackName: &stackName,
+			NextToken: token,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Older pages can only hold earlier failures
+		f, complete := firstFailure(res.StackEvents)
+		if f != nil {
+			failure = f
+		}
+
+		if complete || res.NextToken == nil {
+			return failure, nil
+		}
+
+		token = res.NextToken
+	}
+}
+
+// RootCause returns the first resource that failed in the most recent operation on the stack,
+// following failed nested stacks down to the resource that caused them to fail.
+// It returns nil if no resource failed.
+func RootCause(stackName string) (*Failure, error) {
+	event, err := stackFailure(stackName)
+	if err != nil || event == nil {
+		return nil, err
+	}
+
+	failure := &Failure{Event: *event, Path: make([]string, 0)}
+
+	if ptr.ToString(event.ResourceType) == "AWS::CloudFormation::Stack" && event.PhysicalResourceId != nil {
+		nested, err := RootCause(ptr.ToString(event.PhysicalResourceId))
+		if err == nil && nested != nil {
+			nested.Path = append([]string{ptr.ToString(event.LogicalResourceId)}, nested.Path...)
+			return nested, nil
+		}
+	}
+
+	return failure, nil
+}
@@ -0,0 +1,74 @@
+package cfn
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestFirstFailure(t *testing.T) {
+	const stackId = "arn:aws:cloudformation:us-east-1:123456789012:stack/test/abc"
+
+	stack := func(status types.ResourceStatus) types.StackEvent {
+		return types.StackEvent{
+			StackId:            ptr.String(stackId),
+			PhysicalResourceId: ptr.String(stackId),
+			LogicalResourceId:  ptr.String("test"),
+			ResourceStatus:     status,
+		}
+	}
+
+	resource := func(name string, status types.ResourceStatus, reason string) types.StackEvent {
+		return types.StackEvent{
+			StackId:              ptr.String(stackId),
+			PhysicalResourceId:   ptr.String(name + "-id"),
+			LogicalResourceId:    ptr.String(name),
+			ResourceStatus:       status,
+			ResourceStatusReason: ptr.String(reason),
+		}
+	}
+
+	// Newest first, as returned by DescribeStackEvents
+	events := []types.StackEvent{
+		stack(types.ResourceStatusUpdateRollbackComplete),
+		resource("Bucket", types.ResourceStatusDeleteFailed, "Bucket is not empty"),
+		stack(types.ResourceStatusUpdateRollbackInProgress),
+		resource("Queue", types.ResourceStatusCreateFailed, "Resource creation cancelled"),
+		resource("Role", types.ResourceStatusCreateFailed, "Invalid principal"),
+		resource("Queue", types.ResourceStatusCreateInProgress, ""),
+		resource("Role", types.ResourceStatusCreateInProgress, ""),
+		stack(types.ResourceStatusUpdateInProgress),
+		resource("Old", types.ResourceStatusCreateFailed, "From a previous operation"),
+		stack(types.ResourceStatusCreateInProgress),
+	}
+
+	f, complete := firstFailure(events)
+	if !complete {
+		t.Error("expected to reach the start of the operation")
+	}
+
+	if f == nil || ptr.ToString(f.LogicalResourceId) != "Role" {
+		t.Errorf("expected Role to be the first failure; got %v", f)
+	}
+
+	// A page that doesn't reach the start of the operation
+	f, complete = firstFailure(events[:3])
+	if complete {
+		t.Error("expected more pages to be needed")
+	}
+
+	if f == nil || ptr.ToString(f.LogicalResourceId) != "Bucket" {
+		t.Errorf("expected Bucket to be the first failure on the page; got %v", f)
+	}
+
+	f, _ = firstFailure(events[5:8])
+	if f != nil {
+		t.Errorf("expected no failure; got %v", f)
+	}
+
+	ops, complete := operationEvents(events)
+	if !complete || len(ops) != 8 {
+		t.Errorf("expected the 8 events of the last operation; got %d", len(ops))
+	}
+}
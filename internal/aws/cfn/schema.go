@@ -76,6 +76,13 @@ func ParseSchema(source string) (*Schema, error) {
 	return &s, nil
 }
 
+// SupportsImport returns true if existing resources of the type can be imported into a stack.
+// CloudFormation reads a resource with the type's read handler to check that it exists before importing it.
+func (schema *Schema) SupportsImport() bool {
+	_, ok := schema.Handlers["read"]
+	return ok
+}
+
 // Patch applies patches to the schema to add things like undocumented enums
 func (schema *Schema) Patch() error {
 	switch schema.TypeName {
@@ -91,6 +91,10 @@ func TestSchema(t *testing.T) {
 		t.Fatalf("handlers missing create")
 	}
 
+	if !s.SupportsImport() {
+		t.Fatalf("expected the schema to support import")
+	}
+
 }
 
 func TestSchemaFiles(t *testing.T) {
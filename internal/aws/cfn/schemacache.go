@@ -0,0 +1,150 @@
+package cfn
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+)
+
+// SchemaCacheTTL is how long a resource schema downloaded from the registry is used
+// before rain downloads it again. Expired schemas are still used when rain is offline.
+// It can be set with the RAIN_SCHEMA_CACHE_TTL environment variable, e.g. "24h".
+var SchemaCacheTTL = schemaCacheTTL()
+
+func schemaCacheTTL() time.Duration {
+	if d := envDuration("RAIN_SCHEMA_CACHE_TTL"); d > 0 {
+		return d
+	}
+
+	return 7 * 24 * time.Hour
+}
+
+// typesFile is the name of the cached list of resource types
+const typesFile = "types.txt"
+
+// ErrOffline is returned when rain needs the registry but config.Offline is set
+var ErrOffline = errors.New("rain is offline; run 'rain schemas update' while online to cache the schemas you need")
+
+// SchemaCacheDir returns the directory that resource schemas are cached in,
+// e.g. ~/.cache/rain/schemas
+func SchemaCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "rain", "schemas"), nil
+}
+
+// schemaFileName returns the name of the cache file for a type, e.g. aws-s3-bucket.json
+func schemaFileName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "::", "-")) + ".json"
+}
+
+// readSchemaCache reads a file from the schema cache.
+// Files older than SchemaCacheTTL are only returned if rain is offline.
+func readSchemaCache(fileName string) (string, bool) {
+	dir, err := SchemaCacheDir()
+	if err != nil {
+		return "", false
+	}
+
+	path := filepath.Join(dir, fileName)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", false
+	}
+
+	if time.Since(info.ModTime()) > SchemaCacheTTL && !config.Offline {
+		config.Debugf("cached schema %s has expired", path)
+		return "", false
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	return string(content), true
+}
+
+// writeSchemaCache saves a file to the schema cache.
+// Failing to write the cache is not an error; the schema will be downloaded again next time.
+func writeSchemaCache(fileName string, content string) {
+	dir, err := SchemaCacheDir()
+	if err == nil {
+		err = os.MkdirAll(dir, 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(filepath.Join(dir, fileName), []byte(content), 0600)
+	}
+
+	if err != nil {
+		config.Debugf("unable to cache schema %s: %s", fileName, err)
+	}
+}
+
+// UpdateSchemas downloads the schemas of the resource types that start with one of the prefixes,
+// or of every resource type if there are no prefixes, and saves them in the schema cache.
+// progress is called before each schema is downloaded.
+func UpdateSchemas(prefixes []string, progress func(name string, i, total int)) ([]string, error) {
+	if config.Offline {
+		return nil, ErrOffline
+	}
+
+	all, err := ListResourceTypes(true)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0)
+	for _, name := range all {
+		if name == "" {
+			continue
+		}
+
+		if len(prefixes) == 0 {
+			names = append(names, name)
+			continue
+		}
+
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(name, prefix) {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+
+	if len(prefixes) > 0 && len(names) == 0 {
+		return nil, fmt.Errorf("no resource types start with %s", strings.Join(prefixes, " or "))
+	}
+
+	for i, name := range names {
+		if progress != nil {
+			progress(name, i, len(names))
+		}
+
+		if _, err := GetTypeSchema(name, true); err != nil {
+			return nil, fmt.Errorf("unable to download the schema for %s: %w", name, err)
+		}
+	}
+
+	return names, nil
+}
+
+// ClearSchemaCache removes every cached schema
+func ClearSchemaCache() error {
+	dir, err := SchemaCacheDir()
+	if err != nil {
+		return err
+	}
+
+	return os.RemoveAll(dir)
+}
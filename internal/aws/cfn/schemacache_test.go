@@ -0,0 +1,60 @@
+package cfn
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+)
+
+func TestSchemaCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	defer func() { config.Offline = false }()
+
+	fileName := schemaFileName("Acme::Widget::Gear")
+	if fileName != "acme-widget-gear.json" {
+		t.Errorf("unexpected file name %s", fileName)
+	}
+
+	if _, ok := readSchemaCache(fileName); ok {
+		t.Error("expected an empty cache")
+	}
+
+	writeSchemaCache(fileName, `{"typeName": "Acme::Widget::Gear"}`)
+
+	content, ok := readSchemaCache(fileName)
+	if !ok || content != `{"typeName": "Acme::Widget::Gear"}` {
+		t.Errorf("unexpected cached schema %q", content)
+	}
+
+	// Expire the schema
+	dir, _ := SchemaCacheDir()
+	old := time.Now().Add(-SchemaCacheTTL - time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, fileName), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := readSchemaCache(fileName); ok {
+		t.Error("expected the schema to have expired")
+	}
+
+	// Expired schemas are still used offline
+	config.Offline = true
+	if _, ok := readSchemaCache(fileName); !ok {
+		t.Error("expected the expired schema to be used offline")
+	}
+
+	if _, err := UpdateSchemas(nil, nil); err != ErrOffline {
+		t.Errorf("expected ErrOffline, got %v", err)
+	}
+
+	if err := ClearSchemaCache(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := readSchemaCache(fileName); ok {
+		t.Error("expected the cache to be cleared")
+	}
+}
@@ -1,13 +1,17 @@
 package cfn
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws-cloudformation/rain/internal/ui"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/aws/smithy-go/ptr"
@@ -192,6 +196,35 @@ func GetStackOutput(stack types.Stack) (string, []string) {
 	return strings.TrimSpace(out.String()), messages
 }
 
+// WaitForStack polls the stack in the region until it settles, without drawing anything,
+// and returns it. progress, if it is not nil, is called with the stack each time it is polled.
+// If ctx is cancelled, WaitForStack returns the stack as it was last seen, with ctx's error.
+func WaitForStack(ctx context.Context, r Region, stackName string, progress func(types.Stack)) (types.Stack, error) {
+	stackID := stackName
+
+	for {
+		stack, err := r.GetStackContext(ctx, stackID)
+		if err != nil {
+			return stack, err
+		}
+
+		// Follow the stack by its ID, which still works once it has been deleted
+		stackID = ptr.ToString(stack.StackId)
+
+		if progress != nil {
+			progress(stack)
+		}
+
+		if StackHasSettled(stack) {
+			return stack, nil
+		}
+
+		if err := interrupt.SleepContext(ctx, time.Second*WaitPeriodInSeconds); err != nil {
+			return stack, err
+		}
+	}
+}
+
 // WaitForStackToSettle blocks excute until a stack has finished updating
 // and then returns its status
 func WaitForStackToSettle(stackName string) (string, []string) {
@@ -205,6 +238,7 @@ func WaitForStackToSettle(stackName string) (string, []string) {
 	out := strings.Builder{}
 	outStr := ""
 	lastOutput := ""
+	lastStatus := ""
 
 	for {
 		out.Reset()
@@ -219,6 +253,20 @@ func WaitForStackToSettle(stackName string) (string, []string) {
 
 		output, messages := GetStackOutput(stack)
 
+		// Plain output reports each change once instead of redrawing
+		if console.PlainOutput {
+			if output != lastStatus {
+				console.Status(output)
+				lastStatus = output
+			}
+
+			for _, message := range messages {
+				if !collectedMessages[message] {
+					console.Status("Message: " + message)
+				}
+			}
+		}
+
 		// Send the output first
 		out.WriteString(output)
 		out.WriteString("\n")
@@ -235,7 +283,7 @@ func WaitForStackToSettle(stackName string) (string, []string) {
 
 		spinner.Pause()
 		console.ClearLines(console.CountLines(lastOutput))
-		if console.IsTTY {
+		if console.IsTTY && !console.PlainOutput {
 			fmt.Print(outStr)
 		}
 		lastOutput = outStr
@@ -255,7 +303,13 @@ func WaitForStackToSettle(stackName string) (string, []string) {
 			return string(stack.StackStatus), messages
 		}
 
-		time.Sleep(time.Second * WaitPeriodInSeconds)
+		if err := interrupt.Sleep(time.Second * WaitPeriodInSeconds); err != nil {
+			spinner.StopTimer()
+			console.ClearLines(console.CountLines(lastOutput))
+
+			panic(exitcode.Wrap(exitcode.Interrupted, fmt.Errorf("stopped waiting for stack '%s', which is %s; "+
+				"you can check its status with: rain watch %s", stackName, stack.StackStatus, stackName)))
+		}
 	}
 }
 
@@ -279,9 +333,9 @@ func GetStackSummary(stack types.Stack, long bool) string {
 				out.WriteString(fmt.Sprintf("    %s: ", console.Yellow(ptr.ToString(param.ParameterKey))))
 
 				if param.ResolvedValue != nil {
-					out.WriteString(ptr.ToString(param.ResolvedValue))
+					out.WriteString(config.Mask(ptr.ToString(param.ResolvedValue)))
 				} else {
-					out.WriteString(ptr.ToString(param.ParameterValue))
+					out.WriteString(config.Mask(ptr.ToString(param.ParameterValue)))
 				}
 
 				out.WriteString("\n")
@@ -360,9 +414,9 @@ func GetStackSetSummary(stackSet *types.StackSet, long bool) string {
 				out.WriteString(fmt.Sprintf("    %s: ", console.Yellow(ptr.ToString(param.ParameterKey))))
 
 				if param.ResolvedValue != nil {
-					out.WriteString(ptr.ToString(param.ResolvedValue))
+					out.WriteString(config.Mask(ptr.ToString(param.ResolvedValue)))
 				} else {
-					out.WriteString(ptr.ToString(param.ParameterValue))
+					out.WriteString(config.Mask(ptr.ToString(param.ParameterValue)))
 				}
 
 				out.WriteString("\n")
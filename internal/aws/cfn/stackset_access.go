@@ -0,0 +1,81 @@
+package cfn
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// StackSetsServicePrincipal is the service principal that delegated administrators for StackSets are registered with
+const StackSetsServicePrincipal = "member.org.stacksets.cloudformation.amazonaws.com"
+
+// StackSetAccess is how the current account can work with the stack sets of its organization
+type StackSetAccess struct {
+	Account string
+
+	// ManagementAccount is the organization's management account,
+	// or empty if the account is not in an organization
+	ManagementAccount string
+
+	// DelegatedAdmin is true if the account is registered as a delegated administrator for StackSets
+	DelegatedAdmin bool
+}
+
+// IsManagementAccount returns true if the account is its organization's management account
+func (a StackSetAccess) IsManagementAccount() bool {
+	return a.ManagementAccount != "" && a.Account == a.ManagementAccount
+}
+
+// CallAs returns how stack set operations should be called from the account:
+// as a delegated administrator if it is one, or as itself
+func (a StackSetAccess) CallAs() types.CallAs {
+	if a.DelegatedAdmin && !a.IsManagementAccount() {
+		return types.CallAsDelegatedAdmin
+	}
+
+	return types.CallAsSelf
+}
+
+// CheckServiceManaged returns an error that explains how to register the account
+// if it can't work with service-managed stack sets
+func (a StackSetAccess) CheckServiceManaged() error {
+	switch {
+	case a.IsManagementAccount(), a.DelegatedAdmin:
+		return nil
+	case a.ManagementAccount == "":
+		return fmt.Errorf("service-managed stack sets deploy to the accounts of an AWS Organization, "+
+			"but account %s is not in an organization; use self-managed permissions instead", a.Account)
+	default:
+		return fmt.Errorf(`account %s is not the management account of its organization or a delegated administrator for StackSets, so it can't work with service-managed stack sets.
+To register it as a delegated administrator:
+  1. Activate trusted access for StackSets in the management account (%s), from the StackSets page of the CloudFormation console
+  2. From the management account, run:
+     aws organizations register-delegated-administrator --service-principal %s --account-id %s
+Or run rain from the management account instead`, a.Account, a.ManagementAccount, StackSetsServicePrincipal, a.Account)
+	}
+}
+
+// IsDelegatedAdmin returns true if the current account is registered as a delegated administrator for StackSets.
+// Only the management account can list delegated administrators, so this tries to list stack sets as one instead.
+func IsDelegatedAdmin() (bool, error) {
+	_, err := getClient().ListStackSets(interrupt.Context(), &cloudformation.ListStackSetsInput{
+		CallAs:     types.CallAsDelegatedAdmin,
+		MaxResults: ptr.Int32(1),
+	})
+
+	if err == nil {
+		return true, nil
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ValidationError" {
+		return false, nil
+	}
+
+	return false, err
+}
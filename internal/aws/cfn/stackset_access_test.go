@@ -0,0 +1,36 @@
+package cfn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+func TestStackSetAccess(t *testing.T) {
+	cases := []struct {
+		name     string
+		access   StackSetAccess
+		callAs   types.CallAs
+		errorHas string
+	}{
+		{"management account", StackSetAccess{Account: "111111111111", ManagementAccount: "111111111111"}, types.CallAsSelf, ""},
+		{"delegated admin", StackSetAccess{Account: "222222222222", ManagementAccount: "111111111111", DelegatedAdmin: true}, types.CallAsDelegatedAdmin, ""},
+		{"member account", StackSetAccess{Account: "222222222222", ManagementAccount: "111111111111"}, types.CallAsSelf, "register-delegated-administrator"},
+		{"standalone account", StackSetAccess{Account: "333333333333"}, types.CallAsSelf, "not in an organization"},
+	}
+
+	for _, c := range cases {
+		if callAs := c.access.CallAs(); callAs != c.callAs {
+			t.Errorf("%s: expected to call as %s, got %s", c.name, c.callAs, callAs)
+		}
+
+		err := c.access.CheckServiceManaged()
+		switch {
+		case c.errorHas == "" && err != nil:
+			t.Errorf("%s: unexpected error: %s", c.name, err)
+		case c.errorHas != "" && (err == nil || !strings.Contains(err.Error(), c.errorHas)):
+			t.Errorf("%s: expected an error containing %q, got %v", c.name, c.errorHas, err)
+		}
+	}
+}
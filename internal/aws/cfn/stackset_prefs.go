@@ -0,0 +1,152 @@
+package cfn
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// ValidateOperationPreferences checks stack set operation preferences before they are sent,
+// so that a mistake is reported before rain waits in the stack set's operation queue
+func ValidateOperationPreferences(p *types.StackSetOperationPreferences) error {
+	if p == nil {
+		return nil
+	}
+
+	errs := make([]error, 0)
+
+	if p.MaxConcurrentCount != nil && p.MaxConcurrentPercentage != nil {
+		errs = append(errs, errors.New("set either the maximum concurrent count or percentage, not both"))
+	}
+
+	if p.FailureToleranceCount != nil && p.FailureTolerancePercentage != nil {
+		errs = append(errs, errors.New("set either the failure tolerance count or percentage, not both"))
+	}
+
+	if p.MaxConcurrentCount != nil && *p.MaxConcurrentCount < 1 {
+		errs = append(errs, fmt.Errorf("the maximum concurrent count must be at least 1, not %d", *p.MaxConcurrentCount))
+	}
+
+	if p.MaxConcurrentPercentage != nil && (*p.MaxConcurrentPercentage < 1 || *p.MaxConcurrentPercentage > 100) {
+		errs = append(errs, fmt.Errorf("the maximum concurrent percentage must be between 1 and 100, not %d", *p.MaxConcurrentPercentage))
+	}
+
+	if p.FailureToleranceCount != nil && *p.FailureToleranceCount < 0 {
+		errs = append(errs, fmt.Errorf("the failure tolerance count can't be negative: %d", *p.FailureToleranceCount))
+	}
+
+	if p.FailureTolerancePercentage != nil && (*p.FailureTolerancePercentage < 0 || *p.FailureTolerancePercentage > 100) {
+		errs = append(errs, fmt.Errorf("the failure tolerance percentage must be between 0 and 100, not %d", *p.FailureTolerancePercentage))
+	}
+
+	if p.RegionConcurrencyType != "" && !containsValue(p.RegionConcurrencyType.Values(), p.RegionConcurrencyType) {
+		errs = append(errs, fmt.Errorf("unknown region concurrency '%s'; use %s",
+			p.RegionConcurrencyType, joinValues(p.RegionConcurrencyType.Values())))
+	}
+
+	if p.ConcurrencyMode != "" && !containsValue(p.ConcurrencyMode.Values(), p.ConcurrencyMode) {
+		errs = append(errs, fmt.Errorf("unknown concurrency mode '%s'; use %s",
+			p.ConcurrencyMode, joinValues(p.ConcurrencyMode.Values())))
+	}
+
+	// In the default mode, CloudFormation stops starting new operations once
+	// the failure tolerance is reached, which limits how many can run at once
+	strict := p.ConcurrencyMode == "" || p.ConcurrencyMode == types.ConcurrencyModeStrictFailureTolerance
+	if strict && p.MaxConcurrentCount != nil && p.FailureToleranceCount != nil &&
+		*p.MaxConcurrentCount > *p.FailureToleranceCount+1 {
+		errs = append(errs, fmt.Errorf("the maximum concurrent count (%d) can be at most one more than the failure tolerance count (%d), "+
+			"unless the concurrency mode is %s", *p.MaxConcurrentCount, *p.FailureToleranceCount, types.ConcurrencyModeSoftFailureTolerance))
+	}
+
+	if len(p.RegionOrder) > 0 && p.RegionConcurrencyType == types.RegionConcurrencyTypeParallel {
+		errs = append(errs, errors.New("a region order can't be set when regions are deployed in parallel"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// SummarizeOperationResults counts the instances of a stack set operation by status,
+// e.g. "3 succeeded, 2 running, 1 failed of 6 instances"
+func SummarizeOperationResults(results []types.StackSetOperationResultSummary) string {
+	counts := make(map[types.StackSetOperationResultStatus]int)
+	for _, r := range results {
+		counts[r.Status]++
+	}
+
+	statuses := make([]string, 0, len(counts))
+	for status := range counts {
+		statuses = append(statuses, string(status))
+	}
+
+	// Show statuses in the order that instances move through them
+	order := []types.StackSetOperationResultStatus{
+		types.StackSetOperationResultStatusSucceeded,
+		types.StackSetOperationResultStatusRunning,
+		types.StackSetOperationResultStatusPending,
+		types.StackSetOperationResultStatusFailed,
+		types.StackSetOperationResultStatusCancelled,
+	}
+	rank := func(s string) int {
+		for i, o := range order {
+			if string(o) == s {
+				return i
+			}
+		}
+		return len(order)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		if rank(statuses[i]) != rank(statuses[j]) {
+			return rank(statuses[i]) < rank(statuses[j])
+		}
+		return statuses[i] < statuses[j]
+	})
+
+	parts := make([]string, len(statuses))
+	for i, status := range statuses {
+		parts[i] = fmt.Sprintf("%d %s", counts[types.StackSetOperationResultStatus(status)], strings.ToLower(status))
+	}
+
+	return fmt.Sprintf("%s of %d instances", strings.Join(parts, ", "), len(results))
+}
+
+// FailedOperationResults describes the instances that failed in a stack set operation,
+// one per line with the account, region and reason
+func FailedOperationResults(results []types.StackSetOperationResultSummary) []string {
+	failed := make([]string, 0)
+
+	for _, r := range results {
+		if r.Status != types.StackSetOperationResultStatusFailed {
+			continue
+		}
+
+		failed = append(failed, fmt.Sprintf("%s / %s: %s",
+			ptr.ToString(r.Account), ptr.ToString(r.Region), ptr.ToString(r.StatusReason)))
+	}
+
+	sort.Strings(failed)
+
+	return failed
+}
+
+func containsValue[T comparable](values []T, value T) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinValues[T ~string](values []T) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = string(v)
+	}
+
+	return strings.Join(parts, " or ")
+}
@@ -0,0 +1,67 @@
+package cfn
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestValidateOperationPreferences(t *testing.T) {
+	valid := []*types.StackSetOperationPreferences{
+		nil,
+		{},
+		{MaxConcurrentCount: ptr.Int32(3), FailureToleranceCount: ptr.Int32(2)},
+		{MaxConcurrentPercentage: ptr.Int32(50), FailureTolerancePercentage: ptr.Int32(0)},
+		{MaxConcurrentCount: ptr.Int32(10), FailureToleranceCount: ptr.Int32(1),
+			ConcurrencyMode: types.ConcurrencyModeSoftFailureTolerance},
+		{RegionConcurrencyType: types.RegionConcurrencyTypeSequential, RegionOrder: []string{"us-east-1", "us-west-2"}},
+	}
+
+	for _, p := range valid {
+		if err := ValidateOperationPreferences(p); err != nil {
+			t.Errorf("%+v: unexpected error: %s", p, err)
+		}
+	}
+
+	invalid := map[string]*types.StackSetOperationPreferences{
+		"count or percentage, not both": {MaxConcurrentCount: ptr.Int32(1), MaxConcurrentPercentage: ptr.Int32(10)},
+		"tolerance count or percentage": {FailureToleranceCount: ptr.Int32(1), FailureTolerancePercentage: ptr.Int32(10)},
+		"at least 1":                    {MaxConcurrentCount: ptr.Int32(0)},
+		"between 1 and 100":             {MaxConcurrentPercentage: ptr.Int32(150)},
+		"can't be negative":             {FailureToleranceCount: ptr.Int32(-1)},
+		"between 0 and 100":             {FailureTolerancePercentage: ptr.Int32(101)},
+		"unknown region concurrency":    {RegionConcurrencyType: "ALL"},
+		"unknown concurrency mode":      {ConcurrencyMode: "FAST"},
+		"at most one more":              {MaxConcurrentCount: ptr.Int32(5), FailureToleranceCount: ptr.Int32(1)},
+		"region order":                  {RegionConcurrencyType: types.RegionConcurrencyTypeParallel, RegionOrder: []string{"us-east-1"}},
+	}
+
+	for expected, p := range invalid {
+		err := ValidateOperationPreferences(p)
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("%+v: expected an error containing %q, got %v", p, expected, err)
+		}
+	}
+}
+
+func TestSummarizeOperationResults(t *testing.T) {
+	results := []types.StackSetOperationResultSummary{
+		{Account: ptr.String("111111111111"), Region: ptr.String("us-east-1"), Status: types.StackSetOperationResultStatusSucceeded},
+		{Account: ptr.String("222222222222"), Region: ptr.String("us-east-1"), Status: types.StackSetOperationResultStatusFailed,
+			StatusReason: ptr.String("Bucket already exists")},
+		{Account: ptr.String("111111111111"), Region: ptr.String("us-west-2"), Status: types.StackSetOperationResultStatusRunning},
+		{Account: ptr.String("222222222222"), Region: ptr.String("us-west-2"), Status: types.StackSetOperationResultStatusSucceeded},
+	}
+
+	expected := "2 succeeded, 1 running, 1 failed of 4 instances"
+	if s := SummarizeOperationResults(results); s != expected {
+		t.Errorf("expected %q, got %q", expected, s)
+	}
+
+	failed := FailedOperationResults(results)
+	if len(failed) != 1 || failed[0] != "222222222222 / us-east-1: Bucket already exists" {
+		t.Errorf("unexpected failures: %v", failed)
+	}
+}
@@ -0,0 +1,51 @@
+package cfn
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// FilterTargetAccounts returns the accounts that a stack set operation on targets would reach,
+// given the accounts in the targets' organizational units.
+// The targets' AccountFilterType decides how their Accounts combine with the organizational units' accounts.
+func FilterTargetAccounts(targets *types.DeploymentTargets, ouAccounts []string) []string {
+	if targets == nil {
+		return nil
+	}
+
+	listed := make(map[string]bool, len(targets.Accounts))
+	for _, a := range targets.Accounts {
+		listed[a] = true
+	}
+
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	add := func(a string) {
+		if !seen[a] {
+			seen[a] = true
+			result = append(result, a)
+		}
+	}
+
+	for _, a := range ouAccounts {
+		switch targets.AccountFilterType {
+		case types.AccountFilterTypeIntersection:
+			if listed[a] {
+				add(a)
+			}
+		case types.AccountFilterTypeDifference:
+			if !listed[a] {
+				add(a)
+			}
+		default:
+			add(a)
+		}
+	}
+
+	if targets.AccountFilterType == types.AccountFilterTypeUnion {
+		for _, a := range targets.Accounts {
+			add(a)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,36 @@
+package cfn
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestFilterTargetAccounts(t *testing.T) {
+	ouAccounts := []string{"111111111111", "222222222222", "333333333333", "222222222222"}
+	listed := []string{"222222222222", "444444444444"}
+
+	cases := []struct {
+		filter   types.AccountFilterType
+		expected []string
+	}{
+		{"", []string{"111111111111", "222222222222", "333333333333"}},
+		{types.AccountFilterTypeNone, []string{"111111111111", "222222222222", "333333333333"}},
+		{types.AccountFilterTypeIntersection, []string{"222222222222"}},
+		{types.AccountFilterTypeDifference, []string{"111111111111", "333333333333"}},
+		{types.AccountFilterTypeUnion, []string{"111111111111", "222222222222", "333333333333", "444444444444"}},
+	}
+
+	for _, c := range cases {
+		targets := &types.DeploymentTargets{Accounts: listed, AccountFilterType: c.filter}
+
+		if d := cmp.Diff(c.expected, FilterTargetAccounts(targets, ouAccounts)); d != "" {
+			t.Errorf("%q: %s", c.filter, d)
+		}
+	}
+
+	if accounts := FilterTargetAccounts(nil, ouAccounts); accounts != nil {
+		t.Errorf("expected no accounts without targets, got %v", accounts)
+	}
+}
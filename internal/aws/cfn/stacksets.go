@@ -1,12 +1,13 @@
 package cfn
 
 import (
-	"context"
 	"errors"
 	"fmt"
 	"github.com/aws-cloudformation/rain/cft/format"
 	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/aws/smithy-go/ptr"
@@ -26,7 +27,7 @@ func ListStackSets(delegateAdmin bool) ([]types.StackSetSummary, error) {
 	}
 
 	for {
-		res, err := getClient().ListStackSets(context.Background(), &cloudformation.ListStackSetsInput{
+		res, err := getClient().ListStackSets(interrupt.Context(), &cloudformation.ListStackSetsInput{
 			NextToken: token,
 			CallAs:    callas,
 		})
@@ -58,7 +59,7 @@ func ListStackSetInstances(stackSetName string, delegatedAdmin bool) ([]types.St
 	}
 
 	for {
-		res, err := getClient().ListStackInstances(context.Background(), &cloudformation.ListStackInstancesInput{
+		res, err := getClient().ListStackInstances(interrupt.Context(), &cloudformation.ListStackInstancesInput{
 			NextToken:    token,
 			StackSetName: &stackSetName,
 			CallAs:       callas,
@@ -88,7 +89,7 @@ func ListLast10StackSetOperations(stackSetName string, delegatedAdmin bool) ([]t
 	if delegatedAdmin {
 		callas = types.CallAsDelegatedAdmin
 	}
-	res, err := getClient().ListStackSetOperations(context.Background(), &cloudformation.ListStackSetOperationsInput{
+	res, err := getClient().ListStackSetOperations(interrupt.Context(), &cloudformation.ListStackSetOperationsInput{
 		MaxResults:   ptr.Int32(10),
 		StackSetName: &stackSetName,
 		CallAs:       callas,
@@ -108,7 +109,7 @@ func GetStackSetOperationsResult(stackSetName *string, operationId *string, dele
 	if delegatedAdmin {
 		callas = types.CallAsDelegatedAdmin
 	}
-	res, err := getClient().ListStackSetOperationResults(context.Background(), &cloudformation.ListStackSetOperationResultsInput{
+	res, err := getClient().ListStackSetOperationResults(interrupt.Context(), &cloudformation.ListStackSetOperationResultsInput{
 		MaxResults:   ptr.Int32(1),
 		OperationId:  operationId,
 		StackSetName: stackSetName,
@@ -127,19 +128,20 @@ func DeleteStackSet(stackSetName string, delegatedAdmin bool) error {
 	if delegatedAdmin {
 		callas = types.CallAsDelegatedAdmin
 	}
-	_, err := getClient().DeleteStackSet(context.Background(), &cloudformation.DeleteStackSetInput{
-		StackSetName: &stackSetName,
-		CallAs:       callas,
+	return queueStackSetOperation(stackSetName, callas, func() error {
+		_, err := getClient().DeleteStackSet(interrupt.Context(), &cloudformation.DeleteStackSetInput{
+			StackSetName: &stackSetName,
+			CallAs:       callas,
+		})
+		return err
 	})
-
-	return err
 }
 
 // DeleteAllStackSetInstances deletes all instances for a given stack set
-func DeleteAllStackSetInstances(stackSetName string, wait bool, retainStacks bool, delegatedAdmin bool) error {
+func DeleteAllStackSetInstances(stackSetName string, prefs *types.StackSetOperationPreferences, wait bool, retainStacks bool, delegatedAdmin bool) error {
 	instances, err := ListStackSetInstances(stackSetName, delegatedAdmin)
 	if err != nil {
-		fmt.Printf("Could not fetch instances for stack set '%s'", stackSetName)
+		console.Logf("Could not fetch instances for stack set '%s'", stackSetName)
 		return err
 	}
 	accounts := make([]string, 0)
@@ -150,44 +152,92 @@ func DeleteAllStackSetInstances(stackSetName string, wait bool, retainStacks boo
 			regions = append(regions, *i.Region)
 		}
 	}
-	return DeleteStackSetInstances(stackSetName, accounts, regions, wait, retainStacks, delegatedAdmin)
+	return DeleteStackSetInstances(stackSetName, accounts, regions, prefs, wait, retainStacks, delegatedAdmin)
 }
 
 // DeleteStackSetInstances deletes instances for a given stack set in specified accounts and regions
-func DeleteStackSetInstances(stackSetName string, accounts []string, regions []string, wait bool, retainStacks bool, delegatedAdmin bool) error {
+func DeleteStackSetInstances(stackSetName string, accounts []string, regions []string, prefs *types.StackSetOperationPreferences, wait bool, retainStacks bool, delegatedAdmin bool) error {
 	_, err := GetStackSet(stackSetName, delegatedAdmin)
 	if err != nil {
-		fmt.Printf("Could not find stack set '%s'", stackSetName)
+		console.Logf("Could not find stack set '%s'", stackSetName)
 		return err
 	}
 
-	callas := types.CallAsSelf
-	if delegatedAdmin {
-		callas = types.CallAsDelegatedAdmin
-	}
-	var input = &cloudformation.DeleteStackInstancesInput{
-		Accounts:     UniqueStrings(accounts),
-		Regions:      UniqueStrings(regions),
-		RetainStacks: &retainStacks,
-		StackSetName: &stackSetName,
-		CallAs:       callas,
+	operationId, err := StartDeleteStackSetInstances(stackSetName, accounts, regions, prefs, retainStacks, delegatedAdmin)
+
+	return reportDeleteInstances(stackSetName, operationId, wait, err)
+}
+
+// StartDeleteStackSetInstances starts deleting the instances of a stack set in the accounts and regions
+// and returns the ID of the operation without waiting for it
+func StartDeleteStackSetInstances(stackSetName string, accounts []string, regions []string, prefs *types.StackSetOperationPreferences, retainStacks bool, delegatedAdmin bool) (string, error) {
+	return startDeleteStackInstances(&cloudformation.DeleteStackInstancesInput{
+		Accounts:             UniqueStrings(accounts),
+		Regions:              UniqueStrings(regions),
+		RetainStacks:         &retainStacks,
+		StackSetName:         &stackSetName,
+		OperationPreferences: prefs,
+		CallAs:               callAs(delegatedAdmin),
+	})
+}
+
+// DeleteStackSetInstancesFromOUs deletes the instances of a service-managed stack set
+// in the accounts of the organizational units, in the specified regions
+func DeleteStackSetInstancesFromOUs(stackSetName string, ous []string, regions []string, prefs *types.StackSetOperationPreferences, wait bool, retainStacks bool, delegatedAdmin bool) error {
+	operationId, err := StartDeleteStackSetInstancesFromOUs(stackSetName, ous, regions, prefs, retainStacks, delegatedAdmin)
+
+	return reportDeleteInstances(stackSetName, operationId, wait, err)
+}
+
+// StartDeleteStackSetInstancesFromOUs starts deleting the instances of a service-managed stack set
+// in the accounts of the organizational units and returns the ID of the operation without waiting for it
+func StartDeleteStackSetInstancesFromOUs(stackSetName string, ous []string, regions []string, prefs *types.StackSetOperationPreferences, retainStacks bool, delegatedAdmin bool) (string, error) {
+	return startDeleteStackInstances(&cloudformation.DeleteStackInstancesInput{
+		DeploymentTargets: &types.DeploymentTargets{
+			OrganizationalUnitIds: UniqueStrings(ous),
+		},
+		Regions:              UniqueStrings(regions),
+		RetainStacks:         &retainStacks,
+		StackSetName:         &stackSetName,
+		OperationPreferences: prefs,
+		CallAs:               callAs(delegatedAdmin),
+	})
+}
+
+func startDeleteStackInstances(input *cloudformation.DeleteStackInstancesInput) (string, error) {
+	var res *cloudformation.DeleteStackInstancesOutput
+	err := queueStackSetOperation(*input.StackSetName, input.CallAs, func() error {
+		var err error
+		res, err = getClient().DeleteStackInstances(interrupt.Context(), input)
+		return err
+	})
+	if err != nil {
+		return "", err
 	}
 
-	res, err := getClient().DeleteStackInstances(context.Background(), input)
+	return ptr.ToString(res.OperationId), nil
+}
+
+// reportDeleteInstances shows the delete operation that was started and waits for it if wait is set
+func reportDeleteInstances(stackSetName string, operationId string, wait bool, err error) error {
 	spinner.Pause()
 	if err != nil {
-		fmt.Print("error occurred while tried to delete instances")
+		console.Log("error occurred while tried to delete instances")
 		return err
 	}
-	fmt.Printf("Submitted DELETE instances operation with ID: %s\n", *res.OperationId)
+	console.Logf("Submitted DELETE instances operation with ID: %s", operationId)
 	spinner.Resume()
 	if wait {
-		err := WaitUntilStackSetOperationCompleted(*res.OperationId, stackSetName)
-		if err != nil {
-			return err
-		}
+		return WaitUntilStackSetOperationCompleted(operationId, stackSetName)
 	}
-	return err
+	return nil
+}
+
+func callAs(delegatedAdmin bool) types.CallAs {
+	if delegatedAdmin {
+		return types.CallAsDelegatedAdmin
+	}
+	return types.CallAsSelf
 }
 
 // GetStackSet returns a cloudformation.StackSet
@@ -199,7 +249,7 @@ func GetStackSet(stackSetName string, delegatedAdmin bool) (*types.StackSet, err
 		callas = types.CallAsDelegatedAdmin
 	}
 
-	res, err := getClient().DescribeStackSet(context.Background(), &cloudformation.DescribeStackSetInput{
+	res, err := getClient().DescribeStackSet(interrupt.Context(), &cloudformation.DescribeStackSetInput{
 		StackSetName: &stackSetName,
 		CallAs:       callas,
 	})
@@ -243,7 +293,7 @@ func CreateStackSet(conf StackSetConfig) (*string, error) {
 		input.TemplateBody = ptr.String(templateBody)
 	}
 
-	res, err := getClient().CreateStackSet(context.Background(), input)
+	res, err := getClient().CreateStackSet(interrupt.Context(), input)
 
 	if err != nil {
 		return nil, err
@@ -253,15 +303,40 @@ func CreateStackSet(conf StackSetConfig) (*string, error) {
 
 // UpdateStackSet updates stack set and its instances
 func UpdateStackSet(conf StackSetConfig, instanceConf StackSetInstancesConfig, wait bool) error {
+	spinner.Pause()
+	if len(instanceConf.Accounts) == 0 {
+		console.Log("Updating stack set instances in all previously deployed accounts and regions")
+	} else {
+		console.Logf("Updating stack set instances in...\naccounts: %+v\nregions: %+v", instanceConf.Accounts, instanceConf.Regions)
+	}
+	spinner.Resume()
+
+	operationId, err := StartUpdateStackSet(conf, instanceConf)
+	if err != nil {
+		return err
+	}
+
+	spinner.Pause()
+	console.Logf("Submitted UPDATE stack set operation with ID: %s", operationId)
+	spinner.Resume()
+
+	if wait {
+		err = WaitUntilStackSetOperationCompleted(operationId, conf.StackSetName)
+	}
+	return err
+}
 
+// StartUpdateStackSet starts updating a stack set and its instances
+// and returns the ID of the operation without waiting for it
+func StartUpdateStackSet(conf StackSetConfig, instanceConf StackSetInstancesConfig) (string, error) {
 	templateBody, err := checkTemplate(conf.Template)
 	if err != nil {
-		return errors.New("error occurred while extracting template body")
+		return "", errors.New("error occurred while extracting template body")
 	}
 
 	_, err = GetStackSet(conf.StackSetName, conf.CallAs == types.CallAsDelegatedAdmin)
 	if err != nil {
-		return errors.New("can't update stack set. It does not exists or it is in a wrong state")
+		return "", errors.New("can't update stack set. It does not exists or it is in a wrong state")
 	}
 
 	input := &cloudformation.UpdateStackSetInput{
@@ -289,33 +364,19 @@ func UpdateStackSet(conf StackSetConfig, instanceConf StackSetInstancesConfig, w
 		input.TemplateBody = ptr.String(templateBody)
 	}
 
-	spinner.Pause()
-	if len(input.Accounts) == 0 {
-		fmt.Println("Updating stack set instances in all previously deployed accounts and regions")
-	} else {
-		fmt.Printf("Updating stack set instances in...\naccounts: %+v\nregions: %+v\n", input.Accounts, input.Regions)
-	}
-	spinner.Resume()
-
-	res, err := getClient().UpdateStackSet(context.Background(), input)
-
-	config.Debugf("Update stack instances API result:\n%s", format.PrettyPrint(res))
-	if err != nil {
+	var res *cloudformation.UpdateStackSetOutput
+	err = queueStackSetOperation(conf.StackSetName, conf.CallAs, func() error {
+		var err error
+		res, err = getClient().UpdateStackSet(interrupt.Context(), input)
 		return err
-	}
-
-	spinner.Pause()
-	fmt.Printf("Submitted UPDATE stack set operation with ID: %s\n", *res.OperationId)
-	spinner.Resume()
+	})
 
+	config.Debugf("Update stack instances API result:\n%s", format.PrettyPrint(res))
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	if wait {
-		err = WaitUntilStackSetOperationCompleted(*res.OperationId, conf.StackSetName)
-	}
-	return err
+	return ptr.ToString(res.OperationId), nil
 }
 
 // AddStackSetInstances adds instances to a stack set
@@ -330,42 +391,52 @@ func AddStackSetInstances(conf StackSetConfig, instanceConf StackSetInstancesCon
 	if len(instanceConf.Accounts) == 0 || len(instanceConf.Regions) == 0 {
 		return errors.New("can't update stack set. Account(s) and region(s) must be provided")
 	} else {
-		fmt.Printf("Adding stack set instances in...\naccounts: %+v\nregions: %+v\n", instanceConf.Accounts, instanceConf.Regions)
+		console.Logf("Adding stack set instances in...\naccounts: %+v\nregions: %+v", instanceConf.Accounts, instanceConf.Regions)
 	}
 	spinner.Resume()
 
-	input := &cloudformation.CreateStackInstancesInput{
-		StackSetName:         &conf.StackSetName,
-		Accounts:             instanceConf.Accounts,
-		Regions:              instanceConf.Regions,
-		DeploymentTargets:    instanceConf.DeploymentTargets,
-		OperationPreferences: instanceConf.OperationPreferences,
-		CallAs:               conf.CallAs,
-	}
-
-	res, err := getClient().CreateStackInstances(context.Background(), input)
+	instanceConf.StackSetName = conf.StackSetName
+	instanceConf.CallAs = conf.CallAs
 
-	config.Debugf("CreateStackInstances API result:\n%s", format.PrettyPrint(res))
+	operationId, err := StartCreateStackSetInstances(instanceConf)
 	if err != nil {
 		return errors.New("error occurred durin stack set update")
 	}
 
 	spinner.Pause()
-	fmt.Printf("Submitted CREATE stack set instance(s) operation with ID: %s\n", *res.OperationId)
+	console.Logf("Submitted CREATE stack set instance(s) operation with ID: %s", operationId)
 	spinner.Resume()
 
+	if wait {
+		err = WaitUntilStackSetOperationCompleted(operationId, conf.StackSetName)
+	}
+	return err
+}
+
+func CreateStackSetInstances(conf StackSetInstancesConfig, wait bool) error {
+	operationId, err := StartCreateStackSetInstances(conf)
 	if err != nil {
+		console.Log("error occurred durin stack set instance(s) deployment")
 		return err
 	}
 
+	spinner.Pause()
+	console.Logf("Submitted CREATE instances operation with ID: %s", operationId)
+	spinner.Resume()
+
 	if wait {
-		err = WaitUntilStackSetOperationCompleted(*res.OperationId, conf.StackSetName)
+		err := WaitUntilStackSetOperationCompleted(operationId, conf.StackSetName)
+		if err != nil {
+			return err
+		}
 	}
+
 	return err
 }
 
-func CreateStackSetInstances(conf StackSetInstancesConfig, wait bool) error {
-
+// StartCreateStackSetInstances starts creating stack set instances
+// and returns the ID of the operation without waiting for it
+func StartCreateStackSetInstances(conf StackSetInstancesConfig) (string, error) {
 	input := &cloudformation.CreateStackInstancesInput{
 		StackSetName:         &conf.StackSetName,
 		Regions:              conf.Regions,
@@ -375,35 +446,62 @@ func CreateStackSetInstances(conf StackSetInstancesConfig, wait bool) error {
 		OperationPreferences: conf.OperationPreferences,
 	}
 
-	res, err := getClient().CreateStackInstances(context.Background(), input)
+	var res *cloudformation.CreateStackInstancesOutput
+	err := queueStackSetOperation(conf.StackSetName, conf.CallAs, func() error {
+		var err error
+		res, err = getClient().CreateStackInstances(interrupt.Context(), input)
+		return err
+	})
 	config.Debugf("Create stack instances API result:\n%s", format.PrettyPrint(res))
 	if err != nil {
-		fmt.Println("error occurred durin stack set instance(s) deployment ")
-		return err
+		return "", err
 	}
 
-	spinner.Pause()
-	fmt.Printf("Submitted CREATE instances operation with ID: %s\n", *res.OperationId)
-	spinner.Resume()
+	return ptr.ToString(res.OperationId), nil
+}
 
-	if wait {
-		err := WaitUntilStackSetOperationCompleted(*res.OperationId, conf.StackSetName)
-		if err != nil {
-			return err
-		}
+// GetStackSetOperation returns the current state of a stack set operation
+func GetStackSetOperation(stackSetName string, operationId string, delegatedAdmin bool) (*types.StackSetOperation, error) {
+	res, err := getClient().DescribeStackSetOperation(interrupt.Context(), &cloudformation.DescribeStackSetOperationInput{
+		OperationId:  &operationId,
+		StackSetName: &stackSetName,
+		CallAs:       callAs(delegatedAdmin),
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return err
+	return res.StackSetOperation, nil
 }
 
+// WaitUntilStackSetOperationCompleted waits for a stack set operation to finish,
+// showing how many of its instances have succeeded, are running and have failed.
+// It returns an error if the operation failed or was stopped.
 func WaitUntilStackSetOperationCompleted(operationId string, stacksetName string) error {
 	var operation *cloudformation.DescribeStackSetOperationOutput
+	var results []types.StackSetOperationResultSummary
 	var err error
+
+	progress := false
+	defer func() {
+		if progress {
+			spinner.Pop()
+		}
+	}()
+
 	for {
-		operation, err = getClient().DescribeStackSetOperation(context.Background(), &cloudformation.DescribeStackSetOperationInput{
+		operation, err = getClient().DescribeStackSetOperation(interrupt.Context(), &cloudformation.DescribeStackSetOperationInput{
 			OperationId:  &operationId,
 			StackSetName: &stacksetName,
 		})
+
+		// The results are only used to show progress, so the operation can still be waited for without them
+		var listErr error
+		results, listErr = ListStackSetOperationResults(stacksetName, operationId)
+		if listErr != nil {
+			config.Debugf("unable to list results of stack set operation '%s': %v", operationId, listErr)
+		}
+
 		if err != nil || operation == nil ||
 			operation.StackSetOperation.Status == types.StackSetOperationStatusStopped ||
 			operation.StackSetOperation.Status == types.StackSetOperationStatusSucceeded ||
@@ -411,12 +509,68 @@ func WaitUntilStackSetOperationCompleted(operationId string, stacksetName string
 			break
 		}
 
-		time.Sleep(time.Second * WaitPeriodInSeconds)
+		if len(results) > 0 {
+			if progress {
+				spinner.Pop()
+			}
+			spinner.Push(SummarizeOperationResults(results))
+			progress = true
+		}
+
+		if err := interrupt.Sleep(time.Second * WaitPeriodInSeconds); err != nil {
+			return err
+		}
 	}
-	if err == nil && operation != nil {
-		spinner.Pause()
-		fmt.Printf("Stack set operation resulted with state: %s\n", operation.StackSetOperation.Status)
-		spinner.Resume()
+
+	if err != nil || operation == nil {
+		return err
 	}
-	return err
+
+	status := operation.StackSetOperation.Status
+
+	spinner.Pause()
+	console.Logf("Stack set operation resulted with state: %s", status)
+	if len(results) > 0 {
+		console.Log(SummarizeOperationResults(results))
+	}
+	failed := FailedOperationResults(results)
+	for _, f := range failed {
+		console.Logf("  - %s", f)
+	}
+	spinner.Resume()
+
+	if status == types.StackSetOperationStatusFailed || status == types.StackSetOperationStatusStopped {
+		return fmt.Errorf("stack set operation '%s' %s with %d failed instances",
+			operationId, strings.ToLower(string(status)), len(failed))
+	}
+
+	return nil
+}
+
+// ListStackSetOperationResults returns the result of a stack set operation in each account and region
+func ListStackSetOperationResults(stackSetName string, operationId string) ([]types.StackSetOperationResultSummary, error) {
+	results := make([]types.StackSetOperationResultSummary, 0)
+	var token *string
+
+	for {
+		res, err := getClient().ListStackSetOperationResults(interrupt.Context(), &cloudformation.ListStackSetOperationResultsInput{
+			NextToken:    token,
+			OperationId:  &operationId,
+			StackSetName: &stackSetName,
+		})
+
+		if err != nil {
+			return results, err
+		}
+
+		results = append(results, res.Summaries...)
+
+		if res.NextToken == nil {
+			break
+		}
+
+		token = res.NextToken
+	}
+
+	return results, nil
 }
@@ -0,0 +1,59 @@
+package cfn
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// TransformTemplate runs the template's transforms, such as AWS::Serverless-2016-10-31,
+// and returns the template that CloudFormation would deploy.
+// It creates a change set for a temporary stack, reads the processed template from it,
+// and then deletes the temporary stack; no resources are created.
+// params must set the template's parameters that don't have defaults.
+// A template without a Transform section is returned unchanged.
+func TransformTemplate(template cft.Template, params map[string]string) (cft.Template, error) {
+	if _, err := template.GetSection(cft.Transform); err != nil {
+		return template, nil
+	}
+
+	parameters := make([]types.Parameter, 0, len(params))
+	for k, v := range params {
+		parameters = append(parameters, types.Parameter{
+			ParameterKey:   ptr.String(k),
+			ParameterValue: ptr.String(v),
+		})
+	}
+
+	stackName := fmt.Sprintf("rain-transform-%d", time.Now().UnixNano())
+
+	// The change set leaves a stack in REVIEW_IN_PROGRESS, even if it fails
+	defer func() {
+		if err := DeleteStack(stackName, ""); err != nil {
+			config.Debugf("unable to delete temporary stack %s: %s", stackName, err)
+		}
+	}()
+
+	changeSetName, err := CreateChangeSet(template, parameters, nil, stackName, "", ChangeSetOptions{})
+	if err != nil {
+		return cft.Template{}, fmt.Errorf("unable to transform the template: %w", err)
+	}
+
+	res, err := getClient().GetTemplate(interrupt.Context(), &cloudformation.GetTemplateInput{
+		StackName:     ptr.String(stackName),
+		ChangeSetName: ptr.String(changeSetName),
+		TemplateStage: types.TemplateStageProcessed,
+	})
+	if err != nil {
+		return cft.Template{}, err
+	}
+
+	return parse.String(ptr.ToString(res.TemplateBody))
+}
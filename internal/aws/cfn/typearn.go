@@ -0,0 +1,64 @@
+package cfn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// IsTypeArn returns true if name is the ARN of a registry type rather than a type name, e.g.
+// arn:aws:cloudformation:us-east-1:123456789012:type/resource/MyOrg-Networking-VPC/00000003
+func IsTypeArn(name string) bool {
+	return strings.HasPrefix(name, "arn:") && strings.Contains(name, ":type/resource/")
+}
+
+// ParseTypeArn returns the type name and version of a registry type ARN.
+// The version is "" if the ARN refers to the type's default version.
+func ParseTypeArn(arn string) (string, string, error) {
+	_, resource, found := strings.Cut(arn, ":type/resource/")
+	if !strings.HasPrefix(arn, "arn:") || !found {
+		return "", "", fmt.Errorf("'%s' is not the ARN of a resource type", arn)
+	}
+
+	name, version, _ := strings.Cut(resource, "/")
+
+	parts := strings.Split(name, "-")
+	if len(parts) != 3 {
+		return "", "", fmt.Errorf("'%s' is not the ARN of a resource type", arn)
+	}
+
+	for _, part := range parts {
+		if part == "" {
+			return "", "", fmt.Errorf("'%s' is not the ARN of a resource type", arn)
+		}
+	}
+
+	return strings.Join(parts, "::"), version, nil
+}
+
+// TypeName returns the type name of a type name or registry type ARN
+func TypeName(name string) string {
+	if !IsTypeArn(name) {
+		return name
+	}
+
+	typeName, _, err := ParseTypeArn(name)
+	if err != nil {
+		return name
+	}
+
+	return typeName
+}
+
+// schemaCacheName returns the name of the cache file for a type name or registry type ARN
+func schemaCacheName(name string) string {
+	if !IsTypeArn(name) {
+		return schemaFileName(name)
+	}
+
+	typeName, version, err := ParseTypeArn(name)
+	if err != nil || version == "" {
+		return schemaFileName(typeName)
+	}
+
+	return schemaFileName(typeName + "::" + version)
+}
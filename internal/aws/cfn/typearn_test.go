@@ -0,0 +1,43 @@
+package cfn
+
+import "testing"
+
+func TestParseTypeArn(t *testing.T) {
+	cases := map[string][2]string{
+		"arn:aws:cloudformation:us-east-1:123456789012:type/resource/MyOrg-Networking-VPC":          {"MyOrg::Networking::VPC", ""},
+		"arn:aws:cloudformation:us-east-1:123456789012:type/resource/MyOrg-Networking-VPC/00000003": {"MyOrg::Networking::VPC", "00000003"},
+	}
+
+	for arn, expected := range cases {
+		if !IsTypeArn(arn) {
+			t.Errorf("expected %s to be a type ARN", arn)
+		}
+
+		name, version, err := ParseTypeArn(arn)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if name != expected[0] || version != expected[1] {
+			t.Errorf("%s: expected %v, got %s %s", arn, expected, name, version)
+		}
+	}
+
+	if TypeName("AWS::S3::Bucket") != "AWS::S3::Bucket" || IsTypeArn("AWS::S3::Bucket") {
+		t.Error("expected a type name to be unchanged")
+	}
+
+	if schemaCacheName("arn:aws:cloudformation:us-east-1:123456789012:type/resource/MyOrg-Networking-VPC/00000003") != "myorg-networking-vpc-00000003.json" {
+		t.Error("expected each version to be cached separately")
+	}
+
+	for _, arn := range []string{
+		"arn:aws:cloudformation:us-east-1:123456789012:type/resource/MyOrg-VPC",
+		"arn:aws:cloudformation:us-east-1:123456789012:type/resource/MyOrg--VPC",
+		"arn:aws:s3:::bucket",
+	} {
+		if _, _, err := ParseTypeArn(arn); err == nil {
+			t.Errorf("expected an error for %s", arn)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Package cloudtrail looks up recent API errors in CloudTrail
+package cloudtrail
+
+import (
+	"encoding/json"
+	"time"
+
+	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// maxPages limits how many pages of events are read,
+// since LookupEvents only allows two calls per second
+const maxPages = 10
+
+// Error is a failed API call recorded by CloudTrail
+type Error struct {
+	Time    time.Time `json:"time"`
+	Source  string    `json:"source"`
+	Name    string    `json:"name"`
+	User    string    `json:"user,omitempty"`
+	Code    string    `json:"code"`
+	Message string    `json:"message,omitempty"`
+}
+
+// record is the part of a CloudTrail record that rain needs
+type record struct {
+	ErrorCode    string `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+}
+
+// parseError returns the error in a CloudTrail record, and false if the call succeeded
+func parseError(event string) (record, bool) {
+	var r record
+	if err := json.Unmarshal([]byte(event), &r); err != nil {
+		return r, false
+	}
+
+	return r, r.ErrorCode != ""
+}
+
+// LookupErrors returns the write calls that failed between start and end, oldest first
+func LookupErrors(start, end time.Time) ([]Error, error) {
+	client := cloudtrail.NewFromConfig(rainaws.Config())
+
+	paginator := cloudtrail.NewLookupEventsPaginator(client, &cloudtrail.LookupEventsInput{
+		LookupAttributes: []types.LookupAttribute{{
+			AttributeKey:   types.LookupAttributeKeyReadOnly,
+			AttributeValue: ptr.String("false"),
+		}},
+		StartTime:  ptr.Time(start),
+		EndTime:    ptr.Time(end),
+		MaxResults: ptr.Int32(50),
+	})
+
+	out := make([]Error, 0)
+
+	for page := 0; page < maxPages && paginator.HasMorePages(); page++ {
+		output, err := paginator.NextPage(interrupt.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range output.Events {
+			r, failed := parseError(ptr.ToString(e.CloudTrailEvent))
+			if !failed {
+				continue
+			}
+
+			// Events are returned newest first
+			out = append([]Error{{
+				Time:    ptr.ToTime(e.EventTime),
+				Source:  ptr.ToString(e.EventSource),
+				Name:    ptr.ToString(e.EventName),
+				User:    ptr.ToString(e.Username),
+				Code:    r.ErrorCode,
+				Message: r.ErrorMessage,
+			}}, out...)
+		}
+	}
+
+	return out, nil
+}
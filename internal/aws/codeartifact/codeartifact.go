@@ -4,7 +4,6 @@ package codeartifact
 import (
 	"archive/zip"
 	"bytes"
-	"context"
 	"crypto/sha256"
 	"errors"
 	"fmt"
@@ -20,6 +19,7 @@ import (
 
 	rainaws "github.com/aws-cloudformation/rain/internal/aws"
 	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/service/codeartifact"
 	"github.com/aws/smithy-go"
 )
@@ -31,7 +31,7 @@ func getClient() *codeartifact.Client {
 // DomainExists checks if a domain exists
 func DomainExists(name string) (bool, error) {
 	client := getClient()
-	res, err := client.DescribeDomain(context.Background(),
+	res, err := client.DescribeDomain(interrupt.Context(),
 		&codeartifact.DescribeDomainInput{Domain: &name})
 	if err != nil {
 		// Check to see if this is a ResourceNotFoundException
@@ -51,7 +51,7 @@ func DomainExists(name string) (bool, error) {
 // CreateDomain creates a domain
 func CreateDomain(name string) error {
 	client := getClient()
-	_, err := client.CreateDomain(context.Background(),
+	_, err := client.CreateDomain(interrupt.Context(),
 		&codeartifact.CreateDomainInput{Domain: &name})
 	return err
 }
@@ -59,7 +59,7 @@ func CreateDomain(name string) error {
 // RepoExists checks if a repo exists
 func RepoExists(name string, domain string) (bool, error) {
 	client := getClient()
-	res, err := client.DescribeRepository(context.Background(),
+	res, err := client.DescribeRepository(interrupt.Context(),
 		&codeartifact.DescribeRepositoryInput{Domain: &domain, Repository: &name})
 	if err != nil {
 		var ae smithy.APIError
@@ -78,7 +78,7 @@ func RepoExists(name string, domain string) (bool, error) {
 // CreateRepo creates a repo
 func CreateRepo(name string, domain string) error {
 	client := getClient()
-	_, err := client.CreateRepository(context.Background(),
+	_, err := client.CreateRepository(interrupt.Context(),
 		&codeartifact.CreateRepositoryInput{Domain: &domain, Repository: &name})
 	return err
 }
@@ -229,7 +229,7 @@ func GetLatestPackageVersion(packageInfo *PackageInfo) (string, error) {
 	client := getClient()
 
 	// Call the codeartifact api to get the current version of the package
-	res, err := client.ListPackageVersions(context.Background(),
+	res, err := client.ListPackageVersions(interrupt.Context(),
 		&codeartifact.ListPackageVersionsInput{
 			Domain:     &packageInfo.Domain,
 			Repository: &packageInfo.Repo,
@@ -332,7 +332,7 @@ func Publish(packageInfo *PackageInfo) error {
 	packageInfo.Version = newVersion
 
 	// Call the codeartifact api to publish the package version
-	res, err := client.PublishPackageVersion(context.Background(),
+	res, err := client.PublishPackageVersion(interrupt.Context(),
 		&codeartifact.PublishPackageVersionInput{
 			Domain:         aws.String(packageInfo.Domain),
 			Repository:     aws.String(packageInfo.Repo),
@@ -429,7 +429,7 @@ func GetAssetHashForPackage(packageInfo *PackageInfo) (string, error) {
 	client := getClient()
 
 	// Call the api to describe the package version, so we can verify the hash
-	assets, err := client.ListPackageVersionAssets(context.Background(),
+	assets, err := client.ListPackageVersionAssets(interrupt.Context(),
 		&codeartifact.ListPackageVersionAssetsInput{
 			Domain:         aws.String(packageInfo.Domain),
 			Repository:     aws.String(packageInfo.Repo),
@@ -467,7 +467,7 @@ func GetAsset(packageInfo *PackageInfo) (io.Reader, error) {
 	client := getClient()
 
 	// Call the api to get the asset content
-	res, err := client.GetPackageVersionAsset(context.Background(),
+	res, err := client.GetPackageVersionAsset(interrupt.Context(),
 		&codeartifact.GetPackageVersionAssetInput{
 			Domain:         aws.String(packageInfo.Domain),
 			Repository:     aws.String(packageInfo.Repo),
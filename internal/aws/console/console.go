@@ -1,7 +1,6 @@
 package console
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -12,13 +11,14 @@ import (
 
 	"github.com/aws-cloudformation/rain/internal/aws"
 	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
 	"github.com/aws-cloudformation/rain/internal/aws/sts"
 	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/smithy-go/ptr"
 )
 
 var signinURI string
-var consoleURI string
 var signoutURI string
 
 const issuer = "https://aws-cloudformation.github.io/rain/rain_console.html"
@@ -44,7 +44,7 @@ func buildSessionString(sessionName string) (string, error) {
 
 	config.Debugf("sessionName: %v", sessionName)
 
-	creds, err := aws.NamedConfig(sessionName).Credentials.Retrieve(context.Background())
+	creds, err := aws.NamedConfig(sessionName).Credentials.Retrieve(interrupt.Context())
 	if err != nil {
 		return "", err
 	}
@@ -118,23 +118,21 @@ func getSigninToken(userName string) (string, error) {
 	return token, nil
 }
 
-// GetURI returns a sign-in uri for the current credentials and region
-func GetURI(logout bool, service, stackName, userName string) (string, error) {
-	config.Debugf("GetURI %v, %v, %v", service, stackName, userName)
+// GetURI returns a sign-in uri for the current credentials and region.
+// If logicalId is not empty, the console opens at that resource in the named stack.
+func GetURI(logout bool, service, stackName, logicalId, userName string) (string, error) {
+	config.Debugf("GetURI %v, %v, %v, %v", service, stackName, logicalId, userName)
 
 	region := aws.Config().Region
 
-	signinURI = "https://signin.aws.amazon.com/federation"
-	consoleURI = "https://console.aws.amazon.com"
-	signoutURI = "https://signin.aws.amazon.com/oauth?Action=logout&redirect_uri=https://aws.amazon.com"
-
-	// Different URIs for GovCloud
-	if strings.HasPrefix(region, "us-gov-") {
-		signinURI = "https://signin.amazonaws-us-gov.com/federation"
-		consoleURI = "https://console.amazonaws-us-gov.com"
-		signoutURI = "https://signin.amazonaws-us-gov.com/oauth?Action=logout&redirect_uri=https://amazonaws-us-gov.com"
+	p := partition.ForRegion(region)
+	if p.Signin == "" {
+		return "", fmt.Errorf("console sign-in is not available in region %s", region)
 	}
 
+	signinURI = p.Signin + "/federation"
+	signoutURI = fmt.Sprintf("%s/oauth?Action=logout&redirect_uri=%s", p.Signin, p.Home)
+
 	if logout {
 		return signoutURI, nil
 	}
@@ -151,18 +149,38 @@ func GetURI(logout bool, service, stackName, userName string) (string, error) {
 		service = defaultService
 	}
 
-	destination := fmt.Sprintf("%s/%s/home?region=%s", consoleURI, service, aws.Config().Region)
+	destination := ""
+
+	if stackName != "" && logicalId != "" {
+		resource, err := cfn.GetStackResource(stackName, logicalId)
+		if err != nil {
+			return "", err
+		}
+
+		destination = resourceURL(p, region,
+			ptr.ToString(resource.StackId),
+			ptr.ToString(resource.ResourceType),
+			logicalId,
+			ptr.ToString(resource.PhysicalResourceId),
+		)
+	}
+
+	fragment := ""
 
-	if service == defaultService && stackName != "" {
+	if destination == "" && service == defaultService && stackName != "" {
 		if stack, err := cfn.GetStack(stackName); err == nil {
 			if stack.StackId != nil {
-				destination += fmt.Sprintf("#/stacks/stackinfo?stackId=%s&hideStacks=false&viewNested=true",
+				fragment = fmt.Sprintf("/stacks/stackinfo?stackId=%s&hideStacks=false&viewNested=true",
 					ptr.ToString(stack.StackId),
 				)
 			}
 		}
 	}
 
+	if destination == "" {
+		destination = p.ConsoleURL(service, region, fragment)
+	}
+
 	return fmt.Sprintf("%s?Action=login&Issuer=%s&Destination=%s&SigninToken=%s",
 		signinURI,
 		url.QueryEscape(issuer),
@@ -170,3 +188,22 @@ func GetURI(logout bool, service, stackName, userName string) (string, error) {
 		url.QueryEscape(token),
 	), nil
 }
+
+// StackEventsURI returns a link to the events of a stack in the console,
+// using the region and partition in the stack's ARN.
+// Unlike GetURI, it does not sign in to the console.
+// It returns an empty string if the stack's partition has no public console.
+func StackEventsURI(stackId string) string {
+	// arn:partition:cloudformation:region:account:stack/name/id
+	parts := strings.SplitN(stackId, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+
+	p, err := partition.ForID(parts[1])
+	if err != nil {
+		return ""
+	}
+
+	return p.ConsoleURL(defaultService, parts[3], "/stacks/events?stackId="+url.QueryEscape(stackId))
+}
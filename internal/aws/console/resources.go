@@ -0,0 +1,90 @@
+package console
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
+)
+
+// resourcePages returns the path of a resource's page in the console,
+// relative to the console's base URL, given its physical id and region
+var resourcePages = map[string]func(id, region string) string{
+	"AWS::ApiGateway::RestApi": func(id, region string) string {
+		return fmt.Sprintf("apigateway/main/apis/%s/resources?api=%s&region=%s", id, id, region)
+	},
+	"AWS::CloudFormation::Stack": func(id, region string) string {
+		return fmt.Sprintf("cloudformation/home?region=%s#/stacks/stackinfo?stackId=%s", region, url.QueryEscape(id))
+	},
+	"AWS::CloudFront::Distribution": func(id, region string) string {
+		return fmt.Sprintf("cloudfront/v4/home#/distributions/%s", id)
+	},
+	"AWS::DynamoDB::Table": func(id, region string) string {
+		return fmt.Sprintf("dynamodbv2/home?region=%s#table?name=%s", region, url.QueryEscape(id))
+	},
+	"AWS::EC2::Instance": func(id, region string) string {
+		return fmt.Sprintf("ec2/home?region=%s#InstanceDetails:instanceId=%s", region, id)
+	},
+	"AWS::EC2::SecurityGroup": func(id, region string) string {
+		return fmt.Sprintf("ec2/home?region=%s#SecurityGroup:groupId=%s", region, id)
+	},
+	"AWS::EC2::Subnet": func(id, region string) string {
+		return fmt.Sprintf("vpcconsole/home?region=%s#SubnetDetails:subnetId=%s", region, id)
+	},
+	"AWS::EC2::VPC": func(id, region string) string {
+		return fmt.Sprintf("vpcconsole/home?region=%s#VpcDetails:VpcId=%s", region, id)
+	},
+	"AWS::ECS::Cluster": func(id, region string) string {
+		return fmt.Sprintf("ecs/v2/clusters/%s?region=%s", id, region)
+	},
+	"AWS::IAM::ManagedPolicy": func(id, region string) string {
+		return fmt.Sprintf("iam/home#/policies/details/%s", url.QueryEscape(id))
+	},
+	"AWS::IAM::Role": func(id, region string) string {
+		return fmt.Sprintf("iam/home#/roles/details/%s", id)
+	},
+	"AWS::IAM::User": func(id, region string) string {
+		return fmt.Sprintf("iam/home#/users/details/%s", id)
+	},
+	"AWS::KMS::Key": func(id, region string) string {
+		return fmt.Sprintf("kms/home?region=%s#/kms/keys/%s", region, id)
+	},
+	"AWS::Lambda::Function": func(id, region string) string {
+		return fmt.Sprintf("lambda/home?region=%s#/functions/%s", region, id)
+	},
+	"AWS::Logs::LogGroup": func(id, region string) string {
+		// CloudWatch escapes the escape characters in log group names
+		return fmt.Sprintf("cloudwatch/home?region=%s#logsV2:log-groups/log-group/%s",
+			region, strings.ReplaceAll(url.QueryEscape(id), "%", "$25"))
+	},
+	"AWS::RDS::DBInstance": func(id, region string) string {
+		return fmt.Sprintf("rds/home?region=%s#database:id=%s", region, id)
+	},
+	"AWS::S3::Bucket": func(id, region string) string {
+		return fmt.Sprintf("s3/buckets/%s?region=%s", id, region)
+	},
+	"AWS::SecretsManager::Secret": func(id, region string) string {
+		return fmt.Sprintf("secretsmanager/secret?name=%s&region=%s", url.QueryEscape(id), region)
+	},
+	"AWS::SNS::Topic": func(id, region string) string {
+		return fmt.Sprintf("sns/v3/home?region=%s#/topic/%s", region, id)
+	},
+	"AWS::SQS::Queue": func(id, region string) string {
+		return fmt.Sprintf("sqs/v3/home?region=%s#/queues/%s", region, url.QueryEscape(id))
+	},
+	"AWS::StepFunctions::StateMachine": func(id, region string) string {
+		return fmt.Sprintf("states/home?region=%s#/statemachines/view/%s", region, url.QueryEscape(id))
+	},
+}
+
+// resourceURL returns a link to the resource's page in the console.
+// Resources without a page of their own are shown in the stack's list of resources.
+func resourceURL(p partition.Partition, region, stackId, typeName, logicalId, physicalId string) string {
+	if page, ok := resourcePages[typeName]; ok && physicalId != "" {
+		return fmt.Sprintf("%s/%s", p.Console, page(physicalId, region))
+	}
+
+	return p.ConsoleURL(defaultService, region, fmt.Sprintf("/stacks/resources?stackId=%s&filteringText=%s",
+		url.QueryEscape(stackId), url.QueryEscape(logicalId)))
+}
@@ -0,0 +1,44 @@
+package console
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
+)
+
+func TestResourceURL(t *testing.T) {
+	const stackId = "arn:aws:cloudformation:us-east-1:123456789012:stack/test/abc"
+
+	cases := []struct {
+		p          partition.Partition
+		region     string
+		typeName   string
+		logicalId  string
+		physicalId string
+		expected   string
+	}{
+		{
+			partition.Aws, "us-east-1", "AWS::S3::Bucket", "Bucket", "my-bucket",
+			"https://console.aws.amazon.com/s3/buckets/my-bucket?region=us-east-1",
+		},
+		{
+			partition.China, "cn-north-1", "AWS::Lambda::Function", "Function", "my-function",
+			"https://console.amazonaws.cn/lambda/home?region=cn-north-1#/functions/my-function",
+		},
+		{
+			partition.Aws, "us-east-1", "AWS::Logs::LogGroup", "Logs", "/aws/lambda/my-function",
+			"https://console.aws.amazon.com/cloudwatch/home?region=us-east-1#logsV2:log-groups/log-group/$252Faws$252Flambda$252Fmy-function",
+		},
+		{
+			partition.Aws, "us-east-1", "AWS::Some::Thing", "Thing", "thing-id",
+			"https://console.aws.amazon.com/cloudformation/home?region=us-east-1#/stacks/resources?stackId=arn%3Aaws%3Acloudformation%3Aus-east-1%3A123456789012%3Astack%2Ftest%2Fabc&filteringText=Thing",
+		},
+	}
+
+	for _, c := range cases {
+		actual := resourceURL(c.p, c.region, stackId, c.typeName, c.logicalId, c.physicalId)
+		if actual != c.expected {
+			t.Errorf("%s: expected %s, got %s", c.typeName, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/aws/sessioncache"
+	"github.com/aws-cloudformation/rain/internal/aws/sso"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+)
+
+// profileName returns the name of the AWS profile that rain uses
+func profileName() string {
+	if config.Profile != "" {
+		return config.Profile
+	}
+
+	return "default"
+}
+
+// sharedProfile returns the profile's settings from the AWS configuration files,
+// or false if credentials come from somewhere else, such as environment variables
+func sharedProfile(ctx context.Context) (awsconfig.SharedConfig, bool) {
+	profile, err := awsconfig.LoadSharedConfigProfile(ctx, profileName())
+	if err != nil {
+		config.Debugf("not using a shared profile: %s", err)
+		return profile, false
+	}
+
+	return profile, true
+}
+
+// ssoSession returns the IAM Identity Center session that the profile logs in with,
+// following source_profile if the profile assumes a role
+func ssoSession(profile *awsconfig.SharedConfig) (sso.Session, bool) {
+	for p := profile; p != nil; p = p.Source {
+		if p.SSOSession != nil {
+			return sso.Session{
+				Name:     p.SSOSession.Name,
+				StartURL: p.SSOSession.SSOStartURL,
+				Region:   p.SSOSession.SSORegion,
+			}, true
+		}
+
+		if p.SSOStartURL != "" {
+			return sso.Session{StartURL: p.SSOStartURL, Region: p.SSORegion}, true
+		}
+	}
+
+	return sso.Session{}, false
+}
+
+// cacheSession keeps the credentials of a profile that assumes a role on disk,
+// so that the role, and any MFA code it needs, is only asked for once per session.
+// The key includes the settings that the role is assumed with, so that changing them starts a new session.
+func cacheSession(cfg *aws.Config, profile awsconfig.SharedConfig, sessionName string) {
+	if profile.RoleARN == "" {
+		return
+	}
+
+	key := fmt.Sprintf("%s|%s|%s|%s|%s", profileName(), profile.RoleARN, profile.ExternalID, profile.MFASerial, sessionName)
+
+	cfg.Credentials = aws.NewCredentialsCache(sessioncache.Provider{
+		Key:      key,
+		Provider: cfg.Credentials,
+	})
+}
+
+// ssoLogin offers to log in to IAM Identity Center when the profile's session has expired
+func ssoLogin(ctx context.Context, s sso.Session) error {
+	if !console.CanAsk() {
+		return fmt.Errorf("the IAM Identity Center session for %s has expired; "+
+			"run rain in a terminal to log in, or run: aws sso login --profile %s", s.StartURL, profileName())
+	}
+
+	spinner.Pause()
+	defer spinner.Resume()
+
+	if !console.Confirm(true, fmt.Sprintf("Your IAM Identity Center session for %s has expired. Log in?", s.StartURL)) {
+		return errors.New("not logged in to IAM Identity Center")
+	}
+
+	_, err := sso.Login(ctx, s, func(uri, code string) {
+		console.Logf("Approve the login in your browser, checking that it shows the code %s", console.Bold(code))
+
+		if err := console.OpenURL(uri); err != nil {
+			console.Log("Open this URL in your browser: " + uri)
+		}
+
+		console.Log("Waiting for the login to be approved...")
+	})
+
+	return err
+}
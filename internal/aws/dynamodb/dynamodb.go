@@ -0,0 +1,110 @@
+// Package dynamodb makes the few DynamoDB calls that rain needs
+package dynamodb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// ErrConditionFailed is returned when the condition of a write is not met
+var ErrConditionFailed = errors.New("the conditional request failed")
+
+// Item is a DynamoDB item, e.g. {"Id": S("abc"), "Expires": N(1700000000)}
+type Item map[string]types.AttributeValue
+
+// S returns a string attribute value
+func S(s string) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: s}
+}
+
+// N returns a number attribute value
+func N(n int64) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: fmt.Sprint(n)}
+}
+
+// Table identifies a table by name, or by ARN if it is in another region
+type Table struct {
+	Name   string
+	Region string
+}
+
+// ParseTable returns the Table identified by nameOrArn
+func ParseTable(nameOrArn string) Table {
+	// arn:partition:dynamodb:region:account:table/name
+	parts := strings.SplitN(nameOrArn, ":", 6)
+	if len(parts) == 6 && parts[0] == "arn" && strings.HasPrefix(parts[5], "table/") {
+		return Table{Name: strings.TrimPrefix(parts[5], "table/"), Region: parts[3]}
+	}
+
+	return Table{Name: nameOrArn}
+}
+
+// getClient returns a client for the region that the table is in
+func getClient(table Table) *dynamodb.Client {
+	return dynamodb.NewFromConfig(rainaws.Config(), func(o *dynamodb.Options) {
+		if table.Region != "" {
+			o.Region = table.Region
+		}
+	})
+}
+
+// conditionFailed returns ErrConditionFailed if err says that the condition of a write was not met
+func conditionFailed(err error) error {
+	var ccf *types.ConditionalCheckFailedException
+	if errors.As(err, &ccf) {
+		return ErrConditionFailed
+	}
+
+	return err
+}
+
+// condition returns the condition expression, or nil if it is empty
+func condition(expression string) *string {
+	if expression == "" {
+		return nil
+	}
+
+	return ptr.String(expression)
+}
+
+// values returns the condition's values, or nil if there are none
+func values(v Item) map[string]types.AttributeValue {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return v
+}
+
+// PutItem writes item to the table if expression, which may be empty, is met.
+// It returns ErrConditionFailed if it is not.
+func PutItem(table Table, item Item, expression string, v Item) error {
+	_, err := getClient(table).PutItem(interrupt.Context(), &dynamodb.PutItemInput{
+		TableName:                 ptr.String(table.Name),
+		Item:                      item,
+		ConditionExpression:       condition(expression),
+		ExpressionAttributeValues: values(v),
+	})
+
+	return conditionFailed(err)
+}
+
+// DeleteItem deletes the item with the given key from the table if expression, which may be empty, is met.
+// It returns ErrConditionFailed if it is not.
+func DeleteItem(table Table, key Item, expression string, v Item) error {
+	_, err := getClient(table).DeleteItem(interrupt.Context(), &dynamodb.DeleteItemInput{
+		TableName:                 ptr.String(table.Name),
+		Key:                       key,
+		ConditionExpression:       condition(expression),
+		ExpressionAttributeValues: values(v),
+	})
+
+	return conditionFailed(err)
+}
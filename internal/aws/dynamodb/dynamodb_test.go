@@ -0,0 +1,20 @@
+package dynamodb_test
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/internal/aws/dynamodb"
+)
+
+func TestParseTable(t *testing.T) {
+	cases := map[string]dynamodb.Table{
+		"rain-budget": {Name: "rain-budget"},
+		"arn:aws:dynamodb:us-west-2:123456789012:table/rain-budget": {Name: "rain-budget", Region: "us-west-2"},
+	}
+
+	for input, expected := range cases {
+		if actual := dynamodb.ParseTable(input); actual != expected {
+			t.Errorf("%s: expected %v, got %v", input, expected, actual)
+		}
+	}
+}
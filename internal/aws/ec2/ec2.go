@@ -1,12 +1,12 @@
 package ec2
 
 import (
-	"context"
 	"fmt"
 	"sort"
 
 	rainaws "github.com/aws-cloudformation/rain/internal/aws"
 	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
@@ -19,7 +19,7 @@ func getClient() *ec2.Client {
 
 // GetRegions returns all region names as strings
 func GetRegions() ([]string, error) {
-	res, err := getClient().DescribeRegions(context.Background(), &ec2.DescribeRegionsInput{})
+	res, err := getClient().DescribeRegions(interrupt.Context(), &ec2.DescribeRegionsInput{})
 	if err != nil {
 		return nil, err
 	}
@@ -36,7 +36,7 @@ func GetRegions() ([]string, error) {
 
 // CheckKeyPairExists checks to see if a key pair exists by name
 func CheckKeyPairExists(name string) (bool, error) {
-	res, err := getClient().DescribeKeyPairs(context.Background(), &ec2.DescribeKeyPairsInput{
+	res, err := getClient().DescribeKeyPairs(interrupt.Context(), &ec2.DescribeKeyPairsInput{
 		KeyNames: []string{name},
 	})
 	if err != nil {
@@ -47,7 +47,7 @@ func CheckKeyPairExists(name string) (bool, error) {
 }
 
 func GetInstanceType(instanceType string) (*types.InstanceTypeInfo, error) {
-	res, err := getClient().DescribeInstanceTypes(context.Background(),
+	res, err := getClient().DescribeInstanceTypes(interrupt.Context(),
 		&ec2.DescribeInstanceTypesInput{
 			InstanceTypes: []types.InstanceType{types.InstanceType(instanceType)},
 		})
@@ -64,7 +64,7 @@ func GetInstanceType(instanceType string) (*types.InstanceTypeInfo, error) {
 }
 
 func GetImage(imageID string) (*types.Image, error) {
-	res, err := getClient().DescribeImages(context.Background(),
+	res, err := getClient().DescribeImages(interrupt.Context(),
 		&ec2.DescribeImagesInput{
 			ImageIds: []string{imageID},
 		})
@@ -107,7 +107,7 @@ func GetInstanceTypesForArchitecture(architecture string) ([]string, error) {
 			input.NextToken = nextToken
 		}
 
-		res, err := getClient().DescribeInstanceTypes(context.Background(), input)
+		res, err := getClient().DescribeInstanceTypes(interrupt.Context(), input)
 
 		if err != nil {
 			return nil, err
@@ -134,7 +134,7 @@ func GetInstanceTypesForArchitecture(architecture string) ([]string, error) {
 // GetDefaultVPCId returns the default VPC Id, or a blank string
 func GetDefaultVPCId() (string, error) {
 
-	output, err := getClient().DescribeVpcs(context.Background(), &ec2.DescribeVpcsInput{})
+	output, err := getClient().DescribeVpcs(interrupt.Context(), &ec2.DescribeVpcsInput{})
 	if err != nil {
 		return "", err
 	}
@@ -154,3 +154,45 @@ func GetDefaultVPCId() (string, error) {
 func init() {
 	typesByArchCache = make(map[string][]string)
 }
+
+// CountVPCs returns the number of VPCs in the region
+func CountVPCs() (int, error) {
+	count := 0
+
+	p := ec2.NewDescribeVpcsPaginator(getClient(), &ec2.DescribeVpcsInput{})
+	for p.HasMorePages() {
+		res, err := p.NextPage(interrupt.Context())
+		if err != nil {
+			return 0, err
+		}
+		count += len(res.Vpcs)
+	}
+
+	return count, nil
+}
+
+// CountInternetGateways returns the number of internet gateways in the region
+func CountInternetGateways() (int, error) {
+	count := 0
+
+	p := ec2.NewDescribeInternetGatewaysPaginator(getClient(), &ec2.DescribeInternetGatewaysInput{})
+	for p.HasMorePages() {
+		res, err := p.NextPage(interrupt.Context())
+		if err != nil {
+			return 0, err
+		}
+		count += len(res.InternetGateways)
+	}
+
+	return count, nil
+}
+
+// CountElasticIPs returns the number of Elastic IP addresses allocated in the region
+func CountElasticIPs() (int, error) {
+	res, err := getClient().DescribeAddresses(interrupt.Context(), &ec2.DescribeAddressesInput{})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(res.Addresses), nil
+}
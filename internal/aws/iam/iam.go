@@ -1,7 +1,6 @@
 package iam
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"net/url"
@@ -10,7 +9,9 @@ import (
 
 	"github.com/aws-cloudformation/rain/internal/aws"
 	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws-cloudformation/rain/internal/s11n"
 	awsgo "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/iam"
@@ -26,10 +27,10 @@ func getClient() *iam.Client {
 // GetCallerArn gets the role arn of the caller based on the aws config
 func GetCallerArn(config awsgo.Config) (string, error) {
 	stsClient := sts.NewFromConfig(config)
-	stsRes, stsErr := stsClient.GetCallerIdentity(context.Background(),
+	stsRes, stsErr := stsClient.GetCallerIdentity(interrupt.Context(),
 		&sts.GetCallerIdentityInput{})
 	if stsErr != nil {
-		fmt.Println("Unable to get caller identity", stsErr)
+		console.Logf("Unable to get caller identity %v", stsErr)
 		return "", stsErr
 	}
 	return TransformCallerArn(*stsRes.Arn), nil
@@ -50,11 +51,12 @@ func TransformCallerArn(stsResArn string) string {
 // Will this work consistently for other SSO providers?
 // Is there a programmatic way to retrieve the actual role?
 func convertAssumeRoleToRole(stsResArn string) string {
+	partitionId := strings.Split(stsResArn, ":")[1]
 	stsStr := strings.Split(stsResArn, "sts::")[1]
 	accountId := strings.Split(stsStr, ":")[0]
 	assumedRole := strings.Split(stsResArn, "assumed-role/")[1]
 	actualRoleName := strings.Split(assumedRole, "/")[0]
-	return fmt.Sprintf("arn:aws:iam::%v:role/%v", accountId, actualRoleName)
+	return fmt.Sprintf("arn:%v:iam::%v:role/%v", partitionId, accountId, actualRoleName)
 }
 
 // Simulate actions on a resource.
@@ -85,7 +87,7 @@ func Simulate(
 
 		spinnerCallback(action)
 
-		res, err := client.SimulatePrincipalPolicy(context.Background(), input)
+		res, err := client.SimulatePrincipalPolicy(interrupt.Context(), input)
 
 		spinner.Pop()
 
@@ -146,7 +148,7 @@ func RoleExists(roleArn string) bool {
 		config.Debugf("RoleExists GetRoleNameFromArn Error for %v: %v", roleArn, err)
 		return false
 	}
-	res, err := getClient().GetRole(context.Background(), &iam.GetRoleInput{
+	res, err := getClient().GetRole(interrupt.Context(), &iam.GetRoleInput{
 		RoleName: &roleName,
 	})
 	if err != nil {
@@ -183,14 +185,14 @@ func PrincipalExists(principal string) (bool, error) {
 		return true, nil
 	}
 
-	var rootRegex = regexp.MustCompile(`arn:aws:iam::\d{12}:root`)
+	var rootRegex = regexp.MustCompile(`arn:aws[a-z-]*:iam::\d{12}:root`)
 	if rootRegex.MatchString(principal) {
 		config.Debugf("PrincipalExists %v is an account root", principal)
 		// Assume that the account exists
 		return true, nil
 	}
 
-	var roleRegex = regexp.MustCompile(`arn:aws:iam::\d{12}:role/[a-zA-Z0-9_@=\\-]+`)
+	var roleRegex = regexp.MustCompile(`arn:aws[a-z-]*:iam::\d{12}:role/[a-zA-Z0-9_@=\\-]+`)
 	if roleRegex.MatchString(principal) {
 		config.Debugf("PrincipalExists %v is a role", principal)
 		if RoleExists(principal) {
@@ -246,7 +248,7 @@ func CanAssumeRole(roleArn string, serviceName string) (bool, error) {
 	config.Debugf("CanAssumeRole checking role %v for service %v", roleName, serviceName)
 
 	// Get the role details
-	roleOutput, err := getClient().GetRole(context.Background(),
+	roleOutput, err := getClient().GetRole(interrupt.Context(),
 		&iam.GetRoleInput{
 			RoleName: awsgo.String(roleName),
 		})
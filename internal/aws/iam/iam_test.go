@@ -9,6 +9,9 @@ func TestTransformCallerArn2(t *testing.T) {
 	if TransformCallerArn("arn:aws:iam::755952356119:user/khmoryz") != "arn:aws:iam::755952356119:user/khmoryz" {
 		t.Errorf("Failed to transform IAM user type arn")
 	}
+	if TransformCallerArn("arn:aws-cn:sts::755952356119:assumed-role/Admin/khmoryz") != "arn:aws-cn:iam::755952356119:role/Admin" {
+		t.Errorf("Failed to transform assume-role type arn in the aws-cn partition")
+	}
 }
 
 func TestGetRoleNameFromArn(t *testing.T) {
@@ -1,8 +1,8 @@
 package kms
 
 import (
-	"context"
 	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	"time"
 )
@@ -15,7 +15,7 @@ func IsKeyArnValid(keyArn string) bool {
 	client := getClient()
 
 	// Use the aws go sdk to call kms and make sure the key is valid
-	key, err := client.DescribeKey(context.Background(), &kms.DescribeKeyInput{KeyId: &keyArn})
+	key, err := client.DescribeKey(interrupt.Context(), &kms.DescribeKeyInput{KeyId: &keyArn})
 	if err != nil {
 		// Throws an error if it can't find the key
 		return false
@@ -1,10 +1,10 @@
 package lightsail
 
 import (
-	"context"
 	"errors"
 
 	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/service/lightsail"
 )
 
@@ -17,7 +17,7 @@ func GetBlueprints() ([]string, error) {
 	var nextPageToken *string
 	retval := make([]string, 0)
 	for sanity := 0; sanity < 10; sanity += 1 {
-		res, err := getClient().GetBlueprints(context.Background(),
+		res, err := getClient().GetBlueprints(interrupt.Context(),
 			&lightsail.GetBlueprintsInput{PageToken: nextPageToken})
 		if err != nil {
 			return nil, err
@@ -40,7 +40,7 @@ func GetBundles() ([]string, error) {
 	var nextPageToken *string
 	retval := make([]string, 0)
 	for sanity := 0; sanity < 10; sanity += 1 {
-		res, err := getClient().GetBundles(context.Background(),
+		res, err := getClient().GetBundles(interrupt.Context(),
 			&lightsail.GetBundlesInput{PageToken: nextPageToken})
 		if err != nil {
 			return nil, err
@@ -63,7 +63,7 @@ func GetRelationalDatabaseBlueprints() ([]string, error) {
 	var nextPageToken *string
 	retval := make([]string, 0)
 	for sanity := 0; sanity < 10; sanity += 1 {
-		res, err := getClient().GetRelationalDatabaseBlueprints(context.Background(),
+		res, err := getClient().GetRelationalDatabaseBlueprints(interrupt.Context(),
 			&lightsail.GetRelationalDatabaseBlueprintsInput{PageToken: nextPageToken})
 		if err != nil {
 			return nil, err
@@ -86,7 +86,7 @@ func GetRelationalDatabaseBundles() ([]string, error) {
 	var nextPageToken *string
 	retval := make([]string, 0)
 	for sanity := 0; sanity < 10; sanity += 1 {
-		res, err := getClient().GetRelationalDatabaseBundles(context.Background(),
+		res, err := getClient().GetRelationalDatabaseBundles(interrupt.Context(),
 			&lightsail.GetRelationalDatabaseBundlesInput{PageToken: nextPageToken})
 		if err != nil {
 			return nil, err
@@ -107,7 +107,7 @@ func GetRelationalDatabaseBundles() ([]string, error) {
 // GetBucketBundles gets all available lightsail bucket bundles in this region
 func GetBucketBundles() ([]string, error) {
 	retval := make([]string, 0)
-	res, err := getClient().GetBucketBundles(context.Background(),
+	res, err := getClient().GetBucketBundles(interrupt.Context(),
 		&lightsail.GetBucketBundlesInput{})
 	if err != nil {
 		return nil, err
@@ -123,7 +123,7 @@ func GetBucketBundles() ([]string, error) {
 // GetDistributionBundles gets all available lightsail distribution bundles in this region
 func GetDistributionBundles() ([]string, error) {
 	retval := make([]string, 0)
-	res, err := getClient().GetDistributionBundles(context.Background(),
+	res, err := getClient().GetDistributionBundles(interrupt.Context(),
 		&lightsail.GetDistributionBundlesInput{})
 	if err != nil {
 		return nil, err
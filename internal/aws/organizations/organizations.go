@@ -0,0 +1,120 @@
+// Package organizations lists the accounts in an AWS Organization and its organizational units,
+// and describes the organization that the current account belongs to.
+package organizations
+
+import (
+	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	"github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// Account is a member account of an organization
+type Account struct {
+	Id     string
+	Name   string
+	Status string
+}
+
+func getClient() *organizations.Client {
+	return organizations.NewFromConfig(rainaws.Config())
+}
+
+// activeAccounts returns the accounts that are active
+func activeAccounts(in []types.Account) []Account {
+	accounts := make([]Account, 0, len(in))
+
+	for _, a := range in {
+		if a.Status == types.AccountStatusActive {
+			accounts = append(accounts, Account{
+				Id:     ptr.ToString(a.Id),
+				Name:   ptr.ToString(a.Name),
+				Status: string(a.Status),
+			})
+		}
+	}
+
+	return accounts
+}
+
+// ListAccounts returns the active accounts in the organization.
+// It must be called from the management account or a delegated administrator.
+func ListAccounts() ([]Account, error) {
+	accounts := make([]Account, 0)
+
+	paginator := organizations.NewListAccountsPaginator(getClient(), &organizations.ListAccountsInput{})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(interrupt.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, activeAccounts(output.Accounts)...)
+	}
+
+	return accounts, nil
+}
+
+// ListAccountsInOU returns the active accounts in an organizational unit, or under the organization's root,
+// including the accounts in the organizational units inside it.
+// It must be called from the management account or a delegated administrator.
+func ListAccountsInOU(parentId string) ([]Account, error) {
+	client := getClient()
+	accounts := make([]Account, 0)
+
+	accountPages := organizations.NewListAccountsForParentPaginator(client, &organizations.ListAccountsForParentInput{
+		ParentId: ptr.String(parentId),
+	})
+	for accountPages.HasMorePages() {
+		output, err := accountPages.NextPage(interrupt.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, activeAccounts(output.Accounts)...)
+	}
+
+	ouPages := organizations.NewListOrganizationalUnitsForParentPaginator(client, &organizations.ListOrganizationalUnitsForParentInput{
+		ParentId: ptr.String(parentId),
+	})
+	for ouPages.HasMorePages() {
+		output, err := ouPages.NextPage(interrupt.Context())
+		if err != nil {
+			return nil, err
+		}
+
+		for _, ou := range output.OrganizationalUnits {
+			children, err := ListAccountsInOU(ptr.ToString(ou.Id))
+			if err != nil {
+				return nil, err
+			}
+
+			accounts = append(accounts, children...)
+		}
+	}
+
+	return accounts, nil
+}
+
+// Organization is the organization that the current account belongs to
+type Organization struct {
+	Id string
+
+	// MasterAccountId is the organization's management account
+	MasterAccountId string
+}
+
+// DescribeOrganization returns the organization that the current account belongs to.
+// Any account in an organization can call it.
+func DescribeOrganization() (Organization, error) {
+	output, err := getClient().DescribeOrganization(interrupt.Context(), &organizations.DescribeOrganizationInput{})
+	if err != nil {
+		return Organization{}, err
+	}
+
+	return Organization{
+		Id:              ptr.ToString(output.Organization.Id),
+		MasterAccountId: ptr.ToString(output.Organization.MasterAccountId),
+	}, nil
+}
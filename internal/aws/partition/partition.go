@@ -0,0 +1,130 @@
+// Package partition describes the AWS partitions (aws, aws-cn, aws-us-gov, and the isolated regions)
+// so that ARNs, endpoints, and console links can be built correctly for any region.
+package partition
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Partition is a group of AWS regions that share ARNs, endpoints, and a console
+type Partition struct {
+	// ID is the partition as it appears in ARNs, e.g. "aws-cn"
+	ID string
+
+	// URLSuffix is the domain of the partition's service endpoints, e.g. "amazonaws.com.cn"
+	URLSuffix string
+
+	// Console is the base URL of the partition's console,
+	// or empty if the console can't be reached from the internet
+	Console string
+
+	// Signin is the base URL of the partition's sign-in service,
+	// or empty if federated sign-in is not available
+	Signin string
+
+	// Home is the partition's public web site, where users are sent after signing out
+	Home string
+}
+
+// Aws is the standard partition
+var Aws = Partition{
+	ID:        "aws",
+	URLSuffix: "amazonaws.com",
+	Console:   "https://console.aws.amazon.com",
+	Signin:    "https://signin.aws.amazon.com",
+	Home:      "https://aws.amazon.com",
+}
+
+// China is the partition of the Beijing and Ningxia regions
+var China = Partition{
+	ID:        "aws-cn",
+	URLSuffix: "amazonaws.com.cn",
+	Console:   "https://console.amazonaws.cn",
+	Signin:    "https://signin.amazonaws.cn",
+	Home:      "https://www.amazonaws.cn",
+}
+
+// GovCloud is the partition of the AWS GovCloud (US) regions
+var GovCloud = Partition{
+	ID:        "aws-us-gov",
+	URLSuffix: "amazonaws.com",
+	Console:   "https://console.amazonaws-us-gov.com",
+	Signin:    "https://signin.amazonaws-us-gov.com",
+	Home:      "https://amazonaws-us-gov.com",
+}
+
+// Iso is the partition of the us-iso regions
+var Iso = Partition{
+	ID:        "aws-iso",
+	URLSuffix: "c2s.ic.gov",
+}
+
+// IsoB is the partition of the us-isob regions
+var IsoB = Partition{
+	ID:        "aws-iso-b",
+	URLSuffix: "sc2s.sgov.gov",
+}
+
+// ForRegion returns the partition that contains the region
+func ForRegion(region string) Partition {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return China
+	case strings.HasPrefix(region, "us-gov-"):
+		return GovCloud
+	case strings.HasPrefix(region, "us-isob-"):
+		return IsoB
+	case strings.HasPrefix(region, "us-iso-"):
+		return Iso
+	default:
+		return Aws
+	}
+}
+
+// ForID returns the partition with the given ID, e.g. from an ARN
+func ForID(id string) (Partition, error) {
+	for _, p := range []Partition{Aws, China, GovCloud, Iso, IsoB} {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+
+	return Partition{}, fmt.Errorf("unknown partition '%s'", id)
+}
+
+// ForArn returns the partition of the ARN
+func ForArn(arn string) (Partition, error) {
+	parts := strings.SplitN(arn, ":", 3)
+	if len(parts) < 3 || parts[0] != "arn" {
+		return Partition{}, fmt.Errorf("invalid ARN '%s'", arn)
+	}
+
+	return ForID(parts[1])
+}
+
+// Arn returns an ARN in the partition.
+// region and account can be empty for services that don't use them, like IAM and S3.
+func (p Partition) Arn(service, region, account, resource string) string {
+	return fmt.Sprintf("arn:%s:%s:%s:%s:%s", p.ID, service, region, account, resource)
+}
+
+// Endpoint returns the URL of a service's regional endpoint
+func (p Partition) Endpoint(service, region string) string {
+	return fmt.Sprintf("https://%s.%s.%s", service, region, p.URLSuffix)
+}
+
+// ConsoleURL returns a link to a page of a service's console in the region,
+// or an empty string if the partition's console can't be reached from the internet
+func (p Partition) ConsoleURL(service, region, fragment string) string {
+	if p.Console == "" {
+		return ""
+	}
+
+	out := fmt.Sprintf("%s/%s/home?region=%s", p.Console, service, region)
+	if fragment != "" {
+		out += "#" + fragment
+	}
+
+	return out
+}
@@ -0,0 +1,87 @@
+package partition_test
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
+)
+
+func TestForRegion(t *testing.T) {
+	cases := map[string]string{
+		"us-east-1":      "aws",
+		"eu-west-2":      "aws",
+		"cn-north-1":     "aws-cn",
+		"cn-northwest-1": "aws-cn",
+		"us-gov-west-1":  "aws-us-gov",
+		"us-iso-east-1":  "aws-iso",
+		"us-isob-east-1": "aws-iso-b",
+	}
+
+	for region, expected := range cases {
+		if actual := partition.ForRegion(region).ID; actual != expected {
+			t.Errorf("%s: expected %s, got %s", region, expected, actual)
+		}
+	}
+}
+
+func TestForArn(t *testing.T) {
+	p, err := partition.ForArn("arn:aws-us-gov:cloudformation:us-gov-west-1:123456789012:stack/test/abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p != partition.GovCloud {
+		t.Errorf("expected GovCloud, got %v", p)
+	}
+
+	if _, err := partition.ForArn("not-an-arn"); err == nil {
+		t.Error("expected an error for an invalid ARN")
+	}
+
+	if _, err := partition.ForArn("arn:aws-moon:s3:::bucket"); err == nil {
+		t.Error("expected an error for an unknown partition")
+	}
+}
+
+func TestArn(t *testing.T) {
+	actual := partition.China.Arn("iam", "", "123456789012", "role/Admin")
+	expected := "arn:aws-cn:iam::123456789012:role/Admin"
+
+	if actual != expected {
+		t.Errorf("expected %s, got %s", expected, actual)
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	cases := map[string]string{
+		"us-east-1":     "https://dynamodb.us-east-1.amazonaws.com",
+		"cn-north-1":    "https://dynamodb.cn-north-1.amazonaws.com.cn",
+		"us-gov-east-1": "https://dynamodb.us-gov-east-1.amazonaws.com",
+		"us-iso-east-1": "https://dynamodb.us-iso-east-1.c2s.ic.gov",
+	}
+
+	for region, expected := range cases {
+		if actual := partition.ForRegion(region).Endpoint("dynamodb", region); actual != expected {
+			t.Errorf("%s: expected %s, got %s", region, expected, actual)
+		}
+	}
+}
+
+func TestConsoleURL(t *testing.T) {
+	cases := []struct {
+		region   string
+		expected string
+	}{
+		{"us-east-1", "https://console.aws.amazon.com/cloudformation/home?region=us-east-1#/stacks"},
+		{"cn-north-1", "https://console.amazonaws.cn/cloudformation/home?region=cn-north-1#/stacks"},
+		{"us-gov-west-1", "https://console.amazonaws-us-gov.com/cloudformation/home?region=us-gov-west-1#/stacks"},
+		{"us-iso-east-1", ""},
+	}
+
+	for _, c := range cases {
+		actual := partition.ForRegion(c.region).ConsoleURL("cloudformation", c.region, "/stacks")
+		if actual != c.expected {
+			t.Errorf("%s: expected '%s', got '%s'", c.region, c.expected, actual)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Package pricing looks up on-demand prices with the AWS Pricing API
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+
+	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/pricing"
+	"github.com/aws/aws-sdk-go-v2/service/pricing/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// The Pricing API is only available in a few regions, but it
+// returns prices for every region, so rain always calls us-east-1
+const region = "us-east-1"
+
+// priceListItem is the part of a price list item that rain needs.
+// The Pricing API returns each item as a JSON document in a string.
+type priceListItem struct {
+	Terms struct {
+		OnDemand map[string]struct {
+			PriceDimensions map[string]struct {
+				Unit         string
+				BeginRange   string
+				PricePerUnit map[string]string
+			}
+		}
+	}
+}
+
+// onDemandPrice returns the USD price of the first tier of the item's
+// on-demand price dimension that is measured in unit
+func onDemandPrice(item string, unit string) (float64, bool, error) {
+	var p priceListItem
+	if err := json.Unmarshal([]byte(item), &p); err != nil {
+		return 0, false, err
+	}
+
+	for _, term := range p.Terms.OnDemand {
+		for _, dim := range term.PriceDimensions {
+			if dim.Unit != unit || (dim.BeginRange != "" && dim.BeginRange != "0") {
+				continue
+			}
+
+			usd, ok := dim.PricePerUnit["USD"]
+			if !ok {
+				continue
+			}
+
+			price, err := strconv.ParseFloat(usd, 64)
+			if err != nil {
+				return 0, false, err
+			}
+
+			return price, true, nil
+		}
+	}
+
+	return 0, false, nil
+}
+
+// GetPrice returns the on-demand USD price per unit of the product of serviceCode
+// that matches every field in filters, e.g. {"instanceType": "t3.micro"}.
+// If more than one product matches, the lowest price is returned.
+func GetPrice(serviceCode string, filters map[string]string, unit string) (float64, error) {
+	input := &pricing.GetProductsInput{
+		ServiceCode:   ptr.String(serviceCode),
+		FormatVersion: ptr.String("aws_v1"),
+		MaxResults:    ptr.Int32(100),
+	}
+
+	// Sort the fields so that requests are the same from run to run
+	fields := make([]string, 0, len(filters))
+	for field := range filters {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		input.Filters = append(input.Filters, types.Filter{
+			Type:  types.FilterTypeTermMatch,
+			Field: ptr.String(field),
+			Value: ptr.String(filters[field]),
+		})
+	}
+
+	client := pricing.NewFromConfig(rainaws.Config(), func(o *pricing.Options) {
+		o.Region = region
+	})
+
+	prices := make([]float64, 0)
+
+	paginator := pricing.NewGetProductsPaginator(client, input)
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(interrupt.Context())
+		if err != nil {
+			return 0, err
+		}
+
+		for _, item := range output.PriceList {
+			price, ok, err := onDemandPrice(item, unit)
+			if err != nil {
+				return 0, err
+			}
+
+			if ok {
+				prices = append(prices, price)
+			}
+		}
+	}
+
+	if len(prices) == 0 {
+		return 0, fmt.Errorf("no %s price found for %s %v", unit, serviceCode, filters)
+	}
+
+	sort.Float64s(prices)
+
+	return prices[0], nil
+}
@@ -0,0 +1,39 @@
+package pricing
+
+import "testing"
+
+func TestOnDemandPrice(t *testing.T) {
+	item := `{
+		"product": {"attributes": {"instanceType": "t3.micro"}},
+		"terms": {
+			"OnDemand": {
+				"ABC.JRTCKXETXF": {
+					"priceDimensions": {
+						"ABC.JRTCKXETXF.6YS6EN2CT7": {
+							"unit": "Hrs",
+							"beginRange": "0",
+							"endRange": "Inf",
+							"pricePerUnit": {"USD": "0.0104000000"}
+						}
+					}
+				}
+			}
+		}
+	}`
+
+	price, ok, err := onDemandPrice(item, "Hrs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok || price != 0.0104 {
+		t.Errorf("expected 0.0104, got %v (%v)", price, ok)
+	}
+
+	_, ok, err = onDemandPrice(item, "GB-Mo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("expected no GB-Mo price")
+	}
+}
@@ -1,9 +1,8 @@
 package rds
 
 import (
-	"context"
-
 	aws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/service/rds"
 )
 
@@ -14,7 +13,7 @@ func getClient() *rds.Client {
 func GetValidEngineVersions(engine string) ([]string, error) {
 	retval := make([]string, 0)
 
-	res, err := getClient().DescribeDBEngineVersions(context.Background(),
+	res, err := getClient().DescribeDBEngineVersions(interrupt.Context(),
 		&rds.DescribeDBEngineVersionsInput{Engine: &engine})
 
 	if err != nil {
@@ -33,7 +32,7 @@ func GetValidEngineVersions(engine string) ([]string, error) {
 }
 
 func GetNumClusters() (int, error) {
-	res, err := getClient().DescribeDBClusters(context.Background(),
+	res, err := getClient().DescribeDBClusters(interrupt.Context(),
 		&rds.DescribeDBClustersInput{})
 	if err != nil {
 		return -1, err
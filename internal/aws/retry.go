@@ -0,0 +1,79 @@
+package aws
+
+import (
+	"context"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/ratelimit"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	smithymiddleware "github.com/aws/smithy-go/middleware"
+)
+
+// limiter spaces out rain's AWS calls if config.RateLimit is set
+var limiter *ratelimit.Limiter
+
+// configureRetries sets how the clients made from cfg retry calls, and limits the rate of calls.
+// Every client shares one retryer, so that when a call is throttled,
+// the rest of rain's calls slow down too, rather than each client finding out for itself.
+func configureRetries(cfg *aws.Config) {
+	mode := cfg.RetryMode
+	if config.RetryMode != "" {
+		m, err := aws.ParseRetryMode(config.RetryMode)
+		if err != nil {
+			panic(ui.Errorf(err, "unknown retry mode '%s'; use standard or adaptive", config.RetryMode))
+		}
+		mode = m
+	}
+	if mode == "" {
+		mode = aws.RetryModeAdaptive
+	}
+
+	maxAttempts := cfg.RetryMaxAttempts
+	if config.MaxRetries > 0 {
+		maxAttempts = config.MaxRetries + 1
+	}
+
+	retryer := newRetryer(mode, maxAttempts)
+	cfg.Retryer = func() aws.Retryer {
+		return retryer
+	}
+
+	limiter = ratelimit.New(config.RateLimit)
+	if limiter != nil {
+		cfg.APIOptions = append(cfg.APIOptions, addRateLimit)
+	}
+}
+
+// newRetryer returns a retryer for mode. If maxAttempts is 0, the SDK's default is used.
+func newRetryer(mode aws.RetryMode, maxAttempts int) aws.Retryer {
+	attempts := func(o *retry.StandardOptions) {
+		if maxAttempts > 0 {
+			o.MaxAttempts = maxAttempts
+		}
+	}
+
+	if mode == aws.RetryModeStandard {
+		return retry.NewStandard(attempts)
+	}
+
+	return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+		o.StandardOptions = append(o.StandardOptions, attempts)
+	})
+}
+
+// addRateLimit waits for the limiter before each call.
+// Retries are not limited, as the retryer already spaces them out.
+func addRateLimit(stack *smithymiddleware.Stack) error {
+	return stack.Initialize.Add(smithymiddleware.InitializeMiddlewareFunc("RainRateLimit",
+		func(ctx context.Context, in smithymiddleware.InitializeInput, next smithymiddleware.InitializeHandler) (
+			smithymiddleware.InitializeOutput, smithymiddleware.Metadata, error) {
+
+			if err := limiter.Wait(ctx); err != nil {
+				return smithymiddleware.InitializeOutput{}, smithymiddleware.Metadata{}, err
+			}
+
+			return next.HandleInitialize(ctx, in)
+		}), smithymiddleware.Before)
+}
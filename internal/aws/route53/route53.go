@@ -0,0 +1,71 @@
+// Package route53 changes DNS records in Amazon Route 53
+package route53
+
+import (
+	"fmt"
+	"strings"
+
+	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	"github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// UpsertRecord creates the record in the hosted zone with a single value, or replaces it if it exists.
+// Route 53 applies the change to its DNS servers within about a minute.
+func UpsertRecord(hostedZoneID, name, recordType string, ttl int64, value, comment string) error {
+	client := route53.NewFromConfig(rainaws.Config())
+
+	// Hosted zone IDs are sometimes written with the /hostedzone/ prefix that the API returns
+	id := strings.TrimPrefix(hostedZoneID, "/hostedzone/")
+
+	batch := &types.ChangeBatch{
+		Changes: []types.Change{{
+			Action: types.ChangeActionUpsert,
+			ResourceRecordSet: &types.ResourceRecordSet{
+				Name:            ptr.String(name),
+				Type:            types.RRType(recordType),
+				TTL:             ptr.Int64(ttl),
+				ResourceRecords: []types.ResourceRecord{{Value: ptr.String(value)}},
+			},
+		}},
+	}
+	if comment != "" {
+		batch.Comment = ptr.String(comment)
+	}
+
+	_, err := client.ChangeResourceRecordSets(interrupt.Context(), &route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: ptr.String(id),
+		ChangeBatch:  batch,
+	})
+
+	return err
+}
+
+// GetRecordValue returns the first value of the record in the hosted zone,
+// or an error if the zone doesn't have a record with that name and type
+func GetRecordValue(hostedZoneID, name, recordType string) (string, error) {
+	client := route53.NewFromConfig(rainaws.Config())
+
+	res, err := client.ListResourceRecordSets(interrupt.Context(), &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    ptr.String(strings.TrimPrefix(hostedZoneID, "/hostedzone/")),
+		StartRecordName: ptr.String(name),
+		StartRecordType: types.RRType(recordType),
+		MaxItems:        ptr.Int32(1),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Records are listed from the start name onwards, so the first one may be a different record.
+	// Route 53 returns names with a trailing dot.
+	for _, r := range res.ResourceRecordSets {
+		if strings.TrimSuffix(ptr.ToString(r.Name), ".") == strings.TrimSuffix(name, ".") &&
+			string(r.Type) == recordType && len(r.ResourceRecords) > 0 {
+			return ptr.ToString(r.ResourceRecords[0].Value), nil
+		}
+	}
+
+	return "", fmt.Errorf("%s record '%s' was not found", recordType, name)
+}
@@ -3,7 +3,6 @@ package s3
 import (
 	"archive/zip"
 	"bytes"
-	"context"
 	"crypto/sha256"
 	"errors"
 	"fmt"
@@ -12,6 +11,7 @@ import (
 	"time"
 
 	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
@@ -22,6 +22,7 @@ import (
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 )
 
 var BucketName = ""
@@ -34,7 +35,7 @@ func getClient() *s3.Client {
 // BucketHasContents returns true if the bucket is not empty
 func BucketHasContents(bucketName string) (bool, error) {
 
-	res, err := getClient().ListObjectVersions(context.Background(),
+	res, err := getClient().ListObjectVersions(interrupt.Context(),
 		&s3.ListObjectVersionsInput{
 			Bucket: ptr.String(bucketName),
 		})
@@ -49,7 +50,7 @@ func BucketHasContents(bucketName string) (bool, error) {
 
 // BucketExists checks whether the named bucket exists
 func BucketExists(bucketName string) (bool, error) {
-	_, err := getClient().HeadBucket(context.Background(), &s3.HeadBucketInput{
+	_, err := getClient().HeadBucket(interrupt.Context(), &s3.HeadBucketInput{
 		Bucket: ptr.String(bucketName),
 	})
 
@@ -63,6 +64,32 @@ func BucketExists(bucketName string) (bool, error) {
 	return true, nil
 }
 
+// BucketNameTaken checks whether the bucket name is already in use by any account.
+// Bucket names are global, so a bucket that belongs to a different account
+// is reported as taken even though BucketExists can't see it.
+func BucketNameTaken(bucketName string) (bool, error) {
+	_, err := getClient().HeadBucket(interrupt.Context(), &s3.HeadBucketInput{
+		Bucket: ptr.String(bucketName),
+	})
+
+	if err != nil {
+		var nf *types.NotFound
+		if errors.As(err, &nf) {
+			return false, nil
+		}
+
+		// Someone else's bucket, or one in a different region
+		var re *awshttp.ResponseError
+		if errors.As(err, &re) && (re.HTTPStatusCode() == 403 || re.HTTPStatusCode() == 301) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
 // CreateBucket creates a new S3 bucket
 func CreateBucket(bucketName string) error {
 	input := &s3.CreateBucketInput{
@@ -76,13 +103,20 @@ func CreateBucket(bucketName string) error {
 		}
 	}
 
-	_, err := getClient().CreateBucket(context.Background(), input)
+	_, err := getClient().CreateBucket(interrupt.Context(), input)
 	if err != nil {
 		return err
 	}
 
+	return ConfigureBucket(bucketName)
+}
+
+// ConfigureBucket applies rain's standard settings to an artifact bucket:
+// default encryption, a public access block, and a lifecycle configuration.
+// It is safe to call on a bucket that is already configured.
+func ConfigureBucket(bucketName string) error {
 	// Encrypt the bucket
-	_, err = getClient().PutBucketEncryption(context.Background(), &s3.PutBucketEncryptionInput{
+	_, err := getClient().PutBucketEncryption(interrupt.Context(), &s3.PutBucketEncryptionInput{
 		Bucket: ptr.String(bucketName),
 		ServerSideEncryptionConfiguration: &types.ServerSideEncryptionConfiguration{
 			Rules: []types.ServerSideEncryptionRule{
@@ -99,7 +133,7 @@ func CreateBucket(bucketName string) error {
 	}
 
 	// Add public access block
-	_, err = getClient().PutPublicAccessBlock(context.Background(), &s3.PutPublicAccessBlockInput{
+	_, err = getClient().PutPublicAccessBlock(interrupt.Context(), &s3.PutPublicAccessBlockInput{
 		Bucket: ptr.String(bucketName),
 		PublicAccessBlockConfiguration: &types.PublicAccessBlockConfiguration{
 			BlockPublicAcls:       awssdk.Bool(true),
@@ -113,24 +147,24 @@ func CreateBucket(bucketName string) error {
 	}
 
 	// Add lifecycle config
-	_, err = getClient().PutBucketLifecycleConfiguration(context.Background(), &s3.PutBucketLifecycleConfigurationInput{
+	_, err = getClient().PutBucketLifecycleConfiguration(interrupt.Context(), &s3.PutBucketLifecycleConfigurationInput{
 		Bucket: ptr.String(bucketName),
 		LifecycleConfiguration: &types.BucketLifecycleConfiguration{
 			Rules: []types.LifecycleRule{
 				{
 					Status: types.ExpirationStatusEnabled,
 					AbortIncompleteMultipartUpload: &types.AbortIncompleteMultipartUpload{
-						DaysAfterInitiation: awssdk.Int32(7),
+						DaysAfterInitiation: awssdk.Int32(artifactExpirationDays),
 					},
 					Expiration: &types.LifecycleExpiration{
-						Days: awssdk.Int32(7),
+						Days: awssdk.Int32(artifactExpirationDays),
 					},
 					Filter: &types.LifecycleRuleFilterMemberPrefix{
 						Value: "",
 					},
 					ID: ptr.String("delete after 14 days"),
 					NoncurrentVersionExpiration: &types.NoncurrentVersionExpiration{
-						NoncurrentDays: awssdk.Int32(7),
+						NoncurrentDays: awssdk.Int32(artifactExpirationDays),
 					},
 				},
 			},
@@ -140,7 +174,23 @@ func CreateBucket(bucketName string) error {
 	return err
 }
 
-// Upload uploads an artifact to the bucket with a unique name
+// artifactExpirationDays is how long the artifact bucket's lifecycle rule keeps objects after they are written
+const artifactExpirationDays = 7
+
+// artifactReuseAge is how old an artifact can be and still be reused by Upload.
+// Older artifacts are written again, so that a stack that refers to one
+// can still read it for almost the whole of the bucket's expiration period.
+const artifactReuseAge = 24 * time.Hour
+
+// reusable returns true if an artifact that is already in the bucket
+// has the content's size and was written recently enough to be used again
+func reusable(existing *S3ObjectInfo, size int, now time.Time) bool {
+	return existing.SizeBytes == int64(size) && now.Sub(existing.LastModified) < artifactReuseAge
+}
+
+// Upload uploads an artifact to the bucket with a name based on its content.
+// Artifacts that were uploaded recently are not uploaded again; older ones are
+// uploaded again so that the bucket's lifecycle rule doesn't expire them while they are in use.
 func Upload(bucketName string, content []byte) (string, error) {
 	isBucketExists, errBucketExists := BucketExists(bucketName)
 
@@ -154,7 +204,16 @@ func Upload(bucketName string, content []byte) (string, error) {
 
 	key := filepath.Join(BucketKeyPrefix, fmt.Sprintf("%x", sha256.Sum256(content)))
 
-	_, err := getClient().PutObject(context.Background(), &s3.PutObjectInput{
+	// Keys are content hashes, so if the key exists the content is already there
+	if existing, err := HeadObject(bucketName, key); err == nil {
+		if reusable(existing, len(content), time.Now()) {
+			config.Debugf("Artifact already uploaded: %s", key)
+			return key, nil
+		}
+		config.Debugf("Uploading artifact again so that it doesn't expire: %s", key)
+	}
+
+	_, err := getClient().PutObject(interrupt.Context(), &s3.PutObjectInput{
 		Bucket: ptr.String(bucketName),
 		Key:    ptr.String(key),
 		Body:   bytes.NewReader(content),
@@ -165,18 +224,28 @@ func Upload(bucketName string, content []byte) (string, error) {
 	return key, err
 }
 
+// RainBucketName returns the name of the rain artifact bucket in the current region,
+// which is BucketName if it has been set, without checking whether the bucket exists
+func RainBucketName() (string, error) {
+	if BucketName != "" {
+		return BucketName, nil
+	}
+
+	accountID, err := sts.GetAccountID()
+	if err != nil {
+		return "", fmt.Errorf("unable to get account ID: %w", err)
+	}
+
+	return fmt.Sprintf("rain-artifacts-%s-%s", accountID, aws.Config().Region), nil
+}
+
 // RainBucket returns the name of the rain deployment bucket in the current region
 // and asks the user if they wish it to be created if it does not exist
 // unless forceCreation is true, then it will not ask
 func RainBucket(forceCreation bool) string {
-	accountID, err := sts.GetAccountID()
+	bucketName, err := RainBucketName()
 	if err != nil {
-		panic(fmt.Errorf("unable to get account ID: %w", err))
-	}
-
-	bucketName := BucketName
-	if bucketName == "" {
-		bucketName = fmt.Sprintf("rain-artifacts-%s-%s", accountID, aws.Config().Region)
+		panic(err)
 	}
 
 	config.Debugf("Artifact bucket: %s", bucketName)
@@ -232,7 +301,7 @@ func RainBucket(forceCreation bool) string {
 
 // GetObject gets an object by key from an S3 bucket
 func GetObject(bucketName string, key string) ([]byte, error) {
-	result, err := getClient().GetObject(context.Background(),
+	result, err := getClient().GetObject(interrupt.Context(),
 		&s3.GetObjectInput{
 			Bucket: &bucketName,
 			Key:    &key,
@@ -250,7 +319,7 @@ func GetObject(bucketName string, key string) ([]byte, error) {
 // GetUnzippedObjectSize gets the uncompressed length in bytes of an object.
 // Calling this on a large object will be slow!
 func GetUnzippedObjectSize(bucketName string, key string) (int64, error) {
-	result, err := getClient().GetObject(context.Background(),
+	result, err := getClient().GetObject(interrupt.Context(),
 		&s3.GetObjectInput{
 			Bucket: &bucketName,
 			Key:    &key,
@@ -304,11 +373,14 @@ func GetUnzippedObjectSize(bucketName string, key string) (int64, error) {
 
 type S3ObjectInfo struct {
 	SizeBytes int64
+
+	// LastModified is when the object was written
+	LastModified time.Time
 }
 
 // HeadObject gets information about an object without downloading it
 func HeadObject(bucketName string, key string) (*S3ObjectInfo, error) {
-	result, err := getClient().HeadObject(context.Background(),
+	result, err := getClient().HeadObject(interrupt.Context(),
 		&s3.HeadObjectInput{
 			Bucket: &bucketName,
 			Key:    &key,
@@ -317,14 +389,15 @@ func HeadObject(bucketName string, key string) (*S3ObjectInfo, error) {
 		return nil, err
 	}
 	retval := &S3ObjectInfo{
-		SizeBytes: *result.ContentLength,
+		SizeBytes:    *result.ContentLength,
+		LastModified: ptr.ToTime(result.LastModified),
 	}
 	return retval, nil
 }
 
 // PutObject puts an object into a bucket
 func PutObject(bucketName string, key string, body []byte) error {
-	_, err := getClient().PutObject(context.Background(),
+	_, err := getClient().PutObject(interrupt.Context(),
 		&s3.PutObjectInput{
 			Bucket: &bucketName,
 			Key:    &key,
@@ -335,10 +408,67 @@ func PutObject(bucketName string, key string, body []byte) error {
 
 // DeleteObject deletes an object from a bucket
 func DeleteObject(bucketName string, key string) error {
-	_, err := getClient().DeleteObject(context.Background(),
+	_, err := getClient().DeleteObject(interrupt.Context(),
 		&s3.DeleteObjectInput{
 			Bucket: &bucketName,
 			Key:    &key,
 		})
 	return err
 }
+
+// ListObjects returns every object in the bucket whose key starts with prefix
+func ListObjects(bucketName string, prefix string) ([]types.Object, error) {
+	objects := make([]types.Object, 0)
+
+	var token *string
+
+	for {
+		res, err := getClient().ListObjectsV2(interrupt.Context(), &s3.ListObjectsV2Input{
+			Bucket:            &bucketName,
+			Prefix:            &prefix,
+			ContinuationToken: token,
+		})
+		if err != nil {
+			return objects, err
+		}
+
+		objects = append(objects, res.Contents...)
+
+		if res.NextContinuationToken == nil {
+			break
+		}
+
+		token = res.NextContinuationToken
+	}
+
+	return objects, nil
+}
+
+// DeleteObjects deletes the objects with the given keys from a bucket
+func DeleteObjects(bucketName string, keys []string) error {
+	// DeleteObjects accepts up to 1000 keys at a time
+	for start := 0; start < len(keys); start += 1000 {
+		ids := make([]types.ObjectIdentifier, 0)
+		for _, key := range keys[start:min(start+1000, len(keys))] {
+			ids = append(ids, types.ObjectIdentifier{Key: ptr.String(key)})
+		}
+
+		res, err := getClient().DeleteObjects(interrupt.Context(), &s3.DeleteObjectsInput{
+			Bucket: &bucketName,
+			Delete: &types.Delete{
+				Objects: ids,
+				Quiet:   awssdk.Bool(true),
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(res.Errors) > 0 {
+			e := res.Errors[0]
+			return fmt.Errorf("unable to delete '%s': %s", ptr.ToString(e.Key), ptr.ToString(e.Message))
+		}
+	}
+
+	return nil
+}
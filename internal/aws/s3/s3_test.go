@@ -0,0 +1,27 @@
+package s3
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReusable(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		name     string
+		existing S3ObjectInfo
+		expected bool
+	}{
+		{"recent", S3ObjectInfo{SizeBytes: 10, LastModified: now.Add(-time.Hour)}, true},
+		{"different size", S3ObjectInfo{SizeBytes: 11, LastModified: now.Add(-time.Hour)}, false},
+		{"close to expiring", S3ObjectInfo{SizeBytes: 10, LastModified: now.Add(-6 * 24 * time.Hour)}, false},
+		{"unknown age", S3ObjectInfo{SizeBytes: 10}, false},
+	}
+
+	for _, c := range cases {
+		if actual := reusable(&c.existing, 10, now); actual != c.expected {
+			t.Errorf("%s: expected %t, got %t", c.name, c.expected, actual)
+		}
+	}
+}
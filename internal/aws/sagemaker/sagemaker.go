@@ -1,9 +1,8 @@
 package sagemaker
 
 import (
-	"context"
-
 	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/service/sagemaker"
 )
 
@@ -22,7 +21,7 @@ func GetNotebookInstances() ([]NotebookInstance, error) {
 	var nextToken *string
 
 	for {
-		resp, err := client.ListNotebookInstances(context.Background(),
+		resp, err := client.ListNotebookInstances(interrupt.Context(),
 			&sagemaker.ListNotebookInstancesInput{
 				NextToken: nextToken,
 			})
@@ -0,0 +1,44 @@
+// Package secretsmanager reads secrets from AWS Secrets Manager
+package secretsmanager
+
+import (
+	"errors"
+	"strings"
+
+	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// region returns the region of the secret if secretId is an ARN,
+// e.g. arn:aws:secretsmanager:us-east-1:123456789012:secret:name
+func region(secretId string) string {
+	parts := strings.SplitN(secretId, ":", 7)
+	if len(parts) == 7 && parts[0] == "arn" && parts[2] == "secretsmanager" {
+		return parts[3]
+	}
+
+	return rainaws.Config().Region
+}
+
+// GetSecretValue returns the current value of the secret,
+// which is identified by its name or ARN
+func GetSecretValue(secretId string) (string, error) {
+	client := secretsmanager.NewFromConfig(rainaws.Config(), func(o *secretsmanager.Options) {
+		o.Region = region(secretId)
+	})
+
+	output, err := client.GetSecretValue(interrupt.Context(), &secretsmanager.GetSecretValueInput{
+		SecretId: ptr.String(secretId),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if output.SecretString == nil {
+		return "", errors.New("the secret is binary; only string secrets can be used")
+	}
+
+	return *output.SecretString, nil
+}
@@ -1,9 +1,8 @@
 package servicequotas
 
 import (
-	"context"
-
 	aws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
 )
 
@@ -14,7 +13,7 @@ func getClient() *servicequotas.Client {
 // Get the value for a service quota
 func GetQuota(serviceCode string, quotaCode string) (float64, error) {
 
-	res, err := getClient().GetServiceQuota(context.Background(),
+	res, err := getClient().GetServiceQuota(interrupt.Context(),
 		&servicequotas.GetServiceQuotaInput{
 			QuotaCode:   &quotaCode,
 			ServiceCode: &serviceCode,
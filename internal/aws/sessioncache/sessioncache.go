@@ -0,0 +1,130 @@
+// Package sessioncache keeps temporary credentials on disk between invocations of rain,
+// so that a profile that assumes a role, perhaps with an MFA code, only does so once per session
+// rather than every time rain runs. Sessions are cached in the rain/sessions directory
+// of the user's cache directory, e.g. ~/.cache/rain/sessions, readable only by the user.
+package sessioncache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// ExpiryWindow is how long before they expire that cached credentials stop being used
+const ExpiryWindow = 5 * time.Minute
+
+// Provider returns the credentials cached under Key, or gets new ones from Provider and caches them.
+// Credentials that don't expire are never cached.
+type Provider struct {
+	Key      string
+	Provider aws.CredentialsProvider
+}
+
+// cachedSession is an entry in the session cache
+type cachedSession struct {
+	AccessKeyID     string    `json:"accessKeyId"`
+	SecretAccessKey string    `json:"secretAccessKey"`
+	SessionToken    string    `json:"sessionToken"`
+	Expires         time.Time `json:"expires"`
+}
+
+// These are replaced in tests
+var (
+	now = time.Now
+
+	cacheDir = func() (string, error) {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(dir, "rain", "sessions"), nil
+	}
+)
+
+// Retrieve implements aws.CredentialsProvider
+func (p Provider) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	if creds, ok := read(p.Key); ok {
+		return creds, nil
+	}
+
+	creds, err := p.Provider.Retrieve(ctx)
+	if err != nil {
+		return creds, err
+	}
+
+	if creds.CanExpire {
+		// Failing to cache the session is not an error; a new one will be started next time
+		write(p.Key, creds)
+	}
+
+	return creds, nil
+}
+
+func cachePath(key string) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, fmt.Sprintf("%x.json", sha256.Sum256([]byte(key)))), nil
+}
+
+func read(key string) (aws.Credentials, bool) {
+	path, err := cachePath(key)
+	if err != nil {
+		return aws.Credentials{}, false
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return aws.Credentials{}, false
+	}
+
+	var s cachedSession
+	if err := json.Unmarshal(content, &s); err != nil {
+		return aws.Credentials{}, false
+	}
+
+	if s.AccessKeyID == "" || s.Expires.Before(now().Add(ExpiryWindow)) {
+		return aws.Credentials{}, false
+	}
+
+	return aws.Credentials{
+		AccessKeyID:     s.AccessKeyID,
+		SecretAccessKey: s.SecretAccessKey,
+		SessionToken:    s.SessionToken,
+		Source:          "rain session cache",
+		CanExpire:       true,
+		Expires:         s.Expires,
+	}, true
+}
+
+func write(key string, creds aws.Credentials) {
+	path, err := cachePath(key)
+	if err != nil {
+		return
+	}
+
+	content, err := json.Marshal(cachedSession{
+		AccessKeyID:     creds.AccessKeyID,
+		SecretAccessKey: creds.SecretAccessKey,
+		SessionToken:    creds.SessionToken,
+		Expires:         creds.Expires,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return
+	}
+
+	os.WriteFile(path, content, 0600)
+}
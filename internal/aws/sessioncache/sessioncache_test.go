@@ -0,0 +1,105 @@
+package sessioncache
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// counter hands out new credentials each time it is called
+type counter struct {
+	calls   int
+	expires time.Duration
+	err     error
+}
+
+func (c *counter) Retrieve(ctx context.Context) (aws.Credentials, error) {
+	c.calls++
+	if c.err != nil {
+		return aws.Credentials{}, c.err
+	}
+
+	return aws.Credentials{
+		AccessKeyID:     "AKIA" + string(rune('A'+c.calls)),
+		SecretAccessKey: "secret",
+		SessionToken:    "token",
+		CanExpire:       c.expires > 0,
+		Expires:         testNow.Add(c.expires),
+	}, nil
+}
+
+func setup(t *testing.T) string {
+	dir := t.TempDir()
+
+	oldNow, oldDir := now, cacheDir
+	t.Cleanup(func() {
+		now, cacheDir = oldNow, oldDir
+	})
+
+	now = func() time.Time { return testNow }
+	cacheDir = func() (string, error) { return dir, nil }
+
+	return dir
+}
+
+func TestRetrieve(t *testing.T) {
+	setup(t)
+
+	c := &counter{expires: time.Hour}
+	p := Provider{Key: "dev|arn:aws:iam::123456789012:role/admin", Provider: c}
+
+	first, err := p.Retrieve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := p.Retrieve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.calls != 1 || second.AccessKeyID != first.AccessKeyID || !second.Expires.Equal(first.Expires) {
+		t.Errorf("expected the second call to use the cached session: %d calls, %+v", c.calls, second)
+	}
+
+	// Sessions that are about to expire are replaced
+	now = func() time.Time { return testNow.Add(time.Hour - ExpiryWindow + time.Second) }
+	if _, err := p.Retrieve(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.calls != 2 {
+		t.Errorf("expected a new session near expiry, got %d calls", c.calls)
+	}
+}
+
+func TestRetrieveDoesNotCacheLongTermCredentials(t *testing.T) {
+	dir := setup(t)
+
+	p := Provider{Key: "default", Provider: &counter{}}
+	if _, err := p.Retrieve(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("expected nothing to be cached, got %d files", len(entries))
+	}
+}
+
+func TestRetrieveError(t *testing.T) {
+	setup(t)
+
+	expected := errors.New("MFA code was wrong")
+	p := Provider{Key: "dev", Provider: &counter{err: expected}}
+
+	if _, err := p.Retrieve(context.Background()); !errors.Is(err, expected) {
+		t.Errorf("expected %v, got %v", expected, err)
+	}
+}
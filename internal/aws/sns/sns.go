@@ -0,0 +1,52 @@
+// Package sns publishes messages to Amazon SNS topics
+package sns
+
+import (
+	"fmt"
+	"strings"
+
+	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// region returns the region of a topic from its ARN,
+// e.g. arn:aws:sns:us-east-1:123456789012:deployments
+func region(topicArn string) (string, error) {
+	parts := strings.Split(topicArn, ":")
+	if len(parts) != 6 || parts[0] != "arn" || parts[2] != "sns" {
+		return "", fmt.Errorf("'%s' is not the ARN of an SNS topic", topicArn)
+	}
+
+	return parts[3], nil
+}
+
+// Publish sends a message to the topic. Subjects longer than SNS allows are shortened.
+func Publish(topicArn, subject, message string) error {
+	r, err := region(topicArn)
+	if err != nil {
+		return err
+	}
+
+	// Subjects are limited to 100 characters
+	if len(subject) > 100 {
+		subject = subject[:97] + "..."
+	}
+
+	input := &sns.PublishInput{
+		TopicArn: ptr.String(topicArn),
+		Message:  ptr.String(message),
+	}
+	if subject != "" {
+		input.Subject = ptr.String(subject)
+	}
+
+	client := sns.NewFromConfig(rainaws.Config(), func(o *sns.Options) {
+		o.Region = r
+	})
+
+	_, err = client.Publish(interrupt.Context(), input)
+
+	return err
+}
@@ -1,9 +1,11 @@
 package ssm
 
 import (
-	"context"
 	rainaws "github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
 )
 
 func getClient() *ssm.Client {
@@ -13,7 +15,7 @@ func getClient() *ssm.Client {
 // GetParameter returns the value of the specified parameter.
 func GetParameter(name string) (string, error) {
 	client := getClient()
-	parameter, err := client.GetParameter(context.Background(), &ssm.GetParameterInput{
+	parameter, err := client.GetParameter(interrupt.Context(), &ssm.GetParameterInput{
 		Name: &name,
 	})
 	if err != nil {
@@ -22,3 +24,32 @@ func GetParameter(name string) (string, error) {
 
 	return *parameter.Parameter.Value, nil
 }
+
+// GetSecureParameter returns the value of the specified parameter,
+// decrypting it if it is a SecureString.
+func GetSecureParameter(name string) (string, error) {
+	client := getClient()
+	parameter, err := client.GetParameter(interrupt.Context(), &ssm.GetParameterInput{
+		Name:           &name,
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return *parameter.Parameter.Value, nil
+}
+
+// PutParameter sets the value of a String parameter, creating it if it doesn't exist.
+func PutParameter(name, value, description string) error {
+	client := getClient()
+	_, err := client.PutParameter(interrupt.Context(), &ssm.PutParameterInput{
+		Name:        &name,
+		Value:       &value,
+		Description: &description,
+		Type:        types.ParameterTypeString,
+		Overwrite:   aws.Bool(true),
+	})
+
+	return err
+}
@@ -0,0 +1,253 @@
+// Package sso logs in to AWS IAM Identity Center (formerly AWS SSO) with the device authorization flow.
+// Tokens are kept in the same cache as the AWS CLI's, ~/.aws/sso/cache,
+// so the AWS SDK uses them to get credentials for profiles that log in with IAM Identity Center,
+// and a login with rain or the AWS CLI works for both.
+package sso
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// Session is where to log in, from a profile's sso_session section,
+// or from its sso_start_url and sso_region settings
+type Session struct {
+	// Name is the sso_session's name; it is empty for profiles that only set sso_start_url
+	Name     string
+	StartURL string
+	Region   string
+}
+
+// cacheKey is the session's name, or its start URL for profiles without a session,
+// which is what the AWS CLI and SDKs name the cache file after
+func (s Session) cacheKey() string {
+	if s.Name != "" {
+		return s.Name
+	}
+
+	return s.StartURL
+}
+
+// Token is a cached access token, in the format that the AWS CLI and SDKs use
+type Token struct {
+	AccessToken string    `json:"accessToken"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+
+	// RefreshToken and the client registration let the AWS SDK renew a session's token without logging in again
+	RefreshToken          string     `json:"refreshToken,omitempty"`
+	ClientID              string     `json:"clientId,omitempty"`
+	ClientSecret          string     `json:"clientSecret,omitempty"`
+	RegistrationExpiresAt *time.Time `json:"registrationExpiresAt,omitempty"`
+
+	Region   string `json:"region,omitempty"`
+	StartURL string `json:"startUrl,omitempty"`
+}
+
+// Valid returns true if the token can still be used for a while
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && t.ExpiresAt.After(now().Add(time.Minute))
+}
+
+// registered returns true if the token's client registration can be used to log in again
+func (t *Token) registered() bool {
+	return t != nil && t.ClientID != "" && t.ClientSecret != "" &&
+		t.RegistrationExpiresAt != nil && t.RegistrationExpiresAt.After(now().Add(time.Hour))
+}
+
+// oidc is the part of the IAM Identity Center OIDC API that Login uses
+type oidc interface {
+	RegisterClient(context.Context, *ssooidc.RegisterClientInput, ...func(*ssooidc.Options)) (*ssooidc.RegisterClientOutput, error)
+	StartDeviceAuthorization(context.Context, *ssooidc.StartDeviceAuthorizationInput, ...func(*ssooidc.Options)) (*ssooidc.StartDeviceAuthorizationOutput, error)
+	CreateToken(context.Context, *ssooidc.CreateTokenInput, ...func(*ssooidc.Options)) (*ssooidc.CreateTokenOutput, error)
+}
+
+// These are replaced in tests
+var (
+	// The OIDC API doesn't need credentials, which is just as well, as rain doesn't have any yet
+	newClient = func(region string) oidc {
+		return ssooidc.New(ssooidc.Options{Region: region})
+	}
+
+	now = time.Now
+
+	sleep = func(ctx context.Context, d time.Duration) error {
+		t := time.NewTimer(d)
+		defer t.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+			return nil
+		}
+	}
+
+	cacheDir = func() (string, error) {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+
+		return filepath.Join(home, ".aws", "sso", "cache"), nil
+	}
+)
+
+const deviceCodeGrant = "urn:ietf:params:oauth:grant-type:device_code"
+
+// cachePath returns the path of the file that the session's token is cached in
+func cachePath(s Session) (string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha1.Sum([]byte(s.cacheKey()))
+
+	return filepath.Join(dir, hex.EncodeToString(hash[:])+".json"), nil
+}
+
+// ReadToken returns the session's cached token, which may have expired
+func ReadToken(s Session) (*Token, error) {
+	path, err := cachePath(s)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Token{}
+	if err := json.Unmarshal(content, t); err != nil {
+		return nil, fmt.Errorf("unable to read cached token '%s': %w", path, err)
+	}
+
+	return t, nil
+}
+
+func writeToken(s Session, t *Token) error {
+	path, err := cachePath(s)
+	if err != nil {
+		return err
+	}
+
+	content, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, content, 0600)
+}
+
+// Login asks the user to approve the login in their browser, waits for them to do so, and caches the token.
+// approve is called with the URL to open and the code that the page should show.
+func Login(ctx context.Context, s Session, approve func(uri, code string)) (*Token, error) {
+	if s.StartURL == "" || s.Region == "" {
+		return nil, errors.New("the profile needs an sso_start_url and sso_region to log in to IAM Identity Center")
+	}
+
+	client := newClient(s.Region)
+
+	// Register rain as a client again only if the last registration has expired
+	token, _ := ReadToken(s)
+	if !token.registered() {
+		input := &ssooidc.RegisterClientInput{
+			ClientName: ptr.String("rain"),
+			ClientType: ptr.String("public"),
+		}
+
+		// Sessions can be refreshed, but profiles without one can't
+		if s.Name != "" {
+			input.GrantTypes = []string{deviceCodeGrant, "refresh_token"}
+			input.Scopes = []string{"sso:account:access"}
+		}
+
+		reg, err := client.RegisterClient(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("unable to register with IAM Identity Center: %w", err)
+		}
+
+		expires := time.Unix(reg.ClientSecretExpiresAt, 0).UTC()
+		token = &Token{
+			ClientID:              ptr.ToString(reg.ClientId),
+			ClientSecret:          ptr.ToString(reg.ClientSecret),
+			RegistrationExpiresAt: &expires,
+		}
+	}
+
+	auth, err := client.StartDeviceAuthorization(ctx, &ssooidc.StartDeviceAuthorizationInput{
+		ClientId:     ptr.String(token.ClientID),
+		ClientSecret: ptr.String(token.ClientSecret),
+		StartUrl:     ptr.String(s.StartURL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to start logging in to IAM Identity Center: %w", err)
+	}
+
+	uri := ptr.ToString(auth.VerificationUriComplete)
+	if uri == "" {
+		uri = ptr.ToString(auth.VerificationUri)
+	}
+	approve(uri, ptr.ToString(auth.UserCode))
+
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	deadline := now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+
+	for now().Before(deadline) {
+		if err := sleep(ctx, interval); err != nil {
+			return nil, err
+		}
+
+		res, err := client.CreateToken(ctx, &ssooidc.CreateTokenInput{
+			ClientId:     ptr.String(token.ClientID),
+			ClientSecret: ptr.String(token.ClientSecret),
+			GrantType:    ptr.String(deviceCodeGrant),
+			DeviceCode:   auth.DeviceCode,
+		})
+
+		var pending *types.AuthorizationPendingException
+		var slowDown *types.SlowDownException
+		switch {
+		case errors.As(err, &pending):
+			continue
+		case errors.As(err, &slowDown):
+			interval += 5 * time.Second
+			continue
+		case err != nil:
+			return nil, fmt.Errorf("unable to log in to IAM Identity Center: %w", err)
+		}
+
+		token.AccessToken = ptr.ToString(res.AccessToken)
+		token.ExpiresAt = now().Add(time.Duration(res.ExpiresIn) * time.Second).UTC().Truncate(time.Second)
+		token.RefreshToken = ptr.ToString(res.RefreshToken)
+		token.Region = s.Region
+		token.StartURL = s.StartURL
+
+		if err := writeToken(s, token); err != nil {
+			return nil, fmt.Errorf("unable to cache the IAM Identity Center token: %w", err)
+		}
+
+		return token, nil
+	}
+
+	return nil, errors.New("the login to IAM Identity Center was not approved in time")
+}
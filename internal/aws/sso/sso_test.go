@@ -0,0 +1,152 @@
+package sso
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc"
+	"github.com/aws/aws-sdk-go-v2/service/ssooidc/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// fakeOIDC approves the login after pending polls
+type fakeOIDC struct {
+	registered int
+	pending    int
+	polls      int
+}
+
+func (f *fakeOIDC) RegisterClient(ctx context.Context, in *ssooidc.RegisterClientInput, _ ...func(*ssooidc.Options)) (*ssooidc.RegisterClientOutput, error) {
+	f.registered++
+
+	return &ssooidc.RegisterClientOutput{
+		ClientId:              ptr.String("client"),
+		ClientSecret:          ptr.String("secret"),
+		ClientSecretExpiresAt: testNow.Add(90 * 24 * time.Hour).Unix(),
+	}, nil
+}
+
+func (f *fakeOIDC) StartDeviceAuthorization(ctx context.Context, in *ssooidc.StartDeviceAuthorizationInput, _ ...func(*ssooidc.Options)) (*ssooidc.StartDeviceAuthorizationOutput, error) {
+	return &ssooidc.StartDeviceAuthorizationOutput{
+		DeviceCode:              ptr.String("device"),
+		UserCode:                ptr.String("ABCD-EFGH"),
+		VerificationUri:         ptr.String("https://device.sso.us-east-1.amazonaws.com/"),
+		VerificationUriComplete: ptr.String("https://device.sso.us-east-1.amazonaws.com/?user_code=ABCD-EFGH"),
+		ExpiresIn:               600,
+		Interval:                1,
+	}, nil
+}
+
+func (f *fakeOIDC) CreateToken(ctx context.Context, in *ssooidc.CreateTokenInput, _ ...func(*ssooidc.Options)) (*ssooidc.CreateTokenOutput, error) {
+	f.polls++
+	if f.polls <= f.pending {
+		return nil, &types.AuthorizationPendingException{}
+	}
+
+	return &ssooidc.CreateTokenOutput{
+		AccessToken:  ptr.String("token"),
+		RefreshToken: ptr.String("refresh"),
+		ExpiresIn:    3600,
+	}, nil
+}
+
+var testNow = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func setup(t *testing.T, f *fakeOIDC) {
+	dir := t.TempDir()
+
+	oldClient, oldNow, oldSleep, oldDir := newClient, now, sleep, cacheDir
+	t.Cleanup(func() {
+		newClient, now, sleep, cacheDir = oldClient, oldNow, oldSleep, oldDir
+	})
+
+	newClient = func(string) oidc { return f }
+	now = func() time.Time { return testNow }
+	sleep = func(context.Context, time.Duration) error { return nil }
+	cacheDir = func() (string, error) { return dir, nil }
+}
+
+func TestLogin(t *testing.T) {
+	f := &fakeOIDC{pending: 2}
+	setup(t, f)
+
+	s := Session{Name: "work", StartURL: "https://example.awsapps.com/start", Region: "us-east-1"}
+
+	var shown string
+	token, err := Login(context.Background(), s, func(uri, code string) { shown = uri + " " + code })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if shown != "https://device.sso.us-east-1.amazonaws.com/?user_code=ABCD-EFGH ABCD-EFGH" {
+		t.Errorf("unexpected approval: %s", shown)
+	}
+
+	if f.polls != 3 {
+		t.Errorf("expected 3 polls, got %d", f.polls)
+	}
+
+	if !token.Valid() || token.RefreshToken != "refresh" || !token.ExpiresAt.Equal(testNow.Add(time.Hour)) {
+		t.Errorf("unexpected token: %+v", token)
+	}
+
+	// The token is cached where the AWS CLI looks for it: the SHA-1 of the session name
+	path := filepath.Join(must(cacheDir()), "e274eeff768c6396088ec6eb091f4bf4d47ab1e0.json")
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("expected the token to be cached in %s: %v", path, err)
+	}
+
+	cached, err := ReadToken(s)
+	if err != nil || cached.AccessToken != "token" || cached.ClientID != "client" {
+		t.Errorf("unexpected cached token: %+v, %v", cached, err)
+	}
+
+	// Logging in again reuses the client registration
+	if _, err := Login(context.Background(), s, func(string, string) {}); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.registered != 1 {
+		t.Errorf("expected rain to register once, got %d", f.registered)
+	}
+}
+
+func TestLoginNeedsStartURL(t *testing.T) {
+	setup(t, &fakeOIDC{})
+
+	if _, err := Login(context.Background(), Session{Name: "work"}, func(string, string) {}); err == nil {
+		t.Error("expected an error without a start URL")
+	}
+}
+
+func TestValid(t *testing.T) {
+	setup(t, &fakeOIDC{})
+
+	var missing *Token
+	cases := []struct {
+		token    *Token
+		expected bool
+	}{
+		{missing, false},
+		{&Token{ExpiresAt: testNow.Add(time.Hour)}, false},
+		{&Token{AccessToken: "token", ExpiresAt: testNow.Add(30 * time.Second)}, false},
+		{&Token{AccessToken: "token", ExpiresAt: testNow.Add(time.Hour)}, true},
+	}
+
+	for i, c := range cases {
+		if c.token.Valid() != c.expected {
+			t.Errorf("case %d: expected %t", i, c.expected)
+		}
+	}
+}
+
+func must(s string, err error) string {
+	if err != nil {
+		panic(err)
+	}
+
+	return s
+}
@@ -1,9 +1,8 @@
 package sts
 
 import (
-	"context"
-
 	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/aws/aws-sdk-go-v2/service/sts/types"
 )
@@ -14,7 +13,7 @@ func getClient() *sts.Client {
 
 // GetSessionToken returns a session token for the current IAM principle
 func GetSessionToken() (*types.Credentials, error) {
-	res, err := getClient().GetSessionToken(context.Background(), &sts.GetSessionTokenInput{})
+	res, err := getClient().GetSessionToken(interrupt.Context(), &sts.GetSessionTokenInput{})
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +23,7 @@ func GetSessionToken() (*types.Credentials, error) {
 
 // GetCallerID returns the identity of the current IAM principal
 func GetCallerID() (sts.GetCallerIdentityOutput, error) {
-	res, err := getClient().GetCallerIdentity(context.Background(), nil)
+	res, err := getClient().GetCallerIdentity(interrupt.Context(), nil)
 	if err != nil {
 		return sts.GetCallerIdentityOutput{}, err
 	}
@@ -0,0 +1,270 @@
+// Package budget limits how many stack operations rain runs at the same time
+// in an AWS account, across every machine that shares a DynamoDB table.
+// This stops many pipelines that deploy at once from being throttled by CloudFormation.
+//
+// The table must have a string partition key named SlotId.
+// Each running operation holds a lease on one of Limit slots per account,
+// and other operations queue until a slot is free.
+// Leases are renewed while the operation runs and expire if they are not,
+// so a pipeline that crashes does not hold on to its slot.
+// Enable TTL on the LeaseExpires attribute to clean up old items.
+//
+// The budget is configured with the RAIN_BUDGET_TABLE and RAIN_BUDGET_LIMIT
+// environment variables, or with the --budget-table and --budget flags.
+// The table can be given as an ARN if it is in a different region from the stacks.
+// A table given by name is in the region that rain starts in, even when it deploys to other regions.
+package budget
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/dynamodb"
+	"github.com/aws-cloudformation/rain/internal/aws/sts"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+)
+
+// Table is the name or ARN of the coordination table; the budget is disabled if it is empty
+var Table = os.Getenv("RAIN_BUDGET_TABLE")
+
+// Limit is the number of stack operations that can run at the same time in an account
+var Limit = envInt("RAIN_BUDGET_LIMIT", 5)
+
+// QueueTimeout is the longest rain will wait for a slot before giving up
+var QueueTimeout = time.Hour
+
+// LeaseDuration is how long a slot is held for if the lease is not renewed
+var LeaseDuration = 5 * time.Minute
+
+func envInt(name string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return v
+	}
+
+	return fallback
+}
+
+// Enabled returns true if a budget has been configured
+func Enabled() bool {
+	return Table != "" && Limit > 0
+}
+
+// Lease is a slot in the budget that is held by this process
+type Lease struct {
+	table dynamodb.Table
+	slot  string
+	owner string
+	stack string
+
+	stop chan bool
+	done chan bool
+
+	// lost is set by renew if the lease expired or was taken by another process
+	lost error
+}
+
+// slotId returns the key of the nth slot in the account
+func slotId(account string, n int) string {
+	return fmt.Sprintf("%s#%d", account, n)
+}
+
+// backoff returns how long to wait before trying again for the given attempt
+func backoff(attempt int) time.Duration {
+	wait := 5 * time.Second
+	for i := 0; i < attempt && wait < time.Minute; i++ {
+		wait *= 2
+	}
+
+	// Spread out pipelines that started waiting at the same time
+	wait += time.Duration(rand.Int63n(int64(time.Second)))
+
+	return min(wait, time.Minute)
+}
+
+func (l *Lease) put(condition string, now time.Time) error {
+	return dynamodb.PutItem(l.table, dynamodb.Item{
+		"SlotId":       dynamodb.S(l.slot),
+		"LeaseOwner":   dynamodb.S(l.owner),
+		"LeaseExpires": dynamodb.N(now.Add(LeaseDuration).Unix()),
+		"StackName":    dynamodb.S(l.stack),
+	}, condition, dynamodb.Item{
+		":now":   dynamodb.N(now.Unix()),
+		":owner": dynamodb.S(l.owner),
+	})
+}
+
+// claim takes the slot if it is free or its lease has expired
+func (l *Lease) claim() error {
+	return l.put("attribute_not_exists(SlotId) OR LeaseExpires < :now OR LeaseOwner = :owner", time.Now())
+}
+
+// renew extends the lease until Release is called.
+// A renewal that fails is tried again until the lease expires;
+// if it does, or another process has taken the slot, renewal stops and the lease is lost.
+func (l *Lease) renew() {
+	defer close(l.done)
+
+	expires := time.Now().Add(LeaseDuration)
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-time.After(LeaseDuration / 3):
+		}
+
+		now := time.Now()
+
+		err := l.put("LeaseOwner = :owner AND LeaseExpires >= :now", now)
+		if err == nil {
+			expires = now.Add(LeaseDuration)
+			continue
+		}
+
+		config.Debugf("unable to renew lease on budget slot '%s': %s", l.slot, err)
+
+		if errors.Is(err, dynamodb.ErrConditionFailed) || time.Now().After(expires) {
+			l.lost = fmt.Errorf("lost the lease on budget slot '%s', so more stack operations than the budget allows may have run: %w",
+				l.slot, err)
+			return
+		}
+	}
+}
+
+// Acquire waits until one of the account's slots is free and takes it.
+// waiting, if not nil, is called with a message each time Acquire has to wait.
+// If no budget has been configured, Acquire returns a nil Lease straight away.
+// The lease must be released with Release once the stack operation has finished.
+func Acquire(stackName string, waiting func(message string)) (*Lease, error) {
+	leases, err := AcquireN(stackName, 1, waiting)
+	if err != nil || leases == nil {
+		return nil, err
+	}
+
+	return leases[0], nil
+}
+
+// AcquireN is Acquire for n operations that run at the same time, such as a stack deployed to several regions.
+// It takes the n slots together: if it can't get all of them, it gives back the ones it got before waiting,
+// so that it never holds slots that it, or another process doing the same, needs in order to finish.
+// It returns an error straight away if n is more than Limit.
+// If no budget has been configured, AcquireN returns nil straight away.
+func AcquireN(stackName string, n int, waiting func(message string)) ([]*Lease, error) {
+	if !Enabled() {
+		return nil, nil
+	}
+
+	if n > Limit {
+		return nil, fmt.Errorf("%d stack operations can't run at the same time with a budget of %d; "+
+			"raise the budget with --budget or RAIN_BUDGET_LIMIT", n, Limit)
+	}
+
+	account, err := sts.GetAccountID()
+	if err != nil {
+		return nil, err
+	}
+
+	// A table given by name is in the current region, which must not change while the leases are renewed
+	table := dynamodb.ParseTable(Table)
+	if table.Region == "" {
+		table.Region = aws.Config().Region
+	}
+
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%s/%d/%d", host, os.Getpid(), rand.Int63())
+
+	deadline := time.Now().Add(QueueTimeout)
+
+	for attempt := 0; ; attempt++ {
+		leases := make([]*Lease, 0, n)
+
+		// Start at a random slot so that waiting pipelines don't all compete for the first one
+		first := rand.Intn(Limit)
+
+		for i := 0; i < Limit && len(leases) < n; i++ {
+			l := &Lease{
+				table: table,
+				slot:  slotId(account, (first+i)%Limit),
+				owner: owner,
+				stack: stackName,
+				stop:  make(chan bool),
+				done:  make(chan bool),
+			}
+
+			err := l.claim()
+			if err == nil {
+				leases = append(leases, l)
+				continue
+			}
+
+			if !errors.Is(err, dynamodb.ErrConditionFailed) {
+				for _, l := range leases {
+					l.free()
+				}
+				return nil, err
+			}
+		}
+
+		if len(leases) == n {
+			for _, l := range leases {
+				go l.renew()
+			}
+			return leases, nil
+		}
+
+		for _, l := range leases {
+			l.free()
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("gave up waiting for %d of the %d stack operation slots in account %s after %s",
+				n, Limit, account, QueueTimeout)
+		}
+
+		if waiting != nil {
+			if n == 1 {
+				waiting(fmt.Sprintf("Waiting for one of the %d stack operation slots in account %s", Limit, account))
+			} else {
+				waiting(fmt.Sprintf("Waiting for %d of the %d stack operation slots in account %s", n, Limit, account))
+			}
+		}
+
+		if err := interrupt.Sleep(backoff(attempt)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Release frees the lease's slot for another operation.
+// It returns an error if the lease was lost before it was released.
+// It is safe to call Release on a nil Lease.
+func (l *Lease) Release() error {
+	if l == nil {
+		return nil
+	}
+
+	close(l.stop)
+	<-l.done
+
+	l.free()
+
+	return l.lost
+}
+
+// free deletes the lease's item, unless another process has taken the slot since
+func (l *Lease) free() {
+	err := dynamodb.DeleteItem(l.table, dynamodb.Item{
+		"SlotId": dynamodb.S(l.slot),
+	}, "LeaseOwner = :owner", dynamodb.Item{
+		":owner": dynamodb.S(l.owner),
+	})
+	if err != nil {
+		config.Debugf("unable to release budget slot '%s': %s", l.slot, err)
+	}
+}
@@ -0,0 +1,42 @@
+package budget
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	if wait := backoff(0); wait < 5*time.Second || wait >= 6*time.Second {
+		t.Errorf("unexpected first wait: %s", wait)
+	}
+
+	if wait := backoff(10); wait != time.Minute {
+		t.Errorf("expected waits to be capped at a minute, got %s", wait)
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	Table, Limit = "", 5
+	if Enabled() {
+		t.Error("expected the budget to be disabled without a table")
+	}
+
+	Table = "rain-budget"
+	if !Enabled() {
+		t.Error("expected the budget to be enabled")
+	}
+
+	Limit = 0
+	if Enabled() {
+		t.Error("expected the budget to be disabled with a limit of 0")
+	}
+}
+
+func TestAcquireNOverLimit(t *testing.T) {
+	defer func(table string, limit int) { Table, Limit = table, limit }(Table, Limit)
+
+	Table, Limit = "rain-budget", 2
+	if _, err := AcquireN("app", 3, nil); err == nil {
+		t.Error("expected an error when more slots are needed than the budget allows")
+	}
+}
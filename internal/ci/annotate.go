@@ -0,0 +1,148 @@
+package ci
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/lint"
+)
+
+// Annotate reports the findings that aren't suppressed to the CI system, if rain is running in one.
+// GitHub annotations are written to w.
+func Annotate(w io.Writer, fn string, findings []lint.Finding) error {
+	active := make([]lint.Finding, 0, len(findings))
+	for _, f := range findings {
+		if !f.Suppressed {
+			active = append(active, f)
+		}
+	}
+
+	switch Detect() {
+	case GitHub:
+		fmt.Fprint(w, WorkflowCommands(fn, active))
+	case GitLab:
+		return addToCodeQualityReport(fileFromEnv("RAIN_CODE_QUALITY_REPORT", DefaultCodeQualityFile), fn, active)
+	}
+
+	return nil
+}
+
+// WorkflowCommands returns the GitHub Actions workflow commands that annotate the template with the findings
+func WorkflowCommands(fn string, findings []lint.Finding) string {
+	var b strings.Builder
+
+	for _, f := range findings {
+		command := "notice"
+		switch f.Severity {
+		case lint.Error:
+			command = "error"
+		case lint.Warning:
+			command = "warning"
+		}
+
+		properties := []string{"file=" + escapeProperty(filepath.ToSlash(fn))}
+		if f.Line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", f.Line))
+		}
+		properties = append(properties, "title="+escapeProperty(f.Rule))
+
+		fmt.Fprintf(&b, "::%s %s::%s\n", command, strings.Join(properties, ","), escapeData(f.Element+": "+f.Message))
+	}
+
+	return b.String()
+}
+
+// escapeData escapes the message of a workflow command
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes the value of a workflow command's property
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
+
+// codeQualityIssue is an issue in a GitLab code quality report
+type codeQualityIssue struct {
+	Description string `json:"description"`
+	CheckName   string `json:"check_name"`
+	Fingerprint string `json:"fingerprint"`
+	Severity    string `json:"severity"`
+	Location    struct {
+		Path  string `json:"path"`
+		Lines struct {
+			Begin int `json:"begin"`
+		} `json:"lines"`
+	} `json:"location"`
+}
+
+// codeQualityIssues returns the findings as issues in a GitLab code quality report
+func codeQualityIssues(fn string, findings []lint.Finding) []codeQualityIssue {
+	issues := make([]codeQualityIssue, 0, len(findings))
+
+	for _, f := range findings {
+		issue := codeQualityIssue{
+			Description: f.Element + ": " + f.Message,
+			CheckName:   f.Rule,
+			Severity:    "info",
+		}
+
+		switch f.Severity {
+		case lint.Error:
+			issue.Severity = "major"
+		case lint.Warning:
+			issue.Severity = "minor"
+		}
+
+		issue.Location.Path = filepath.ToSlash(fn)
+		issue.Location.Lines.Begin = max(f.Line, 1)
+
+		// GitLab compares fingerprints between pipelines to tell which issues are new,
+		// so they leave out the line, which changes when lines are added above it
+		sum := sha256.Sum256([]byte(strings.Join([]string{issue.Location.Path, f.Rule, f.Element, f.Message}, "\x00")))
+		issue.Fingerprint = fmt.Sprintf("%x", sum[:16])
+
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// addToCodeQualityReport adds the findings to the report at path,
+// keeping the issues that earlier runs of rain added for other templates
+func addToCodeQualityReport(path, fn string, findings []lint.Finding) error {
+	issues := make([]codeQualityIssue, 0)
+
+	content, err := os.ReadFile(path)
+	if err == nil {
+		if err := json.Unmarshal(content, &issues); err != nil {
+			return fmt.Errorf("unable to read code quality report '%s': %w", path, err)
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to read code quality report '%s': %w", path, err)
+	}
+
+	kept := make([]codeQualityIssue, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Location.Path != filepath.ToSlash(fn) {
+			kept = append(kept, issue)
+		}
+	}
+
+	out, err := json.MarshalIndent(append(kept, codeQualityIssues(fn, findings)...), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, append(out, '\n'), 0644); err != nil {
+		return fmt.Errorf("unable to write code quality report '%s': %w", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,177 @@
+// Package ci reports rain's results to the CI system it is running in.
+//
+// In GitHub Actions, deployment summaries are added to the job summary (GITHUB_STEP_SUMMARY),
+// and lint findings are written as workflow commands, which annotate the template's lines.
+//
+// In GitLab CI, deployment summaries are appended to a Markdown file, rain-summary.md or the file
+// that RAIN_SUMMARY_FILE names, which the job can keep as an artifact. Lint findings are added to
+// a code quality report, gl-code-quality-report.json or the file that RAIN_CODE_QUALITY_REPORT names,
+// which GitLab shows in merge requests when the job declares it as an artifacts:reports:codequality.
+package ci
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
+	"github.com/aws-cloudformation/rain/internal/notify"
+)
+
+// Provider is a CI system
+type Provider string
+
+const (
+	None   Provider = ""
+	GitHub Provider = "github"
+	GitLab Provider = "gitlab"
+)
+
+// Files that GitLab reports are written to, unless the environment variables override them
+const (
+	DefaultSummaryFile     = "rain-summary.md"
+	DefaultCodeQualityFile = "gl-code-quality-report.json"
+)
+
+// Detect returns the CI system that rain is running in, from the variables that it sets
+func Detect() Provider {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return GitHub
+	case os.Getenv("GITLAB_CI") == "true":
+		return GitLab
+	default:
+		return None
+	}
+}
+
+// Output is one of the stack's outputs
+type Output struct {
+	Key         string
+	Value       string
+	Description string
+}
+
+// Deployment is a deployment that has finished
+type Deployment struct {
+	notify.Event
+
+	// StackId links the summary to the stack in the console
+	StackId string
+
+	Outputs []Output
+}
+
+// Markdown describes the deployment as a Markdown table of its status,
+// followed by tables of the changes and the stack's outputs
+func (d Deployment) Markdown() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "### %s\n\n", d.Title())
+
+	b.WriteString("| | |\n|---|---|\n")
+	row(&b, "Status", d.Status)
+	if d.Duration > 0 {
+		row(&b, "Duration", d.Duration.Round(time.Second).String())
+	}
+	if d.FailingResource != "" {
+		row(&b, "Failing resource", d.FailingResource)
+	}
+	if d.Reason != "" {
+		row(&b, "Reason", d.Reason)
+	}
+	b.WriteString("\n")
+
+	if link := d.consoleURL(); link != "" {
+		fmt.Fprintf(&b, "[View the stack in the CloudFormation console](%s)\n\n", link)
+	}
+
+	if len(d.Changes) > 0 {
+		fmt.Fprintf(&b, "#### Changes (%d)\n\n", len(d.Changes))
+		b.WriteString("| Action | Logical ID | Type |\n|---|---|---|\n")
+		for _, c := range d.Changes {
+			row(&b, c.Action, c.LogicalId, c.ResourceType)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(d.Outputs) > 0 {
+		b.WriteString("#### Outputs\n\n")
+		b.WriteString("| Name | Value | Description |\n|---|---|---|\n")
+		for _, o := range d.Outputs {
+			value := cell(o.Value)
+			if strings.HasPrefix(o.Value, "https://") {
+				value = fmt.Sprintf("[%s](%s)", value, o.Value)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", cell(o.Key), value, cell(o.Description))
+		}
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
+// consoleURL returns a link to the stack in the console, if the stack and its partition's console are known
+func (d Deployment) consoleURL() string {
+	if d.StackId == "" || d.Region == "" {
+		return ""
+	}
+
+	return partition.ForRegion(d.Region).ConsoleURL("cloudformation", d.Region,
+		"/stacks/stackinfo?stackId="+url.QueryEscape(d.StackId))
+}
+
+// WriteSummary adds the deployment's summary to the CI system's report, if rain is running in one
+func WriteSummary(d Deployment) error {
+	var path string
+
+	switch Detect() {
+	case GitHub:
+		path = os.Getenv("GITHUB_STEP_SUMMARY")
+	case GitLab:
+		path = fileFromEnv("RAIN_SUMMARY_FILE", DefaultSummaryFile)
+	}
+
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("unable to write the deployment summary: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(d.Markdown()); err != nil {
+		return fmt.Errorf("unable to write the deployment summary: %w", err)
+	}
+
+	return nil
+}
+
+// fileFromEnv returns the value of an environment variable, or a default if it isn't set
+func fileFromEnv(name, defaultPath string) string {
+	if path := os.Getenv(name); path != "" {
+		return path
+	}
+
+	return defaultPath
+}
+
+// row writes a row of a Markdown table
+func row(b *strings.Builder, cells ...string) {
+	for i := range cells {
+		cells[i] = cell(cells[i])
+	}
+
+	fmt.Fprintf(b, "| %s |\n", strings.Join(cells, " | "))
+}
+
+// cell escapes a value so that it fits in one cell of a Markdown table
+func cell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	s = strings.ReplaceAll(s, "\r\n", "<br>")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
@@ -0,0 +1,172 @@
+package ci
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft/lint"
+	"github.com/aws-cloudformation/rain/internal/notify"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDetect(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "")
+	if p := Detect(); p != None {
+		t.Errorf("expected no CI, got %s", p)
+	}
+
+	t.Setenv("GITLAB_CI", "true")
+	if p := Detect(); p != GitLab {
+		t.Errorf("expected gitlab, got %s", p)
+	}
+
+	t.Setenv("GITHUB_ACTIONS", "true")
+	if p := Detect(); p != GitHub {
+		t.Errorf("expected github, got %s", p)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	d := Deployment{
+		Event: notify.Event{
+			Kind:      notify.Succeeded,
+			StackName: "app",
+			Region:    "us-east-1",
+			Status:    "UPDATE_COMPLETE",
+			Duration:  83 * time.Second,
+			Changes: []notify.Change{
+				{Action: "Add", LogicalId: "Bucket", ResourceType: "AWS::S3::Bucket"},
+			},
+		},
+		StackId: "arn:aws:cloudformation:us-east-1:123456789012:stack/app/abc",
+		Outputs: []Output{
+			{Key: "Url", Value: "https://example.com", Description: "The site"},
+			{Key: "Names", Value: "a|b"},
+		},
+	}
+
+	expected := `### Deployed stack app in us-east-1
+
+| | |
+|---|---|
+| Status | UPDATE_COMPLETE |
+| Duration | 1m23s |
+
+[View the stack in the CloudFormation console](https://console.aws.amazon.com/cloudformation/home?region=us-east-1#/stacks/stackinfo?stackId=arn%3Aaws%3Acloudformation%3Aus-east-1%3A123456789012%3Astack%2Fapp%2Fabc)
+
+#### Changes (1)
+
+| Action | Logical ID | Type |
+|---|---|---|
+| Add | Bucket | AWS::S3::Bucket |
+
+#### Outputs
+
+| Name | Value | Description |
+|---|---|---|
+| Url | [https://example.com](https://example.com) | The site |
+| Names | a\|b |  |
+
+`
+
+	if diff := cmp.Diff(expected, d.Markdown()); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestWriteSummary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.md")
+	t.Setenv("GITHUB_ACTIONS", "true")
+	t.Setenv("GITHUB_STEP_SUMMARY", path)
+
+	d := Deployment{Event: notify.Event{Kind: notify.Failed, StackName: "app", Region: "us-east-1", Reason: "line one\nline two"}}
+	for i := 0; i < 2; i++ {
+		if err := WriteSummary(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := strings.Count(string(content), "### Failed to deploy stack app in us-east-1"); n != 2 {
+		t.Errorf("expected the summary to be appended twice, found it %d times", n)
+	}
+
+	if !strings.Contains(string(content), "| Reason | line one<br>line two |") {
+		t.Errorf("expected the reason in one cell:\n%s", content)
+	}
+}
+
+var testFindings = []lint.Finding{
+	{Rule: "cidr", Severity: lint.Error, Element: "Resources/Vpc", Message: "overlaps 10.0.0.0/16, 50% of it", Line: 4},
+	{Rule: "naming", Severity: lint.Warning, Element: "Outputs/Arn", Message: "not exported"},
+	{Rule: "unused", Severity: lint.Info, Element: "Parameters/Env", Message: "accepted", Line: 2, Suppressed: true},
+}
+
+func TestWorkflowCommands(t *testing.T) {
+	expected := strings.Join([]string{
+		"::error file=templates/app.yaml,line=4,title=cidr::Resources/Vpc: overlaps 10.0.0.0/16, 50%25 of it",
+		"::warning file=templates/app.yaml,title=naming::Outputs/Arn: not exported",
+		"::notice file=templates/app.yaml,line=2,title=unused::Parameters/Env: accepted",
+		"",
+	}, "\n")
+
+	if diff := cmp.Diff(expected, WorkflowCommands("templates/app.yaml", testFindings)); diff != "" {
+		t.Error(diff)
+	}
+}
+
+func TestAnnotateGitLab(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.json")
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("RAIN_CODE_QUALITY_REPORT", path)
+
+	// Issues for other templates are kept, and those for the same template are replaced
+	for _, fn := range []string{"other.yaml", "app.yaml", "app.yaml"} {
+		if err := Annotate(nil, fn, testFindings); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var issues []codeQualityIssue
+	if err := json.Unmarshal(content, &issues); err != nil {
+		t.Fatal(err)
+	}
+
+	actual := make([]string, 0)
+	for _, issue := range issues {
+		actual = append(actual, strings.Join([]string{issue.Location.Path, issue.CheckName, issue.Severity}, " "))
+		if len(issue.Fingerprint) != 32 {
+			t.Errorf("unexpected fingerprint %q", issue.Fingerprint)
+		}
+	}
+
+	expected := []string{
+		"other.yaml cidr major",
+		"other.yaml naming minor",
+		"app.yaml cidr major",
+		"app.yaml naming minor",
+	}
+
+	if diff := cmp.Diff(expected, actual); diff != "" {
+		t.Error(diff)
+	}
+
+	if issues[1].Location.Lines.Begin != 1 {
+		t.Errorf("expected a finding without a line to be reported on line 1, got %d", issues[1].Location.Lines.Begin)
+	}
+}
@@ -0,0 +1,150 @@
+package adopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/ccapi"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var logicalIdFlag string
+var yes bool
+var dryRun bool
+
+// Cmd is the adopt command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "adopt <stack> <template> <type> <identifier>",
+	Short: "Bring an existing resource under the management of a stack",
+	Long: `Imports a resource that was created outside of CloudFormation, for example in the console, into a stack.
+
+The resource's current configuration is read with the Cloud Control API and turned into
+a minimal template block, without read-only properties or empty values. The block is added
+to the template with a Retain deletion policy, the template file is saved, and an import
+change set is created and executed.
+
+The identifier is the resource's primary identifier, such as a bucket name.
+Separate the values of compound identifiers with |, e.g. "my-cluster|my-service".
+
+The template must match what is already deployed to the stack, since an import can't
+change other resources. If the stack does not exist, it is created with the imported resource.
+
+Use --dry-run to see the template block without changing the template or the stack.`,
+	Args:                  cobra.ExactArgs(4),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		stackName, fn, typeName, identifier := args[0], args[1], args[2], args[3]
+
+		t, err := parse.File(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		spinner.Push(fmt.Sprintf("Reading %s %s", typeName, identifier))
+
+		schemaSource, err := cfn.GetTypeSchema(typeName, false)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to get the schema for %s", typeName))
+		}
+
+		schema, err := cfn.ParseSchema(schemaSource)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse the schema for %s", typeName))
+		}
+
+		model, err := ccapi.GetResource(identifier, typeName)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read %s %s", typeName, identifier))
+		}
+
+		spinner.Pop()
+
+		var props map[string]any
+		if err := json.Unmarshal([]byte(model), &props); err != nil {
+			panic(ui.Errorf(err, "unable to parse the model of %s", identifier))
+		}
+		props = minimalProperties(props, schema.ReadOnlyProperties)
+
+		idProps, err := cfn.GetTypeIdentifier(typeName)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to get the primary identifier of %s", typeName))
+		}
+
+		resourceId, err := resourceIdentifier(idProps, identifier)
+		if err != nil {
+			panic(ui.Errorf(err, "invalid identifier '%s'", identifier))
+		}
+
+		id := logicalIdFlag
+		if id == "" {
+			id = logicalId(t, typeName, identifier)
+		}
+
+		if err := addResource(t, id, typeName, props); err != nil {
+			panic(ui.Errorf(err, "unable to add %s to the template", id))
+		}
+
+		block, _ := t.GetResource(id)
+		fmt.Println(console.Yellow(fmt.Sprintf("Template block for %s:", identifier)))
+		fmt.Println(format.String(blockTemplate(id, block), format.Options{}))
+
+		if dryRun {
+			return
+		}
+
+		if !yes && !console.Confirm(true, fmt.Sprintf("Add %s to %s and import it into stack %s?", id, fn, stackName)) {
+			panic(fmt.Errorf("user cancelled import"))
+		}
+
+		if err := os.WriteFile(fn, []byte(format.String(t, format.Options{})), 0644); err != nil {
+			panic(ui.Errorf(err, "unable to write '%s'", fn))
+		}
+
+		imports := []types.ResourceToImport{
+			{
+				LogicalResourceId:  ptr.String(id),
+				ResourceType:       ptr.String(typeName),
+				ResourceIdentifier: resourceId,
+			},
+		}
+
+		spinner.Push("Creating import change set")
+		changeSetName, err := cfn.CreateImportChangeSet(t, stackName, imports)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to create import change set"))
+		}
+
+		spinner.Push("Importing")
+		if err := cfn.ExecuteChangeSet(stackName, changeSetName, false); err != nil {
+			panic(ui.Errorf(err, "unable to execute import change set"))
+		}
+
+		status, messages := cfn.WaitForStackToSettle(stackName)
+		spinner.Pop()
+
+		if status != "IMPORT_COMPLETE" {
+			for _, message := range messages {
+				fmt.Println(console.Red(message))
+			}
+			panic(fmt.Errorf("import of %s failed: %s", id, status))
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Imported %s into %s as %s", identifier, stackName, id)))
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVar(&logicalIdFlag, "logical-id", "", "The logical ID for the resource in the template; by default it is made from the type and identifier")
+	Cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't ask before changing the template and the stack")
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the template block without changing the template or the stack")
+}
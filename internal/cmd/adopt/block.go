@@ -0,0 +1,160 @@
+package adopt
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"gopkg.in/yaml.v3"
+)
+
+// minimalProperties returns the properties in the resource model that can be set in a template.
+// Read-only properties, empty values and tags reserved by AWS are left out.
+func minimalProperties(model map[string]any, readOnly []string) map[string]any {
+	skip := make(map[string]bool)
+	for _, p := range readOnly {
+		skip[strings.TrimPrefix(p, "/properties/")] = true
+	}
+
+	props := make(map[string]any)
+	for name, value := range model {
+		if skip[name] || isEmpty(value) {
+			continue
+		}
+
+		if name == "Tags" {
+			value = userTags(value)
+			if isEmpty(value) {
+				continue
+			}
+		}
+
+		props[name] = value
+	}
+
+	return props
+}
+
+func isEmpty(value any) bool {
+	switch v := value.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case []any:
+		return len(v) == 0
+	case map[string]any:
+		return len(v) == 0
+	}
+
+	return false
+}
+
+// userTags removes tags with the reserved aws: prefix, which can't be set in a template
+func userTags(value any) any {
+	tags, ok := value.([]any)
+	if !ok {
+		return value
+	}
+
+	out := make([]any, 0, len(tags))
+	for _, tag := range tags {
+		if m, ok := tag.(map[string]any); ok {
+			if key, _ := m["Key"].(string); strings.HasPrefix(key, "aws:") {
+				continue
+			}
+		}
+		out = append(out, tag)
+	}
+
+	return out
+}
+
+// resourceIdentifier maps the type's primary identifier properties to the values in identifier,
+// which separates the values of compound identifiers with |
+func resourceIdentifier(properties []string, identifier string) (map[string]string, error) {
+	values := strings.Split(identifier, "|")
+	if len(values) != len(properties) {
+		return nil, fmt.Errorf("the identifier needs %d values separated by |: %s",
+			len(properties), strings.Join(properties, ", "))
+	}
+
+	out := make(map[string]string)
+	for i, p := range properties {
+		out[p] = values[i]
+	}
+
+	return out, nil
+}
+
+var nonAlphanumeric = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// logicalId makes a logical ID for the resource from its type and identifier
+// that doesn't clash with another resource in the template
+func logicalId(t cft.Template, typeName string, identifier string) string {
+	parts := strings.Split(typeName, "::")
+	name := parts[len(parts)-1]
+
+	for _, word := range nonAlphanumeric.Split(identifier, -1) {
+		if word != "" {
+			name += strings.ToUpper(word[:1]) + word[1:]
+		}
+	}
+
+	if len(name) > 255 {
+		name = name[:255]
+	}
+
+	id := name
+	for i := 2; ; i++ {
+		if _, err := t.GetResource(id); err != nil {
+			return id
+		}
+		id = fmt.Sprintf("%s%d", name, i)
+	}
+}
+
+// addResource adds the resource to the template with a Retain deletion policy,
+// which CloudFormation requires for every resource that is imported
+func addResource(t cft.Template, logicalId string, typeName string, props map[string]any) error {
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil {
+		if resources, err = t.AddMapSection(cft.Resources); err != nil {
+			return err
+		}
+	}
+
+	if _, err := t.GetResource(logicalId); err == nil {
+		return fmt.Errorf("the template already has a resource named %s", logicalId)
+	}
+
+	resource := &yaml.Node{Kind: yaml.MappingNode}
+	node.Add(resource, "Type", typeName)
+	node.Add(resource, "DeletionPolicy", "Retain")
+	node.Add(resource, "UpdateReplacePolicy", "Retain")
+
+	if len(props) > 0 {
+		p := &yaml.Node{}
+		if err := p.Encode(props); err != nil {
+			return err
+		}
+		node.SetMapValue(resource, "Properties", p)
+	}
+
+	node.SetMapValue(resources, logicalId, resource)
+
+	return nil
+}
+
+// blockTemplate returns a template that only holds the resource, to show it to the user
+func blockTemplate(logicalId string, resource *yaml.Node) cft.Template {
+	resources := &yaml.Node{Kind: yaml.MappingNode}
+	node.SetMapValue(resources, logicalId, resource)
+
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	node.SetMapValue(root, string(cft.Resources), resources)
+
+	return cft.Template{Node: &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}}
+}
@@ -0,0 +1,78 @@
+package adopt
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+func TestMinimalProperties(t *testing.T) {
+	model := map[string]any{
+		"BucketName": "my-bucket",
+		"Arn":        "arn:aws:s3:::my-bucket",
+		"DomainName": "my-bucket.s3.amazonaws.com",
+		"Empty":      "",
+		"Tags": []any{
+			map[string]any{"Key": "aws:cloudformation:stack-name", "Value": "old"},
+			map[string]any{"Key": "team", "Value": "data"},
+		},
+	}
+
+	props := minimalProperties(model, []string{"/properties/Arn", "/properties/DomainName"})
+
+	if len(props) != 2 || props["BucketName"] != "my-bucket" {
+		t.Errorf("unexpected properties: %v", props)
+	}
+
+	if tags := props["Tags"].([]any); len(tags) != 1 {
+		t.Errorf("expected the aws: tag to be removed: %v", tags)
+	}
+}
+
+func TestResourceIdentifier(t *testing.T) {
+	id, err := resourceIdentifier([]string{"Cluster", "ServiceArn"}, "my-cluster|arn:aws:ecs:service")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if id["Cluster"] != "my-cluster" || id["ServiceArn"] != "arn:aws:ecs:service" {
+		t.Errorf("unexpected identifier: %v", id)
+	}
+
+	if _, err := resourceIdentifier([]string{"Cluster", "ServiceArn"}, "my-cluster"); err == nil {
+		t.Error("expected an error for a missing value")
+	}
+}
+
+func TestAddResource(t *testing.T) {
+	tmpl, err := parse.String(`
+Resources:
+  BucketMyBucket:
+    Type: AWS::S3::Bucket
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := logicalId(tmpl, "AWS::S3::Bucket", "my-bucket")
+	if id != "BucketMyBucket2" {
+		t.Errorf("expected BucketMyBucket2, got %s", id)
+	}
+
+	if err := addResource(tmpl, id, "AWS::S3::Bucket", map[string]any{"BucketName": "my-bucket"}); err != nil {
+		t.Fatal(err)
+	}
+
+	out := format.String(tmpl, format.Options{})
+	for _, expected := range []string{"BucketMyBucket2:", "DeletionPolicy: Retain", "BucketName: my-bucket"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected %q in:\n%s", expected, out)
+		}
+	}
+
+	if err := addResource(tmpl, id, "AWS::S3::Bucket", nil); err == nil {
+		t.Error("expected an error for a duplicate logical ID")
+	}
+}
@@ -0,0 +1,182 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var duration time.Duration
+var baselinePath string
+var savePath string
+var threshold float64
+var jsonFlag bool
+
+// Cmd is the bench command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "bench <path>...",
+	Short: "Measure how fast rain parses, formats and diffs templates",
+	Long: `Runs rain's parser, formatter and diff on the templates at the given paths
+and reports the time and memory each operation takes. Directories are searched for
+.yaml, .yml, .json and .template files.
+
+Each operation runs on every template repeatedly for --duration. Times and allocations
+are per run over all of the templates, and throughput is measured in template source bytes.
+
+Use --save to record the results as a baseline, and --baseline to compare a later run with it.
+The command exits with a non-zero status if any operation is slower, or allocates more,
+than the baseline by more than --threshold percent.`,
+	Args:                  cobra.MinimumNArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		paths, err := findTemplates(args)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to find templates"))
+		}
+
+		templates, err := Load(paths)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to load templates"))
+		}
+
+		results := make([]Result, 0, len(Operations))
+		for _, op := range Operations {
+			spinner.Push(fmt.Sprintf("Measuring %s", op.Name))
+			r, err := Measure(op, templates, duration)
+			spinner.Pop()
+			if err != nil {
+				panic(ui.Errorf(err, "benchmark failed"))
+			}
+			results = append(results, r)
+		}
+
+		var changes []Change
+		if baselinePath != "" {
+			b, err := LoadBaseline(baselinePath)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to load baseline"))
+			}
+			changes = b.Compare(results, threshold)
+		}
+
+		if savePath != "" {
+			if err := (Baseline{Templates: paths, Results: results}).Save(savePath); err != nil {
+				panic(ui.Errorf(err, "unable to save baseline"))
+			}
+		}
+
+		if jsonFlag {
+			out, err := json.MarshalIndent(struct {
+				Results []Result `json:"results"`
+				Changes []Change `json:"changes,omitempty"`
+			}{results, changes}, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else {
+			printResults(len(templates), results, changes)
+		}
+
+		for _, c := range changes {
+			if c.Regression {
+				exitcode.Exit(exitcode.Invalid)
+			}
+		}
+	},
+}
+
+// findTemplates returns the files in paths, searching directories for templates
+func findTemplates(paths []string) ([]string, error) {
+	files := make([]string, 0)
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".yaml", ".yml", ".json", ".template":
+				if !d.IsDir() {
+					files = append(files, p)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", strings.Join(paths, ", "))
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func printResults(count int, results []Result, changes []Change) {
+	fmt.Println(console.Yellow(fmt.Sprintf("Benchmarks for %d templates:", count)))
+
+	byOperation := make(map[string]Change)
+	for _, c := range changes {
+		byOperation[c.Operation] = c
+	}
+
+	for _, r := range results {
+		line := fmt.Sprintf("  %-12s %12s/op %8.2f MB/s %10d allocs/op %12d B/op",
+			r.Operation,
+			time.Duration(r.NsPerOp),
+			r.MBPerSec,
+			r.AllocsPerOp,
+			r.BytesPerOp,
+		)
+
+		if c, ok := byOperation[r.Operation]; ok {
+			delta := fmt.Sprintf("time %+.1f%% allocs %+.1f%%", c.Time, c.Allocs)
+			switch {
+			case c.Regression:
+				line += "  " + console.Red(delta)
+			case c.Time < -threshold:
+				line += "  " + console.Green(delta)
+			default:
+				line += "  " + console.Grey(delta)
+			}
+		}
+
+		fmt.Println(line)
+	}
+}
+
+func init() {
+	Cmd.Flags().DurationVar(&duration, "duration", time.Second, "How long to run each operation")
+	Cmd.Flags().StringVar(&baselinePath, "baseline", "", "Baseline file to compare the results with")
+	Cmd.Flags().StringVar(&savePath, "save", "", "Save the results to a baseline file")
+	Cmd.Flags().Float64Var(&threshold, "threshold", 10, "Percentage slowdown or growth in allocations from the baseline that counts as a regression")
+	Cmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output the results as JSON")
+}
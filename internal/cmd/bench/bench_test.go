@@ -0,0 +1,80 @@
+package bench
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMeasure(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("Resources:\n  Bucket:\n    Type: AWS::S3::Bucket\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not a template"), 0644)
+
+	paths, err := findTemplates([]string{dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || filepath.Base(paths[0]) != "a.yaml" {
+		t.Fatalf("unexpected templates %v", paths)
+	}
+
+	templates, err := Load(paths)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, op := range Operations {
+		r, err := Measure(op, templates, time.Millisecond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if r.Operation != op.Name || r.Iterations < 1 || r.NsPerOp <= 0 || r.MBPerSec <= 0 {
+			t.Errorf("unexpected result %+v", r)
+		}
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	b := Baseline{
+		Templates: []string{"a.yaml"},
+		Results: []Result{
+			{Operation: "parse", NsPerOp: 1000, AllocsPerOp: 100},
+			{Operation: "format", NsPerOp: 1000, AllocsPerOp: 100},
+			{Operation: "diff", NsPerOp: 1000, AllocsPerOp: 100},
+		},
+	}
+	if err := b.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := LoadBaseline(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changes := b.Compare([]Result{
+		{Operation: "parse", NsPerOp: 1050, AllocsPerOp: 100},
+		{Operation: "format", NsPerOp: 800, AllocsPerOp: 150},
+		{Operation: "format-json", NsPerOp: 800, AllocsPerOp: 150},
+	}, 10)
+
+	expected := []Change{
+		{Operation: "parse", Time: 5, Allocs: 0, Regression: false},
+		{Operation: "format", Time: -20, Allocs: 50, Regression: true},
+	}
+
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, changes)
+	}
+
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("expected %+v, got %+v", expected[i], changes[i])
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/diff"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+// Template is a template to run the benchmarks on
+type Template struct {
+	Name   string
+	Source string
+
+	parsed cft.Template
+}
+
+// Operation is something rain does to a template that is timed
+type Operation struct {
+	Name string
+	Run  func(t *Template) error
+}
+
+// Operations are the benchmarks, in the order they run
+var Operations = []Operation{
+	{"parse", func(t *Template) error {
+		_, err := parse.String(t.Source)
+		return err
+	}},
+	{"format", func(t *Template) error {
+		format.String(t.parsed, format.Options{})
+		return nil
+	}},
+	{"format-json", func(t *Template) error {
+		format.String(t.parsed, format.Options{JSON: true})
+		return nil
+	}},
+	{"diff", func(t *Template) error {
+		diff.New(t.parsed, t.parsed)
+		return nil
+	}},
+}
+
+// Result is the performance of an operation across all of the templates
+type Result struct {
+	Operation string `json:"operation"`
+
+	// Iterations is how many times the operation ran on every template
+	Iterations int `json:"iterations"`
+
+	// NsPerOp is the time it takes to run the operation once on every template
+	NsPerOp int64 `json:"nsPerOp"`
+
+	// MBPerSec is the throughput, measured in template source bytes
+	MBPerSec float64 `json:"mbPerSec"`
+
+	AllocsPerOp uint64 `json:"allocsPerOp"`
+
+	BytesPerOp uint64 `json:"bytesPerOp"`
+}
+
+// Load reads templates and parses them, so that the operations that need a parsed template can use it
+func Load(paths []string) ([]*Template, error) {
+	templates := make([]*Template, 0, len(paths))
+
+	for _, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		parsed, err := parse.String(string(source))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse template '%s': %w", path, err)
+		}
+
+		templates = append(templates, &Template{Name: path, Source: string(source), parsed: parsed})
+	}
+
+	return templates, nil
+}
+
+// Measure runs the operation on every template repeatedly for at least d, and at least once
+func Measure(op Operation, templates []*Template, d time.Duration) (Result, error) {
+	size := 0
+	for _, t := range templates {
+		size += len(t.Source)
+	}
+
+	runAll := func() error {
+		for _, t := range templates {
+			if err := op.Run(t); err != nil {
+				return fmt.Errorf("%s failed on '%s': %w", op.Name, t.Name, err)
+			}
+		}
+		return nil
+	}
+
+	// Warm up, and fail early if the operation doesn't work
+	if err := runAll(); err != nil {
+		return Result{}, err
+	}
+
+	runtime.GC()
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	n := 0
+	start := time.Now()
+	for n == 0 || time.Since(start) < d {
+		if err := runAll(); err != nil {
+			return Result{}, err
+		}
+		n++
+	}
+	elapsed := time.Since(start)
+
+	runtime.ReadMemStats(&after)
+
+	r := Result{
+		Operation:   op.Name,
+		Iterations:  n,
+		NsPerOp:     elapsed.Nanoseconds() / int64(n),
+		AllocsPerOp: (after.Mallocs - before.Mallocs) / uint64(n),
+		BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / uint64(n),
+	}
+
+	if elapsed > 0 {
+		r.MBPerSec = float64(size) * float64(n) / 1e6 / elapsed.Seconds()
+	}
+
+	return r, nil
+}
+
+// Baseline is a saved set of results to compare later runs against
+type Baseline struct {
+	// Templates are the templates the results were measured on
+	Templates []string `json:"templates"`
+
+	Results []Result `json:"results"`
+}
+
+// LoadBaseline reads a baseline file
+func LoadBaseline(path string) (Baseline, error) {
+	var b Baseline
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return b, err
+	}
+
+	if err := json.Unmarshal(content, &b); err != nil {
+		return b, fmt.Errorf("unable to read baseline '%s': %w", path, err)
+	}
+
+	return b, nil
+}
+
+// Save writes the baseline to a file
+func (b Baseline) Save(path string) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// Change compares a result with the baseline's result for the same operation
+type Change struct {
+	Operation string `json:"operation"`
+
+	// Time and Allocs are the percentage changes; positive numbers are slower or bigger
+	Time   float64 `json:"time"`
+	Allocs float64 `json:"allocs"`
+
+	// Regression is set if the time or allocations grew by more than the threshold
+	Regression bool `json:"regression"`
+}
+
+func percent(old, new float64) float64 {
+	if old == 0 {
+		return 0
+	}
+
+	return (new - old) / old * 100
+}
+
+// Compare returns the changes from the baseline, for the operations that are in both.
+// A change is a regression if the time or allocations grew by more than threshold percent.
+func (b Baseline) Compare(results []Result, threshold float64) []Change {
+	old := make(map[string]Result)
+	for _, r := range b.Results {
+		old[r.Operation] = r
+	}
+
+	changes := make([]Change, 0)
+	for _, r := range results {
+		o, ok := old[r.Operation]
+		if !ok {
+			continue
+		}
+
+		c := Change{
+			Operation: r.Operation,
+			Time:      percent(float64(o.NsPerOp), float64(r.NsPerOp)),
+			Allocs:    percent(float64(o.AllocsPerOp), float64(r.AllocsPerOp)),
+		}
+		c.Regression = c.Time > threshold || c.Allocs > threshold
+
+		changes = append(changes, c)
+	}
+
+	return changes
+}
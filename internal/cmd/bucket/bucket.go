@@ -0,0 +1,140 @@
+// Package bucket contains commands for managing rain's artifact bucket
+package bucket
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/aws/s3"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var yes bool
+
+// Cmd is the bucket command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "bucket <command>",
+	Short: "Manage rain's artifact bucket",
+	Long: `Manage the S3 bucket that rain uses to store packaged artifacts.
+
+The bucket is called rain-artifacts-<AWS account id>-<AWS region> unless --s3-bucket is set.
+It is created with default encryption, all public access blocked, and a lifecycle rule
+that expires artifacts after 7 days.`,
+}
+
+// InfoCmd is the bucket info command's entrypoint
+var InfoCmd = &cobra.Command{
+	Use:                   "info",
+	Short:                 "Show the name and contents of the artifact bucket",
+	Args:                  cobra.NoArgs,
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		spinner.Push("Looking up artifact bucket")
+		bucketName, err := s3.RainBucketName()
+		if err != nil {
+			panic(err)
+		}
+
+		exists, err := s3.BucketExists(bucketName)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to confirm whether artifact bucket exists"))
+		}
+		spinner.Pop()
+
+		fmt.Printf("%s: %s\n", console.Yellow("Bucket"), bucketName)
+
+		if !exists {
+			fmt.Printf("%s: %s\n", console.Yellow("Status"), console.Grey("does not exist; create it with rain bucket create"))
+			return
+		}
+
+		spinner.Push("Listing artifacts")
+		objects, err := s3.ListObjects(bucketName, s3.BucketKeyPrefix)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to list artifacts in '%s'", bucketName))
+		}
+		spinner.Pop()
+
+		var size int64
+		for _, o := range objects {
+			if o.Size != nil {
+				size += *o.Size
+			}
+		}
+
+		fmt.Printf("%s: %d (%s)\n", console.Yellow("Objects"), len(objects), formatSize(size))
+	},
+}
+
+// CreateCmd is the bucket create command's entrypoint
+var CreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create the artifact bucket or update its settings",
+	Long: `Creates the artifact bucket if it does not exist.
+If it already exists, re-applies rain's encryption, public access block, and lifecycle settings.`,
+	Args:                  cobra.NoArgs,
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		spinner.Push("Looking up artifact bucket")
+		bucketName, err := s3.RainBucketName()
+		if err != nil {
+			panic(err)
+		}
+
+		exists, err := s3.BucketExists(bucketName)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to confirm whether artifact bucket exists"))
+		}
+		spinner.Pop()
+
+		if !exists {
+			s3.RainBucket(yes)
+			fmt.Println(console.Green(fmt.Sprintf("Created artifact bucket '%s'", bucketName)))
+			return
+		}
+
+		spinner.Push(fmt.Sprintf("Configuring artifact bucket '%s'", bucketName))
+		err = s3.ConfigureBucket(bucketName)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to configure artifact bucket '%s'", bucketName))
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Updated settings for artifact bucket '%s'", bucketName)))
+	},
+}
+
+func formatSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+func addCommonParams(c *cobra.Command) {
+	c.Flags().StringVarP(&config.Profile, "profile", "p", "", "AWS profile name; read from the AWS CLI configuration file")
+	c.Flags().StringVarP(&config.Region, "region", "r", "", "AWS region to use")
+	c.Flags().StringVar(&s3.BucketName, "s3-bucket", "", "Name of the S3 bucket that is used to upload assets")
+	c.Flags().StringVar(&s3.BucketKeyPrefix, "s3-prefix", "", "Prefix to add to objects uploaded to S3 bucket")
+	c.Flags().BoolVar(&config.Debug, "debug", false, "Output debugging information")
+}
+
+func init() {
+	CreateCmd.Flags().BoolVarP(&yes, "yes", "y", false, "create the bucket without asking for confirmation")
+
+	for _, c := range []*cobra.Command{InfoCmd, CreateCmd, CleanupCmd} {
+		addCommonParams(c)
+		Cmd.AddCommand(c)
+	}
+}
@@ -0,0 +1,171 @@
+package bucket
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/aws/s3"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var olderThan time.Duration
+var dryRun bool
+
+// artifactKey matches the content hashes that rain uses as artifact names
+var artifactKey = regexp.MustCompile(`[0-9a-f]{64}`)
+
+// unreferenced returns the keys of artifacts that were uploaded before cutoff
+// and whose content hash does not appear in any of the references.
+// Objects that rain did not upload as artifacts are never returned.
+func unreferenced(objects []types.Object, references []string, cutoff time.Time) []string {
+	used := make(map[string]bool)
+	for _, ref := range references {
+		for _, hash := range artifactKey.FindAllString(ref, -1) {
+			used[hash] = true
+		}
+	}
+
+	keys := make([]string, 0)
+	for _, o := range objects {
+		key := ptr.ToString(o.Key)
+		name := path.Base(key)
+
+		if len(name) != 64 || !artifactKey.MatchString(name) {
+			continue
+		}
+
+		if used[name] {
+			continue
+		}
+
+		if o.LastModified == nil || !o.LastModified.Before(cutoff) {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// references returns the template and parameter values of every live stack in the region
+func references() ([]string, error) {
+	stacks, err := cfn.ListStacks()
+	if err != nil {
+		return nil, ui.Errorf(err, "unable to list stacks")
+	}
+
+	refs := make([]string, 0)
+
+	for _, summary := range stacks {
+		name := ptr.ToString(summary.StackName)
+
+		spinner.Push(fmt.Sprintf("Checking stack '%s'", name))
+
+		template, err := cfn.GetStackTemplate(name, false)
+		if err != nil {
+			spinner.Pop()
+			return nil, ui.Errorf(err, "unable to get template for stack '%s'", name)
+		}
+		refs = append(refs, template)
+
+		stack, err := cfn.GetStack(name)
+		spinner.Pop()
+		if err != nil {
+			return nil, ui.Errorf(err, "unable to get parameters of stack '%s'", name)
+		}
+
+		for _, p := range stack.Parameters {
+			refs = append(refs, ptr.ToString(p.ParameterValue))
+		}
+	}
+
+	return refs, nil
+}
+
+// CleanupCmd is the bucket cleanup command's entrypoint
+var CleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Delete artifacts that are not used by any stack",
+	Long: `Deletes artifacts from the artifact bucket that are not referenced by the template
+or parameters of any stack in the region.
+
+Only objects that rain uploaded as packaged artifacts are considered, and artifacts that were
+uploaded more recently than --older-than are kept so that deployments in progress are not affected.`,
+	Args:                  cobra.NoArgs,
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		spinner.Push("Looking up artifact bucket")
+		bucketName, err := s3.RainBucketName()
+		if err != nil {
+			panic(err)
+		}
+
+		exists, err := s3.BucketExists(bucketName)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to confirm whether artifact bucket exists"))
+		}
+		spinner.Pop()
+
+		if !exists {
+			fmt.Printf("Artifact bucket '%s' does not exist\n", bucketName)
+			return
+		}
+
+		spinner.Push("Listing artifacts")
+		objects, err := s3.ListObjects(bucketName, s3.BucketKeyPrefix)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to list artifacts in '%s'", bucketName))
+		}
+
+		refs, err := references()
+		if err != nil {
+			panic(err)
+		}
+
+		keys := unreferenced(objects, refs, time.Now().Add(-olderThan))
+
+		if len(keys) == 0 {
+			fmt.Println(console.Green("Nothing to clean up"))
+			return
+		}
+
+		fmt.Println(console.Yellow(fmt.Sprintf("Unused artifacts in '%s':", bucketName)))
+		for _, key := range keys {
+			fmt.Printf("  %s\n", key)
+		}
+
+		if dryRun {
+			return
+		}
+
+		if !yes && !console.Confirm(false, fmt.Sprintf("Are you sure you want to delete %d artifacts?", len(keys))) {
+			panic(errors.New("user cancelled clean up"))
+		}
+
+		spinner.Push("Deleting artifacts")
+		err = s3.DeleteObjects(bucketName, keys)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to delete artifacts"))
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Deleted %d artifacts", len(keys))))
+	},
+}
+
+func init() {
+	CleanupCmd.Flags().DurationVar(&olderThan, "older-than", 24*time.Hour, "only delete artifacts uploaded longer ago than this")
+	CleanupCmd.Flags().BoolVar(&dryRun, "dry-run", false, "list the artifacts that would be deleted without deleting them")
+	CleanupCmd.Flags().BoolVarP(&yes, "yes", "y", false, "don't ask questions; just delete")
+}
@@ -0,0 +1,36 @@
+package bucket
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestUnreferenced(t *testing.T) {
+	used := strings.Repeat("a", 64)
+	unused := strings.Repeat("b", 64)
+	recent := strings.Repeat("c", 64)
+
+	old := time.Now().Add(-48 * time.Hour)
+	now := time.Now()
+
+	objects := []types.Object{
+		{Key: ptr.String("prefix/" + used), LastModified: &old},
+		{Key: ptr.String("prefix/" + unused), LastModified: &old},
+		{Key: ptr.String("prefix/" + recent), LastModified: &now},
+		{Key: ptr.String("deployments/state.yaml"), LastModified: &old},
+	}
+
+	refs := []string{
+		"Code:\n  S3Bucket: rain-artifacts\n  S3Key: prefix/" + used,
+	}
+
+	keys := unreferenced(objects, refs, now.Add(-24*time.Hour))
+
+	if len(keys) != 1 || keys[0] != "prefix/"+unused {
+		t.Errorf("unexpected keys: %v", keys)
+	}
+}
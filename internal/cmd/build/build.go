@@ -11,6 +11,7 @@ import (
 	"github.com/aws-cloudformation/rain/cft"
 	"github.com/aws-cloudformation/rain/cft/format"
 	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/cmd/build/patterns"
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/node"
@@ -25,9 +26,12 @@ var promptFlag = false
 var showSchema = false
 var omitPatches = false
 var recommendFlag = false
+var patternFlag = ""
+var patternParams []string
 var outFn = ""
 var pklClass = false
 var noCache = false
+var commentsFlag = false
 var promptLanguage = "cfn"
 var model string
 var models map[string]string
@@ -202,6 +206,7 @@ func buildNode(n *yaml.Node, s cfn.SchemaLike, schema *cfn.Schema, ancestorTypes
 				if err != nil {
 					return err
 				}
+				addComment(n, p, schema, true)
 			} else {
 				config.Debugf("invalid: %+v", s)
 				return fmt.Errorf("invalid schema: required property %s not found in properties", requiredName)
@@ -218,11 +223,57 @@ func buildNode(n *yaml.Node, s cfn.SchemaLike, schema *cfn.Schema, ancestorTypes
 			if err != nil {
 				return err
 			}
+			addComment(n, p, schema, slices.Contains(s.GetRequired(), k))
 		}
 	}
 	return nil
 }
 
+// addComment describes the property that was just added to n in a line comment, if --comments is set
+func addComment(n *yaml.Node, p *cfn.Prop, schema *cfn.Schema, required bool) {
+	if !commentsFlag || n.Kind != yaml.MappingNode || len(n.Content) < 2 {
+		return
+	}
+
+	description := p.Description
+	if description == "" && p.Ref != "" {
+		if def, ok := schema.Definitions[fixRef(p.Ref)]; ok {
+			description = def.Description
+		}
+	}
+
+	n.Content[len(n.Content)-2].LineComment = describe(description, required)
+}
+
+// describe returns a one-line comment for a property
+func describe(description string, required bool) string {
+	description = strings.Join(strings.Fields(description), " ")
+
+	// Keep the first sentence, since some descriptions are several paragraphs long
+	if i := strings.Index(description, ". "); i > 0 {
+		description = description[:i+1]
+	}
+
+	const maxLength = 120
+	if len(description) > maxLength {
+		description = strings.TrimSpace(description[:maxLength-3]) + "..."
+	}
+
+	if required {
+		if description == "" {
+			description = "Required"
+		} else {
+			description = "Required. " + description
+		}
+	}
+
+	if description == "" {
+		return ""
+	}
+
+	return "# " + description
+}
+
 func startTemplate() cft.Template {
 
 	t := cft.Template{}
@@ -273,10 +324,15 @@ func build(typeNames []string) (cft.Template, error) {
 			return t, err
 		}
 
-		// Add a node for the resource
-		shortName := strings.Split(typeName, "::")[2]
+		// Add a node for the resource.
+		// Private types can be named by their ARN, to choose a version.
+		name := cfn.TypeName(typeName)
+		shortName := strings.Split(name, "::")[2]
 		r := node.AddMap(resourceMap, shortName)
-		node.Add(r, "Type", typeName)
+		if commentsFlag && schema.Description != "" {
+			resourceMap.Content[len(resourceMap.Content)-2].HeadComment = describe(schema.Description, false)
+		}
+		node.Add(r, "Type", name)
 		props := node.AddMap(r, "Properties")
 
 		// Recursively build the node
@@ -353,9 +409,13 @@ func basicBuild(args []string) {
 
 // Cmd is the build command's entrypoint
 var Cmd = &cobra.Command{
-	Use:                   "build [<resource type>] or <prompt>",
-	Short:                 "Create CloudFormation templates",
-	Long:                  "The build command interacts with the CloudFormation registry to list types, output schema files, and build starter CloudFormation templates containing the named resource types.",
+	Use:   "build [<resource type>] or <prompt>",
+	Short: "Create CloudFormation templates",
+	Long: `The build command interacts with the CloudFormation registry to list types, output schema files, and build starter CloudFormation templates containing the named resource types. Use --comments to describe each property in the template, and --bare to include only the required properties. Private registry types can be named by type name, or by ARN to use a particular version, e.g. arn:aws:cloudformation:us-east-1:123456789012:type/resource/MyOrg-Networking-VPC/00000003
+
+Use --pattern to start from a complete, opinionated template for a common architecture instead of a blank file. Rain asks for the few values that the pattern needs, or you can set them with --params, e.g. rain build --pattern vpc --params Cidr=10.1.0.0/16. The patterns are:
+
+` + patternHelp(),
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
 
@@ -377,6 +437,13 @@ var Cmd = &cobra.Command{
 			return
 		}
 
+		// --pattern
+		// Output a starter template from the pattern library
+		if patternFlag != "" {
+			buildPattern(patternFlag)
+			return
+		}
+
 		// --schema -s
 		// Download and print out the registry schema
 		if showSchema {
@@ -454,9 +521,12 @@ func init() {
 	Cmd.Flags().BoolVar(&config.Debug, "debug", false, "Output debugging information")
 	Cmd.Flags().BoolVar(&omitPatches, "omit-patches", false, "Omit patches and use the raw schema")
 	Cmd.Flags().BoolVar(&recommendFlag, "recommend", false, "Output a recommended architecture for the chosen use case")
+	Cmd.Flags().StringVar(&patternFlag, "pattern", "", "Output a starter template for a common architecture: "+strings.Join(patterns.Names(), ", "))
+	Cmd.Flags().StringSliceVar(&patternParams, "params", []string{}, "Set the values that --pattern asks for; use the format key1=value1,key2=value2")
 	Cmd.Flags().StringVarP(&outFn, "output", "o", "", "Output to a file")
 	Cmd.Flags().BoolVar(&pklClass, "pkl-class", false, "Output a pkl class based on a resource type schema")
 	Cmd.Flags().BoolVar(&noCache, "no-cache", false, "Do not used cached schema files")
+	Cmd.Flags().BoolVarP(&commentsFlag, "comments", "c", false, "Add comments that describe each property, using the registry schema")
 	Cmd.Flags().StringVar(&promptLanguage, "prompt-lang", "cfn", "The language to target for --prompt, CloudFormation YAML (cfn), CloudFormation Guard (guard), Open Policy Agent Rego (rego)")
 	Cmd.Flags().StringVar(&model, "model", "claude2", "The ID of the Bedrock model to use for --prompt. Shorthand: claude2, claude3haiku, claude3sonnet, claude3opus, claude3.5sonnet")
 }
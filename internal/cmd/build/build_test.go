@@ -2,11 +2,13 @@ package build
 
 import (
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/aws-cloudformation/rain/cft"
 	"github.com/aws-cloudformation/rain/internal/aws/cfn"
 	"github.com/aws-cloudformation/rain/internal/node"
+	"github.com/aws-cloudformation/rain/internal/s11n"
 )
 
 const SCHEMAS = "../../../test/schemas/"
@@ -68,3 +70,40 @@ func TestBareMetricFilter(t *testing.T) {
 	path := SCHEMAS + "aws-logs-metricfilter.json"
 	fromFile(path, "AWS::Logs::MetricFilter", "MyMetricFilter", true, t)
 }
+
+func TestComments(t *testing.T) {
+	commentsFlag = true
+	defer func() { commentsFlag = false }()
+
+	path := SCHEMAS + "aws-logs-metricfilter.json"
+	template := fromFile(path, "AWS::Logs::MetricFilter", "MyMetricFilter", true, t)
+
+	_, props, _ := s11n.GetMapValue(template.Node.Content[0], "Resources")
+	_, props, _ = s11n.GetMapValue(props, "MyMetricFilter")
+	_, props, _ = s11n.GetMapValue(props, "Properties")
+
+	key, _, _ := s11n.GetMapValue(props, "LogGroupName")
+	expected := "# Required. Existing log group that you want to associate with this filter."
+	if key == nil || key.LineComment != expected {
+		t.Errorf("expected %q, got %+v", expected, key)
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	cases := []struct {
+		description string
+		required    bool
+		expected    string
+	}{
+		{"", false, ""},
+		{"", true, "# Required"},
+		{"The name  of the\nbucket. Other details.", false, "# The name of the bucket."},
+		{strings.Repeat("a", 200), false, "# " + strings.Repeat("a", 117) + "..."},
+	}
+
+	for _, c := range cases {
+		if actual := describe(c.description, c.required); actual != c.expected {
+			t.Errorf("expected %q, got %q", c.expected, actual)
+		}
+	}
+}
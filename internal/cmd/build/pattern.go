@@ -0,0 +1,72 @@
+package build
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/internal/cmd/build/patterns"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/ui"
+)
+
+// buildPattern writes the starter template for a pattern from the library,
+// asking for the values of any knobs that were not set with --params
+func buildPattern(name string) {
+	p, err := patterns.Get(name)
+	if err != nil {
+		panic(err)
+	}
+
+	values := dc.ListToMap("params", patternParams)
+
+	if console.CanAsk() {
+		asked := make(map[string]string)
+		for _, k := range p.Knobs {
+			if value, ok := values[k.Name]; ok {
+				asked[k.Name] = value
+				continue
+			}
+
+			if !p.Asks(k, asked) {
+				continue
+			}
+
+			for {
+				value := console.AskWithDefault(k.Prompt+":", k.Default)
+				if err := k.Check(value); err != nil {
+					fmt.Println(console.Red(err))
+					continue
+				}
+				values[k.Name] = value
+				asked[k.Name] = value
+				break
+			}
+		}
+	}
+
+	t, err := p.Render(values)
+	if err != nil {
+		panic(ui.Errorf(err, "unable to build pattern '%s'", name))
+	}
+
+	output(format.String(t, format.Options{
+		JSON: buildJSON,
+	}))
+}
+
+// patternHelp describes the patterns in the library and their knobs for the command's help
+func patternHelp() string {
+	out := &strings.Builder{}
+
+	for _, p := range patterns.All() {
+		knobs := make([]string, 0, len(p.Knobs))
+		for _, k := range p.Knobs {
+			knobs = append(knobs, k.Name)
+		}
+		fmt.Fprintf(out, "  %-16s %s (%s)\n", p.Name, p.Description, strings.Join(knobs, ", "))
+	}
+
+	return out.String()
+}
@@ -0,0 +1,218 @@
+// Package patterns is a library of opinionated starter templates for common
+// architectures, used by rain build --pattern.
+//
+// Each pattern is a Go text/template in the templates directory. The knobs of a
+// pattern are the handful of values, like a CIDR block or a domain name, that
+// are needed to render it; everything else is a reasonable default that can be
+// changed once the template has been written.
+package patterns
+
+import (
+	"embed"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Knob is a value that is asked for when a pattern is rendered
+type Knob struct {
+	Name    string
+	Prompt  string
+	Default string
+
+	// AllowedValues, if set, are the only values the knob accepts
+	AllowedValues []string
+
+	// Pattern, if set, is a regular expression that the value must match
+	Pattern string
+
+	// Optional knobs may be left empty
+	Optional bool
+
+	// Requires is the name of another knob that must be set for this knob to be asked for
+	Requires string
+}
+
+// Check returns an error if the value is not valid for the knob
+func (k Knob) Check(value string) error {
+	if value == "" {
+		if k.Optional {
+			return nil
+		}
+		return fmt.Errorf("%s is required", k.Name)
+	}
+
+	if len(k.AllowedValues) > 0 && !slices.Contains(k.AllowedValues, value) {
+		return fmt.Errorf("%s must be one of %s, not '%s'", k.Name, strings.Join(k.AllowedValues, ", "), value)
+	}
+
+	if k.Pattern != "" && !regexp.MustCompile(k.Pattern).MatchString(value) {
+		return fmt.Errorf("'%s' is not a valid value for %s", value, k.Name)
+	}
+
+	return nil
+}
+
+// Pattern is a starter template for a common architecture
+type Pattern struct {
+	Name        string
+	Description string
+	Knobs       []Knob
+}
+
+var all = []Pattern{
+	{
+		Name:        "vpc",
+		Description: "A VPC with public and private subnets in two availability zones and a NAT gateway",
+		Knobs: []Knob{
+			{
+				Name:    "Cidr",
+				Prompt:  "VPC CIDR block (/16 to /20)",
+				Default: "10.0.0.0/16",
+				Pattern: `^(\d{1,3}\.){3}\d{1,3}/(1[6-9]|20)$`,
+			},
+		},
+	},
+	{
+		Name:        "static-site",
+		Description: "A private S3 bucket served by CloudFront, optionally on your own domain",
+		Knobs: []Knob{
+			{
+				Name:     "DomainName",
+				Prompt:   "Domain name (leave empty to use the CloudFront domain)",
+				Pattern:  `^([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)+[a-z]{2,}$`,
+				Optional: true,
+			},
+			{
+				Name:     "HostedZoneId",
+				Prompt:   "Route 53 hosted zone ID for the domain",
+				Pattern:  `^Z[A-Z0-9]+$`,
+				Requires: "DomainName",
+			},
+		},
+	},
+	{
+		Name:        "fargate-service",
+		Description: "A container running on ECS Fargate behind an application load balancer",
+		Knobs: []Knob{
+			{
+				Name:    "Image",
+				Prompt:  "Container image",
+				Default: "public.ecr.aws/nginx/nginx:latest",
+				Pattern: `^\S+$`,
+			},
+			{
+				Name:    "ContainerPort",
+				Prompt:  "Container port",
+				Default: "80",
+				Pattern: `^[1-9][0-9]{0,4}$`,
+			},
+			{
+				Name:          "Cpu",
+				Prompt:        "Task CPU units",
+				Default:       "256",
+				AllowedValues: []string{"256", "512", "1024", "2048", "4096"},
+			},
+			{
+				Name:    "Memory",
+				Prompt:  "Task memory (MiB)",
+				Default: "512",
+				Pattern: `^[1-9][0-9]*$`,
+			},
+		},
+	},
+	{
+		Name:        "lambda-api",
+		Description: "An HTTP API backed by a Lambda function",
+		Knobs: []Knob{
+			{
+				Name:          "Runtime",
+				Prompt:        "Lambda runtime",
+				Default:       "python3.12",
+				AllowedValues: []string{"python3.12", "python3.13", "nodejs20.x", "nodejs22.x"},
+			},
+		},
+	},
+}
+
+// All returns the patterns in the library
+func All() []Pattern {
+	return all
+}
+
+// Names returns the names of the patterns in the library
+func Names() []string {
+	names := make([]string, 0, len(all))
+	for _, p := range all {
+		names = append(names, p.Name)
+	}
+	return names
+}
+
+// Get returns the named pattern
+func Get(name string) (Pattern, error) {
+	for _, p := range all {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+
+	return Pattern{}, fmt.Errorf("unknown pattern '%s'; choose one of %s", name, strings.Join(Names(), ", "))
+}
+
+// Asks returns whether the knob is asked for, given the values of the knobs before it
+func (p Pattern) Asks(k Knob, values map[string]string) bool {
+	return k.Requires == "" || values[k.Requires] != ""
+}
+
+// Render checks the values of the pattern's knobs and returns the rendered template.
+// Knobs that are not asked for are left empty.
+func (p Pattern) Render(values map[string]string) (cft.Template, error) {
+	data := make(map[string]string)
+
+	for name := range values {
+		if !slices.ContainsFunc(p.Knobs, func(k Knob) bool { return k.Name == name }) {
+			return cft.Template{}, fmt.Errorf("pattern '%s' has no knob named '%s'", p.Name, name)
+		}
+	}
+
+	for _, k := range p.Knobs {
+		if !p.Asks(k, data) {
+			data[k.Name] = ""
+			continue
+		}
+
+		value, ok := values[k.Name]
+		if !ok {
+			value = k.Default
+		}
+
+		if err := k.Check(value); err != nil {
+			return cft.Template{}, err
+		}
+
+		data[k.Name] = value
+	}
+
+	tmpl, err := template.New(p.Name).Funcs(template.FuncMap{
+		"hasPrefix": strings.HasPrefix,
+	}).ParseFS(templateFiles, "templates/"+p.Name+".yaml")
+	if err != nil {
+		return cft.Template{}, err
+	}
+
+	out := &strings.Builder{}
+	if err := tmpl.ExecuteTemplate(out, p.Name+".yaml", data); err != nil {
+		return cft.Template{}, err
+	}
+
+	return parse.String(out.String())
+}
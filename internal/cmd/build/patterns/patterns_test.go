@@ -0,0 +1,108 @@
+package patterns_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/internal/cmd/build/patterns"
+)
+
+func TestRenderDefaults(t *testing.T) {
+	for _, p := range patterns.All() {
+		tmpl, err := p.Render(map[string]string{})
+		if err != nil {
+			t.Fatalf("%s: %v", p.Name, err)
+		}
+
+		resources, err := tmpl.GetSection("Resources")
+		if err != nil || len(resources.Content) == 0 {
+			t.Errorf("%s: expected resources", p.Name)
+		}
+	}
+}
+
+func TestRenderKnobs(t *testing.T) {
+	p, err := patterns.Get("vpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := p.Render(map[string]string{"Cidr": "10.1.0.0/20"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out := format.String(tmpl, format.Options{}); !strings.Contains(out, "CidrBlock: 10.1.0.0/20") {
+		t.Errorf("expected the CIDR block in:\n%s", out)
+	}
+
+	for _, values := range []map[string]string{
+		{"Cidr": "10.1.0.0/24"},
+		{"Cidr": "not a cidr"},
+		{"Domain": "example.com"},
+	} {
+		if _, err := p.Render(values); err == nil {
+			t.Errorf("expected an error for %v", values)
+		}
+	}
+}
+
+func TestRenderOptional(t *testing.T) {
+	p, err := patterns.Get("static-site")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := p.Render(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out := format.String(tmpl, format.Options{}); strings.Contains(out, "Certificate") {
+		t.Errorf("expected no certificate without a domain:\n%s", out)
+	}
+
+	// The hosted zone is required once there is a domain
+	if _, err := p.Render(map[string]string{"DomainName": "www.example.com"}); err == nil {
+		t.Error("expected an error without a hosted zone")
+	}
+
+	tmpl, err = p.Render(map[string]string{"DomainName": "www.example.com", "HostedZoneId": "Z0123456789ABC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := format.String(tmpl, format.Options{})
+	for _, expected := range []string{"AcmCertificateArn: !Ref Certificate", "Value: https://www.example.com"} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected '%s' in:\n%s", expected, out)
+		}
+	}
+}
+
+func TestRenderRuntime(t *testing.T) {
+	p, err := patterns.Get("lambda-api")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := p.Render(map[string]string{"Runtime": "nodejs20.x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out := format.String(tmpl, format.Options{}); !strings.Contains(out, "exports.handler") {
+		t.Errorf("expected a Node.js handler in:\n%s", out)
+	}
+
+	if _, err := p.Render(map[string]string{"Runtime": "cobol"}); err == nil {
+		t.Error("expected an error for an unknown runtime")
+	}
+}
+
+func TestGet(t *testing.T) {
+	if _, err := patterns.Get("monolith"); err == nil || !strings.Contains(err.Error(), "fargate-service") {
+		t.Errorf("expected the available patterns in the error, got %v", err)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
 	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/manifest"
 	"github.com/aws-cloudformation/rain/internal/ui"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/spf13/cobra"
@@ -23,6 +24,9 @@ import (
 // PackageTemplate reads the template and performs any necessary packaging on it
 // before deployment. The rain bucket will be created if it does not already exist.
 func PackageTemplate(fn string, yes bool) cft.Template {
+	if err := manifest.UseProject(); err != nil {
+		panic(ui.Errorf(err, "unable to load %s", manifest.DefaultFileName))
+	}
 
 	t, err := pkg.File(fn)
 	if err != nil {
@@ -8,6 +8,7 @@ import (
 
 	"github.com/aws-cloudformation/rain/cft/parse"
 	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
 	"github.com/aws-cloudformation/rain/internal/aws/sts"
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/node"
@@ -173,26 +174,13 @@ func resolvePseudoParam(name string) (string, error) {
 		// TODO: Needs special handling to remove nodes from the template
 		return "", errors.New("unsupported: AWS::NoValue")
 	case "Partition":
-		region := aws.Config().Region
-		if strings.HasPrefix(region, "us-gov") {
-			return "aws-us-gov", nil
-		}
-		if strings.HasPrefix(region, "cn-") {
-			return "aws-cn", nil
-		}
-		if strings.HasPrefix(region, "us-iso-") {
-			return "aws-iso", nil
-		}
-		if strings.HasPrefix(region, "us-isob-") {
-			return "aws-iso-b", nil
-		}
-		return "aws", nil
+		return partition.ForRegion(aws.Config().Region).ID, nil
 	case "StackId":
 		return "", errors.New("unsupported: AWS::StackId")
 	case "StackName":
 		return "", errors.New("unsupported: AWS::StackName")
 	case "URLSuffix":
-		return "", errors.New("unsupported: AWS::URLSuffix")
+		return partition.ForRegion(aws.Config().Region).URLSuffix, nil
 	default:
 		return "", fmt.Errorf("unexpected AWS::%s", p)
 	}
@@ -0,0 +1,29 @@
+// Package changeset contains commands for managing change sets
+// that are not covered by "rain deploy", "rain ls -c", and "rain rm -c"
+package changeset
+
+import (
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the changeset command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "changeset <command>",
+	Short: "Manage change sets",
+	Long: `Manage change sets.
+
+Use "rain deploy --no-exec" to create a change set, "rain deploy --changeset" to execute one,
+"rain ls -c" to list them, and "rain rm -c" to delete one.`,
+}
+
+func addCommonParams(c *cobra.Command) {
+	c.Flags().StringVarP(&config.Profile, "profile", "p", "", "AWS profile name; read from the AWS CLI configuration file")
+	c.Flags().StringVarP(&config.Region, "region", "r", "", "AWS region to use")
+	c.Flags().BoolVar(&config.Debug, "debug", false, "Output debugging information")
+}
+
+func init() {
+	addCommonParams(GcCmd)
+	Cmd.AddCommand(GcCmd)
+}
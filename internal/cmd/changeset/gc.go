@@ -0,0 +1,203 @@
+package changeset
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var olderThan time.Duration
+var dryRun bool
+var yes bool
+
+// orphan is a change set that was created but never executed
+type orphan struct {
+	stackName     string
+	changeSetName string
+	status        string
+	created       time.Time
+}
+
+// garbage is everything that gc will delete
+type garbage struct {
+	// ghosts are stacks in REVIEW_IN_PROGRESS, which were created
+	// by a change set that was never executed. Deleting a ghost
+	// stack also deletes its change sets.
+	ghosts []types.StackSummary
+
+	orphans []orphan
+}
+
+// findGarbage returns the ghost stacks and orphaned change sets that were created before cutoff
+func findGarbage(stacks []types.StackSummary, changeSets map[string][]types.ChangeSetSummary, cutoff time.Time) garbage {
+	g := garbage{
+		ghosts:  make([]types.StackSummary, 0),
+		orphans: make([]orphan, 0),
+	}
+
+	for _, stack := range stacks {
+		name := ptr.ToString(stack.StackName)
+
+		if stack.StackStatus == types.StackStatusReviewInProgress {
+			if stack.CreationTime != nil && stack.CreationTime.Before(cutoff) {
+				g.ghosts = append(g.ghosts, stack)
+			}
+			continue
+		}
+
+		for _, cs := range changeSets[name] {
+			if cs.CreationTime == nil || !cs.CreationTime.Before(cutoff) {
+				continue
+			}
+
+			// Leave change sets alone while CloudFormation is working on them
+			if cs.ExecutionStatus == types.ExecutionStatusExecuteInProgress ||
+				cs.Status == types.ChangeSetStatusCreateInProgress ||
+				cs.Status == types.ChangeSetStatusCreatePending ||
+				cs.Status == types.ChangeSetStatusDeletePending ||
+				cs.Status == types.ChangeSetStatusDeleteInProgress {
+				continue
+			}
+
+			g.orphans = append(g.orphans, orphan{
+				stackName:     name,
+				changeSetName: ptr.ToString(cs.ChangeSetName),
+				status:        string(cs.Status),
+				created:       *cs.CreationTime,
+			})
+		}
+	}
+
+	return g
+}
+
+// GcCmd is the changeset gc command's entrypoint
+var GcCmd = &cobra.Command{
+	Use:   "gc [stack]",
+	Short: "Delete change sets that were never executed",
+	Long: `Finds and deletes change sets that were created but never executed, and stacks that are
+stuck in REVIEW_IN_PROGRESS because the change set that created them was never executed.
+
+Only change sets and stacks older than --older-than are deleted, so that change sets
+that are waiting for review are left alone. If [stack] is supplied, only that stack is checked.
+
+Run with --dry-run to see what would be deleted, or with --yes to run without prompting,
+e.g. as a scheduled job.`,
+	Args:                  cobra.MaximumNArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		var stacks []types.StackSummary
+
+		spinner.Push("Listing stacks")
+		if len(args) == 1 {
+			stack, err := cfn.GetStack(args[0])
+			if err != nil {
+				panic(ui.Errorf(err, "unable to get stack '%s'", args[0]))
+			}
+			stacks = []types.StackSummary{{
+				StackName:    stack.StackName,
+				StackStatus:  stack.StackStatus,
+				CreationTime: stack.CreationTime,
+			}}
+		} else {
+			var err error
+			stacks, err = cfn.ListStacks()
+			if err != nil {
+				panic(ui.Errorf(err, "unable to list stacks"))
+			}
+		}
+		spinner.Pop()
+
+		changeSets := make(map[string][]types.ChangeSetSummary)
+		for _, stack := range stacks {
+			if stack.StackStatus == types.StackStatusReviewInProgress {
+				continue
+			}
+
+			name := ptr.ToString(stack.StackName)
+
+			spinner.Push(fmt.Sprintf("Listing change sets for stack '%s'", name))
+			sets, err := cfn.ListChangeSets(name)
+			spinner.Pop()
+			if err != nil {
+				panic(ui.Errorf(err, "unable to list change sets for stack '%s'", name))
+			}
+
+			changeSets[name] = sets
+		}
+
+		g := findGarbage(stacks, changeSets, time.Now().Add(-olderThan))
+
+		if len(g.ghosts) == 0 && len(g.orphans) == 0 {
+			fmt.Println(console.Green("Nothing to clean up"))
+			return
+		}
+
+		if len(g.ghosts) > 0 {
+			fmt.Println(console.Yellow("Stacks in REVIEW_IN_PROGRESS:"))
+			for _, stack := range g.ghosts {
+				fmt.Printf("  %s (created %s)\n", ptr.ToString(stack.StackName), stack.CreationTime.Format(time.RFC3339))
+			}
+		}
+
+		if len(g.orphans) > 0 {
+			fmt.Println(console.Yellow("Change sets that were never executed:"))
+			for _, o := range g.orphans {
+				fmt.Printf("  %s %s %s (created %s)\n", o.stackName, console.Blue(o.changeSetName),
+					ui.ColouriseStatus(o.status), o.created.Format(time.RFC3339))
+			}
+		}
+
+		if dryRun {
+			return
+		}
+
+		if !yes && !console.Confirm(false, "Are you sure you want to delete these?") {
+			panic(errors.New("user cancelled clean up"))
+		}
+
+		failed := false
+
+		for _, o := range g.orphans {
+			spinner.Push(fmt.Sprintf("Deleting change set '%s'", o.changeSetName))
+			err := cfn.DeleteChangeSet(o.stackName, o.changeSetName)
+			spinner.Pop()
+			if err != nil {
+				fmt.Println(console.Red(fmt.Sprintf("Unable to delete change set '%s' on stack '%s': %s", o.changeSetName, o.stackName, err)))
+				failed = true
+			}
+		}
+
+		for _, stack := range g.ghosts {
+			name := ptr.ToString(stack.StackName)
+
+			spinner.Push(fmt.Sprintf("Deleting stack '%s'", name))
+			err := cfn.DeleteStack(name, "")
+			spinner.Pop()
+			if err != nil {
+				fmt.Println(console.Red(fmt.Sprintf("Unable to delete stack '%s': %s", name, err)))
+				failed = true
+			}
+		}
+
+		if failed {
+			panic(errors.New("some items could not be deleted"))
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Deleted %d change sets and %d stacks", len(g.orphans), len(g.ghosts))))
+	},
+}
+
+func init() {
+	GcCmd.Flags().DurationVar(&olderThan, "older-than", 7*24*time.Hour, "only delete change sets and stacks created longer ago than this, e.g. 24h")
+	GcCmd.Flags().BoolVar(&dryRun, "dry-run", false, "list what would be deleted without deleting anything")
+	GcCmd.Flags().BoolVarP(&yes, "yes", "y", false, "don't ask questions; just delete")
+}
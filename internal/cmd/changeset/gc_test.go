@@ -0,0 +1,41 @@
+package changeset
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestFindGarbage(t *testing.T) {
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	cutoff := now.Add(-24 * time.Hour)
+
+	stacks := []types.StackSummary{
+		{StackName: ptr.String("ghost"), StackStatus: types.StackStatusReviewInProgress, CreationTime: &old},
+		{StackName: ptr.String("new-ghost"), StackStatus: types.StackStatusReviewInProgress, CreationTime: &now},
+		{StackName: ptr.String("app"), StackStatus: types.StackStatusUpdateComplete, CreationTime: &old},
+	}
+
+	changeSets := map[string][]types.ChangeSetSummary{
+		"app": {
+			{ChangeSetName: ptr.String("stale"), Status: types.ChangeSetStatusCreateComplete, CreationTime: &old},
+			{ChangeSetName: ptr.String("failed"), Status: types.ChangeSetStatusFailed, CreationTime: &old},
+			{ChangeSetName: ptr.String("recent"), Status: types.ChangeSetStatusCreateComplete, CreationTime: &now},
+			{ChangeSetName: ptr.String("running"), Status: types.ChangeSetStatusCreateComplete,
+				ExecutionStatus: types.ExecutionStatusExecuteInProgress, CreationTime: &old},
+		},
+	}
+
+	g := findGarbage(stacks, changeSets, cutoff)
+
+	if len(g.ghosts) != 1 || ptr.ToString(g.ghosts[0].StackName) != "ghost" {
+		t.Errorf("unexpected ghosts: %v", g.ghosts)
+	}
+
+	if len(g.orphans) != 2 || g.orphans[0].changeSetName != "stale" || g.orphans[1].changeSetName != "failed" {
+		t.Errorf("unexpected orphans: %v", g.orphans)
+	}
+}
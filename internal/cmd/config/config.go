@@ -0,0 +1,16 @@
+package config
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the config command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "config <command>",
+	Short: "Work with rain config files",
+	Long:  "Work with the config files that set the parameters and tags of stacks and stack sets.",
+}
+
+func init() {
+	Cmd.AddCommand(ExportCmd)
+}
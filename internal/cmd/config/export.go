@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/cmd/stackset"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var isStackSet bool
+var admin bool
+
+// ExportCmd is the config export command's entrypoint
+var ExportCmd = &cobra.Command{
+	Use:   "export <stack> [file]",
+	Short: "Write the config file of a deployed stack",
+	Long: `Reads the parameters and tags of a deployed stack and writes them to [file], or to stdout,
+in the format that the --config flag of rain deploy reads. This makes it easy to start
+using rain with stacks that were created with other tools.
+
+With --stackset, <stack> is the name of a stack set. The config file is in the format that
+rain stackset deploy reads, and also has the stack set's settings and the accounts, regions,
+or organizational units that its instances are deployed to.
+
+CloudFormation doesn't return the values of NoEcho parameters, so they are left out
+of the config file. Add them by hand, or set them with rain-ssm:// or
+rain-secretsmanager:// references.`,
+	Args:                  cobra.RangeArgs(1, 2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		var content string
+		var warnings []string
+		var err error
+
+		if isStackSet {
+			spinner.Push(fmt.Sprintf("Getting config from stack set '%s'", name))
+			content, warnings, err = stackset.ConfigFromStackSet(name, admin)
+			spinner.Pop()
+			if err != nil {
+				panic(ui.Errorf(err, "unable to get configuration for stack set '%s'", name))
+			}
+		} else {
+			spinner.Push(fmt.Sprintf("Getting config from stack '%s'", name))
+			stack, err := cfn.GetStack(name)
+			spinner.Pop()
+			if err != nil {
+				panic(ui.Errorf(err, "failed to get stack '%s'", name))
+			}
+
+			var noEcho []string
+			content, noEcho, err = dc.ExportStack(stack)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to get configuration for stack '%s'", name))
+			}
+
+			for _, p := range noEcho {
+				warnings = append(warnings, fmt.Sprintf("parameter '%s' is NoEcho, so its value is not exported", p))
+			}
+		}
+
+		for _, w := range warnings {
+			console.Warnf("%s", w)
+		}
+
+		if len(args) == 1 {
+			fmt.Print(content)
+			return
+		}
+
+		fn := args[1]
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			panic(ui.Errorf(err, "unable to write '%s'", fn))
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Wrote %s", fn)))
+	},
+}
+
+func init() {
+	ExportCmd.Flags().BoolVar(&isStackSet, "stackset", false, "<stack> is the name of a stack set")
+	ExportCmd.Flags().BoolVar(&admin, "admin", false, "Use delegated admin permissions for the stack set")
+	ExportCmd.Flags().StringVarP(&config.Profile, "profile", "p", "", "AWS profile name; read from the AWS CLI configuration file")
+	ExportCmd.Flags().StringVarP(&config.Region, "region", "r", "", "AWS region to use")
+}
@@ -11,24 +11,33 @@ var userName = ""
 
 // Cmd is the console command's entrypoint
 var Cmd = &cobra.Command{
-	Use:   "console [stack]",
+	Use:   "console [stack] [resource]",
 	Short: "Login to the AWS console",
 	Long: `Use your current credentials to create a sign-in URL for the AWS console and open it in a web browser.
 
 If you supply a stack name (and didn't use the --service option), the browser will open with that stack selected.
 
+If you also supply the logical ID of a resource in the stack, the browser will open at that resource's page
+in its service's console, e.g. the bucket's page in the S3 console. Resources without a page of their own
+are shown in the stack's list of resources.
+
 The console command is only valid with an IAM role; not an IAM user.
 
 Unless you specify the --name/-n flag, your AWS console user name will be derived from the role name.`,
-	Args:                  cobra.MaximumNArgs(1),
+	Args:                  cobra.MaximumNArgs(2),
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
 		stackName := ""
-		if len(args) == 1 {
+		if len(args) > 0 {
 			stackName = args[0]
 		}
 
-		Open(printOnlyFlag, logoutFlag, serviceParam, stackName, userName)
+		logicalId := ""
+		if len(args) > 1 {
+			logicalId = args[1]
+		}
+
+		Open(printOnlyFlag, logoutFlag, serviceParam, stackName, logicalId, userName)
 	},
 }
 
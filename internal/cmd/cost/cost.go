@@ -0,0 +1,151 @@
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/cost"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/pricing"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var formatFlag string
+var hours float64
+var maxMonthly float64
+
+// Cmd is the cost command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "cost <template>",
+	Short: "Estimate the monthly cost of a CloudFormation template",
+	Long: `Estimates the monthly cost of the resources in a template with on-demand prices from the AWS Pricing API.
+
+Resources with a fixed price, like EC2 instances, RDS instances, EBS volumes, ElastiCache clusters,
+NAT gateways and load balancers, are priced from their properties. A property that refers to a
+parameter is priced with the parameter's default value. Resources that are billed by usage,
+like Lambda functions and S3 buckets, are listed but not included in the total.
+
+The estimate assumes that resources are deployed to the configured region and run for --hours
+each month. Data transfer, requests and other usage charges are not included.
+
+Use --format json to get the estimate in a form that scripts can read, and --max to fail
+with a non-zero exit code when the total is too high, e.g. as a budget gate in CI.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fn := args[0]
+
+		if formatFlag != "text" && formatFlag != "json" {
+			panic(fmt.Errorf("unknown format '%s'; use text or json", formatFlag))
+		}
+
+		t, err := parse.File(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		assumptions := cost.Assumptions{
+			Region: aws.Config().Region,
+			Hours:  hours,
+		}
+
+		spinner.Push("Looking up prices")
+		report, err := cost.Template(t, assumptions, pricer())
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to estimate the cost of '%s'", fn))
+		}
+
+		emit.Event("cost", report)
+
+		if formatFlag == "json" {
+			out, err := json.MarshalIndent(report, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else {
+			printReport(fn, report)
+		}
+
+		if maxMonthly > 0 && report.Total > maxMonthly {
+			if formatFlag != "json" {
+				console.Failf("Estimated cost $%.2f is above the maximum of $%.2f", report.Total, maxMonthly)
+			}
+			exitcode.Exit(exitcode.Invalid)
+		}
+	},
+}
+
+// pricer looks prices up with the Pricing API,
+// and only looks up each distinct product once
+func pricer() cost.Pricer {
+	cache := make(map[string]float64)
+
+	return func(q cost.Query) (float64, error) {
+		fields := make([]string, 0, len(q.Filters))
+		for field, value := range q.Filters {
+			fields = append(fields, field+"="+value)
+		}
+		sort.Strings(fields)
+		key := q.ServiceCode + "|" + q.Unit + "|" + strings.Join(fields, "|")
+
+		if price, ok := cache[key]; ok {
+			return price, nil
+		}
+
+		price, err := pricing.GetPrice(q.ServiceCode, q.Filters, q.Unit)
+		if err != nil {
+			config.Debugf("Unable to get price for %s: %v", key, err)
+			return 0, err
+		}
+
+		cache[key] = price
+
+		return price, nil
+	}
+}
+
+func printReport(fn string, report cost.Report) {
+	fmt.Printf("%s: %s\n", fn, console.Yellow(fmt.Sprintf("$%.2f per month", report.Total)))
+	fmt.Println()
+
+	if len(report.Resources) > 0 {
+		fmt.Println(console.Yellow("Resources:"))
+
+		for _, r := range report.Resources {
+			if r.Priced {
+				fmt.Printf("  %10s  %s (%s) %s\n", fmt.Sprintf("$%.2f", r.Monthly), r.LogicalId, r.Type, console.Grey(r.Note))
+			} else {
+				fmt.Printf("  %10s  %s (%s) %s\n", "-", r.LogicalId, r.Type, console.Grey(r.Note))
+			}
+		}
+
+		fmt.Println()
+	}
+
+	fmt.Println(console.Yellow("Assumptions:"))
+	fmt.Printf("  Region:          %s\n", report.Assumptions.Region)
+	fmt.Printf("  Hours per month: %v\n", report.Assumptions.Hours)
+	fmt.Println("  On-demand prices in USD; usage, data transfer and taxes are not included")
+
+	if report.Unpriced > 0 {
+		fmt.Println()
+		fmt.Println(console.Yellow(fmt.Sprintf("%d resources are not included in the total", report.Unpriced)))
+	}
+}
+
+func init() {
+	Cmd.Flags().StringVar(&formatFlag, "format", "text", "Output format: text or json")
+	Cmd.Flags().Float64Var(&hours, "hours", cost.HoursPerMonth, "The number of hours a month that resources run for")
+	Cmd.Flags().Float64Var(&maxMonthly, "max", 0, "Exit with a non-zero status if the estimated monthly cost is above this value")
+}
@@ -0,0 +1,354 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/aws/route53"
+	"github.com/aws-cloudformation/rain/internal/aws/ssm"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/hooks"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+	"github.com/aws-cloudformation/rain/internal/strategy"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// validateHook is what the strategy's Validate commands are called when they run
+const validateHook = "validate"
+
+// blueGreen is a deployment of a new stack alongside the live one, following the config file's Strategy
+type blueGreen struct {
+	strategy *strategy.Strategy
+
+	// name is the stack's name without a suffix
+	name string
+
+	// live is the stack that the alias points at now, or empty if this is the first deployment
+	live string
+
+	// next is the stack that the template is deployed as
+	next string
+
+	// locked is true if the live stack has been locked with rain protect, which the new stack inherits
+	locked bool
+
+	// unprotect is true if the live stack's termination protection may be turned off to delete it
+	unprotect bool
+}
+
+// deployed returns true if the stack has been created, even if its last update failed
+func deployed(stackName string) bool {
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		return false
+	}
+
+	switch stack.StackStatus {
+	case types.StackStatusReviewInProgress, types.StackStatusRollbackComplete,
+		types.StackStatusRollbackFailed, types.StackStatusRollbackInProgress,
+		types.StackStatusCreateFailed, types.StackStatusCreateInProgress:
+		return false
+	}
+
+	return true
+}
+
+// aliasValue returns what the alias is set to now, reading the first of its SSM parameter, Route 53 record or export
+func aliasValue(a strategy.Alias, stackName string) (string, error) {
+	switch {
+	case a.SSMParameter != "":
+		return ssm.GetParameter(a.SSMParameter)
+	case a.Route53 != nil:
+		return route53.GetRecordValue(a.Route53.HostedZoneId, a.Route53.Name, a.Route53.Type)
+	default:
+		return cfn.GetStackOutputValue(strategy.AliasStack(stackName), "Alias")
+	}
+}
+
+// aliasedTo returns a function that reports whether the alias points at a stack,
+// which is the case if the alias is set to the stack's value of the alias Output.
+// The alias is only read the first time the function is called.
+func aliasedTo(a strategy.Alias, stackName string) func(string) (bool, error) {
+	var current string
+	var err error
+	read := false
+
+	return func(candidate string) (bool, error) {
+		if !read {
+			current, err = aliasValue(a, stackName)
+			read = true
+		}
+		if err != nil {
+			return false, ui.Errorf(err, "unable to read the alias of '%s'", stackName)
+		}
+
+		value, err := cfn.GetStackOutputValue(candidate, a.Output)
+		if err != nil {
+			return false, err
+		}
+
+		return value == current, nil
+	}
+}
+
+// blueGreenStacks returns the stacks to switch between if the config file has a blue-green Strategy,
+// or nil if it doesn't
+func blueGreenStacks(configPath, stackName string) *blueGreen {
+	if configPath == "" {
+		return nil
+	}
+
+	s, err := strategy.Load(configPath)
+	if err != nil {
+		panic(err)
+	}
+
+	if s == nil {
+		return nil
+	}
+
+	if detach || noexec || planOnly {
+		panic(errors.New("a blue-green Strategy can't be used with --detach, --no-exec or --plan-only, " +
+			"as rain needs to validate the new stack once it has deployed"))
+	}
+
+	spinner.Push(fmt.Sprintf("Finding the live stack for '%s'", stackName))
+	live, next, err := strategy.Stacks(stackName, deployed, aliasedTo(s.Alias, stackName))
+	spinner.Pop()
+	if err != nil {
+		panic(err)
+	}
+
+	bg := &blueGreen{
+		strategy: s,
+		name:     stackName,
+		live:     live,
+		next:     next,
+	}
+
+	if live != "" {
+		stack, err := cfn.GetStack(live)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to get the live stack '%s'", live))
+		}
+
+		// Switching over deletes the live stack, which a lock forbids without --unlock
+		if !s.KeepOld {
+			if err := cfn.CheckUnlocked(stack, unlock); err != nil {
+				panic(err)
+			}
+		}
+
+		bg.locked = cfn.IsLocked(stack)
+
+		// Ask before the deployment starts, rather than once the alias has been switched
+		if !s.KeepOld && ptr.ToBool(stack.EnableTerminationProtection) {
+			if !yes && !s.DeleteProtected && !console.Confirm(false, fmt.Sprintf("The live stack '%s' has termination protection enabled, "+
+				"so it must be disabled to delete the stack after switching over. Do you wish to disable it?", live)) {
+				panic(fmt.Errorf("user cancelled deployment of stack '%s'", stackName))
+			}
+
+			bg.unprotect = true
+		}
+	}
+
+	if live == "" {
+		console.Logf("Stack '%s' has no live stack yet, so it will be deployed as '%s'.", stackName, next)
+	} else {
+		console.Logf("Stack '%s' is live as '%s', so it will be deployed as '%s'.", stackName, live, next)
+	}
+
+	return bg
+}
+
+// switchOver validates the new stack, points the alias at it and deletes the old stack.
+// If the new stack fails validation, it is deleted, unless rollback is disabled with --keep,
+// and the alias is left as it was.
+func (bg *blueGreen) switchOver(s manifest.Stack, h hooks.Hooks) {
+	if len(bg.strategy.Validate) > 0 {
+		err := runHook(hooks.Hooks{validateHook: bg.strategy.Validate}, validateHook, bg.next, nil)
+		if err != nil {
+			bg.discard(s)
+			panic(exitcode.Wrap(exitcode.DeployFailed, onFailure(h, bg.next, err)))
+		}
+	}
+
+	a := bg.strategy.Alias
+
+	value, err := cfn.GetStackOutputValue(bg.next, a.Output)
+	if err != nil {
+		bg.discard(s)
+		panic(exitcode.Wrap(exitcode.DeployFailed, onFailure(h, bg.next, err)))
+	}
+
+	if err := bg.pointAlias(value); err != nil {
+		err = ui.Errorf(err, "unable to point the alias at stack '%s'", bg.next)
+		panic(exitcode.Wrap(exitcode.DeployFailed, onFailure(h, bg.next, err)))
+	}
+
+	if bg.live == "" {
+		console.Log(console.Green(fmt.Sprintf("Stack '%s' is live as '%s'", bg.name, bg.next)))
+		return
+	}
+
+	console.Log(console.Green(fmt.Sprintf("Switched '%s' from '%s' to '%s'", bg.name, bg.live, bg.next)))
+
+	if bg.strategy.KeepOld {
+		console.Logf("Kept the old stack '%s'. Remove it with: rain rm %s", bg.live, bg.live)
+		return
+	}
+
+	if err := bg.deleteOld(s); err != nil {
+		panic(exitcode.Wrap(exitcode.DeployFailed, ui.Errorf(err,
+			"the alias points at stack '%s', but the old stack '%s' could not be deleted", bg.next, bg.live)))
+	}
+}
+
+// pointAlias sets each of the strategy's aliases to value
+func (bg *blueGreen) pointAlias(value string) error {
+	a := bg.strategy.Alias
+	description := fmt.Sprintf("The %s output of stack '%s', set by rain", a.Output, bg.next)
+
+	if a.SSMParameter != "" {
+		spinner.Push(fmt.Sprintf("Setting SSM parameter '%s'", a.SSMParameter))
+		err := ssm.PutParameter(a.SSMParameter, value, description)
+		spinner.Pop()
+		if err != nil {
+			return err
+		}
+
+		console.Logf("SSM parameter '%s' is now %s", a.SSMParameter, console.Cyan(value))
+	}
+
+	if r := a.Route53; r != nil {
+		spinner.Push(fmt.Sprintf("Setting %s record '%s'", r.Type, r.Name))
+		err := route53.UpsertRecord(r.HostedZoneId, r.Name, r.Type, r.TTL, value, description)
+		spinner.Pop()
+		if err != nil {
+			return err
+		}
+
+		console.Logf("%s record '%s' is now %s", r.Type, r.Name, console.Cyan(value))
+	}
+
+	if a.Export != "" {
+		if err := bg.exportAlias(value); err != nil {
+			return err
+		}
+
+		console.Logf("Export '%s' is now %s", a.Export, console.Cyan(value))
+	}
+
+	return nil
+}
+
+// exportAlias deploys the stack that exports the alias, with the export set to value.
+// CloudFormation won't change an export that another stack imports,
+// so stacks that follow the alias should read it in a way that can change, such as !StackOutput.
+func (bg *blueGreen) exportAlias(value string) error {
+	stackName := strategy.AliasStack(bg.name)
+
+	template, err := parse.Map(map[string]interface{}{
+		"Description": fmt.Sprintf("The alias of the live stack of '%s', which rain sets", bg.name),
+		"Resources": map[string]interface{}{
+			"Alias": map[string]interface{}{
+				"Type": "AWS::CloudFormation::WaitConditionHandle",
+			},
+		},
+		"Outputs": map[string]interface{}{
+			"Alias": map[string]interface{}{
+				"Value": value,
+				"Export": map[string]interface{}{
+					"Name": bg.strategy.Alias.Export,
+				},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	spinner.Push(fmt.Sprintf("Creating change set for stack '%s'", stackName))
+	changeSetName, err := cfn.CreateChangeSet(template, nil, nil, stackName, "", cfn.ChangeSetOptions{})
+	spinner.Pop()
+	if err != nil {
+		if cfn.ChangeSetHasNoChanges(err) {
+			return nil
+		}
+		return err
+	}
+
+	if err := cfn.ExecuteChangeSet(stackName, changeSetName, false); err != nil {
+		return err
+	}
+
+	status, _ := cfn.WaitForStackToSettle(stackName)
+	if !succeeded(status) {
+		return fmt.Errorf("stack '%s' is %s", stackName, status)
+	}
+
+	return nil
+}
+
+// deleteOld deletes the stack that the alias pointed at before
+func (bg *blueGreen) deleteOld(s manifest.Stack) error {
+	stack, err := cfn.GetStack(bg.live)
+	if err != nil {
+		return err
+	}
+
+	if err := cfn.CheckUnlocked(stack, unlock); err != nil {
+		return err
+	}
+
+	if ptr.ToBool(stack.EnableTerminationProtection) {
+		if !bg.unprotect {
+			return fmt.Errorf("stack '%s' has termination protection enabled; remove it with rain rm %s", bg.live, bg.live)
+		}
+
+		if err := cfn.SetTerminationProtection(bg.live, false); err != nil {
+			return err
+		}
+	}
+
+	console.Logf("Deleting the old stack '%s'.", bg.live)
+
+	if err := cfn.DeleteStack(bg.live, s.RoleArn); err != nil {
+		return err
+	}
+
+	status, _ := cfn.WaitForStackToSettle(bg.live)
+	if status != "DELETE_COMPLETE" {
+		return fmt.Errorf("stack '%s' is %s", bg.live, status)
+	}
+
+	return nil
+}
+
+// discard deletes the new stack after it failed validation, unless rollback is disabled with --keep
+func (bg *blueGreen) discard(s manifest.Stack) {
+	if keep {
+		console.Logf("Stack '%s' was kept because rollback is disabled. Remove it with: rain rm %s", bg.next, bg.next)
+		return
+	}
+
+	console.Logf("Deleting the new stack '%s'; the alias has not been changed.", bg.next)
+
+	if err := cfn.SetTerminationProtection(bg.next, false); err != nil {
+		console.Log(console.Yellow(fmt.Sprintf("Unable to delete stack '%s': %s", bg.next, err)))
+		return
+	}
+
+	if err := cfn.DeleteStack(bg.next, s.RoleArn); err != nil {
+		console.Log(console.Yellow(fmt.Sprintf("Unable to delete stack '%s': %s", bg.next, err)))
+		return
+	}
+
+	cfn.WaitForStackToSettle(bg.next)
+}
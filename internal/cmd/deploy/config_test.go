@@ -0,0 +1,75 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// TestParametersOnlyConfig checks that every reader of the deploy config file
+// accepts the aws cli's parameter list formats
+func TestParametersOnlyConfig(t *testing.T) {
+	template, err := parse.String(`
+Parameters:
+  Environment:
+    Type: String
+  InstanceType:
+    Type: String
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := map[string]string{
+		"overrides.json":  `["Environment=prod", "InstanceType=t3.micro"]`,
+		"parameters.json": `[{"ParameterKey": "Environment", "ParameterValue": "prod"}, {"ParameterKey": "InstanceType", "ParameterValue": "t3.micro"}]`,
+	}
+
+	dir := t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		config, err := dc.GetDeployConfig(nil, nil, path, "", template, types.Stack{}, false, true, false)
+		if err != nil {
+			t.Fatalf("%s: %s", name, err)
+		}
+
+		params := make(map[string]string)
+		for _, p := range config.Params {
+			params[ptr.ToString(p.ParameterKey)] = ptr.ToString(p.ParameterValue)
+		}
+		if params["Environment"] != "prod" || params["InstanceType"] != "t3.micro" {
+			t.Errorf("%s: unexpected parameters %v", name, params)
+		}
+
+		h, err := loadHooks(path)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+		} else if len(h) != 0 {
+			t.Errorf("%s: expected no hooks, got %v", name, h)
+		}
+
+		n, err := loadNotifications(path)
+		if err != nil {
+			t.Errorf("%s: %s", name, err)
+		} else if !n.IsEmpty() {
+			t.Errorf("%s: expected no notifications, got %v", name, n)
+		}
+
+		if bg := blueGreenStacks(path, "app"); bg != nil {
+			t.Errorf("%s: expected no blue-green strategy", name)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package deploy
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// deadline stops a stack operation that is still running after the stack's TimeoutInMinutes.
+// Change sets can't set a timeout, so rain enforces it while it waits for the stack.
+// An update is cancelled, which rolls the stack back.
+// A new stack is deleted, as CloudFormation does when a stack times out while it is created,
+// unless rollback is disabled with --keep.
+type deadline struct {
+	// at is when the deadline passes, or zero if the stack has no timeout
+	at time.Time
+
+	timer  *time.Timer
+	mu     sync.Mutex
+	action string
+}
+
+// startDeadline starts the clock on an operation on the stack.
+// It does nothing if the stack has no timeout.
+func startDeadline(s manifest.Stack) *deadline {
+	return startRegionDeadline("", s)
+}
+
+// startRegionDeadline is startDeadline for a stack in another region
+func startRegionDeadline(r cfn.Region, s manifest.Stack) *deadline {
+	d := &deadline{}
+
+	if s.TimeoutInMinutes <= 0 {
+		return d
+	}
+
+	timeout := time.Duration(s.TimeoutInMinutes) * time.Minute
+	d.at = time.Now().Add(timeout)
+
+	d.timer = time.AfterFunc(timeout, func() {
+		stack, err := r.GetStack(s.Name)
+		if err != nil {
+			return
+		}
+
+		var action string
+		switch stack.StackStatus {
+		case types.StackStatusUpdateInProgress:
+			action = "cancelled the update"
+			err = r.CancelUpdateStack(s.Name)
+		case types.StackStatusCreateInProgress:
+			if keep {
+				// CloudFormation can't cancel a create, so say how to clean up
+				action = fmt.Sprintf("left the stack as it is because rollback is disabled; "+
+					"once it has finished, remove it with: rain rm %s", s.Name)
+				break
+			}
+			action = "deleted the stack"
+			err = r.DeleteStack(s.Name, s.RoleArn)
+		default:
+			return
+		}
+
+		if err != nil {
+			config.Debugf("Unable to stop stack '%s' at its deadline: %v", s.Name, err)
+			action = "was unable to stop it: " + err.Error()
+		}
+
+		d.mu.Lock()
+		d.action = action
+		d.mu.Unlock()
+	})
+
+	return d
+}
+
+// stop stops the clock. If the deadline passed, it returns what was done to the stack.
+func (d *deadline) stop() string {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.action
+}
@@ -1,17 +1,28 @@
 package deploy
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 
 	"github.com/aws-cloudformation/rain/cft/format"
 	cftpkg "github.com/aws-cloudformation/rain/cft/pkg"
 	"github.com/aws-cloudformation/rain/internal/aws"
 	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/budget"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
 	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/hooks"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+	"github.com/aws-cloudformation/rain/internal/notify"
+	"github.com/aws-cloudformation/rain/internal/oci"
+	"github.com/aws-cloudformation/rain/internal/plan"
 	"github.com/aws-cloudformation/rain/internal/ui"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 
@@ -20,12 +31,17 @@ import (
 
 var detach bool
 var yes bool
+var force bool
+var unlock bool
 var params []string
 var tags []string
 var configFilePath string
 var terminationProtection bool
+var stackPolicyPath string
 var keep bool
 var roleArn string
+var timeout int
+var failOnEmpty bool
 var ignoreUnknownParams bool
 var noexec bool
 var changeset bool
@@ -38,14 +54,48 @@ var Cmd = &cobra.Command{
 	Long: `Creates or updates a CloudFormation stack named <stack> from the template file <template>. 
 You can also create and execute changesets with this command.
 If you don't specify a stack name, rain will use the template filename minus its extension.
+While the stack deploys, rain shows its events, and those of any nested stacks, as they happen.
+In a terminal, press the up and down arrow keys (or k and j) to choose a resource and enter to
+inspect it: its physical ID, properties, latest events, and the full reason for any failure are
+shown under the event log. Press escape to close the inspector.
 
 If a template needs to be packaged before it can be deployed, rain will package the template first.
 Rain will attempt to create an S3 bucket to store artifacts that it packages and deploys.
 The bucket's name will be of the format rain-artifacts-<AWS account id>-<AWS region>.
 
-The config flag can be used to programmatically set tags and parameters.
-The format is similar to the "Template configuration file" for AWS CodePipeline just without the
-'StackPolicy' key. The file can be in YAML or JSON format.
+Rain asks for the value of each parameter, showing its description and type and
+starting from its existing or default value. A parameter with AllowedValues is chosen
+from a list, values are checked against AllowedPattern and the other constraints
+before anything is deployed, and NoEcho values are hidden as they are typed.
+Use --yes or --no-input to deploy without being asked. With --no-input, rain stops
+if any parameter has no value instead of asking for it.
+
+When a stack is updated, a parameter that keeps its existing value is sent to
+CloudFormation as UsePreviousValue, so NoEcho values don't need to be entered again.
+Use --keep-params to keep the existing value of every parameter without being asked;
+only the parameters set with --params or the config file are changed.
+
+A parameter value that starts with rain-ssm:// or rain-secretsmanager:// is read from
+the SSM Parameter Store or Secrets Manager when the stack is deployed, so that secrets
+don't need to be written into config files or shell history:
+
+  --params DbPassword=rain-ssm:///app/db/password
+  --params DbPassword=rain-secretsmanager://app/db#password
+
+The part after # selects a key from a secret that is stored as JSON. The values are
+masked in rain's output; declare these parameters with NoEcho so that CloudFormation
+hides them too.
+
+A stack that has been locked with rain protect is only deployed to with --unlock,
+which keeps the lock.
+
+If the change set would replace or delete a resource that holds data, such as a database,
+table, bucket or file system, rain lists those resources and asks for the logical ID of each
+one to be typed before it deploys, even with --yes. Use --force to skip this in automation.
+
+The config flag can be used to programmatically set tags, parameters and a stack policy.
+The format is the same as the "Template configuration file" for AWS CodePipeline.
+The file can be in YAML or JSON format.
 
 JSON:
   {
@@ -66,6 +116,87 @@ YAML:
   Tags:
     TagKey: TagValue
     ...
+  Hooks:
+    pre_deploy: ./check-quota.sh
+    post_deploy:
+      - curl -fsS "$RAIN_OUTPUT_ApiUrl/health"
+    on_failure: ./notify.sh
+  StackPolicy:
+    Statement:
+      - Effect: Deny
+        Action: Update:Replace
+        Principal: "*"
+        Resource: LogicalResourceId/Database
+
+The config file can also be one of the parameter files that the aws cli reads:
+a JSON list of Key=Value strings, as used by "aws cloudformation deploy --parameter-overrides",
+or a list of parameters, as used by "aws cloudformation create-stack --parameters":
+
+  ["Environment=prod", "InstanceType=t3.micro"]
+
+  [{"ParameterKey": "Environment", "ParameterValue": "prod"},
+   {"ParameterKey": "Version", "UsePreviousValue": true}]
+
+Parameters with UsePreviousValue keep their existing values.
+
+The stack policy is set once the stack has been deployed, replacing any policy the stack had.
+Use --stack-policy to read the policy from a JSON file instead.
+
+Hooks run shell commands at points in the deployment: pre_package before the template
+is packaged, pre_deploy before the change set is executed, post_deploy once the stack
+has been deployed, and on_failure if the deployment or another hook fails. Each hook is
+a command or a list of commands. They are told about the stack with the environment
+variables RAIN_STACK_NAME, RAIN_REGION, RAIN_STACK_STATUS, RAIN_OUTPUT_<OutputKey>
+and, for on_failure, RAIN_ERROR. The deployment stops if a command fails.
+
+A Strategy in the config file deploys the stack blue/green: the template is deployed
+as a new stack alongside the live one, named <stack>-blue or <stack>-green. Once it has
+deployed, the Validate commands are run against it like hooks, an alias is set to one of
+its outputs, and the old stack is deleted. The alias can be an SSM parameter, a Route 53
+record, or an export, which rain keeps in a stack named <stack>-alias:
+
+  Strategy:
+    Type: blue-green
+    Validate: curl -fsS "$RAIN_OUTPUT_ApiUrl/health"
+    Alias:
+      Output: ApiUrl
+      SSMParameter: /app/api-url
+      Route53:
+        HostedZoneId: Z0123456789ABCDEFGHIJ
+        Name: api.example.com
+    KeepOld: false
+
+If validation fails, the new stack is deleted and the alias is left as it was.
+
+Rain can send a summary of the deployment when the change set is executed and when
+the stack has deployed or failed: the stack, its changes, how long it took, and the
+resource that caused a failure. Summaries are sent to Slack incoming webhooks (--notify-slack),
+SNS topics (--notify-sns) and HTTP endpoints that receive JSON (--notify-webhook),
+or to the targets in the config file. Events limits which summaries are sent:
+
+  Notifications:
+    Slack:
+      - https://hooks.slack.com/services/T000/B000/XXXX
+    SNS:
+      - arn:aws:sns:us-east-1:123456789012:deployments
+    Webhooks:
+      - https://deploy-log.example.com/events
+    Events: [started, succeeded, failed]
+
+A summary that can't be sent is reported, but doesn't stop the deployment.
+
+In GitHub Actions, rain adds a summary of the deployment to the job summary: its status and duration,
+a table of the changes, the stack's outputs, and a link to the stack in the console. In GitLab CI,
+the summary is appended to rain-summary.md, or the file that RAIN_SUMMARY_FILE names, which the job
+can keep as an artifact.
+
+A YAML config file can use the output of another stack as a value:
+
+  Parameters:
+    VpcId: !StackOutput network.VpcId
+
+Stack outputs are looked up once per run. To also cache them between runs,
+set RAIN_OUTPUT_CACHE_TTL to a duration such as 10m.
 
 To create a changeset (with optional stackName and changeSetName):
 
@@ -76,16 +207,199 @@ To execute a changeset:
 rain deploy --changeset <stackName> <changeSetName>
 
 To list and delete changesets, use the ls and rm commands.
+
+For pipelines that approve changes before they are made, create the change set
+and save it to a plan file, along with the packaged template it was created from:
+
+rain deploy --plan-only --out plan.json <template> [stackName]
+
+Once the plan has been reviewed, execute exactly that change set:
+
+rain deploy --apply plan.json
+
+The plan fails to apply if its change set has been deleted, or if the stack
+has changed since the plan was made. The stack policy is saved in the plan too.
+
+To enforce policies before deploying, pass a directory of cfn-guard rules (.guard)
+or OPA policies (.rego) with --policy. Policies in the directory are evaluated against
+the template, and policies in its changeset subdirectory are evaluated against the
+change set. Deployment stops if any rule denies it. OPA policies deny by defining
+deny rules whose values are messages. The cfn-guard and opa binaries must be on the PATH.
+
+A template that was pushed to an OCI registry with rain push can be deployed by
+its reference. The configuration file in the bundle is used unless --config is set:
+
+rain deploy oci://registry.example.com/team/network:v1.2.0 network
+
+With --cosign-key, the bundle must have a cosign signature made with the key.
+
+To deploy several stacks at once, list them in a manifest file (rain.yaml):
+
+  Stacks:
+    - Name: network
+      Template: network.yaml
+      Config: network-config.yaml
+    - Name: app
+      Template: app.yaml
+      DependsOn:
+        - network
+
+rain deploy --manifest rain.yaml
+
+Stacks are deployed in dependency order. Stacks that do not depend on each other
+are deployed in parallel. Deployment stops at the first failure.
+
+To deploy the same stack to several regions at once, without a stack set, list them with --regions:
+
+rain deploy --regions us-east-1,eu-west-1 template.yaml app
+
+A change set is created in each region in turn, packaging the template into that region's
+artifact bucket. Once they have all been created, they are executed together and rain shows
+the stack's status in each region until it has deployed everywhere. If a change set can't
+be created in one of the regions, nothing is deployed. A config file can set parameters and
+tags for each region, which take precedence over those for every region:
+
+  Parameters:
+    InstanceType: t3.micro
+  Regions:
+    eu-west-1:
+      Parameters:
+        InstanceType: t3.small
+      Tags:
+        DataResidency: eu
+
+A manifest can also set a naming convention for exported outputs.
+Outputs with an Export but no export Name are named according to the convention:
+
+  Exports:
+    NameTemplate: ${StackName}:${OutputName}
+
+Before deploying, rain checks that none of the template's export names
+are already exported by another stack in the region.
+
+Each stack in a manifest can also set how it is deployed. These settings are also used
+when a stack that is listed in a rain.yaml in the current directory is deployed on its own,
+so that the command line stays short. The --role-arn, --termination-protection,
+--stack-policy and --timeout flags take precedence.
+
+  Stacks:
+    - Name: app
+      Template: app.yaml
+      Capabilities:
+        - CAPABILITY_IAM
+      RoleArn: arn:aws:iam::123456789012:role/deploy
+      NotificationArns:
+        - arn:aws:sns:us-east-1:123456789012:deployments
+      TerminationProtection: true
+      StackPolicy: app-policy.json
+      TimeoutInMinutes: 30
+
+Change sets can't set a timeout, so rain enforces TimeoutInMinutes itself while it
+waits for the stack. If an update is still running when the time is up, rain cancels it
+and the stack rolls back. If a new stack is still being created, rain deletes it, unless
+rollback is disabled with --keep. The time left is shown while the stack deploys.
+Use --timeout to set the timeout on the command line. It is not enforced when rain detaches.
+
+While the stack deploys, rain warns about any resource that has been in progress for
+longer than --stall-warning minutes (30 by default), so that a stuck resource can be
+found before the whole deployment times out.
+
+To trace a deployed stack back to its source, use --git-tags to tag the stack with
+the commit, branch and remote URL of the git repository that the template is in,
+and whether it had uncommitted changes (rain:GitCommit, rain:GitBranch, rain:GitDirty
+and rain:GitRemote). Use --git-metadata to record the same details in the template's
+Metadata section, under Rain::Git. Credentials are removed from the remote URL.
+
+To enforce an organisation's tagging policy, pass a policy file with --tag-policy,
+set TagPolicy in the manifest, or set RAIN_TAG_POLICY. Deployment stops with a list
+of the problems if a required tag is missing or has a value the policy doesn't allow.
+The policy can also add standard tags that record the deployment, which CloudFormation
+propagates to the stack's resources:
+
+  Required:
+    - Key: CostCenter
+      Pattern: ^[0-9]{4}$
+    - Key: Environment
+      Values: [dev, staging, prod]
+  StandardTags:
+    GitCommit: rain:GitCommit
+    Deployer: rain:DeployedBy
+    Timestamp: rain:DeployedAt
+
+To stop many pipelines that deploy at once from being throttled, set --budget-table
+(or RAIN_BUDGET_TABLE) to a DynamoDB table with a string partition key named SlotId.
+Rain will then run at most --budget (or RAIN_BUDGET_LIMIT) stack operations at a time
+in the account, queueing until a slot is free.
+
+Pipelines can act on how the deployment went with rain's exit code. A failed
+or rolled back deployment exits with 3 and one that timed out with 5. A policy check
+that fails exits with 2. With --fail-on-empty-changeset, rain exits with 4 if there
+are no changes to deploy.
 `,
-	Args:                  cobra.RangeArgs(1, 3),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if manifestPath != "" || applyPath != "" {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.RangeArgs(1, 3)(cmd, args)
+	},
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
 
+		if err := checkPlanFlags(); err != nil {
+			panic(err)
+		}
+
+		if err := checkRegionsFlags(args); err != nil {
+			panic(err)
+		}
+
+		if manifestPath != "" {
+			deployManifest(manifestPath, cmd.Flags())
+			return
+		}
+
 		var stackName, changeSetName, fn string
 		var err error
 		var stack types.Stack
+		var settings manifest.Stack
+		var policy string
+		var applied *plan.Plan
+		var stackHooks hooks.Hooks
+		var notifications notify.Config
+		var bg *blueGreen
+
+		if applyPath != "" {
+
+			applied = readPlan(applyPath)
+			stackName = applied.StackName
+			stackHooks = mustLoadHooks(configFilePath)
+			notifications = mustLoadNotifications(configFilePath)
+			changeSetName = applied.ChangeSetId
+			settings = stackSettings(stackName, cmd.Flags())
+			policy = applied.StackPolicy
+
+			if applied.NoChanges {
+				console.Log(console.Green(fmt.Sprintf("The plan has no changes to make to stack '%s'.", stackName)))
+				if err := protect(stackName, settings, policy); err != nil {
+					panic(err)
+				}
+				exitIfEmpty()
+				return
+			}
+
+			if err := checkChangeSetPolicies(stackName, changeSetName); err != nil {
+				panic(exitcode.Wrap(exitcode.Invalid, ui.Errorf(err, "change set policy check failed")))
+			}
+
+			if err := checkUnlocked(stackName); err != nil {
+				panic(err)
+			}
 
-		if changeset {
+			if err := confirmStateful(stackName, changeSetName); err != nil {
+				panic(err)
+			}
+
+		} else if changeset {
 
 			if len(args) != 2 {
 				panic("expected 2 args: rain deploy --changeset <stackName> <changeSetName>")
@@ -93,10 +407,46 @@ To list and delete changesets, use the ls and rm commands.
 
 			stackName = args[0]
 			changeSetName = args[1]
+			settings = stackSettings(stackName, cmd.Flags())
+			stackHooks = mustLoadHooks(configFilePath)
+			notifications = mustLoadNotifications(configFilePath)
+
+			policy, err = stackPolicy(settings, "")
+			if err != nil {
+				panic(err)
+			}
+
+			if err := checkChangeSetPolicies(stackName, changeSetName); err != nil {
+				panic(exitcode.Wrap(exitcode.Invalid, ui.Errorf(err, "change set policy check failed")))
+			}
+
+			if err := checkUnlocked(stackName); err != nil {
+				panic(err)
+			}
+
+			if err := confirmStateful(stackName, changeSetName); err != nil {
+				panic(err)
+			}
 
 		} else {
 
 			fn = args[0]
+
+			// Templates can be deployed straight from a registry
+			if oci.IsReference(fn) {
+				dir, err := os.MkdirTemp("", "rain-oci-")
+				if err != nil {
+					panic(err)
+				}
+				defer os.RemoveAll(dir)
+
+				b := pullBundle(fn, dir)
+				fn = b.Template
+				if configFilePath == "" {
+					configFilePath = b.Config
+				}
+			}
+
 			base := filepath.Base(fn)
 
 			var suppliedStackName string
@@ -110,6 +460,28 @@ To list and delete changesets, use the ls and rm commands.
 				changeSetName = args[2]
 			}
 
+			stackName = dc.GetStackName(suppliedStackName, base)
+
+			if len(regions) > 0 {
+				deployRegions(fn, stackName, cmd.Flags())
+				return
+			}
+
+			settings = stackSettings(stackName, cmd.Flags())
+			stackHooks = mustLoadHooks(configFilePath)
+			notifications = mustLoadNotifications(configFilePath)
+
+			// With a blue-green strategy, the template is deployed as a new stack alongside the live one
+			bg = blueGreenStacks(configFilePath, stackName)
+			if bg != nil {
+				stackName = bg.next
+				settings.Name = stackName
+			}
+
+			if err := runHook(stackHooks, hooks.PrePackage, stackName, nil); err != nil {
+				panic(onFailure(stackHooks, stackName, err))
+			}
+
 			// Package template
 			if experimental {
 				cftpkg.Experimental = true
@@ -118,19 +490,58 @@ To list and delete changesets, use the ls and rm commands.
 			template := PackageTemplate(fn, yes)
 			spinner.Pop()
 
-			stackName = dc.GetStackName(suppliedStackName, base)
+			// Make sure we aren't going to clash with another stack's exports
+			spinner.Push("Checking exports")
+			err = checkExports(template, stackName, make(map[string]string))
+			spinner.Pop()
+			if err != nil {
+				panic(err)
+			}
+
+			if err := checkTemplatePolicies(template); err != nil {
+				panic(exitcode.Wrap(exitcode.Invalid, ui.Errorf(err, "template policy check failed")))
+			}
 
 			// Check current stack status
 			spinner.Push(fmt.Sprintf("Checking current status of stack '%s'", stackName))
 			stack, stackExists := CheckStack(stackName)
 			spinner.Pop()
 
+			if stackExists {
+				if err := cfn.CheckUnlocked(stack, unlock); err != nil {
+					panic(err)
+				}
+			}
+
 			dc, err := dc.GetDeployConfig(tags, params, configFilePath, base,
 				template, stack, stackExists, yes, ignoreUnknownParams)
 			if err != nil {
 				panic(err)
 			}
 
+			policy, err = stackPolicy(settings, dc.StackPolicy)
+			if err != nil {
+				panic(err)
+			}
+
+			dc.Tags, err = stampGit(fn, template, dc.Tags)
+			if err != nil {
+				panic(err)
+			}
+
+			dc.Tags, err = applyTagPolicy(loadTagPolicy(nil), stackName, fn, dc.Tags)
+			if err != nil {
+				panic(err)
+			}
+
+			// Deploying with --unlock keeps the lock, and a new blue-green stack takes over the live stack's lock
+			if (stackExists && cfn.IsLocked(stack)) || (bg != nil && bg.locked) {
+				if dc.Tags == nil {
+					dc.Tags = make(map[string]string)
+				}
+				dc.Tags[cfn.LockTag] = "true"
+			}
+
 			// Figure out how long we thing the stack will take to execute
 			//totalSeconds := forecast.PredictTotalEstimate(template, stackExists)
 			// TODO - Wait until the forecast command is GA and add this to output
@@ -138,26 +549,54 @@ To list and delete changesets, use the ls and rm commands.
 			// Create change set
 			spinner.Push("Creating change set")
 			var createErr error
-			changeSetName, createErr = cfn.CreateChangeSet(template, dc.Params, dc.Tags, stackName, changeSetName, roleArn)
+			changeSetName, createErr = cfn.CreateChangeSet(template, dc.Params, dc.Tags, stackName, changeSetName,
+				changeSetOptions(settings))
 			if createErr != nil {
-				if changeSetHasNoChanges(createErr.Error()) {
+				if cfn.ChangeSetHasNoChanges(createErr) {
 					spinner.Pop()
-					fmt.Println(console.Green("Change set was created, but there is no change. Deploy was skipped."))
+					if planOnly {
+						savePlan(stackName, "", template, policy)
+						exitIfEmpty()
+						return
+					}
+					console.Log(console.Green("Change set was created, but there is no change. Deploy was skipped."))
+					emit.Event("no_changes", map[string]string{"stackName": stackName})
+					if err := protect(stackName, settings, policy); err != nil {
+						panic(err)
+					}
+					exitIfEmpty()
 					return
 				} else {
-					panic(ui.Errorf(createErr, "error creating changeset"))
+					err := ui.Errorf(createErr, "error creating changeset")
+					panic(exitcode.Wrap(changeSetErrorCode(createErr), onFailure(stackHooks, stackName, err)))
 				}
 			}
 			spinner.Pop()
 
+			if err := checkChangeSetPolicies(stackName, changeSetName); err != nil {
+				cfn.DeleteChangeSet(stackName, changeSetName)
+				if !stackExists {
+					cfn.DeleteStack(stackName, "")
+				}
+				panic(exitcode.Wrap(exitcode.Invalid, ui.Errorf(err, "change set policy check failed")))
+			}
+
+			emitChangeSet(stackName, changeSetName)
+
+			// The change set is kept for rain deploy --apply to execute once it has been approved
+			if planOnly {
+				savePlan(stackName, changeSetName, template, policy)
+				return
+			}
+
 			// Confirm changes
 			if !yes {
 				spinner.Push("Formatting change set")
 				status := formatChangeSet(stackName, changeSetName)
 				spinner.Pop()
 
-				fmt.Println("CloudFormation will make the following changes:")
-				fmt.Println(status)
+				console.Log("CloudFormation will make the following changes:")
+				console.Log(status)
 
 				if !console.Confirm(true, "Do you wish to continue?") {
 					err := cfn.DeleteChangeSet(stackName, changeSetName)
@@ -177,89 +616,197 @@ To list and delete changesets, use the ls and rm commands.
 			}
 
 			if noexec {
-				fmt.Println("changeset created but not executed:", changeSetName)
+				console.Logf("changeset created but not executed: %s", changeSetName)
 				return
 			}
+
+			if err := confirmStateful(stackName, changeSetName); err != nil {
+				cfn.DeleteChangeSet(stackName, changeSetName)
+				panic(err)
+			}
+		}
+
+		if err := runHook(stackHooks, hooks.PreDeploy, stackName, nil); err != nil {
+			panic(onFailure(stackHooks, stackName, err))
+		}
+
+		// Start following the stack's events before anything happens
+		var events <-chan types.StackEvent
+		if !detach {
+			ctx, cancel := context.WithCancel(interrupt.Context())
+			defer cancel()
+
+			events, err = streamEvents(ctx, stackName)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to get events for stack '%s'", stackName))
+			}
+		}
+
+		// Wait our turn if the account has a budget for concurrent stack operations
+		spinner.Push("Reserving a stack operation slot")
+		lease, err := budget.Acquire(stackName, func(message string) {
+			spinner.Pop()
+			spinner.Push(message)
+		})
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to reserve a stack operation slot"))
 		}
+		defer releaseLease(lease)
 
 		// Deploy!
+		d := startDeployment(notifications, stackName, changeSetName)
 		err = cfn.ExecuteChangeSet(stackName, changeSetName, keep)
 		if err != nil {
-			panic(ui.Errorf(err, "error while executing changeset '%s'", changeSetName))
+			err = ui.Errorf(err, "error while executing changeset '%s'", changeSetName)
+			d.failed("", err)
+			panic(exitcode.Wrap(exitcode.DeployFailed, onFailure(stackHooks, stackName, err)))
 		}
 
 		if detach {
-			fmt.Printf("Detaching. You can check your stack's status with: rain watch %s\n", stackName)
+			if settings.TimeoutInMinutes > 0 {
+				console.Log(console.Yellow("The stack's timeout is not enforced when rain detaches"))
+			}
+			if stackHooks.Has(hooks.PostDeploy) {
+				console.Log(console.Yellow("The post_deploy hook is not run when rain detaches"))
+			}
+			console.Logf("Detaching. You can check your stack's status with: rain watch %s", stackName)
+			emit.Event("detached", map[string]string{"stackName": stackName, "changeSetName": changeSetName})
 		} else {
-			if changeset {
-				fmt.Printf("Executing changeset '%s' as stack '%s' in %s.\n",
+			if applied != nil {
+				console.Logf("Executing changeset '%s' from plan '%s' as stack '%s' in %s.",
+					applied.ChangeSetName, applyPath, stackName, aws.Config().Region)
+			} else if changeset {
+				console.Logf("Executing changeset '%s' as stack '%s' in %s.",
 					changeSetName, stackName, aws.Config().Region)
 			} else {
-				fmt.Printf("Deploying template '%s' as stack '%s' in %s.\n",
+				console.Logf("Deploying template '%s' as stack '%s' in %s.",
 					filepath.Base(fn), stackName, aws.Config().Region)
 			}
-			status, messages := cfn.WaitForStackToSettle(stackName)
+
+			// Show the time left before the deadline next to the elapsed time
+			deadline := startDeadline(settings)
+			spinner.SetDeadline(deadline.at)
+
+			status, messages := watchEvents(stackName, events)
+			failureCode := exitcode.DeployFailed
+			action := deadline.stop()
+			if interrupt.Interrupted() {
+				status = stopDeployment(stackName)
+			}
+			if action != "" {
+				console.Log(console.Red(fmt.Sprintf("Stack '%s' did not finish within %d minutes, so rain %s",
+					stackName, settings.TimeoutInMinutes, action)))
+				failureCode = exitcode.Timeout
+
+				if status == "DELETE_COMPLETE" {
+					err := fmt.Errorf("failed deploying stack '%s'", stackName)
+					d.failed(status, err)
+					panic(exitcode.Wrap(failureCode, onFailure(stackHooks, stackName, err)))
+				}
+			}
+			cfn.InvalidateStackOutputs(stackName)
 			stack, _ = cfn.GetStack(stackName)
+			if status == "" {
+				status = string(stack.StackStatus)
+			}
 			output := cfn.GetStackSummary(stack, false)
 
-			fmt.Println(output)
+			console.Log(output)
+
+			emit.Stack(stack, messages)
 
 			if len(messages) > 0 {
-				fmt.Println(console.Yellow("Messages:"))
+				console.Log(console.Yellow("Messages:"))
 				for _, message := range messages {
-					fmt.Printf("  - %s\n", message)
+					console.Logf("  - %s", message)
 				}
 			}
 
 			if status == "CREATE_COMPLETE" {
-				fmt.Println(console.Green("Successfully deployed " + stackName))
+				console.Log(console.Green("Successfully deployed " + stackName))
+				d.succeeded(status)
 			} else if status == "UPDATE_COMPLETE" {
-				fmt.Println(console.Green("Successfully updated " + stackName))
+				console.Log(console.Green("Successfully updated " + stackName))
+				d.succeeded(status)
 			} else {
-				panic(fmt.Errorf("failed deploying stack '%s'", stackName))
+				showRootCause(stackName)
+				err := fmt.Errorf("failed deploying stack '%s'", stackName)
+				d.failed(status, err)
+				panic(exitcode.Wrap(failureCode, onFailure(stackHooks, stackName, err)))
 			}
 		}
 
-		// Enable termination protection
-		if terminationProtection {
-			err = cfn.SetTerminationProtection(stackName, true)
-			if err != nil {
-				panic(ui.Errorf(err, "error while enabling termination protection on stack '%s'", stackName))
+		if err := protect(stackName, settings, policy); err != nil {
+			panic(err)
+		}
+
+		if !detach {
+			if err := runHook(stackHooks, hooks.PostDeploy, stackName, nil); err != nil {
+				panic(onFailure(stackHooks, stackName, err))
 			}
 		}
+
+		if bg != nil {
+			bg.switchOver(settings, stackHooks)
+		}
 	},
 	PostRun: func(cmd *cobra.Command, args []string) {
 		params = nil
 	},
 }
 
-func changeSetHasNoChanges(msg string) bool {
-	// mesages returned as error when the change set is empty
-	noChangeFoundMsg := []string{
-		"The submitted information didn't contain changes. Submit different information to create a change set.",
-		"No updates are to be performed.",
+// releaseLease releases the stack operation slot and warns if it was lost while the operation ran
+func releaseLease(lease *budget.Lease) {
+	if err := lease.Release(); err != nil {
+		console.Warnf("%s", err)
 	}
-	for _, m := range noChangeFoundMsg {
-		if m == msg {
-			return true
-		}
+}
+
+// exitIfEmpty stops rain with exitcode.NoChanges if --fail-on-empty-changeset is set
+func exitIfEmpty() {
+	if failOnEmpty {
+		exitcode.Exit(exitcode.NoChanges)
 	}
-	return false
 }
 
 func init() {
 
 	Cmd.Flags().BoolVarP(&detach, "detach", "d", false, "once deployment has started, don't wait around for it to finish")
 	Cmd.Flags().BoolVarP(&yes, "yes", "y", false, "don't ask questions; just deploy")
+	Cmd.Flags().BoolVar(&unlock, "unlock", false, "deploy to the stack even if it has been locked with rain protect; the lock is kept")
+	Cmd.Flags().BoolVar(&force, "force", false, "replace or delete resources that hold data, such as databases and buckets, without asking to confirm each one")
 	Cmd.Flags().StringSliceVar(&tags, "tags", []string{}, "add tags to the stack; use the format key1=value1,key2=value2")
 	Cmd.Flags().StringSliceVar(&params, "params", []string{}, "set parameter values; use the format key1=value1,key2=value2")
 	Cmd.Flags().StringVarP(&configFilePath, "config", "c", "", "YAML or JSON file to set tags and parameters")
 	Cmd.Flags().BoolVarP(&terminationProtection, "termination-protection", "t", false, "enable termination protection on the stack")
+	Cmd.Flags().StringVar(&stackPolicyPath, "stack-policy", "", "JSON stack policy document to set on the stack once it has been deployed")
 	Cmd.Flags().BoolVarP(&keep, "keep", "k", false, "keep deployed resources after a failure by disabling rollbacks")
+	Cmd.Flags().IntVar(&timeout, "timeout", 0, "stop the deployment if it takes longer than this many minutes")
+	Cmd.Flags().IntVar(&stallMinutes, "stall-warning", 30, "warn when a resource has been in progress for longer than this many minutes; 0 turns the warning off")
+	Cmd.Flags().BoolVar(&failOnEmpty, "fail-on-empty-changeset", false, "exit with code 4 if there are no changes to deploy")
 	Cmd.Flags().StringVarP(&roleArn, "role-arn", "", "", "ARN of an IAM role that CloudFormation should assume to deploy the stack")
 	Cmd.Flags().BoolVarP(&ignoreUnknownParams, "ignore-unknown-params", "", false, "Ignore unknown parameters")
+	Cmd.Flags().BoolVar(&dc.KeepParams, "keep-params", false, "keep the existing values of parameters that are not set with --params or --config")
+	Cmd.Flags().BoolVar(&dc.NoInput, "no-input", false, "fail instead of asking for parameter values that are not set, e.g. in CI")
 	Cmd.Flags().BoolVarP(&noexec, "no-exec", "x", false, "do not execute the changeset")
 	Cmd.Flags().BoolVar(&changeset, "changeset", false, "execute the changeset, rain deploy --changeset <stackName> <changeSetName>")
+	Cmd.Flags().BoolVar(&planOnly, "plan-only", false, "create the changeset and save it with the packaged template to --out, without executing it")
+	Cmd.Flags().StringVar(&planOut, "out", "", "plan file to write with --plan-only, e.g. plan.json")
+	Cmd.Flags().StringVar(&applyPath, "apply", "", "execute the changeset in a plan file that was written by --plan-only")
 	Cmd.Flags().StringVar(&format.NodeStyle, "node-style", "", format.NodeStyleDocs)
+	Cmd.Flags().StringToStringVar(&cftpkg.Constants, "constant", nil, "set the value of a Rain::Constants entry; use the format Name=value")
 	Cmd.Flags().BoolVar(&experimental, "experimental", false, "Acknowledge that you want to deploy with an experimental feature")
+	Cmd.Flags().StringVar(&budget.Table, "budget-table", budget.Table, "name or ARN of a DynamoDB table used to limit concurrent stack operations in the account")
+	Cmd.Flags().IntVar(&budget.Limit, "budget", budget.Limit, "maximum number of concurrent stack operations in the account when --budget-table is set")
+	Cmd.Flags().StringVar(&cosignKey, "cosign-key", "", "cosign public key that a template pulled from an OCI registry must be signed with")
+	Cmd.Flags().StringVar(&policyDir, "policy", "", "directory of cfn-guard or OPA policies that must allow the template and change set")
+	Cmd.Flags().BoolVar(&gitTags, "git-tags", false, "tag the stack with the commit, branch, dirty flag and remote of the template's git repository")
+	Cmd.Flags().BoolVar(&gitMetadata, "git-metadata", false, "record the template's git commit, branch, dirty flag and remote in its Metadata section")
+	Cmd.Flags().StringVar(&tagPolicyPath, "tag-policy", "", "tagging policy file that the stack's tags must meet; also adds the policy's standard tags")
+	Cmd.Flags().StringSliceVar(&notifySlack, "notify-slack", []string{}, "Slack incoming webhook URLs to send a summary to when the deployment starts, succeeds or fails")
+	Cmd.Flags().StringSliceVar(&notifySNS, "notify-sns", []string{}, "ARNs of SNS topics to send a summary to when the deployment starts, succeeds or fails")
+	Cmd.Flags().StringSliceVar(&notifyWebhooks, "notify-webhook", []string{}, "URLs to post a JSON summary to when the deployment starts, succeeds or fails")
+	Cmd.Flags().StringVar(&manifestPath, "manifest", "", "deploy all of the stacks listed in a manifest file, e.g. rain.yaml")
+	Cmd.Flags().StringSliceVar(&regions, "regions", []string{}, "deploy the stack to each of these regions at the same time, e.g. us-east-1,eu-west-1")
 }
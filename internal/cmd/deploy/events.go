@@ -0,0 +1,249 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	awsconsole "github.com/aws-cloudformation/rain/internal/aws/console"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// streamEvents returns a channel that receives the events of the named stack,
+// and of its nested stacks, from now until the stack settles.
+// Call it before executing a change set so that none of its events are missed.
+// The channel is closed when the stack settles or ctx is cancelled.
+func streamEvents(ctx context.Context, stackName string) (<-chan types.StackEvent, error) {
+	return cfn.StreamStackEvents(ctx, stackName)
+}
+
+// progress keeps track of the latest status of every resource in a deployment
+type progress struct {
+	statuses map[string]string
+}
+
+func newProgress() *progress {
+	return &progress{statuses: make(map[string]string)}
+}
+
+// isStackEvent returns true if the event is about a stack itself rather than one of its resources
+func isStackEvent(e types.StackEvent) bool {
+	return ptr.ToString(e.PhysicalResourceId) == ptr.ToString(e.StackId)
+}
+
+func (p *progress) add(e types.StackEvent) {
+	// Nested stacks are counted as resources of their parent
+	if isStackEvent(e) {
+		return
+	}
+
+	key := ptr.ToString(e.StackId) + "/" + ptr.ToString(e.LogicalResourceId)
+	p.statuses[key] = string(e.ResourceStatus)
+}
+
+func (p *progress) String() string {
+	counts := make(map[string]int)
+	for _, status := range p.statuses {
+		switch ui.MapStatus(status).Category {
+		case ui.Complete:
+			counts["complete"]++
+		case ui.Failed:
+			counts["failed"]++
+		case ui.InProgress:
+			counts["in progress"]++
+		}
+	}
+
+	parts := []string{console.Green(fmt.Sprintf("%d complete", counts["complete"]))}
+
+	if counts["in progress"] > 0 {
+		parts = append(parts, console.Blue(fmt.Sprintf("%d in progress", counts["in progress"])))
+	}
+
+	if counts["failed"] > 0 {
+		parts = append(parts, console.Red(fmt.Sprintf("%d failed", counts["failed"])))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// eventName returns the name of the resource the event is about,
+// prefixed with the nested stack it belongs to if it isn't in the root stack
+func eventName(e types.StackEvent, rootStackName string) string {
+	stackName := ptr.ToString(e.StackName)
+	logicalId := ptr.ToString(e.LogicalResourceId)
+
+	if stackName == rootStackName || isStackEvent(e) {
+		return logicalId
+	}
+
+	return stackName + "/" + logicalId
+}
+
+// formatEvent returns a line of the event log
+func formatEvent(e types.StackEvent, rootStackName string) string {
+	out := fmt.Sprintf("%s %s (%s) %s",
+		console.Grey(ptr.ToTime(e.Timestamp).Local().Format(time.TimeOnly)),
+		console.Yellow(eventName(e, rootStackName)),
+		ptr.ToString(e.ResourceType),
+		ui.ColouriseStatus(string(e.ResourceStatus)),
+	)
+
+	if e.ResourceStatusReason != nil && ui.MapStatus(string(e.ResourceStatus)).Category == ui.Failed {
+		out += " " + ui.Colourise(ptr.ToString(e.ResourceStatusReason), string(e.ResourceStatus))
+	}
+
+	return out
+}
+
+// watchEvents prints each event as it arrives, with a summary of the deployment's progress
+// below the log, and returns the final status of the stack and the reasons for any failures.
+// In a terminal, the arrow keys choose a resource whose details are shown below the summary.
+func watchEvents(stackName string, events <-chan types.StackEvent) (string, []string) {
+	p := newProgress()
+	in := newInspector(stackName)
+	stalls := newStallDetector(stackName, time.Duration(stallMinutes)*time.Minute)
+	messages := make([]string, 0)
+	seenMessages := make(map[string]bool)
+	status := ""
+
+	keys, stopKeys, err := console.ReadKeys()
+	if err == nil {
+		defer stopKeys()
+	} else {
+		config.Debugf("unable to read key presses: %s", err)
+		in = nil
+	}
+
+	summary := func() string {
+		if in == nil {
+			return p.String()
+		}
+		return p.String() + "\n" + in.String()
+	}
+
+	// Check for resources that are stuck while waiting for the next event
+	var ticks <-chan time.Time
+	if stalls != nil {
+		ticker := time.NewTicker(stallCheckInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	spinner.StartTimer(summary())
+
+	for {
+		var e types.StackEvent
+
+		select {
+		case now := <-ticks:
+			for _, s := range stalls.check(now) {
+				spinner.Pause()
+				console.Log(console.Yellow("Warning: " + s.String()))
+				spinner.Resume()
+
+				emit.Event("stall", map[string]interface{}{
+					"stackName":      stackName,
+					"resource":       s.name,
+					"resourceType":   s.resourceType,
+					"status":         s.status,
+					"elapsedSeconds": s.elapsed.Round(time.Second).Seconds(),
+				})
+			}
+			continue
+		case k, ok := <-keys:
+			if !ok {
+				// Stop waiting for keys if stdin can't be read any more
+				keys = nil
+			} else if in.handle(k) {
+				spinner.Pop()
+				spinner.Push(summary())
+			}
+			continue
+		case event, ok := <-events:
+			if !ok {
+				spinner.StopTimer()
+				return status, messages
+			}
+			e = event
+		}
+
+		p.add(e)
+		if in != nil {
+			in.add(e)
+		}
+		if stalls != nil {
+			stalls.add(e)
+		}
+
+		emit.StackEvent(e)
+
+		spinner.Pause()
+		console.Log(formatEvent(e, stackName))
+		spinner.Resume()
+
+		spinner.Pop()
+		spinner.Push(summary())
+
+		if isStackEvent(e) && ptr.ToString(e.StackName) == stackName {
+			status = string(e.ResourceStatus)
+		}
+
+		reason := ptr.ToString(e.ResourceStatusReason)
+		if reason != "" && reason != "Resource creation cancelled" &&
+			ui.MapStatus(string(e.ResourceStatus)).Category == ui.Failed {
+
+			message := fmt.Sprintf("%s %s", console.Yellow(eventName(e, stackName)+":"), console.Red(reason))
+			if !seenMessages[message] {
+				seenMessages[message] = true
+				messages = append(messages, message)
+			}
+		}
+	}
+}
+
+// formatRootCause describes the resource that caused a deployment to fail
+func formatRootCause(f *cfn.Failure) string {
+	name := strings.Join(append(f.Path, ptr.ToString(f.Event.LogicalResourceId)), "/")
+
+	out := fmt.Sprintf("%s (%s) %s\n",
+		console.Yellow(name),
+		ptr.ToString(f.Event.ResourceType),
+		ui.ColouriseStatus(string(f.Event.ResourceStatus)),
+	)
+
+	if f.Event.ResourceStatusReason != nil {
+		out += fmt.Sprintf("  %s\n", console.Red(ptr.ToString(f.Event.ResourceStatusReason)))
+	}
+
+	if uri := awsconsole.StackEventsURI(ptr.ToString(f.Event.StackId)); uri != "" {
+		out += fmt.Sprintf("  %s\n", console.Grey(uri))
+	}
+
+	return out
+}
+
+// showRootCause prints the first resource that failed in the stack's most recent operation,
+// so that users don't have to look for it among the rollback events
+func showRootCause(stackName string) {
+	f, err := cfn.RootCause(stackName)
+	if err != nil {
+		config.Debugf("unable to find the root cause of the failure of stack '%s': %s", stackName, err)
+		return
+	}
+
+	if f == nil {
+		return
+	}
+
+	console.Log(console.Yellow(fmt.Sprintf("Root cause of the failure of %s:", stackName)))
+	console.Log("  " + strings.TrimSuffix(config.Mask(formatRootCause(f)), "\n"))
+}
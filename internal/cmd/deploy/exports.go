@@ -0,0 +1,66 @@
+package deploy
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/exports"
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// existingExports caches the exports of each region, by name, so that
+// a manifest deployment only has to list them once per region
+var existingExports = make(map[string]map[string]string)
+
+// checkExports returns an error if any of the template's exports
+// would conflict with each other or with an export that
+// already belongs to a different stack in the region.
+// claimed holds export names already used by other stacks
+// in the same deployment, and is updated with the template's exports.
+func checkExports(template cft.Template, stackName string, claimed map[string]string) error {
+	err := exports.CheckUnique(template, stackName)
+	if err != nil {
+		return err
+	}
+
+	names := exports.Names(template, stackName)
+	if len(names) == 0 {
+		return nil
+	}
+
+	region := aws.Config().Region
+	existing, ok := existingExports[region]
+	if !ok {
+		list, err := cfn.ListExports()
+		if err != nil {
+			return ui.Errorf(err, "unable to list exports")
+		}
+
+		existing = make(map[string]string)
+		for _, e := range list {
+			existing[ptr.ToString(e.Name)] = cfn.StackNameFromId(ptr.ToString(e.ExportingStackId))
+		}
+		existingExports[region] = existing
+	}
+
+	for output, name := range names {
+		if owner, ok := existing[name]; ok && owner != stackName {
+			return fmt.Errorf("output '%s' would be exported as '%s', which is already exported by stack '%s'",
+				output, name, owner)
+		}
+
+		if owner, ok := claimed[name]; ok && owner != stackName {
+			return fmt.Errorf("output '%s' would be exported as '%s', which is also exported by stack '%s'",
+				output, name, owner)
+		}
+	}
+
+	for _, name := range names {
+		claimed[name] = stackName
+	}
+
+	return nil
+}
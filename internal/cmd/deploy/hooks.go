@@ -0,0 +1,66 @@
+package deploy
+
+import (
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/hooks"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// loadHooks reads the hooks in a deploy config file, if there is one
+func loadHooks(configPath string) (hooks.Hooks, error) {
+	if configPath == "" {
+		return nil, nil
+	}
+
+	return hooks.Load(configPath)
+}
+
+// runHook runs one of the stack's hooks, telling it about the stack as it is now.
+// failure is why the deployment failed, for on_failure hooks.
+func runHook(h hooks.Hooks, name, stackName string, failure error) error {
+	if !h.Has(name) {
+		return nil
+	}
+
+	env := hooks.Env{
+		StackName: stackName,
+		Region:    aws.Config().Region,
+	}
+
+	// The stack won't exist yet before it is first deployed
+	if stack, err := cfn.GetStack(stackName); err == nil {
+		env.Status = string(stack.StackStatus)
+		env.Outputs = make(map[string]string, len(stack.Outputs))
+		for _, output := range stack.Outputs {
+			env.Outputs[ptr.ToString(output.OutputKey)] = ptr.ToString(output.OutputValue)
+		}
+	}
+
+	if failure != nil {
+		env.Error = failure.Error()
+	}
+
+	return h.Run(name, env)
+}
+
+// onFailure runs the stack's on_failure hook and returns err.
+// If the hook fails too, its error is shown but err is still returned.
+func onFailure(h hooks.Hooks, stackName string, err error) error {
+	if hookErr := runHook(h, hooks.OnFailure, stackName, err); hookErr != nil {
+		console.Failf("%s", hookErr)
+	}
+
+	return err
+}
+
+// mustLoadHooks reads the hooks in the deploy config file, stopping rain if they are invalid
+func mustLoadHooks(configPath string) hooks.Hooks {
+	h, err := loadHooks(configPath)
+	if err != nil {
+		panic(err)
+	}
+
+	return h
+}
@@ -0,0 +1,214 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"gopkg.in/yaml.v3"
+)
+
+// The inspector shows only the end of long lists, so that it fits under the event log
+const maxPropertyLines = 15
+const maxInspectorEvents = 5
+
+// getStackTemplate returns the template of a stack; tests replace it
+var getStackTemplate = cfn.GetStackTemplate
+
+// resourceState is what the events of a deployment say about one of its resources
+type resourceState struct {
+	name         string
+	stackName    string
+	logicalId    string
+	resourceType string
+	physicalId   string
+	status       string
+	reason       string
+	events       []types.StackEvent
+}
+
+// inspector lets users choose a resource while a deployment is watched,
+// and shows its details under the event log without interrupting it
+type inspector struct {
+	rootStackName string
+	resources     []*resourceState
+	byName        map[string]*resourceState
+	selected      int
+	open          bool
+
+	// properties of each resource, by name, from the templates of the stacks
+	properties map[string]string
+}
+
+func newInspector(rootStackName string) *inspector {
+	return &inspector{
+		rootStackName: rootStackName,
+		resources:     make([]*resourceState, 0),
+		byName:        make(map[string]*resourceState),
+		selected:      -1,
+		properties:    make(map[string]string),
+	}
+}
+
+// add records an event
+func (in *inspector) add(e types.StackEvent) {
+	name := eventName(e, in.rootStackName)
+
+	r, ok := in.byName[name]
+	if !ok {
+		r = &resourceState{
+			name:      name,
+			stackName: ptr.ToString(e.StackName),
+			logicalId: ptr.ToString(e.LogicalResourceId),
+		}
+		in.byName[name] = r
+		in.resources = append(in.resources, r)
+	}
+
+	r.resourceType = ptr.ToString(e.ResourceType)
+	r.status = string(e.ResourceStatus)
+	r.events = append(r.events, e)
+
+	if id := ptr.ToString(e.PhysicalResourceId); id != "" {
+		r.physicalId = id
+	}
+
+	if reason := ptr.ToString(e.ResourceStatusReason); reason != "" {
+		r.reason = reason
+	}
+}
+
+// handle responds to a key press and returns true if the pane needs to be drawn again
+func (in *inspector) handle(k console.Key) bool {
+	if len(in.resources) == 0 {
+		return false
+	}
+
+	switch k {
+	case console.KeyUp, "k":
+		if in.selected < 0 {
+			in.selected = len(in.resources) - 1
+		} else if in.selected > 0 {
+			in.selected--
+		}
+	case console.KeyDown, "j":
+		if in.selected < 0 || in.selected == len(in.resources)-1 {
+			in.selected = len(in.resources) - 1
+		} else {
+			in.selected++
+		}
+	case console.KeyEnter, "i":
+		if in.selected < 0 {
+			in.selected = len(in.resources) - 1
+		}
+		in.open = !in.open
+	case console.KeyEscape, "q":
+		if in.open {
+			in.open = false
+		} else {
+			in.selected = -1
+		}
+	default:
+		return false
+	}
+
+	return true
+}
+
+// resourceProperties returns the properties of the resource as YAML,
+// reading them from the stack's template the first time they are needed
+func (in *inspector) resourceProperties(r *resourceState) string {
+	if p, ok := in.properties[r.name]; ok {
+		return p
+	}
+
+	p := ""
+
+	if source, err := getStackTemplate(r.stackName, false); err == nil {
+		if t, err := parse.String(source); err == nil {
+			if resource, err := t.GetResource(r.logicalId); err == nil {
+				if _, props, _ := s11n.GetMapValue(resource, "Properties"); props != nil {
+					var out strings.Builder
+					enc := yaml.NewEncoder(&out)
+					enc.SetIndent(2)
+					if enc.Encode(props) == nil {
+						p = strings.TrimSpace(out.String())
+					}
+				}
+			}
+		}
+	}
+
+	in.properties[r.name] = p
+
+	return p
+}
+
+// String returns the pane that is shown under the event log
+func (in *inspector) String() string {
+	if in.selected < 0 || in.selected >= len(in.resources) {
+		if len(in.resources) == 0 {
+			return ""
+		}
+		return console.Grey("Press ↑ or ↓ to choose a resource to inspect")
+	}
+
+	r := in.resources[in.selected]
+
+	header := fmt.Sprintf("› %s (%s) %s", console.Yellow(r.name), r.resourceType, ui.ColouriseStatus(r.status))
+
+	if !in.open {
+		return header + console.Grey("  enter to inspect, esc to stop choosing")
+	}
+
+	lines := []string{header + console.Grey("  esc to close")}
+
+	if r.physicalId != "" {
+		lines = append(lines, fmt.Sprintf("  Physical ID: %s", r.physicalId))
+	}
+
+	if r.reason != "" && ui.MapStatus(r.status).Category == ui.Failed {
+		lines = append(lines, fmt.Sprintf("  Reason: %s", console.Red(r.reason)))
+	}
+
+	if props := in.resourceProperties(r); props != "" {
+		lines = append(lines, "  Properties:")
+
+		propLines := strings.Split(props, "\n")
+		for i, line := range propLines {
+			if i == maxPropertyLines {
+				lines = append(lines, console.Grey(fmt.Sprintf("    ... %d more lines", len(propLines)-i)))
+				break
+			}
+			lines = append(lines, "    "+line)
+		}
+	}
+
+	lines = append(lines, "  Events:")
+
+	events := r.events
+	if len(events) > maxInspectorEvents {
+		events = events[len(events)-maxInspectorEvents:]
+	}
+
+	for _, e := range events {
+		line := fmt.Sprintf("    %s %s",
+			console.Grey(ptr.ToTime(e.Timestamp).Local().Format(time.TimeOnly)),
+			ui.ColouriseStatus(string(e.ResourceStatus)))
+
+		if reason := ptr.ToString(e.ResourceStatusReason); reason != "" {
+			line += " " + reason
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,62 @@
+package deploy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestInspector(t *testing.T) {
+	getStackTemplate = func(stackName string, processed bool) (string, error) {
+		if stackName != "app" {
+			return "", errors.New("unexpected stack")
+		}
+		return "Resources:\n  Bucket:\n    Type: AWS::S3::Bucket\n    Properties:\n      BucketName: logs\n", nil
+	}
+
+	in := newInspector("app")
+
+	if in.handle(console.KeyDown) {
+		t.Error("expected keys to be ignored before there are any resources")
+	}
+
+	event := func(status types.ResourceStatus, reason string) types.StackEvent {
+		return types.StackEvent{
+			StackId:              ptr.String("stack-id"),
+			StackName:            ptr.String("app"),
+			LogicalResourceId:    ptr.String("Bucket"),
+			PhysicalResourceId:   ptr.String("logs"),
+			ResourceType:         ptr.String("AWS::S3::Bucket"),
+			ResourceStatus:       status,
+			ResourceStatusReason: ptr.String(reason),
+		}
+	}
+
+	in.add(event(types.ResourceStatusCreateInProgress, ""))
+	in.add(event(types.ResourceStatusCreateFailed, "Bucket logs already exists"))
+
+	if !strings.Contains(in.String(), "choose a resource") {
+		t.Errorf("expected a hint before a resource is chosen: %s", in.String())
+	}
+
+	in.handle(console.KeyUp)
+	in.handle(console.KeyEnter)
+
+	pane := in.String()
+	for _, expected := range []string{"Physical ID: logs", "Bucket logs already exists", "BucketName: logs", "CREATE_IN_PROGRESS"} {
+		if !strings.Contains(pane, expected) {
+			t.Errorf("expected '%s' in the inspector: %s", expected, pane)
+		}
+	}
+
+	in.handle(console.KeyEscape)
+	in.handle(console.KeyEscape)
+
+	if in.open || in.selected != -1 {
+		t.Error("expected escape to close the inspector and then stop choosing")
+	}
+}
@@ -0,0 +1,48 @@
+package deploy
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// stopDeployment deals with rain being interrupted while it waits for a stack.
+// If the stack has settled anyway, its status is returned so that the deployment can finish as usual.
+// Otherwise, an update can be cancelled, which rolls the stack back, or left to carry on without rain,
+// and rain stops with exitcode.Interrupted.
+func stopDeployment(stackName string) string {
+	// The context has been cancelled, so get a new one to check on the stack with
+	interrupt.Reset()
+	spinner.Stop()
+
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		panic(exitcode.Wrap(exitcode.Interrupted, ui.Errorf(err, "unable to get the status of stack '%s'", stackName)))
+	}
+
+	if cfn.StackHasSettled(stack) {
+		return string(stack.StackStatus)
+	}
+
+	watch := fmt.Sprintf("You can check its status with: rain watch %s", stackName)
+
+	if stack.StackStatus == types.StackStatusUpdateInProgress && console.CanAsk() &&
+		console.Confirm(false, fmt.Sprintf("Cancel the update of stack '%s' and roll it back?", stackName)) {
+
+		if err := cfn.CancelUpdateStack(stackName); err != nil {
+			panic(exitcode.Wrap(exitcode.Interrupted, ui.Errorf(err, "unable to cancel the update of stack '%s'", stackName)))
+		}
+
+		console.Log(console.Yellow(fmt.Sprintf("Cancelled the update of stack '%s', which is rolling back. %s", stackName, watch)))
+	} else {
+		console.Log(console.Yellow(fmt.Sprintf("Stack '%s' is %s and carries on without rain. %s", stackName, stack.StackStatus, watch)))
+	}
+
+	panic(exitcode.Wrap(exitcode.Interrupted, fmt.Errorf("interrupted while deploying stack '%s'", stackName)))
+}
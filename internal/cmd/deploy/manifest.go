@@ -0,0 +1,448 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	cftpkg "github.com/aws-cloudformation/rain/cft/pkg"
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/budget"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/hooks"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+	"github.com/aws-cloudformation/rain/internal/notify"
+	"github.com/aws-cloudformation/rain/internal/tagpolicy"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/spf13/pflag"
+)
+
+// manifestPath is the path to a rain.yaml manifest (--manifest)
+var manifestPath string
+
+type stackResult struct {
+	name   string
+	status string
+	err    error
+}
+
+// prepared is a stack with a change set that is ready to execute
+type prepared struct {
+	name          string
+	changeSetName string
+	settings      manifest.Stack
+	policy        string
+	hooks         hooks.Hooks
+	notifications notify.Config
+	deployment    *deployment
+
+	// existed is false if creating the change set made a new, empty stack
+	existed bool
+}
+
+// discard deletes the change set of a stack that won't be deployed,
+// along with the empty stack that creating it made if the stack is new
+func (p *prepared) discard() {
+	cfn.DeleteChangeSet(p.name, p.changeSetName)
+
+	if !p.existed {
+		cfn.DeleteStack(p.name, "")
+	}
+}
+
+// prepareManifestStack packages the stack's template and creates a change set.
+// It returns nil if there are no changes to deploy.
+// stackParams are parameter values that take precedence over those in the stack's config file.
+// claimed holds the export names used by stacks that have already been prepared.
+// flags are the command line flags, which take precedence over the stack's settings.
+// tagPolicy is the tagging policy that the stack's tags must meet, if there is one.
+func prepareManifestStack(m *manifest.Manifest, s manifest.Stack, stackParams []string, claimed map[string]string, flags *pflag.FlagSet, tagPolicy *tagpolicy.Policy) (*prepared, error) {
+	s.StackPolicy = m.Path(s.StackPolicy)
+	s = withFlags(s, flags)
+	fn := m.Path(s.Template)
+	base := filepath.Base(fn)
+
+	h, err := loadHooks(m.Path(s.Config))
+	if err != nil {
+		return nil, err
+	}
+
+	notifications, err := loadNotifications(m.Path(s.Config))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runHook(h, hooks.PrePackage, s.Name, nil); err != nil {
+		return nil, onFailure(h, s.Name, err)
+	}
+
+	spinner.Push(fmt.Sprintf("Preparing template '%s'", base))
+	template := PackageTemplate(fn, yes)
+	spinner.Pop()
+
+	spinner.Push(fmt.Sprintf("Checking exports for stack '%s'", s.Name))
+	err = checkExports(template, s.Name, claimed)
+	spinner.Pop()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTemplatePolicies(template); err != nil {
+		return nil, exitcode.Wrap(exitcode.Invalid, ui.Errorf(err, "template policy check failed for stack '%s'", s.Name))
+	}
+
+	spinner.Push(fmt.Sprintf("Checking current status of stack '%s'", s.Name))
+	stack, stackExists := CheckStack(s.Name)
+	spinner.Pop()
+
+	if stackExists {
+		if err := cfn.CheckUnlocked(stack, unlock); err != nil {
+			return nil, err
+		}
+	}
+
+	config, err := dc.GetDeployConfig(tags, stackParams, m.Path(s.Config), base,
+		template, stack, stackExists, yes, ignoreUnknownParams)
+	if err != nil {
+		return nil, err
+	}
+
+	policy, err := stackPolicy(s, config.StackPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Tags, err = stampGit(fn, template, config.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	config.Tags, err = applyTagPolicy(tagPolicy, s.Name, fn, config.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	// Deploying with --unlock keeps the lock
+	if stackExists && cfn.IsLocked(stack) {
+		if config.Tags == nil {
+			config.Tags = make(map[string]string)
+		}
+		config.Tags[cfn.LockTag] = "true"
+	}
+
+	spinner.Push(fmt.Sprintf("Creating change set for stack '%s'", s.Name))
+	changeSetName, err := cfn.CreateChangeSet(template, config.Params, config.Tags, s.Name, "", changeSetOptions(s))
+	spinner.Pop()
+	if err != nil {
+		if cfn.ChangeSetHasNoChanges(err) {
+			if !stackExists {
+				return nil, fmt.Errorf("new stack '%s' has no resources to create", s.Name)
+			}
+			return nil, protect(s.Name, s, policy)
+		}
+		code := changeSetErrorCode(err)
+		err = ui.Errorf(err, "error creating changeset for stack '%s'", s.Name)
+		return nil, exitcode.Wrap(code, onFailure(h, s.Name, err))
+	}
+
+	if err := checkChangeSetPolicies(s.Name, changeSetName); err != nil {
+		cfn.DeleteChangeSet(s.Name, changeSetName)
+		if !stackExists {
+			cfn.DeleteStack(s.Name, "")
+		}
+		return nil, exitcode.Wrap(exitcode.Invalid, ui.Errorf(err, "change set policy check failed for stack '%s'", s.Name))
+	}
+
+	if !yes {
+		spinner.Push("Formatting change set")
+		status := formatChangeSet(s.Name, changeSetName)
+		spinner.Pop()
+
+		console.Log("CloudFormation will make the following changes:")
+		console.Log(status)
+
+		if !console.Confirm(true, "Do you wish to continue?") {
+			err := cfn.DeleteChangeSet(s.Name, changeSetName)
+			if err != nil {
+				return nil, ui.Errorf(err, "error while deleting changeset '%s'", changeSetName)
+			}
+
+			if !stackExists {
+				err = cfn.DeleteStack(s.Name, "")
+				if err != nil {
+					return nil, ui.Errorf(err, "error deleting empty stack '%s'", s.Name)
+				}
+			}
+
+			return nil, errors.New("user cancelled deployment")
+		}
+	}
+
+	if err := confirmStateful(s.Name, changeSetName); err != nil {
+		cfn.DeleteChangeSet(s.Name, changeSetName)
+		return nil, err
+	}
+
+	return &prepared{
+		name:          s.Name,
+		changeSetName: changeSetName,
+		settings:      s,
+		policy:        policy,
+		hooks:         h,
+		notifications: notifications,
+		existed:       stackExists,
+	}, nil
+}
+
+// waitQuietly polls the stack until it settles without drawing anything,
+// so that several stacks can be waited on at the same time
+func waitQuietly(stackName string) (string, error) {
+	return waitInRegion("", stackName, nil)
+}
+
+// waitInRegion is waitQuietly for a stack in another region.
+// progress, if it is not nil, is called with the stack's status each time it is polled.
+func waitInRegion(r cfn.Region, stackName string, progress func(status string)) (string, error) {
+	stack, err := cfn.WaitForStack(interrupt.Context(), r, stackName, func(stack types.Stack) {
+		if progress != nil {
+			progress(string(stack.StackStatus))
+		}
+	})
+
+	return string(stack.StackStatus), err
+}
+
+// executeWave executes the prepared change sets concurrently
+// and waits for all of them to finish
+func executeWave(stacks []*prepared) []stackResult {
+	results := make([]stackResult, len(stacks))
+
+	var wg sync.WaitGroup
+	for i, p := range stacks {
+		wg.Add(1)
+		go func(i int, p *prepared) {
+			defer wg.Done()
+
+			results[i].name = p.name
+
+			lease, err := budget.Acquire(p.name, nil)
+			if err != nil {
+				results[i].err = ui.Errorf(err, "unable to reserve a stack operation slot")
+				return
+			}
+			defer releaseLease(lease)
+
+			p.deployment = startDeployment(p.notifications, p.name, p.changeSetName)
+			err = cfn.ExecuteChangeSet(p.name, p.changeSetName, keep)
+			if err != nil {
+				results[i].err = exitcode.Wrap(exitcode.DeployFailed,
+					ui.Errorf(err, "error while executing changeset '%s'", p.changeSetName))
+				return
+			}
+
+			d := startDeadline(p.settings)
+			results[i].status, results[i].err = waitQuietly(p.name)
+			if action := d.stop(); action != "" && results[i].err == nil {
+				results[i].err = exitcode.Wrap(exitcode.Timeout, fmt.Errorf("did not finish within %d minutes, so rain %s",
+					p.settings.TimeoutInMinutes, action))
+			}
+
+			// Later waves may look up this stack's new outputs
+			cfn.InvalidateStackOutputs(p.name)
+
+			if results[i].err == nil && succeeded(results[i].status) {
+				results[i].err = protect(p.name, p.settings, p.policy)
+			}
+		}(i, p)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func succeeded(status string) bool {
+	return status == "CREATE_COMPLETE" || status == "UPDATE_COMPLETE"
+}
+
+// deployManifest deploys every stack in the manifest in dependency order.
+// Stacks that do not depend on each other are deployed in parallel.
+// Deployment stops after the first wave that contains a failure.
+func deployManifest(path string, flags *pflag.FlagSet) {
+	m, err := manifest.Load(path)
+	if err != nil {
+		panic(ui.Errorf(err, "unable to load manifest"))
+	}
+
+	waves, err := m.Waves()
+	if err != nil {
+		panic(err)
+	}
+
+	if err := manifest.UseProject(); err != nil {
+		panic(ui.Errorf(err, "unable to load %s", manifest.DefaultFileName))
+	}
+
+	// Exports without a Name are named as each template is packaged,
+	// following this manifest's convention over the current directory's
+	if m.Exports != nil {
+		cftpkg.Exports = m.Exports
+	}
+
+	tagPolicy := loadTagPolicy(m)
+
+	console.Logf("Deploying %d stacks from '%s' in %s.", len(m.Stacks), path, aws.Config().Region)
+
+	status := make(map[string]string)
+	claimed := make(map[string]string)
+	failed := false
+	failedStacks := make([]string, 0)
+	changed := false
+
+	// The exit code is set by the first stack that fails
+	code := exitcode.Success
+	fail := func(c int) {
+		if !failed {
+			code = c
+		}
+		failed = true
+	}
+
+	// cancel discards the prepared stacks, which won't be deployed now that a stack has failed
+	cancel := func(ready []*prepared) {
+		for _, p := range ready {
+			if _, ok := status[p.name]; !ok {
+				status[p.name] = console.Grey("cancelled because another stack failed")
+			}
+			p.discard()
+		}
+	}
+
+	for i, wave := range waves {
+		ready := make([]*prepared, 0)
+
+		for _, s := range wave {
+			p, err := prepareManifestStack(m, s, nil, claimed, flags, tagPolicy)
+			if err != nil {
+				status[s.Name] = console.Red(err.Error())
+				fail(exitcode.Of(err))
+				emit.Event(emit.StackType, emit.StackData{StackName: s.Name, Messages: []string{err.Error()}})
+				break
+			}
+
+			if p == nil {
+				status[s.Name] = console.Grey("no changes")
+				continue
+			}
+
+			ready = append(ready, p)
+		}
+
+		if failed {
+			cancel(ready)
+			break
+		}
+
+		if len(ready) == 0 {
+			continue
+		}
+
+		// Hooks are run one stack at a time so that their output isn't mixed up
+		for _, p := range ready {
+			if err := runHook(p.hooks, hooks.PreDeploy, p.name, nil); err != nil {
+				status[p.name] = console.Red(onFailure(p.hooks, p.name, err).Error())
+				fail(exitcode.Of(err))
+				break
+			}
+		}
+
+		if failed {
+			cancel(ready)
+			break
+		}
+
+		changed = true
+
+		names := make([]string, len(ready))
+		for j, p := range ready {
+			names[j] = p.name
+		}
+
+		spinner.StartTimer(fmt.Sprintf("Deploying wave %d of %d: %s", i+1, len(waves), strings.Join(names, ", ")))
+		results := executeWave(ready)
+		spinner.StopTimer()
+
+		for j, r := range results {
+			h := ready[j].hooks
+			d := ready[j].deployment
+
+			switch {
+			case r.err != nil:
+				if d != nil {
+					d.failed(r.status, r.err)
+				}
+				status[r.name] = console.Red(onFailure(h, r.name, r.err).Error())
+				fail(exitcode.Of(r.err))
+			case succeeded(r.status):
+				d.succeeded(r.status)
+				status[r.name] = ui.ColouriseStatus(r.status)
+				if err := runHook(h, hooks.PostDeploy, r.name, nil); err != nil {
+					status[r.name] = console.Red(onFailure(h, r.name, err).Error())
+					fail(exitcode.Of(err))
+				}
+			default:
+				status[r.name] = ui.ColouriseStatus(r.status)
+				failedStacks = append(failedStacks, r.name)
+				fail(exitcode.DeployFailed)
+				err := fmt.Errorf("failed deploying stack '%s'", r.name)
+				d.failed(r.status, err)
+				onFailure(h, r.name, err)
+			}
+		}
+
+		for _, r := range results {
+			data := emit.StackData{StackName: r.name, Status: r.status}
+			if r.err != nil {
+				data.Messages = []string{r.err.Error()}
+			}
+			emit.Event(emit.StackType, data)
+		}
+
+		if failed || interrupt.Interrupted() {
+			break
+		}
+	}
+
+	// Summary
+	console.Log(console.Yellow("Summary:"))
+	for _, s := range m.Stacks {
+		st, ok := status[s.Name]
+		if !ok {
+			st = console.Grey("not started")
+		}
+		console.Logf("  %s: %s", console.Yellow(s.Name), config.Mask(st))
+	}
+
+	for _, name := range failedStacks {
+		showRootCause(name)
+	}
+
+	if failed {
+		panic(exitcode.Wrap(code, errors.New("manifest deployment failed")))
+	}
+
+	if !changed {
+		exitIfEmpty()
+	}
+
+	console.Log(console.Green(fmt.Sprintf("Successfully deployed %d stacks", len(m.Stacks))))
+}
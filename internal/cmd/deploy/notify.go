@@ -0,0 +1,181 @@
+package deploy
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/aws/sns"
+	"github.com/aws-cloudformation/rain/internal/ci"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/notify"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/smithy-go/ptr"
+)
+
+var notifySlack []string
+var notifySNS []string
+var notifyWebhooks []string
+
+func init() {
+	notify.PublishSNS = sns.Publish
+}
+
+// loadNotifications combines the targets set with flags with those in the deploy config file
+func loadNotifications(configPath string) (notify.Config, error) {
+	c := notify.Config{
+		Slack:    notifySlack,
+		SNS:      notifySNS,
+		Webhooks: notifyWebhooks,
+	}
+
+	if configPath == "" {
+		return c, nil
+	}
+
+	fromFile, err := notify.Load(configPath)
+	if err != nil {
+		return c, err
+	}
+
+	return c.Merge(fromFile), nil
+}
+
+// mustLoadNotifications reads the notification targets for the deployment,
+// stopping rain if the deploy config file's are invalid
+func mustLoadNotifications(configPath string) notify.Config {
+	c, err := loadNotifications(configPath)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// deployment is a change set that is being executed, which notifications are sent about
+// and which is summarized for the CI system that rain is running in, if there is one
+type deployment struct {
+	notifications notify.Config
+	ciProvider    ci.Provider
+	stackName     string
+	changes       []notify.Change
+	started       time.Time
+}
+
+// startDeployment sends notifications that the change set is about to be executed
+func startDeployment(c notify.Config, stackName, changeSetName string) *deployment {
+	d := &deployment{
+		notifications: c,
+		ciProvider:    ci.Detect(),
+		stackName:     stackName,
+		started:       time.Now(),
+	}
+
+	if c.IsEmpty() && d.ciProvider == ci.None {
+		return d
+	}
+
+	if cs, err := cfn.GetChangeSet(stackName, changeSetName); err == nil {
+		d.changes = resourceChanges(cs)
+	} else {
+		config.Debugf("unable to describe change set '%s' for notifications: %s", changeSetName, err)
+	}
+
+	d.send(notify.Event{Kind: notify.Started})
+
+	return d
+}
+
+// succeeded sends notifications that the stack has been deployed
+func (d *deployment) succeeded(status string) {
+	e := notify.Event{Kind: notify.Succeeded, Status: status, Duration: time.Since(d.started)}
+	d.send(e)
+	d.summarize(e)
+}
+
+// failed sends notifications that the deployment failed,
+// with the resource that caused it to fail if there is one
+func (d *deployment) failed(status string, err error) {
+	if d.notifications.IsEmpty() && d.ciProvider == ci.None {
+		return
+	}
+
+	e := notify.Event{Kind: notify.Failed, Status: status, Duration: time.Since(d.started)}
+
+	if f, rcErr := cfn.RootCause(d.stackName); rcErr == nil && f != nil {
+		e.FailingResource = fmt.Sprintf("%s (%s)",
+			strings.Join(append(f.Path, ptr.ToString(f.Event.LogicalResourceId)), "/"),
+			ptr.ToString(f.Event.ResourceType))
+		e.Reason = ptr.ToString(f.Event.ResourceStatusReason)
+	} else if err != nil {
+		e.Reason = err.Error()
+	}
+
+	d.send(e)
+	d.summarize(e)
+}
+
+// send fills in the details of the deployment and sends the event.
+// A notification that can't be sent is reported but doesn't stop the deployment.
+func (d *deployment) send(e notify.Event) {
+	e.StackName = d.stackName
+	e.Region = aws.Config().Region
+	e.Changes = d.changes
+
+	if err := d.notifications.Send(e); err != nil {
+		console.Warnf("%s", err)
+	}
+}
+
+// summarize writes a summary of the finished deployment, with the stack's outputs,
+// for the CI system that rain is running in
+func (d *deployment) summarize(e notify.Event) {
+	if d.ciProvider == ci.None {
+		return
+	}
+
+	e.StackName = d.stackName
+	e.Region = aws.Config().Region
+	e.Changes = d.changes
+
+	summary := ci.Deployment{Event: e}
+
+	if stack, err := cfn.GetStack(d.stackName); err == nil {
+		summary.StackId = ptr.ToString(stack.StackId)
+		for _, o := range stack.Outputs {
+			summary.Outputs = append(summary.Outputs, ci.Output{
+				Key:         ptr.ToString(o.OutputKey),
+				Value:       ptr.ToString(o.OutputValue),
+				Description: ptr.ToString(o.Description),
+			})
+		}
+	} else {
+		config.Debugf("unable to describe stack '%s' for the deployment summary: %s", d.stackName, err)
+	}
+
+	if err := ci.WriteSummary(summary); err != nil {
+		console.Warnf("%s", err)
+	}
+}
+
+// resourceChanges returns what a change set does to the stack's resources
+func resourceChanges(cs *cloudformation.DescribeChangeSetOutput) []notify.Change {
+	changes := make([]notify.Change, 0, len(cs.Changes))
+
+	for _, change := range cs.Changes {
+		if change.ResourceChange == nil {
+			continue
+		}
+
+		changes = append(changes, notify.Change{
+			Action:       string(change.ResourceChange.Action),
+			LogicalId:    ptr.ToString(change.ResourceChange.LogicalResourceId),
+			ResourceType: ptr.ToString(change.ResourceChange.ResourceType),
+		})
+	}
+
+	return changes
+}
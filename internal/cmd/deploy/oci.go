@@ -0,0 +1,34 @@
+package deploy
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/oci"
+	"github.com/aws-cloudformation/rain/internal/ui"
+)
+
+var cosignKey string
+
+// pullBundle downloads a template bundle from an OCI registry into dir,
+// verifying its signature if --cosign-key is set
+func pullBundle(uri string, dir string) oci.Bundle {
+	var key []byte
+	if cosignKey != "" {
+		var err error
+		key, err = os.ReadFile(cosignKey)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read key '%s'", cosignKey))
+		}
+	}
+
+	spinner.Push(fmt.Sprintf("Pulling %s", uri))
+	b, err := oci.Download(uri, dir, key)
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "unable to pull %s", uri))
+	}
+
+	return b
+}
@@ -0,0 +1,116 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/plan"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+var planOnly bool
+var planOut string
+var applyPath string
+
+// checkPlanFlags returns an error if --plan-only or --apply are combined with flags they can't be used with
+func checkPlanFlags() error {
+	switch {
+	case planOnly && applyPath != "":
+		return errors.New("--plan-only and --apply can't be used together")
+	case planOnly && planOut == "":
+		return errors.New("--plan-only needs --out to set the plan file to write")
+	case !planOnly && planOut != "":
+		return errors.New("--out can only be used with --plan-only")
+	case (planOnly || applyPath != "") && manifestPath != "":
+		return errors.New("--plan-only and --apply can't be used with --manifest")
+	case (planOnly || applyPath != "") && (changeset || noexec):
+		return errors.New("--plan-only and --apply can't be used with --changeset or --no-exec")
+	}
+
+	return nil
+}
+
+// savePlan writes the change set and the template it was created from to --out.
+// changeSetName is empty if there are no changes to make.
+func savePlan(stackName, changeSetName string, template cft.Template, policy string) {
+	var changeSet *cloudformation.DescribeChangeSetOutput
+
+	if changeSetName != "" {
+		spinner.Push("Describing change set")
+		var err error
+		changeSet, err = cfn.GetFullChangeSet(stackName, changeSetName)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "error getting changeset '%s' for stack '%s'", changeSetName, stackName))
+		}
+
+		spinner.Push("Formatting change set")
+		status := formatChangeSet(stackName, changeSetName)
+		spinner.Pop()
+
+		console.Log("CloudFormation will make the following changes:")
+		console.Log(status)
+	}
+
+	p := plan.New(aws.Config().Region, stackName, format.String(template, format.Options{}),
+		policy, changeSet, time.Now())
+
+	if err := p.Write(planOut); err != nil {
+		panic(ui.Errorf(err, "unable to write plan '%s'", planOut))
+	}
+
+	emit.Event("plan", map[string]interface{}{
+		"path":          planOut,
+		"stackName":     stackName,
+		"changeSetName": p.ChangeSetName,
+		"changeSetId":   p.ChangeSetId,
+		"noChanges":     p.NoChanges,
+	})
+
+	if p.NoChanges {
+		console.Log(console.Green(fmt.Sprintf("There are no changes to make to stack '%s'. Plan saved to %s", stackName, planOut)))
+		return
+	}
+
+	console.Logf("Plan saved to %s. To execute change set '%s', run: rain deploy --apply %s",
+		planOut, changeSetName, planOut)
+}
+
+// readPlan loads the plan to apply and checks that its change set can still be executed
+func readPlan(path string) *plan.Plan {
+	p, err := plan.Read(path)
+	if err != nil {
+		panic(err)
+	}
+
+	if region := aws.Config().Region; region != p.Region {
+		panic(fmt.Errorf("the plan was made in %s but the current region is %s; use --region %s",
+			p.Region, region, p.Region))
+	}
+
+	if p.NoChanges {
+		return p
+	}
+
+	spinner.Push(fmt.Sprintf("Checking change set '%s'", p.ChangeSetName))
+	current, err := cfn.GetChangeSet(p.StackName, p.ChangeSetId)
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "unable to find change set '%s' of stack '%s'", p.ChangeSetName, p.StackName))
+	}
+
+	if err := p.Check(current); err != nil {
+		panic(err)
+	}
+
+	return p
+}
@@ -0,0 +1,94 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/policy"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+)
+
+// policyDir is the directory of policies to evaluate before deploying (--policy)
+var policyDir string
+
+// policyError is returned when a policy denies a deployment
+type policyError struct {
+	subject string
+	results []policy.Result
+}
+
+func (e policyError) Error() string {
+	return fmt.Sprintf("the %s was denied by %d policy rules", e.subject, len(e.results))
+}
+
+func showDenials(results []policy.Result) {
+	console.Log(console.Red("Denied by policy:"))
+	for _, r := range results {
+		console.Logf("  - %s", r)
+	}
+}
+
+// checkTemplatePolicies evaluates the template against the policies in policyDir
+func checkTemplatePolicies(template cft.Template) error {
+	if policyDir == "" {
+		return nil
+	}
+
+	spinner.Push("Evaluating template policies")
+	results, err := policy.Template(policyDir, template.Map())
+	spinner.Pop()
+	if err != nil {
+		return err
+	}
+
+	if len(results) > 0 {
+		showDenials(results)
+		return policyError{"template", results}
+	}
+
+	return nil
+}
+
+// checkChangeSetPolicies evaluates the change set against the change set policies in policyDir
+func checkChangeSetPolicies(stackName, changeSetName string) error {
+	if policyDir == "" {
+		return nil
+	}
+
+	spinner.Push("Evaluating change set policies")
+	defer spinner.Pop()
+
+	res, err := cfn.GetChangeSet(stackName, changeSetName)
+	if err != nil {
+		return err
+	}
+
+	// Round trip through JSON so that policies see plain data, not SDK types
+	raw, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+
+	var changeSet map[string]any
+	if err := json.Unmarshal(raw, &changeSet); err != nil {
+		return err
+	}
+	delete(changeSet, "ResultMetadata")
+
+	results, err := policy.ChangeSet(policyDir, changeSet)
+	if err != nil {
+		return err
+	}
+
+	if len(results) > 0 {
+		spinner.Pause()
+		showDenials(results)
+		spinner.Resume()
+		return policyError{"change set", results}
+	}
+
+	return nil
+}
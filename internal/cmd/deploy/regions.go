@@ -0,0 +1,408 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/budget"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/hooks"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+	"github.com/aws-cloudformation/rain/internal/strategy"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/pflag"
+)
+
+// regions are the regions to deploy the stack to at the same time (--regions)
+var regions []string
+
+// regionalStack is the stack in one of the regions that it is deployed to
+type regionalStack struct {
+	region string
+
+	// prepared is the stack's change set in the region, which is nil if there are no changes to deploy
+	prepared  *prepared
+	noChanges bool
+
+	// executed is true once the change set has been executed
+	executed bool
+
+	// lease is the region's stack operation slot, which is released once the stack has settled
+	lease *budget.Lease
+
+	status string
+	err    error
+}
+
+// checkRegionsFlags returns an error if --regions is used in a way that it can't be
+func checkRegionsFlags(args []string) error {
+	if len(regions) == 0 {
+		return nil
+	}
+
+	switch {
+	case manifestPath != "":
+		return errors.New("--regions can't be used with --manifest")
+	case planOnly || applyPath != "":
+		return errors.New("--regions can't be used with --plan-only or --apply")
+	case changeset || noexec:
+		return errors.New("--regions can't be used with --changeset or --no-exec")
+	case detach:
+		return errors.New("--regions can't be used with --detach")
+	case len(args) == 3:
+		return errors.New("--regions can't be used with a change set name")
+	}
+
+	seen := make(map[string]bool)
+	for _, region := range regions {
+		if seen[region] {
+			return fmt.Errorf("region '%s' is listed more than once in --regions", region)
+		}
+		seen[region] = true
+	}
+
+	return nil
+}
+
+// useRegion makes region the one that rain calls AWS in from now on
+func useRegion(region string) {
+	// The config must be loaded before its region can be changed
+	aws.Config()
+
+	config.Region = region
+	aws.SetRegion(region)
+}
+
+// deployRegions deploys the template as the same stack in each of the regions in --regions.
+// Change sets are created in one region after another, as the template is packaged in each region
+// and the changes may need to be confirmed. They are then executed together,
+// and rain waits for the stack in every region at the same time.
+// Nothing is executed if the change set can't be created in any of the regions.
+func deployRegions(fn, stackName string, flags *pflag.FlagSet) {
+	if configFilePath != "" {
+		if bg, _ := strategy.Load(configFilePath); bg != nil {
+			panic(errors.New("a blue-green Strategy can't be used with --regions"))
+		}
+	}
+
+	home := aws.Config().Region
+	defer useRegion(home)
+
+	// Paths are relative to the current directory, so the manifest doesn't need a Dir
+	m := &manifest.Manifest{}
+
+	s := stackSettings(stackName, flags)
+	s.Template = fn
+	s.Config = configFilePath
+
+	tagPolicy := loadTagPolicy(nil)
+
+	console.Logf("Deploying template '%s' as stack '%s' in %s.",
+		filepath.Base(fn), stackName, strings.Join(regions, ", "))
+
+	stacks := make([]*regionalStack, len(regions))
+	for i, region := range regions {
+		stacks[i] = &regionalStack{region: region}
+	}
+
+	// The exit code is set by the first region that fails
+	code := exitcode.Success
+	fail := func(c int) {
+		if code == exitcode.Success {
+			code = c
+		}
+	}
+
+	for _, r := range stacks {
+		useRegion(r.region)
+
+		if !yes {
+			console.Log(console.Yellow(r.region + ":"))
+		}
+
+		p, err := prepareManifestStack(m, s, params, make(map[string]string), flags, tagPolicy)
+		if err != nil {
+			r.err = err
+			fail(exitcode.Of(err))
+			break
+		}
+
+		r.prepared = p
+		r.noChanges = p == nil
+	}
+
+	// Hooks are run one region at a time so that their output isn't mixed up
+	if code == exitcode.Success {
+		for _, r := range stacks {
+			if r.prepared == nil {
+				continue
+			}
+
+			useRegion(r.region)
+			if err := runHook(r.prepared.hooks, hooks.PreDeploy, stackName, nil); err != nil {
+				r.err = onFailure(r.prepared.hooks, stackName, err)
+				fail(exitcode.Of(err))
+				break
+			}
+		}
+	}
+
+	if code != exitcode.Success {
+		discardRegions(stacks)
+		showRegions(stacks)
+		panic(exitcode.Wrap(code, fmt.Errorf("stack '%s' was not deployed to any region", stackName)))
+	}
+
+	// Wait our turn if the account has a budget for concurrent stack operations.
+	// The slots for every region are taken together, from the table in this region if it is given by name.
+	deploying := 0
+	for _, r := range stacks {
+		if r.prepared != nil {
+			deploying++
+		}
+	}
+
+	useRegion(home)
+	spinner.Push("Reserving stack operation slots")
+	leases, err := budget.AcquireN(stackName, deploying, func(message string) {
+		spinner.Pop()
+		spinner.Push(message)
+	})
+	spinner.Pop()
+	if err != nil {
+		discardRegions(stacks)
+		showRegions(stacks)
+		panic(ui.Errorf(err, "unable to reserve stack operation slots"))
+	}
+
+	for _, r := range stacks {
+		if r.prepared == nil {
+			continue
+		}
+
+		useRegion(r.region)
+
+		if len(leases) > 0 {
+			r.lease, leases = leases[0], leases[1:]
+		}
+
+		r.prepared.deployment = startDeployment(r.prepared.notifications, stackName, r.prepared.changeSetName)
+		if err := cfn.ExecuteChangeSet(stackName, r.prepared.changeSetName, keep); err != nil {
+			r.err = exitcode.Wrap(exitcode.DeployFailed,
+				ui.Errorf(err, "error while executing changeset '%s'", r.prepared.changeSetName))
+			releaseLease(r.lease)
+			continue
+		}
+
+		r.executed = true
+	}
+
+	waitForRegions(stackName, stacks)
+
+	if interrupt.Interrupted() {
+		interrupt.Reset()
+		spinner.Stop()
+
+		for _, r := range stacks {
+			if r.executed {
+				console.Log(console.Yellow(fmt.Sprintf("Stack '%s' in %s carries on without rain. "+
+					"You can check its status with: rain watch %s --region %s", stackName, r.region, stackName, r.region)))
+			}
+		}
+
+		panic(exitcode.Wrap(exitcode.Interrupted, fmt.Errorf("interrupted while deploying stack '%s'", stackName)))
+	}
+
+	changed := false
+	failed := 0
+	failedStacks := make([]*regionalStack, 0)
+
+	for _, r := range stacks {
+		if r.prepared == nil {
+			continue
+		}
+
+		changed = true
+		useRegion(r.region)
+		cfn.InvalidateStackOutputs(stackName)
+
+		p := r.prepared
+
+		switch {
+		case r.err != nil:
+			failed++
+			fail(exitcode.Of(r.err))
+			if p.deployment != nil {
+				p.deployment.failed(r.status, r.err)
+			}
+			onFailure(p.hooks, stackName, r.err)
+		case succeeded(r.status):
+			p.deployment.succeeded(r.status)
+			err := protect(stackName, p.settings, p.policy)
+			if err == nil {
+				err = runHook(p.hooks, hooks.PostDeploy, stackName, nil)
+				if err != nil {
+					err = onFailure(p.hooks, stackName, err)
+				}
+			}
+			if err != nil {
+				r.err = err
+				failed++
+				fail(exitcode.Of(err))
+			}
+		default:
+			failed++
+			failedStacks = append(failedStacks, r)
+			fail(exitcode.DeployFailed)
+			err := fmt.Errorf("failed deploying stack '%s' in %s", stackName, r.region)
+			p.deployment.failed(r.status, err)
+			onFailure(p.hooks, stackName, err)
+		}
+
+		data := emit.StackData{StackName: stackName, Region: r.region, Status: r.status}
+		if r.err != nil {
+			data.Messages = []string{r.err.Error()}
+		}
+		emit.Event(emit.StackType, data)
+	}
+
+	showRegions(stacks)
+
+	for _, r := range failedStacks {
+		useRegion(r.region)
+		showRootCause(stackName)
+	}
+
+	if code != exitcode.Success {
+		panic(exitcode.Wrap(code, fmt.Errorf("failed deploying stack '%s' to %d of %d regions", stackName, failed, len(stacks))))
+	}
+
+	if !changed {
+		exitIfEmpty()
+	}
+
+	console.Log(console.Green(fmt.Sprintf("Successfully deployed %s to %d regions", stackName, len(stacks))))
+}
+
+// discardRegions deletes the change sets in the regions that the stack won't be deployed to,
+// and the empty stacks that creating them made where the stack is new
+func discardRegions(stacks []*regionalStack) {
+	for _, r := range stacks {
+		if r.prepared != nil {
+			useRegion(r.region)
+			r.prepared.discard()
+		}
+	}
+}
+
+// waitForRegions waits for the stack to settle in every region that its change set was executed in,
+// showing its status in each region as it changes
+func waitForRegions(stackName string, stacks []*regionalStack) {
+	type update struct {
+		i      int
+		status string
+	}
+
+	updates := make(chan update)
+	statuses := make([]string, len(stacks))
+
+	var wg sync.WaitGroup
+	for i, r := range stacks {
+		switch {
+		case r.executed:
+			statuses[i] = "waiting"
+		case r.err != nil:
+			statuses[i] = "failed"
+			continue
+		default:
+			statuses[i] = "no changes"
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, r *regionalStack) {
+			defer wg.Done()
+
+			region := cfn.Region(r.region)
+
+			d := startRegionDeadline(region, r.prepared.settings)
+			r.status, r.err = waitInRegion(region, stackName, func(status string) {
+				updates <- update{i, status}
+			})
+			if action := d.stop(); action != "" && r.err == nil {
+				r.err = exitcode.Wrap(exitcode.Timeout, fmt.Errorf("did not finish within %d minutes, so rain %s",
+					r.prepared.settings.TimeoutInMinutes, action))
+			}
+
+			// Free the region's slot for other operations as soon as its stack has settled
+			releaseLease(r.lease)
+		}(i, r)
+	}
+
+	go func() {
+		wg.Wait()
+		close(updates)
+	}()
+
+	spinner.StartTimer(regionsProgress(stackName, stacks, statuses))
+	for u := range updates {
+		if statuses[u.i] == u.status {
+			continue
+		}
+		statuses[u.i] = u.status
+
+		// Plain output gets a line for each change instead of the whole view
+		if console.PlainOutput {
+			console.Status(fmt.Sprintf("%s: %s", stacks[u.i].region, u.status))
+			continue
+		}
+
+		spinner.Pop()
+		spinner.Push(regionsProgress(stackName, stacks, statuses))
+	}
+	spinner.StopTimer()
+}
+
+// regionsProgress shows the status of the stack in each region
+func regionsProgress(stackName string, stacks []*regionalStack, statuses []string) string {
+	lines := []string{fmt.Sprintf("Deploying stack '%s' to %d regions", stackName, len(stacks))}
+
+	for i, r := range stacks {
+		lines = append(lines, fmt.Sprintf("  %s: %s", console.Yellow(r.region), ui.ColouriseStatus(statuses[i])))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// showRegions prints how the deployment went in each region
+func showRegions(stacks []*regionalStack) {
+	console.Log(console.Yellow("Summary:"))
+
+	for _, r := range stacks {
+		var status string
+		switch {
+		case r.err != nil:
+			status = console.Red(config.Mask(r.err.Error()))
+		case r.status != "":
+			status = ui.ColouriseStatus(r.status)
+		case r.noChanges:
+			status = console.Grey("no changes")
+		case r.prepared != nil:
+			status = console.Grey("change set deleted")
+		default:
+			status = console.Grey("not started")
+		}
+
+		console.Logf("  %s: %s", console.Yellow(r.region), status)
+	}
+}
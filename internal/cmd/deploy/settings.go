@@ -0,0 +1,102 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/pflag"
+)
+
+// withFlags overrides the stack's settings with the flags that were set on the command line
+func withFlags(s manifest.Stack, flags *pflag.FlagSet) manifest.Stack {
+	if flags.Changed("role-arn") {
+		s.RoleArn = roleArn
+	}
+
+	if flags.Changed("termination-protection") {
+		s.TerminationProtection = terminationProtection
+	}
+
+	if flags.Changed("stack-policy") {
+		s.StackPolicy = stackPolicyPath
+	}
+
+	if flags.Changed("timeout") {
+		s.TimeoutInMinutes = timeout
+	}
+
+	return s
+}
+
+// stackSettings returns the settings for the stack from the rain.yaml
+// in the current directory, if the stack is listed there,
+// with the flags that were set on the command line taking precedence
+func stackSettings(stackName string, flags *pflag.FlagSet) manifest.Stack {
+	s := manifest.Stack{Name: stackName}
+
+	if _, err := os.Stat(manifest.DefaultFileName); err == nil {
+		found, err := manifest.LoadStack(manifest.DefaultFileName, stackName)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read the settings for stack '%s' from %s",
+				stackName, manifest.DefaultFileName))
+		}
+
+		if found != nil {
+			config.Debugf("Using the settings for stack '%s' from %s", stackName, manifest.DefaultFileName)
+			s = *found
+		}
+	}
+
+	return withFlags(s, flags)
+}
+
+// changeSetOptions returns the change set options for the stack's settings
+func changeSetOptions(s manifest.Stack) cfn.ChangeSetOptions {
+	return cfn.ChangeSetOptions{
+		RoleArn:          s.RoleArn,
+		Capabilities:     s.Capabilities,
+		NotificationArns: s.NotificationArns,
+	}
+}
+
+// stackPolicy returns the stack policy document for the stack: the file in its settings,
+// which --stack-policy sets, or else the StackPolicy from its config file
+func stackPolicy(s manifest.Stack, configured string) (string, error) {
+	if s.StackPolicy == "" {
+		return configured, nil
+	}
+
+	content, err := os.ReadFile(s.StackPolicy)
+	if err != nil {
+		return "", fmt.Errorf("unable to read stack policy '%s': %w", s.StackPolicy, err)
+	}
+
+	if !json.Valid(content) {
+		return "", fmt.Errorf("stack policy '%s' is not a valid JSON document", s.StackPolicy)
+	}
+
+	return string(content), nil
+}
+
+// protect enables termination protection and sets the stack policy
+// on a stack that has been deployed, if its settings ask for them
+func protect(stackName string, s manifest.Stack, policy string) error {
+	if s.TerminationProtection {
+		if err := cfn.SetTerminationProtection(stackName, true); err != nil {
+			return ui.Errorf(err, "error while enabling termination protection on stack '%s'", stackName)
+		}
+	}
+
+	if policy != "" {
+		if err := cfn.SetStackPolicy(stackName, policy); err != nil {
+			return ui.Errorf(err, "error while setting the stack policy on stack '%s'", stackName)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package deploy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// stallMinutes is how long a resource can be in progress before rain warns that it may be stuck
+var stallMinutes int
+
+// stallCheckInterval is how often in-progress resources are checked
+const stallCheckInterval = 30 * time.Second
+
+// stall is a resource that has been in progress for longer than the threshold
+type stall struct {
+	name         string
+	resourceType string
+	status       string
+	elapsed      time.Duration
+}
+
+func (s stall) String() string {
+	return fmt.Sprintf("%s (%s) has been %s for %s and may be stuck",
+		s.name, s.resourceType, s.status, s.elapsed.Round(time.Minute))
+}
+
+// stallDetector keeps track of how long each resource has been in progress,
+// so that a resource that is stuck can be pointed out while the rest of the stack deploys
+type stallDetector struct {
+	threshold     time.Duration
+	rootStackName string
+
+	// inProgress is the event that started each resource's current operation
+	inProgress map[string]types.StackEvent
+
+	// warned is the resources that have been reported for their current operation
+	warned map[string]bool
+}
+
+// newStallDetector returns a stallDetector that reports resources that have been
+// in progress for longer than threshold, or nil if threshold is zero
+func newStallDetector(rootStackName string, threshold time.Duration) *stallDetector {
+	if threshold <= 0 {
+		return nil
+	}
+
+	return &stallDetector{
+		threshold:     threshold,
+		rootStackName: rootStackName,
+		inProgress:    make(map[string]types.StackEvent),
+		warned:        make(map[string]bool),
+	}
+}
+
+func (s *stallDetector) add(e types.StackEvent) {
+	if isStackEvent(e) {
+		return
+	}
+
+	key := ptr.ToString(e.StackId) + "/" + ptr.ToString(e.LogicalResourceId)
+
+	// ui.MapStatus counts deletes as failures, but a stuck delete is worth pointing out too
+	if !strings.HasSuffix(string(e.ResourceStatus), "_IN_PROGRESS") {
+		delete(s.inProgress, key)
+		delete(s.warned, key)
+		return
+	}
+
+	// A resource can report that it is still in progress more than once
+	if current, ok := s.inProgress[key]; ok && current.ResourceStatus == e.ResourceStatus {
+		return
+	}
+
+	s.inProgress[key] = e
+	delete(s.warned, key)
+}
+
+// check returns the resources that have been in progress for longer than the threshold
+// at now and haven't been reported yet, ordered by how long they have been in progress
+func (s *stallDetector) check(now time.Time) []stall {
+	stalls := make([]stall, 0)
+
+	for key, e := range s.inProgress {
+		elapsed := now.Sub(ptr.ToTime(e.Timestamp))
+		if s.warned[key] || elapsed < s.threshold {
+			continue
+		}
+
+		s.warned[key] = true
+		stalls = append(stalls, stall{
+			name:         eventName(e, s.rootStackName),
+			resourceType: ptr.ToString(e.ResourceType),
+			status:       string(e.ResourceStatus),
+			elapsed:      elapsed,
+		})
+	}
+
+	sort.Slice(stalls, func(i, j int) bool {
+		if stalls[i].elapsed != stalls[j].elapsed {
+			return stalls[i].elapsed > stalls[j].elapsed
+		}
+		return stalls[i].name < stalls[j].name
+	})
+
+	return stalls
+}
@@ -0,0 +1,73 @@
+package deploy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestStallDetector(t *testing.T) {
+	if newStallDetector("app", 0) != nil {
+		t.Error("expected no stall detection without a threshold")
+	}
+
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	event := func(logicalId string, status types.ResourceStatus, at time.Duration) types.StackEvent {
+		return types.StackEvent{
+			StackId:            ptr.String("stack-id"),
+			StackName:          ptr.String("app"),
+			LogicalResourceId:  ptr.String(logicalId),
+			PhysicalResourceId: ptr.String(logicalId),
+			ResourceType:       ptr.String("AWS::CloudFront::Distribution"),
+			ResourceStatus:     status,
+			Timestamp:          ptr.Time(start.Add(at)),
+		}
+	}
+
+	s := newStallDetector("app", 10*time.Minute)
+
+	s.add(event("Distribution", types.ResourceStatusCreateInProgress, 0))
+	s.add(event("Bucket", types.ResourceStatusCreateInProgress, time.Minute))
+	s.add(event("Bucket", types.ResourceStatusCreateComplete, 2*time.Minute))
+
+	// The stack's own events are ignored
+	s.add(types.StackEvent{
+		StackId:            ptr.String("stack-id"),
+		PhysicalResourceId: ptr.String("stack-id"),
+		LogicalResourceId:  ptr.String("app"),
+		ResourceStatus:     types.ResourceStatusCreateInProgress,
+		Timestamp:          ptr.Time(start),
+	})
+
+	if stalls := s.check(start.Add(5 * time.Minute)); len(stalls) != 0 {
+		t.Errorf("expected no stalls yet, got %v", stalls)
+	}
+
+	// A repeated in progress event doesn't restart the clock
+	s.add(event("Distribution", types.ResourceStatusCreateInProgress, 6*time.Minute))
+
+	stalls := s.check(start.Add(11 * time.Minute))
+	if len(stalls) != 1 {
+		t.Fatalf("expected 1 stall, got %v", stalls)
+	}
+
+	expected := "Distribution (AWS::CloudFront::Distribution) has been CREATE_IN_PROGRESS for 11m0s and may be stuck"
+	if stalls[0].String() != expected {
+		t.Errorf("expected %q, got %q", expected, stalls[0].String())
+	}
+
+	if stalls := s.check(start.Add(20 * time.Minute)); len(stalls) != 0 {
+		t.Errorf("expected each stall to be reported once, got %v", stalls)
+	}
+
+	// A new operation on the resource is checked again
+	s.add(event("Distribution", types.ResourceStatusCreateFailed, 21*time.Minute))
+	s.add(event("Distribution", types.ResourceStatusDeleteInProgress, 22*time.Minute))
+
+	if stalls := s.check(start.Add(33 * time.Minute)); len(stalls) != 1 || stalls[0].status != "DELETE_IN_PROGRESS" {
+		t.Errorf("expected the delete to be reported, got %v", stalls)
+	}
+}
@@ -0,0 +1,82 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/stateful"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// statefulChanges returns the changes in the change set, and in the change sets of its nested stacks,
+// that would replace or delete a resource that holds data
+func statefulChanges(stackName, changeSetName string) ([]stateful.Change, error) {
+	changeSet, err := cfn.GetChangeSet(stackName, changeSetName)
+	if err != nil {
+		return nil, err
+	}
+
+	if stackName == "" {
+		stackName = ptr.ToString(changeSet.StackName)
+	}
+
+	changes := stateful.Changes(stackName, changeSet.Changes)
+
+	for _, change := range changeSet.Changes {
+		rc := change.ResourceChange
+		if rc == nil || rc.ChangeSetId == nil {
+			continue
+		}
+
+		nested, err := statefulChanges("", ptr.ToString(rc.ChangeSetId))
+		if err != nil {
+			return nil, err
+		}
+
+		changes = append(changes, nested...)
+	}
+
+	return changes, nil
+}
+
+// confirmStateful makes the user type the logical ID of each resource that holds data
+// before the change set replaces or deletes it. --yes doesn't skip this; only --force does.
+func confirmStateful(stackName, changeSetName string) error {
+	if force {
+		return nil
+	}
+
+	spinner.Push("Checking for resources that hold data")
+	changes, err := statefulChanges(stackName, changeSetName)
+	spinner.Pop()
+	if err != nil {
+		return ui.Errorf(err, "error getting changeset '%s' for stack '%s'", changeSetName, stackName)
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+
+	console.Log(console.Red("This deployment replaces or deletes resources that hold data, which may be lost:"))
+	for _, c := range changes {
+		console.Log(console.Red("  " + c.String()))
+	}
+
+	if !console.CanAsk() {
+		return errors.New("rain won't replace or delete resources that hold data without confirmation; " +
+			"use --force to deploy anyway")
+	}
+
+	for _, c := range changes {
+		answer := console.Ask(fmt.Sprintf("Type %s to confirm that %s may lose its data:", c.LogicalId, c.LogicalId))
+		if answer != c.LogicalId {
+			return fmt.Errorf("'%s' does not match %s", answer, c.LogicalId)
+		}
+	}
+
+	return nil
+}
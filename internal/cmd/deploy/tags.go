@@ -0,0 +1,135 @@
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/aws/sts"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/gitinfo"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+	"github.com/aws-cloudformation/rain/internal/tagpolicy"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// tagPolicyPath is the path to a tagging policy file (--tag-policy)
+var tagPolicyPath string
+
+// gitTags and gitMetadata record the template's git repository in the stack (--git-tags, --git-metadata)
+var gitTags bool
+var gitMetadata bool
+
+// loadTagPolicy returns the tagging policy for the deployment, or nil if there isn't one.
+// --tag-policy takes precedence over the manifest's TagPolicy,
+// which takes precedence over RAIN_TAG_POLICY.
+func loadTagPolicy(m *manifest.Manifest) *tagpolicy.Policy {
+	path := tagPolicyPath
+
+	if path == "" {
+		if m != nil {
+			path = m.Path(m.TagPolicy)
+		} else if _, err := os.Stat(manifest.DefaultFileName); err == nil {
+			var err error
+			path, err = manifest.LoadTagPolicy(manifest.DefaultFileName)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to read the tag policy from %s", manifest.DefaultFileName))
+			}
+		}
+	}
+
+	if path == "" {
+		path = tagpolicy.Path
+	}
+
+	if path == "" {
+		return nil
+	}
+
+	p, err := tagpolicy.Load(path)
+	if err != nil {
+		panic(ui.Errorf(err, "unable to load tag policy"))
+	}
+
+	return p
+}
+
+// applyTagPolicy adds the policy's standard tags to the stack's tags
+// and checks the result against the policy
+func applyTagPolicy(p *tagpolicy.Policy, stackName string, templatePath string, tags map[string]string) (map[string]string, error) {
+	if p == nil {
+		return tags, nil
+	}
+
+	tags = p.Apply(tags, deploySource(p, templatePath))
+
+	if violations := p.Check(tags); len(violations) > 0 {
+		return nil, tagpolicy.ViolationError{StackName: stackName, Violations: violations}
+	}
+
+	return tags, nil
+}
+
+// deploySource returns the details of the deployment that the policy's standard tags record
+func deploySource(p *tagpolicy.Policy, templatePath string) tagpolicy.Source {
+	src := tagpolicy.Source{Time: time.Now()}
+
+	if _, ok := p.StandardTags[tagpolicy.GitCommit]; ok {
+		info, err := gitinfo.Read(filepath.Dir(templatePath))
+		if err != nil {
+			config.Debugf("unable to find the git commit of '%s': %s", templatePath, err)
+		} else {
+			src.GitCommit = info.Commit
+		}
+	}
+
+	if _, ok := p.StandardTags[tagpolicy.Deployer]; ok {
+		id, err := sts.GetCallerID()
+		if err != nil {
+			config.Debugf("unable to get the caller identity: %s", err)
+		} else {
+			src.Deployer = ptr.ToString(id.Arn)
+		}
+	}
+
+	return src
+}
+
+// stampGit records the state of the git repository that the template is in
+// in the stack's tags and the template's Metadata, if --git-tags or --git-metadata are set.
+// Tags that are already set are not replaced.
+func stampGit(templatePath string, template cft.Template, tags map[string]string) (map[string]string, error) {
+	if !gitTags && !gitMetadata {
+		return tags, nil
+	}
+
+	info, err := gitinfo.Read(filepath.Dir(templatePath))
+	if errors.Is(err, gitinfo.ErrNotRepository) {
+		fmt.Println(console.Yellow(fmt.Sprintf("Template '%s' is not in a git repository, so no git details were recorded", templatePath)))
+		return tags, nil
+	}
+	if err != nil {
+		return nil, ui.Errorf(err, "unable to read the git repository of '%s'", templatePath)
+	}
+
+	if gitMetadata {
+		if err := info.AddMetadata(template); err != nil {
+			return nil, ui.Errorf(err, "unable to add git details to the Metadata of '%s'", templatePath)
+		}
+	}
+
+	if gitTags {
+		out := info.Tags()
+		for k, v := range tags {
+			out[k] = v
+		}
+		tags = out
+	}
+
+	return tags, nil
+}
@@ -1,6 +1,7 @@
 package deploy
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -11,11 +12,34 @@ import (
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/manifest"
 	"github.com/aws-cloudformation/rain/internal/ui"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go"
 	"github.com/aws/smithy-go/ptr"
 )
 
+// emitChangeSet writes the change set's resource changes as a structured event
+func emitChangeSet(stackName, changeSetName string) {
+	if !emit.Enabled() {
+		return
+	}
+
+	cs, err := cfn.GetChangeSet(stackName, changeSetName)
+	if err != nil {
+		panic(ui.Errorf(err, "error getting changeset '%s' for stack '%s'", changeSetName, stackName))
+	}
+
+	emit.Event("changeset", map[string]interface{}{
+		"stackName":     stackName,
+		"changeSetName": ptr.ToString(cs.ChangeSetName),
+		"changeSetId":   ptr.ToString(cs.ChangeSetId),
+		"changes":       resourceChanges(cs),
+	})
+}
+
 func formatChangeSet(stackName, changeSetName string) string {
 	status, err := cfn.GetChangeSet(stackName, changeSetName)
 	if err != nil {
@@ -78,21 +102,56 @@ func formatChangeSet(stackName, changeSetName string) string {
 		out.WriteString("\n")
 	}
 
-	return strings.TrimSpace(out.String())
+	return config.Mask(strings.TrimSpace(out.String()))
 }
 
 func PackageTemplate(fn string, yes bool) cft.Template {
 	// Call RainBucket for side-effects in case we want to force bucket creation
 	s3.RainBucket(yes)
 
+	if err := manifest.UseProject(); err != nil {
+		panic(ui.Errorf(err, "unable to load %s", manifest.DefaultFileName))
+	}
+
 	t, err := pkg.File(fn)
 	if err != nil {
-		panic(ui.Errorf(err, "error packaging template '%s'", fn))
+		panic(exitcode.Wrap(exitcode.Invalid, ui.Errorf(err, "error packaging template '%s'", fn)))
 	}
 
 	return t
 }
 
+// changeSetErrorCode returns the exit code for a change set that couldn't be created:
+// exitcode.Invalid if CloudFormation rejected the template or parameters, or exitcode.DeployFailed
+func changeSetErrorCode(err error) int {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "ValidationError" {
+		return exitcode.Invalid
+	}
+
+	return exitcode.DeployFailed
+}
+
+// deleteEmptyStack deletes a stack that has no resources and waits for the deletion to finish
+func deleteEmptyStack(stackName string) {
+	message := "Existing stack is empty; deleting it."
+	fmt.Println(message)
+
+	err := cfn.DeleteStack(stackName, "")
+	if err != nil {
+		panic(ui.Errorf(err, "unable to delete stack '%s'", stackName))
+	}
+
+	status, _ := cfn.WaitForStackToSettle(stackName)
+
+	if status != "DELETE_COMPLETE" {
+		panic(fmt.Errorf("failed to delete stack '%s'", stackName))
+	}
+
+	console.ClearLines(console.CountLines(message) + 1)
+	fmt.Println("Deleted existing, empty stack.")
+}
+
 func CheckStack(stackName string) (types.Stack, bool) {
 	// Find out if stack exists already
 	// If it does and it's not in a good state, offer to wait/delete
@@ -108,26 +167,31 @@ func CheckStack(stackName string) (types.Stack, bool) {
 
 	if stackExists {
 		switch {
-		case stack.StackStatus == types.StackStatusRollbackComplete,
-			stack.StackStatus == types.StackStatusReviewInProgress,
-			stack.StackStatus == types.StackStatusCreateFailed:
+		case stack.StackStatus == types.StackStatusReviewInProgress:
+			// The stack was created by a change set that was never executed
+			fmt.Printf("Stack '%s' is in REVIEW_IN_PROGRESS because its change set was never executed.\n", stackName)
+
+			sets, _ := cfn.ListChangeSets(stackName)
+			for _, cs := range sets {
+				fmt.Printf("  Pending change set: %s %s\n",
+					console.Blue(ptr.ToString(cs.ChangeSetName)), ui.ColouriseStatus(string(cs.Status)))
+			}
 
-			message := "Existing stack is empty; deleting it."
-			fmt.Println(message)
+			if len(sets) > 0 {
+				fmt.Printf("To deploy a pending change set instead, run: rain deploy --changeset %s <changeset>\n", stackName)
+			}
 
-			err := cfn.DeleteStack(stackName, "")
-			if err != nil {
-				panic(ui.Errorf(err, "unable to delete stack '%s'", stackName))
+			if !yes && !console.Confirm(true, "Delete the pending change sets and continue?") {
+				panic(errors.New("user cancelled deployment"))
 			}
 
-			status, _ := cfn.WaitForStackToSettle(stackName)
+			deleteEmptyStack(stackName)
 
-			if status != "DELETE_COMPLETE" {
-				panic(fmt.Errorf("failed to delete stack '%s'", stackName))
-			}
+			stackExists = false
+		case stack.StackStatus == types.StackStatusRollbackComplete,
+			stack.StackStatus == types.StackStatusCreateFailed:
 
-			console.ClearLines(console.CountLines(message) + 1)
-			fmt.Println("Deleted existing, empty stack.")
+			deleteEmptyStack(stackName)
 
 			stackExists = false
 		case !strings.HasSuffix(string(stack.StackStatus), "_COMPLETE"):
@@ -140,3 +204,14 @@ func CheckStack(stackName string) (types.Stack, bool) {
 
 	return stack, stackExists
 }
+
+// checkUnlocked returns an error if the stack has been locked with rain protect and --unlock is not set
+func checkUnlocked(stackName string) error {
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		// A change set that creates a stack has nothing to lock yet
+		return nil
+	}
+
+	return cfn.CheckUnlocked(stack, unlock)
+}
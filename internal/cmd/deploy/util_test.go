@@ -1 +1,29 @@
-package deploy_test
+package deploy
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws/smithy-go"
+)
+
+func TestChangeSetErrorCode(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected int
+	}{
+		{&smithy.GenericAPIError{Code: "ValidationError", Message: "Template format error: unknown function"}, exitcode.Invalid},
+		{fmt.Errorf("operation error CloudFormation: CreateChangeSet: %w",
+			&smithy.GenericAPIError{Code: "ValidationError", Message: "Parameters: [Env] must have values"}), exitcode.Invalid},
+		{&smithy.GenericAPIError{Code: "AccessDenied"}, exitcode.DeployFailed},
+		{errors.New("change set failed"), exitcode.DeployFailed},
+	}
+
+	for _, c := range cases {
+		if actual := changeSetErrorCode(c.err); actual != c.expected {
+			t.Errorf("%s: expected %d, got %d", c.err, c.expected, actual)
+		}
+	}
+}
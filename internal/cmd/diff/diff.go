@@ -3,20 +3,37 @@ package diff
 import (
 	"fmt"
 
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/emit"
 	"github.com/aws-cloudformation/rain/internal/ui"
 
+	"github.com/aws-cloudformation/rain/cft"
 	"github.com/aws-cloudformation/rain/cft/diff"
+	"github.com/aws-cloudformation/rain/cft/langext"
 	"github.com/aws-cloudformation/rain/cft/parse"
 	"github.com/spf13/cobra"
 )
 
 var longDiff = false
+var transform = false
+var expandForEach = false
+var params []string
 
 // Cmd is the diff command's entrypoint
 var Cmd = &cobra.Command{
-	Use:                   "diff <from> <to>",
-	Short:                 "Compare CloudFormation templates",
-	Long:                  "Outputs a summary of the changes necessary to transform the CloudFormation template named <from> into the template named <to>.",
+	Use:   "diff <from> <to>",
+	Short: "Compare CloudFormation templates",
+	Long: `Outputs a summary of the changes necessary to transform the CloudFormation template named <from> into the template named <to>.
+
+Use --transform to compare the resources that CloudFormation will actually create for templates
+that declare transforms, such as AWS::Serverless-2016-10-31. The transforms are run by creating
+a change set for a temporary stack, which is deleted afterwards; use --params to set any
+parameters that don't have defaults.
+
+Use --expand-foreach to compare the resources that Fn::ForEach loops create without calling CloudFormation.
+Loops over parameters use --params, or the parameters' defaults.`,
 	Args:                  cobra.ExactArgs(2),
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
@@ -32,10 +49,49 @@ var Cmd = &cobra.Command{
 			panic(ui.Errorf(err, "unable to parse template '%s'", leftFn))
 		}
 
-		fmt.Print(ui.ColouriseDiff(diff.New(left, right), longDiff))
+		if expandForEach && !transform {
+			left = expandTemplate(leftFn, left)
+			right = expandTemplate(rightFn, right)
+		}
+
+		if transform {
+			left = transformTemplate(leftFn, left)
+			right = transformTemplate(rightFn, right)
+		}
+
+		d := diff.New(left, right)
+
+		emit.Event("diff", map[string]any{"changes": diff.Changes(d)})
+
+		fmt.Print(ui.ColouriseDiff(d, longDiff))
 	},
 }
 
+// transformTemplate runs the template's transforms, if it has any
+func transformTemplate(fn string, t cft.Template) cft.Template {
+	spinner.Push(fmt.Sprintf("Transforming %s", fn))
+	out, err := cfn.TransformTemplate(t, dc.ListToMap("param", params))
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "unable to transform template '%s'", fn))
+	}
+
+	return out
+}
+
+// expandTemplate expands the template's Fn::ForEach loops
+func expandTemplate(fn string, t cft.Template) cft.Template {
+	out, err := langext.Expand(t, dc.ListToMap("param", params))
+	if err != nil {
+		panic(ui.Errorf(err, "unable to expand Fn::ForEach in '%s'", fn))
+	}
+
+	return out
+}
+
 func init() {
 	Cmd.Flags().BoolVarP(&longDiff, "long", "l", false, "Include unchanged elements in diff output")
+	Cmd.Flags().BoolVarP(&transform, "transform", "t", false, "Compare the templates after running their transforms, such as AWS::Serverless-2016-10-31")
+	Cmd.Flags().BoolVar(&expandForEach, "expand-foreach", false, "Expand Fn::ForEach loops locally before comparing")
+	Cmd.Flags().StringSliceVar(&params, "params", []string{}, "Parameter values for --transform and --expand-foreach; use the format key1=value1,key2=value2")
 }
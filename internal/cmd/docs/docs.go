@@ -0,0 +1,96 @@
+package docs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/docs"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var htmlFlag bool
+var outFile string
+var title string
+
+// Cmd is the docs command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "docs <template>",
+	Short: "Write documentation for a template's parameters, outputs and resources",
+	Long: `Writes Markdown that documents a template for the people who deploy it:
+
+  - Parameters, with their types, defaults, constraints and descriptions
+  - Outputs, with their values and the names they are exported as
+  - Exports, listed on their own so that other stacks' authors can find them
+  - Resources, with their types and conditions
+
+The defaults of NoEcho parameters are hidden. The documentation is titled with the
+template's file name unless --title is given.
+
+Use --html to write a standalone HTML page instead, e.g. to publish to a wiki,
+and --output to write to a file rather than to stdout:
+
+  rain docs template.yaml --output README.md`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fn := args[0]
+
+		t, err := parse.File(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		name := title
+		if name == "" {
+			base := filepath.Base(fn)
+			name = strings.TrimSuffix(base, filepath.Ext(base))
+		}
+
+		d := docs.New(name, t)
+
+		emit.Event("docs", withoutSecrets(d))
+
+		out := docs.Markdown(d)
+		if htmlFlag {
+			out, err = docs.HTML(d)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to write HTML for '%s'", fn))
+			}
+		}
+
+		if outFile == "" {
+			fmt.Print(out)
+			return
+		}
+
+		if err := os.WriteFile(outFile, []byte(out), 0644); err != nil {
+			panic(ui.Errorf(err, "unable to write '%s'", outFile))
+		}
+	},
+}
+
+// withoutSecrets returns the documentation without the defaults of NoEcho parameters,
+// which the Markdown and HTML hide too
+func withoutSecrets(d docs.Doc) docs.Doc {
+	params := make([]docs.Parameter, len(d.Parameters))
+	for i, p := range d.Parameters {
+		if p.NoEcho {
+			p.Default = ""
+		}
+		params[i] = p
+	}
+	d.Parameters = params
+
+	return d
+}
+
+func init() {
+	Cmd.Flags().BoolVar(&htmlFlag, "html", false, "Write an HTML page instead of Markdown")
+	Cmd.Flags().StringVarP(&outFile, "output", "o", "", "Write the documentation to this file instead of stdout")
+	Cmd.Flags().StringVar(&title, "title", "", "The title of the documentation; by default, the template's file name")
+}
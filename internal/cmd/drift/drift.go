@@ -0,0 +1,196 @@
+package drift
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/diff"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var formatFlag string
+var all bool
+
+// Cmd is the drift command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "drift <stack>",
+	Short: "Detect drift in a CloudFormation stack",
+	Long: `Runs drift detection on a stack, waits for it to finish, and shows each resource
+whose configuration no longer matches the template, with the differences between
+the expected and actual properties.
+
+Only resources that have drifted are shown, unless --all is set.
+Use --format json to get the report in a form that scripts can read.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		stackName := args[0]
+
+		if formatFlag != "text" && formatFlag != "json" {
+			panic(fmt.Errorf("unknown format '%s'; use text or json", formatFlag))
+		}
+
+		spinner.Push(fmt.Sprintf("Detecting drift in stack '%s'", stackName))
+		status, err := cfn.DetectStackDrift(stackName)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to detect drift in stack '%s'", stackName))
+		}
+
+		spinner.Push("Getting drift results")
+		drifts, err := cfn.GetStackResourceDrifts(stackName)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to get drift results for stack '%s'", stackName))
+		}
+
+		r := newReport(stackName, status, drifts)
+
+		emit.Event("drift", r)
+
+		if formatFlag == "json" {
+			out, err := json.MarshalIndent(r, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else {
+			printReport(r)
+		}
+	},
+}
+
+// Difference is a property that does not have the value in the template
+type Difference struct {
+	Path     string `json:"path"`
+	Type     string `json:"type"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// Resource is the drift of a single resource
+type Resource struct {
+	LogicalId   string       `json:"logicalId"`
+	PhysicalId  string       `json:"physicalId"`
+	Type        string       `json:"type"`
+	Status      string       `json:"status"`
+	Differences []Difference `json:"differences,omitempty"`
+
+	expected string
+	actual   string
+}
+
+// Report is the drift of a stack
+type Report struct {
+	Stack     string     `json:"stack"`
+	Status    string     `json:"status"`
+	Resources []Resource `json:"resources"`
+}
+
+func newReport(stackName string, status types.StackDriftStatus, drifts []types.StackResourceDrift) Report {
+	r := Report{
+		Stack:     stackName,
+		Status:    string(status),
+		Resources: make([]Resource, 0),
+	}
+
+	for _, d := range drifts {
+		if !all && d.StackResourceDriftStatus == types.StackResourceDriftStatusInSync {
+			continue
+		}
+
+		resource := Resource{
+			LogicalId:  ptr.ToString(d.LogicalResourceId),
+			PhysicalId: ptr.ToString(d.PhysicalResourceId),
+			Type:       ptr.ToString(d.ResourceType),
+			Status:     string(d.StackResourceDriftStatus),
+			expected:   ptr.ToString(d.ExpectedProperties),
+			actual:     ptr.ToString(d.ActualProperties),
+		}
+
+		for _, p := range d.PropertyDifferences {
+			resource.Differences = append(resource.Differences, Difference{
+				Path:     ptr.ToString(p.PropertyPath),
+				Type:     string(p.DifferenceType),
+				Expected: ptr.ToString(p.ExpectedValue),
+				Actual:   ptr.ToString(p.ActualValue),
+			})
+		}
+
+		r.Resources = append(r.Resources, resource)
+	}
+
+	return r
+}
+
+// propertyDiff renders the difference between the expected and actual properties
+// in the same way as rain diff
+func propertyDiff(expected, actual string) (string, error) {
+	var e, a map[string]any
+
+	if err := json.Unmarshal([]byte(expected), &e); err != nil {
+		return "", err
+	}
+
+	if err := json.Unmarshal([]byte(actual), &a); err != nil {
+		return "", err
+	}
+
+	return ui.ColouriseDiff(diff.CompareMaps(e, a), false), nil
+}
+
+func statusColour(status string) func(...any) string {
+	switch status {
+	case string(types.StackResourceDriftStatusInSync):
+		return console.Green
+	case string(types.StackResourceDriftStatusNotChecked):
+		return console.Grey
+	default:
+		return console.Red
+	}
+}
+
+func printReport(r Report) {
+	fmt.Printf("Stack %s: %s\n", console.Yellow(r.Stack), statusColour(r.Status)(r.Status))
+
+	if len(r.Resources) == 0 {
+		fmt.Println(console.Green("No resources have drifted"))
+		return
+	}
+
+	for _, resource := range r.Resources {
+		fmt.Println()
+		fmt.Printf("%s (%s) %s\n", console.Yellow(resource.LogicalId), resource.Type,
+			statusColour(resource.Status)(resource.Status))
+
+		if resource.Status != string(types.StackResourceDriftStatusModified) {
+			continue
+		}
+
+		out, err := propertyDiff(resource.expected, resource.actual)
+		if err != nil {
+			// Fall back to the differences reported by CloudFormation
+			for _, d := range resource.Differences {
+				fmt.Printf("  %s %s: %s -> %s\n", d.Type, d.Path, d.Expected, d.Actual)
+			}
+			continue
+		}
+
+		for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	}
+}
+
+func init() {
+	Cmd.Flags().StringVar(&formatFlag, "format", "text", "Output format: text or json")
+	Cmd.Flags().BoolVarP(&all, "all", "a", false, "Include resources that have not drifted")
+}
@@ -0,0 +1,53 @@
+package drift
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestNewReport(t *testing.T) {
+	drifts := []types.StackResourceDrift{
+		{
+			LogicalResourceId:        ptr.String("Bucket"),
+			ResourceType:             ptr.String("AWS::S3::Bucket"),
+			StackResourceDriftStatus: types.StackResourceDriftStatusModified,
+			ExpectedProperties:       ptr.String(`{"BucketName": "a", "Versioning": {"Status": "Enabled"}}`),
+			ActualProperties:         ptr.String(`{"BucketName": "a", "Versioning": {"Status": "Suspended"}}`),
+			PropertyDifferences: []types.PropertyDifference{
+				{
+					PropertyPath:   ptr.String("/Versioning/Status"),
+					DifferenceType: types.DifferenceTypeNotEqual,
+					ExpectedValue:  ptr.String("Enabled"),
+					ActualValue:    ptr.String("Suspended"),
+				},
+			},
+		},
+		{
+			LogicalResourceId:        ptr.String("Queue"),
+			ResourceType:             ptr.String("AWS::SQS::Queue"),
+			StackResourceDriftStatus: types.StackResourceDriftStatusInSync,
+		},
+	}
+
+	r := newReport("test", types.StackDriftStatusDrifted, drifts)
+
+	if len(r.Resources) != 1 || r.Resources[0].LogicalId != "Bucket" {
+		t.Fatalf("expected only the drifted bucket: %v", r.Resources)
+	}
+
+	if d := r.Resources[0].Differences; len(d) != 1 || d[0].Path != "/Versioning/Status" || d[0].Actual != "Suspended" {
+		t.Errorf("unexpected differences: %v", d)
+	}
+
+	out, err := propertyDiff(r.Resources[0].expected, r.Resources[0].actual)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out, "Suspended") || strings.Contains(out, "BucketName") {
+		t.Errorf("unexpected diff:\n%s", out)
+	}
+}
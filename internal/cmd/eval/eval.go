@@ -0,0 +1,92 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft/eval"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var params []string
+var region string
+var accountId string
+var stackName string
+var jsonFlag bool
+
+// pseudo returns the values of the pseudo parameters that were set with flags
+func pseudo() map[string]string {
+	values := make(map[string]string)
+
+	if region != "" {
+		p := partition.ForRegion(region)
+		values["AWS::Region"] = region
+		values["AWS::Partition"] = p.ID
+		values["AWS::URLSuffix"] = p.URLSuffix
+	}
+
+	if accountId != "" {
+		values["AWS::AccountId"] = accountId
+	}
+
+	if stackName != "" {
+		values["AWS::StackName"] = stackName
+	}
+
+	return values
+}
+
+// Cmd is the eval command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "eval <template>",
+	Short: "Show a template with its parameters, conditions and intrinsic functions resolved",
+	Long: `Evaluates a template locally, without deploying it, for a set of parameter values,
+and prints the template as CloudFormation would see it:
+
+  - Refs to parameters and pseudo parameters are replaced with their values
+  - Fn::Sub, Fn::Join, Fn::If, Fn::Select, Fn::FindInMap and Fn::Split are evaluated
+  - Each condition is shown as true or false
+  - Resources and outputs whose conditions are false are removed, as are values set to AWS::NoValue
+
+Parameters without a value from --params use their defaults. Pseudo parameters are only
+resolved if you set them with --region, --account-id and --stack-name.
+Values that are only known once the stack is deployed, like Fn::GetAtt, are left as they are.
+
+  rain eval template.yaml --params Env=prod,Subnets=subnet-1,subnet-2 --region eu-west-1`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fn := args[0]
+
+		t, err := parse.File(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		resolved, err := eval.Resolve(t, eval.Values{
+			Parameters: dc.ListToMap("params", params),
+			Pseudo:     pseudo(),
+		})
+		if err != nil {
+			panic(ui.Errorf(err, "unable to evaluate '%s'", fn))
+		}
+
+		// Keep the template in its original order so that it is easy to compare with the source
+		fmt.Print(format.String(resolved, format.Options{
+			JSON:     jsonFlag,
+			Unsorted: true,
+		}))
+	},
+}
+
+func init() {
+	Cmd.Flags().StringSliceVar(&params, "params", []string{}, "set parameter values; use the format key1=value1,key2=value2")
+	Cmd.Flags().StringVar(&region, "region", "", "the value of AWS::Region, which also sets AWS::Partition and AWS::URLSuffix")
+	Cmd.Flags().StringVar(&accountId, "account-id", "", "the value of AWS::AccountId")
+	Cmd.Flags().StringVar(&stackName, "stack-name", "", "the value of AWS::StackName")
+	Cmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "output the template as JSON")
+}
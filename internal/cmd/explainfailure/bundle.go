@@ -0,0 +1,154 @@
+package explainfailure
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cloudtrail"
+	"github.com/aws-cloudformation/rain/internal/console"
+)
+
+// Bundle holds everything rain knows about a failed stack operation
+type Bundle struct {
+	Stack     StackInfo          `json:"stack"`
+	RootCause *Event             `json:"rootCause,omitempty"`
+	Events    []Event            `json:"failedEvents"`
+	Resources []Resource         `json:"resources"`
+	APIErrors []cloudtrail.Error `json:"cloudTrailErrors"`
+	Quotas    []Quota            `json:"quotas"`
+	Warnings  []string           `json:"warnings,omitempty"`
+	Created   time.Time          `json:"created"`
+}
+
+// StackInfo describes the stack
+type StackInfo struct {
+	Name         string    `json:"name"`
+	Id           string    `json:"id"`
+	Region       string    `json:"region"`
+	Status       string    `json:"status"`
+	StatusReason string    `json:"statusReason,omitempty"`
+	LastUpdated  time.Time `json:"lastUpdated"`
+}
+
+// Event is a stack event in which a resource failed
+type Event struct {
+	Time       time.Time `json:"time"`
+	Path       []string  `json:"path,omitempty"`
+	LogicalId  string    `json:"logicalId"`
+	PhysicalId string    `json:"physicalId,omitempty"`
+	Type       string    `json:"type"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason,omitempty"`
+}
+
+// Resource is the current state of a resource in the stack
+type Resource struct {
+	LogicalId  string `json:"logicalId"`
+	PhysicalId string `json:"physicalId,omitempty"`
+	Type       string `json:"type"`
+	Status     string `json:"status"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+// Quota is the value of a service quota that might have caused the failure
+type Quota struct {
+	ServiceCode string  `json:"serviceCode"`
+	QuotaCode   string  `json:"quotaCode"`
+	Description string  `json:"description"`
+	Value       float64 `json:"value"`
+}
+
+// redactions replace details that identify an account or network
+// with placeholders, so that the bundle can be shared
+var redactions = []struct {
+	re  *regexp.Regexp
+	out string
+}{
+	{regexp.MustCompile(`\b\d{12}\b`), "<account>"},
+	{regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}\b`), "<ip>"},
+	{regexp.MustCompile(`\b[\w.+-]+@[\w-]+\.[\w.-]+\b`), "<email>"},
+	{regexp.MustCompile(`\b(AKIA|ASIA)[A-Z0-9]{16}\b`), "<access-key>"},
+}
+
+func redact(s string) string {
+	for _, r := range redactions {
+		s = r.re.ReplaceAllString(s, r.out)
+	}
+
+	return s
+}
+
+// JSON returns the bundle as indented JSON, with identifying details removed if redacted is true
+func (b Bundle) JSON(redacted bool) (string, error) {
+	out, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	if redacted {
+		return redact(string(out)), nil
+	}
+
+	return string(out), nil
+}
+
+func (e Event) name() string {
+	return strings.Join(append(append([]string{}, e.Path...), e.LogicalId), "/")
+}
+
+// Print shows a summary of the bundle
+func (b Bundle) Print() {
+	fmt.Printf("Stack %s: %s\n", console.Yellow(b.Stack.Name), console.Red(b.Stack.Status))
+	if b.Stack.StatusReason != "" {
+		fmt.Printf("  %s\n", b.Stack.StatusReason)
+	}
+
+	if b.RootCause != nil {
+		fmt.Println()
+		fmt.Println(console.Yellow("Root cause:"))
+		fmt.Printf("  %s (%s) %s\n", b.RootCause.name(), b.RootCause.Type, console.Red(b.RootCause.Status))
+		fmt.Printf("  %s\n", b.RootCause.Reason)
+	}
+
+	if len(b.Events) > 0 {
+		fmt.Println()
+		fmt.Println(console.Yellow("Failed events:"))
+		for _, e := range b.Events {
+			fmt.Printf("  %s %s (%s) %s: %s\n", console.Grey(e.Time.Format(time.RFC3339)),
+				e.name(), e.Type, console.Red(e.Status), e.Reason)
+		}
+	}
+
+	if len(b.Resources) > 0 {
+		fmt.Println()
+		fmt.Println(console.Yellow("Resources that are not complete:"))
+		for _, r := range b.Resources {
+			fmt.Printf("  %s (%s) %s %s\n", r.LogicalId, r.Type, r.Status, console.Grey(r.Reason))
+		}
+	}
+
+	if len(b.APIErrors) > 0 {
+		fmt.Println()
+		fmt.Println(console.Yellow("CloudTrail errors during the operation:"))
+		for _, e := range b.APIErrors {
+			fmt.Printf("  %s %s %s: %s %s\n", console.Grey(e.Time.Format(time.RFC3339)),
+				e.Source, e.Name, console.Red(e.Code), e.Message)
+		}
+	}
+
+	if len(b.Quotas) > 0 {
+		fmt.Println()
+		fmt.Println(console.Yellow("Quotas:"))
+		for _, q := range b.Quotas {
+			fmt.Printf("  %s: %v\n", q.Description, q.Value)
+		}
+	}
+
+	for _, w := range b.Warnings {
+		fmt.Println()
+		fmt.Println(console.Grey(w))
+	}
+}
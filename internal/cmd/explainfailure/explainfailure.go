@@ -0,0 +1,244 @@
+package explainfailure
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/aws/cloudtrail"
+	"github.com/aws-cloudformation/rain/internal/aws/servicequotas"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var outputPath string
+var redactFlag bool
+
+// Cmd is the explain-failure command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "explain-failure <stack>",
+	Short: "Collect what went wrong in a stack's last operation",
+	Long: `Gathers everything needed to understand why the most recent operation on a stack failed
+into a single diagnostic bundle:
+
+  - the stack's status and the root cause of the failure, following nested stacks
+  - every resource that failed during the operation
+  - the resources that are not in a complete state
+  - write calls that failed in CloudTrail while the operation was running
+  - the service quotas for the types of the resources that failed
+
+A summary is shown, and --output writes the whole bundle as JSON so that it can be
+attached to a support case or shared with a teammate. Use --redact to replace account IDs,
+IP addresses, email addresses and access key IDs with placeholders.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		stackName := args[0]
+
+		spinner.Push(fmt.Sprintf("Collecting failure details for %s", stackName))
+		b, err := collect(stackName)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to explain the failure of '%s'", stackName))
+		}
+
+		if outputPath != "-" {
+			b.Print()
+		}
+
+		if outputPath == "" {
+			return
+		}
+
+		out, err := b.JSON(redactFlag)
+		if err != nil {
+			panic(err)
+		}
+
+		if outputPath == "-" {
+			fmt.Println(out)
+			return
+		}
+
+		if err := os.WriteFile(outputPath, []byte(out+"\n"), 0644); err != nil {
+			panic(ui.Errorf(err, "unable to write '%s'", outputPath))
+		}
+
+		fmt.Println()
+		fmt.Printf("Wrote the diagnostic bundle to %s\n", outputPath)
+	},
+}
+
+// quotas are the service quotas that commonly cause resources of a type to fail to create
+var quotas = map[string][]Quota{
+	"AWS::CloudFormation::Stack": {
+		{ServiceCode: "cloudformation", QuotaCode: "L-0485CFC8", Description: "Stack count"},
+	},
+	"AWS::EC2::VPC": {
+		{ServiceCode: "vpc", QuotaCode: "L-F678F1CE", Description: "VPCs per region"},
+	},
+	"AWS::EC2::InternetGateway": {
+		{ServiceCode: "vpc", QuotaCode: "L-A4707A72", Description: "Internet gateways per region"},
+	},
+	"AWS::EC2::EIP": {
+		{ServiceCode: "ec2", QuotaCode: "L-0263D0A3", Description: "EC2-VPC Elastic IPs"},
+	},
+	"AWS::EC2::NatGateway": {
+		{ServiceCode: "vpc", QuotaCode: "L-FE5A380F", Description: "NAT gateways per Availability Zone"},
+	},
+	"AWS::Lambda::Function": {
+		{ServiceCode: "lambda", QuotaCode: "L-B99A9384", Description: "Lambda concurrent executions"},
+	},
+	"AWS::S3::Bucket": {
+		{ServiceCode: "s3", QuotaCode: "L-DC2B2D3D", Description: "S3 general purpose buckets"},
+	},
+	"AWS::IAM::Role": {
+		{ServiceCode: "iam", QuotaCode: "L-FE177D64", Description: "IAM roles per account"},
+	},
+}
+
+func toEvent(e types.StackEvent, path []string) Event {
+	return Event{
+		Time:       ptr.ToTime(e.Timestamp),
+		Path:       path,
+		LogicalId:  ptr.ToString(e.LogicalResourceId),
+		PhysicalId: ptr.ToString(e.PhysicalResourceId),
+		Type:       ptr.ToString(e.ResourceType),
+		Status:     string(e.ResourceStatus),
+		Reason:     ptr.ToString(e.ResourceStatusReason),
+	}
+}
+
+// collect builds the bundle for the stack.
+// Details that can't be looked up are recorded as warnings,
+// so that a missing permission doesn't hide everything else.
+func collect(stackName string) (Bundle, error) {
+	b := Bundle{
+		Events:    make([]Event, 0),
+		Resources: make([]Resource, 0),
+		APIErrors: make([]cloudtrail.Error, 0),
+		Quotas:    make([]Quota, 0),
+		Created:   time.Now().UTC(),
+	}
+
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		return b, err
+	}
+
+	b.Stack = StackInfo{
+		Name:         ptr.ToString(stack.StackName),
+		Id:           ptr.ToString(stack.StackId),
+		Region:       aws.Config().Region,
+		Status:       string(stack.StackStatus),
+		StatusReason: ptr.ToString(stack.StackStatusReason),
+		LastUpdated:  ptr.ToTime(stack.CreationTime),
+	}
+	if stack.LastUpdatedTime != nil {
+		b.Stack.LastUpdated = *stack.LastUpdatedTime
+	}
+
+	spinner.Push("Finding the root cause")
+	failure, err := cfn.RootCause(stackName)
+	spinner.Pop()
+	if err != nil {
+		b.Warnings = append(b.Warnings, fmt.Sprintf("Unable to find the root cause: %v", err))
+	} else if failure != nil {
+		e := toEvent(failure.Event, failure.Path)
+		b.RootCause = &e
+	}
+
+	spinner.Push("Reading stack events")
+	events, err := cfn.LastOperationEvents(stackName)
+	spinner.Pop()
+	if err != nil {
+		return b, err
+	}
+
+	failedTypes := make(map[string]bool)
+	if b.RootCause != nil {
+		failedTypes[b.RootCause.Type] = true
+	}
+
+	for i := len(events) - 1; i >= 0; i-- {
+		e := events[i]
+		if !strings.HasSuffix(string(e.ResourceStatus), "_FAILED") {
+			continue
+		}
+
+		b.Events = append(b.Events, toEvent(e, nil))
+		failedTypes[ptr.ToString(e.ResourceType)] = true
+	}
+
+	spinner.Push("Reading resource states")
+	resources, err := cfn.GetStackResources(stackName)
+	spinner.Pop()
+	if err != nil {
+		b.Warnings = append(b.Warnings, fmt.Sprintf("Unable to read resources: %v", err))
+	}
+
+	for _, r := range resources {
+		if strings.HasSuffix(string(r.ResourceStatus), "_COMPLETE") {
+			continue
+		}
+
+		b.Resources = append(b.Resources, Resource{
+			LogicalId:  ptr.ToString(r.LogicalResourceId),
+			PhysicalId: ptr.ToString(r.PhysicalResourceId),
+			Type:       ptr.ToString(r.ResourceType),
+			Status:     string(r.ResourceStatus),
+			Reason:     ptr.ToString(r.ResourceStatusReason),
+		})
+	}
+
+	if len(events) > 0 {
+		// Events are newest first
+		start := ptr.ToTime(events[len(events)-1].Timestamp)
+		end := ptr.ToTime(events[0].Timestamp)
+
+		spinner.Push("Looking up CloudTrail errors")
+		apiErrors, err := cloudtrail.LookupErrors(start, end.Add(time.Minute))
+		spinner.Pop()
+		if err != nil {
+			b.Warnings = append(b.Warnings, fmt.Sprintf("Unable to look up CloudTrail errors: %v", err))
+		} else {
+			b.APIErrors = apiErrors
+		}
+	}
+
+	typeNames := make([]string, 0, len(failedTypes))
+	for typeName := range failedTypes {
+		typeNames = append(typeNames, typeName)
+	}
+	sort.Strings(typeNames)
+
+	spinner.Push("Looking up service quotas")
+	for _, typeName := range typeNames {
+		for _, q := range quotas[typeName] {
+			value, err := servicequotas.GetQuota(q.ServiceCode, q.QuotaCode)
+			if err != nil || value < 0 {
+				config.Debugf("Unable to get quota %s: %v", q.QuotaCode, err)
+				continue
+			}
+
+			q.Value = value
+			b.Quotas = append(b.Quotas, q)
+		}
+	}
+	spinner.Pop()
+
+	return b, nil
+}
+
+func init() {
+	Cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Write the diagnostic bundle as JSON to this file, or - for stdout")
+	Cmd.Flags().BoolVar(&redactFlag, "redact", false, "Replace account IDs, IP addresses, email addresses and access key IDs in the bundle")
+}
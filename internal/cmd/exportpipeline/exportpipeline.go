@@ -0,0 +1,168 @@
+package exportpipeline
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	cftpkg "github.com/aws-cloudformation/rain/cft/pkg"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// The names of the files in the output directory
+const (
+	templateFile      = "template.yaml"
+	configurationFile = "template-configuration.json"
+	buildspecFile     = "buildspec.yml"
+)
+
+var outputDir string
+var configFilePath string
+var params []string
+var tags []string
+var roleArn string
+
+// Cmd is the export-pipeline command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "export-pipeline <template> [stack]",
+	Short: "Write the files that deploy a stack from CodePipeline",
+	Long: `Writes the files that a CodePipeline needs to deploy <template> as [stack] the way rain deploy would,
+so that a stack managed with rain can be added to an existing pipeline:
+
+  template.yaml                 the template, packaged with rain pkg
+  template-configuration.json   the parameters, tags and stack policy from --config, --params and --tags,
+                                as the CloudFormation deploy action's template configuration file
+  buildspec.yml                 a CodeBuild buildspec that packages the template with rain
+                                and outputs both files as an artifact
+
+The files are written to --output-dir, which defaults to "pipeline". Commit them with the template,
+add a CodeBuild action that uses the buildspec, and then a CloudFormation deploy action with the
+configuration that rain prints.
+
+Values in the config file that use !StackOutput are read when the files are written.
+The deploy action passes rain-ssm:// and rain-secretsmanager:// references to CloudFormation
+as they are, so use dynamic references such as {{resolve:ssm:name}} instead.
+
+If [stack] is not set, the stack is named after the template, as rain deploy does.`,
+	Args:                  cobra.RangeArgs(1, 2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fn := args[0]
+		base := filepath.Base(fn)
+
+		stackName := ""
+		if len(args) == 2 {
+			stackName = args[1]
+		}
+		stackName = dc.GetStackName(stackName, base)
+
+		configuration, secrets, err := dc.TemplateConfiguration(configFilePath, params, tags)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read the stack's configuration"))
+		}
+
+		if err := manifest.UseProject(); err != nil {
+			panic(ui.Errorf(err, "unable to load %s", manifest.DefaultFileName))
+		}
+
+		spinner.Push(fmt.Sprintf("Packaging template '%s'", fn))
+		packaged, err := cftpkg.File(fn)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to package template '%s'", fn))
+		}
+
+		if err := os.MkdirAll(outputDir, 0755); err != nil {
+			panic(ui.Errorf(err, "unable to create '%s'", outputDir))
+		}
+
+		files := map[string]string{
+			templateFile:      format.String(packaged, format.Options{}),
+			configurationFile: configuration,
+			buildspecFile:     buildspec(fn, outputDir),
+		}
+
+		for _, name := range []string{templateFile, configurationFile, buildspecFile} {
+			path := filepath.Join(outputDir, name)
+			if err := os.WriteFile(path, []byte(files[name]), 0644); err != nil {
+				panic(ui.Errorf(err, "unable to write '%s'", path))
+			}
+			fmt.Printf("Wrote %s\n", path)
+		}
+
+		for _, p := range secrets {
+			console.Warnf("parameter '%s' refers to a secret, which the deploy action won't read; use a dynamic reference instead", p)
+		}
+
+		fmt.Println()
+		fmt.Println(console.Yellow("CloudFormation deploy action configuration:"))
+		fmt.Print(actionConfiguration(stackName, roleArn))
+	},
+}
+
+// buildspec returns a CodeBuild buildspec that packages the template into dir
+// and outputs the packaged template and its configuration as an artifact
+func buildspec(template, dir string) string {
+	template, dir = filepath.ToSlash(template), filepath.ToSlash(dir)
+
+	return fmt.Sprintf(`# Packages %s with rain for a CloudFormation deploy action.
+# Written by rain export-pipeline.
+version: 0.2
+phases:
+  install:
+    runtime-versions:
+      golang: latest
+    commands:
+      - go install github.com/aws-cloudformation/rain/cmd/rain@%s
+  build:
+    commands:
+      - rain pkg %s --output %s/%s
+artifacts:
+  base-directory: %s
+  files:
+    - %s
+    - %s
+`, template, config.VERSION, template, dir, templateFile, dir, templateFile, configurationFile)
+}
+
+// actionConfiguration returns the settings of the CloudFormation deploy action
+// that deploys the artifact that the buildspec outputs
+func actionConfiguration(stackName, roleArn string) string {
+	if roleArn == "" {
+		roleArn = "<the ARN of the role that CloudFormation uses to deploy the stack>"
+	}
+
+	settings := [][2]string{
+		{"ActionMode", "CREATE_UPDATE"},
+		{"StackName", stackName},
+		{"TemplatePath", "BuildOutput::" + templateFile},
+		{"TemplateConfiguration", "BuildOutput::" + configurationFile},
+		{"Capabilities", "CAPABILITY_NAMED_IAM,CAPABILITY_AUTO_EXPAND"},
+		{"RoleArn", roleArn},
+	}
+
+	var b strings.Builder
+	for _, s := range settings {
+		fmt.Fprintf(&b, "  %-22s %s\n", s[0]+":", s[1])
+	}
+	b.WriteString(console.Grey("  BuildOutput is the name of the CodeBuild action's output artifact.") + "\n")
+
+	return b.String()
+}
+
+func init() {
+	Cmd.Flags().StringVarP(&outputDir, "output-dir", "d", "pipeline", "directory to write the files to")
+	Cmd.Flags().StringVarP(&configFilePath, "config", "c", "", "YAML or JSON file to set tags and parameters, as used by rain deploy")
+	Cmd.Flags().StringSliceVar(&params, "params", []string{}, "set parameter values; use the format key1=value1,key2=value2")
+	Cmd.Flags().StringSliceVar(&tags, "tags", []string{}, "add tags to the stack; use the format key1=value1,key2=value2")
+	Cmd.Flags().StringVar(&roleArn, "role-arn", "", "ARN of the IAM role that CloudFormation should assume to deploy the stack, for the action's configuration")
+	Cmd.Flags().BoolVar(&config.Debug, "debug", false, "Output debugging information")
+}
@@ -1,6 +1,7 @@
 package fmt
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -13,8 +14,10 @@ import (
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/node"
 	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws-cloudformation/rain/sdk"
 
 	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
 	"github.com/spf13/cobra"
 )
 
@@ -23,6 +26,8 @@ var pklFlag bool
 var verifyFlag bool
 var writeFlag bool
 var unsortedFlag bool
+var humanizeFlag bool
+var stringStyle string
 var dataModel bool
 
 // pklPackageAlias is the package name to use in module imports
@@ -39,6 +44,25 @@ type result struct {
 }
 
 func formatString(input string, res *result) {
+	formatter := sdk.Formatter{
+		JSON:        jsonFlag,
+		Unsorted:    unsortedFlag,
+		Humanize:    humanizeFlag,
+		StringStyle: stringStyle,
+	}
+
+	if !dataModel && !pklFlag {
+		formatted, err := formatter.Format(context.Background(), input)
+		showWarnings(res.name, formatted.Warnings)
+		if err != nil {
+			res.err = err
+			return
+		}
+
+		res.output = formatted.Output
+		res.ok = formatted.Formatted
+		return
+	}
 
 	// Parse the template
 	source, err := parse.String(string(input))
@@ -47,28 +71,28 @@ func formatString(input string, res *result) {
 		return
 	}
 
+	warnings, err := formatter.Rewrite(source)
+	showWarnings(res.name, warnings)
+	if err != nil {
+		res.err = err
+		return
+	}
+
 	if dataModel {
 		res.output = node.ToJson(source.Node)
-	} else if pklFlag {
+	} else {
 		res.output, err = format.CftToPkl(source, pklBasic, pklPackageAlias)
 		if err != nil {
 			res.err = err
 			return
 		}
-	} else {
-		// Format the output
-		res.output = format.String(source, format.Options{
-			JSON:     jsonFlag,
-			Unsorted: unsortedFlag,
-		})
-
-		// Verify the output is valid
-		if err = parse.Verify(source, res.output); err != nil {
-			res.err = err
-			return
-		}
+	}
+}
 
-		res.ok = strings.TrimSpace(string(input)) == strings.TrimSpace(res.output)
+// showWarnings shows the values that were written differently because YAML 1.1 reads them differently
+func showWarnings(name string, warnings []string) {
+	for _, w := range warnings {
+		console.Warnf("%s: %s", name, w)
 	}
 }
 
@@ -129,10 +153,23 @@ func formatFile(filename string) result {
 
 // Cmd is the fmt command's entrypoint
 var Cmd = &cobra.Command{
-	Use:                   "fmt <filename>...",
-	Aliases:               []string{"format"},
-	Short:                 "Format CloudFormation templates",
-	Long:                  "Reads CloudFormation templates from filename arguments (or stdin if no filenames are supplied) and formats them",
+	Use:     "fmt <filename>...",
+	Aliases: []string{"format"},
+	Short:   "Format CloudFormation templates",
+	Long: `Reads CloudFormation templates from filename arguments (or stdin if no filenames are supplied) and formats them.
+
+Use --humanize to make templates generated by tools such as the CDK or SAM easier to review:
+tool-specific metadata (e.g. aws:cdk:path) and the CDKMetadata resource are removed,
+Fn::Join expressions that only join strings, Refs and Fn::GetAtts are collapsed into Fn::Sub,
+and parameters, resources and outputs whose names end in a generated hash are moved after the others.
+
+Use --string-style to write strings that are built from several values consistently:
+"sub" rewrites Fn::Join as Fn::Sub and "join" rewrites Fn::Sub as Fn::Join.
+Expressions are only rewritten if the result is equivalent.
+
+CloudFormation reads templates as YAML 1.1, so a few unquoted values don't mean what they appear to.
+rain fmt warns about them and quotes them, so that they stay strings: yes, no, on and off,
+numbers with a leading zero, such as account IDs, and colon-separated numbers such as 1:30.`,
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
 		var results []result
@@ -193,7 +230,7 @@ var Cmd = &cobra.Command{
 		}
 
 		if hasErr {
-			os.Exit(1)
+			exitcode.Exit(exitcode.Invalid)
 		}
 	},
 }
@@ -205,6 +242,8 @@ func init() {
 	Cmd.Flags().BoolVarP(&verifyFlag, "verify", "v", false, "Check if the input is already correctly formatted and exit.\nThe exit status will be 0 if so and 1 if not.")
 	Cmd.Flags().BoolVarP(&writeFlag, "write", "w", false, "Write the output back to the file rather than to stdout.")
 	Cmd.Flags().BoolVarP(&unsortedFlag, "unsorted", "u", false, "Do not sort the template's properties.")
+	Cmd.Flags().StringVar(&stringStyle, "string-style", "", "Rewrite Fn::Join and Fn::Sub expressions to use one style: sub or join.")
+	Cmd.Flags().BoolVar(&humanizeFlag, "humanize", false, "Strip generated metadata and simplify expressions to make machine-generated templates easier to review.")
 	Cmd.Flags().BoolVar(&config.Debug, "debug", false, "Output debugging information")
 	Cmd.Flags().BoolVar(&dataModel, "datamodel", false, "Output the go yaml data model")
 	Cmd.Flags().StringVar(&pklPackageAlias, "pkl-package", "@cfn", "An alias or full package URI for the Pkl package for generated Pkl files")
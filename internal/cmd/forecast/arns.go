@@ -57,7 +57,7 @@ func predictResourceArn(input fc.PredictionInput) string {
 
 	switch input.TypeName {
 	case "AWS::S3::Bucket":
-		return fmt.Sprintf("arn:aws:s3:::%v", physicalId)
+		return fmt.Sprintf("arn:%v:s3:::%v", input.Env.Partition, physicalId)
 	case "AWS::S3::BucketPolicy":
 		return ""
 	case "AWS::S3::AccessPoint":
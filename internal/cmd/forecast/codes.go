@@ -25,4 +25,5 @@ const (
 	F0020 = "F0020"
 	F0021 = "F0021"
 	F0022 = "F0022"
+	F0023 = "F0023"
 )
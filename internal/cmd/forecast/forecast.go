@@ -4,7 +4,6 @@ package forecast
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 	"plugin"
 	"strings"
@@ -21,7 +20,10 @@ import (
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
 	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/manifest"
 	"github.com/aws-cloudformation/rain/internal/s11n"
+	"github.com/aws-cloudformation/rain/internal/ui"
 	"github.com/aws-cloudformation/rain/plugins/deployconfig"
 	fc "github.com/aws-cloudformation/rain/plugins/forecast"
 	"github.com/spf13/cobra"
@@ -164,10 +166,10 @@ func forecastForType(input fc.PredictionInput) fc.Forecast {
 		}
 	}
 
-	// Check service quotas
-	// TODO - Can we do this in a generic way?
-	// https://docs.aws.amazon.com/sdk-for-go/api/service/servicequotas/#ServiceQuotas.GetServiceQuota
-	// https://pkg.go.dev/github.com/aws/aws-sdk-go-v2/service/servicequotas
+	if !pluginOnly {
+		// Check service quotas
+		checkQuota(input, &forecast)
+	}
 
 	// TODO - What about drift errors? Can we predict what will fail based on
 	// a drift detection report for the stack if it already exists?
@@ -211,6 +213,7 @@ func Predict(source cft.Template, stackName string, stack types.Stack, stackExis
 	emptyInput := &fc.PredictionInput{}
 	emptyInput.Ignore = fc.Ignore
 	forecast := fc.MakeForecast(emptyInput)
+	risks := make([]resourceRisk, 0)
 
 	rootMap := source.Node.Content[0]
 
@@ -271,7 +274,9 @@ func Predict(source cft.Template, stackName string, stack types.Stack, stackExis
 			input.RoleArn = callerArn
 		}
 
-		forecast.Append(forecastForType(input))
+		resourceForecast := forecastForType(input)
+		risks = append(risks, newResourceRisk(resourceForecast))
+		forecast.Append(resourceForecast)
 
 		spinner.Pop()
 	}
@@ -292,6 +297,7 @@ func Predict(source cft.Template, stackName string, stack types.Stack, stackExis
 		for _, reason := range forecast.Failed {
 			fmt.Println(console.Red(reason.String()))
 		}
+		printRisks(risks)
 		if all {
 			fmt.Println()
 			fmt.Println(console.Green(fmt.Sprintf(
@@ -343,6 +349,9 @@ template has been checked to make sure it has a valid syntax.
 
 This command checks for some common issues across all resources, and 
 resource-specific checks. See the README for more details.
+
+When checks fail, each resource that failed a check is listed with an
+estimated risk that its deployment will fail.
 `,
 	Args:                  cobra.RangeArgs(1, 2),
 	DisableFlagsInUseLine: true,
@@ -381,6 +390,10 @@ resource-specific checks. See the README for more details.
 			lineNums[logicalId] = lineNum
 		}
 
+		if err := manifest.UseProject(); err != nil {
+			panic(ui.Errorf(err, "unable to load %s", manifest.DefaultFileName))
+		}
+
 		source, err := pkg.File(fn)
 		if err != nil {
 			panic(err)
@@ -445,7 +458,7 @@ resource-specific checks. See the README for more details.
 		}
 
 		if !Predict(source, stackName, stack, stackExists, dc) {
-			os.Exit(1)
+			exitcode.Exit(exitcode.Invalid)
 		}
 
 	},
@@ -37,3 +37,26 @@ func TestForecastMethods(t *testing.T) {
 		t.Errorf("Append did not append")
 	}
 }
+
+func TestRiskiest(t *testing.T) {
+	risks := []resourceRisk{
+		{logicalId: "Safe", failed: 0, checked: 3},
+		{logicalId: "Bucket", failed: 1, checked: 3},
+		{logicalId: "Vpc", failed: 2, checked: 2},
+		{logicalId: "Alarm", failed: 1, checked: 1},
+	}
+
+	out := riskiest(risks)
+
+	if len(out) != 3 {
+		t.Fatalf("Expected 3 risky resources, got %v", len(out))
+	}
+
+	if out[0].logicalId != "Vpc" || out[1].logicalId != "Alarm" || out[2].logicalId != "Bucket" {
+		t.Errorf("Unexpected order: %v", out)
+	}
+
+	if out[0].level() != "High" || out[2].level() != "Medium" || risks[0].level() != "Low" {
+		t.Errorf("Unexpected risk levels")
+	}
+}
@@ -0,0 +1,113 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/aws-cloudformation/rain/internal/aws/ec2"
+	"github.com/aws-cloudformation/rain/internal/aws/servicequotas"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	fc "github.com/aws-cloudformation/rain/plugins/forecast"
+)
+
+// quota is a service quota that limits how many resources of a type can be created
+type quota struct {
+	serviceCode string
+	quotaCode   string
+	description string
+
+	// usage returns how much of the quota is already in use
+	usage func() (int, error)
+}
+
+// quotas are the quotas that are checked for every resource of a type,
+// in addition to any quota checks made by the type's own forecaster
+var quotas = map[string]quota{
+	"AWS::EC2::VPC": {
+		serviceCode: "vpc",
+		quotaCode:   "L-F678F1CE",
+		description: "VPCs per region",
+		usage:       ec2.CountVPCs,
+	},
+	"AWS::EC2::InternetGateway": {
+		serviceCode: "vpc",
+		quotaCode:   "L-A4707A72",
+		description: "internet gateways per region",
+		usage:       ec2.CountInternetGateways,
+	},
+	"AWS::EC2::EIP": {
+		serviceCode: "ec2",
+		quotaCode:   "L-0263D0A3",
+		description: "Elastic IP addresses per region",
+		usage:       ec2.CountElasticIPs,
+	},
+}
+
+// headroom caches the number of resources of each type that can still be created,
+// so that templates with many resources of a type only look it up once
+var headroom = make(map[string]int)
+
+// countResources returns the number of resources of the type in the template
+func countResources(input fc.PredictionInput, typeName string) int {
+	_, resources, _ := s11n.GetMapValue(input.Source.Node.Content[0], "Resources")
+	if resources == nil {
+		return 0
+	}
+
+	count := 0
+	for i := 1; i < len(resources.Content); i += 2 {
+		_, t, _ := s11n.GetMapValue(resources.Content[i], "Type")
+		if t != nil && t.Value == typeName {
+			count++
+		}
+	}
+
+	return count
+}
+
+// checkQuota makes sure there is enough headroom in the account's quota
+// to create every resource of this type in the template
+func checkQuota(input fc.PredictionInput, forecast *fc.Forecast) {
+	q, ok := quotas[input.TypeName]
+	if !ok || input.StackExists {
+		return
+	}
+
+	spin(input.TypeName, input.LogicalId, "quota headroom?")
+	defer spinner.Pop()
+
+	code := F0023
+	lineNum := getLineNum(input.LogicalId, input.Resource)
+
+	free, ok := headroom[input.TypeName]
+	if !ok {
+		limit, err := servicequotas.GetQuota(q.serviceCode, q.quotaCode)
+		if err != nil || limit < 0 {
+			config.Debugf("Unable to get quota %v for %v: %v", q.quotaCode, input.TypeName, err)
+			return
+		}
+
+		used, err := q.usage()
+		if err != nil {
+			config.Debugf("Unable to get usage for %v: %v", input.TypeName, err)
+			return
+		}
+
+		free = int(math.Round(limit)) - used
+		headroom[input.TypeName] = free
+	}
+
+	needed := countResources(input, input.TypeName)
+
+	if needed > free {
+		forecast.Add(code, false,
+			fmt.Sprintf("template creates %v but only %v more %v can be created", needed, max(free, 0), q.description),
+			lineNum)
+	} else {
+		forecast.Add(code, true,
+			fmt.Sprintf("quota for %v ok: %v more can be created", q.description, free),
+			lineNum)
+	}
+}
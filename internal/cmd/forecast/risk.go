@@ -0,0 +1,81 @@
+package forecast
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws-cloudformation/rain/internal/console"
+	fc "github.com/aws-cloudformation/rain/plugins/forecast"
+)
+
+// resourceRisk summarizes the checks for a single resource
+type resourceRisk struct {
+	logicalId string
+	typeName  string
+	failed    int
+	checked   int
+}
+
+func newResourceRisk(f fc.Forecast) resourceRisk {
+	return resourceRisk{
+		logicalId: f.LogicalId,
+		typeName:  f.TypeName,
+		failed:    f.GetNumFailed(),
+		checked:   f.GetNumChecked(),
+	}
+}
+
+// level estimates how likely the resource is to fail,
+// based on the share of its checks that failed
+func (r resourceRisk) level() string {
+	switch {
+	case r.failed == 0:
+		return "Low"
+	case r.failed*2 < r.checked:
+		return "Medium"
+	default:
+		return "High"
+	}
+}
+
+func (r resourceRisk) String() string {
+	out := fmt.Sprintf("%s (%s): %s - %d of %d checks failed",
+		r.logicalId, r.typeName, r.level(), r.failed, r.checked)
+
+	switch r.level() {
+	case "High":
+		return console.Red(out)
+	case "Medium":
+		return console.Yellow(out)
+	default:
+		return console.Green(out)
+	}
+}
+
+// riskiest returns the resources that had failed checks, most failures first
+func riskiest(risks []resourceRisk) []resourceRisk {
+	out := make([]resourceRisk, 0)
+	for _, r := range risks {
+		if r.failed > 0 {
+			out = append(out, r)
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].failed != out[j].failed {
+			return out[i].failed > out[j].failed
+		}
+		return out[i].logicalId < out[j].logicalId
+	})
+
+	return out
+}
+
+// printRisks shows the estimated failure risk of each resource that failed a check
+func printRisks(risks []resourceRisk) {
+	fmt.Println()
+	fmt.Println(console.Yellow("Risk by resource:"))
+	for _, r := range riskiest(risks) {
+		fmt.Printf("  %s\n", r)
+	}
+}
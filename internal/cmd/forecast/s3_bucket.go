@@ -10,6 +10,7 @@ import (
 	"github.com/aws-cloudformation/rain/internal/s11n"
 	fc "github.com/aws-cloudformation/rain/plugins/forecast"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"gopkg.in/yaml.v3"
 )
 
 // An empty bucket cannot be deleted, which will cause a stack DELETE to fail.
@@ -87,5 +88,38 @@ func CheckS3Bucket(input fc.PredictionInput) fc.Forecast {
 		config.Debugf("Stack does not exist, not checking if bucket is empty")
 	}
 
+	if !input.StackExists {
+		checkBucketNameAvailable(input, &forecast)
+	}
+
 	return forecast
 }
+
+// checkBucketNameAvailable makes sure that a hard coded bucket name
+// is not already taken, by this account or any other.
+// Bucket names are global, so a name that is free in this account can still fail.
+func checkBucketNameAvailable(input fc.PredictionInput, forecast *fc.Forecast) {
+	name := input.GetPropertyNode("BucketName")
+	if name == nil || name.Kind != yaml.ScalarNode {
+		// CloudFormation will generate a unique name
+		return
+	}
+
+	spin(input.TypeName, input.LogicalId, "bucket name available?")
+	defer spinner.Pop()
+
+	code := F0022
+	lineNum := getLineNum(input.LogicalId, input.Resource)
+
+	taken, err := s3.BucketNameTaken(name.Value)
+	if err != nil {
+		config.Debugf("Unable to check if bucket name %v is taken: %v", name.Value, err)
+		return
+	}
+
+	if taken {
+		forecast.Add(code, false, fmt.Sprintf("Bucket name %v is already taken", name.Value), lineNum)
+	} else {
+		forecast.Add(code, true, fmt.Sprintf("Bucket name %v is available", name.Value), lineNum)
+	}
+}
@@ -0,0 +1,244 @@
+package generate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+// maxResources is the most resources the IaC generator puts in one template
+const maxResources = 500
+
+var scanId string
+var rescan bool
+var typePrefix string
+var tag string
+var all bool
+var yes bool
+var name string
+var mappingPath string
+var keep bool
+
+// Cmd is the generate command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "generate <template>",
+	Short: "Generate a template from existing resources",
+	Long: `Uses the CloudFormation IaC generator to write a template for resources that already
+exist in the account, so that they can be imported into a stack.
+
+Rain uses the most recent resource scan of the account, or starts a new scan if there
+isn't one or if --rescan is set. The scanned resources are listed so that you can choose
+which of them to include. Filter the list with --type, which matches the start of the
+resource type, and --tag. Resources that already belong to a stack are left out unless
+--all is set.
+
+The template is formatted and written to <template>. An import mapping file is written
+next to it, which lists the identifier of each resource in the template in the format
+that the ResourcesToImport parameter of CreateChangeSet accepts. To import the resources
+into a stack, use rain import with the template, or pass the mapping file to the AWS CLI:
+
+  aws cloudformation create-change-set --change-set-type IMPORT \
+    --resources-to-import file://template.import.json ...
+
+The generator can't read every property. Rain lists the properties it could not read,
+which must be filled in by hand before the template is deployed.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fn := args[0]
+
+		if mappingPath == "" {
+			mappingPath = strings.TrimSuffix(fn, filepath.Ext(fn)) + ".import.json"
+		}
+
+		var tagKey, tagValue string
+		if tag != "" {
+			tagKey, tagValue, _ = strings.Cut(tag, "=")
+		}
+
+		id := resourceScan()
+
+		spinner.Push("Listing scanned resources")
+		resources, err := cfn.ListScannedResources(id, typePrefix, tagKey, tagValue)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to list the resources in scan %s", id))
+		}
+
+		if !all {
+			unmanaged := make([]types.ScannedResource, 0, len(resources))
+			for _, r := range resources {
+				if !ptr.ToBool(r.ManagedByStack) {
+					unmanaged = append(unmanaged, r)
+				}
+			}
+			resources = unmanaged
+		}
+
+		if len(resources) == 0 {
+			fmt.Println("The scan did not find any resources to include")
+			return
+		}
+
+		sortResources(resources)
+
+		selected := selectResources(resources)
+		if len(selected) == 0 {
+			panic(errors.New("no resources were selected"))
+		}
+
+		if len(selected) > maxResources {
+			panic(fmt.Errorf("%d resources were selected; a template can have at most %d", len(selected), maxResources))
+		}
+
+		templateName := name
+		if templateName == "" {
+			templateName = fmt.Sprintf("rain-%d", time.Now().Unix())
+		}
+
+		spinner.Push(fmt.Sprintf("Generating a template of %d resources", len(selected)))
+		generated, err := cfn.CreateGeneratedTemplate(templateName, definitions(selected))
+		if err != nil {
+			spinner.Pop()
+			panic(ui.Errorf(err, "unable to generate template '%s'", templateName))
+		}
+
+		body, err := cfn.GetGeneratedTemplate(templateName)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to get generated template '%s'", templateName))
+		}
+
+		if !keep {
+			if err := cfn.DeleteGeneratedTemplate(templateName); err != nil {
+				console.Warnf("Unable to delete generated template '%s': %v", templateName, err)
+			}
+		}
+
+		t, err := parse.String(body)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse the generated template"))
+		}
+
+		if err := os.WriteFile(fn, []byte(format.String(t, format.Options{})), 0644); err != nil {
+			panic(ui.Errorf(err, "unable to write '%s'", fn))
+		}
+
+		out, err := json.MarshalIndent(mappings(generated.Resources), "", "  ")
+		if err != nil {
+			panic(err)
+		}
+
+		if err := os.WriteFile(mappingPath, append(out, '\n'), 0644); err != nil {
+			panic(ui.Errorf(err, "unable to write '%s'", mappingPath))
+		}
+
+		if w := warnings(generated.Resources); len(w) > 0 {
+			fmt.Println(console.Yellow("Warnings:"))
+			for _, warning := range w {
+				fmt.Printf("  - %s\n", warning)
+			}
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Wrote %s and %s", fn, mappingPath)))
+	},
+}
+
+// resourceScan returns the ID of the scan to generate the template from,
+// starting a new scan if needed
+func resourceScan() string {
+	if scanId != "" {
+		return scanId
+	}
+
+	if !rescan {
+		spinner.Push("Looking for a resource scan")
+		latest, err := cfn.LatestResourceScan()
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to list resource scans"))
+		}
+
+		if latest != nil {
+			fmt.Printf("Using resource scan from %s\n", latest.EndTime.Local().Format(time.RFC1123))
+			return ptr.ToString(latest.ResourceScanId)
+		}
+	}
+
+	id, err := cfn.StartResourceScan()
+	if err != nil {
+		panic(ui.Errorf(err, "unable to start a resource scan"))
+	}
+
+	spinner.Push("Scanning resources")
+	err = cfn.WaitForResourceScan(id, func(percent float64) {
+		spinner.Pop()
+		spinner.Push(fmt.Sprintf("Scanning resources: %.0f%%", percent))
+	})
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "resource scan failed"))
+	}
+
+	return id
+}
+
+// selectResources lists the resources and asks which to include in the template
+func selectResources(resources []types.ScannedResource) []types.ScannedResource {
+	if yes {
+		return resources
+	}
+
+	for i, r := range resources {
+		managed := ""
+		if ptr.ToBool(r.ManagedByStack) {
+			managed = console.Grey(" (in a stack)")
+		}
+
+		fmt.Printf("%4d. %s %s%s\n", i+1, console.Yellow(ptr.ToString(r.ResourceType)),
+			identifierString(r.ResourceIdentifier), managed)
+	}
+
+	for {
+		answer := console.Ask("Which resources should be in the template? (e.g. 1,3,5-8 or all):")
+
+		indexes, err := parseSelection(answer, len(resources))
+		if err != nil {
+			fmt.Println(console.Red(err.Error()))
+			continue
+		}
+
+		selected := make([]types.ScannedResource, len(indexes))
+		for i, index := range indexes {
+			selected[i] = resources[index]
+		}
+
+		return selected
+	}
+}
+
+func init() {
+	Cmd.Flags().StringVar(&scanId, "scan-id", "", "ID of the resource scan to use; by default, the most recent scan is used")
+	Cmd.Flags().BoolVar(&rescan, "rescan", false, "Start a new resource scan instead of using the most recent one")
+	Cmd.Flags().StringVar(&typePrefix, "type", "", "Only list resources whose type starts with this, e.g. AWS::S3::")
+	Cmd.Flags().StringVar(&tag, "tag", "", "Only list resources with this tag, as key or key=value")
+	Cmd.Flags().BoolVarP(&all, "all", "a", false, "Include resources that already belong to a stack")
+	Cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't ask questions; include every resource that is listed")
+	Cmd.Flags().StringVar(&name, "name", "", "Name of the generated template in CloudFormation")
+	Cmd.Flags().StringVar(&mappingPath, "mapping", "", "Path of the import mapping file; by default, the template's path with .import.json")
+	Cmd.Flags().BoolVar(&keep, "keep", false, "Keep the generated template in CloudFormation after it is downloaded")
+}
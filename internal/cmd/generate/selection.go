@@ -0,0 +1,157 @@
+package generate
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// identifierString renders a resource identifier as key=value pairs in a stable order
+func identifierString(id map[string]string) string {
+	keys := make([]string, 0, len(id))
+	for k := range id {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf("%s=%s", k, id[k])
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+// sortResources sorts scanned resources by type and then by identifier
+func sortResources(resources []types.ScannedResource) {
+	sort.SliceStable(resources, func(i, j int) bool {
+		ti, tj := ptr.ToString(resources[i].ResourceType), ptr.ToString(resources[j].ResourceType)
+		if ti != tj {
+			return ti < tj
+		}
+		return identifierString(resources[i].ResourceIdentifier) < identifierString(resources[j].ResourceIdentifier)
+	})
+}
+
+// parseSelection parses a selection of items from a numbered list of n items,
+// such as "1,3,5-7" or "all", and returns the zero-based indexes of the selected items
+func parseSelection(s string, n int) ([]int, error) {
+	s = strings.TrimSpace(s)
+
+	if strings.EqualFold(s, "all") {
+		indexes := make([]int, n)
+		for i := range indexes {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	seen := make(map[int]bool)
+	indexes := make([]int, 0)
+
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		from, to, isRange := strings.Cut(part, "-")
+
+		start, err := strconv.Atoi(strings.TrimSpace(from))
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a number or a range", part)
+		}
+
+		end := start
+		if isRange {
+			end, err = strconv.Atoi(strings.TrimSpace(to))
+			if err != nil {
+				return nil, fmt.Errorf("'%s' is not a number or a range", part)
+			}
+		}
+
+		if start < 1 || end > n || start > end {
+			return nil, fmt.Errorf("'%s' is not between 1 and %d", part, n)
+		}
+
+		for i := start; i <= end; i++ {
+			if !seen[i] {
+				seen[i] = true
+				indexes = append(indexes, i-1)
+			}
+		}
+	}
+
+	sort.Ints(indexes)
+
+	return indexes, nil
+}
+
+// definitions returns the resource definitions for the IaC generator
+func definitions(resources []types.ScannedResource) []types.ResourceDefinition {
+	defs := make([]types.ResourceDefinition, len(resources))
+
+	for i, r := range resources {
+		defs[i] = types.ResourceDefinition{
+			ResourceType:       r.ResourceType,
+			ResourceIdentifier: r.ResourceIdentifier,
+		}
+	}
+
+	return defs
+}
+
+// importMapping is an entry in the import mapping file, in the format accepted by
+// the ResourcesToImport parameter of CreateChangeSet
+type importMapping struct {
+	ResourceType       string            `json:"ResourceType"`
+	LogicalResourceId  string            `json:"LogicalResourceId"`
+	ResourceIdentifier map[string]string `json:"ResourceIdentifier"`
+}
+
+// mappings returns the import mappings for the resources in the generated template
+func mappings(resources []types.ResourceDetail) []importMapping {
+	out := make([]importMapping, len(resources))
+
+	for i, r := range resources {
+		out[i] = importMapping{
+			ResourceType:       ptr.ToString(r.ResourceType),
+			LogicalResourceId:  ptr.ToString(r.LogicalResourceId),
+			ResourceIdentifier: r.ResourceIdentifier,
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].LogicalResourceId < out[j].LogicalResourceId
+	})
+
+	return out
+}
+
+// warnings returns a description of each warning the IaC generator gave about the resources,
+// such as properties that it could not read and must be filled in by hand
+func warnings(resources []types.ResourceDetail) []string {
+	out := make([]string, 0)
+
+	for _, r := range resources {
+		id := ptr.ToString(r.LogicalResourceId)
+
+		for _, w := range r.Warnings {
+			if len(w.Properties) == 0 {
+				out = append(out, fmt.Sprintf("%s: %s", id, w.Type))
+				continue
+			}
+
+			for _, p := range w.Properties {
+				out = append(out, fmt.Sprintf("%s: %s %s: %s", id, w.Type,
+					ptr.ToString(p.PropertyPath), ptr.ToString(p.Description)))
+			}
+		}
+	}
+
+	return out
+}
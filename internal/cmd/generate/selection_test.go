@@ -0,0 +1,89 @@
+package generate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestParseSelection(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected []int
+	}{
+		{"1", []int{0}},
+		{"3, 1", []int{0, 2}},
+		{"2-4,3", []int{1, 2, 3}},
+		{"all", []int{0, 1, 2, 3, 4}},
+		{"", []int{}},
+	}
+
+	for _, c := range cases {
+		actual, err := parseSelection(c.input, 5)
+		if err != nil {
+			t.Errorf("%q: %v", c.input, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(actual, c.expected) {
+			t.Errorf("%q: expected %v, got %v", c.input, c.expected, actual)
+		}
+	}
+
+	for _, input := range []string{"0", "6", "4-2", "x", "1-y"} {
+		if _, err := parseSelection(input, 5); err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestSortResources(t *testing.T) {
+	resources := []types.ScannedResource{
+		{ResourceType: ptr.String("AWS::SQS::Queue"), ResourceIdentifier: map[string]string{"QueueUrl": "b"}},
+		{ResourceType: ptr.String("AWS::S3::Bucket"), ResourceIdentifier: map[string]string{"BucketName": "z"}},
+		{ResourceType: ptr.String("AWS::S3::Bucket"), ResourceIdentifier: map[string]string{"BucketName": "a"}},
+	}
+
+	sortResources(resources)
+
+	if resources[0].ResourceIdentifier["BucketName"] != "a" ||
+		resources[1].ResourceIdentifier["BucketName"] != "z" ||
+		*resources[2].ResourceType != "AWS::SQS::Queue" {
+		t.Errorf("unexpected order: %v", resources)
+	}
+}
+
+func TestMappings(t *testing.T) {
+	resources := []types.ResourceDetail{
+		{
+			LogicalResourceId:  ptr.String("Queue"),
+			ResourceType:       ptr.String("AWS::SQS::Queue"),
+			ResourceIdentifier: map[string]string{"QueueUrl": "https://sqs"},
+		},
+		{
+			LogicalResourceId:  ptr.String("Bucket"),
+			ResourceType:       ptr.String("AWS::S3::Bucket"),
+			ResourceIdentifier: map[string]string{"BucketName": "my-bucket"},
+			Warnings: []types.WarningDetail{
+				{
+					Type: types.WarningTypeUnsupportedProperties,
+					Properties: []types.WarningProperty{
+						{PropertyPath: ptr.String("/Secret"), Description: ptr.String("write only")},
+					},
+				},
+			},
+		},
+	}
+
+	m := mappings(resources)
+	if len(m) != 2 || m[0].LogicalResourceId != "Bucket" || m[0].ResourceIdentifier["BucketName"] != "my-bucket" {
+		t.Errorf("unexpected mappings: %v", m)
+	}
+
+	w := warnings(resources)
+	if len(w) != 1 || w[0] != "Bucket: UNSUPPORTED_PROPERTIES /Secret: write only" {
+		t.Errorf("unexpected warnings: %v", w)
+	}
+}
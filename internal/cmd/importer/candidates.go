@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"gopkg.in/yaml.v3"
+)
+
+// candidate is a resource in the template that is not yet in the stack
+type candidate struct {
+	LogicalId string
+	Type      string
+
+	// Properties are the resource's properties that have literal values,
+	// which are used as the default values of its identifier
+	Properties map[string]string
+}
+
+// skipped is a resource in the template that is not in the stack and can't be imported
+type skipped struct {
+	LogicalId string
+	Reason    string
+}
+
+func (s skipped) String() string {
+	return fmt.Sprintf("%s: %s", s.LogicalId, s.Reason)
+}
+
+// scalarProperties returns the properties of the resource that are set to literal values
+func scalarProperties(resource *yaml.Node) map[string]string {
+	props := make(map[string]string)
+
+	_, p, _ := s11n.GetMapValue(resource, "Properties")
+	if p == nil || p.Kind != yaml.MappingNode {
+		return props
+	}
+
+	for i := 0; i+1 < len(p.Content); i += 2 {
+		if v := p.Content[i+1]; v.Kind == yaml.ScalarNode && (v.Tag == "" || strings.HasPrefix(v.Tag, "!!")) {
+			props[p.Content[i].Value] = v.Value
+		}
+	}
+
+	return props
+}
+
+// findCandidates returns the resources in the template that are not in the stack.
+// Only resources with a Retain deletion policy are candidates for import,
+// so that removing one from the stack later can't delete it;
+// the others are returned as skipped.
+func findCandidates(t cft.Template, existing map[string]bool) ([]candidate, []skipped) {
+	candidates := make([]candidate, 0)
+	skips := make([]skipped, 0)
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil || resources == nil {
+		return candidates, skips
+	}
+
+	for i := 0; i+1 < len(resources.Content); i += 2 {
+		id := resources.Content[i].Value
+		resource := resources.Content[i+1]
+
+		if existing[id] {
+			continue
+		}
+
+		_, typeNode, _ := s11n.GetMapValue(resource, "Type")
+		if typeNode == nil {
+			skips = append(skips, skipped{id, "it has no Type"})
+			continue
+		}
+
+		_, policy, _ := s11n.GetMapValue(resource, "DeletionPolicy")
+		if policy == nil || policy.Value != "Retain" {
+			skips = append(skips, skipped{id, "its DeletionPolicy is not Retain"})
+			continue
+		}
+
+		candidates = append(candidates, candidate{
+			LogicalId:  id,
+			Type:       typeNode.Value,
+			Properties: scalarProperties(resource),
+		})
+	}
+
+	return candidates, skips
+}
+
+// resourcesToImport makes the ResourcesToImport payload for the identified candidates.
+// identifiers maps logical IDs to the values of the resource's identifier properties.
+// Candidates without identifiers are left out.
+func resourcesToImport(candidates []candidate, identifiers map[string]map[string]string) []types.ResourceToImport {
+	imports := make([]types.ResourceToImport, 0)
+
+	for _, c := range candidates {
+		id, ok := identifiers[c.LogicalId]
+		if !ok {
+			continue
+		}
+
+		imports = append(imports, types.ResourceToImport{
+			LogicalResourceId:  ptr.String(c.LogicalId),
+			ResourceType:       ptr.String(c.Type),
+			ResourceIdentifier: id,
+		})
+	}
+
+	sort.Slice(imports, func(i, j int) bool {
+		return *imports[i].LogicalResourceId < *imports[j].LogicalResourceId
+	})
+
+	return imports
+}
@@ -0,0 +1,87 @@
+package importer
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+const template = `
+Parameters:
+  Name:
+    Type: String
+Resources:
+  Existing:
+    Type: AWS::S3::Bucket
+    DeletionPolicy: Retain
+  Logs:
+    Type: AWS::S3::Bucket
+    DeletionPolicy: Retain
+    Properties:
+      BucketName: my-logs
+      Tags:
+        - Key: team
+          Value: data
+  Table:
+    Type: AWS::DynamoDB::Table
+    DeletionPolicy: Retain
+    Properties:
+      TableName: !Ref Name
+  Queue:
+    Type: AWS::SQS::Queue
+`
+
+func TestFindCandidates(t *testing.T) {
+	tmpl, err := parse.String(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, skips := findCandidates(tmpl, map[string]bool{"Existing": true})
+
+	if len(candidates) != 2 {
+		t.Fatalf("expected 2 candidates: %v", candidates)
+	}
+
+	logs := candidates[0]
+	if logs.LogicalId != "Logs" || logs.Type != "AWS::S3::Bucket" {
+		t.Errorf("unexpected candidate: %v", logs)
+	}
+
+	if len(logs.Properties) != 1 || logs.Properties["BucketName"] != "my-logs" {
+		t.Errorf("expected only the literal BucketName: %v", logs.Properties)
+	}
+
+	if table := candidates[1]; table.LogicalId != "Table" || len(table.Properties) != 0 {
+		t.Errorf("expected the Ref to be left out: %v", table)
+	}
+
+	if len(skips) != 1 || skips[0].LogicalId != "Queue" {
+		t.Errorf("expected Queue to be skipped: %v", skips)
+	}
+}
+
+func TestResourcesToImport(t *testing.T) {
+	candidates := []candidate{
+		{LogicalId: "Table", Type: "AWS::DynamoDB::Table"},
+		{LogicalId: "Logs", Type: "AWS::S3::Bucket"},
+		{LogicalId: "Queue", Type: "AWS::SQS::Queue"},
+	}
+
+	imports := resourcesToImport(candidates, map[string]map[string]string{
+		"Table": {"TableName": "orders"},
+		"Logs":  {"BucketName": "my-logs"},
+	})
+
+	if len(imports) != 2 {
+		t.Fatalf("expected 2 imports: %v", imports)
+	}
+
+	if *imports[0].LogicalResourceId != "Logs" || imports[0].ResourceIdentifier["BucketName"] != "my-logs" {
+		t.Errorf("unexpected import: %v", imports[0])
+	}
+
+	if *imports[1].ResourceType != "AWS::DynamoDB::Table" {
+		t.Errorf("unexpected import: %v", imports[1])
+	}
+}
@@ -0,0 +1,197 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var yes bool
+var dryRun bool
+
+// Cmd is the import command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "import <stack> <template>",
+	Short: "Import existing resources into a stack",
+	Long: `Guides you through importing resources that already exist into a stack,
+using the resources that are in the template but not yet in the stack.
+
+Only resources with a Retain deletion policy are imported. For each of them, rain asks
+for the values of the resource type's primary identifier, such as a bucket name.
+Values that are set in the template are offered as defaults; leave a value empty
+to skip the resource. Rain then creates an import change set and executes it.
+
+The rest of the template must match what is already deployed to the stack,
+since an import can't change other resources. If the stack does not exist,
+it is created with the imported resources.
+
+Use --dry-run to see the resources that would be imported without changing the stack.
+With --yes, rain does not ask any questions and only imports the resources whose
+identifiers are all set in the template.
+
+To write a template block for a resource that is not in the template yet, use rain adopt.`,
+	Args:                  cobra.ExactArgs(2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		stackName, fn := args[0], args[1]
+
+		t, err := parse.File(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		spinner.Push(fmt.Sprintf("Checking current status of stack '%s'", stackName))
+		existing, err := stackResources(stackName)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to get the resources in stack '%s'", stackName))
+		}
+
+		candidates, skips := findCandidates(t, existing)
+
+		for _, s := range skips {
+			fmt.Println(console.Yellow(fmt.Sprintf("Not importing %s", s)))
+		}
+
+		if len(candidates) == 0 {
+			fmt.Println("There are no resources with a Retain deletion policy to import")
+			return
+		}
+
+		identifiers := make(map[string]map[string]string)
+		for _, c := range candidates {
+			id, err := identify(c)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to identify %s", c.LogicalId))
+			}
+
+			if id == nil {
+				fmt.Println(console.Grey(fmt.Sprintf("Skipping %s", c.LogicalId)))
+				continue
+			}
+
+			identifiers[c.LogicalId] = id
+		}
+
+		imports := resourcesToImport(candidates, identifiers)
+		if len(imports) == 0 {
+			fmt.Println("No resources were identified, so there is nothing to import")
+			return
+		}
+
+		out, err := json.MarshalIndent(imports, "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(console.Yellow("Resources to import:"))
+		fmt.Println(string(out))
+
+		if dryRun {
+			return
+		}
+
+		if !yes && !console.Confirm(true, fmt.Sprintf("Import %d resources into stack %s?", len(imports), stackName)) {
+			panic(errors.New("user cancelled import"))
+		}
+
+		spinner.Push("Creating import change set")
+		changeSetName, err := cfn.CreateImportChangeSet(t, stackName, imports)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to create import change set"))
+		}
+
+		if err := cfn.ExecuteChangeSet(stackName, changeSetName, false); err != nil {
+			panic(ui.Errorf(err, "unable to execute import change set '%s'", changeSetName))
+		}
+
+		status, messages := cfn.WaitForStackToSettle(stackName)
+
+		stack, err := cfn.GetStack(stackName)
+		if err == nil {
+			output, _ := cfn.GetStackOutput(stack)
+			fmt.Println(output)
+		}
+
+		if status != "IMPORT_COMPLETE" {
+			for _, message := range messages {
+				fmt.Println(console.Red(message))
+			}
+			panic(fmt.Errorf("import into stack '%s' failed: %s", stackName, status))
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Imported %d resources into %s", len(imports), stackName)))
+	},
+}
+
+// stackResources returns the logical IDs of the resources in the stack,
+// which is empty if the stack does not exist
+func stackResources(stackName string) (map[string]bool, error) {
+	existing := make(map[string]bool)
+
+	exists, err := cfn.StackExists(stackName)
+	if err != nil || !exists {
+		return existing, err
+	}
+
+	resources, err := cfn.GetStackResources(stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range resources {
+		existing[ptr.ToString(r.LogicalResourceId)] = true
+	}
+
+	return existing, nil
+}
+
+// identify asks for the values of the candidate's primary identifier.
+// It returns nil if the resource should be skipped.
+func identify(c candidate) (map[string]string, error) {
+	properties, err := cfn.GetTypeIdentifier(c.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	if !yes {
+		fmt.Println(console.Blue(fmt.Sprintf("%s (%s)", c.LogicalId, c.Type)))
+	}
+
+	id := make(map[string]string)
+	for _, p := range properties {
+		value := c.Properties[p]
+
+		if !yes {
+			prompt := fmt.Sprintf("  %s", p)
+			if value != "" {
+				prompt += fmt.Sprintf(" [%s]", value)
+			}
+
+			if answer := console.Ask(prompt + ":"); answer != "" {
+				value = answer
+			}
+		}
+
+		if value == "" {
+			return nil, nil
+		}
+
+		id[p] = value
+	}
+
+	return id, nil
+}
+
+func init() {
+	Cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't ask questions; import the resources whose identifiers are set in the template")
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the resources to import without changing the stack")
+}
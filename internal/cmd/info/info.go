@@ -1,7 +1,6 @@
 package info
 
 import (
-	"context"
 	"fmt"
 	"os"
 
@@ -10,6 +9,8 @@ import (
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/aws-cloudformation/rain/internal/ui"
 
 	"github.com/spf13/cobra"
@@ -31,20 +32,39 @@ var Cmd = &cobra.Command{
 		}
 		spinner.Pop()
 
+		profile := config.Profile
+		if profile == "" {
+			profile = os.Getenv("AWS_PROFILE")
+		}
+
+		data := map[string]any{
+			"account":  *id.Account,
+			"region":   aws.Config().Region,
+			"identity": *id.Arn,
+		}
+		if profile != "" {
+			data["profile"] = profile
+		}
+
 		fmt.Println("Account: ", console.Yellow(*id.Account))
 		fmt.Println("Region:  ", console.Yellow(aws.Config().Region))
 		fmt.Println("Identity:", console.Yellow(*id.Arn))
 
-		if config.Profile != "" {
-			fmt.Println("Profile: ", console.Yellow(config.Profile))
-		} else if profile, ok := os.LookupEnv("AWS_PROFILE"); ok {
+		if profile != "" {
 			fmt.Println("Profile: ", console.Yellow(profile))
 		}
 
 		if checkCreds {
 			fmt.Println()
-			c, err := aws.Config().Credentials.Retrieve(context.Background())
+			c, err := aws.Config().Credentials.Retrieve(interrupt.Context())
 			if err == nil {
+				// The secret parts of the credentials are left out of events
+				creds := map[string]any{"source": c.Source, "accessKeyId": c.AccessKeyID}
+				if !c.Expires.IsZero() {
+					creds["expires"] = c.Expires
+				}
+				data["credentials"] = creds
+
 				fmt.Println("Credentials:")
 				fmt.Println("  Source:         ", console.Yellow(c.Source))
 				fmt.Println("  AccessKeyId:    ", console.Yellow(c.AccessKeyID))
@@ -57,6 +77,8 @@ var Cmd = &cobra.Command{
 				}
 			}
 		}
+
+		emit.Event("info", data)
 	},
 }
 
@@ -0,0 +1,309 @@
+package lint
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/lint"
+	"github.com/aws-cloudformation/rain/internal/ci"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws-cloudformation/rain/sdk"
+	"github.com/spf13/cobra"
+)
+
+var jsonFlag bool
+var manifestPath string
+var stackName string
+var listRules bool
+var rulePaths []string
+var skipSchemas bool
+var transform bool
+var expandForEach bool
+var params []string
+var external []string
+var sarifFlag bool
+
+// Cmd is the lint command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "lint <template>",
+	Short: "Check a CloudFormation template against project conventions",
+	Long: `Checks a template against rain's lint rules and reports any problems it finds.
+
+Some rules are configured by the manifest file (rain.yaml) that is used by "rain deploy --manifest".
+If --manifest is not set, rain looks for rain.yaml in the current directory.
+If the template is listed in the manifest, the stack name from the manifest is used.
+
+Use --list-rules to see all of the rules.
+
+Resource properties are checked against the types' registry schemas. Schemas for private and third-party types
+are read from the registry in the account and region that --profile and --region select, and are cached;
+use --offline to only use cached schemas, or --skip-schemas to skip the check.
+
+Use --transform to lint the resources that CloudFormation will actually create for templates
+that declare transforms, such as AWS::Serverless-2016-10-31. The transforms are run by creating
+a change set for a temporary stack, which is deleted afterwards; use --params to set any
+parameters that don't have defaults. Suppression comments don't apply to transformed templates.
+
+Use --expand-foreach to lint the resources that Fn::ForEach loops create without calling CloudFormation.
+Loops over parameters use --params, or the parameters' defaults.
+
+The conditions rule tries every combination of the AllowedValues of the parameters that conditions use,
+and of the values that they are compared with, to find conditions that are always true or always false,
+and the resources and outputs that depend on conditions that can never be true.
+
+Custom rules are loaded with --rules, or from the LintRules section of the manifest.
+They run alongside the built-in rules and are reported the same way.
+A rule file is YAML that matches resources by type and asserts on their properties:
+
+  Rules:
+    - Name: bucket-names
+      Description: Bucket names start with the team prefix
+      Severity: error
+      Match:
+        Type: AWS::S3::Bucket
+      Assert:
+        - Path: Properties/BucketName
+          Pattern: ^acme-
+        - Path: Properties/BucketEncryption
+          Exists: true
+
+Assertions can also use Equals and OneOf, and Match can have a Where list of assertions
+that limits the rule to some resources. A file ending in .so is loaded as a Go plugin,
+built with "go build -buildmode=plugin", that exports a variable Rules of type []lint.Rule.
+
+Use --external to run other linters and report their findings with rain's, in one list sorted
+by severity and resource. The linters must be installed and on the PATH:
+
+` + externalHelp() + `
+
+Their rules are named after the linter, such as cfn-lint/E3012, and can be suppressed in the same way
+as rain's own rules. Use --json or --sarif to write the combined findings as JSON or as a SARIF log.
+
+To accept a finding, add a comment on the line before it with the rules to suppress and the reason:
+
+  # rain-disable-next-line cidr reason=the range is shared with another VPC
+
+or list the rules under Rain::Suppressions in the resource's Metadata, or the template's Metadata
+to accept them for every resource. These suppressions are shared with rain scan, must have a reason,
+and can expire:
+
+  Metadata:
+    Rain::Suppressions:
+      - Rule: cidr
+        Reason: the range is shared with another VPC
+        Expires: 2025-06-30
+
+Suppressed findings are listed in a summary and do not cause the command to fail.
+
+In GitHub Actions, the findings are also written as annotations on the template's lines.
+In GitLab CI, they are added to a code quality report, gl-code-quality-report.json,
+or the file that RAIN_CODE_QUALITY_REPORT names.
+
+The command exits with a non-zero status if any errors are found.`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if listRules {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		for _, p := range append(manifestRules(), rulePaths...) {
+			if err := lint.LoadRules(p); err != nil {
+				panic(ui.Errorf(err, "unable to load custom lint rules"))
+			}
+		}
+
+		if listRules {
+			for _, rule := range lint.Rules {
+				fmt.Printf("%s: %s\n", console.Yellow(rule.Name), rule.Description)
+			}
+			return
+		}
+
+		fn := args[0]
+
+		if transform {
+			spinner.Push(fmt.Sprintf("Transforming %s", fn))
+		} else if len(external) > 0 {
+			spinner.Push(fmt.Sprintf("Running %s", strings.Join(external, ", ")))
+		}
+		findings, err := linter(fn).LintFile(context.Background(), fn)
+		if transform || len(external) > 0 {
+			spinner.Pop()
+		}
+		if err != nil {
+			panic(ui.Errorf(err, "unable to lint '%s'", fn))
+		}
+
+		emit.Event("findings", map[string]any{"file": fn, "findings": findings})
+
+		if jsonFlag {
+			out, err := json.MarshalIndent(findings, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else if sarifFlag {
+			out, err := lint.SARIF(fn, findings)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else {
+			printFindings(fn, findings)
+		}
+
+		if err := ci.Annotate(os.Stderr, fn, findings); err != nil {
+			console.Warnf("%s", err)
+		}
+
+		if lint.HasErrors(findings) {
+			exitcode.Exit(exitcode.Invalid)
+		}
+	},
+}
+
+// loadManifest loads the manifest, or returns nil if there isn't one
+func loadManifest() *manifest.Manifest {
+	path := manifestPath
+	if path == "" {
+		if _, err := os.Stat(manifest.DefaultFileName); err != nil {
+			return nil
+		}
+		path = manifest.DefaultFileName
+	}
+
+	m, err := manifest.Load(path)
+	if err != nil {
+		panic(ui.Errorf(err, "unable to load manifest"))
+	}
+
+	return m
+}
+
+// manifestRules returns the paths of the custom rules in the manifest, if there is one
+func manifestRules() []string {
+	paths := make([]string, 0)
+
+	m := loadManifest()
+	if m == nil {
+		return paths
+	}
+
+	for _, p := range m.LintRules {
+		paths = append(paths, m.Path(p))
+	}
+
+	return paths
+}
+
+// linter builds a linter from the flags and the manifest, if there is one
+func linter(fn string) sdk.Linter {
+	l := sdk.Linter{
+		StackName:     stackName,
+		Schemas:       !skipSchemas,
+		ExpandForEach: expandForEach,
+		Transform:     transform,
+		Params:        dc.ListToMap("param", params),
+		External:      external,
+	}
+
+	m := loadManifest()
+	if m == nil {
+		return l
+	}
+
+	l.Exports = m.Exports
+
+	if l.StackName == "" {
+		abs, _ := filepath.Abs(fn)
+		for _, s := range m.Stacks {
+			if other, _ := filepath.Abs(m.Path(s.Template)); other == abs {
+				l.StackName = s.Name
+				break
+			}
+		}
+	}
+
+	return l
+}
+
+func printFindings(fn string, findings []lint.Finding) {
+	active := make([]lint.Finding, 0)
+	suppressed := make([]lint.Finding, 0)
+	for _, f := range findings {
+		if f.Suppressed {
+			suppressed = append(suppressed, f)
+		} else {
+			active = append(active, f)
+		}
+	}
+
+	if len(active) == 0 {
+		fmt.Printf("%s: %s\n", fn, console.Green("no problems found"))
+	} else {
+		fmt.Printf("%s:\n", fn)
+	}
+
+	for _, f := range active {
+		var severity string
+		switch f.Severity {
+		case lint.Error:
+			severity = console.Red(string(f.Severity))
+		case lint.Warning:
+			severity = console.Yellow(string(f.Severity))
+		default:
+			severity = console.Grey(string(f.Severity))
+		}
+
+		fmt.Printf("  %s %s\n", severity, f)
+	}
+
+	if len(suppressed) == 0 {
+		return
+	}
+
+	fmt.Printf("%s\n", console.Grey(fmt.Sprintf("Suppressed %d findings:", len(suppressed))))
+	for _, f := range suppressed {
+		reason := f.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+		fmt.Printf("  %s %s %s\n", console.Grey(string(f.Severity)), f, console.Grey("("+reason+")"))
+	}
+}
+
+// externalHelp lists the external linters for the command's help
+func externalHelp() string {
+	lines := make([]string, 0, len(lint.ExternalLinters))
+	for _, l := range lint.ExternalLinters {
+		lines = append(lines, fmt.Sprintf("  %-10s %s (runs %s)", l.Name, l.Description, l.Binary))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func init() {
+	Cmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output the findings as JSON")
+	Cmd.Flags().StringVar(&manifestPath, "manifest", "", "Manifest file to read configuration from (default rain.yaml if it exists)")
+	Cmd.Flags().StringVarP(&stackName, "stack-name", "s", "", "Name of the stack the template will be deployed as")
+	Cmd.Flags().BoolVar(&listRules, "list-rules", false, "List the lint rules and exit")
+	Cmd.Flags().BoolVarP(&transform, "transform", "t", false, "Lint the template after running its transforms, such as AWS::Serverless-2016-10-31")
+	Cmd.Flags().StringSliceVar(&params, "params", []string{}, "Parameter values for --transform and --expand-foreach; use the format key1=value1,key2=value2")
+	Cmd.Flags().BoolVar(&expandForEach, "expand-foreach", false, "Expand Fn::ForEach loops locally before linting")
+	Cmd.Flags().BoolVar(&skipSchemas, "skip-schemas", false, "Don't check resource properties against registry schemas")
+	Cmd.Flags().StringSliceVar(&external, "external", []string{}, "External linters to run alongside rain's rules: cfn-lint, cfn_nag or checkov")
+	Cmd.Flags().BoolVar(&sarifFlag, "sarif", false, "Output the findings as a SARIF log")
+	Cmd.MarkFlagsMutuallyExclusive("json", "sarif")
+	Cmd.Flags().StringSliceVar(&rulePaths, "rules", []string{}, "File or directory of custom rules to load (can be repeated)")
+}
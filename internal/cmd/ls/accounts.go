@@ -0,0 +1,96 @@
+package ls
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/organizations"
+	"github.com/aws-cloudformation/rain/internal/aws/partition"
+	"github.com/aws-cloudformation/rain/internal/aws/sts"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+)
+
+var allAccounts bool
+var accountList []string
+var roleName string
+var concurrency int
+
+// defaultRoleName is the role that AWS Organizations creates in new member accounts
+const defaultRoleName = "OrganizationAccountAccessRole"
+
+// target is an account and region whose stacks are listed
+type target struct {
+	// account is empty when only the current account is listed
+	account     string
+	accountName string
+	region      string
+	cfg         awssdk.Config
+}
+
+// multiAccount returns true if --all-accounts or --accounts is set
+func multiAccount() bool {
+	return allAccounts || len(accountList) > 0
+}
+
+// targets returns the accounts and regions to list stacks in.
+// Other accounts are listed by assuming --role in them;
+// the current account is listed with the current credentials.
+func targets(regions []string) []target {
+	if !multiAccount() {
+		out := make([]target, 0, len(regions))
+		for _, region := range regions {
+			out = append(out, target{region: region, cfg: aws.Config()})
+		}
+		return out
+	}
+
+	var accounts []organizations.Account
+	if allAccounts {
+		var err error
+		spinner.Push("Fetching the accounts in the organization")
+		accounts, err = organizations.ListAccounts()
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to list the accounts in the organization"))
+		}
+	} else {
+		for _, id := range accountList {
+			accounts = append(accounts, organizations.Account{Id: strings.TrimSpace(id)})
+		}
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Id < accounts[j].Id
+	})
+
+	spinner.Push("Checking the current account")
+	current, err := sts.GetAccountID()
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "unable to get the current account"))
+	}
+
+	p := partition.ForRegion(aws.Config().Region)
+
+	out := make([]target, 0, len(accounts)*len(regions))
+	for _, account := range accounts {
+		cfg := aws.Config()
+		if account.Id != current {
+			cfg = aws.AssumeRole(p.Arn("iam", "", account.Id, "role/"+roleName))
+		}
+
+		for _, region := range regions {
+			out = append(out, target{
+				account:     account.Id,
+				accountName: account.Name,
+				region:      region,
+				cfg:         cfg,
+			})
+		}
+	}
+
+	return out
+}
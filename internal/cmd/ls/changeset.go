@@ -76,7 +76,7 @@ func showChangeset(stackName, changeSetName string) {
 		if p.ParameterValue != nil {
 			v = *p.ParameterValue
 		}
-		out += fmt.Sprintf("  %s: %s\n", k, v)
+		out += fmt.Sprintf("  %s: %s\n", k, config.Mask(v))
 	}
 	out += "Changes: \n"
 	for _, csch := range cs.Changes {
@@ -0,0 +1,106 @@
+package ls
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// stackFilter selects stacks by name, status and tags
+type stackFilter struct {
+	// name is a pattern the stack name must match
+	name string
+
+	// statuses are patterns, one of which the stack status must match
+	statuses []string
+
+	// tags maps tag keys the stack must have to patterns their values must match
+	tags map[string]string
+}
+
+// newStackFilter checks the patterns from the command line.
+// Statuses are compared without regard to case, and a tag without a value
+// only needs to be set on the stack.
+func newStackFilter(name string, statuses []string, tags []string) (stackFilter, error) {
+	f := stackFilter{
+		name:     name,
+		statuses: make([]string, 0, len(statuses)),
+		tags:     make(map[string]string),
+	}
+
+	if _, err := path.Match(name, ""); err != nil {
+		return f, fmt.Errorf("invalid name pattern '%s': %w", name, err)
+	}
+
+	for _, s := range statuses {
+		if _, err := path.Match(s, ""); err != nil {
+			return f, fmt.Errorf("invalid status pattern '%s': %w", s, err)
+		}
+		f.statuses = append(f.statuses, strings.ToUpper(s))
+	}
+
+	for _, t := range tags {
+		key, value, found := strings.Cut(t, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return f, fmt.Errorf("invalid tag filter '%s'", t)
+		}
+
+		if !found {
+			value = "*"
+		}
+
+		if _, err := path.Match(value, ""); err != nil {
+			return f, fmt.Errorf("invalid tag pattern '%s': %w", t, err)
+		}
+
+		f.tags[key] = value
+	}
+
+	return f, nil
+}
+
+// matches returns true if the stack meets every part of the filter
+func (f stackFilter) matches(stack types.Stack) bool {
+	if f.name != "" {
+		if ok, _ := path.Match(f.name, ptr.ToString(stack.StackName)); !ok {
+			return false
+		}
+	}
+
+	if len(f.statuses) > 0 {
+		matched := false
+		for _, s := range f.statuses {
+			if ok, _ := path.Match(s, string(stack.StackStatus)); ok {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	for key, pattern := range f.tags {
+		matched := false
+		for _, tag := range stack.Tags {
+			if ptr.ToString(tag.Key) != key {
+				continue
+			}
+
+			if ok, _ := path.Match(pattern, ptr.ToString(tag.Value)); ok {
+				matched = true
+			}
+		}
+
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,152 @@
+package ls
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/google/go-cmp/cmp"
+)
+
+func testStack(name string, status types.StackStatus, tags map[string]string) types.Stack {
+	stack := types.Stack{
+		StackName:   ptr.String(name),
+		StackId:     ptr.String("arn:aws:cloudformation:us-east-1:123456789012:stack/" + name + "/1"),
+		StackStatus: status,
+	}
+
+	for k, v := range tags {
+		stack.Tags = append(stack.Tags, types.Tag{Key: ptr.String(k), Value: ptr.String(v)})
+	}
+
+	return stack
+}
+
+func TestStackFilter(t *testing.T) {
+	prod := testStack("prod-api", types.StackStatusUpdateRollbackFailed, map[string]string{"Team": "payments"})
+	dev := testStack("dev-api", types.StackStatusCreateComplete, map[string]string{"Team": "search"})
+	untagged := testStack("prod-db", types.StackStatusCreateComplete, nil)
+
+	cases := []struct {
+		name     string
+		statuses []string
+		tags     []string
+		expected []bool
+	}{
+		{"", nil, nil, []bool{true, true, true}},
+		{"prod-*", nil, nil, []bool{true, false, true}},
+		{"", []string{"*failed"}, nil, []bool{true, false, false}},
+		{"", []string{"CREATE_COMPLETE", "UPDATE_*"}, nil, []bool{true, true, true}},
+		{"", nil, []string{"Team"}, []bool{true, true, false}},
+		{"", nil, []string{"Team=pay*"}, []bool{true, false, false}},
+		{"*-api", nil, []string{"Team=search"}, []bool{false, true, false}},
+	}
+
+	for _, c := range cases {
+		f, err := newStackFilter(c.name, c.statuses, c.tags)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		actual := []bool{f.matches(prod), f.matches(dev), f.matches(untagged)}
+		if d := cmp.Diff(c.expected, actual); d != "" {
+			t.Errorf("name %q statuses %v tags %v: %s", c.name, c.statuses, c.tags, d)
+		}
+	}
+
+	if _, err := newStackFilter("[", nil, nil); err == nil {
+		t.Error("expected an error for an invalid name pattern")
+	}
+
+	if _, err := newStackFilter("", nil, []string{"=value"}); err == nil {
+		t.Error("expected an error for a tag filter without a key")
+	}
+}
+
+func TestWriteStacks(t *testing.T) {
+	updated := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	parent := testStack("app", types.StackStatusUpdateComplete, map[string]string{"Team": "payments", "Env": "prod"})
+	parent.LastUpdatedTime = &updated
+	parent.DriftInformation = &types.StackDriftInformation{StackDriftStatus: types.StackDriftStatusDrifted}
+
+	child := testStack("app-network", types.StackStatusCreateComplete, nil)
+	child.CreationTime = &updated
+	child.ParentId = parent.StackId
+
+	results := []regionStacks{{region: "us-east-1", stacks: []types.Stack{parent, child}}}
+
+	var buf bytes.Buffer
+	if err := writeStacks(&buf, formatCSV, results); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `region,name,status,drift,last_updated,parent,tags
+us-east-1,app,UPDATE_COMPLETE,DRIFTED,2024-03-01T09:30:00Z,,Env=prod;Team=payments
+us-east-1,app-network,CREATE_COMPLETE,NOT_CHECKED,2024-03-01T09:30:00Z,app,
+`
+	if d := cmp.Diff(expected, buf.String()); d != "" {
+		t.Error(d)
+	}
+
+	buf.Reset()
+	if err := writeStacks(&buf, formatTable, results); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `Region     Name         Status           Drift        Last updated          
+us-east-1  app          UPDATE_COMPLETE  DRIFTED      2024-03-01T09:30:00Z  
+us-east-1  app-network  CREATE_COMPLETE  NOT_CHECKED  2024-03-01T09:30:00Z  
+`
+	if d := cmp.Diff(expected, buf.String()); d != "" {
+		t.Error(d)
+	}
+
+	buf.Reset()
+	if err := writeStacks(&buf, formatYAML, results[:0]); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("unexpected YAML for no stacks: %q", buf.String())
+	}
+
+	if err := writeStacks(&buf, "xml", results); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestWriteStacksAccounts(t *testing.T) {
+	results := []regionStacks{
+		{account: "111111111111", accountName: "prod", region: "us-east-1",
+			stacks: []types.Stack{testStack("app", types.StackStatusCreateComplete, nil)}},
+		{account: "222222222222", region: "eu-west-1",
+			stacks: []types.Stack{testStack("app", types.StackStatusRollbackComplete, nil)}},
+	}
+
+	var buf bytes.Buffer
+	if err := writeStacks(&buf, formatCSV, results); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `account,account_name,region,name,status,drift,last_updated,parent,tags
+111111111111,prod,us-east-1,app,CREATE_COMPLETE,NOT_CHECKED,,,
+222222222222,,eu-west-1,app,ROLLBACK_COMPLETE,NOT_CHECKED,,,
+`
+	if d := cmp.Diff(expected, buf.String()); d != "" {
+		t.Error(d)
+	}
+
+	if l := results[0].location(); l != "account 111111111111 (prod), us-east-1" {
+		t.Errorf("unexpected location: %s", l)
+	}
+
+	if l := results[1].location(); l != "account 222222222222, eu-west-1" {
+		t.Errorf("unexpected location: %s", l)
+	}
+
+	if l := (regionStacks{region: "us-west-2"}).location(); l != "us-west-2" {
+		t.Errorf("unexpected location: %s", l)
+	}
+}
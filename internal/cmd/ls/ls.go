@@ -1,8 +1,13 @@
 package ls
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"slices"
 	"sort"
+	"strings"
+	"sync"
 
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/ui"
@@ -12,12 +17,18 @@ import (
 	"github.com/aws-cloudformation/rain/internal/aws/ec2"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
-	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws/smithy-go/ptr"
 	"github.com/spf13/cobra"
 )
 
 var all = false
 var changeset = false
+var regionList []string
+var namePattern string
+var statusPatterns []string
+var tagFilters []string
+var formatFlag string
 
 func ShowChangeSetsForStack(stackName string) error {
 	sets, err := cfn.ListChangeSets(stackName)
@@ -45,13 +56,30 @@ func ShowChangeSetsForStack(stackName string) error {
 
 // Cmd is the ls command's entrypoint
 var Cmd = &cobra.Command{
-	Use:                   "ls <stack> [changeset]",
-	Short:                 "List running CloudFormation stacks or changesets",
-	Long:                  "Displays a list of all running stacks or the contents of <stack> if provided. If the -c arg is supplied, operates on changesets instead of stacks",
+	Use:   "ls <stack> [changeset]",
+	Short: "List running CloudFormation stacks or changesets",
+	Long: `Displays a list of all running stacks or the contents of <stack> if provided. If the -c arg is supplied, operates on changesets instead of stacks.
+
+Stacks can be filtered by name, status and tags, with * as a wildcard:
+
+  rain ls --name 'prod-*' --status '*FAILED' --tag Team=payments
+
+Use --regions to list the stacks in several regions at once, and --format to output
+a table, JSON, YAML or CSV that includes each stack's drift status and when it was last updated.
+
+Use --all-accounts to build an inventory of the stacks in every account in the AWS Organization,
+or --accounts to list specific accounts. Rain assumes the role named by --role in each account
+other than the current one, and lists --concurrency accounts and regions at the same time.
+Accounts and regions that can't be listed are reported after the stacks that could.
+--all-accounts must be run from the management account or a delegated administrator.`,
 	Args:                  cobra.MaximumNArgs(2),
 	Aliases:               []string{"list"},
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
+		if changeset && emit.Enabled() {
+			panic(fmt.Errorf("rain ls --changeset doesn't support --output-format %s", emit.JSON))
+		}
+
 		if len(args) > 0 {
 
 			if changeset {
@@ -79,6 +107,8 @@ var Cmd = &cobra.Command{
 			output := cfn.GetStackSummary(stack, all)
 			spinner.Pop()
 
+			emit.Stack(stack, nil)
+
 			fmt.Println(output)
 			fmt.Println(console.Yellow("  ChangeSets:"))
 			err = ShowChangeSetsForStack(*stack.StackName)
@@ -91,6 +121,10 @@ var Cmd = &cobra.Command{
 			var err error
 			regions := []string{aws.Config().Region}
 
+			if len(regionList) > 0 {
+				regions = regionList
+			}
+
 			if all {
 				spinner.Push("Fetching region list")
 				regions, err = ec2.GetRegions()
@@ -100,70 +134,180 @@ var Cmd = &cobra.Command{
 				spinner.Pop()
 			}
 
-			origRegion := aws.Config().Region
+			if changeset && multiAccount() {
+				panic(errors.New("--all-accounts and --accounts can't be used with --changeset"))
+			}
 
-			for _, region := range regions {
+			if changeset {
+				listChangeSets(regions)
+			} else {
+				listStacks(regions)
+			}
+		}
 
-				spinner.Push(fmt.Sprintf("Fetching stacks in %s", region))
-				aws.SetRegion(region)
-				stacks, err := cfn.ListStacks()
-				if err != nil {
-					panic(ui.Errorf(err, "failed to list stacks"))
-				}
-				spinner.Pop()
+		// Reset flags
+		all = false
+		regionList = nil
+		allAccounts = false
+		accountList = nil
+	},
+}
 
-				if len(stacks) == 0 && all {
-					continue
-				}
+// listChangeSets shows the change sets of the stacks in each region
+func listChangeSets(regions []string) {
+	origRegion := aws.Config().Region
+
+	for _, region := range regions {
+		spinner.Push(fmt.Sprintf("Fetching stacks in %s", region))
+		aws.SetRegion(region)
+		stacks, err := cfn.ListStacks()
+		if err != nil {
+			panic(ui.Errorf(err, "failed to list stacks"))
+		}
+		spinner.Pop()
+
+		if len(stacks) == 0 && len(regions) > 1 {
+			continue
+		}
+
+		// We need to call ListChangeSets for each stack
+		// and see if it has any active changesets
+		fmt.Println(console.Yellow(fmt.Sprintf("Stacks with changesets in %s:", region)))
+		for _, stack := range stacks {
+			if stack.StackName == nil {
+				continue
+			}
+			config.Debugf("Checking stack %s", *stack.StackName)
+
+			err := ShowChangeSetsForStack(*stack.StackName)
+			if err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	aws.SetRegion(origRegion)
+}
+
+// fetchStacks lists the stacks that match the filter in each account and region,
+// listing up to --concurrency of them at the same time
+func fetchStacks(targets []target, f stackFilter) []regionStacks {
+	results := make([]regionStacks, len(targets))
 
-				stackNames := make(sort.StringSlice, 0)
-
-				// For changesets, we need to now call ListChangeSets for
-				// each stack and see if it has any active changesets
-				if changeset {
-					fmt.Println(console.Yellow(fmt.Sprintf("Stacks with changesets in %s:", region)))
-					for _, stack := range stacks {
-						if stack.StackName == nil {
-							continue
-						}
-						config.Debugf("Checking stack %s", *stack.StackName)
-
-						err := ShowChangeSetsForStack(*stack.StackName)
-						if err != nil {
-							panic(err)
-						}
-
-					}
-
-				} else {
-
-					stackMap := make(map[string]types.StackSummary)
-					for _, stack := range stacks {
-						stackNames = append(stackNames, *stack.StackName)
-						stackMap[*stack.StackName] = stack
-					}
-					sort.Strings(stackNames)
-
-					fmt.Println(console.Yellow(fmt.Sprintf("CloudFormation stacks in %s:", region)))
-					for _, stackName := range stackNames {
-						stack := stackMap[stackName]
-
-						if stack.ParentId == nil {
-							fmt.Println(ui.Indent("  ", formatStack(stack, stackMap)))
-						}
-					}
+	limit := make(chan struct{}, max(concurrency, 1))
+
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func(i int, t target) {
+			defer wg.Done()
+
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			results[i].account = t.account
+			results[i].accountName = t.accountName
+			results[i].region = t.region
+
+			stacks, err := cfn.DescribeStacksWithConfig(t.cfg, t.region)
+			if err != nil {
+				results[i].err = err
+				return
+			}
+
+			for _, stack := range stacks {
+				if f.matches(stack) {
+					results[i].stacks = append(results[i].stacks, stack)
 				}
 			}
 
-			aws.SetRegion(origRegion)
+			sort.Slice(results[i].stacks, func(a, b int) bool {
+				return ptr.ToString(results[i].stacks[a].StackName) < ptr.ToString(results[i].stacks[b].StackName)
+			})
+		}(i, t)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// listStacks shows the stacks in each region
+func listStacks(regions []string) {
+	f, err := newStackFilter(namePattern, statusPatterns, tagFilters)
+	if err != nil {
+		panic(err)
+	}
+
+	if !slices.Contains(formats, formatFlag) {
+		panic(fmt.Errorf("unknown format '%s'; use one of %s", formatFlag, strings.Join(formats, ", ")))
+	}
+
+	targets := targets(regions)
+
+	switch {
+	case multiAccount():
+		spinner.Push(fmt.Sprintf("Fetching stacks in %d regions of %d accounts", len(regions), len(targets)/max(len(regions), 1)))
+	case len(regions) == 1:
+		spinner.Push(fmt.Sprintf("Fetching stacks in %s", regions[0]))
+	default:
+		spinner.Push(fmt.Sprintf("Fetching stacks in %d regions", len(regions)))
+	}
+	results := fetchStacks(targets, f)
+	spinner.Pop()
+
+	// Show what could be listed, and then report what couldn't
+	listed := make([]regionStacks, 0, len(results))
+	failed := make([]regionStacks, 0)
+	for _, r := range results {
+		if r.err != nil {
+			failed = append(failed, r)
+		} else {
+			listed = append(listed, r)
 		}
+	}
 
-		// Reset flags
-		all = false
-	},
+	if len(failed) == len(results) && len(failed) == 1 {
+		panic(ui.Errorf(failed[0].err, "failed to list stacks in %s", failed[0].location()))
+	}
+
+	emit.Event("stacks", stackRows(listed))
+
+	if formatFlag != formatText {
+		if err := writeStacks(os.Stdout, formatFlag, listed); err != nil {
+			panic(err)
+		}
+	} else {
+		for _, r := range listed {
+			if len(r.stacks) == 0 && len(results) > 1 {
+				continue
+			}
+
+			fmt.Println(console.Yellow(fmt.Sprintf("CloudFormation stacks in %s:", r.location())))
+			for _, stack := range topLevel(r.stacks) {
+				fmt.Println(ui.Indent("  ", formatStack(stack, r.stacks)))
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		for _, r := range failed {
+			console.Failf("Unable to list stacks in %s: %s", r.location(), r.err)
+		}
+
+		panic(fmt.Errorf("failed to list stacks in %d of %d account regions", len(failed), len(results)))
+	}
 }
 
 func init() {
 	Cmd.Flags().BoolVarP(&all, "all", "a", false, "list stacks in all regions; if you specify a stack, show more details")
 	Cmd.Flags().BoolVarP(&changeset, "changeset", "c", false, "List changesets instead of stacks")
+	Cmd.Flags().StringSliceVar(&regionList, "regions", []string{}, "list stacks in these regions, e.g. us-east-1,eu-west-1")
+	Cmd.Flags().StringVar(&namePattern, "name", "", "only list stacks whose names match this pattern, e.g. prod-*")
+	Cmd.Flags().StringSliceVar(&statusPatterns, "status", []string{}, "only list stacks with one of these statuses, e.g. *FAILED,*IN_PROGRESS")
+	Cmd.Flags().StringArrayVar(&tagFilters, "tag", []string{}, "only list stacks with this tag, e.g. Environment=prod or Owner (can be repeated)")
+	Cmd.Flags().StringVar(&formatFlag, "format", formatText, "Output format: text, table, json, yaml or csv")
+	Cmd.Flags().BoolVar(&allAccounts, "all-accounts", false, "list stacks in every account in the AWS Organization")
+	Cmd.Flags().StringSliceVar(&accountList, "accounts", []string{}, "list stacks in these accounts, e.g. 111111111111,222222222222")
+	Cmd.Flags().StringVar(&roleName, "role", defaultRoleName, "name of the role to assume in each account with --all-accounts or --accounts")
+	Cmd.Flags().IntVar(&concurrency, "concurrency", 10, "maximum number of accounts and regions to list at the same time")
 }
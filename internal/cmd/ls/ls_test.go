@@ -14,7 +14,20 @@ func Example_ls_help() {
 
 	ls.Cmd.Execute()
 	// Output:
-	// Displays a list of all running stacks or the contents of <stack> if provided. If the -c arg is supplied, operates on changesets instead of stacks
+	// Displays a list of all running stacks or the contents of <stack> if provided. If the -c arg is supplied, operates on changesets instead of stacks.
+	//
+	// Stacks can be filtered by name, status and tags, with * as a wildcard:
+	//
+	//   rain ls --name 'prod-*' --status '*FAILED' --tag Team=payments
+	//
+	// Use --regions to list the stacks in several regions at once, and --format to output
+	// a table, JSON, YAML or CSV that includes each stack's drift status and when it was last updated.
+	//
+	// Use --all-accounts to build an inventory of the stacks in every account in the AWS Organization,
+	// or --accounts to list specific accounts. Rain assumes the role named by --role in each account
+	// other than the current one, and lists --concurrency accounts and regions at the same time.
+	// Accounts and regions that can't be listed are reported after the stacks that could.
+	// --all-accounts must be run from the management account or a delegated administrator.
 	//
 	// Usage:
 	//   ls <stack> [changeset]
@@ -23,7 +36,16 @@ func Example_ls_help() {
 	//   ls, list
 	//
 	// Flags:
-	//   -a, --all         list stacks in all regions; if you specify a stack, show more details
-	//   -c, --changeset   List changesets instead of stacks
-	//   -h, --help        help for ls
+	//       --accounts strings   list stacks in these accounts, e.g. 111111111111,222222222222
+	//   -a, --all                list stacks in all regions; if you specify a stack, show more details
+	//       --all-accounts       list stacks in every account in the AWS Organization
+	//   -c, --changeset          List changesets instead of stacks
+	//       --concurrency int    maximum number of accounts and regions to list at the same time (default 10)
+	//       --format string      Output format: text, table, json, yaml or csv (default "text")
+	//   -h, --help               help for ls
+	//       --name string        only list stacks whose names match this pattern, e.g. prod-*
+	//       --regions strings    list stacks in these regions, e.g. us-east-1,eu-west-1
+	//       --role string        name of the role to assume in each account with --all-accounts or --accounts (default "OrganizationAccountAccessRole")
+	//       --status strings     only list stacks with one of these statuses, e.g. *FAILED,*IN_PROGRESS
+	//       --tag stringArray    only list stacks with this tag, e.g. Environment=prod or Owner (can be repeated)
 }
@@ -0,0 +1,210 @@
+package ls
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/table"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats for --format
+const (
+	formatText  = "text"
+	formatTable = "table"
+	formatJSON  = "json"
+	formatYAML  = "yaml"
+	formatCSV   = "csv"
+)
+
+var formats = []string{formatText, formatTable, formatJSON, formatYAML, formatCSV}
+
+// regionStacks are the stacks that were found in a region of an account
+type regionStacks struct {
+	account     string
+	accountName string
+	region      string
+	stacks      []types.Stack
+	err         error
+}
+
+// location describes where stacks were listed, for headings and error messages
+func (r regionStacks) location() string {
+	if r.account == "" {
+		return r.region
+	}
+
+	if r.accountName != "" {
+		return fmt.Sprintf("account %s (%s), %s", r.account, r.accountName, r.region)
+	}
+
+	return fmt.Sprintf("account %s, %s", r.account, r.region)
+}
+
+// stackRow is a stack as it appears in table, JSON, YAML and CSV output
+type stackRow struct {
+	Account     string            `json:"account,omitempty" yaml:"account,omitempty"`
+	AccountName string            `json:"accountName,omitempty" yaml:"accountName,omitempty"`
+	Region      string            `json:"region" yaml:"region"`
+	Name        string            `json:"name" yaml:"name"`
+	Status      string            `json:"status" yaml:"status"`
+	Drift       string            `json:"drift" yaml:"drift"`
+	LastUpdated string            `json:"lastUpdated" yaml:"lastUpdated"`
+	Parent      string            `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty" yaml:"tags,omitempty"`
+}
+
+// lastUpdated returns when the stack last changed, which is when it was created if it has never been updated
+func lastUpdated(stack types.Stack) string {
+	t := stack.LastUpdatedTime
+	if t == nil {
+		t = stack.CreationTime
+	}
+
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
+
+// driftStatus returns the result of the stack's last drift detection
+func driftStatus(stack types.Stack) string {
+	if stack.DriftInformation == nil || stack.DriftInformation.StackDriftStatus == "" {
+		return string(types.StackDriftStatusNotChecked)
+	}
+
+	return string(stack.DriftInformation.StackDriftStatus)
+}
+
+// stackRows flattens the stacks of every account and region into rows, in the order they were listed
+func stackRows(results []regionStacks) []stackRow {
+	rows := make([]stackRow, 0)
+
+	for _, r := range results {
+		names := make(map[string]string)
+		for _, stack := range r.stacks {
+			names[ptr.ToString(stack.StackId)] = ptr.ToString(stack.StackName)
+		}
+
+		for _, stack := range r.stacks {
+			row := stackRow{
+				Account:     r.account,
+				AccountName: r.accountName,
+				Region:      r.region,
+				Name:        ptr.ToString(stack.StackName),
+				Status:      string(stack.StackStatus),
+				Drift:       driftStatus(stack),
+				LastUpdated: lastUpdated(stack),
+			}
+
+			if stack.ParentId != nil {
+				row.Parent = names[*stack.ParentId]
+				if row.Parent == "" {
+					row.Parent = *stack.ParentId
+				}
+			}
+
+			if len(stack.Tags) > 0 {
+				row.Tags = make(map[string]string, len(stack.Tags))
+				for _, tag := range stack.Tags {
+					row.Tags[ptr.ToString(tag.Key)] = ptr.ToString(tag.Value)
+				}
+			}
+
+			rows = append(rows, row)
+		}
+	}
+
+	return rows
+}
+
+// writeStacks writes the stacks in one of the structured formats
+func writeStacks(w io.Writer, format string, results []regionStacks) error {
+	rows := stackRows(results)
+
+	switch format {
+	case formatTable:
+		headers := []interface{}{"Region", "Name", "Status", "Drift", "Last updated"}
+		if hasAccounts(rows) {
+			headers = append([]interface{}{"Account"}, headers...)
+		}
+
+		tbl := table.New(headers...).WithWriter(w)
+		for _, row := range rows {
+			values := []interface{}{row.Region, row.Name, row.Status, row.Drift, row.LastUpdated}
+			if hasAccounts(rows) {
+				values = append([]interface{}{row.Account}, values...)
+			}
+			tbl.AddRow(values...)
+		}
+		tbl.Print()
+		return nil
+
+	case formatJSON:
+		out, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(out))
+		return err
+
+	case formatYAML:
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(rows); err != nil {
+			return err
+		}
+		return enc.Close()
+
+	case formatCSV:
+		out := csv.NewWriter(w)
+
+		header := []string{"region", "name", "status", "drift", "last_updated", "parent", "tags"}
+		if hasAccounts(rows) {
+			header = append([]string{"account", "account_name"}, header...)
+		}
+		if err := out.Write(header); err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			tags := make([]string, 0, len(row.Tags))
+			for k, v := range row.Tags {
+				tags = append(tags, k+"="+v)
+			}
+			sort.Strings(tags)
+
+			record := []string{row.Region, row.Name, row.Status, row.Drift, row.LastUpdated, row.Parent, strings.Join(tags, ";")}
+			if hasAccounts(rows) {
+				record = append([]string{row.Account, row.AccountName}, record...)
+			}
+			if err := out.Write(record); err != nil {
+				return err
+			}
+		}
+		out.Flush()
+		return out.Error()
+	}
+
+	return fmt.Errorf("unknown format '%s'; use one of %s", format, strings.Join(formats, ", "))
+}
+
+// hasAccounts returns true if the rows come from more than the current account,
+// in which case table and CSV output have account columns
+func hasAccounts(rows []stackRow) bool {
+	for _, row := range rows {
+		if row.Account != "" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -5,11 +5,12 @@ import (
 	"strings"
 
 	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/smithy-go/ptr"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 )
 
-func formatStack(stack types.StackSummary, stackMap map[string]types.StackSummary) string {
+func formatStack(stack types.Stack, stacks []types.Stack) string {
 	out := strings.Builder{}
 
 	out.WriteString(fmt.Sprintf("%s: %s\n",
@@ -17,12 +18,30 @@ func formatStack(stack types.StackSummary, stackMap map[string]types.StackSummar
 		ui.ColouriseStatus(string(stack.StackStatus)),
 	))
 
-	for _, otherStack := range stackMap {
-		if otherStack.ParentId != nil && *otherStack.ParentId == *stack.StackId {
-			out.WriteString(ui.Indent("  - ", formatStack(otherStack, stackMap)))
+	for _, otherStack := range stacks {
+		if otherStack.ParentId != nil && *otherStack.ParentId == ptr.ToString(stack.StackId) {
+			out.WriteString(ui.Indent("  - ", formatStack(otherStack, stacks)))
 			out.WriteString("\n")
 		}
 	}
 
 	return out.String()
 }
+
+// topLevel returns the stacks whose parents are not in the list,
+// so that nested stacks are shown under their parents
+func topLevel(stacks []types.Stack) []types.Stack {
+	ids := make(map[string]bool)
+	for _, stack := range stacks {
+		ids[ptr.ToString(stack.StackId)] = true
+	}
+
+	out := make([]types.Stack, 0)
+	for _, stack := range stacks {
+		if stack.ParentId == nil || !ids[*stack.ParentId] {
+			out = append(out, stack)
+		}
+	}
+
+	return out
+}
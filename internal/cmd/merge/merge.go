@@ -1,8 +1,10 @@
 package merge
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/aws-cloudformation/rain/cft"
 	"github.com/aws-cloudformation/rain/cft/format"
@@ -11,19 +13,34 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var strategy = strategyFail
 var forceMerge = false
 var outFn = ""
 
 // Cmd is the merge command's entrypoint
 var Cmd = &cobra.Command{
-	Use:                   "merge <template> <template> ...",
-	Short:                 "Merge two or more CloudFormation templates",
-	Long:                  "Merges all specified CloudFormation templates, print the resultant template to standard out",
+	Use:   "merge <template> <template> ...",
+	Short: "Merge two or more CloudFormation templates",
+	Long: `Merges all specified CloudFormation templates, print the resultant template to standard out.
+
+When templates have elements with the same logical ID, --strategy decides what happens:
+  fail      stop the merge (the default)
+  rename    give the element from the later template a new logical ID, and update
+            the Refs, GetAtts, Subs, DependsOn, Conditions, and FindInMaps that use it
+  override  keep the element from the later template`,
 	Args:                  cobra.MinimumNArgs(2),
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
 		var err error
 
+		if forceMerge && cmd.Flags().Changed("strategy") {
+			panic(errors.New("--force can't be used with --strategy"))
+		}
+
+		if !validStrategy(strategy) {
+			panic(fmt.Errorf("unknown strategy '%s'; use one of: %s", strategy, strings.Join(strategies, ", ")))
+		}
+
 		templates := make([]cft.Template, len(args))
 
 		for i, fn := range args {
@@ -58,6 +75,7 @@ var Cmd = &cobra.Command{
 
 func init() {
 	Cmd.Flags().StringVarP(&outFn, "output", "o", "", "Output merged template to a file")
-	Cmd.Flags().BoolVarP(&forceMerge, "force", "f", false, "Don't warn on clashing attributes; rename them instead. Note: this will not rename Refs, GetAtts, etc.")
+	Cmd.Flags().BoolVarP(&forceMerge, "force", "f", false, "Don't warn on clashing attributes; rename them instead. Note: this will not rename Refs, GetAtts, etc.; use --strategy rename for that")
+	Cmd.Flags().StringVarP(&strategy, "strategy", "s", strategyFail, "What to do with clashing logical IDs: "+strings.Join(strategies, ", "))
 	Cmd.Flags().StringVar(&format.NodeStyle, "node-style", "", format.NodeStyleDocs)
 }
@@ -128,6 +128,7 @@ func TestMergeTemplatesSuccess(t *testing.T) {
 	})
 
 	forceMerge = false
+	strategy = strategyFail
 	actual, err := mergeTemplates(dst, src)
 	if err != nil {
 		t.Fatal(err)
@@ -267,6 +268,7 @@ func TestEmptyMergeTemplatesSuccess(t *testing.T) {
 	empty, _ := parse.Map(map[string]interface{}{})
 
 	forceMerge = false
+	strategy = strategyFail
 	// rain merge src.yaml /dev/null
 	{
 		actual, err := mergeTemplates(src, empty)
@@ -309,6 +311,199 @@ func TestMergeTemplatesClash(t *testing.T) {
 	})
 
 	forceMerge = false
+	strategy = strategyFail
+	if _, err := mergeTemplates(dst, src); err == nil {
+		t.Fail()
+	}
+}
+
+func TestRenameMergeTemplatesRewritesReferences(t *testing.T) {
+	dst, _ := parse.Map(map[string]interface{}{
+		"Parameters": map[string]interface{}{
+			"Name": map[string]interface{}{"Type": "String"},
+		},
+		"Mappings": map[string]interface{}{
+			"Sizes": map[string]interface{}{"Small": map[string]interface{}{"Count": 1}},
+		},
+		"Conditions": map[string]interface{}{
+			"IsProd": map[string]interface{}{"Fn::Equals": []interface{}{"a", "b"}},
+		},
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{"Type": "AWS::S3::Bucket"},
+		},
+	})
+
+	src, _ := parse.Map(map[string]interface{}{
+		"Metadata": map[string]interface{}{
+			"AWS::CloudFormation::Interface": map[string]interface{}{
+				"ParameterGroups": []interface{}{
+					map[string]interface{}{"Parameters": []interface{}{"Name"}},
+				},
+				"ParameterLabels": map[string]interface{}{
+					"Name": map[string]interface{}{"default": "Bucket name"},
+				},
+			},
+		},
+		"Parameters": map[string]interface{}{
+			"Name": map[string]interface{}{"Type": "String"},
+		},
+		"Mappings": map[string]interface{}{
+			"Sizes": map[string]interface{}{"Large": map[string]interface{}{"Count": 2}},
+		},
+		"Conditions": map[string]interface{}{
+			"IsProd": map[string]interface{}{"Fn::Equals": []interface{}{"c", "d"}},
+		},
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{
+				"Type":      "AWS::S3::Bucket",
+				"Condition": "IsProd",
+				"Properties": map[string]interface{}{
+					"BucketName": map[string]interface{}{"Ref": "Name"},
+				},
+			},
+			"Topic": map[string]interface{}{
+				"Type":      "AWS::SNS::Topic",
+				"DependsOn": []interface{}{"Bucket"},
+				"Properties": map[string]interface{}{
+					"TopicName": map[string]interface{}{
+						"Fn::Sub": []interface{}{
+							"${Name}-${Bucket.Arn}-${Size}-${!Literal}",
+							map[string]interface{}{
+								"Size": map[string]interface{}{
+									"Fn::FindInMap": []interface{}{"Sizes", "Large", "Count"},
+								},
+							},
+						},
+					},
+					"DisplayName": map[string]interface{}{
+						"Fn::If": []interface{}{
+							"IsProd",
+							map[string]interface{}{"Fn::GetAtt": "Bucket.Arn"},
+							map[string]interface{}{"Fn::GetAtt": []interface{}{"Bucket", "DomainName"}},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	expected, _ := parse.Map(map[string]interface{}{
+		"Metadata": map[string]interface{}{
+			"AWS::CloudFormation::Interface": map[string]interface{}{
+				"ParameterGroups": []interface{}{
+					map[string]interface{}{"Parameters": []interface{}{"Name2"}},
+				},
+				"ParameterLabels": map[string]interface{}{
+					"Name2": map[string]interface{}{"default": "Bucket name"},
+				},
+			},
+		},
+		"Parameters": map[string]interface{}{
+			"Name":  map[string]interface{}{"Type": "String"},
+			"Name2": map[string]interface{}{"Type": "String"},
+		},
+		"Mappings": map[string]interface{}{
+			"Sizes":  map[string]interface{}{"Small": map[string]interface{}{"Count": 1}},
+			"Sizes2": map[string]interface{}{"Large": map[string]interface{}{"Count": 2}},
+		},
+		"Conditions": map[string]interface{}{
+			"IsProd":  map[string]interface{}{"Fn::Equals": []interface{}{"a", "b"}},
+			"IsProd2": map[string]interface{}{"Fn::Equals": []interface{}{"c", "d"}},
+		},
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{"Type": "AWS::S3::Bucket"},
+			"Bucket2": map[string]interface{}{
+				"Type":      "AWS::S3::Bucket",
+				"Condition": "IsProd2",
+				"Properties": map[string]interface{}{
+					"BucketName": map[string]interface{}{"Ref": "Name2"},
+				},
+			},
+			"Topic": map[string]interface{}{
+				"Type":      "AWS::SNS::Topic",
+				"DependsOn": []interface{}{"Bucket2"},
+				"Properties": map[string]interface{}{
+					"TopicName": map[string]interface{}{
+						"Fn::Sub": []interface{}{
+							"${Name2}-${Bucket2.Arn}-${Size}-${!Literal}",
+							map[string]interface{}{
+								"Size": map[string]interface{}{
+									"Fn::FindInMap": []interface{}{"Sizes2", "Large", "Count"},
+								},
+							},
+						},
+					},
+					"DisplayName": map[string]interface{}{
+						"Fn::If": []interface{}{
+							"IsProd2",
+							map[string]interface{}{"Fn::GetAtt": "Bucket2.Arn"},
+							map[string]interface{}{"Fn::GetAtt": []interface{}{"Bucket2", "DomainName"}},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	strategy = strategyRename
+	actual, err := mergeTemplates(dst, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := cmp.Diff(actual.Map(), expected.Map()); d != "" {
+		t.Errorf(d)
+	}
+}
+
+func TestOverrideMergeTemplates(t *testing.T) {
+	dst, _ := parse.Map(map[string]interface{}{
+		"Metadata": map[string]interface{}{"Foo": "bar"},
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{"Type": "AWS::S3::Bucket"},
+		},
+	})
+
+	src, _ := parse.Map(map[string]interface{}{
+		"Metadata": map[string]interface{}{"Foo": "baz"},
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{"Type": "AWS::S3::AccessPoint"},
+		},
+	})
+
+	expected, _ := parse.Map(map[string]interface{}{
+		"Metadata": map[string]interface{}{"Foo": "baz"},
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{"Type": "AWS::S3::AccessPoint"},
+		},
+	})
+
+	strategy = strategyOverride
+	actual, err := mergeTemplates(dst, src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := cmp.Diff(actual.Map(), expected.Map()); d != "" {
+		t.Errorf(d)
+	}
+}
+
+func TestMergeTemplatesParameterResourceClash(t *testing.T) {
+	dst, _ := parse.Map(map[string]interface{}{
+		"Parameters": map[string]interface{}{
+			"Bucket": map[string]interface{}{"Type": "String"},
+		},
+	})
+
+	src, _ := parse.Map(map[string]interface{}{
+		"Resources": map[string]interface{}{
+			"Bucket": map[string]interface{}{"Type": "AWS::S3::Bucket"},
+		},
+	})
+
+	// Overriding a parameter with a resource would leave Refs to the parameter pointing at the resource
+	strategy = strategyOverride
 	if _, err := mergeTemplates(dst, src); err == nil {
 		t.Fail()
 	}
@@ -0,0 +1,314 @@
+package merge
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Strategies for elements of different templates that have the same logical ID
+const (
+	// strategyFail stops the merge
+	strategyFail = "fail"
+
+	// strategyRename gives the element from the later template a new logical ID,
+	// and updates the references to it in that template
+	strategyRename = "rename"
+
+	// strategyOverride keeps the element from the later template
+	strategyOverride = "override"
+)
+
+var strategies = []string{strategyFail, strategyRename, strategyOverride}
+
+func validStrategy(s string) bool {
+	for _, known := range strategies {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// idSections are the sections of a template whose elements have logical IDs
+var idSections = []string{"Parameters", "Mappings", "Conditions", "Resources", "Outputs"}
+
+// Parameters and resources share logical IDs, since Ref can refer to either
+func sharesIds(section string) bool {
+	return section == "Parameters" || section == "Resources"
+}
+
+// renames are the new logical IDs of elements, by section and old logical ID
+type renames map[string]map[string]string
+
+func sectionMap(t map[string]interface{}, section string) map[string]interface{} {
+	m, _ := t[section].(map[string]interface{})
+	return m
+}
+
+// sortedKeys returns the keys of the map in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// clashes returns the section of dst that already has the logical ID, or ""
+func clashes(dst map[string]interface{}, section, id string) string {
+	if sharesIds(section) {
+		for _, s := range []string{"Parameters", "Resources"} {
+			if _, ok := sectionMap(dst, s)[id]; ok {
+				return s
+			}
+		}
+		return ""
+	}
+
+	if _, ok := sectionMap(dst, section)[id]; ok {
+		return section
+	}
+
+	return ""
+}
+
+// planRenames chooses new logical IDs for the elements of src whose logical IDs are taken in dst.
+// Elements that clash with an element of the same section are left to the strategy;
+// a parameter that clashes with a resource, or the other way round, can only be renamed.
+func planRenames(dst, src map[string]interface{}) (renames, error) {
+	taken := make(map[string]bool)
+	for _, t := range []map[string]interface{}{dst, src} {
+		for _, section := range idSections {
+			for id := range sectionMap(t, section) {
+				taken[id] = true
+			}
+		}
+	}
+
+	r := make(renames)
+
+	for _, section := range idSections {
+		for _, id := range sortedKeys(sectionMap(src, section)) {
+			clash := clashes(dst, section, id)
+			if clash == "" {
+				continue
+			}
+
+			switch {
+			case strategy == strategyRename:
+				// Unlike --force's legacy Name_2, the new ID must stay alphanumeric
+				// for CloudFormation to accept it
+				newId := ""
+				for i := 2; newId == "" || taken[newId]; i++ {
+					newId = fmt.Sprintf("%s%d", id, i)
+				}
+				taken[newId] = true
+
+				if r[section] == nil {
+					r[section] = make(map[string]string)
+				}
+				r[section][id] = newId
+			case clash != section:
+				return nil, fmt.Errorf("templates have clashing logical IDs: %s in %s and %s", id, clash, section)
+			}
+		}
+	}
+
+	return r, nil
+}
+
+// subVar matches the variables of an Fn::Sub string, but not literals like ${!Name}
+var subVar = regexp.MustCompile(`\$\{([^!}][^}]*)\}`)
+
+// apply renames the elements of t and updates the references to them
+func (r renames) apply(t map[string]interface{}) {
+	for _, section := range idSections {
+		m := sectionMap(t, section)
+		for oldId, newId := range r[section] {
+			if v, ok := m[oldId]; ok {
+				delete(m, oldId)
+				m[newId] = v
+			}
+		}
+	}
+
+	for key, value := range t {
+		t[key] = r.rewrite(value, nil)
+	}
+
+	r.rewriteInterface(t)
+}
+
+// messages describe the renames, in order
+func (r renames) messages() []string {
+	out := make([]string, 0)
+
+	for _, section := range idSections {
+		ids := make([]string, 0, len(r[section]))
+		for oldId := range r[section] {
+			ids = append(ids, oldId)
+		}
+		sort.Strings(ids)
+
+		for _, oldId := range ids {
+			out = append(out, fmt.Sprintf("Renamed %s %s to %s", strings.TrimSuffix(section, "s"), oldId, r[section][oldId]))
+		}
+	}
+
+	return out
+}
+
+// ref returns the new name of a parameter or resource
+func (r renames) ref(name string) string {
+	for _, section := range []string{"Parameters", "Resources"} {
+		if newId, ok := r[section][name]; ok {
+			return newId
+		}
+	}
+	return name
+}
+
+func (r renames) rename(section, name string) string {
+	if newId, ok := r[section][name]; ok {
+		return newId
+	}
+	return name
+}
+
+// renameFirst renames the first element of a list, as in Fn::If and Fn::FindInMap
+func (r renames) renameFirst(value interface{}, section string) {
+	if l, ok := value.([]interface{}); ok && len(l) > 0 {
+		if s, ok := l[0].(string); ok {
+			l[0] = r.rename(section, s)
+		}
+	}
+}
+
+// sub updates the variables of an Fn::Sub string, except those that are set by the Sub itself
+func (r renames) sub(s string, vars map[string]interface{}) string {
+	return subVar.ReplaceAllStringFunc(s, func(match string) string {
+		name := match[2 : len(match)-1]
+
+		id, attr, hasAttr := strings.Cut(name, ".")
+		if _, ok := vars[id]; ok {
+			return match
+		}
+
+		if hasAttr {
+			return "${" + r.rename("Resources", id) + "." + attr + "}"
+		}
+
+		return "${" + r.ref(id) + "}"
+	})
+}
+
+// rewrite updates the references in a part of a template.
+// vars are the variables that an enclosing Fn::Sub sets.
+func (r renames) rewrite(value interface{}, vars map[string]interface{}) interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		for i := range v {
+			v[i] = r.rewrite(v[i], vars)
+		}
+	case map[string]interface{}:
+		for key, child := range v {
+			switch key {
+			case "Ref":
+				if s, ok := child.(string); ok {
+					v[key] = r.ref(s)
+					continue
+				}
+			case "Fn::GetAtt":
+				switch g := child.(type) {
+				case string:
+					if id, attr, ok := strings.Cut(g, "."); ok {
+						v[key] = r.rename("Resources", id) + "." + attr
+					} else {
+						v[key] = r.rename("Resources", g)
+					}
+					continue
+				case []interface{}:
+					r.renameFirst(g, "Resources")
+				}
+			case "DependsOn":
+				switch d := child.(type) {
+				case string:
+					v[key] = r.rename("Resources", d)
+				case []interface{}:
+					for i, item := range d {
+						if s, ok := item.(string); ok {
+							d[i] = r.rename("Resources", s)
+						}
+					}
+				}
+				continue
+			case "Condition":
+				if s, ok := child.(string); ok {
+					v[key] = r.rename("Conditions", s)
+					continue
+				}
+			case "Fn::If":
+				r.renameFirst(child, "Conditions")
+			case "Fn::FindInMap":
+				r.renameFirst(child, "Mappings")
+			case "Fn::Sub":
+				switch s := child.(type) {
+				case string:
+					v[key] = r.sub(s, vars)
+					continue
+				case []interface{}:
+					if len(s) == 2 {
+						subVars, _ := s[1].(map[string]interface{})
+						if str, ok := s[0].(string); ok {
+							s[0] = r.sub(str, subVars)
+						}
+						s[1] = r.rewrite(s[1], vars)
+						continue
+					}
+				}
+			}
+
+			v[key] = r.rewrite(child, vars)
+		}
+	}
+
+	return value
+}
+
+// rewriteInterface updates the parameters in the AWS::CloudFormation::Interface metadata
+func (r renames) rewriteInterface(t map[string]interface{}) {
+	metadata := sectionMap(t, "Metadata")
+	iface, ok := metadata["AWS::CloudFormation::Interface"].(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	if groups, ok := iface["ParameterGroups"].([]interface{}); ok {
+		for _, group := range groups {
+			g, ok := group.(map[string]interface{})
+			if !ok {
+				continue
+			}
+
+			if params, ok := g["Parameters"].([]interface{}); ok {
+				for i, p := range params {
+					if s, ok := p.(string); ok {
+						params[i] = r.rename("Parameters", s)
+					}
+				}
+			}
+		}
+	}
+
+	if labels, ok := iface["ParameterLabels"].(map[string]interface{}); ok {
+		for oldId, newId := range r["Parameters"] {
+			if v, ok := labels[oldId]; ok {
+				delete(labels, oldId)
+				labels[newId] = v
+			}
+		}
+	}
+}
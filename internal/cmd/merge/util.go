@@ -5,6 +5,7 @@ import (
 
 	"github.com/aws-cloudformation/rain/cft"
 	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/console"
 )
 
 func checkMerge(name string, dst, src map[string]interface{}) error {
@@ -16,15 +17,18 @@ func checkMerge(name string, dst, src map[string]interface{}) error {
 
 		for key, value := range srcMap {
 			if _, ok := dstMap[key]; ok {
-				if forceMerge {
+				switch {
+				case forceMerge || strategy == strategyRename:
 					for i := 2; true; i++ {
 						newKey := fmt.Sprintf("%s_%d", key, i)
-						if _, ok := dst[newKey]; !ok {
+						if _, ok := dstMap[newKey]; !ok {
 							key = newKey
 							break
 						}
 					}
-				} else {
+				case strategy == strategyOverride:
+					// The later template's value replaces the earlier one
+				default:
 					return fmt.Errorf("templates have clashing %s: %s", name, key)
 				}
 			}
@@ -40,6 +44,21 @@ func mergeTemplates(dstTemplate, srcTemplate cft.Template) (cft.Template, error)
 	dst := dstTemplate.Map()
 	src := srcTemplate.Map()
 
+	// Logical IDs are renamed before the merge, so that references to them can be updated.
+	// --force renames clashing elements as they are merged instead, and leaves references alone.
+	if !forceMerge {
+		r, err := planRenames(dst, src)
+		if err != nil {
+			return cft.Template{}, err
+		}
+
+		r.apply(src)
+
+		for _, message := range r.messages() {
+			console.Warnf("%s", message)
+		}
+	}
+
 	for key, value := range src {
 		switch key {
 		case "AWSTemplateFormatVersion": // Always overwrite
@@ -119,7 +138,8 @@ func mergeTemplates(dstTemplate, srcTemplate cft.Template) (cft.Template, error)
 					if _, ok = dstMap[k]; !ok {
 						dstMap[k] = srcMap[k]
 					} else {
-						if forceMerge {
+						switch {
+						case forceMerge || strategy == strategyRename:
 							for i := 2; true; i++ {
 								newKey := fmt.Sprintf("%s_%d", k, i)
 								if _, ok := dstMap[newKey]; !ok {
@@ -127,7 +147,9 @@ func mergeTemplates(dstTemplate, srcTemplate cft.Template) (cft.Template, error)
 									break
 								}
 							}
-						} else {
+						case strategy == strategyOverride:
+							dstMap[k] = srcMap[k]
+						default:
 							return cft.Template{}, fmt.Errorf("templates have clashing %s: %s", key, k)
 						}
 					}
@@ -8,6 +8,8 @@ import (
 	cftpkg "github.com/aws-cloudformation/rain/cft/pkg"
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/gitmodule"
+	"github.com/aws-cloudformation/rain/internal/manifest"
 	"github.com/aws-cloudformation/rain/internal/node"
 	"github.com/aws-cloudformation/rain/internal/ui"
 	"github.com/spf13/cobra"
@@ -29,6 +31,12 @@ You may use the following, rain-specific directives in templates packaged with "
 
   !Rain::Embed <path>          Embeds the contents of the file at <path> into the template as a string
 
+  !Rain::Embed <object>        supply an object with the following properties:
+    Path: <path>               the file to embed
+    Encoding: raw|base64|json  "raw" (the default) embeds the trimmed contents of the file,
+                               "base64" embeds every byte of the file, base64-encoded,
+                               and "json" escapes the contents so they can be placed inside a JSON string
+
   !Rain::Include <path>        Reads the file at <path> as YAML/JSON and inserts the resulting object into the template
 
   !Rain::Env <name>            Reads the <name> environmental variable and inserts value into the template as a string
@@ -54,8 +62,43 @@ You may use the following, rain-specific directives in templates packaged with "
                                must be called "ModuleExtension", and it must have a Metadata entry called 
                                "Extends" that supplies the existing type to be extended. The Parameters section 
                                of the module can be used to define additional properties for the extension.
+                               The URL can be a local path, an https:// or s3:// URL, or a git:: source.
                                This is an experimental directive that must be enabled by adding the 
                                --experimental arg on the command line.
+
+A module can also be loaded from a git repository, pinned to a tag, branch or commit:
+
+  !Rain::Module git::https://github.com/example/modules.git//bucket/module.yaml?ref=v1.2.0
+
+The commit that each ref resolves to is recorded in rain.lock in the current directory,
+so that later builds use the same commit even if the tag or branch moves.
+Commit rain.lock with your templates, and use --update-modules to resolve the refs again.
+
+A template can declare constants in a Rain::Constants section. Each ${Rain::Name} in the template
+is replaced with the constant's value, which is useful where parameters can't be used,
+like the literal values in policy documents. Set a constant with --constant to override the template:
+
+  Rain::Constants:
+    Prefix: app
+    BucketName: ${Rain::Prefix}-assets
+
+Local paths in artifact properties such as a Lambda function's Code or a serverless function's CodeUri
+are zipped if necessary and uploaded to S3, just as "aws cloudformation package" does.
+Artifacts are stored under a hash of their content and directories are zipped with fixed timestamps,
+so artifacts that have not changed are not uploaded again.
+
+Nested stacks (AWS::CloudFormation::Stack) whose TemplateURL is a local file are packaged
+recursively, uploaded to S3, and their TemplateURL is replaced with the uploaded template's URL.
+
+A project can limit which directives, module sources, and transforms its templates use
+by listing them under Features in a rain.yaml file in the current directory:
+
+  Features:
+    Directives: [Rain::Embed, Rain::Module]
+    Modules: [modules/]
+    Transforms: [AWS::LanguageExtensions]
+
+Templates that use anything that is not listed fail to package.
 `,
 	Args:                  cobra.ExactArgs(1),
 	Aliases:               []string{"package"},
@@ -65,6 +108,10 @@ You may use the following, rain-specific directives in templates packaged with "
 
 		cftpkg.Experimental = Experimental
 
+		if err := manifest.UseProject(); err != nil {
+			panic(ui.Errorf(err, "unable to load %s", manifest.DefaultFileName))
+		}
+
 		spinner.Push(fmt.Sprintf("Packaging template '%s'", fn))
 		packaged, err := cftpkg.File(fn)
 		if err != nil {
@@ -93,4 +140,6 @@ func init() {
 	Cmd.Flags().BoolVar(&config.Debug, "debug", false, "Output debugging information")
 	Cmd.Flags().BoolVar(&dataModel, "datamodel", false, "Output the go yaml data model")
 	Cmd.Flags().StringVar(&format.NodeStyle, "node-style", "", format.NodeStyleDocs)
+	Cmd.Flags().StringToStringVar(&cftpkg.Constants, "constant", nil, "set the value of a Rain::Constants entry; use the format Name=value")
+	Cmd.Flags().BoolVar(&gitmodule.Update, "update-modules", false, "Resolve the refs of git modules again instead of using the commits in rain.lock")
 }
@@ -0,0 +1,171 @@
+package protect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var policyPath string
+
+// Cmd is the protect command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "protect <stack> [on|off|lock|unlock]",
+	Short: "Turn termination protection on or off for a stack, or lock it against rain deploy and rain rm",
+	Long: `Enables or disables termination protection for an existing stack.
+With only a stack name, shows whether the stack has termination protection, a stack policy, and a lock.
+
+Use lock to guard a stack, such as a production stack, from commands that were run by mistake.
+A locked stack has the tag rain:protected=true, and rain deploy and rain rm refuse to change it
+unless they are given --unlock. Setting the tag updates the stack with its previous template,
+and CloudFormation passes it on to the stack's resources. Use unlock to remove the tag.
+
+Use --stack-policy to replace the stack's policy with a JSON policy document:
+
+  rain protect app on --stack-policy app-policy.json
+
+To protect stacks when they are deployed, use rain deploy --termination-protection and --stack-policy,
+or set StackPolicy in the deploy config file.`,
+	Args:                  cobra.RangeArgs(1, 2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		stackName := args[0]
+
+		var policy string
+		if policyPath != "" {
+			content, err := os.ReadFile(policyPath)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to read stack policy '%s'", policyPath))
+			}
+
+			if !json.Valid(content) {
+				panic(fmt.Errorf("stack policy '%s' is not a valid JSON document", policyPath))
+			}
+
+			policy = string(content)
+		}
+
+		if len(args) == 1 {
+			if policy != "" {
+				setPolicy(stackName, policy)
+				return
+			}
+
+			showProtection(stackName)
+			return
+		}
+
+		var enabled bool
+		switch args[1] {
+		case "on":
+			enabled = true
+		case "off":
+			enabled = false
+		case "lock", "unlock":
+			setLocked(stackName, args[1] == "lock")
+			if policy != "" {
+				setPolicy(stackName, policy)
+			}
+			return
+		default:
+			panic(fmt.Errorf("expected on, off, lock or unlock, not '%s'", args[1]))
+		}
+
+		spinner.Push(fmt.Sprintf("Updating termination protection for stack '%s'", stackName))
+		err := cfn.SetTerminationProtection(stackName, enabled)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to update termination protection for stack '%s'", stackName))
+		}
+
+		if enabled {
+			fmt.Println(console.Green(fmt.Sprintf("Termination protection is on for stack '%s'", stackName)))
+		} else {
+			fmt.Println(console.Yellow(fmt.Sprintf("Termination protection is off for stack '%s'", stackName)))
+		}
+
+		if policy != "" {
+			setPolicy(stackName, policy)
+		}
+	},
+}
+
+// setLocked adds or removes the tag that locks the stack
+func setLocked(stackName string, locked bool) {
+	action := "Unlocking"
+	if locked {
+		action = "Locking"
+	}
+
+	spinner.Push(fmt.Sprintf("%s stack '%s'", action, stackName))
+	err := cfn.SetLocked(stackName, locked)
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "unable to update the lock on stack '%s'", stackName))
+	}
+
+	if locked {
+		fmt.Println(console.Green(fmt.Sprintf("Stack '%s' is locked; rain deploy and rain rm need --unlock to change it", stackName)))
+	} else {
+		fmt.Println(console.Yellow(fmt.Sprintf("Stack '%s' is unlocked", stackName)))
+	}
+}
+
+// setPolicy replaces the stack's policy
+func setPolicy(stackName string, policy string) {
+	spinner.Push(fmt.Sprintf("Setting the stack policy for stack '%s'", stackName))
+	err := cfn.SetStackPolicy(stackName, policy)
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "unable to set the stack policy for stack '%s'", stackName))
+	}
+
+	fmt.Println(console.Green(fmt.Sprintf("Set the stack policy for stack '%s'", stackName)))
+}
+
+// showProtection prints the stack's termination protection and stack policy
+func showProtection(stackName string) {
+	spinner.Push(fmt.Sprintf("Fetching stack '%s'", stackName))
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		spinner.Pop()
+		panic(ui.Errorf(err, "unable to get stack '%s'", stackName))
+	}
+
+	policy, err := cfn.GetStackPolicy(stackName)
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "unable to get the stack policy for stack '%s'", stackName))
+	}
+
+	if ptr.ToBool(stack.EnableTerminationProtection) {
+		fmt.Printf("Termination protection: %s\n", console.Green("on"))
+	} else {
+		fmt.Printf("Termination protection: %s\n", console.Yellow("off"))
+	}
+
+	if cfn.IsLocked(stack) {
+		fmt.Printf("Lock: %s\n", console.Green("locked"))
+	} else {
+		fmt.Printf("Lock: %s\n", console.Yellow("unlocked"))
+	}
+
+	if policy == "" {
+		fmt.Printf("Stack policy: %s\n", console.Grey("none"))
+		return
+	}
+
+	fmt.Println("Stack policy:")
+	fmt.Println(policy)
+}
+
+func init() {
+	Cmd.Flags().StringVar(&policyPath, "stack-policy", "", "JSON stack policy document to set on the stack")
+}
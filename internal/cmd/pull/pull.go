@@ -0,0 +1,69 @@
+package pull
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/oci"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var keyFile string
+
+// Cmd is the pull command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "pull <oci://registry/repository:tag> [directory]",
+	Short: "Download a template from an OCI registry",
+	Long: `Downloads the template, and the configuration file if there is one, of a bundle that was pushed with rain push.
+The files are written to <directory>, or to the current directory.
+
+With --cosign-key, the bundle must have a cosign signature made with the key pair of the public key.
+`,
+	Args:                  cobra.RangeArgs(1, 2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		uri := args[0]
+
+		dir := "."
+		if len(args) == 2 {
+			dir = args[1]
+		}
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			panic(ui.Errorf(err, "unable to create directory '%s'", dir))
+		}
+
+		var key []byte
+		if keyFile != "" {
+			var err error
+			key, err = os.ReadFile(keyFile)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to read key '%s'", keyFile))
+			}
+		}
+
+		spinner.Push(fmt.Sprintf("Pulling %s", uri))
+		b, err := oci.Download(uri, dir, key)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to pull %s", uri))
+		}
+
+		if key != nil {
+			fmt.Println(console.Green("Verified signature"))
+		}
+
+		fmt.Printf("Digest: %s\n", b.Digest)
+		fmt.Printf("Template: %s\n", b.Template)
+		if b.Config != "" {
+			fmt.Printf("Config: %s\n", b.Config)
+		}
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVar(&keyFile, "cosign-key", "", "cosign public key that the bundle must be signed with")
+}
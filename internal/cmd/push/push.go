@@ -0,0 +1,54 @@
+package push
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/oci"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var configFilePath string
+
+// Cmd is the push command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "push <oci://registry/repository:tag> <template>",
+	Short: "Push a template to an OCI registry",
+	Long: `Pushes <template>, and optionally a configuration file with its parameters and tags, to an OCI registry as a bundle.
+The bundle can be deployed with rain deploy oci://registry/repository:tag, or downloaded with rain pull.
+
+Credentials are read from RAIN_OCI_USERNAME and RAIN_OCI_PASSWORD, or from docker's configuration file after docker login.
+Sign the bundle with cosign sign --key, using the digest that rain prints, so that it can be verified when it is pulled.
+`,
+	Args:                  cobra.ExactArgs(2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		uri, template := args[0], args[1]
+
+		r, err := oci.ParseReference(uri)
+		if err != nil {
+			panic(err)
+		}
+
+		files, err := oci.Files(template, configFilePath)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read bundle files"))
+		}
+
+		spinner.Push(fmt.Sprintf("Pushing %s", r))
+		digest, err := oci.NewClient().Push(r, files)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to push %s", r))
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Pushed %s", r)))
+		fmt.Printf("Digest: %s\n", digest)
+	},
+}
+
+func init() {
+	Cmd.Flags().StringVarP(&configFilePath, "config", "c", "", "YAML or JSON file with tags and parameters to include in the bundle")
+}
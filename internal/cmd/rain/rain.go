@@ -2,32 +2,65 @@ package rain
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/spf13/cobra"
 
 	"github.com/aws-cloudformation/rain/internal/aws/s3"
+	"github.com/aws-cloudformation/rain/internal/aws/secretsmanager"
+	"github.com/aws-cloudformation/rain/internal/aws/ssm"
 	"github.com/aws-cloudformation/rain/internal/cmd"
+	"github.com/aws-cloudformation/rain/internal/cmd/adopt"
+	"github.com/aws-cloudformation/rain/internal/cmd/bench"
 	"github.com/aws-cloudformation/rain/internal/cmd/bootstrap"
+	"github.com/aws-cloudformation/rain/internal/cmd/bucket"
 	"github.com/aws-cloudformation/rain/internal/cmd/build"
 	"github.com/aws-cloudformation/rain/internal/cmd/cat"
 	"github.com/aws-cloudformation/rain/internal/cmd/cc"
+	"github.com/aws-cloudformation/rain/internal/cmd/changeset"
+	configcmd "github.com/aws-cloudformation/rain/internal/cmd/config"
 	consolecmd "github.com/aws-cloudformation/rain/internal/cmd/console"
+	"github.com/aws-cloudformation/rain/internal/cmd/cost"
 	"github.com/aws-cloudformation/rain/internal/cmd/deploy"
 	"github.com/aws-cloudformation/rain/internal/cmd/diff"
+	"github.com/aws-cloudformation/rain/internal/cmd/docs"
+	"github.com/aws-cloudformation/rain/internal/cmd/drift"
+	"github.com/aws-cloudformation/rain/internal/cmd/eval"
+	"github.com/aws-cloudformation/rain/internal/cmd/explainfailure"
+	"github.com/aws-cloudformation/rain/internal/cmd/exportpipeline"
 	rainfmt "github.com/aws-cloudformation/rain/internal/cmd/fmt"
 	"github.com/aws-cloudformation/rain/internal/cmd/forecast"
+	"github.com/aws-cloudformation/rain/internal/cmd/generate"
+	"github.com/aws-cloudformation/rain/internal/cmd/importer"
 	"github.com/aws-cloudformation/rain/internal/cmd/info"
+	"github.com/aws-cloudformation/rain/internal/cmd/lint"
 	"github.com/aws-cloudformation/rain/internal/cmd/logs"
 	"github.com/aws-cloudformation/rain/internal/cmd/ls"
 	"github.com/aws-cloudformation/rain/internal/cmd/merge"
 	"github.com/aws-cloudformation/rain/internal/cmd/module"
 	"github.com/aws-cloudformation/rain/internal/cmd/pkg"
+	"github.com/aws-cloudformation/rain/internal/cmd/protect"
+	"github.com/aws-cloudformation/rain/internal/cmd/pull"
+	"github.com/aws-cloudformation/rain/internal/cmd/push"
+	"github.com/aws-cloudformation/rain/internal/cmd/recipes"
+	"github.com/aws-cloudformation/rain/internal/cmd/refactor"
 	"github.com/aws-cloudformation/rain/internal/cmd/rm"
+	"github.com/aws-cloudformation/rain/internal/cmd/scan"
+	"github.com/aws-cloudformation/rain/internal/cmd/schemas"
+	"github.com/aws-cloudformation/rain/internal/cmd/score"
+	"github.com/aws-cloudformation/rain/internal/cmd/search"
+	"github.com/aws-cloudformation/rain/internal/cmd/similar"
+	"github.com/aws-cloudformation/rain/internal/cmd/spec"
 	"github.com/aws-cloudformation/rain/internal/cmd/stackset"
+	"github.com/aws-cloudformation/rain/internal/cmd/summary"
 	"github.com/aws-cloudformation/rain/internal/cmd/tree"
 	"github.com/aws-cloudformation/rain/internal/cmd/watch"
 	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
 )
 
 // Cmd is the rain command's entrypoint
@@ -35,6 +68,57 @@ var Cmd = &cobra.Command{
 	Use:     "rain",
 	Long:    "Rain is a command line tool for working with AWS CloudFormation templates and stacks",
 	Version: config.VERSION,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		startOutput(cmd)
+	},
+}
+
+// emitters are the commands that write their results as events, with all of their subcommands,
+// so they are the only commands that can be run with --output-format json
+var emitters = []*cobra.Command{
+	cost.Cmd, deploy.Cmd, diff.Cmd, docs.Cmd, drift.Cmd, info.Cmd, lint.Cmd, ls.Cmd,
+	rm.Cmd, scan.Cmd, score.Cmd, stackset.StackSetCmd, summary.Cmd, watch.Cmd,
+}
+
+// emits returns true if the command, or the command it belongs to, writes its results as events
+func emits(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if slices.Contains(emitters, c) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// startOutput turns on structured output if --output-format json is set.
+// Events are then written to stdout, and the human output to stderr without colour or spinners.
+// Commands that don't write events can't be run with json, as their results would only go to stderr.
+func startOutput(cmd *cobra.Command) {
+	if err := emit.Start(); err != nil {
+		panic(err)
+	}
+
+	if !emit.Enabled() {
+		return
+	}
+
+	console.NoColour = true
+	console.PlainOutput = true
+
+	if !emits(cmd) {
+		panic(fmt.Errorf("rain %s doesn't support --output-format %s; it supports %s", cmd.Name(), emit.JSON, emitterNames()))
+	}
+}
+
+// emitterNames lists the commands that can be run with --output-format json
+func emitterNames() string {
+	names := make([]string, len(emitters))
+	for i, c := range emitters {
+		names[i] = c.Name()
+	}
+
+	return strings.Join(names, ", ")
 }
 
 const usageTemplate = `Usage:{{if .Runnable}}
@@ -58,7 +142,8 @@ Flags:
 
 Additional help topics:{{range .Commands}}{{if .IsAdditionalHelpTopicCommand}}
   {{rpad .CommandPath .CommandPathPadding}} {{.Short}}{{end}}{{end}}{{end}}
-`
+
+` + exitcode.Help + "\n"
 
 const stackGroup = "Stack commands"
 const templateGroup = "Template commands"
@@ -83,29 +168,60 @@ func addCommand(label string, profileOptions, bucketOptions bool, c *cobra.Comma
 
 func init() {
 	// Stack commands
+	addCommand(stackGroup, true, false, adopt.Cmd)
 	addCommand(stackGroup, true, false, cat.Cmd)
 	addCommand(stackGroup, true, true, deploy.Cmd)
 	addCommand(stackGroup, true, true, cc.Cmd)
+	addCommand(stackGroup, false, false, changeset.Cmd)
+	addCommand(stackGroup, true, false, drift.Cmd)
+	addCommand(stackGroup, true, false, explainfailure.Cmd)
+	addCommand(stackGroup, true, true, exportpipeline.Cmd)
+	addCommand(stackGroup, true, false, importer.Cmd)
 	addCommand(stackGroup, true, false, logs.Cmd)
 	addCommand(stackGroup, true, false, ls.Cmd)
+	addCommand(stackGroup, true, false, refactor.MvCmd)
+	addCommand(stackGroup, true, false, protect.Cmd)
+	addCommand(stackGroup, true, false, refactor.Cmd)
 	addCommand(stackGroup, true, false, rm.Cmd)
+	addCommand(stackGroup, true, false, search.Cmd)
 	addCommand(stackGroup, true, false, watch.Cmd)
 	addCommand(stackGroup, true, false, stackset.StackSetCmd)
 
 	// Template commands
 	addCommand(templateGroup, true, false, bootstrap.Cmd)
 	addCommand(templateGroup, true, false, build.Cmd)
-	addCommand(templateGroup, false, false, diff.Cmd)
+	addCommand(templateGroup, true, false, cost.Cmd)
+	addCommand(templateGroup, true, false, diff.Cmd)
+	addCommand(templateGroup, false, false, docs.Cmd)
+	addCommand(templateGroup, false, false, eval.Cmd)
 	addCommand(templateGroup, false, false, rainfmt.Cmd)
+	addCommand(templateGroup, true, false, lint.Cmd)
 	addCommand(templateGroup, false, false, merge.Cmd)
 	addCommand(templateGroup, true, true, pkg.Cmd)
-	addCommand(templateGroup, false, false, tree.Cmd)
+	addCommand(templateGroup, false, false, pull.Cmd)
+	addCommand(templateGroup, false, false, push.Cmd)
+	addCommand(templateGroup, false, false, recipes.Cmd)
+	addCommand(templateGroup, false, false, scan.Cmd)
+	addCommand(templateGroup, false, false, score.Cmd)
+	addCommand(templateGroup, false, false, similar.Cmd)
+	addCommand(templateGroup, false, false, spec.Cmd)
+	addCommand(templateGroup, true, false, summary.Cmd)
+	addCommand(templateGroup, true, false, tree.Cmd)
 	addCommand(templateGroup, true, false, forecast.Cmd)
+	addCommand(templateGroup, true, false, generate.Cmd)
 	addCommand(templateGroup, true, false, module.Cmd)
 
 	// Other commands
+	addCommand("", false, false, bench.Cmd)
+	addCommand("", false, false, bucket.Cmd)
+	addCommand("", false, false, configcmd.Cmd)
 	addCommand("", true, false, consolecmd.Cmd)
 	addCommand("", true, false, info.Cmd)
+	addCommand("", false, false, schemas.Cmd)
+
+	// Resolve secret references in parameter values
+	dc.LookupSecret = secretsmanager.GetSecretValue
+	dc.LookupSSMParameter = ssm.GetSecureParameter
 
 	// Customise usage
 	Cmd.Annotations = map[string]string{"Groups": fmt.Sprintf("%s|%s", stackGroup, templateGroup)}
@@ -120,7 +236,16 @@ func init() {
 		return oldUsageFunc(c)
 	})
 
-	Cmd.PersistentFlags().BoolVarP(&console.NoColour, "no-colour", "", false, "Disable colour output")
+	Cmd.PersistentFlags().BoolVarP(&console.NoColour, "no-colour", "", console.NoColour, "Disable colour output (also set by the NO_COLOR environment variable)")
+	Cmd.PersistentFlags().BoolVar(&console.NoColour, "no-color", console.NoColour, "Disable colour output (also set by the NO_COLOR environment variable)")
+	Cmd.PersistentFlags().BoolVarP(&console.Quiet, "quiet", "q", false, "Hide progress such as spinners and status lines; only show results, warnings and errors")
+	Cmd.PersistentFlags().BoolVar(&console.PlainOutput, "plain", console.PlainOutput, "Show progress as timestamped lines instead of spinners, for screen readers and logs")
+	// This isn't --output, which several commands already use for the file they write to
+	Cmd.PersistentFlags().StringVar(&emit.Format, "output-format", emit.Format, "Output format: text, or json to write events and results to stdout as newline-delimited JSON (also set by RAIN_OUTPUT)")
+	Cmd.PersistentFlags().BoolVar(&config.Offline, "offline", config.Offline, "Use cached resource schemas instead of calling the CloudFormation registry")
+	Cmd.PersistentFlags().IntVar(&config.MaxRetries, "max-retries", config.MaxRetries, "Retry throttled or failed AWS calls up to this many times; 0 uses the AWS configuration's max_attempts or the SDK default (also set by RAIN_MAX_RETRIES)")
+	Cmd.PersistentFlags().StringVar(&config.RetryMode, "retry-mode", config.RetryMode, "How to retry AWS calls: adaptive, which slows all calls down when AWS throttles them, or standard (also set by RAIN_RETRY_MODE; default adaptive)")
+	Cmd.PersistentFlags().Float64Var(&config.RateLimit, "rate-limit", config.RateLimit, "Make no more than this many AWS calls each second; 0 for no limit (also set by RAIN_RATE_LIMIT)")
 
 	cmd.AddDefaults(Cmd)
 }
@@ -0,0 +1,200 @@
+package recipes
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var logicalId string
+var modulesDir string
+var params []string
+
+var addCmd = &cobra.Command{
+	Use:   "add <name> <template>",
+	Short: "Add a recipe to a template",
+	Long: `Copies the recipe's module into the modules directory next to <template>,
+and adds a resource to <template> that uses it.
+
+Each of the recipe's parameters is set from --params if it is given there.
+Otherwise, the template gets a parameter of the same name, with the recipe's default,
+so that the value can be chosen when the stack is deployed.
+
+Recipes are Rain modules, which are experimental.
+Package the template with rain pkg -x, or deploy it with rain deploy --experimental.`,
+	Args:                  cobra.ExactArgs(2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		name, fn := args[0], args[1]
+
+		r, err := find(name)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to add recipe"))
+		}
+
+		t, err := parse.File(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		id := logicalId
+		if id == "" {
+			id = defaultLogicalId(r.name)
+		}
+
+		modulePath := filepath.ToSlash(filepath.Join(modulesDir, r.name+".yaml"))
+
+		added, err := insert(t, r, id, modulePath, dc.ListToMap("params", params))
+		if err != nil {
+			panic(ui.Errorf(err, "unable to add recipe '%s' to '%s'", r.name, fn))
+		}
+
+		dest := filepath.Join(filepath.Dir(fn), filepath.FromSlash(modulePath))
+		if existing, err := os.ReadFile(dest); err == nil && !bytes.Equal(existing, r.content) {
+			panic(fmt.Errorf("'%s' already exists and is not the same as recipe '%s'", dest, r.name))
+		}
+
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			panic(ui.Errorf(err, "unable to create '%s'", filepath.Dir(dest)))
+		}
+
+		if err := os.WriteFile(dest, r.content, 0644); err != nil {
+			panic(ui.Errorf(err, "unable to write '%s'", dest))
+		}
+
+		out := format.String(t, format.Options{JSON: strings.HasSuffix(fn, ".json")})
+		if err := os.WriteFile(fn, []byte(out), 0644); err != nil {
+			panic(ui.Errorf(err, "unable to write '%s'", fn))
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Added recipe '%s' to %s as %s, using module %s", r.name, fn, id, dest)))
+
+		if len(added) > 0 {
+			fmt.Println(console.Grey("Added parameters: " + strings.Join(added, ", ")))
+		}
+
+		fmt.Println(console.Grey("Package the template with rain pkg -x, or deploy it with rain deploy --experimental"))
+	},
+}
+
+// defaultLogicalId returns the logical ID of a recipe's resource, e.g. AlbAsg for alb-asg
+func defaultLogicalId(name string) string {
+	var out strings.Builder
+
+	for _, word := range strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		out.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+
+	return out.String()
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+func mapping(pairs ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Content: pairs}
+}
+
+// propertyValue returns the value of a module property that is set on the command line.
+// Lists are separated by commas.
+func propertyValue(def *yaml.Node, value string) *yaml.Node {
+	typ := ""
+	if _, t, _ := s11n.GetMapValue(def, "Type"); t != nil {
+		typ = t.Value
+	}
+
+	if strings.HasPrefix(typ, "List<") || typ == "CommaDelimitedList" {
+		seq := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, v := range strings.Split(value, ",") {
+			seq.Content = append(seq.Content, scalar(strings.TrimSpace(v)))
+		}
+		return seq
+	}
+
+	return scalar(value)
+}
+
+// insert adds a resource named logicalId to the template, which uses the recipe's module at modulePath.
+// Parameters of the recipe that are not in values are set from template parameters of the same name,
+// which are added if the template doesn't have them. insert returns the names of the added parameters.
+func insert(t cft.Template, r recipe, logicalId string, modulePath string, values map[string]string) ([]string, error) {
+	names, defs, err := r.parameters()
+	if err != nil {
+		return nil, err
+	}
+
+	for name := range values {
+		if _, ok := defs[name]; !ok {
+			return nil, fmt.Errorf("recipe '%s' has no parameter '%s'", r.name, name)
+		}
+	}
+
+	resources, err := t.GetSection(cft.Resources)
+	if err != nil {
+		resources, err = t.AddMapSection(cft.Resources)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := t.GetResource(logicalId); err == nil {
+		return nil, fmt.Errorf("the template already has a resource named '%s'; choose another with --as", logicalId)
+	}
+
+	added := make([]string, 0)
+	props := mapping()
+
+	for _, name := range names {
+		if v, ok := values[name]; ok {
+			props.Content = append(props.Content, scalar(name), propertyValue(defs[name], v))
+			continue
+		}
+
+		if _, err := t.GetParameter(name); err != nil {
+			section, err := t.GetSection(cft.Parameters)
+			if err != nil {
+				section, err = t.AddMapSection(cft.Parameters)
+				if err != nil {
+					return nil, err
+				}
+			}
+
+			section.Content = append(section.Content, scalar(name), node.Clone(defs[name]))
+			added = append(added, name)
+		}
+
+		props.Content = append(props.Content, scalar(name), mapping(scalar("Ref"), scalar(name)))
+	}
+
+	resource := mapping(scalar("Type"), mapping(scalar("Rain::Module"), scalar(modulePath)))
+	if len(props.Content) > 0 {
+		resource.Content = append(resource.Content, scalar("Properties"), props)
+	}
+
+	resources.Content = append(resources.Content, scalar(logicalId), resource)
+
+	return added, nil
+}
+
+func init() {
+	addCmd.Flags().StringVar(&logicalId, "as", "", "Logical ID of the recipe's resource in the template (default: the recipe's name in PascalCase)")
+	addCmd.Flags().StringVar(&modulesDir, "modules-dir", "modules", "Directory to copy the recipe's module to, relative to the template")
+	addCmd.Flags().StringSliceVar(&params, "params", []string{}, "Set recipe parameters. Use the format key1=value1,key2=value2.")
+}
@@ -0,0 +1,30 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var lsCmd = &cobra.Command{
+	Use:                   "ls",
+	Short:                 "List the recipes",
+	Long:                  "Lists the built-in recipes and the recipes in your recipe directories.",
+	Args:                  cobra.NoArgs,
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, err := list()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to list recipes"))
+		}
+
+		for _, r := range all {
+			fmt.Printf("%s %s\n", console.Yellow(r.name), console.Grey("("+r.source+")"))
+			if d := r.description(); d != "" {
+				fmt.Printf("  %s\n", d)
+			}
+		}
+	},
+}
@@ -0,0 +1,206 @@
+// Package recipes implements the rain recipes command, a gallery of
+// common template snippets that are shipped as Rain modules
+package recipes
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvVar is a list of directories, separated like PATH, with the recipes of a team.
+// Recipes in these directories replace the built-in recipes of the same name.
+const EnvVar = "RAIN_RECIPES"
+
+//go:embed builtin/*.yaml
+var builtin embed.FS
+
+const builtinSource = "built-in"
+
+// recipe is a Rain module that can be added to a template
+type recipe struct {
+	name string
+
+	// source is the file the recipe was read from, or builtinSource
+	source string
+
+	content []byte
+}
+
+// template returns the recipe's module
+func (r recipe) template() (cft.Template, error) {
+	t, err := parse.String(string(r.content))
+	if err != nil {
+		return t, fmt.Errorf("unable to parse recipe '%s' from %s: %w", r.name, r.source, err)
+	}
+
+	return t, nil
+}
+
+// description returns the recipe's Description on a single line
+func (r recipe) description() string {
+	t, err := r.template()
+	if err != nil {
+		return ""
+	}
+
+	_, d, _ := s11n.GetMapValue(t.Node.Content[0], string(cft.Description))
+	if d == nil {
+		return ""
+	}
+
+	return strings.Join(strings.Fields(d.Value), " ")
+}
+
+// parameters returns the names and definitions of the recipe's parameters, in order
+func (r recipe) parameters() ([]string, map[string]*yaml.Node, error) {
+	t, err := r.template()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	names := make([]string, 0)
+	defs := make(map[string]*yaml.Node)
+
+	params, err := t.GetSection(cft.Parameters)
+	if err != nil {
+		return names, defs, nil
+	}
+
+	for i := 0; i < len(params.Content)-1; i += 2 {
+		name := params.Content[i].Value
+		names = append(names, name)
+		defs[name] = params.Content[i+1]
+	}
+
+	return names, defs, nil
+}
+
+// dirs returns the directories that recipes are read from, in order of precedence
+func dirs() []string {
+	out := make([]string, 0)
+
+	for _, dir := range filepath.SplitList(os.Getenv(EnvVar)) {
+		if dir != "" {
+			out = append(out, dir)
+		}
+	}
+
+	if dir, err := os.UserConfigDir(); err == nil {
+		out = append(out, filepath.Join(dir, "rain", "recipes"))
+	}
+
+	return out
+}
+
+// recipeName returns the name of the recipe in a file, or "" if the file is not a recipe
+func recipeName(fn string) string {
+	ext := filepath.Ext(fn)
+	if ext != ".yaml" && ext != ".yml" && ext != ".json" {
+		return ""
+	}
+
+	return strings.TrimSuffix(filepath.Base(fn), ext)
+}
+
+// list returns every recipe, sorted by name.
+// A recipe in a directory replaces the built-in recipe, or the recipe in a later directory, of the same name.
+func list() ([]recipe, error) {
+	byName := make(map[string]recipe)
+
+	entries, err := builtin.ReadDir("builtin")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range entries {
+		content, err := builtin.ReadFile("builtin/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		name := recipeName(e.Name())
+		byName[name] = recipe{name: name, source: builtinSource, content: content}
+	}
+
+	ds := dirs()
+	for i := len(ds) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(ds[i])
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, e := range entries {
+			name := recipeName(e.Name())
+			if e.IsDir() || name == "" {
+				continue
+			}
+
+			fn := filepath.Join(ds[i], e.Name())
+			content, err := os.ReadFile(fn)
+			if err != nil {
+				return nil, err
+			}
+
+			byName[name] = recipe{name: name, source: fn, content: content}
+		}
+	}
+
+	out := make([]recipe, 0, len(byName))
+	for _, r := range byName {
+		out = append(out, r)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].name < out[j].name
+	})
+
+	return out, nil
+}
+
+// find returns the named recipe
+func find(name string) (recipe, error) {
+	all, err := list()
+	if err != nil {
+		return recipe{}, err
+	}
+
+	for _, r := range all {
+		if r.name == name {
+			return r, nil
+		}
+	}
+
+	return recipe{}, fmt.Errorf("no recipe named '%s'; run rain recipes ls to see the recipes", name)
+}
+
+// Cmd is the recipes command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "recipes <command>",
+	Short: "Add common snippets, such as a VPC, to templates",
+	Long: `Recipes are Rain modules for common pieces of infrastructure, such as a VPC,
+a load balancer with an auto scaling group, a static website, or a Lambda function behind an API.
+
+Rain comes with a few recipes. Add your own, or your team's, by putting Rain modules in
+` + "`<config dir>/rain/recipes`" + ` (e.g. ~/.config/rain/recipes), or in the directories listed
+in the ` + EnvVar + ` environment variable, which is separated like PATH.
+The name of a recipe is its file name without the extension.
+A recipe replaces any built-in recipe of the same name.`,
+}
+
+func init() {
+	Cmd.AddCommand(lsCmd)
+	Cmd.AddCommand(showCmd)
+	Cmd.AddCommand(addCmd)
+}
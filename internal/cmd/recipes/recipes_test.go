@@ -0,0 +1,156 @@
+package recipes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+// isolate keeps the tests from reading the recipes of the user running them
+func isolate(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvVar, "")
+}
+
+func TestBuiltinRecipes(t *testing.T) {
+	isolate(t)
+
+	all, err := list()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := make([]string, 0)
+	for _, r := range all {
+		names = append(names, r.name)
+
+		if r.source != builtinSource {
+			t.Errorf("recipe '%s' is from %s", r.name, r.source)
+		}
+
+		if r.description() == "" {
+			t.Errorf("recipe '%s' has no description", r.name)
+		}
+
+		m, err := r.template()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := m.GetSection(cft.Resources); err != nil {
+			t.Errorf("recipe '%s' has no resources", r.name)
+		}
+	}
+
+	expected := "alb-asg lambda-api static-site vpc"
+	if got := strings.Join(names, " "); got != expected {
+		t.Errorf("expected recipes %s, got %s", expected, got)
+	}
+}
+
+func TestDirectoryReplacesBuiltin(t *testing.T) {
+	isolate(t)
+
+	team := t.TempDir()
+	t.Setenv(EnvVar, team)
+
+	fn := filepath.Join(team, "vpc.yaml")
+	if err := os.WriteFile(fn, []byte("Description: Our VPC\nResources:\n  VPC:\n    Type: AWS::EC2::VPC\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(team, "README.md"), []byte("not a recipe"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := find("vpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.source != fn || r.description() != "Our VPC" {
+		t.Errorf("expected the team's vpc recipe, got %s: %s", r.source, r.description())
+	}
+
+	if _, err := find("README"); err == nil {
+		t.Error("expected README.md not to be a recipe")
+	}
+
+	if _, err := find("lambda-api"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInsert(t *testing.T) {
+	isolate(t)
+
+	r, err := find("alb-asg")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl, err := parse.String(`
+Parameters:
+  VpcId:
+    Type: AWS::EC2::VPC::Id
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	added, err := insert(tmpl, r, "Web", "modules/alb-asg.yaml", map[string]string{
+		"PublicSubnets": "subnet-1, subnet-2",
+		"InstanceType":  "t3.small",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(added, ","); got != "PrivateSubnets,ImageId" {
+		t.Errorf("unexpected added parameters: %s", got)
+	}
+
+	out := format.String(tmpl, format.Options{})
+
+	for _, expected := range []string{
+		`    Type: !Rain::Module modules/alb-asg.yaml`,
+		`      VpcId: !Ref VpcId`,
+		`        - subnet-1`,
+		`      InstanceType: t3.small`,
+		`      ImageId: !Ref ImageId`,
+		`    Default: /aws/service/ami-amazon-linux-latest/al2023-ami-kernel-default-x86_64`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected output to contain %q:\n%s", expected, out)
+		}
+	}
+
+	if _, err := insert(tmpl, r, "Web", "modules/alb-asg.yaml", nil); err == nil {
+		t.Error("expected an error when the logical ID is taken")
+	}
+
+	if _, err := insert(tmpl, r, "Web2", "modules/alb-asg.yaml", map[string]string{"Nope": "x"}); err == nil {
+		t.Error("expected an error for an unknown parameter")
+	}
+}
+
+func TestDefaultLogicalId(t *testing.T) {
+	for name, expected := range map[string]string{
+		"vpc":         "Vpc",
+		"alb-asg":     "AlbAsg",
+		"static_site": "StaticSite",
+	} {
+		if got := defaultLogicalId(name); got != expected {
+			t.Errorf("%s: expected %s, got %s", name, expected, got)
+		}
+	}
+}
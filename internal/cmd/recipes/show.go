@@ -0,0 +1,24 @@
+package recipes
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var showCmd = &cobra.Command{
+	Use:                   "show <name>",
+	Short:                 "Print a recipe",
+	Long:                  "Prints the Rain module of a recipe, so that you can see its parameters and resources.",
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		r, err := find(args[0])
+		if err != nil {
+			panic(ui.Errorf(err, "unable to show recipe"))
+		}
+
+		fmt.Print(string(r.content))
+	},
+}
@@ -0,0 +1,248 @@
+package refactor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/graph"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// checkMove returns an error that lists every reason the resources can't be moved
+// from the source template to the destination template, or nil if they can.
+//
+// The moved resources may only refer to each other and to pseudo parameters,
+// since the parameters, conditions and mappings of the source stack don't exist in the destination,
+// and nothing that stays in the source stack may refer to them.
+func checkMove(source, dest cft.Template, ids []string) error {
+	problems := make([]string, 0)
+
+	if _, err := source.GetSection(cft.Transform); err == nil {
+		problems = append(problems, "the source template has a Transform, so rain can't change it without changing what the transform generates")
+	}
+
+	if dest.Node != nil {
+		if _, err := dest.GetSection(cft.Transform); err == nil {
+			problems = append(problems, "the destination template has a Transform, so rain can't change it without changing what the transform generates")
+		}
+	}
+
+	moving := make(map[string]bool)
+	for _, id := range ids {
+		moving[id] = true
+	}
+
+	g := graph.New(source)
+
+	for _, id := range ids {
+		resource, err := source.GetResource(id)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s is not in the source stack", id))
+			continue
+		}
+
+		if dest.Node != nil {
+			if _, err := dest.GetResource(id); err == nil {
+				problems = append(problems, fmt.Sprintf("the destination stack already has a resource named %s", id))
+			}
+		}
+
+		if _, c, _ := s11n.GetMapValue(resource, "Condition"); c != nil {
+			problems = append(problems, fmt.Sprintf("%s has a Condition, which is not in the destination stack", id))
+		}
+
+		for _, f := range functions(resource, "Fn::If", "Fn::FindInMap") {
+			problems = append(problems, fmt.Sprintf("%s uses %s, which refers to a part of the source template that is not in the destination stack", id, f))
+		}
+
+		for _, to := range g.Get(graph.Node{Type: string(cft.Resources), Name: id}) {
+			switch {
+			case to.Type == string(cft.Parameters) && strings.HasPrefix(to.Name, "AWS::"):
+				continue
+			case to.Type == string(cft.Resources) && moving[to.Name]:
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s refers to %s, which stays in the source stack", id, to))
+		}
+
+		for _, from := range g.GetReverse(graph.Node{Type: string(cft.Resources), Name: id}) {
+			if from.Type == string(cft.Resources) && moving[from.Name] {
+				continue
+			}
+			problems = append(problems, fmt.Sprintf("%s, which stays in the source stack, refers to %s", from, id))
+		}
+	}
+
+	if resources, err := source.GetSection(cft.Resources); err == nil && len(resources.Content) <= 2*len(moving) {
+		problems = append(problems, "the source stack would have no resources left; CloudFormation needs at least one, so delete the stack once the resources are retained instead")
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(problems, "\n"))
+}
+
+// functions returns the names of the intrinsic functions in n that are one of names
+func functions(n *yaml.Node, names ...string) []string {
+	found := make([]string, 0)
+
+	var walk func(*yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.MappingNode {
+			for i := 0; i < len(n.Content); i += 2 {
+				key := n.Content[i].Value
+				if slices.Contains(names, key) && !slices.Contains(found, key) {
+					found = append(found, key)
+				}
+			}
+		}
+
+		for _, c := range n.Content {
+			walk(c)
+		}
+	}
+	walk(n)
+
+	return found
+}
+
+// retain returns a copy of the template in which the resources have
+// Retain deletion and update replace policies, so that they are kept when they are
+// removed from the stack. It also returns whether any policy had to change.
+func retain(t cft.Template, ids []string) (cft.Template, bool) {
+	out := cft.Template{Node: node.Clone(t.Node)}
+	changed := false
+
+	for _, id := range ids {
+		resource, err := out.GetResource(id)
+		if err != nil {
+			continue
+		}
+
+		for _, policy := range []string{"DeletionPolicy", "UpdateReplacePolicy"} {
+			_, v, _ := s11n.GetMapValue(resource, policy)
+			if v != nil && v.Value == "Retain" {
+				continue
+			}
+
+			node.SetMapValue(resource, policy, &yaml.Node{Kind: yaml.ScalarNode, Value: "Retain"})
+			changed = true
+		}
+	}
+
+	return out, changed
+}
+
+// remove returns a copy of the template without the resources
+func remove(t cft.Template, ids []string) cft.Template {
+	out := cft.Template{Node: node.Clone(t.Node)}
+
+	resources, err := out.GetSection(cft.Resources)
+	if err != nil {
+		return out
+	}
+
+	for _, id := range ids {
+		node.RemoveFromMap(resources, id)
+	}
+
+	return out
+}
+
+// add returns a copy of the destination template with the resources from the source template added,
+// with the Retain policies that CloudFormation requires for every resource that is imported.
+// If dest has no Node, the stack doesn't exist yet and the template only has the resources.
+func add(dest, source cft.Template, ids []string) (cft.Template, error) {
+	var out cft.Template
+	if dest.Node == nil {
+		out = cft.Template{Node: &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode}},
+		}}
+	} else {
+		out = cft.Template{Node: node.Clone(dest.Node)}
+	}
+
+	resources, err := out.GetSection(cft.Resources)
+	if err != nil {
+		if resources, err = out.AddMapSection(cft.Resources); err != nil {
+			return out, err
+		}
+	}
+
+	retained, _ := retain(source, ids)
+
+	for _, id := range ids {
+		resource, err := retained.GetResource(id)
+		if err != nil {
+			return out, err
+		}
+
+		node.SetMapValue(resources, id, resource)
+	}
+
+	return out, nil
+}
+
+var logicalIdPattern = regexp.MustCompile(`^[A-Za-z0-9]{1,255}$`)
+
+// checkRename returns an error that lists every reason the resource can't be given a new logical ID,
+// or nil if it can. Nothing else in the template may refer to the resource,
+// since a reference can't follow it while it is out of the stack.
+func checkRename(t cft.Template, oldId, newId string) error {
+	problems := make([]string, 0)
+
+	if !logicalIdPattern.MatchString(newId) {
+		problems = append(problems, fmt.Sprintf("%s is not a valid logical ID; use up to 255 letters and digits", newId))
+	}
+
+	if _, err := t.GetSection(cft.Transform); err == nil {
+		problems = append(problems, "the template has a Transform, so rain can't change it without changing what the transform generates")
+	}
+
+	if _, err := t.GetResource(oldId); err != nil {
+		problems = append(problems, fmt.Sprintf("%s is not in the stack", oldId))
+	}
+
+	if _, err := t.GetResource(newId); err == nil {
+		problems = append(problems, fmt.Sprintf("the stack already has a resource named %s", newId))
+	}
+
+	g := graph.New(t)
+	for _, from := range g.GetReverse(graph.Node{Type: string(cft.Resources), Name: oldId}) {
+		problems = append(problems, fmt.Sprintf("%s refers to %s", from, oldId))
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return errors.New(strings.Join(problems, "\n"))
+}
+
+// rename returns a copy of the template with the resource under its new logical ID,
+// in the same place in the Resources section
+func rename(t cft.Template, oldId, newId string) cft.Template {
+	out := cft.Template{Node: node.Clone(t.Node)}
+
+	resources, err := out.GetSection(cft.Resources)
+	if err != nil {
+		return out
+	}
+
+	for i := 0; i < len(resources.Content); i += 2 {
+		if resources.Content[i].Value == oldId {
+			resources.Content[i].Value = newId
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,216 @@
+package refactor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+)
+
+const source = `
+Parameters:
+  Env:
+    Type: String
+Conditions:
+  IsProd: !Equals [!Ref Env, prod]
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    DeletionPolicy: Delete
+  Policy:
+    Type: AWS::S3::BucketPolicy
+    Properties:
+      Bucket: !Ref Bucket
+      PolicyDocument: {}
+  Table:
+    Type: AWS::DynamoDB::Table
+    Properties:
+      TableName: !Sub ${Env}-table
+  Queue:
+    Type: AWS::SQS::Queue
+    Condition: IsProd
+  Topic:
+    Type: AWS::SNS::Topic
+    Properties:
+      TopicName: !Sub ${AWS::StackName}-topic
+Outputs:
+  TopicArn:
+    Value: !Ref Topic
+`
+
+func parseTemplate(t *testing.T, s string) cft.Template {
+	tmpl, err := parse.String(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tmpl
+}
+
+func TestCheckMove(t *testing.T) {
+	src := parseTemplate(t, source)
+	dest := parseTemplate(t, "Resources:\n  Policy:\n    Type: AWS::SNS::Topic\n")
+
+	cases := []struct {
+		ids      []string
+		problems []string
+	}{
+		{[]string{"Bucket", "Policy"}, nil},
+		{[]string{"Bucket"}, []string{"Resources/Policy, which stays in the source stack, refers to Bucket"}},
+		{[]string{"Policy"}, []string{"Policy refers to Resources/Bucket, which stays in the source stack"}},
+		{[]string{"Table"}, []string{"Table refers to Parameters/Env"}},
+		{[]string{"Queue"}, []string{"Queue has a Condition"}},
+		{[]string{"Topic"}, []string{"Outputs/TopicArn, which stays in the source stack, refers to Topic"}},
+		{[]string{"Missing"}, []string{"Missing is not in the source stack"}},
+	}
+
+	for _, c := range cases {
+		err := checkMove(src, cft.Template{}, c.ids)
+		if len(c.problems) == 0 {
+			if err != nil {
+				t.Errorf("%v: unexpected error: %v", c.ids, err)
+			}
+			continue
+		}
+
+		if err == nil {
+			t.Errorf("%v: expected an error", c.ids)
+			continue
+		}
+
+		for _, p := range c.problems {
+			if !strings.Contains(err.Error(), p) {
+				t.Errorf("%v: expected '%s' in:\n%s", c.ids, p, err)
+			}
+		}
+	}
+
+	if err := checkMove(src, dest, []string{"Bucket", "Policy"}); err == nil ||
+		!strings.Contains(err.Error(), "already has a resource named Policy") {
+		t.Errorf("expected a clash with the destination, got %v", err)
+	}
+}
+
+func TestRetainAndRemove(t *testing.T) {
+	src := parseTemplate(t, source)
+
+	retained, changed := retain(src, []string{"Bucket"})
+	if !changed {
+		t.Error("expected the policies to change")
+	}
+
+	bucket, _ := retained.GetResource("Bucket")
+	if out := format.String(cft.Template{Node: bucket}, format.Options{}); !strings.Contains(out, "DeletionPolicy: Retain") ||
+		!strings.Contains(out, "UpdateReplacePolicy: Retain") {
+		t.Errorf("expected Retain policies:\n%s", out)
+	}
+
+	if original, _ := src.GetResource("Bucket"); strings.Contains(format.String(cft.Template{Node: original}, format.Options{}), "Retain") {
+		t.Error("the source template should not change")
+	}
+
+	if _, changed := retain(retained, []string{"Bucket"}); changed {
+		t.Error("expected no change to resources that are already retained")
+	}
+
+	removed := remove(retained, []string{"Bucket", "Policy"})
+	if _, err := removed.GetResource("Bucket"); err == nil {
+		t.Error("expected Bucket to be removed")
+	}
+	if _, err := removed.GetResource("Table"); err != nil {
+		t.Error("expected Table to stay")
+	}
+}
+
+func TestAdd(t *testing.T) {
+	src := parseTemplate(t, source)
+
+	out, err := add(cft.Template{}, src, []string{"Bucket", "Policy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `Resources:
+  Bucket:
+    DeletionPolicy: Retain
+    UpdateReplacePolicy: Retain
+    Type: AWS::S3::Bucket
+
+  Policy:
+    DeletionPolicy: Retain
+    UpdateReplacePolicy: Retain
+    Type: AWS::S3::BucketPolicy
+    Properties:
+      Bucket: !Ref Bucket
+      PolicyDocument: {}
+`
+
+	if actual := format.String(out, format.Options{}); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	dest := parseTemplate(t, "Resources:\n  Existing:\n    Type: AWS::SNS::Topic\n")
+	out, err = add(dest, src, []string{"Bucket"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := out.GetResource("Existing"); err != nil {
+		t.Error("expected the destination's resources to stay")
+	}
+	if _, err := dest.GetResource("Bucket"); err == nil {
+		t.Error("the destination template should not change")
+	}
+}
+
+func TestCheckRename(t *testing.T) {
+	src := parseTemplate(t, source)
+
+	if err := checkRename(src, "Table", "Orders"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		oldId   string
+		newId   string
+		problem string
+	}{
+		{"Bucket", "Data", "Resources/Policy refers to Bucket"},
+		{"Table", "Bucket", "already has a resource named Bucket"},
+		{"Table", "orders-table", "not a valid logical ID"},
+		{"Missing", "Orders", "Missing is not in the stack"},
+	}
+
+	for _, c := range cases {
+		err := checkRename(src, c.oldId, c.newId)
+		if err == nil || !strings.Contains(err.Error(), c.problem) {
+			t.Errorf("%s to %s: expected '%s', got %v", c.oldId, c.newId, c.problem, err)
+		}
+	}
+}
+
+func TestRename(t *testing.T) {
+	src := parseTemplate(t, "Resources:\n  A:\n    Type: AWS::SNS::Topic\n  B:\n    Type: AWS::SQS::Queue\n  C:\n    Type: AWS::SNS::Topic\n")
+
+	out := rename(src, "B", "Queue")
+
+	expected := `Resources:
+  A:
+    Type: AWS::SNS::Topic
+
+  Queue:
+    Type: AWS::SQS::Queue
+
+  C:
+    Type: AWS::SNS::Topic
+`
+
+	if actual := format.String(out, format.Options{}); actual != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, actual)
+	}
+
+	if _, err := src.GetResource("B"); err != nil {
+		t.Error("the original template should not change")
+	}
+}
@@ -0,0 +1,119 @@
+package refactor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+// MvCmd is the mv command's entrypoint
+var MvCmd = &cobra.Command{
+	Use:   "mv <stack> <old logical id> <new logical id>",
+	Short: "Rename a resource in a stack without replacing it",
+	Long: `Gives a resource a new logical ID without deleting or recreating it.
+
+Changing a logical ID in a template makes CloudFormation create a new resource and delete the old one.
+Instead, rain mv takes these steps:
+
+  1. The stack is updated to give the resource Retain deletion and update replace policies
+  2. The resource is removed from the stack, which leaves it in place
+  3. The resource is imported back into the stack with its new logical ID
+  4. The stack is checked to make sure that the resource has the same physical ID
+  5. The resource's original deletion and update replace policies are restored
+
+The resource's type must support import, and nothing else in the stack may refer to the resource,
+since a reference can't follow it while it is out of the stack. Update the template you deploy
+with the new logical ID once the resource has been renamed.
+
+Use --dry-run to see the plan without changing the stack.`,
+	Args:                  cobra.ExactArgs(3),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		stackName, oldId, newId := args[0], args[1], args[2]
+
+		spinner.Push(fmt.Sprintf("Reading stack '%s'", stackName))
+		stack, err := cfn.GetStack(stackName)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read stack '%s'", stackName))
+		}
+
+		template, err := stackTemplate(stackName)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read the template of stack '%s'", stackName))
+		}
+		spinner.Pop()
+
+		if err := checkRename(template, oldId, newId); err != nil {
+			panic(ui.Errorf(err, "unable to rename %s to %s", oldId, newId))
+		}
+
+		imports := resourcesToImport(stackName, []string{oldId})
+		imports[0].LogicalResourceId = ptr.String(newId)
+
+		retained, changed := retain(template, []string{oldId})
+		removed := remove(retained, []string{oldId})
+		imported := rename(retained, oldId, newId)
+		renamed := rename(template, oldId, newId)
+
+		showResources(fmt.Sprintf("Resource to rename from %s in stack '%s':", oldId, stackName), imports)
+
+		steps := make([]string, 0)
+		if changed {
+			steps = append(steps, fmt.Sprintf("Update stack '%s' to retain %s", stackName, oldId))
+		}
+		steps = append(steps,
+			fmt.Sprintf("Remove %s from stack '%s'", oldId, stackName),
+			fmt.Sprintf("Import the resource into stack '%s' as %s", stackName, newId),
+			"Check that the stack has the expected resource")
+		if changed {
+			steps = append(steps, fmt.Sprintf("Restore the deletion and update replace policies of %s", newId))
+		}
+		showSteps(steps)
+
+		if dryRun {
+			return
+		}
+
+		if !yes && !console.Confirm(false, fmt.Sprintf("Rename %s to %s in stack '%s'?", oldId, newId, stackName)) {
+			panic(errors.New("user cancelled rename"))
+		}
+
+		if changed {
+			if err := update(stack, retained, fmt.Sprintf("Retaining %s", oldId)); err != nil {
+				panic(ui.Errorf(err, "unable to set Retain policies in stack '%s'; nothing has been renamed", stackName))
+			}
+		}
+
+		if err := update(stack, removed, fmt.Sprintf("Removing %s", oldId)); err != nil {
+			panic(ui.Errorf(err, "unable to remove %s from stack '%s'; it is still in the stack, with Retain policies", oldId, stackName))
+		}
+
+		if err := importResources(stackName, imported, imports); err != nil {
+			showOrphans(stackName, stackName, imports)
+			panic(ui.Errorf(err, "unable to import the resource into stack '%s' as %s", stackName, newId))
+		}
+
+		if err := verify(stackName, []string{oldId}, stackName, imports); err != nil {
+			panic(ui.Errorf(err, "the resource was imported into stack '%s', but it doesn't match %s", stackName, oldId))
+		}
+
+		if changed {
+			if err := update(stack, renamed, fmt.Sprintf("Restoring the policies of %s", newId)); err != nil {
+				panic(ui.Errorf(err, "%s was renamed to %s, but its original policies could not be restored; it keeps Retain policies", oldId, newId))
+			}
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Renamed %s to %s in stack '%s'", oldId, newId, stackName)))
+	},
+}
+
+func init() {
+	MvCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't ask before changing the stack")
+	MvCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the plan without changing the stack")
+}
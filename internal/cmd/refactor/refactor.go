@@ -0,0 +1,414 @@
+package refactor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var fromStack string
+var toStack string
+var resourceIds []string
+var yes bool
+var dryRun bool
+
+// Cmd is the refactor command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "refactor --from <stack> --to <stack> --resources <logical id>,...",
+	Short: "Move resources from one stack to another without replacing them",
+	Long: `Moves resources between stacks, without deleting or recreating them.
+
+The resources are moved in four steps:
+
+  1. The source stack is updated to give the resources Retain deletion and update replace policies
+  2. The resources are removed from the source stack, which leaves them in place
+  3. The resources are imported into the destination stack, which is created if it doesn't exist
+  4. Both stacks are checked to make sure that the resources moved, with the same physical IDs
+
+The moved resources keep their logical IDs and their Retain policies in the destination stack.
+They may only refer to each other and to pseudo parameters such as AWS::Region, and nothing that stays
+in the source stack may refer to them, since a reference can't cross from one stack to another.
+Move resources that refer to each other together, or replace the references with exports or parameters first.
+
+The parameters of both stacks keep their previous values. Stacks with a Transform can't be refactored,
+and only resources whose type supports import, and whose primary identifier is their physical ID, can be moved.
+
+To give a resource a new logical ID within a stack, use rain mv.
+
+Use --dry-run to see the plan and the destination template without changing either stack.`,
+	Args:                  cobra.NoArgs,
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if fromStack == "" || toStack == "" || len(resourceIds) == 0 {
+			panic(errors.New("--from, --to and --resources are all required"))
+		}
+
+		if fromStack == toStack {
+			panic(errors.New("--from and --to must be different stacks"))
+		}
+
+		spinner.Push(fmt.Sprintf("Reading stack '%s'", fromStack))
+		source, err := cfn.GetStack(fromStack)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read stack '%s'", fromStack))
+		}
+
+		sourceTemplate, err := stackTemplate(fromStack)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read the template of stack '%s'", fromStack))
+		}
+		spinner.Pop()
+
+		var dest *types.Stack
+		var destTemplate cft.Template
+
+		spinner.Push(fmt.Sprintf("Reading stack '%s'", toStack))
+		if stack, err := cfn.GetStack(toStack); err == nil {
+			dest = &stack
+			destTemplate, err = stackTemplate(toStack)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to read the template of stack '%s'", toStack))
+			}
+		}
+		spinner.Pop()
+
+		if err := checkMove(sourceTemplate, destTemplate, resourceIds); err != nil {
+			panic(ui.Errorf(err, "unable to move the resources from stack '%s' to stack '%s'", fromStack, toStack))
+		}
+
+		imports := resourcesToImport(fromStack, resourceIds)
+
+		retained, changed := retain(sourceTemplate, resourceIds)
+		removed := remove(retained, resourceIds)
+		imported, err := add(destTemplate, sourceTemplate, resourceIds)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to add the resources to the template of stack '%s'", toStack))
+		}
+
+		showResources(fmt.Sprintf("Resources to move from stack '%s' to stack '%s':", fromStack, toStack), imports)
+
+		steps := make([]string, 0)
+		if changed {
+			steps = append(steps, fmt.Sprintf("Update stack '%s' to retain the resources", fromStack))
+		}
+		steps = append(steps, fmt.Sprintf("Remove the resources from stack '%s'", fromStack))
+		if dest == nil {
+			steps = append(steps, fmt.Sprintf("Create stack '%s' by importing the resources", toStack))
+		} else {
+			steps = append(steps, fmt.Sprintf("Import the resources into stack '%s'", toStack))
+		}
+		steps = append(steps, "Check that both stacks have the expected resources")
+		showSteps(steps)
+
+		fmt.Println(console.Yellow(fmt.Sprintf("Template for stack '%s':", toStack)))
+		fmt.Println(format.String(imported, format.Options{}))
+
+		if dryRun {
+			return
+		}
+
+		if !yes && !console.Confirm(false, fmt.Sprintf("Move %s from stack '%s' to stack '%s'?", strings.Join(resourceIds, ", "), fromStack, toStack)) {
+			panic(errors.New("user cancelled refactor"))
+		}
+
+		if changed {
+			if err := update(source, retained, "Retaining the resources"); err != nil {
+				panic(ui.Errorf(err, "unable to set Retain policies in stack '%s'; nothing has been moved", fromStack))
+			}
+		}
+
+		if err := update(source, removed, "Removing the resources"); err != nil {
+			panic(ui.Errorf(err, "unable to remove the resources from stack '%s'; they are still in it, with Retain policies", fromStack))
+		}
+
+		if err := importResources(toStack, imported, imports); err != nil {
+			showOrphans(fromStack, toStack, imports)
+			panic(ui.Errorf(err, "unable to import the resources into stack '%s'", toStack))
+		}
+
+		if err := verify(fromStack, resourceIds, toStack, imports); err != nil {
+			panic(ui.Errorf(err, "the resources were imported into stack '%s', but they don't match the resources that were in stack '%s'", toStack, fromStack))
+		}
+
+		fmt.Println(console.Green(fmt.Sprintf("Moved %s from stack '%s' to stack '%s'", strings.Join(resourceIds, ", "), fromStack, toStack)))
+	},
+}
+
+// stackTemplate returns the template that was last deployed to the stack, as it was submitted
+func stackTemplate(stackName string) (cft.Template, error) {
+	body, err := cfn.GetStackTemplate(stackName, false)
+	if err != nil {
+		return cft.Template{}, err
+	}
+
+	return parse.String(body)
+}
+
+// resourcesToImport returns the import of each resource, identified by its physical ID in the source stack
+func resourcesToImport(stackName string, ids []string) []types.ResourceToImport {
+	spinner.Push(fmt.Sprintf("Reading the resources of stack '%s'", stackName))
+	defer spinner.Pop()
+
+	resources, err := cfn.GetStackResources(stackName)
+	if err != nil {
+		panic(ui.Errorf(err, "unable to read the resources of stack '%s'", stackName))
+	}
+
+	imports := make([]types.ResourceToImport, 0)
+
+	for _, id := range ids {
+		var resource *types.StackResource
+		for _, r := range resources {
+			if ptr.ToString(r.LogicalResourceId) == id {
+				resource = &r
+				break
+			}
+		}
+
+		if resource == nil || ptr.ToString(resource.PhysicalResourceId) == "" {
+			panic(fmt.Errorf("%s has not been created in stack '%s'", id, stackName))
+		}
+
+		if strings.HasSuffix(string(resource.ResourceStatus), "_FAILED") {
+			panic(fmt.Errorf("%s is %s in stack '%s'; fix it first", id, resource.ResourceStatus, stackName))
+		}
+
+		typeName := ptr.ToString(resource.ResourceType)
+
+		idProp, err := importIdentifier(typeName)
+		if err != nil {
+			panic(ui.Errorf(err, "%s can't be imported", id))
+		}
+
+		imports = append(imports, types.ResourceToImport{
+			LogicalResourceId: ptr.String(id),
+			ResourceType:      ptr.String(typeName),
+			ResourceIdentifier: map[string]string{
+				idProp: ptr.ToString(resource.PhysicalResourceId),
+			},
+		})
+	}
+
+	return imports
+}
+
+// importIdentifier returns the property that identifies resources of the type when they are imported,
+// or an error if the type doesn't support import or can't be identified by a physical ID alone
+func importIdentifier(typeName string) (string, error) {
+	source, err := cfn.GetTypeSchema(typeName, false)
+	if err != nil {
+		return "", err
+	}
+
+	schema, err := cfn.ParseSchema(source)
+	if err != nil {
+		return "", err
+	}
+
+	if !schema.SupportsImport() {
+		return "", fmt.Errorf("%s does not support import", typeName)
+	}
+
+	idProps, err := cfn.GetTypeIdentifier(typeName)
+	if err != nil {
+		return "", err
+	}
+
+	if len(idProps) != 1 {
+		return "", fmt.Errorf("the primary identifier of %s has more than one property: %s",
+			typeName, strings.Join(idProps, ", "))
+	}
+
+	return idProps[0], nil
+}
+
+// showResources prints the resources that will be moved, with their physical IDs
+func showResources(title string, imports []types.ResourceToImport) {
+	fmt.Println(console.Yellow(title))
+	for _, i := range imports {
+		for _, v := range i.ResourceIdentifier {
+			fmt.Printf("  %s (%s): %s\n", ptr.ToString(i.LogicalResourceId), ptr.ToString(i.ResourceType), console.Cyan(v))
+		}
+	}
+	fmt.Println()
+}
+
+// showSteps prints the numbered steps that rain will take
+func showSteps(steps []string) {
+	fmt.Println(console.Yellow("Steps:"))
+	for i, step := range steps {
+		fmt.Printf("  %d. %s\n", i+1, step)
+	}
+	fmt.Println()
+}
+
+// update deploys the template to the source stack with its parameters, tags and role unchanged
+func update(stack types.Stack, template cft.Template, message string) error {
+	stackName := ptr.ToString(stack.StackName)
+
+	params := make([]types.Parameter, 0)
+	for _, p := range stack.Parameters {
+		params = append(params, types.Parameter{
+			ParameterKey:     p.ParameterKey,
+			UsePreviousValue: ptr.Bool(true),
+		})
+	}
+
+	tags := make(map[string]string)
+	for _, t := range stack.Tags {
+		tags[ptr.ToString(t.Key)] = ptr.ToString(t.Value)
+	}
+
+	spinner.Push(fmt.Sprintf("%s: creating change set for stack '%s'", message, stackName))
+	changeSetName, err := cfn.CreateChangeSet(template, params, tags, stackName, "", cfn.ChangeSetOptions{
+		RoleArn:          ptr.ToString(stack.RoleARN),
+		NotificationArns: stack.NotificationARNs,
+	})
+	spinner.Pop()
+	if err != nil {
+		if cfn.ChangeSetHasNoChanges(err) {
+			return nil
+		}
+		return err
+	}
+
+	spinner.Push(fmt.Sprintf("%s: updating stack '%s'", message, stackName))
+	if err := cfn.ExecuteChangeSet(stackName, changeSetName, false); err != nil {
+		spinner.Pop()
+		return err
+	}
+
+	status, messages := cfn.WaitForStackToSettle(stackName)
+	spinner.Pop()
+
+	if status != "UPDATE_COMPLETE" {
+		for _, message := range messages {
+			fmt.Println(console.Red(message))
+		}
+		return fmt.Errorf("stack '%s' is %s", stackName, status)
+	}
+
+	fmt.Println(console.Green(fmt.Sprintf("%s: stack '%s' updated", message, stackName)))
+
+	return nil
+}
+
+// importResources imports the resources into the stack, which creates it if it doesn't exist yet
+func importResources(stackName string, template cft.Template, imports []types.ResourceToImport) error {
+	spinner.Push(fmt.Sprintf("Creating import change set for stack '%s'", stackName))
+	changeSetName, err := cfn.CreateImportChangeSet(template, stackName, imports)
+	spinner.Pop()
+	if err != nil {
+		return err
+	}
+
+	spinner.Push(fmt.Sprintf("Importing the resources into stack '%s'", stackName))
+	if err := cfn.ExecuteChangeSet(stackName, changeSetName, false); err != nil {
+		spinner.Pop()
+		return err
+	}
+
+	status, messages := cfn.WaitForStackToSettle(stackName)
+	spinner.Pop()
+
+	if status != "IMPORT_COMPLETE" {
+		for _, message := range messages {
+			fmt.Println(console.Red(message))
+		}
+		return fmt.Errorf("stack '%s' is %s", stackName, status)
+	}
+
+	return nil
+}
+
+// verify checks that the resources that were removed are no longer in the source stack,
+// and that the imported resources are in the destination stack with the physical IDs they had before
+func verify(fromStack string, removed []string, toStack string, imports []types.ResourceToImport) error {
+	spinner.Push("Checking the stacks")
+	defer spinner.Pop()
+
+	fromResources, err := cfn.GetStackResources(fromStack)
+	if err != nil {
+		return err
+	}
+
+	toResources, err := cfn.GetStackResources(toStack)
+	if err != nil {
+		return err
+	}
+
+	problems := make([]string, 0)
+
+	for _, id := range removed {
+		for _, r := range fromResources {
+			if ptr.ToString(r.LogicalResourceId) == id {
+				problems = append(problems, fmt.Sprintf("%s is still in stack '%s'", id, fromStack))
+			}
+		}
+	}
+
+	for _, i := range imports {
+		id := ptr.ToString(i.LogicalResourceId)
+
+		var physicalId string
+		for _, v := range i.ResourceIdentifier {
+			physicalId = v
+		}
+
+		found := false
+		for _, r := range toResources {
+			if ptr.ToString(r.LogicalResourceId) != id {
+				continue
+			}
+
+			found = true
+			if ptr.ToString(r.PhysicalResourceId) != physicalId {
+				problems = append(problems, fmt.Sprintf("%s is %s in stack '%s', not %s",
+					id, ptr.ToString(r.PhysicalResourceId), toStack, physicalId))
+			}
+		}
+
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s is not in stack '%s'", id, toStack))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
+
+// showOrphans tells the user how to import the resources once the import has failed,
+// since they are no longer in the source stack
+func showOrphans(fromStack, toStack string, imports []types.ResourceToImport) {
+	fmt.Println(console.Yellow(fmt.Sprintf(
+		"The resources were retained when they were removed from stack '%s', so they still exist outside of any stack.", fromStack)))
+	fmt.Println("Once the cause of the failure has been fixed, import them with rain adopt:")
+	for _, i := range imports {
+		for _, v := range i.ResourceIdentifier {
+			fmt.Printf("  rain adopt --logical-id %s %s <template> %s %s\n",
+				ptr.ToString(i.LogicalResourceId), toStack, ptr.ToString(i.ResourceType), v)
+		}
+	}
+}
+
+func init() {
+	Cmd.Flags().StringVar(&fromStack, "from", "", "The stack to move the resources from")
+	Cmd.Flags().StringVar(&toStack, "to", "", "The stack to move the resources to, which is created if it doesn't exist")
+	Cmd.Flags().StringSliceVar(&resourceIds, "resources", []string{}, "The logical IDs of the resources to move, separated by commas")
+	Cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Don't ask before changing the stacks")
+	Cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the plan and the destination template without changing the stacks")
+}
@@ -0,0 +1,116 @@
+package rm
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+)
+
+// findImporters returns, for the named stack and every stack that depends on it,
+// the names of the stacks that import its exports
+func findImporters(stackName string) (map[string][]string, error) {
+	importers := make(map[string][]string)
+
+	pending := []string{stackName}
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+
+		if _, ok := importers[name]; ok {
+			continue
+		}
+
+		spinner.Push(fmt.Sprintf("Checking for stacks that import exports from '%s'", name))
+		stack, err := cfn.GetStack(name)
+		if err != nil {
+			spinner.Pop()
+			return nil, ui.Errorf(err, "unable to get stack '%s'", name)
+		}
+
+		importers[name] = make([]string, 0)
+
+		for _, output := range stack.Outputs {
+			if output.ExportName == nil {
+				continue
+			}
+
+			stacks, err := cfn.ListImports(*output.ExportName)
+			if err != nil {
+				spinner.Pop()
+				return nil, ui.Errorf(err, "unable to list imports of '%s'", *output.ExportName)
+			}
+
+			for _, s := range stacks {
+				importers[name] = append(importers[name], s)
+				pending = append(pending, s)
+			}
+		}
+		importers[name] = cfn.UniqueStrings(importers[name])
+		spinner.Pop()
+	}
+
+	return importers, nil
+}
+
+// deletionOrder returns the stacks in the order in which they can be deleted:
+// every stack comes after all of the stacks that import its exports,
+// so the named stack comes last
+func deletionOrder(stackName string, importers map[string][]string) []string {
+	order := make([]string, 0)
+	visited := make(map[string]bool)
+
+	var visit func(string)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+
+		for _, importer := range importers[name] {
+			visit(importer)
+		}
+
+		order = append(order, name)
+	}
+
+	visit(stackName)
+
+	return order
+}
+
+// dependents returns the stacks that must be deleted before the named stack
+func dependents(stackName string) ([]string, error) {
+	importers, err := findImporters(stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	order := deletionOrder(stackName, importers)
+
+	return order[:len(order)-1], nil
+}
+
+// deleteDependent deletes a stack that imports exports from the stack being removed
+// and waits for the deletion to finish.
+// unprotect disables the stack's termination protection first, once the user has agreed to it.
+func deleteDependent(stackName string, unprotect bool) error {
+	if unprotect {
+		if err := cfn.SetTerminationProtection(stackName, false); err != nil {
+			return ui.Errorf(err, "unable to set termination protection of stack '%s'", stackName)
+		}
+	}
+
+	err := cfn.DeleteStack(stackName, roleArn)
+	if err != nil {
+		return ui.Errorf(err, "unable to delete stack '%s'", stackName)
+	}
+
+	status, _ := cfn.WaitForStackToSettle(stackName)
+	if status != "DELETE_COMPLETE" {
+		return fmt.Errorf("failed to delete stack '%s'", stackName)
+	}
+
+	return nil
+}
@@ -0,0 +1,20 @@
+package rm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeletionOrder(t *testing.T) {
+	// network is imported by app and data; data is also imported by app
+	importers := map[string][]string{
+		"network": {"data", "app"},
+		"data":    {"app"},
+		"app":     {},
+	}
+
+	order := strings.Join(deletionOrder("network", importers), ",")
+	if order != "app,data,network" {
+		t.Errorf("unexpected order: %s", order)
+	}
+}
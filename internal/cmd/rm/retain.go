@@ -0,0 +1,79 @@
+package rm
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// failedResources returns the resources that CloudFormation was unable to delete
+func failedResources(resources []types.StackResource) []types.StackResource {
+	failed := make([]types.StackResource, 0)
+
+	for _, r := range resources {
+		if r.ResourceStatus == types.ResourceStatusDeleteFailed {
+			failed = append(failed, r)
+		}
+	}
+
+	return failed
+}
+
+// deleteRetainingFailed offers to delete a stack in DELETE_FAILED again,
+// leaving the resources that could not be deleted in place.
+// It returns the resources that were retained, or nil if the stack was not deleted again.
+func deleteRetainingFailed(stackName string) ([]types.StackResource, error) {
+	resources, err := cfn.GetStackResources(stackName)
+	if err != nil {
+		return nil, ui.Errorf(err, "unable to list resources of stack '%s'", stackName)
+	}
+
+	failed := failedResources(resources)
+	if len(failed) == 0 {
+		return nil, nil
+	}
+
+	fmt.Println(console.Yellow("These resources could not be deleted:"))
+	for _, r := range failed {
+		fmt.Printf("  %s (%s): %s\n", ptr.ToString(r.LogicalResourceId), ptr.ToString(r.ResourceType),
+			ptr.ToString(r.ResourceStatusReason))
+	}
+
+	if !retainFailed {
+		if yes || !console.Confirm(false, "Do you want to delete the stack again, leaving these resources in place?") {
+			return nil, nil
+		}
+	}
+
+	logicalIds := make([]string, len(failed))
+	for i, r := range failed {
+		logicalIds[i] = ptr.ToString(r.LogicalResourceId)
+	}
+
+	err = cfn.DeleteStack(stackName, roleArn, logicalIds...)
+	if err != nil {
+		return nil, ui.Errorf(err, "unable to delete stack '%s'", stackName)
+	}
+
+	fmt.Printf("Deleting stack '%s' again, retaining %d resources\n", stackName, len(failed))
+	status, _ := cfn.WaitForStackToSettle(stackName)
+
+	if status != "DELETE_COMPLETE" {
+		return nil, fmt.Errorf("failed to delete stack '%s' while retaining resources", stackName)
+	}
+
+	return failed, nil
+}
+
+// showRetained lists the resources that were left behind so that they can be cleaned up manually
+func showRetained(retained []types.StackResource) {
+	fmt.Println(console.Yellow("These resources were retained and must be deleted manually:"))
+	for _, r := range retained {
+		fmt.Printf("  %s (%s): %s\n", ptr.ToString(r.LogicalResourceId), ptr.ToString(r.ResourceType),
+			ptr.ToString(r.PhysicalResourceId))
+	}
+}
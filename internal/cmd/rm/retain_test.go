@@ -0,0 +1,23 @@
+package rm
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestFailedResources(t *testing.T) {
+	resources := []types.StackResource{
+		{LogicalResourceId: ptr.String("Bucket"), ResourceStatus: types.ResourceStatusDeleteFailed},
+		{LogicalResourceId: ptr.String("Queue"), ResourceStatus: types.ResourceStatusDeleteComplete},
+		{LogicalResourceId: ptr.String("Table"), ResourceStatus: types.ResourceStatusDeleteFailed},
+		{LogicalResourceId: ptr.String("Topic"), ResourceStatus: types.ResourceStatusDeleteSkipped},
+	}
+
+	failed := failedResources(resources)
+
+	if len(failed) != 2 || *failed[0].LogicalResourceId != "Bucket" || *failed[1].LogicalResourceId != "Table" {
+		t.Errorf("unexpected failed resources: %v", failed)
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"os"
 
 	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/budget"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
 	"github.com/aws-cloudformation/rain/internal/ui"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
 	"github.com/spf13/cobra"
 )
 
@@ -16,6 +20,9 @@ var yes bool
 var detach bool
 var roleArn string
 var changeset bool
+var cascade bool
+var retainFailed bool
+var unlock bool
 
 func DeleteChangeSet(stack *types.Stack, changeSetName string) error {
 	if !yes {
@@ -43,7 +50,7 @@ func DeleteChangeSet(stack *types.Stack, changeSetName string) error {
 var Cmd = &cobra.Command{
 	Use:                   "rm <stack> [changeset]",
 	Short:                 "Delete a CloudFormation stack or changeset",
-	Long:                  "Deletes the CloudFormation stack named <stack> and waits for the action to complete. With -c, deletes a changeset named [changeset].",
+	Long:                  "Deletes the CloudFormation stack named <stack> and waits for the action to complete. With -c, deletes a changeset named [changeset]. With --cascade, first deletes any stacks that import <stack>'s exports. Stacks that have been locked with rain protect are only deleted with --unlock. If some resources can't be deleted, offers to delete the stack again while retaining them (or does so without asking with --retain-failed) and lists the retained resources so they can be cleaned up manually.",
 	Args:                  cobra.MaximumNArgs(2),
 	Aliases:               []string{"remove", "del", "delete"},
 	DisableFlagsInUseLine: true,
@@ -70,6 +77,62 @@ var Cmd = &cobra.Command{
 			return
 		}
 
+		if err := cfn.CheckUnlocked(stack, unlock); err != nil {
+			panic(err)
+		}
+
+		// Stacks that import this stack's exports would stop the deletion
+		protected := make(map[string]bool)
+		deps, err := dependents(stackName)
+		if err != nil {
+			panic(err)
+		}
+
+		if len(deps) > 0 {
+			fmt.Println(console.Yellow(fmt.Sprintf("These stacks import exports from '%s':", stackName)))
+			for _, dep := range deps {
+				fmt.Printf("  %s\n", dep)
+			}
+
+			if !cascade {
+				panic(fmt.Errorf("stack '%s' cannot be deleted while other stacks import its exports; "+
+					"delete them first or use --cascade", stackName))
+			}
+
+			// Check every dependent before deleting any of them
+			for _, dep := range deps {
+				depStack, err := cfn.GetStack(dep)
+				if err != nil {
+					panic(ui.Errorf(err, "unable to get stack '%s'", dep))
+				}
+
+				if err := cfn.CheckUnlocked(depStack, unlock); err != nil {
+					panic(err)
+				}
+
+				if ptr.ToBool(depStack.EnableTerminationProtection) {
+					protected[dep] = true
+				}
+			}
+
+			if !yes && !console.Confirm(false, "Are you sure you want to delete these stacks, in this order?") {
+				panic(fmt.Errorf("user cancelled deletion of stack '%s'", stackName))
+			}
+
+			if len(protected) > 0 {
+				fmt.Println(console.Yellow("These stacks have termination protection enabled:"))
+				for _, dep := range deps {
+					if protected[dep] {
+						fmt.Printf("  %s\n", dep)
+					}
+				}
+
+				if !yes && !console.Confirm(false, "Do you wish to disable it?") {
+					panic(fmt.Errorf("user cancelled deletion of stack '%s'", stackName))
+				}
+			}
+		}
+
 		if !yes {
 			output, _ := cfn.GetStackOutput(stack)
 
@@ -80,19 +143,46 @@ var Cmd = &cobra.Command{
 			}
 		}
 
+		// Ask about termination protection before any dependent stack is deleted
 		if *stack.EnableTerminationProtection {
-
-			if yes || console.Confirm(false, "This stack has termination protection enabled. Do you wish to disable it?") {
-				spinner.Push("Disabling termination protection")
-				if err := cfn.SetTerminationProtection(stackName, false); err != nil {
-					panic(ui.Errorf(err, "unable to set termination protection of stack '%s'", stackName))
-				}
-				spinner.Pop()
-			} else {
+			if !yes && !console.Confirm(false, "This stack has termination protection enabled. Do you wish to disable it?") {
 				panic(fmt.Errorf("user cancelled deletion of stack '%s'", stackName))
 			}
 		}
 
+		for _, dep := range deps {
+			spinner.Push(fmt.Sprintf("Deleting dependent stack '%s'", dep))
+			err := deleteDependent(dep, protected[dep])
+			spinner.Pop()
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(console.Green(fmt.Sprintf("Successfully deleted stack '%s'", dep)))
+		}
+
+		if *stack.EnableTerminationProtection {
+			spinner.Push("Disabling termination protection")
+			if err := cfn.SetTerminationProtection(stackName, false); err != nil {
+				panic(ui.Errorf(err, "unable to set termination protection of stack '%s'", stackName))
+			}
+			spinner.Pop()
+		}
+
+		spinner.Push("Reserving a stack operation slot")
+		lease, err := budget.Acquire(stackName, func(message string) {
+			spinner.Pop()
+			spinner.Push(message)
+		})
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to reserve a stack operation slot"))
+		}
+		defer func() {
+			if err := lease.Release(); err != nil {
+				console.Warnf("%s", err)
+			}
+		}()
+
 		err = cfn.DeleteStack(stackName, roleArn)
 		if err != nil {
 			panic(ui.Errorf(err, "unable to delete stack '%s'", stackName))
@@ -100,12 +190,14 @@ var Cmd = &cobra.Command{
 
 		if detach {
 			fmt.Printf("Detaching. You can check your stack's status with: rain watch %s\n", stackName)
+			emit.Event("detached", map[string]string{"stackName": stackName})
 		} else {
 			status, messages := cfn.WaitForStackToSettle(stackName)
 			stack, _ = cfn.GetStack(stackName)
 
 			if status == "DELETE_COMPLETE" {
 				fmt.Println(console.Green(fmt.Sprintf("Successfully deleted stack '%s'", stackName)))
+				emit.Event(emit.StackType, emit.StackData{StackName: stackName, Status: status})
 				return
 			}
 
@@ -118,7 +210,22 @@ var Cmd = &cobra.Command{
 				}
 			}
 
-			os.Exit(1)
+			if status == "DELETE_FAILED" {
+				retained, err := deleteRetainingFailed(stackName)
+				if err != nil {
+					panic(err)
+				}
+
+				if retained != nil {
+					fmt.Println(console.Green(fmt.Sprintf("Successfully deleted stack '%s'", stackName)))
+					showRetained(retained)
+					emit.Event(emit.StackType, emit.StackData{StackName: stackName, Status: "DELETE_COMPLETE"})
+					return
+				}
+			}
+
+			emit.Event(emit.StackType, emit.StackData{StackName: stackName, Status: status, Messages: messages})
+			exitcode.Exit(exitcode.DeployFailed)
 		}
 	},
 }
@@ -128,4 +235,9 @@ func init() {
 	Cmd.Flags().BoolVarP(&yes, "yes", "y", false, "don't ask questions; just delete")
 	Cmd.Flags().StringVar(&roleArn, "role-arn", "", "ARN of an IAM role that CloudFormation should assume to remove the stack")
 	Cmd.Flags().BoolVarP(&changeset, "changeset", "c", false, "delete a changeset")
+	Cmd.Flags().BoolVar(&retainFailed, "retain-failed", false, "if some resources can't be deleted, delete the stack again while retaining them")
+	Cmd.Flags().StringVar(&budget.Table, "budget-table", budget.Table, "name or ARN of a DynamoDB table used to limit concurrent stack operations in the account")
+	Cmd.Flags().IntVar(&budget.Limit, "budget", budget.Limit, "maximum number of concurrent stack operations in the account when --budget-table is set")
+	Cmd.Flags().BoolVar(&unlock, "unlock", false, "delete the stack even if it has been locked with rain protect")
+	Cmd.Flags().BoolVar(&cascade, "cascade", false, "also delete stacks that import this stack's exports, in reverse dependency order")
 }
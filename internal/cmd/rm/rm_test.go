@@ -14,7 +14,7 @@ func Example_rm_help() {
 
 	rm.Cmd.Execute()
 	// Output:
-	// Deletes the CloudFormation stack named <stack> and waits for the action to complete. With -c, deletes a changeset named [changeset].
+	// Deletes the CloudFormation stack named <stack> and waits for the action to complete. With -c, deletes a changeset named [changeset]. With --cascade, first deletes any stacks that import <stack>'s exports. Stacks that have been locked with rain protect are only deleted with --unlock. If some resources can't be deleted, offers to delete the stack again while retaining them (or does so without asking with --retain-failed) and lists the retained resources so they can be cleaned up manually.
 	//
 	// Usage:
 	//   rm <stack> [changeset]
@@ -23,9 +23,14 @@ func Example_rm_help() {
 	//   rm, remove, del, delete
 	//
 	// Flags:
-	//   -c, --changeset         delete a changeset
-	//   -d, --detach            once removal has started, don't wait around for it to finish
-	//   -h, --help              help for rm
-	//       --role-arn string   ARN of an IAM role that CloudFormation should assume to remove the stack
-	//   -y, --yes               don't ask questions; just delete
+	//       --budget int            maximum number of concurrent stack operations in the account when --budget-table is set (default 5)
+	//       --budget-table string   name or ARN of a DynamoDB table used to limit concurrent stack operations in the account
+	//       --cascade               also delete stacks that import this stack's exports, in reverse dependency order
+	//   -c, --changeset             delete a changeset
+	//   -d, --detach                once removal has started, don't wait around for it to finish
+	//   -h, --help                  help for rm
+	//       --retain-failed         if some resources can't be deleted, delete the stack again while retaining them
+	//       --role-arn string       ARN of an IAM role that CloudFormation should assume to remove the stack
+	//       --unlock                delete the stack even if it has been locked with rain protect
+	//   -y, --yes                   don't ask questions; just delete
 }
@@ -0,0 +1,116 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft/iam"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var jsonFlag bool
+var sarifFlag bool
+
+var iamCmd = &cobra.Command{
+	Use:   "iam <template>",
+	Short: "Find IAM policy statements that allow too much",
+	Long: `Analyzes the IAM policy documents in a template: the inline policies of roles, users and groups,
+managed policies, the trust policies of roles, and the resource policies of buckets, queues, topics,
+KMS keys, secrets and ECR repositories. Policies written as JSON strings are analyzed too.
+
+It reports:
+
+  - Statements that allow every action (*), or every action of a service (s3:*)
+  - Identity policy statements that apply to every resource (Resource: *)
+  - Resource and trust policies that allow anyone (Principal: *) with no condition
+  - Trust policies that let other accounts or identity providers assume a role with no condition,
+    such as sts:ExternalId for another account, or the audience and subject of an OIDC token
+
+Only Allow statements are checked. Values written with intrinsic functions can't be checked.
+
+The command exits with a non-zero status if any errors are found.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fn := args[0]
+
+		t, err := parse.File(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		findings := iam.Analyze(t)
+
+		emit.Event("findings", map[string]any{"file": fn, "findings": findings})
+
+		if jsonFlag {
+			out, err := json.MarshalIndent(findings, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else if sarifFlag {
+			out, err := iam.SARIF(fn, findings)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else {
+			printFindings(fn, findings)
+		}
+
+		for _, f := range findings {
+			if f.Severity == iam.Error {
+				exitcode.Exit(exitcode.Invalid)
+			}
+		}
+	},
+}
+
+// printFindings shows the findings grouped by resource and policy document
+func printFindings(fn string, findings []iam.Finding) {
+	if len(findings) == 0 {
+		fmt.Printf("%s: %s\n", fn, console.Green("no problems found in IAM policies"))
+		return
+	}
+
+	fmt.Printf("%s:\n", fn)
+
+	docs := make([]string, 0)
+	byDoc := make(map[string][]iam.Finding)
+	for _, f := range findings {
+		doc := fmt.Sprintf("%s %s", f.Resource, f.Path)
+		if _, ok := byDoc[doc]; !ok {
+			docs = append(docs, doc)
+		}
+		byDoc[doc] = append(byDoc[doc], f)
+	}
+
+	for _, doc := range docs {
+		fmt.Printf("  %s\n", console.Yellow(doc))
+		for _, f := range byDoc[doc] {
+			var severity string
+			switch f.Severity {
+			case iam.Error:
+				severity = console.Red(string(f.Severity))
+			case iam.Warning:
+				severity = console.Yellow(string(f.Severity))
+			default:
+				severity = console.Grey(string(f.Severity))
+			}
+
+			fmt.Printf("    %s line %d, statement %s: %s %s\n", severity, f.Line, f.Statement, f.Message, console.Grey("["+f.Check+"]"))
+		}
+	}
+}
+
+func init() {
+	iamCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output the findings as JSON")
+	iamCmd.Flags().BoolVar(&sarifFlag, "sarif", false, "Output the findings as a SARIF log")
+	iamCmd.MarkFlagsMutuallyExclusive("json", "sarif")
+}
@@ -0,0 +1,75 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/policy"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var policyCmd = &cobra.Command{
+	Use:   "policy <template> <policy directory>",
+	Short: "Check a template against cfn-guard or OPA policies",
+	Long: `Evaluates the cfn-guard rules (.guard) and OPA policies (.rego) in a directory against a template,
+in the same way as "rain deploy --policy", without deploying it. The cfn-guard and opa programs
+must be installed to evaluate their policies.
+
+Policies in the "changeset" subdirectory are evaluated against change sets, so they are only
+checked by rain deploy.
+
+The command exits with a non-zero status if any policy denies the template.`,
+	Args:                  cobra.ExactArgs(2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fn, dir := args[0], args[1]
+
+		t, err := parse.File(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		results, err := policy.Template(dir, t.Map())
+		if err != nil {
+			panic(ui.Errorf(err, "unable to evaluate the policies in '%s'", dir))
+		}
+
+		emit.Event("denials", map[string]any{"file": fn, "denials": results})
+
+		if jsonFlag {
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else if sarifFlag {
+			out, err := policy.SARIF(fn, results)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else if len(results) == 0 {
+			fmt.Printf("%s: %s\n", fn, console.Green("allowed by every policy"))
+		} else {
+			fmt.Printf("%s: %s\n", fn, console.Red("denied by policy:"))
+			for _, r := range results {
+				fmt.Printf("  - %s\n", r)
+			}
+		}
+
+		if len(results) > 0 {
+			exitcode.Exit(exitcode.Invalid)
+		}
+	},
+}
+
+func init() {
+	policyCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output the denials as JSON")
+	policyCmd.Flags().BoolVar(&sarifFlag, "sarif", false, "Output the denials as a SARIF log")
+	policyCmd.MarkFlagsMutuallyExclusive("json", "sarif")
+}
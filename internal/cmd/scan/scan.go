@@ -0,0 +1,29 @@
+package scan
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the scan command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "scan <command>",
+	Short: "Scan a template for security problems",
+	Long: `Analyzes a template locally, without calling AWS, for security problems.
+
+The IAM checks also run as part of "rain lint", where their findings can be suppressed like
+any other rule's; scan shows the findings in more detail. The security rules are only run by scan.
+
+Both commands honor the Rain::Suppressions in the template's and resources' Metadata;
+"rain scan suppressions" lists them for auditing. "rain scan policy" checks the template
+against the cfn-guard and OPA policies that "rain deploy --policy" enforces.
+
+Use --sarif to write the findings as a SARIF log, which GitHub code scanning and other
+security dashboards can read.`,
+}
+
+func init() {
+	Cmd.AddCommand(iamCmd)
+	Cmd.AddCommand(policyCmd)
+	Cmd.AddCommand(securityCmd)
+	Cmd.AddCommand(suppressionsCmd)
+}
@@ -0,0 +1,153 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/scan"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var showSuppressed bool
+
+var securityCmd = &cobra.Command{
+	Use:   "security <template>",
+	Short: "Check resources for common security problems",
+	Long: `Checks the resources in a template for common security problems, such as unencrypted storage,
+security groups open to the internet, buckets without a public access block, and missing logs.
+
+The rules are:
+
+` + ruleHelp() + `
+
+Values written with intrinsic functions are assumed to be set correctly.
+
+To accept a finding, list the rule in the resource's Metadata, or in the template's Metadata
+to accept it for every resource, with the reason and optionally the last day it applies:
+
+  Metadata:
+    Rain::Suppressions:
+      - Rule: s3-logging
+        Reason: access is logged by CloudTrail data events
+        Expires: 2025-06-30
+
+rain lint reads the same suppressions. Suppressed findings are not shown unless you pass --show-suppressed;
+use "rain scan suppressions" to list them. SARIF logs written with --sarif include them, marked as suppressed.
+
+The command exits with a non-zero status if any errors are found that are not suppressed.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fn := args[0]
+
+		t, err := parse.File(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		findings := scan.Template(t)
+
+		// SARIF logs mark suppressed findings instead of leaving them out
+		if !showSuppressed && !sarifFlag {
+			shown := make([]scan.Finding, 0)
+			for _, f := range findings {
+				if !f.Suppressed {
+					shown = append(shown, f)
+				}
+			}
+
+			if suppressed := len(findings) - len(shown); suppressed > 0 && !jsonFlag {
+				defer fmt.Println(console.Grey(fmt.Sprintf("%d suppressed finding(s) not shown", suppressed)))
+			}
+
+			findings = shown
+		}
+
+		emit.Event("findings", map[string]any{"file": fn, "findings": findings})
+
+		if jsonFlag {
+			out, err := json.MarshalIndent(findings, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else if sarifFlag {
+			out, err := scan.SARIF(fn, findings)
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else {
+			printSecurityFindings(fn, findings)
+		}
+
+		if scan.HasErrors(findings) {
+			exitcode.Exit(exitcode.Invalid)
+		}
+	},
+}
+
+// printSecurityFindings shows the findings grouped by resource
+func printSecurityFindings(fn string, findings []scan.Finding) {
+	if len(findings) == 0 {
+		fmt.Printf("%s: %s\n", fn, console.Green("no security problems found"))
+		return
+	}
+
+	fmt.Printf("%s:\n", fn)
+
+	resources := make([]string, 0)
+	byResource := make(map[string][]scan.Finding)
+	for _, f := range findings {
+		if _, ok := byResource[f.Resource]; !ok {
+			resources = append(resources, f.Resource)
+		}
+		byResource[f.Resource] = append(byResource[f.Resource], f)
+	}
+
+	for _, resource := range resources {
+		fmt.Printf("  %s\n", console.Yellow(resource))
+		for _, f := range byResource[resource] {
+			var severity string
+			switch f.Severity {
+			case scan.Error:
+				severity = console.Red(string(f.Severity))
+			case scan.Warning:
+				severity = console.Yellow(string(f.Severity))
+			default:
+				severity = console.Grey(string(f.Severity))
+			}
+
+			message := fmt.Sprintf("    %s line %d: %s %s", severity, f.Line, f.Message, console.Grey("["+f.Rule+"]"))
+			if f.Suppressed {
+				message += console.Grey(" suppressed")
+				if f.Reason != "" {
+					message += console.Grey(": " + f.Reason)
+				}
+			}
+			fmt.Println(message)
+		}
+	}
+}
+
+// ruleHelp lists the rules for the command's help
+func ruleHelp() string {
+	lines := make([]string, 0, len(scan.Rules))
+	for _, rule := range scan.Rules {
+		lines = append(lines, fmt.Sprintf("  %-28s %s", rule.Name, rule.Description))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func init() {
+	securityCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output the findings as JSON")
+	securityCmd.Flags().BoolVar(&sarifFlag, "sarif", false, "Output the findings as a SARIF log")
+	securityCmd.MarkFlagsMutuallyExclusive("json", "sarif")
+	securityCmd.Flags().BoolVar(&showSuppressed, "show-suppressed", false, "Show findings that are suppressed in the resources' Metadata")
+}
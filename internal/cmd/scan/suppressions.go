@@ -0,0 +1,193 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft/lint"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/suppress"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var allFlag bool
+
+// entry is a suppression in one of the templates
+type entry struct {
+	File string `json:"file"`
+
+	// Source is "metadata" for Rain::Suppressions or "comment" for rain-disable-next-line
+	Source string `json:"source"`
+
+	suppress.Suppression
+}
+
+var suppressionsCmd = &cobra.Command{
+	Use:   "suppressions <template or directory>...",
+	Short: "List the suppressions in a set of templates",
+	Long: `Lists the suppressions in templates, for auditing which findings have been accepted and why.
+Directories are searched for templates (.yaml, .yml, .json and .template files).
+
+Suppressions are read from Rain::Suppressions in the templates' and resources' Metadata:
+
+  Metadata:
+    Rain::Suppressions:
+      - Rule: s3-logging
+        Reason: this bucket receives the other buckets' access logs
+        Expires: 2025-06-30
+
+and from "# rain-disable-next-line" comments, which only apply to rain lint.
+
+Only the suppressions that apply are listed, unless you pass --all to include the ones
+that have expired, have no reason, or can't be read.`,
+	Args:                  cobra.MinimumNArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		files, err := findTemplates(args)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to find templates"))
+		}
+
+		entries := make([]entry, 0)
+		now := time.Now()
+
+		for _, fn := range files {
+			t, err := parse.File(fn)
+			if err != nil {
+				config.Debugf("Skipping %s: %v", fn, err)
+				continue
+			}
+
+			for _, s := range suppress.Template(t, now) {
+				if allFlag || s.Active() {
+					entries = append(entries, entry{File: fn, Source: "metadata", Suppression: s})
+				}
+			}
+
+			for _, c := range lint.Suppressions(t) {
+				entries = append(entries, entry{File: fn, Source: "comment", Suppression: suppress.Suppression{
+					Rules:  c.Rules,
+					Reason: c.Reason,
+					Line:   c.Comment,
+				}})
+			}
+		}
+
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].File != entries[j].File {
+				return entries[i].File < entries[j].File
+			}
+			return entries[i].Line < entries[j].Line
+		})
+
+		emit.Event("suppressions", entries)
+
+		if jsonFlag {
+			out, err := json.MarshalIndent(entries, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+			return
+		}
+
+		printSuppressions(entries, len(files))
+	},
+}
+
+// printSuppressions shows the suppressions grouped by file
+func printSuppressions(entries []entry, templates int) {
+	file := ""
+	active := 0
+
+	for _, e := range entries {
+		if e.File != file {
+			file = e.File
+			fmt.Printf("%s:\n", file)
+		}
+
+		where := e.Resource
+		if where == "" && e.Source == "metadata" {
+			where = "template"
+		} else if e.Source == "comment" {
+			where = "comment"
+		}
+
+		reason := e.Reason
+		if reason == "" {
+			reason = "no reason given"
+		}
+
+		line := fmt.Sprintf("  line %d %s %s: %s", e.Line, console.Yellow(where), strings.Join(e.Rules, ","), reason)
+		if e.Expires != "" {
+			line += console.Grey(fmt.Sprintf(" (expires %s)", e.Expires))
+		}
+		if e.Active() {
+			active++
+		} else {
+			line += " " + console.Red(e.Problem)
+		}
+
+		fmt.Println(line)
+	}
+
+	fmt.Println(console.Grey(fmt.Sprintf("%d active suppressions in %d templates", active, templates)))
+}
+
+// findTemplates returns the files in paths, searching directories for templates
+func findTemplates(paths []string) ([]string, error) {
+	files := make([]string, 0)
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".yaml", ".yml", ".json", ".template":
+				if !d.IsDir() {
+					files = append(files, p)
+				}
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no templates found in %s", strings.Join(paths, ", "))
+	}
+
+	sort.Strings(files)
+
+	return files, nil
+}
+
+func init() {
+	suppressionsCmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output the suppressions as JSON")
+	suppressionsCmd.Flags().BoolVar(&allFlag, "all", false, "Include suppressions that don't apply, such as expired ones")
+}
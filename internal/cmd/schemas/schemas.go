@@ -0,0 +1,72 @@
+// Package schemas contains commands for managing rain's cache of resource schemas
+package schemas
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// Cmd is the schemas command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "schemas <command>",
+	Short: "Manage the cache of resource schemas",
+	Long: `Manage the resource schemas that rain downloads from the CloudFormation registry.
+
+Schemas are cached in the rain/schemas directory of the user's cache directory (e.g. ~/.cache/rain/schemas)
+and are downloaded again once they are older than a week, or RAIN_SCHEMA_CACHE_TTL if it is set (e.g. "24h").
+
+Run "rain schemas update" while online so that commands like "rain build" work with --offline,
+or with the RAIN_OFFLINE environment variable set. When rain is offline, cached schemas are used
+however old they are, and the schemas that are embedded in rain are used for the rest.`,
+}
+
+// UpdateCmd is the schemas update command's entrypoint
+var UpdateCmd = &cobra.Command{
+	Use:                   "update [<resource type prefix>...]",
+	Short:                 "Download resource schemas into the cache",
+	Long:                  "Downloads the schemas of every resource type in the registry, or of the types that start with one of the prefixes, e.g. AWS::S3::",
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		spinner.Push("Listing resource types")
+		names, err := cfn.UpdateSchemas(args, func(name string, i, total int) {
+			spinner.Pop()
+			spinner.Push(fmt.Sprintf("Downloading %s (%d/%d)", name, i+1, total))
+		})
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to update the schema cache"))
+		}
+
+		dir, _ := cfn.SchemaCacheDir()
+		fmt.Printf("Cached %s schemas in %s\n", console.Yellow(fmt.Sprint(len(names))), dir)
+	},
+}
+
+// ClearCmd is the schemas clear command's entrypoint
+var ClearCmd = &cobra.Command{
+	Use:                   "clear",
+	Short:                 "Remove every cached resource schema",
+	Args:                  cobra.NoArgs,
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := cfn.ClearSchemaCache(); err != nil {
+			panic(ui.Errorf(err, "unable to clear the schema cache"))
+		}
+
+		fmt.Println(console.Green("Schema cache cleared"))
+	},
+}
+
+func init() {
+	UpdateCmd.Flags().StringVarP(&config.Profile, "profile", "p", "", "AWS profile name; read from the AWS CLI configuration file")
+	UpdateCmd.Flags().StringVarP(&config.Region, "region", "r", "", "AWS region to use")
+
+	Cmd.AddCommand(UpdateCmd)
+	Cmd.AddCommand(ClearCmd)
+}
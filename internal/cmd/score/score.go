@@ -0,0 +1,106 @@
+package score
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/score"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var jsonFlag bool
+var minScore int
+
+// Cmd is the score command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "score <template>",
+	Short: "Rate the maintainability of a CloudFormation template",
+	Long: `Computes a maintainability score between 0 and 100 for a template and suggests refactorings.
+
+The score takes into account the size of the template, how deeply intrinsic functions are nested,
+how complex the template's conditions are, and how many resources look like copies of each other.
+
+Use --min-score to fail with a non-zero exit code when the score is too low, e.g. as a soft CI gate.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		fn := args[0]
+
+		source, err := os.ReadFile(fn)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to read '%s'", fn))
+		}
+
+		t, err := parse.String(string(source))
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+		}
+
+		result, err := score.Template(t, strings.Count(string(source), "\n")+1)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to score template '%s'", fn))
+		}
+
+		emit.Event("score", result)
+
+		if jsonFlag {
+			out, err := json.MarshalIndent(result, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+		} else {
+			printResult(fn, result)
+		}
+
+		if result.Score < minScore {
+			if !jsonFlag {
+				console.Failf("Score %d is below the minimum of %d", result.Score, minScore)
+			}
+			exitcode.Exit(exitcode.Invalid)
+		}
+	},
+}
+
+func printResult(fn string, result score.Result) {
+	colour := console.Green
+	if result.Score < 50 {
+		colour = console.Red
+	} else if result.Score < 80 {
+		colour = console.Yellow
+	}
+
+	fmt.Printf("%s: %s\n", fn, colour(fmt.Sprintf("%d/100", result.Score)))
+	fmt.Println()
+
+	m := result.Metrics
+	fmt.Println(console.Yellow("Metrics:"))
+	fmt.Printf("  Lines:              %d\n", m.Lines)
+	fmt.Printf("  Resources:          %d\n", m.Resources)
+	fmt.Printf("  Parameters:         %d\n", m.Parameters)
+	fmt.Printf("  Conditions:         %d\n", m.Conditions)
+	fmt.Printf("  Outputs:            %d\n", m.Outputs)
+	fmt.Printf("  Max nesting:        %d\n", m.MaxNesting)
+	fmt.Printf("  Max condition ops:  %d\n", m.MaxConditionOps)
+	fmt.Printf("  Similar resources:  %d\n", m.SimilarPairs)
+
+	if len(result.Hints) > 0 {
+		fmt.Println()
+		fmt.Println(console.Yellow("Hints:"))
+		for _, hint := range result.Hints {
+			fmt.Printf("  - %s\n", hint)
+		}
+	}
+}
+
+func init() {
+	Cmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output the score as JSON")
+	Cmd.Flags().IntVar(&minScore, "min-score", 0, "Exit with a non-zero status if the score is below this value")
+}
@@ -0,0 +1,85 @@
+package search
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// cachedTemplate is a stack's template as it was when the stack was last updated
+type cachedTemplate struct {
+	Updated  time.Time `json:"updated"`
+	Template string    `json:"template"`
+}
+
+// templateCachePath returns the path of the file the stack's template is cached in
+func templateCachePath(stack types.StackSummary) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	key := fmt.Sprintf("%s|%s|%s", config.Profile, aws.Config().Region, *stack.StackId)
+
+	return filepath.Join(dir, "rain", "templates", fmt.Sprintf("%x.json", sha256.Sum256([]byte(key)))), nil
+}
+
+// lastUpdated returns the time the stack's template could last have changed
+func lastUpdated(stack types.StackSummary) time.Time {
+	if stack.LastUpdatedTime != nil {
+		return *stack.LastUpdatedTime
+	}
+
+	if stack.CreationTime != nil {
+		return *stack.CreationTime
+	}
+
+	return time.Time{}
+}
+
+// getTemplate returns the stack's processed template.
+// Templates are cached on disk until the stack is updated, so that repeated searches are fast.
+func getTemplate(stack types.StackSummary) (string, error) {
+	path, pathErr := templateCachePath(stack)
+	updated := lastUpdated(stack)
+
+	if pathErr == nil && !noCache {
+		if content, err := os.ReadFile(path); err == nil {
+			var cached cachedTemplate
+			if err := json.Unmarshal(content, &cached); err == nil && cached.Updated.Equal(updated) {
+				return cached.Template, nil
+			}
+		}
+	}
+
+	template, err := cfn.GetStackTemplate(*stack.StackName, true)
+	if err != nil {
+		return "", err
+	}
+
+	if pathErr != nil {
+		return template, nil
+	}
+
+	// Failing to write the cache is not an error; the template will be fetched again next time
+	content, err := json.Marshal(cachedTemplate{Updated: updated, Template: template})
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(path, content, 0600)
+	}
+	if err != nil {
+		config.Debugf("unable to cache the template of %s: %s", *stack.StackName, err)
+	}
+
+	return template, nil
+}
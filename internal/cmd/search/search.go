@@ -0,0 +1,223 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/search"
+	"github.com/aws-cloudformation/rain/internal/aws"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/aws/ec2"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var properties []string
+var stackPattern string
+var allRegions bool
+var jsonFlag bool
+var noCache bool
+
+// result is a resource that matched the search
+type result struct {
+	Stack  string `json:"stack"`
+	Region string `json:"region"`
+	search.Match
+}
+
+// Cmd is the search command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "search <resource type>",
+	Short: "Search the templates of deployed stacks for resources",
+	Long: `Searches the templates of the stacks in the account for resources of a type,
+optionally filtered by the values of their properties, to answer questions like
+"which databases still run Postgres 11?":
+
+  rain search AWS::RDS::DBInstance --property Engine=postgres --property EngineVersion=11*
+
+The resource type can use * as a wildcard, e.g. AWS::RDS::*.
+A property is a path inside the resource's Properties, e.g. BucketEncryption/ServerSideEncryptionConfiguration.
+Values can use * as a wildcard and are compared without regard to case.
+A property without a value matches resources that set it to anything.
+Refs to parameters are resolved with the stack's parameter values.
+
+Templates are cached until their stacks are updated, so repeated searches only call GetTemplate for stacks that have changed.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		q := search.Query{Type: args[0]}
+
+		for _, p := range properties {
+			c, err := search.ParseCondition(p)
+			if err != nil {
+				panic(ui.Errorf(err, "invalid --property"))
+			}
+			q.Conditions = append(q.Conditions, c)
+		}
+
+		if _, err := path.Match(stackPattern, ""); err != nil {
+			panic(ui.Errorf(err, "invalid --stack pattern"))
+		}
+
+		regions := []string{aws.Config().Region}
+		if allRegions {
+			var err error
+			spinner.Push("Fetching region list")
+			regions, err = ec2.GetRegions()
+			if err != nil {
+				panic(ui.Errorf(err, "unable to get region list"))
+			}
+			spinner.Pop()
+		}
+
+		origRegion := aws.Config().Region
+
+		results := make([]result, 0)
+		for _, region := range regions {
+			aws.SetRegion(region)
+			results = append(results, searchRegion(q, region)...)
+		}
+
+		aws.SetRegion(origRegion)
+
+		if jsonFlag {
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+			return
+		}
+
+		printResults(q, results)
+	},
+}
+
+// searchRegion searches the templates of the stacks in the current region
+func searchRegion(q search.Query, region string) []result {
+	spinner.Push(fmt.Sprintf("Fetching stacks in %s", region))
+	stacks, err := cfn.ListStacks()
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "failed to list stacks in %s", region))
+	}
+
+	sort.Slice(stacks, func(i, j int) bool {
+		return *stacks[i].StackName < *stacks[j].StackName
+	})
+
+	results := make([]result, 0)
+
+	for _, stack := range stacks {
+		name := *stack.StackName
+
+		if stackPattern != "" {
+			if ok, _ := path.Match(stackPattern, name); !ok {
+				continue
+			}
+		}
+
+		spinner.Push(fmt.Sprintf("Searching %s", name))
+		source, err := getTemplate(stack)
+		if err != nil {
+			spinner.Pop()
+			panic(ui.Errorf(err, "unable to get the template of stack '%s'", name))
+		}
+
+		t, err := parse.String(source)
+		if err != nil {
+			// A template rain can't parse shouldn't stop the search
+			config.Debugf("unable to parse the template of stack '%s': %s", name, err)
+			spinner.Pop()
+			continue
+		}
+
+		matches, err := q.Template(t, stackParameters(q, t, name))
+		spinner.Pop()
+		if err != nil {
+			continue
+		}
+
+		for _, m := range matches {
+			results = append(results, result{Stack: name, Region: region, Match: m})
+		}
+	}
+
+	return results
+}
+
+// stackParameters returns the stack's parameter values,
+// if the query needs them to resolve Refs
+func stackParameters(q search.Query, t cft.Template, stackName string) map[string]string {
+	if len(q.Conditions) == 0 {
+		return nil
+	}
+
+	if _, err := t.GetSection(cft.Parameters); err != nil {
+		return nil
+	}
+
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		config.Debugf("unable to get the parameters of stack '%s': %s", stackName, err)
+		return nil
+	}
+
+	params := make(map[string]string)
+	for _, p := range stack.Parameters {
+		if p.ParameterKey == nil {
+			continue
+		}
+
+		switch {
+		case p.ResolvedValue != nil:
+			params[*p.ParameterKey] = *p.ResolvedValue
+		case p.ParameterValue != nil:
+			params[*p.ParameterKey] = *p.ParameterValue
+		}
+	}
+
+	return params
+}
+
+func printResults(q search.Query, results []result) {
+	if len(results) == 0 {
+		fmt.Println(console.Yellow("No matching resources found"))
+		return
+	}
+
+	stacks := 0
+	last := ""
+	for _, r := range results {
+		if key := r.Region + "/" + r.Stack; key != last {
+			fmt.Printf("%s %s\n", console.Yellow(r.Stack), console.Grey("("+r.Region+")"))
+			last = key
+			stacks++
+		}
+
+		values := make([]string, 0, len(q.Conditions))
+		for _, c := range q.Conditions {
+			values = append(values, fmt.Sprintf("%s=%s", c.Path, r.Values[c.Path]))
+		}
+
+		fmt.Printf("  %s %s %s\n", r.LogicalId, console.Grey(r.Type), strings.Join(values, " "))
+	}
+
+	fmt.Println(console.Grey(fmt.Sprintf("Found %d resources in %d stacks", len(results), stacks)))
+}
+
+func init() {
+	Cmd.Flags().StringArrayVarP(&properties, "property", "P", []string{}, "Property condition, e.g. Engine=postgres or EngineVersion=11* (can be repeated)")
+	Cmd.Flags().StringVar(&stackPattern, "stack", "", "Only search stacks whose names match this pattern, e.g. prod-*")
+	Cmd.Flags().BoolVarP(&allRegions, "all", "a", false, "Search stacks in all regions")
+	Cmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output the results as JSON")
+	Cmd.Flags().BoolVar(&noCache, "no-cache", false, "Fetch every template again instead of using cached templates")
+}
@@ -0,0 +1,115 @@
+package similar
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/similar"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var threshold float64
+var draft bool
+
+// Cmd is the similar command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "similar <template> [<template>...]",
+	Short: "Find near-identical resources that could be extracted into a module",
+	Long: `Compares every resource in the templates with every other resource of the same type
+and reports groups of resources that are near-identical copies of each other.
+
+Each group can usually be replaced by a Rain module (see "rain pkg --help") or,
+if only a single property differs, an Fn::ForEach loop from the AWS::LanguageExtensions transform.
+Use --draft to print a draft module and Fn::ForEach block for each group.`,
+	Args:                  cobra.MinimumNArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		members := make([]similar.Member, 0)
+
+		for _, fn := range args {
+			t, err := parse.File(fn)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to parse template '%s'", fn))
+			}
+
+			file := ""
+			if len(args) > 1 {
+				file = fn
+			}
+
+			members = append(members, similar.Resources(file, t)...)
+		}
+
+		clusters := similar.Clusters(members, threshold)
+		if len(clusters) == 0 {
+			fmt.Println(console.Green("No similar resources found"))
+			return
+		}
+
+		for i, c := range clusters {
+			if i > 0 {
+				fmt.Println()
+			}
+
+			printCluster(i+1, c)
+		}
+	},
+}
+
+func printCluster(n int, c similar.Cluster) {
+	fmt.Printf("%s %s (%d resources, at least %d%% similar)\n",
+		console.Yellow(fmt.Sprintf("Group %d:", n)), c.Type, len(c.Members), int(c.Similarity*100))
+
+	for _, m := range c.Members {
+		fmt.Printf("  - %s\n", console.Blue(m.String()))
+	}
+
+	varying := c.Varying()
+	if len(varying) > 0 {
+		fmt.Printf("  Differs in: %s\n", strings.Join(varying, ", "))
+	}
+
+	if !draft {
+		return
+	}
+
+	moduleName := fmt.Sprintf("%s-module.yaml", strings.ToLower(strings.ReplaceAll(c.Type, "::", "-")))
+	module, usage := c.DraftModule("./" + moduleName)
+
+	fmt.Println()
+	fmt.Println(console.Grey(fmt.Sprintf("# Draft module: %s", moduleName)))
+	fmt.Println(toYaml(module))
+	fmt.Println()
+
+	fmt.Println(console.Grey("# Usage (note that module resources are renamed, e.g. <LogicalId>Resource)"))
+	fmt.Println(toYaml(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Value: "Resources"}, usage,
+	}}))
+
+	if forEach := c.DraftForEach(); forEach != nil {
+		fmt.Println()
+		fmt.Println(console.Grey("# Or, with Fn::ForEach (requires Transform: AWS::LanguageExtensions)"))
+		fmt.Println(toYaml(&yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+			{Kind: yaml.ScalarNode, Value: "Resources"}, forEach,
+		}}))
+	}
+}
+
+func toYaml(n *yaml.Node) string {
+	t, err := parse.Node(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{n}})
+	if err != nil {
+		panic(err)
+	}
+
+	return strings.TrimSpace(format.String(t, format.Options{Unsorted: true}))
+}
+
+func init() {
+	Cmd.Flags().Float64Var(&threshold, "threshold", similar.DefaultThreshold, "Minimum similarity (0.0-1.0) for resources to be grouped")
+	Cmd.Flags().BoolVar(&draft, "draft", false, "Print a draft module and Fn::ForEach block for each group")
+}
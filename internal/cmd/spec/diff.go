@@ -0,0 +1,196 @@
+package spec
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/spec"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var dir string
+var all bool
+var offline bool
+
+// DiffCmd is the spec diff command's entrypoint
+var DiffCmd = &cobra.Command{
+	Use:   "diff [<old> <new>]",
+	Short: "Show changes to the resource types that your templates use",
+	Long: `Downloads the latest CloudFormation resource specification for the region
+and keeps it in rain's cache, then compares the two most recent versions in the cache.
+<old> and <new> choose other versions from the cache, or specification files.
+
+Only the changes to the resource types that are used by the templates in --dir
+(the current directory by default) are shown, along with new resource types
+of the same services. Run it regularly, e.g. in CI, to notice when CloudFormation
+starts to support a property that you have been working around.
+Use --all to show every change.
+
+Nothing can be compared the first time the command is run, because only one
+version of the specification is in the cache.`,
+	Args:                  cobra.MatchAll(cobra.RangeArgs(0, 2), checkArgs),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !offline {
+			region := config.Region
+			if region == "" {
+				region = "us-east-1"
+			}
+
+			spinner.Push("Downloading the resource specification")
+			version, err := fetch(region)
+			spinner.Pop()
+			if err != nil {
+				panic(ui.Errorf(err, "unable to download the resource specification"))
+			}
+
+			config.Debugf("The latest resource specification is version %s", version)
+		}
+
+		if len(args) == 0 {
+			versions, err := cachedVersions()
+			if err != nil {
+				panic(ui.Errorf(err, "unable to read the cached resource specifications"))
+			}
+
+			if len(versions) < 2 {
+				fmt.Println(console.Yellow(fmt.Sprintf("There are %d versions of the resource specification in the cache, so there is nothing to compare yet. "+
+					"Run rain spec diff again after the specification is updated.", len(versions))))
+				return
+			}
+
+			args = versions[len(versions)-2:]
+		}
+
+		from, err := load(args[0])
+		if err != nil {
+			panic(ui.Errorf(err, "unable to load the old resource specification"))
+		}
+
+		to, err := load(args[1])
+		if err != nil {
+			panic(ui.Errorf(err, "unable to load the new resource specification"))
+		}
+
+		changes := spec.Diff(from, to)
+
+		versions := fmt.Sprintf("from version %s to %s of the resource specification",
+			from.ResourceSpecificationVersion, to.ResourceSpecificationVersion)
+		scope := ""
+
+		if !all {
+			used, err := usedTypes(dir)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to find the resource types used in '%s'", dir))
+			}
+
+			changes = spec.Relevant(changes, used)
+			scope = fmt.Sprintf(" that affect the %d resource types used in %s", len(used), dir)
+		}
+
+		if len(changes) == 0 {
+			fmt.Println(console.Green(fmt.Sprintf("No changes %s%s", versions, scope)))
+			return
+		}
+
+		fmt.Println(console.Yellow(fmt.Sprintf("Changes %s%s:", versions, scope)))
+		for _, c := range changes {
+			fmt.Println("  " + formatChange(c))
+		}
+	},
+}
+
+// checkArgs requires either no versions, or both
+func checkArgs(cmd *cobra.Command, args []string) error {
+	if len(args) == 1 {
+		return errors.New("specify both <old> and <new>, or neither")
+	}
+
+	return nil
+}
+
+// formatChange colours a change by whether it adds support or takes it away
+func formatChange(c spec.Change) string {
+	name := c.TypeName
+	if c.Name != "" {
+		name += "." + c.Name
+	}
+
+	switch c.Kind {
+	case spec.RemovedResourceType, spec.RemovedProperty, spec.RemovedAttribute, spec.NowRequired:
+		return console.Red(string(c.Kind)) + " " + name
+	default:
+		return console.Green(string(c.Kind)) + " " + name
+	}
+}
+
+// usedTypes returns the resource types of the templates in the directory and its subdirectories.
+// Hidden directories and files that are not templates are skipped.
+func usedTypes(root string) ([]string, error) {
+	seen := make(map[string]bool)
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			if path != root && (strings.HasPrefix(d.Name(), ".") || d.Name() == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		switch filepath.Ext(path) {
+		case ".yaml", ".yml", ".json", ".template":
+		default:
+			return nil
+		}
+
+		t, err := parse.File(path)
+		if err != nil {
+			config.Debugf("skipping '%s': %s", path, err)
+			return nil
+		}
+
+		types, err := t.GetTypes()
+		if err != nil {
+			return nil
+		}
+
+		for _, typeName := range types {
+			if strings.Count(typeName, "::") == 2 {
+				seen[typeName] = true
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]string, 0, len(seen))
+	for typeName := range seen {
+		out = append(out, typeName)
+	}
+	sort.Strings(out)
+
+	return out, nil
+}
+
+func init() {
+	DiffCmd.Flags().StringVar(&dir, "dir", ".", "Directory of the templates whose resource types are of interest")
+	DiffCmd.Flags().BoolVar(&all, "all", false, "Show the changes to every resource type")
+	DiffCmd.Flags().BoolVar(&offline, "offline", false, "Don't download the latest specification; only compare the cached versions")
+	DiffCmd.Flags().StringVarP(&config.Region, "region", "r", "", "AWS region whose specification is downloaded (default us-east-1)")
+}
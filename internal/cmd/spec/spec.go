@@ -0,0 +1,199 @@
+// Package spec implements the rain spec command, which keeps track of
+// changes to the CloudFormation resource specification
+package spec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/spec"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// CacheDir is where versions of the specification are kept.
+// If it is empty, they are kept in the user's cache directory.
+var CacheDir = ""
+
+// specURL returns the address of the latest specification for the region; tests replace it
+var specURL = func(region string) string {
+	if region == "us-east-1" {
+		return "https://d1uauaxba7bl26.cloudfront.net/latest/gzip/CloudFormationResourceSpecification.json"
+	}
+
+	return fmt.Sprintf("https://cfn-resource-specifications-%s-prod.s3.%s.amazonaws.com/latest/gzip/CloudFormationResourceSpecification.json", region, region)
+}
+
+// cacheDir returns the directory that versions of the specification are kept in
+func cacheDir() (string, error) {
+	if CacheDir != "" {
+		return CacheDir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "rain", "spec"), nil
+}
+
+// fetch downloads the latest specification for the region and keeps it in the cache.
+// It returns the version of the specification.
+func fetch(region string) (string, error) {
+	uri := specURL(region)
+	config.Debugf("Downloading %s", uri)
+
+	resp, err := http.Get(uri)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to download %s: %s", uri, resp.Status)
+	}
+
+	content, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	// The file is gzipped, which the http client only undoes if the server says so
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		r, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return "", err
+		}
+
+		content, err = io.ReadAll(r)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	s, err := spec.Parse(content)
+	if err != nil {
+		return "", err
+	}
+
+	dir, err := cacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+
+	fn := filepath.Join(dir, s.ResourceSpecificationVersion+".json")
+	if _, err := os.Stat(fn); err == nil {
+		return s.ResourceSpecificationVersion, nil
+	}
+
+	if err := os.WriteFile(fn, content, 0644); err != nil {
+		return "", err
+	}
+
+	return s.ResourceSpecificationVersion, nil
+}
+
+// lessVersion compares versions such as 9.0.0 and 10.1.0 by number
+func lessVersion(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+
+		if aErr != nil || bErr != nil {
+			if as[i] != bs[i] {
+				return as[i] < bs[i]
+			}
+			continue
+		}
+
+		if an != bn {
+			return an < bn
+		}
+	}
+
+	return len(as) < len(bs)
+}
+
+// cachedVersions returns the versions of the specification in the cache, oldest first
+func cachedVersions() ([]string, error) {
+	dir, err := cacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return []string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0)
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".json" {
+			versions = append(versions, strings.TrimSuffix(e.Name(), ".json"))
+		}
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return lessVersion(versions[i], versions[j])
+	})
+
+	return versions, nil
+}
+
+// load reads a version of the specification from the cache, or from a file
+func load(versionOrFile string) (*spec.Spec, error) {
+	fn := versionOrFile
+
+	if _, err := os.Stat(fn); err != nil {
+		dir, err := cacheDir()
+		if err != nil {
+			return nil, err
+		}
+
+		fn = filepath.Join(dir, versionOrFile+".json")
+	}
+
+	content, err := os.ReadFile(fn)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("version %s of the specification is not in the cache", versionOrFile)
+	} else if err != nil {
+		return nil, err
+	}
+
+	s, err := spec.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read '%s': %w", fn, err)
+	}
+
+	return s, nil
+}
+
+// Cmd is the spec command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "spec <command>",
+	Short: "Track changes to the CloudFormation resource specification",
+	Long: `Keeps versions of the CloudFormation resource specification,
+which lists the resource types and properties that CloudFormation supports,
+and reports the changes between them.`,
+}
+
+func init() {
+	Cmd.AddCommand(DiffCmd)
+}
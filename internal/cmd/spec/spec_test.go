@@ -0,0 +1,104 @@
+package spec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func serveSpec(t *testing.T, version string) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(`{"ResourceSpecificationVersion": "` + version + `", "ResourceTypes": {}}`))
+	w.Close()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(buf.Bytes())
+	}))
+	t.Cleanup(server.Close)
+
+	original := specURL
+	specURL = func(region string) string {
+		return server.URL + "/" + region
+	}
+	t.Cleanup(func() { specURL = original })
+}
+
+func TestFetch(t *testing.T) {
+	CacheDir = t.TempDir()
+	defer func() { CacheDir = "" }()
+
+	for _, version := range []string{"9.0.0", "10.1.0", "10.0.0", "10.1.0"} {
+		serveSpec(t, version)
+
+		got, err := fetch("us-west-2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got != version {
+			t.Errorf("expected version %s, got %s", version, got)
+		}
+	}
+
+	versions, err := cachedVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(versions, " "); got != "9.0.0 10.0.0 10.1.0" {
+		t.Errorf("unexpected cached versions: %s", got)
+	}
+
+	s, err := load("10.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.ResourceSpecificationVersion != "10.0.0" {
+		t.Errorf("loaded the wrong version: %s", s.ResourceSpecificationVersion)
+	}
+
+	if _, err := load("11.0.0"); err == nil {
+		t.Error("expected an error for a version that is not cached")
+	}
+}
+
+func TestUsedTypes(t *testing.T) {
+	root := t.TempDir()
+
+	files := map[string]string{
+		"app.yaml":              "Resources:\n  Bucket:\n    Type: AWS::S3::Bucket\n  Mod:\n    Type: !Rain::Module m.yaml\n",
+		"nested/queue.json":     `{"Resources": {"Queue": {"Type": "AWS::SQS::Queue"}}}`,
+		"nested/config.yaml":    "Parameters:\n  Name: app\n",
+		".hidden/ignored.yaml":  "Resources:\n  Topic:\n    Type: AWS::SNS::Topic\n",
+		"README.md":             "# Not a template",
+		"nested/broken.yaml":    "Resources: [",
+		"nested/template.yml":   "Resources:\n  Other:\n    Type: AWS::S3::Bucket\n",
+		"node_modules/x/t.yaml": "Resources:\n  Fn:\n    Type: AWS::Lambda::Function\n",
+	}
+
+	for name, content := range files {
+		fn := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(fn), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(fn, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	used, err := usedTypes(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Join(used, " "); got != "AWS::S3::Bucket AWS::SQS::Queue" {
+		t.Errorf("unexpected types: %s", got)
+	}
+}
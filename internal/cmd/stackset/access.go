@@ -0,0 +1,80 @@
+package stackset
+
+import (
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/aws/organizations"
+	"github.com/aws-cloudformation/rain/internal/aws/sts"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/spf13/cobra"
+)
+
+// access is how the current account can work with stack sets. It is set before each command runs.
+var access cfn.StackSetAccess
+
+// detectAccess works out whether the current account is its organization's management account
+// or a delegated administrator for StackSets, and calls as a delegated administrator if it is one.
+// --admin overrides the detection.
+func detectAccess(cmd *cobra.Command, args []string) {
+	spinner.Push("Checking stack set permissions")
+	defer spinner.Pop()
+
+	account, err := sts.GetAccountID()
+	if err != nil {
+		panic(ui.Errorf(err, "unable to get the current account"))
+	}
+
+	access = cfn.StackSetAccess{Account: account}
+
+	// Accounts that aren't in an organization work with their own stack sets
+	if org, err := organizations.DescribeOrganization(); err == nil {
+		access.ManagementAccount = org.MasterAccountId
+	} else {
+		config.Debugf("unable to describe the organization of account %s: %v", account, err)
+	}
+
+	if cmd.Flags().Changed("admin") {
+		access.DelegatedAdmin = delegatedAdmin
+		return
+	}
+
+	if access.ManagementAccount != "" && !access.IsManagementAccount() {
+		access.DelegatedAdmin, err = cfn.IsDelegatedAdmin()
+		if err != nil {
+			config.Debugf("unable to check whether account %s is a delegated administrator: %v", account, err)
+		}
+	}
+
+	delegatedAdmin = access.CallAs() == types.CallAsDelegatedAdmin
+	if delegatedAdmin {
+		spinner.Pause()
+		console.Notef("Account %s is a delegated administrator for StackSets; using delegated administrator permissions", account)
+		spinner.Resume()
+	}
+}
+
+// checkServiceManaged stops rain with the steps to register the account as a delegated administrator
+// if the stack set uses service-managed permissions and the account can't work with it
+func checkServiceManaged(serviceManaged bool) {
+	if !serviceManaged {
+		return
+	}
+
+	if err := access.CheckServiceManaged(); err != nil {
+		panic(err)
+	}
+}
+
+// isServiceManaged returns true if the config describes a service-managed stack set,
+// or instances that are deployed to organizational units
+func isServiceManaged(c configFormat) bool {
+	if c.StackSet.PermissionModel == types.PermissionModelsServiceManaged {
+		return true
+	}
+
+	targets := c.StackSetInstances.DeploymentTargets
+	return targets != nil && len(targets.OrganizationalUnitIds) > 0
+}
@@ -0,0 +1,104 @@
+package stackset
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// stackSetType is the event that the stack set commands write with the state of a stack set
+const stackSetType = "stack_set"
+
+// stackSetData is the data of a stack_set event
+type stackSetData struct {
+	StackSetName string          `json:"stackSetName"`
+	StackSetId   string          `json:"stackSetId,omitempty"`
+	Region       string          `json:"region,omitempty"`
+	Status       string          `json:"status"`
+	Instances    []instanceData  `json:"instances,omitempty"`
+	Operations   []operationData `json:"operations,omitempty"`
+}
+
+// instanceData is a stack set instance in a stack_set event
+type instanceData struct {
+	Account              string `json:"account"`
+	Region               string `json:"region"`
+	OrganizationalUnitId string `json:"organizationalUnitId,omitempty"`
+	StackId              string `json:"stackId,omitempty"`
+	Status               string `json:"status"`
+	Reason               string `json:"reason,omitempty"`
+}
+
+// operationData is a stack set operation in a stack_set event
+type operationData struct {
+	OperationId string     `json:"operationId"`
+	Action      string     `json:"action"`
+	Status      string     `json:"status"`
+	Created     *time.Time `json:"created,omitempty"`
+	Completed   *time.Time `json:"completed,omitempty"`
+}
+
+func newStackSetData(stackSet types.StackSet, instances []types.StackInstanceSummary, ops []types.StackSetOperationSummary) stackSetData {
+	data := stackSetData{
+		StackSetName: ptr.ToString(stackSet.StackSetName),
+		StackSetId:   ptr.ToString(stackSet.StackSetId),
+		Status:       string(stackSet.Status),
+	}
+
+	for _, i := range instances {
+		status := string(i.Status)
+		if i.StackInstanceStatus != nil {
+			status = string(i.StackInstanceStatus.DetailedStatus)
+		}
+
+		data.Instances = append(data.Instances, instanceData{
+			Account:              ptr.ToString(i.Account),
+			Region:               ptr.ToString(i.Region),
+			OrganizationalUnitId: ptr.ToString(i.OrganizationalUnitId),
+			StackId:              ptr.ToString(i.StackId),
+			Status:               status,
+			Reason:               ptr.ToString(i.StatusReason),
+		})
+	}
+
+	for _, o := range ops {
+		data.Operations = append(data.Operations, operationData{
+			OperationId: ptr.ToString(o.OperationId),
+			Action:      string(o.Action),
+			Status:      string(o.Status),
+			Created:     o.CreationTimestamp,
+			Completed:   o.EndTimestamp,
+		})
+	}
+
+	return data
+}
+
+// emitStackSet writes the state of the stack set and its instances once a command has changed them.
+// It only looks them up if structured output is turned on.
+func emitStackSet(stackSetName string) {
+	if !emit.Enabled() {
+		return
+	}
+
+	spinner.Push(fmt.Sprintf("Fetching stack set '%s'", stackSetName))
+	defer spinner.Pop()
+
+	stackSet, err := cfn.GetStackSet(stackSetName, delegatedAdmin)
+	if err != nil {
+		panic(ui.Errorf(err, "unable to find stack set '%s'", stackSetName))
+	}
+
+	instances, err := cfn.ListStackSetInstances(stackSetName, delegatedAdmin)
+	if err != nil {
+		panic(ui.Errorf(err, "failed to list stack set instances"))
+	}
+
+	emit.Event(stackSetType, newStackSetData(*stackSet, instances, nil))
+}
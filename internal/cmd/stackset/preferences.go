@@ -0,0 +1,95 @@
+package stackset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var maxConcurrentCount int
+var maxConcurrentPercentage int
+var failureToleranceCount int
+var failureTolerancePercentage int
+var regionConcurrency string
+var concurrencyMode string
+
+const preferencesHelp = `
+The operation preference flags control how many accounts CloudFormation works on at once,
+how many failures it tolerates in each region before it stops, and whether it works through
+the regions one at a time or all at once. They override OperationPreferences in a config file.`
+
+// addPreferenceFlags adds the flags that set a stack set operation's preferences.
+// action describes the operation, e.g. "deploy to".
+func addPreferenceFlags(cmd *cobra.Command, action string) {
+	cmd.Flags().IntVar(&maxConcurrentCount, "max-concurrent-count", 0, fmt.Sprintf("the most accounts to %s at once", action))
+	cmd.Flags().IntVar(&maxConcurrentPercentage, "max-concurrent-percentage", 0, fmt.Sprintf("the most accounts to %s at once, as a percentage of the accounts", action))
+	cmd.Flags().IntVar(&failureToleranceCount, "failure-tolerance-count", 0, "the number of accounts per region that can fail before the operation stops")
+	cmd.Flags().IntVar(&failureTolerancePercentage, "failure-tolerance-percentage", 0, "the percentage of accounts per region that can fail before the operation stops")
+	cmd.Flags().StringVar(&regionConcurrency, "region-concurrency", "", fmt.Sprintf("%s one region at a time (SEQUENTIAL) or all at once (PARALLEL)", action))
+	cmd.Flags().StringVar(&concurrencyMode, "concurrency-mode", "", "STRICT_FAILURE_TOLERANCE to limit concurrency to the failure tolerance, or SOFT_FAILURE_TOLERANCE to run up to the maximum concurrency regardless of failures")
+}
+
+// operationPreferences returns the preferences in base, usually from a config file,
+// with any that are set by flags replaced. It returns nil to use CloudFormation's defaults.
+func operationPreferences(cmd *cobra.Command, base *types.StackSetOperationPreferences) (*types.StackSetOperationPreferences, error) {
+	changed := cmd.Flags().Changed
+
+	prefs := &types.StackSetOperationPreferences{}
+	if base != nil {
+		*prefs = *base
+	}
+
+	// A count from a flag replaces a percentage from the config file, and the other way round,
+	// but setting both with flags is an error
+	if changed("max-concurrent-count") {
+		prefs.MaxConcurrentCount = ptr.Int32(int32(maxConcurrentCount))
+		if !changed("max-concurrent-percentage") {
+			prefs.MaxConcurrentPercentage = nil
+		}
+	}
+
+	if changed("max-concurrent-percentage") {
+		prefs.MaxConcurrentPercentage = ptr.Int32(int32(maxConcurrentPercentage))
+		if !changed("max-concurrent-count") {
+			prefs.MaxConcurrentCount = nil
+		}
+	}
+
+	if changed("failure-tolerance-count") {
+		prefs.FailureToleranceCount = ptr.Int32(int32(failureToleranceCount))
+		if !changed("failure-tolerance-percentage") {
+			prefs.FailureTolerancePercentage = nil
+		}
+	}
+
+	if changed("failure-tolerance-percentage") {
+		prefs.FailureTolerancePercentage = ptr.Int32(int32(failureTolerancePercentage))
+		if !changed("failure-tolerance-count") {
+			prefs.FailureToleranceCount = nil
+		}
+	}
+
+	if regionConcurrency != "" {
+		prefs.RegionConcurrencyType = types.RegionConcurrencyType(strings.ToUpper(regionConcurrency))
+	}
+
+	if concurrencyMode != "" {
+		prefs.ConcurrencyMode = types.ConcurrencyMode(strings.ToUpper(concurrencyMode))
+	}
+
+	if err := cfn.ValidateOperationPreferences(prefs); err != nil {
+		return nil, fmt.Errorf("invalid operation preferences: %w", err)
+	}
+
+	if prefs.MaxConcurrentCount == nil && prefs.MaxConcurrentPercentage == nil &&
+		prefs.FailureToleranceCount == nil && prefs.FailureTolerancePercentage == nil &&
+		prefs.RegionConcurrencyType == "" && prefs.ConcurrencyMode == "" && len(prefs.RegionOrder) == 0 {
+		return nil, nil
+	}
+
+	return prefs, nil
+}
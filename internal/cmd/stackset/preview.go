@@ -0,0 +1,94 @@
+package stackset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/aws/organizations"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+)
+
+var preview bool
+
+// previewInstances shows the accounts and regions that stack set instances would be created in,
+// resolving organizational units to their accounts, and asks to continue unless --yes was set.
+// It returns false if the user doesn't want to continue.
+func previewInstances(c cfn.StackSetInstancesConfig) bool {
+	if !preview {
+		return true
+	}
+
+	accounts := c.Accounts
+	names := make(map[string]string)
+
+	if c.DeploymentTargets != nil && len(c.DeploymentTargets.OrganizationalUnitIds) > 0 {
+		ouAccounts := make([]string, 0)
+
+		for _, ou := range c.DeploymentTargets.OrganizationalUnitIds {
+			spinner.Push(fmt.Sprintf("Listing the accounts in organizational unit '%s'", ou))
+			found, err := organizations.ListAccountsInOU(ou)
+			spinner.Pop()
+			if err != nil {
+				panic(ui.Errorf(err, "unable to list the accounts in organizational unit '%s'; "+
+					"--preview needs to be run from the management account or a delegated administrator for AWS Organizations", ou))
+			}
+
+			fmt.Printf("Organizational unit %s: %d accounts\n", ou, len(found))
+			for _, a := range found {
+				ouAccounts = append(ouAccounts, a.Id)
+				names[a.Id] = a.Name
+			}
+		}
+
+		accounts = cfn.FilterTargetAccounts(c.DeploymentTargets, ouAccounts)
+	}
+
+	sort.Strings(accounts)
+
+	if len(accounts) == 0 || len(c.Regions) == 0 {
+		fmt.Println(console.Yellow("No stack set instances would be created"))
+		return true
+	}
+
+	fmt.Println(formatMatrix(accounts, names, c.Regions))
+
+	count := len(accounts) * len(c.Regions)
+	summary := fmt.Sprintf("%d stack set instances in %d accounts and %d regions", count, len(accounts), len(c.Regions))
+
+	if forceUpdate {
+		fmt.Println("Creating " + summary)
+		return true
+	}
+
+	return console.Confirm(true, fmt.Sprintf("Create %s?", summary))
+}
+
+// formatMatrix lays out the accounts as rows and the regions as columns, with a mark for each instance
+func formatMatrix(accounts []string, names map[string]string, regions []string) string {
+	nameWidth := len("Name")
+	for _, a := range accounts {
+		nameWidth = max(nameWidth, len(names[a]))
+	}
+
+	out := strings.Builder{}
+
+	out.WriteString(fmt.Sprintf("%-12s  %-*s", "Account", nameWidth, "Name"))
+	for _, r := range regions {
+		out.WriteString("  " + console.Bold(r))
+	}
+	out.WriteString("\n")
+
+	for _, a := range accounts {
+		out.WriteString(fmt.Sprintf("%-12s  %-*s", a, nameWidth, names[a]))
+		for _, r := range regions {
+			out.WriteString("  " + console.Green(fmt.Sprintf("%-*s", len(r), "+")))
+		}
+		out.WriteString("\n")
+	}
+
+	return out.String()
+}
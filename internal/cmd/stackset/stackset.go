@@ -39,7 +39,9 @@ func addCommand(profileOptions bool, c *cobra.Command) {
 		c.Flags().StringVarP(&config.Region, "region", "r", "", "AWS region to use")
 	}
 
-	c.Flags().BoolVar(&delegatedAdmin, "admin", false, "Use delegated admin permissions")
+	c.Flags().BoolVar(&delegatedAdmin, "admin", false, "Use delegated admin permissions; by default, rain uses them if the account is a delegated administrator for StackSets")
+
+	c.PreRun = detectAccess
 
 	StackSetCmd.AddCommand(c)
 }
@@ -54,6 +56,8 @@ func init() {
 	addCommand(true, LsCmd)
 	addCommand(true, DeployCmd)
 	addCommand(true, RmCmd)
+	addCommand(true, RmInstancesCmd)
+	addCommand(true, DiffCmd)
 
 	oldUsageFunc := StackSetCmd.UsageFunc()
 	StackSetCmd.SetUsageFunc(func(c *cobra.Command) error {
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"time"
 
 	"github.com/aws-cloudformation/rain/cft"
 	"github.com/aws-cloudformation/rain/cft/format"
@@ -68,9 +69,22 @@ StackSetInstances:
 	regions:
 		- us-east-1
 		- us-east-2
+	operationpreferences:
+		maxconcurrentcount: 5
+		failuretolerancecount: 4
+		regionconcurrencytype: PARALLEL
 ...
 
+A value in Parameters or Tags can be the output of another stack, which is read when the stack set is deployed:
+Parameters:
+	VpcId: !StackOutput network.VpcId
+
 Account(s) and region(s) provided as flags OVERRIDE values from configuration files. Tags and parameters from the configuration file are MERGED with CLI flag values. 
+
+Use --preview to see every account and region that new instances would be created in before they are created.
+Organizational units in DeploymentTargets are resolved to their accounts, including the accounts in nested organizational units,
+which needs permission to list them from the management account or a delegated administrator for AWS Organizations.
+` + preferencesHelp + `
 `,
 	Args:                  cobra.RangeArgs(1, 2),
 	DisableFlagsInUseLine: false,
@@ -94,6 +108,13 @@ Account(s) and region(s) provided as flags OVERRIDE values from configuration fi
 
 		// Override config data with CLI flag values
 		combineConfigDataWithCliFlags(&configData, cliParamFlags, cliTagFlags, accounts, regions)
+		checkServiceManaged(isServiceManaged(configData))
+
+		prefs, err := operationPreferences(cmd, configData.StackSetInstances.OperationPreferences)
+		if err != nil {
+			panic(err)
+		}
+		configData.StackSetInstances.OperationPreferences = prefs
 
 		// Get current stack set if exist
 		spinner.Push(fmt.Sprintf("Checking current status of stack set '%s'", stackSetName))
@@ -102,6 +123,7 @@ Account(s) and region(s) provided as flags OVERRIDE values from configuration fi
 		isStacksetExists := false
 		if err == nil && existingStackSet.Status != types.StackSetStatusDeleted {
 			isStacksetExists = true
+			checkServiceManaged(existingStackSet.PermissionModel == types.PermissionModelsServiceManaged)
 		}
 		configData.StackSet.StackSetName = stackSetName
 		configData.StackSetInstances.StackSetName = stackSetName
@@ -134,6 +156,7 @@ Account(s) and region(s) provided as flags OVERRIDE values from configuration fi
 				if !ignoreStackInstances {
 					addInstances(configData)
 				}
+				emitStackSet(stackSetName)
 
 			} else {
 				fmt.Println(console.Yellow("operation was cancelled by user"))
@@ -154,7 +177,10 @@ func init() {
 	DeployCmd.Flags().StringSliceVar(&params, "params", []string{}, "set parameter values; use the format key1=value1,key2=value2")
 	DeployCmd.Flags().StringVarP(&configFilePath, "config", "c", "", "YAML or JSON file to set additional configuration parameters")
 	DeployCmd.Flags().BoolVarP(&forceUpdate, "yes", "y", false, "update the stackset without confirmation")
+	DeployCmd.Flags().BoolVar(&preview, "preview", false, "show the accounts and regions that stack set instances would be created in, resolving organizational units to their accounts, and ask before creating them")
 	DeployCmd.Flags().BoolVarP(&ignoreStackInstances, "ignore-stack-instances", "i", false, "ignores adding or removing stack instances while updating, useful if you are managing the stack instances separately")
+	addPreferenceFlags(DeployCmd, "deploy to")
+	DeployCmd.Flags().DurationVar(&cfn.StackSetQueueTimeout, "queue-timeout", time.Hour, "how long to wait for other operations on the stack set to finish before giving up")
 }
 
 func readConfiguration(configFilePath string) configFormat {
@@ -172,6 +198,17 @@ func readConfiguration(configFilePath string) configFormat {
 		if err != nil {
 			panic(ui.Errorf(err, "unable to parse yaml in '%s'", configFilePath))
 		}
+
+		sections := map[string]map[string]string{
+			"Parameters": configData.Parameters,
+			"Tags":       configData.Tags,
+		}
+		for section, values := range sections {
+			err = dc.ResolveStackOutputs(configFileContent, section, values)
+			if err != nil {
+				panic(ui.Errorf(err, "unable to resolve stack outputs in '%s'", configFilePath))
+			}
+		}
 	}
 	return configData
 }
@@ -329,6 +366,11 @@ func createStackSet(configData configFormat) {
 	config.Debugf("Stack Set Configuration: \n%s\n", format.PrettyPrint(stackSetConfig))
 	stackSetConfig.Template = configData.StackSet.Template
 
+	if isInstanceConfigDataValid(&configData.StackSetInstances) && !previewInstances(configData.StackSetInstances) {
+		fmt.Println(console.Yellow("operation was cancelled by user"))
+		return
+	}
+
 	if delegatedAdmin {
 		stackSetConfig.CallAs = types.CallAsDelegatedAdmin
 	}
@@ -366,6 +408,8 @@ func createStackSet(configData configFormat) {
 	} else {
 		fmt.Println("Not enough information provided to create stack set instance(s). Please use configuration file or provide account(s) and region(s) for deployment as command argiments")
 	}
+
+	emitStackSet(configData.StackSet.StackSetName)
 }
 
 // converts 'string' parameters to typed objects
@@ -432,7 +476,12 @@ func addInstances(configData configFormat) {
 	// check if we have accounts and regions to update
 	if !isInstanceConfigDataValid(&configData.StackSetInstances) {
 		fmt.Println("There are no new instances to be created.")
-		os.Exit(0)
+		return
+	}
+
+	if !previewInstances(configData.StackSetInstances) {
+		fmt.Println(console.Yellow("operation was cancelled by user"))
+		return
 	}
 
 	spinner.Push("Adding stack set instances")
@@ -0,0 +1,120 @@
+package stackset
+
+import (
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft/diff"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/stacksetdiff"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var longDiff bool
+
+// DiffCmd is the diff command's entrypoint
+var DiffCmd = &cobra.Command{
+	Use:   "diff <stackset> [template]",
+	Short: "Show what rain stackset deploy would change in a stack set",
+	Long: `Compares the stack set <stackset> with the config file and flags that rain stackset deploy would update it with,
+and shows what would change: the description, permission model, parameters and tags,
+and which accounts (or organizational units) and regions would get new instances.
+Instances that the config doesn't list are shown too; rain stackset deploy leaves them as they are.
+
+If you pass a template, it is compared with the stack set's template. The template is not packaged,
+so artifacts that rain would upload show up as differences.
+
+Settings and parameters that the config leaves out are not compared, because rain stackset deploy keeps their current values.
+`,
+	Args:                  cobra.RangeArgs(1, 2),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		stackSetName := args[0]
+
+		configData := readConfiguration(configFilePath)
+		combineConfigDataWithCliFlags(&configData, dc.ListToMap("param", params), dc.ListToMap("tag", tags), accounts, regions)
+		checkServiceManaged(isServiceManaged(configData))
+
+		spinner.Push(fmt.Sprintf("Fetching stack set '%s'", stackSetName))
+		stackSet, err := cfn.GetStackSet(stackSetName, delegatedAdmin)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "unable to find stack set '%s'", stackSetName))
+		}
+		if stackSet.Status == types.StackSetStatusDeleted {
+			panic(fmt.Errorf("stack set '%s' has been deleted", stackSetName))
+		}
+
+		spinner.Push(fmt.Sprintf("Fetching stack set instances for '%s'", stackSetName))
+		instances, err := cfn.ListStackSetInstances(stackSetName, delegatedAdmin)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "failed to list stack set instances"))
+		}
+
+		desired := stacksetdiff.Desired{
+			Description:     ptr.ToString(configData.StackSet.Description),
+			PermissionModel: string(configData.StackSet.PermissionModel),
+			Parameters:      configData.Parameters,
+			Tags:            configData.Tags,
+			Accounts:        configData.StackSetInstances.Accounts,
+			Regions:         configData.StackSetInstances.Regions,
+		}
+		if targets := configData.StackSetInstances.DeploymentTargets; targets != nil {
+			desired.OrganizationalUnits = targets.OrganizationalUnitIds
+		}
+
+		result := stacksetdiff.Compare(desired, stackSet, instances)
+
+		var templateDiff diff.Diff
+		if len(args) == 2 {
+			deployed, err := parse.String(ptr.ToString(stackSet.TemplateBody))
+			if err != nil {
+				panic(ui.Errorf(err, "unable to parse the template of stack set '%s'", stackSetName))
+			}
+
+			local, err := parse.File(args[1])
+			if err != nil {
+				panic(ui.Errorf(err, "unable to parse template '%s'", args[1]))
+			}
+
+			templateDiff = diff.New(deployed, local)
+		}
+
+		templateChanged := templateDiff != nil && templateDiff.Mode() != diff.Unchanged
+
+		data := map[string]any{"stackSetName": stackSetName, "changes": result}
+		if templateDiff != nil {
+			data["template"] = diff.Changes(templateDiff)
+		}
+		emit.Event("stack_set_diff", data)
+
+		if result.IsEmpty() && !templateChanged {
+			fmt.Println(console.Green(fmt.Sprintf("Stack set '%s' already matches the config", stackSetName)))
+			return
+		}
+
+		fmt.Print(result.String())
+
+		if templateChanged {
+			fmt.Println(console.Yellow("Template:"))
+			fmt.Println(ui.ColouriseDiff(templateDiff, longDiff))
+		}
+	},
+}
+
+func init() {
+	DiffCmd.Flags().StringVarP(&configFilePath, "config", "c", "", "YAML or JSON file with the stack set's configuration, as used by rain stackset deploy")
+	DiffCmd.Flags().StringSliceVar(&accounts, "accounts", []string{}, "accounts that should have stack set instances")
+	DiffCmd.Flags().StringSliceVar(&regions, "regions", []string{}, "regions that should have stack set instances")
+	DiffCmd.Flags().StringSliceVar(&tags, "tags", []string{}, "tags for the stack set; use the format key1=value1,key2=value2")
+	DiffCmd.Flags().StringSliceVar(&params, "params", []string{}, "parameter values; use the format key1=value1,key2=value2")
+	DiffCmd.Flags().BoolVarP(&longDiff, "long", "l", false, "include unchanged elements of the template in the output")
+}
@@ -0,0 +1,156 @@
+package stackset
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"gopkg.in/yaml.v3"
+)
+
+// exportFormat is configFormat without the empty fields,
+// using the keys that configFormat is read with
+type exportFormat struct {
+	Parameters        map[string]string `yaml:"Parameters,omitempty"`
+	Tags              map[string]string `yaml:"Tags,omitempty"`
+	StackSet          map[string]any    `yaml:"StackSet,omitempty"`
+	StackSetInstances map[string]any    `yaml:"StackSetInstances,omitempty"`
+}
+
+// unique returns the sorted, distinct values
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	out := make([]string, 0)
+
+	for _, v := range values {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+
+	sort.Strings(out)
+
+	return out
+}
+
+// exportStackSet returns the config for the stack set and its instances,
+// in the format that rain stackset deploy --config reads.
+// It also returns warnings about settings that the config can't express.
+func exportStackSet(stackSet types.StackSet, instances []types.StackInstanceSummary) (exportFormat, []string) {
+	out := exportFormat{
+		Parameters:        make(map[string]string),
+		Tags:              make(map[string]string),
+		StackSet:          make(map[string]any),
+		StackSetInstances: make(map[string]any),
+	}
+
+	warnings := make([]string, 0)
+
+	for _, p := range stackSet.Parameters {
+		key, value := ptr.ToString(p.ParameterKey), ptr.ToString(p.ParameterValue)
+		if value == "****" {
+			warnings = append(warnings, fmt.Sprintf("parameter '%s' is NoEcho, so its value is not exported", key))
+			continue
+		}
+		out.Parameters[key] = value
+	}
+
+	for _, t := range stackSet.Tags {
+		out.Tags[ptr.ToString(t.Key)] = ptr.ToString(t.Value)
+	}
+
+	if stackSet.Description != nil {
+		out.StackSet["description"] = *stackSet.Description
+	}
+
+	if stackSet.PermissionModel != "" {
+		out.StackSet["permissionmodel"] = string(stackSet.PermissionModel)
+	}
+
+	if stackSet.AdministrationRoleARN != nil {
+		out.StackSet["administrationrolearn"] = *stackSet.AdministrationRoleARN
+	}
+
+	if stackSet.ExecutionRoleName != nil {
+		out.StackSet["executionrolename"] = *stackSet.ExecutionRoleName
+	}
+
+	if len(stackSet.Capabilities) > 0 {
+		out.StackSet["capabilities"] = stackSet.Capabilities
+	}
+
+	if a := stackSet.AutoDeployment; a != nil {
+		out.StackSet["autodeployment"] = map[string]bool{
+			"enabled":                      ptr.ToBool(a.Enabled),
+			"retainstacksonaccountremoval": ptr.ToBool(a.RetainStacksOnAccountRemoval),
+		}
+	}
+
+	if m := stackSet.ManagedExecution; m != nil && ptr.ToBool(m.Active) {
+		out.StackSet["managedexecution"] = map[string]bool{"active": true}
+	}
+
+	accounts := make([]string, 0)
+	regions := make([]string, 0)
+	units := make([]string, 0)
+	pairs := make(map[string]bool)
+
+	for _, i := range instances {
+		account, region := ptr.ToString(i.Account), ptr.ToString(i.Region)
+		accounts = append(accounts, account)
+		regions = append(regions, region)
+		units = append(units, ptr.ToString(i.OrganizationalUnitId))
+		pairs[account+"/"+region] = true
+	}
+
+	accounts, regions, units = unique(accounts), unique(regions), unique(units)
+
+	if len(regions) > 0 {
+		out.StackSetInstances["regions"] = regions
+	}
+
+	if stackSet.PermissionModel == types.PermissionModelsServiceManaged && len(units) > 0 {
+		out.StackSetInstances["deploymenttargets"] = map[string][]string{"organizationalunitids": units}
+	} else if len(accounts) > 0 {
+		out.StackSetInstances["accounts"] = accounts
+
+		// The config deploys to every region in every account
+		if len(pairs) != len(accounts)*len(regions) {
+			warnings = append(warnings, "the stack set does not have an instance in every region of every account; "+
+				"the config deploys to all of the regions in all of the accounts")
+		}
+	}
+
+	return out, warnings
+}
+
+// ConfigFromStackSet returns a config file for a deployed stack set and its instances,
+// in the format that rain stackset deploy --config reads, with warnings about
+// settings that the config file can't express
+func ConfigFromStackSet(stackSetName string, admin bool) (string, []string, error) {
+	stackSet, err := cfn.GetStackSet(stackSetName, admin)
+	if err != nil {
+		return "", nil, err
+	}
+
+	instances, err := cfn.ListStackSetInstances(stackSetName, admin)
+	if err != nil {
+		return "", nil, err
+	}
+
+	out, warnings := exportStackSet(*stackSet, instances)
+
+	var content strings.Builder
+
+	enc := yaml.NewEncoder(&content)
+	enc.SetIndent(2)
+	if err := enc.Encode(out); err != nil {
+		return "", nil, err
+	}
+
+	return content.String(), warnings, nil
+}
@@ -12,7 +12,9 @@ import (
 	"github.com/aws-cloudformation/rain/internal/aws/ec2"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
 	"github.com/spf13/cobra"
 )
 
@@ -43,6 +45,7 @@ var LsCmd = &cobra.Command{
 			}
 
 			origRegion := aws.Config().Region
+			listed := make([]stackSetData, 0)
 
 			for _, region := range regions {
 				spinner.Push(fmt.Sprintf("Fetching stack sets in %s", region))
@@ -69,6 +72,14 @@ var LsCmd = &cobra.Command{
 
 				fmt.Println(console.Yellow(fmt.Sprintf("CloudFormation stack sets in %s:", region)))
 				for _, stackSetName := range stackSetNames {
+					summary := stackSetMap[stackSetName+region]
+					listed = append(listed, stackSetData{
+						StackSetName: stackSetName,
+						StackSetId:   ptr.ToString(summary.StackSetId),
+						Region:       region,
+						Status:       string(summary.Status),
+					})
+
 					out := strings.Builder{}
 					out.WriteString(fmt.Sprintf("%s: %s\n",
 						stackSetName,
@@ -79,6 +90,8 @@ var LsCmd = &cobra.Command{
 			}
 
 			aws.SetRegion(origRegion)
+
+			emit.Event("stack_sets", listed)
 		}
 
 		// Reset flags
@@ -90,10 +103,8 @@ func init() {
 	LsCmd.Flags().BoolVarP(&all, "all", "a", false, "list stacks in all regions; if you specify a stack set name, show more details")
 }
 
-// returns a string with stack set instances for a given stack set
-func getStackSetInstances(stackSetName string) string {
-	out := strings.Builder{}
-	out.WriteString(console.Yellow("Instances (StackID/Account/Region/Status/Reason):\n"))
+// returns the stack set instances for a given stack set
+func getStackSetInstances(stackSetName string) []types.StackInstanceSummary {
 	spinner.Push(fmt.Sprintf("Fetching stack set instances for '%s'", stackSetName))
 	instances, err := cfn.ListStackSetInstances(stackSetName, delegatedAdmin)
 	if err != nil {
@@ -101,6 +112,14 @@ func getStackSetInstances(stackSetName string) string {
 	}
 	spinner.Pop()
 
+	return instances
+}
+
+// returns a string with the stack set instances
+func formatStackSetInstances(instances []types.StackInstanceSummary) string {
+	out := strings.Builder{}
+	out.WriteString(console.Yellow("Instances (StackID/Account/Region/Status/Reason):\n"))
+
 	if len(instances) == 0 {
 		out.WriteString(" - \n")
 		return out.String()
@@ -132,10 +151,8 @@ func getStackSetInstances(stackSetName string) string {
 	return out.String()
 }
 
-// returns a display string with last 10 stack set operations for a given stack set
-func getStackSetOperations(stackSetName string) string {
-	out := strings.Builder{}
-	out.WriteString(console.Yellow("Last 10 operations (ID/Type/Status/Created/Completed):\n"))
+// returns the last 10 stack set operations for a given stack set
+func getStackSetOperations(stackSetName string) []types.StackSetOperationSummary {
 	spinner.Push(fmt.Sprintf("Fetching stack set operations for '%s'", stackSetName))
 	stackSetOps, err := cfn.ListLast10StackSetOperations(stackSetName, delegatedAdmin)
 	if err != nil {
@@ -143,6 +160,14 @@ func getStackSetOperations(stackSetName string) string {
 	}
 	spinner.Pop()
 
+	return stackSetOps
+}
+
+// returns a display string with the stack set operations
+func formatStackSetOperations(stackSetName string, stackSetOps []types.StackSetOperationSummary) string {
+	out := strings.Builder{}
+	out.WriteString(console.Yellow("Last 10 operations (ID/Type/Status/Created/Completed):\n"))
+
 	if len(stackSetOps) == 0 {
 		out.WriteString(" - \n")
 		return out.String()
@@ -189,7 +214,12 @@ func displayStackSetSummaryWithInstancesAndLast10Operations(stackSetName string)
 		panic(ui.Errorf(err, "failed to list stack set '%s'", stackSetName))
 	}
 	spinner.Pop()
+
+	instances := getStackSetInstances(stackSetName)
+	stackSetOps := getStackSetOperations(stackSetName)
+	emit.Event(stackSetType, newStackSetData(*stackSet, instances, stackSetOps))
+
 	fmt.Println(cfn.GetStackSetSummary(stackSet, all))
-	fmt.Println(ui.Indent("  ", getStackSetInstances(stackSetName)))
-	fmt.Println(ui.Indent("  ", getStackSetOperations(stackSetName)))
+	fmt.Println(ui.Indent("  ", formatStackSetInstances(instances)))
+	fmt.Println(ui.Indent("  ", formatStackSetOperations(stackSetName, stackSetOps)))
 }
@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws-cloudformation/rain/internal/aws/cfn"
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
 	"github.com/aws-cloudformation/rain/internal/ui"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/spf13/cobra"
@@ -19,7 +21,7 @@ import (
 var RmCmd = &cobra.Command{
 	Use:                   "rm <stackset>",
 	Short:                 "Delete a CloudFormation stack set and/or its instances.",
-	Long:                  "Delete a CloudFormation stack set <stackset> and/or its instances.",
+	Long:                  "Delete a CloudFormation stack set <stackset> and/or its instances.\n" + preferencesHelp,
 	Args:                  cobra.ExactArgs(1),
 	Aliases:               []string{"delete", "remove"},
 	DisableFlagsInUseLine: true,
@@ -27,6 +29,11 @@ var RmCmd = &cobra.Command{
 		stackSetName := args[0]
 		config.Debugf("Deleting stack set: %s\n", stackSetName)
 
+		prefs, err := operationPreferences(cmd, nil)
+		if err != nil {
+			panic(err)
+		}
+
 		stackSet, err := cfn.GetStackSet(stackSetName, delegatedAdmin)
 		if err != nil {
 			panic(ui.Errorf(err, "Could not find stack set '%s'", stackSetName))
@@ -49,9 +56,9 @@ var RmCmd = &cobra.Command{
 
 				spinner.Push("Deleting stack set instances...")
 				if deleteAll {
-					err = cfn.DeleteAllStackSetInstances(stackSetName, !detach, false, delegatedAdmin)
+					err = cfn.DeleteAllStackSetInstances(stackSetName, prefs, !detach, false, delegatedAdmin)
 				} else {
-					err = cfn.DeleteStackSetInstances(stackSetName, accounts, regions, !detach, false, delegatedAdmin)
+					err = cfn.DeleteStackSetInstances(stackSetName, accounts, regions, prefs, !detach, false, delegatedAdmin)
 				}
 				spinner.Pop()
 
@@ -67,7 +74,10 @@ var RmCmd = &cobra.Command{
 						panic(ui.Errorf(err, "Could not delete stack set '%s'", stackSetName))
 					} else {
 						fmt.Println("Stack set deletion has been completed.")
+						emit.Event(stackSetType, stackSetData{StackSetName: stackSetName, Status: string(types.StackSetStatusDeleted)})
 					}
+				} else {
+					emitStackSet(stackSetName)
 				}
 			} else {
 				panic(ui.Errorf(err, "Could not delete stack set '%s'", stackSetName))
@@ -75,6 +85,7 @@ var RmCmd = &cobra.Command{
 
 		} else {
 			fmt.Println("Success!")
+			emit.Event(stackSetType, stackSetData{StackSetName: stackSetName, Status: string(types.StackSetStatusDeleted)})
 		}
 
 	},
@@ -82,6 +93,8 @@ var RmCmd = &cobra.Command{
 
 func init() {
 	RmCmd.Flags().BoolVarP(&detach, "detach", "d", false, "once delete has started, don't wait around for it to finish")
+	addPreferenceFlags(RmCmd, "delete instances from")
+	RmCmd.Flags().DurationVar(&cfn.StackSetQueueTimeout, "queue-timeout", time.Hour, "how long to wait for other operations on the stack set to finish before giving up")
 }
 
 func getStackInstances(stackSetName string) (string, []types.StackInstanceSummary) {
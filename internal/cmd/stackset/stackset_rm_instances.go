@@ -0,0 +1,157 @@
+package stackset
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var rmOUs []string
+var rmRegions []string
+var retainStacks bool
+var rmYes bool
+
+// RmInstancesCmd is the rm-instances command's entrypoint
+var RmInstancesCmd = &cobra.Command{
+	Use:   "rm-instances <stackset> --ou <ou> [--regions <regions>]",
+	Short: "Delete the instances of a stack set in organizational units",
+	Long: `Deletes the instances of the service-managed stack set <stackset> in the accounts of one or more organizational units.
+If you don't specify regions, instances are deleted from every region that the stack set has instances in for those organizational units.
+
+Rain shows the accounts and regions of the instances that will be deleted, and asks for confirmation before it starts the operation.
+` + preferencesHelp + `
+`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		stackSetName := args[0]
+
+		// Instances in organizational units belong to service-managed stack sets
+		checkServiceManaged(true)
+
+		prefs, err := operationPreferences(cmd, nil)
+		if err != nil {
+			panic(err)
+		}
+
+		spinner.Push(fmt.Sprintf("Fetching stack set instances for '%s'", stackSetName))
+		instances, err := cfn.ListStackSetInstances(stackSetName, delegatedAdmin)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "failed to list stack set instances"))
+		}
+
+		affected := selectInstances(instances, rmOUs, rmRegions)
+		if len(affected) == 0 {
+			fmt.Printf("Stack set '%s' has no instances in %s\n", stackSetName, strings.Join(rmOUs, ", "))
+			return
+		}
+
+		regions := rmRegions
+		if len(regions) == 0 {
+			for _, instance := range affected {
+				regions = append(regions, ptr.ToString(instance.Region))
+			}
+		}
+
+		fmt.Print(formatInstances(affected))
+
+		if retainStacks {
+			fmt.Println("The stacks will be removed from the stack set, but not deleted.")
+		}
+
+		if !rmYes && !console.Confirm(false, fmt.Sprintf("Delete %d instances of stack set '%s'?", len(affected), stackSetName)) {
+			panic(errors.New("user cancelled deletion"))
+		}
+
+		spinner.Push("Deleting stack set instances...")
+		err = cfn.DeleteStackSetInstancesFromOUs(stackSetName, rmOUs, regions, prefs, !detach, retainStacks, delegatedAdmin)
+		spinner.Pop()
+		if err != nil {
+			panic(ui.Errorf(err, "error while deleting stack set instances"))
+		}
+
+		emitStackSet(stackSetName)
+	},
+}
+
+func init() {
+	RmInstancesCmd.Flags().StringSliceVar(&rmOUs, "ou", []string{}, "organizational units whose accounts' instances will be deleted")
+	RmInstancesCmd.Flags().StringSliceVar(&rmRegions, "regions", []string{}, "regions to delete instances from; defaults to all regions with instances in the organizational units")
+	RmInstancesCmd.Flags().BoolVar(&retainStacks, "retain-stacks", false, "remove the stacks from the stack set without deleting them")
+	RmInstancesCmd.Flags().BoolVarP(&rmYes, "yes", "y", false, "delete the instances without confirmation")
+	RmInstancesCmd.Flags().BoolVarP(&detach, "detach", "d", false, "once delete has started, don't wait around for it to finish")
+	addPreferenceFlags(RmInstancesCmd, "delete instances from")
+	RmInstancesCmd.Flags().DurationVar(&cfn.StackSetQueueTimeout, "queue-timeout", time.Hour, "how long to wait for other operations on the stack set to finish before giving up")
+	RmInstancesCmd.MarkFlagRequired("ou")
+}
+
+// selectInstances returns the instances in the organizational units and regions, sorted by account and region.
+// An empty list of regions selects every region.
+func selectInstances(instances []types.StackInstanceSummary, ous []string, regions []string) []types.StackInstanceSummary {
+	inOUs := make(map[string]bool)
+	for _, ou := range ous {
+		inOUs[ou] = true
+	}
+
+	inRegions := make(map[string]bool)
+	for _, region := range regions {
+		inRegions[region] = true
+	}
+
+	selected := make([]types.StackInstanceSummary, 0)
+	for _, instance := range instances {
+		if !inOUs[ptr.ToString(instance.OrganizationalUnitId)] {
+			continue
+		}
+
+		if len(regions) > 0 && !inRegions[ptr.ToString(instance.Region)] {
+			continue
+		}
+
+		selected = append(selected, instance)
+	}
+
+	sort.Slice(selected, func(i, j int) bool {
+		a, b := selected[i], selected[j]
+		if ptr.ToString(a.Account) != ptr.ToString(b.Account) {
+			return ptr.ToString(a.Account) < ptr.ToString(b.Account)
+		}
+		return ptr.ToString(a.Region) < ptr.ToString(b.Region)
+	})
+
+	return selected
+}
+
+// formatInstances lists the instances that will be deleted
+func formatInstances(instances []types.StackInstanceSummary) string {
+	out := strings.Builder{}
+	out.WriteString(console.Yellow("Instances to delete (OU/Account/Region/Status):\n"))
+
+	for _, instance := range instances {
+		status := ""
+		if instance.StackInstanceStatus != nil {
+			status = string(instance.StackInstanceStatus.DetailedStatus)
+		}
+
+		out.WriteString(fmt.Sprintf(" - %s / %s / %s / %s\n",
+			ptr.ToString(instance.OrganizationalUnitId),
+			ptr.ToString(instance.Account),
+			ptr.ToString(instance.Region),
+			ui.ColouriseStatus(status),
+		))
+	}
+	out.WriteString("\n")
+
+	return out.String()
+}
@@ -0,0 +1,202 @@
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/summary"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/ui"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+)
+
+var jsonFlag bool
+var local bool
+
+// Cmd is the summary command's entrypoint
+var Cmd = &cobra.Command{
+	Use:   "summary <template|stack>",
+	Short: "Audit a template at a glance",
+	Long: `Prints a one-screen overview of a template: the transforms it declares, the capabilities
+that deploying it requires, its parameters, and how many resources of each type it has.
+If there is no file with the name that you give, rain summarizes the template of the stack of that name.
+
+Transforms and capabilities come from CloudFormation's GetTemplateSummary, which sees
+what the transforms expand to. With --local, or if CloudFormation can't summarize the
+template, e.g. because it uses rain's packaging directives, they are worked out from
+the template itself.
+
+Use --json to read the summary with other tools.`,
+	Args:                  cobra.ExactArgs(1),
+	DisableFlagsInUseLine: true,
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		t, isStack := load(name)
+		s := summary.New(t)
+
+		if !local {
+			spinner.Push("Getting the template summary from CloudFormation")
+
+			var out *cloudformation.GetTemplateSummaryOutput
+			var err error
+			if isStack {
+				out, err = cfn.GetStackTemplateSummary(name)
+			} else {
+				out, err = cfn.GetTemplateSummary(t)
+			}
+
+			spinner.Pop()
+
+			if err != nil {
+				console.Warnf("Unable to get the template summary from CloudFormation, "+
+					"so transforms and capabilities are worked out from the template: %s", err)
+			} else {
+				merge(&s, out)
+			}
+		}
+
+		emit.Event("summary", s)
+
+		if jsonFlag {
+			out, err := json.MarshalIndent(s, "", "  ")
+			if err != nil {
+				panic(err)
+			}
+			fmt.Println(string(out))
+			return
+		}
+
+		printSummary(name, s)
+	},
+}
+
+// load reads the template from a file or, if there is no such file, from the stack of that name.
+// It returns true if the template is a stack's.
+func load(name string) (cft.Template, bool) {
+	if _, err := os.Stat(name); err == nil {
+		t, err := parse.File(name)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", name))
+		}
+
+		return t, false
+	}
+
+	spinner.Push(fmt.Sprintf("Getting the template of stack '%s'", name))
+	source, err := cfn.GetStackTemplate(name, false)
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "'%s' is not a template file, and unable to get the template of a stack of that name", name))
+	}
+
+	t, err := parse.String(source)
+	if err != nil {
+		panic(ui.Errorf(err, "unable to parse the template of stack '%s'", name))
+	}
+
+	return t, true
+}
+
+// merge replaces the parts of the summary that CloudFormation knows better
+func merge(s *summary.Summary, out *cloudformation.GetTemplateSummaryOutput) {
+	s.Transforms = out.DeclaredTransforms
+	if s.Transforms == nil {
+		s.Transforms = make([]string, 0)
+	}
+
+	s.Capabilities = make([]string, 0, len(out.Capabilities))
+	for _, c := range out.Capabilities {
+		s.Capabilities = append(s.Capabilities, string(c))
+	}
+
+	s.CapabilitiesReason = ptr.ToString(out.CapabilitiesReason)
+
+	if out.Warnings != nil && len(out.Warnings.UnrecognizedResourceTypes) > 0 {
+		s.Warnings = append(s.Warnings, "Unrecognized resource types: "+strings.Join(out.Warnings.UnrecognizedResourceTypes, ", "))
+	}
+}
+
+// width returns the length of the longest value
+func width(values []string) int {
+	w := 0
+	for _, v := range values {
+		if len(v) > w {
+			w = len(v)
+		}
+	}
+	return w
+}
+
+func printSummary(name string, s summary.Summary) {
+	heading := console.Yellow(name)
+	if s.Description != "" {
+		heading += ": " + strings.Join(strings.Fields(s.Description), " ")
+	}
+	fmt.Println(heading)
+
+	none := console.Grey("none")
+
+	transforms := none
+	if len(s.Transforms) > 0 {
+		transforms = strings.Join(s.Transforms, ", ")
+	}
+	fmt.Printf("%s  %s\n", console.Yellow("Transforms:  "), transforms)
+
+	capabilities := none
+	if len(s.Capabilities) > 0 {
+		capabilities = strings.Join(s.Capabilities, ", ")
+		if s.CapabilitiesReason != "" {
+			capabilities += " " + console.Grey("("+s.CapabilitiesReason+")")
+		}
+	}
+	fmt.Printf("%s  %s\n", console.Yellow("Capabilities:"), capabilities)
+
+	fmt.Println(console.Yellow(fmt.Sprintf("Parameters (%d):", len(s.Parameters))))
+	names, types := make([]string, 0), make([]string, 0)
+	for _, p := range s.Parameters {
+		names = append(names, p.Name)
+		types = append(types, p.Type)
+	}
+	nameWidth, typeWidth := width(names), width(types)
+	for _, p := range s.Parameters {
+		line := fmt.Sprintf("  %-*s  %-*s", nameWidth, p.Name, typeWidth, p.Type)
+		if p.HasDefault {
+			line += console.Grey(" = " + p.Default)
+		}
+		if p.NoEcho {
+			line += " " + console.Red("NoEcho")
+		}
+		fmt.Println(strings.TrimRight(line, " "))
+	}
+
+	fmt.Println(console.Yellow(fmt.Sprintf("Resources (%d):", s.Resources)))
+	counts := make([]string, 0)
+	for _, r := range s.ResourceTypes {
+		counts = append(counts, fmt.Sprint(r.Count))
+	}
+	countWidth := width(counts)
+	for _, r := range s.ResourceTypes {
+		fmt.Printf("  %*d  %s\n", countWidth, r.Count, r.Type)
+	}
+
+	fmt.Printf("%s %d  %s %d\n", console.Yellow("Outputs:"), s.Outputs, console.Yellow("Conditions:"), s.Conditions)
+
+	for _, w := range s.Warnings {
+		fmt.Println(console.Red(w))
+	}
+}
+
+func init() {
+	Cmd.Flags().BoolVarP(&jsonFlag, "json", "j", false, "Output the summary as JSON")
+	Cmd.Flags().BoolVar(&local, "local", false, "Don't call CloudFormation; work everything out from the template")
+}
@@ -0,0 +1,100 @@
+package tree
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws-cloudformation/rain/cft/graph"
+	"github.com/aws-cloudformation/rain/internal/console"
+)
+
+// parameters returns the parameters that a condition uses,
+// including those used by the conditions that it refers to
+func parameters(g graph.Graph, condition graph.Node) []graph.Node {
+	seen := map[graph.Node]bool{condition: true}
+	out := make([]graph.Node, 0)
+
+	var dive func(graph.Node)
+	dive = func(from graph.Node) {
+		for _, to := range g.Get(from) {
+			if seen[to] {
+				continue
+			}
+			seen[to] = true
+
+			switch to.Type {
+			case "Parameters":
+				out = append(out, to)
+			case "Conditions":
+				dive(to)
+			}
+		}
+	}
+
+	dive(condition)
+
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Name < out[j].Name
+	})
+
+	return out
+}
+
+func printConditions(g graph.Graph, exprs map[string]graph.Expr) {
+	elements := make([]graph.Node, 0)
+	for _, el := range g.Nodes() {
+		if el.Type == "Conditions" {
+			elements = append(elements, el)
+		}
+	}
+
+	if len(elements) == 0 {
+		fmt.Println("The template has no conditions")
+		return
+	}
+
+	fmt.Println("Conditions:")
+
+	for _, el := range elements {
+		fmt.Printf("  %s:\n", console.Yellow(el.Name))
+
+		expr, ok := exprs[el.Name]
+		if !ok {
+			fmt.Println(console.Red("    Undefined"))
+			continue
+		}
+
+		fmt.Println("    Logic:")
+		for _, line := range expr.Lines() {
+			fmt.Printf("      %s\n", line)
+		}
+
+		uses := g.Get(el)
+		params := parameters(g, el)
+
+		if allLinks || len(uses) > 0 || len(params) > 0 {
+			if len(uses) == 0 && len(params) == 0 {
+				fmt.Println("    DependsOn: []")
+			} else {
+				fmt.Println("    DependsOn:")
+				printLinks(params, "Parameters")
+				printLinks(uses, "Conditions")
+			}
+		}
+
+		usedBy := g.GetReverse(el)
+
+		if allLinks || len(usedBy) > 0 {
+			if len(usedBy) == 0 {
+				fmt.Println("    UsedBy: []")
+			} else {
+				fmt.Println("    UsedBy:")
+				printLinks(usedBy, "Conditions")
+				printLinks(usedBy, "Resources")
+				printLinks(usedBy, "Outputs")
+			}
+		} else {
+			fmt.Println(console.Grey("    Not used"))
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/graph"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/s11n"
+	"gopkg.in/yaml.v3"
+)
+
+// maxNesting stops templates that nest themselves from being expanded forever
+const maxNesting = 10
+
+// nestedSource finds the templates of nested stacks
+type nestedSource interface {
+	// nested returns the template of a nested stack resource, where it was read from,
+	// and the source of the nested stacks in that template
+	nested(logicalId string, resource *yaml.Node) (cft.Template, string, nestedSource, error)
+}
+
+// fileSource reads nested stacks whose TemplateURL is a local file, as rain pkg allows
+type fileSource struct {
+	dir string
+}
+
+func (s fileSource) nested(logicalId string, resource *yaml.Node) (cft.Template, string, nestedSource, error) {
+	_, props, _ := s11n.GetMapValue(resource, "Properties")
+	if props == nil {
+		return cft.Template{}, "", nil, errors.New("no TemplateURL")
+	}
+
+	_, url, _ := s11n.GetMapValue(props, "TemplateURL")
+	if url == nil || url.Kind != yaml.ScalarNode {
+		return cft.Template{}, "", nil, errors.New("TemplateURL is not a file name")
+	}
+
+	if strings.Contains(url.Value, "://") {
+		return cft.Template{}, "", nil, fmt.Errorf("%s is not a local file", url.Value)
+	}
+
+	fn := url.Value
+	if !filepath.IsAbs(fn) {
+		fn = filepath.Join(s.dir, fn)
+	}
+
+	t, err := parse.File(fn)
+	if err != nil {
+		return cft.Template{}, "", nil, err
+	}
+
+	return t, fn, fileSource{dir: filepath.Dir(fn)}, nil
+}
+
+// stackSource reads the templates of the nested stacks of a deployed stack
+type stackSource struct {
+	stackName string
+
+	// physicalIds of the stack's resources, by logical ID
+	physicalIds map[string]string
+}
+
+func (s *stackSource) nested(logicalId string, resource *yaml.Node) (cft.Template, string, nestedSource, error) {
+	if s.physicalIds == nil {
+		resources, err := cfn.GetStackResources(s.stackName)
+		if err != nil {
+			return cft.Template{}, "", nil, err
+		}
+
+		s.physicalIds = make(map[string]string)
+		for _, r := range resources {
+			if r.LogicalResourceId != nil && r.PhysicalResourceId != nil {
+				s.physicalIds[*r.LogicalResourceId] = *r.PhysicalResourceId
+			}
+		}
+	}
+
+	id, ok := s.physicalIds[logicalId]
+	if !ok || id == "" {
+		return cft.Template{}, "", nil, errors.New("not deployed")
+	}
+
+	source, err := cfn.GetStackTemplate(id, false)
+	if err != nil {
+		return cft.Template{}, "", nil, err
+	}
+
+	t, err := parse.String(source)
+	if err != nil {
+		return cft.Template{}, "", nil, err
+	}
+
+	// The physical ID of a nested stack is its ARN, arn:...:stack/<name>/<id>
+	name := id
+	if parts := strings.Split(id, "/"); len(parts) == 3 {
+		name = parts[1]
+	}
+
+	return t, name, &stackSource{stackName: id}, nil
+}
+
+// resourceType returns the type of a resource, or the directive of a Rain module
+func resourceType(resource *yaml.Node) string {
+	_, typ, _ := s11n.GetMapValue(resource, "Type")
+	if typ == nil {
+		return ""
+	}
+
+	if typ.Kind == yaml.MappingNode && len(typ.Content) > 0 {
+		return typ.Content[0].Value
+	}
+
+	return typ.Value
+}
+
+// printHierarchy prints the template's resources by dependency level, and then by type,
+// with the resources of nested stacks under the stacks that they belong to
+func printHierarchy(t cft.Template, src nestedSource, indent string, nesting int) {
+	levels := graph.New(t).Levels("Resources")
+
+	for i, level := range levels {
+		fmt.Printf("%sLevel %d:\n", indent, i)
+
+		byType := make(map[string][]string)
+		types := make([]string, 0)
+
+		for _, n := range level {
+			resource, err := t.GetResource(n.Name)
+			if err != nil {
+				continue
+			}
+
+			typ := resourceType(resource)
+			if _, ok := byType[typ]; !ok {
+				types = append(types, typ)
+			}
+			byType[typ] = append(byType[typ], n.Name)
+		}
+
+		sort.Strings(types)
+
+		for _, typ := range types {
+			fmt.Printf("%s  %s:\n", indent, typ)
+
+			for _, name := range byType[typ] {
+				if typ != "AWS::CloudFormation::Stack" || src == nil {
+					fmt.Printf("%s    - %s\n", indent, console.Yellow(name))
+					continue
+				}
+
+				if nesting >= maxNesting {
+					fmt.Printf("%s    - %s %s\n", indent, console.Yellow(name), console.Grey("(nested too deeply to expand)"))
+					continue
+				}
+
+				resource, _ := t.GetResource(name)
+
+				nested, from, nestedSrc, err := src.nested(name, resource)
+				if err != nil {
+					fmt.Printf("%s    - %s %s\n", indent, console.Yellow(name), console.Grey(fmt.Sprintf("(unable to expand: %s)", err)))
+					continue
+				}
+
+				fmt.Printf("%s    - %s: %s\n", indent, console.Yellow(name), console.Grey(from))
+				printHierarchy(nested, nestedSrc, indent+"        ", nesting+1)
+			}
+		}
+	}
+}
@@ -1,8 +1,15 @@
 package tree
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console/spinner"
 	"github.com/aws-cloudformation/rain/internal/ui"
 
+	"github.com/aws-cloudformation/rain/cft"
 	"github.com/aws-cloudformation/rain/cft/graph"
 	"github.com/aws-cloudformation/rain/cft/parse"
 	"github.com/spf13/cobra"
@@ -11,27 +18,78 @@ import (
 var allLinks = false
 var dotGraph = false
 var twoWayTree = false
+var conditions = false
+var hierarchy = false
+
+// load reads the template from a file or, if there is no such file, from the stack of that name
+func load(name string) (cft.Template, nestedSource) {
+	if _, err := os.Stat(name); err == nil {
+		t, err := parse.File(name)
+		if err != nil {
+			panic(ui.Errorf(err, "unable to parse template '%s'", name))
+		}
+
+		return t, fileSource{dir: filepath.Dir(name)}
+	}
+
+	spinner.Push(fmt.Sprintf("Getting the template of stack '%s'", name))
+	source, err := cfn.GetStackTemplate(name, false)
+	spinner.Pop()
+	if err != nil {
+		panic(ui.Errorf(err, "'%s' is not a template file, and unable to get the template of a stack of that name", name))
+	}
+
+	t, err := parse.String(source)
+	if err != nil {
+		panic(ui.Errorf(err, "unable to parse the template of stack '%s'", name))
+	}
+
+	return t, &stackSource{stackName: name}
+}
 
 // Cmd is the tree command's entrypoint
 var Cmd = &cobra.Command{
-	Use:                   "tree [template]",
-	Short:                 "Find dependencies of Resources and Outputs in a local template",
-	Long:                  "Find and display the dependencies between Parameters, Resources, and Outputs in a CloudFormation template.",
+	Use:   "tree <template|stack>",
+	Short: "Find dependencies of Resources and Outputs in a local template or a stack",
+	Long: `Find and display the dependencies between Parameters, Resources, and Outputs in a CloudFormation template.
+If there is no file with the name that you give, rain reads the template of the stack of that name.
+
+With --hierarchy, rain shows the template's resources grouped by how deep their dependencies go,
+and then by type, which gives a quick overview of an unfamiliar template. Level 0 has the resources
+that don't depend on any other, level 1 has the resources that depend on level 0, and so on.
+The resources of nested stacks are shown under the stack that they belong to: from the files
+that their TemplateURLs refer to, or, for a deployed stack, from the nested stacks themselves.
+
+With --conditions, rain shows each of the template's Conditions instead: the logic of the
+condition as a tree, the parameters and conditions it uses, and the conditions, resources,
+and outputs that use it, either with a Condition attribute or with Fn::If.`,
 	Args:                  cobra.ExactArgs(1),
 	Aliases:               []string{"graph"},
 	DisableFlagsInUseLine: true,
 	Run: func(cmd *cobra.Command, args []string) {
-		fileName := args[0]
+		t, src := load(args[0])
 
-		t, err := parse.File(fileName)
-		if err != nil {
-			panic(ui.Errorf(err, "unable to parse template '%s'", fileName))
+		if hierarchy {
+			printHierarchy(t, src, "", 0)
+			return
+		}
+
+		if conditions {
+			g := graph.NewConditions(t)
+
+			if dotGraph {
+				printDot(g, "Parameters", "Conditions", "Resources", "Outputs")
+			} else {
+				printConditions(g, graph.Conditions(t))
+			}
+
+			return
 		}
 
 		g := graph.New(t)
 
 		if dotGraph {
-			printDot(g)
+			printDot(g, "Parameters", "Resources", "Outputs")
 		} else {
 			printGraph(g, "Parameters")
 			printGraph(g, "Resources")
@@ -44,4 +102,6 @@ func init() {
 	Cmd.Flags().BoolVarP(&allLinks, "all", "a", false, "Display all elements, even those without any dependencies")
 	Cmd.Flags().BoolVarP(&twoWayTree, "both", "b", false, "For each element, display both its dependencies and its dependents")
 	Cmd.Flags().BoolVarP(&dotGraph, "dot", "d", false, "Output the graph in GraphViz DOT format")
+	Cmd.Flags().BoolVarP(&conditions, "conditions", "c", false, "Display the logic of each condition and what uses it")
+	Cmd.Flags().BoolVar(&hierarchy, "hierarchy", false, "Display resources by dependency level and type, with nested stacks expanded")
 }
@@ -47,3 +47,22 @@ func Example_sub() {
 	//       Resources:
 	//         - EfsFileSystem
 }
+
+func Example_hierarchy() {
+	os.Args = []string{
+		os.Args[0],
+		"--hierarchy",
+		"../../../test/templates/nested.template",
+	}
+
+	console.NoColour = true
+
+	tree.Cmd.Execute()
+	// Output:
+	// Level 0:
+	//   AWS::CloudFormation::Stack:
+	//     - SuccessStack: ../../../test/templates/success.template
+	//         Level 0:
+	//           AWS::S3::Bucket:
+	//             - Bucket1
+}
@@ -91,9 +91,10 @@ var dotShapes = map[string]string{
 	"Parameters": "diamond",
 	"Resources":  "Mrecord",
 	"Outputs":    "rectangle",
+	"Conditions": "hexagon",
 }
 
-func printDot(graph graph.Graph) {
+func printDot(graph graph.Graph, groups ...string) {
 	out := strings.Builder{}
 
 	out.WriteString("digraph {\n")
@@ -115,9 +116,9 @@ func printDot(graph graph.Graph) {
 		out.WriteString("\n")
 	}
 
-	doGroup("Parameters")
-	doGroup("Resources")
-	doGroup("Outputs")
+	for _, group := range groups {
+		doGroup(group)
+	}
 
 	for _, from := range graph.Nodes() {
 		fromStr := fmt.Sprintf("%s: %s", from.Type, from.Name)
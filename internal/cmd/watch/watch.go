@@ -10,6 +10,8 @@ import (
 	"github.com/aws-cloudformation/rain/internal/aws/cfn"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/spf13/cobra"
 )
 
@@ -54,11 +56,18 @@ var Cmd = &cobra.Command{
 				first = false
 			}
 
-			time.Sleep(time.Second * cfn.WaitPeriodInSeconds)
+			if err := interrupt.Sleep(time.Second * cfn.WaitPeriodInSeconds); err != nil {
+				panic(ui.Errorf(err, "stopped waiting for stack '%s' to begin changing", stackName))
+			}
 		}
 
 		spinner.Pop()
 
+		if emit.Enabled() {
+			watchEvents(stackName)
+			return
+		}
+
 		status, messages := cfn.WaitForStackToSettle(stackName)
 
 		fmt.Println("Final stack status:", ui.ColouriseStatus(status))
@@ -72,6 +81,29 @@ var Cmd = &cobra.Command{
 	},
 }
 
+// watchEvents writes each of the stack's events as a structured event until the stack settles,
+// followed by the stack's final state
+func watchEvents(stackName string) {
+	events, err := cfn.StreamStackEvents(interrupt.Context(), stackName)
+	if err != nil {
+		panic(ui.Errorf(err, "error watching stack '%s'", stackName))
+	}
+
+	spinner.StartTimer(fmt.Sprintf("Watching stack '%s'", stackName))
+	for e := range events {
+		emit.StackEvent(e)
+	}
+	spinner.StopTimer()
+
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		panic(ui.Errorf(err, "error watching stack '%s'", stackName))
+	}
+
+	fmt.Println("Final stack status:", ui.ColouriseStatus(string(stack.StackStatus)))
+	emit.Stack(stack, nil)
+}
+
 func init() {
 	Cmd.Flags().BoolVarP(&waitThenWatch, "wait", "w", false, "wait for changes to begin rather than refusing to watch an unchanging stack")
 }
@@ -9,6 +9,9 @@ import (
 	"github.com/aws-cloudformation/rain/internal/config"
 	"github.com/aws-cloudformation/rain/internal/console"
 	"github.com/aws-cloudformation/rain/internal/console/spinner"
+	"github.com/aws-cloudformation/rain/internal/emit"
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+	"github.com/aws-cloudformation/rain/internal/interrupt"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -69,22 +72,43 @@ func Wrap(name string, src *cobra.Command) {
 }
 
 func execute(cmd *cobra.Command) (code int) {
+	// The first Ctrl-C cancels what rain is waiting for, so that the command can stop cleanly;
+	// a second one exits straight away
+	stopInterrupts := interrupt.Start(func() {
+		spinner.Pause()
+		fmt.Fprintln(os.Stderr, console.Yellow("Interrupted; stopping. Press Ctrl-C again to exit now."))
+		spinner.Resume()
+	}, spinner.Stop)
+
 	defer func() {
 		spinner.Stop()
+		stopInterrupts()
 
 		if r := recover(); r != nil {
-			if config.Debug {
-				panic(r)
+			code = exitcode.Of(r)
+
+			// The command has already reported why it stopped
+			if !exitcode.IsSilent(r) {
+				if config.Debug {
+					panic(r)
+				}
+
+				if code == exitcode.Interrupted {
+					fmt.Fprintln(os.Stderr, console.Yellow(fmt.Sprint(r)))
+				} else {
+					fmt.Fprintln(os.Stderr, console.Red(fmt.Sprint(r)))
+				}
+				emit.Event(emit.Error, map[string]string{"message": fmt.Sprint(r)})
 			}
-
-			fmt.Fprintln(os.Stderr, console.Red(fmt.Sprint(r)))
-
-			code = 1
 		}
+
+		emit.Event(emit.Exit, map[string]int{"code": code})
+		emit.Stop()
 	}()
 
 	if err := cmd.Execute(); err != nil {
-		code = 1
+		emit.Event(emit.Error, map[string]string{"message": err.Error()})
+		code = exitcode.Usage
 	}
 
 	return
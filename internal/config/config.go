@@ -2,6 +2,9 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/aws-cloudformation/rain/internal/console"
 )
@@ -15,15 +18,53 @@ var Profile = ""
 // Region holds the requested AWS region name
 var Region = ""
 
+// Offline is set if rain should use cached resource schemas instead of calling the registry.
+// It can be set with the --offline flag or the RAIN_OFFLINE environment variable.
+var Offline = os.Getenv("RAIN_OFFLINE") != ""
+
+// MaxRetries is how many times an AWS call is retried after throttling or a transient error.
+// It can be set with the --max-retries flag or the RAIN_MAX_RETRIES environment variable;
+// 0 uses max_attempts from the AWS configuration, or the AWS SDK's default.
+var MaxRetries, _ = strconv.Atoi(os.Getenv("RAIN_MAX_RETRIES"))
+
+// RetryMode is how AWS calls are retried: adaptive, the default, which also slows down
+// every call when the API starts throttling, or standard.
+// It can be set with the --retry-mode flag or the RAIN_RETRY_MODE environment variable,
+// and otherwise comes from retry_mode in the AWS configuration.
+var RetryMode = os.Getenv("RAIN_RETRY_MODE")
+
+// RateLimit is the most AWS calls that rain makes each second; 0 means there is no limit.
+// It can be set with the --rate-limit flag or the RAIN_RATE_LIMIT environment variable.
+var RateLimit, _ = strconv.ParseFloat(os.Getenv("RAIN_RATE_LIMIT"), 64)
+
+// secrets are values that must not be shown, such as resolved secret parameters
+var secrets = make([]string, 0)
+
+// AddSecret records a value that Mask should hide
+func AddSecret(secret string) {
+	if secret != "" {
+		secrets = append(secrets, secret)
+	}
+}
+
+// Mask replaces the value of each secret in s with ****
+func Mask(s string) string {
+	for _, secret := range secrets {
+		s = strings.ReplaceAll(s, secret, "****")
+	}
+
+	return s
+}
+
 // Debugf prints messages for stdout only if Debug is true
 func Debugf(message string, parts ...interface{}) {
 	if Debug {
-		fmt.Println(console.Grey("DEBUG: " + fmt.Sprintf(message, parts...)))
+		fmt.Println(console.Grey("DEBUG: " + Mask(fmt.Sprintf(message, parts...))))
 	}
 }
 
 func Debugln(message string) {
 	if Debug {
-		fmt.Println(console.Grey("DEBUG: " + fmt.Sprintln(message)))
+		fmt.Println(console.Grey("DEBUG: " + Mask(fmt.Sprintln(message))))
 	}
 }
@@ -0,0 +1,21 @@
+package console
+
+import (
+	"errors"
+	"os/exec"
+	"runtime"
+)
+
+// OpenURL opens uri in the user's browser
+func OpenURL(uri string) error {
+	switch runtime.GOOS {
+	case "linux":
+		return exec.Command("xdg-open", uri).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", uri).Start()
+	case "darwin":
+		return exec.Command("open", uri).Start()
+	}
+
+	return errors.New("unable to open a browser on " + runtime.GOOS)
+}
@@ -8,7 +8,7 @@ import (
 
 func wrap(c color.Style) func(...interface{}) string {
 	return func(in ...interface{}) string {
-		if NoColour || !IsTTY {
+		if NoColour || PlainOutput || !IsTTY {
 			return fmt.Sprint(in...)
 		}
 
@@ -20,7 +20,7 @@ func wrap(c color.Style) func(...interface{}) string {
 func Sprint(in ...interface{}) string {
 	out := color.Sprint(in...)
 
-	if NoColour || !IsTTY {
+	if NoColour || PlainOutput || !IsTTY {
 		out = color.ClearCode(out)
 	}
 
@@ -2,11 +2,12 @@
 package console
 
 import (
-	"errors"
 	"fmt"
+	"io"
 	"math"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/chzyer/readline"
 	"github.com/gookit/color"
@@ -20,12 +21,42 @@ var IsTTY bool
 // isANSI will be true if console supports ANSI escape code. It is for Windows only.
 var isANSI bool
 
-// NoColour should be false if you want output to be coloured
-var NoColour = false
+// NoColour should be false if you want output to be coloured.
+// It is set by the NO_COLOR environment variable (see https://no-color.org).
+var NoColour = os.Getenv("NO_COLOR") != ""
+
+// PlainOutput replaces spinners and redrawn output with discrete, timestamped status lines
+// and no colour, for screen readers and environments that only keep logs.
+// It can be set with the --plain flag or the RAIN_PLAIN environment variable,
+// and is set when stdout is not a terminal, such as in CI.
+var PlainOutput = os.Getenv("RAIN_PLAIN") != ""
+
+// plainDefault is PlainOutput before SetEvents changes it
+var plainDefault bool
+
+// Quiet hides progress: spinners, timers and status lines.
+// Results, warnings and errors are still shown.
+var Quiet = false
+
+// isInteractive will be true if rain can ask the user questions,
+// which needs a terminal for both stdin and stdout
+var isInteractive bool
+
+// statusOutput and statusTime are replaced in tests
+var statusOutput io.Writer = os.Stderr
+var statusTime = time.Now
 
 func init() {
 	IsTTY = term.IsTerminal(int(os.Stdout.Fd()))
 	isANSI = true
+	isInteractive = IsTTY && term.IsTerminal(int(os.Stdin.Fd()))
+
+	// Spinners can't be redrawn in a log, so report each status on its own line instead
+	if !IsTTY {
+		PlainOutput = true
+	}
+
+	plainDefault = PlainOutput
 }
 
 // Size returns the width and height of the console in characters
@@ -70,7 +101,7 @@ func ClearLine() {
 
 // ClearLines removes all text from the previous n lines (starting with the current line) and puts the cursor on the left
 func ClearLines(n int) {
-	if !IsTTY {
+	if !IsTTY || PlainOutput {
 		return
 	}
 
@@ -84,43 +115,67 @@ func ClearLines(n int) {
 	}
 }
 
-// Ask prints the supplied prompt and then waits for user input which is returned as a string.
-func Ask(prompt string) string {
-	if !IsTTY {
-		panic(errors.New("no interactive terminal detected; try running rain in interactive mode (e.g. without --yes)"))
-	}
+// Status writes a line to stderr with the time, e.g. "[15:04:05] Deploying stack",
+// for progress that PlainOutput reports instead of showing a spinner.
+// If SetEvents has been called, the line is reported as Progress instead.
+func Status(message string) {
+	events.Progress(message)
+}
 
+// newReadline returns a readline instance that shows the prompt
+func newReadline(prompt string) (*readline.Instance, error) {
 	rl, err := readline.NewEx(&readline.Config{
 		Prompt: prompt + " ",
 	})
 	if err != nil {
-		panic(fmt.Errorf("unable to get user input: %w", err))
+		return nil, fmt.Errorf("unable to get user input: %w", err)
 	}
 
-	answer, err := rl.Readline()
+	return rl, nil
+}
+
+// Ask prints the supplied prompt and then waits for user input which is returned as a string.
+// Without a terminal to ask in, such as in CI, it stops rain
+// rather than waiting for input that will never come.
+func Ask(prompt string) string {
+	return AskWithDefault(prompt, "")
+}
+
+// AskWithDefault is like Ask, but the input starts out as defaultValue, which the user can edit.
+func AskWithDefault(prompt string, defaultValue string) string {
+	answer, err := events.Prompt(prompt, defaultValue, false)
 	if err != nil {
-		panic(fmt.Errorf("unable to get user input: %w", err))
+		panic(err)
 	}
 
-	return strings.TrimSpace(answer)
+	return answer
 }
 
-// Confirm asks the user for "y" or "n" and returns true if the response was "y".
-// defaultYes is used to determine whether (y/N) or (Y/n) is displayed after the prompt.
-func Confirm(defaultYes bool, prompt string) bool {
-	extra := " (y/N)"
-
-	if defaultYes {
-		extra = " (Y/n)"
+// AskSecret is like Ask, but the user's input is not shown.
+func AskSecret(prompt string) string {
+	answer, err := events.Prompt(prompt, "", true)
+	if err != nil {
+		panic(err)
 	}
 
-	answer := Ask(prompt + extra)
+	return answer
+}
+
+// CanAsk returns true if rain can ask the user questions,
+// at the terminal or through the Events set with SetEvents
+func CanAsk() bool {
+	return isInteractive || HasEvents()
+}
 
-	if strings.ToUpper(answer) == "Y" || (defaultYes && answer == "") {
-		return true
+// Confirm asks the user for "y" or "n" and returns true if the response was "y".
+// defaultYes is used to determine whether (y/N) or (Y/n) is displayed after the prompt.
+func Confirm(defaultYes bool, prompt string) bool {
+	answer, err := events.Confirm(prompt, defaultYes)
+	if err != nil {
+		panic(err)
 	}
 
-	return false
+	return answer
 }
 
 // Errorf prints a red message to standard out
@@ -0,0 +1,62 @@
+package console
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatus(t *testing.T) {
+	out := &strings.Builder{}
+	statusOutput = out
+	statusTime = func() time.Time {
+		return time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	}
+
+	Status("Deploying stack\n")
+	Status("  Stack test: CREATE_COMPLETE")
+
+	expected := "[15:04:05] Deploying stack\n[15:04:05] Stack test: CREATE_COMPLETE\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestPlainColours(t *testing.T) {
+	defer func(tty, plain bool) { IsTTY, PlainOutput = tty, plain }(IsTTY, PlainOutput)
+
+	IsTTY = true
+	PlainOutput = true
+
+	if Red("error") != "error" {
+		t.Errorf("expected no colour in plain output, got %q", Red("error"))
+	}
+}
+
+func TestQuiet(t *testing.T) {
+	defer func(quiet bool) { Quiet = quiet }(Quiet)
+
+	out := &strings.Builder{}
+	statusOutput = out
+	Quiet = true
+
+	Status("Deploying stack")
+
+	if out.Len() != 0 {
+		t.Errorf("expected no status lines when quiet, got %q", out.String())
+	}
+}
+
+func TestConfirmWithoutTerminal(t *testing.T) {
+	defer func(interactive bool) { isInteractive = interactive }(isInteractive)
+
+	isInteractive = false
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(r.(error).Error(), "--yes") {
+			t.Errorf("expected an error that mentions --yes, got %v", r)
+		}
+	}()
+
+	Confirm(true, "Delete stack?")
+}
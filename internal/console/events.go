@@ -0,0 +1,165 @@
+package console
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Events receives the progress, output and questions of rain's long-running operations.
+// The terminal implementation draws them on the console, which is what rain's CLI uses.
+// Programs that embed rain can send them elsewhere with SetEvents,
+// for example to show progress in a web UI or as CI annotations.
+type Events interface {
+	// Progress reports what an operation is doing now
+	Progress(message string)
+
+	// Log writes a line of output
+	Log(line string)
+
+	// Prompt asks for a line of input that starts out as defaultValue.
+	// If secret is set, the input must not be shown.
+	Prompt(prompt string, defaultValue string, secret bool) (string, error)
+
+	// Confirm asks a yes or no question, where defaultYes is the answer if none is given
+	Confirm(prompt string, defaultYes bool) (bool, error)
+}
+
+// Terminal is the Events implementation that draws on the console
+var Terminal Events = terminal{}
+
+// Discard is the Events implementation that reports nothing.
+// It has no one to ask questions, so Prompt and Confirm return an error.
+var Discard Events = discard{}
+
+var events = Terminal
+
+// SetEvents sends progress, output and questions to e instead of the terminal.
+// Spinners and output that would be redrawn are reported as Progress instead,
+// and text is not coloured. Passing nil restores the terminal.
+func SetEvents(e Events) {
+	if e == nil || e == Terminal {
+		events = Terminal
+		PlainOutput = plainDefault
+		return
+	}
+
+	events = e
+	PlainOutput = true
+}
+
+// HasEvents returns true if SetEvents has replaced the terminal
+func HasEvents() bool {
+	return events != Terminal
+}
+
+// Log writes a line of output to stdout, or to the Events set with SetEvents
+func Log(line string) {
+	events.Log(line)
+}
+
+// Logf is like Log, with the line built from a format string
+func Logf(format string, args ...any) {
+	events.Log(fmt.Sprintf(format, args...))
+}
+
+// Warnf writes a warning to stderr in yellow, so that it doesn't mix with a command's output.
+// If SetEvents has been called, the warning is logged to the Events instead.
+func Warnf(format string, args ...any) {
+	aside(Yellow, fmt.Sprintf(format, args...))
+}
+
+// Failf is like Warnf, in red, for what made a command or one of its steps fail
+func Failf(format string, args ...any) {
+	aside(Red, fmt.Sprintf(format, args...))
+}
+
+// Notef is like Warnf, in grey, for information that needs no action
+func Notef(format string, args ...any) {
+	aside(Grey, fmt.Sprintf(format, args...))
+}
+
+func aside(colour func(...any) string, line string) {
+	if HasEvents() {
+		events.Log(line)
+		return
+	}
+
+	fmt.Fprintln(os.Stderr, colour(line))
+}
+
+type terminal struct{}
+
+func (terminal) Progress(message string) {
+	if Quiet {
+		return
+	}
+
+	fmt.Fprintf(statusOutput, "[%s] %s\n", statusTime().Format("15:04:05"), strings.TrimSpace(message))
+}
+
+func (terminal) Log(line string) {
+	fmt.Println(line)
+}
+
+func (terminal) Prompt(prompt string, defaultValue string, secret bool) (string, error) {
+	if !isInteractive {
+		return "", fmt.Errorf("rain needs an answer to '%s' but there is no interactive terminal to ask in; "+
+			"set the value with a flag or config file instead, e.g. --params or --config for parameter values", prompt)
+	}
+
+	rl, err := newReadline(prompt)
+	if err != nil {
+		return "", err
+	}
+
+	var answer string
+	if secret {
+		var b []byte
+		b, err = rl.ReadPassword(prompt + " ")
+		answer = string(b)
+	} else {
+		answer, err = rl.ReadlineWithDefault(defaultValue)
+	}
+	if err != nil {
+		return "", fmt.Errorf("unable to get user input: %w", err)
+	}
+
+	return strings.TrimSpace(answer), nil
+}
+
+func (t terminal) Confirm(prompt string, defaultYes bool) (bool, error) {
+	if !isInteractive {
+		return false, errors.New("rain needs you to confirm '" + prompt + "' but there is no interactive terminal to ask in; " +
+			"run rain in a terminal, or use --yes if the command has it to continue without being asked")
+	}
+
+	extra := " (y/N)"
+
+	if defaultYes {
+		extra = " (Y/n)"
+	}
+
+	answer, err := t.Prompt(prompt+extra, "", false)
+	if err != nil {
+		return false, err
+	}
+
+	return strings.ToUpper(answer) == "Y" || (defaultYes && answer == ""), nil
+}
+
+type discard struct{}
+
+func (discard) Progress(message string) {}
+
+func (discard) Log(line string) {}
+
+func (discard) Prompt(prompt string, defaultValue string, secret bool) (string, error) {
+	return "", fmt.Errorf("rain needs an answer to '%s' but there is nothing to ask; "+
+		"set the value in the operation's input instead, e.g. its parameter values", prompt)
+}
+
+func (discard) Confirm(prompt string, defaultYes bool) (bool, error) {
+	return false, errors.New("rain needs you to confirm '" + prompt + "' but there is nothing to ask")
+}
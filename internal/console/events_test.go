@@ -0,0 +1,69 @@
+package console
+
+import (
+	"reflect"
+	"testing"
+)
+
+type recorder struct {
+	calls []string
+}
+
+func (r *recorder) Progress(message string) {
+	r.calls = append(r.calls, "progress "+message)
+}
+
+func (r *recorder) Log(line string) {
+	r.calls = append(r.calls, "log "+line)
+}
+
+func (r *recorder) Prompt(prompt string, defaultValue string, secret bool) (string, error) {
+	r.calls = append(r.calls, "prompt "+prompt)
+	return "answer", nil
+}
+
+func (r *recorder) Confirm(prompt string, defaultYes bool) (bool, error) {
+	r.calls = append(r.calls, "confirm "+prompt)
+	return defaultYes, nil
+}
+
+func TestSetEvents(t *testing.T) {
+	plain := PlainOutput
+	defer SetEvents(nil)
+
+	r := &recorder{}
+	SetEvents(r)
+
+	if !HasEvents() || !PlainOutput {
+		t.Error("expected custom events to replace spinners with progress")
+	}
+
+	Status("Deploying stack")
+	Logf("Stack %s deployed", "app")
+	Warnf("Hook %s failed", "notify")
+
+	if answer := Ask("Name:"); answer != "answer" {
+		t.Errorf("expected the sink's answer, got %q", answer)
+	}
+
+	if !Confirm(true, "Continue?") {
+		t.Error("expected the sink's confirmation")
+	}
+
+	expected := []string{
+		"progress Deploying stack",
+		"log Stack app deployed",
+		"log Hook notify failed",
+		"prompt Name:",
+		"confirm Continue?",
+	}
+	if !reflect.DeepEqual(r.calls, expected) {
+		t.Errorf("expected %v, got %v", expected, r.calls)
+	}
+
+	SetEvents(nil)
+
+	if HasEvents() || PlainOutput != plain {
+		t.Error("expected SetEvents(nil) to restore the terminal")
+	}
+}
@@ -0,0 +1,48 @@
+package console
+
+import (
+	"errors"
+)
+
+// Key is a key that was pressed; printable keys are the character they type
+type Key string
+
+// Keys that don't type a character
+const (
+	KeyUp     Key = "up"
+	KeyDown   Key = "down"
+	KeyEnter  Key = "enter"
+	KeyEscape Key = "escape"
+)
+
+// ErrNoKeys is returned by ReadKeys if key presses can't be read
+var ErrNoKeys = errors.New("key presses can't be read from this terminal")
+
+// parseKeys returns the keys that were pressed to send the input
+func parseKeys(input []byte) []Key {
+	keys := make([]Key, 0)
+
+	for len(input) > 0 {
+		switch {
+		case len(input) >= 3 && input[0] == 0x1b && (input[1] == '[' || input[1] == 'O'):
+			switch input[2] {
+			case 'A':
+				keys = append(keys, KeyUp)
+			case 'B':
+				keys = append(keys, KeyDown)
+			}
+			input = input[3:]
+		case input[0] == 0x1b:
+			keys = append(keys, KeyEscape)
+			input = input[1:]
+		case input[0] == '\r' || input[0] == '\n':
+			keys = append(keys, KeyEnter)
+			input = input[1:]
+		default:
+			keys = append(keys, Key(input[:1]))
+			input = input[1:]
+		}
+	}
+
+	return keys
+}
@@ -0,0 +1,8 @@
+//go:build !(aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris)
+
+package console
+
+// ReadKeys is not supported on this platform, so it always returns ErrNoKeys
+func ReadKeys() (<-chan Key, func(), error) {
+	return nil, nil, ErrNoKeys
+}
@@ -0,0 +1,8 @@
+//go:build aix || linux || solaris
+
+package console
+
+import "golang.org/x/sys/unix"
+
+const ioctlGetTermios = unix.TCGETS
+const ioctlSetTermios = unix.TCSETS
@@ -0,0 +1,22 @@
+package console
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseKeys(t *testing.T) {
+	cases := map[string][]Key{
+		"\x1b[A":     {KeyUp},
+		"\x1bOB":     {KeyDown},
+		"\x1b":       {KeyEscape},
+		"\r":         {KeyEnter},
+		"j\x1b[Bk\n": {"j", KeyDown, "k", KeyEnter},
+	}
+
+	for input, expected := range cases {
+		if actual := parseKeys([]byte(input)); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("%q: expected %v, got %v", input, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+
+package console
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/aws-cloudformation/rain/internal/interrupt"
+	"golang.org/x/sys/unix"
+	"golang.org/x/term"
+)
+
+// ReadKeys reads key presses from stdin without waiting for enter or echoing them.
+// Call stop to restore the terminal when key presses are no longer needed.
+// It returns ErrNoKeys if stdin is not a terminal.
+func ReadKeys() (keys <-chan Key, stop func(), err error) {
+	fd := int(os.Stdin.Fd())
+
+	if !IsTTY || !term.IsTerminal(fd) {
+		return nil, nil, ErrNoKeys
+	}
+
+	old, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Turn off line buffering and echo, but keep output processing
+	// and signals, so that output and Ctrl-C work as usual.
+	// A read returns after a tenth of a second without input,
+	// so that the reader can notice that it should stop.
+	raw := *old
+	raw.Lflag &^= unix.ICANON | unix.ECHO
+	raw.Cc[unix.VMIN] = 0
+	raw.Cc[unix.VTIME] = 1
+
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, nil, err
+	}
+
+	var once sync.Once
+	restore := func() {
+		once.Do(func() {
+			unix.IoctlSetTermios(fd, ioctlSetTermios, old)
+		})
+	}
+
+	// Restore the terminal if rain is interrupted, then let the signal end rain as usual,
+	// unless the command is handling interrupts itself
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	out := make(chan Key)
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		defer close(out)
+
+		buf := make([]byte, 16)
+
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-signals:
+				restore()
+				signal.Stop(signals)
+				if !interrupt.Handling() {
+					syscall.Kill(os.Getpid(), sig.(syscall.Signal))
+				}
+				return
+			default:
+			}
+
+			n, err := os.Stdin.Read(buf)
+			if err != nil && !errors.Is(err, io.EOF) {
+				return
+			}
+
+			for _, k := range parseKeys(buf[:n]) {
+				select {
+				case out <- k:
+				case <-done:
+					return
+				}
+			}
+		}
+	}()
+
+	stop = func() {
+		close(done)
+		<-finished
+		signal.Stop(signals)
+		restore()
+	}
+
+	return out, stop, nil
+}
@@ -1,5 +1,8 @@
 // Package spinner contains functions for displaying progress updates
 // with a spinning icon that shows the user that progress is being made.
+// If console.PlainOutput is set, each status is written once as a timestamped line instead,
+// or reported to the Events set with console.SetEvents, and if console.Quiet is set,
+// nothing is shown on the terminal.
 package spinner
 
 import (
@@ -18,6 +21,7 @@ var hasTimer = false
 var statuses []string
 var count = 0
 var startTime time.Time
+var deadline time.Time
 var paused = false
 
 var lastLine = ""
@@ -26,7 +30,7 @@ func init() {
 	statuses = make([]string, 0)
 
 	go func() {
-		for console.IsTTY && !config.Debug {
+		for console.IsTTY && !config.Debug && !console.PlainOutput {
 			if !paused && len(statuses) > 0 {
 				update()
 				count = (count + 1) % len(spin)
@@ -47,7 +51,7 @@ func update() {
 		return
 	}
 
-	if !console.IsTTY {
+	if !console.IsTTY || console.PlainOutput || console.Quiet {
 		return
 	}
 
@@ -57,11 +61,16 @@ func update() {
 		status := strings.TrimSpace(statuses[len(statuses)-1])
 
 		if hasTimer {
+			elapsed := time.Since(startTime).Truncate(time.Second).String()
+			if !deadline.IsZero() {
+				elapsed += fmt.Sprintf(" (%s left)", max(time.Until(deadline), 0).Truncate(time.Second))
+			}
+
 			lastLine = fmt.Sprintf("%s%s%s %s %s",
 				console.Cyan(spin[count]),
 				console.Cyan(spin[(count+3)%len(spin)]),
 				console.Cyan(spin[(count+5)%len(spin)]),
-				time.Since(startTime).Truncate(time.Second),
+				elapsed,
 				status,
 			)
 		} else {
@@ -81,6 +90,10 @@ func update() {
 func Push(status string) {
 	statuses = append(statuses, status)
 
+	if console.PlainOutput && strings.TrimSpace(status) != "" && !config.Debug {
+		console.Status(status)
+	}
+
 	update()
 }
 
@@ -92,9 +105,20 @@ func StartTimer(status string) {
 	Push(status)
 }
 
+// SetDeadline makes the timer show how long is left until t.
+// The deadline is cleared when the timer stops.
+func SetDeadline(t time.Time) {
+	deadline = t
+}
+
 // StopTimer disables the timer
 func StopTimer() {
+	if console.PlainOutput && hasTimer && !config.Debug {
+		console.Status(fmt.Sprintf("Finished after %s", time.Since(startTime).Truncate(time.Second)))
+	}
+
 	hasTimer = false
+	deadline = time.Time{}
 
 	Pop()
 
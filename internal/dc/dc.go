@@ -4,10 +4,13 @@
 package dc
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/aws-cloudformation/rain/cft"
@@ -26,6 +29,84 @@ type configFileFormat struct {
 	Tags            map[string]string `yaml:"Tags"`
 	LowerParameters map[string]string `yaml:"parameters,omitempty"`
 	LowerTags       map[string]string `yaml:"tags,omitempty"`
+	StackPolicy     interface{}       `yaml:"StackPolicy,omitempty"`
+
+	// Regions sets Parameters and Tags that differ between the regions a stack is deployed to
+	Regions map[string]regionConfig `yaml:"Regions,omitempty"`
+}
+
+// regionConfig is the part of a config file that applies to one region
+type regionConfig struct {
+	Parameters map[string]string `yaml:"Parameters"`
+	Tags       map[string]string `yaml:"Tags"`
+}
+
+// CurrentRegion returns the region that the stack is being deployed to.
+// It is used to choose the values in a config file's Regions section.
+var CurrentRegion func() string
+
+// withRegion returns values with the values that the config file sets for a region taking precedence.
+// section is the Parameters or Tags section that regional comes from.
+// content is the config file, which is used to resolve !StackOutput values in the region's section.
+func withRegion(content []byte, region, section string, values, regional map[string]string) (map[string]string, error) {
+	if len(regional) == 0 {
+		return values, nil
+	}
+
+	if err := resolveStackOutputsAt(content, []string{"Regions", region, section}, regional); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(values)+len(regional))
+	for k, v := range values {
+		out[k] = v
+	}
+	for k, v := range regional {
+		out[k] = v
+	}
+
+	return out, nil
+}
+
+// stackPolicyJSON returns a config file's StackPolicy as a JSON document.
+// The policy can be written as YAML, or as a string of JSON.
+func stackPolicyJSON(policy interface{}) (string, error) {
+	if policy == nil {
+		return "", nil
+	}
+
+	if s, ok := policy.(string); ok {
+		if !json.Valid([]byte(s)) {
+			return "", errors.New("StackPolicy is not a valid JSON document")
+		}
+		return s, nil
+	}
+
+	out, err := json.Marshal(jsonValue(policy))
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// jsonValue converts the maps that yaml.v2 creates into maps that can be marshalled as JSON
+func jsonValue(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, v := range t {
+			m[fmt.Sprint(k)] = jsonValue(v)
+		}
+		return m
+	case []interface{}:
+		for i := range t {
+			t[i] = jsonValue(t[i])
+		}
+		return t
+	}
+
+	return v
 }
 
 // GetParameters checks the combined params supplied as args and in a file
@@ -60,48 +141,48 @@ func GetParameters(
 			}
 		}
 
-		// Decide on a default value
-		for k, p := range params.(map[string]interface{}) {
-			// New variable so we don't mess up the pointers below
-			param := p.(map[string]interface{})
+		missing := make([]string, 0)
+		kept := make([]string, 0)
+
+		// Decide on a value, in the order the parameters are declared
+		for _, k := range parameterNames(template) {
+			param, _ := params.(map[string]interface{})[k].(map[string]interface{})
+			spec := newParamSpec(param)
 
 			value := ""
 			usePrevious := false
 
 			// Decide if we have an existing value
-			if cliParam, ok := combinedParameters[k]; ok {
+			if cliParam, ok := combinedParameters[k]; ok && isSecretRef(cliParam) {
+				value = secretParameter(k, spec, cliParam)
+			} else if ok {
+				if err := spec.validate(cliParam); err != nil {
+					panic(fmt.Errorf("invalid value for parameter '%s': %w", k, err))
+				}
 				value = cliParam
 			} else {
-				extra := ""
+				label := ""
 
 				if oldParam, ok := oldMap[k]; ok {
-					extra = fmt.Sprintf(" (existing value: %s)", fmt.Sprint(*oldParam.ParameterValue))
-
-					if stackExists {
-						usePrevious = true
-					} else {
-						value = *oldParam.ParameterValue
-					}
+					label = "existing value"
+					value = ptr.ToString(oldParam.ParameterValue)
+					usePrevious = stackExists
 				} else if defaultValue, ok := param["Default"]; ok {
-					extra = fmt.Sprintf(" (default value: %s)", fmt.Sprint(defaultValue))
+					label = "default value"
 					value = fmt.Sprint(defaultValue)
 				} else if yes {
 					panic(fmt.Errorf("no default or existing value for parameter '%s'. Set a default, supply a --params flag, or deploy without the --yes flag", k))
+				} else if NoInput {
+					missing = append(missing, k)
+					continue
 				}
 
-				if !yes {
+				if usePrevious && KeepParams {
+					kept = append(kept, k)
+				} else if !yes && !NoInput {
 					spinner.Pause()
 
-					prompt := fmt.Sprintf("Enter a value for parameter '%s'", k)
-
-					if description, ok := param["Description"]; ok {
-						prompt += fmt.Sprintf(" \"%s\"", description)
-					}
-
-					prompt += fmt.Sprintf("%s:", extra)
-
-					newValue := console.Ask(prompt)
-					if newValue != "" {
+					if newValue, changed := askParameter(k, spec, value, label); changed {
 						value = newValue
 						usePrevious = false
 					}
@@ -120,6 +201,16 @@ func GetParameters(
 				})
 			}
 		}
+
+		if len(kept) > 0 {
+			spinner.Pause()
+			fmt.Println(console.Grey(fmt.Sprintf("Keeping the existing values of parameters: %s", strings.Join(kept, ", "))))
+		}
+
+		if len(missing) > 0 {
+			panic(fmt.Errorf("no value for parameters: %s. Set a default, supply a --params flag or a config file, or deploy without the --no-input flag",
+				strings.Join(missing, ", ")))
+		}
 	}
 
 	spinner.Resume()
@@ -197,6 +288,128 @@ func ConfigFromStack(stack types.Stack) (string, error) {
 	return string(configFileContent), err
 }
 
+// ExportStack returns a config file for a deployed stack, in the format that
+// the --config flag of rain deploy reads. CloudFormation doesn't return the values
+// of NoEcho parameters, so they are left out and their names are returned instead.
+func ExportStack(stack types.Stack) (string, []string, error) {
+	configFile := &configFileFormat{
+		Parameters: make(map[string]string),
+		Tags:       make(map[string]string),
+	}
+
+	for _, tag := range stack.Tags {
+		configFile.Tags[ptr.ToString(tag.Key)] = ptr.ToString(tag.Value)
+	}
+
+	noEcho := make([]string, 0)
+	for _, parameter := range stack.Parameters {
+		key, value := ptr.ToString(parameter.ParameterKey), ptr.ToString(parameter.ParameterValue)
+		if value == "****" {
+			noEcho = append(noEcho, key)
+			continue
+		}
+
+		configFile.Parameters[key] = value
+	}
+
+	sort.Strings(noEcho)
+
+	content, err := yaml.Marshal(configFile)
+
+	return string(content), noEcho, err
+}
+
+// readConfigFile returns the parameters, tags and stack policy in a config file,
+// with !StackOutput values resolved and the values for the current region applied.
+// It returns empty values if path is empty.
+func readConfigFile(path string) (map[string]string, map[string]string, string, error) {
+	if path == "" {
+		return make(map[string]string), make(map[string]string), "", nil
+	}
+
+	configFileContent, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, "", ui.Errorf(err, "unable to read config file '%s'", path)
+	}
+
+	configFileContent, err = normalizeConfigFile(configFileContent)
+	if err != nil {
+		return nil, nil, "", ui.Errorf(err, "unable to read parameters in '%s'", path)
+	}
+
+	var configFile configFileFormat
+	err = yaml.Unmarshal([]byte(configFileContent), &configFile)
+	if err != nil {
+		return nil, nil, "", ui.Errorf(err, "unable to parse yaml in '%s'", path)
+	}
+
+	sections := map[string]map[string]string{
+		"Parameters": configFile.Parameters,
+		"Tags":       configFile.Tags,
+		"parameters": configFile.LowerParameters,
+		"tags":       configFile.LowerTags,
+	}
+	for section, values := range sections {
+		err = ResolveStackOutputs(configFileContent, section, values)
+		if err != nil {
+			return nil, nil, "", ui.Errorf(err, "unable to resolve stack outputs in '%s'", path)
+		}
+	}
+
+	config.Debugf("Parsed config file struct: %+v", configFile)
+
+	stackPolicy, err := stackPolicyJSON(configFile.StackPolicy)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("invalid StackPolicy in '%s': %w", path, err)
+	}
+
+	tags := configFile.Tags
+	if len(tags) == 0 && len(configFile.LowerTags) > 0 {
+		tags = configFile.LowerTags
+	}
+	params := configFile.Parameters
+	if len(params) == 0 && len(configFile.LowerParameters) > 0 {
+		params = configFile.LowerParameters
+	}
+
+	if len(configFile.Regions) > 0 && CurrentRegion != nil {
+		region := CurrentRegion()
+		regional := configFile.Regions[region]
+
+		params, err = withRegion(configFileContent, region, "Parameters", params, regional.Parameters)
+		if err != nil {
+			return nil, nil, "", ui.Errorf(err, "unable to resolve stack outputs in '%s'", path)
+		}
+
+		tags, err = withRegion(configFileContent, region, "Tags", tags, regional.Tags)
+		if err != nil {
+			return nil, nil, "", ui.Errorf(err, "unable to resolve stack outputs in '%s'", path)
+		}
+	}
+
+	if params == nil {
+		params = make(map[string]string)
+	}
+	if tags == nil {
+		tags = make(map[string]string)
+	}
+
+	return params, tags, stackPolicy, nil
+}
+
+// override returns values with the values set by a flag taking precedence.
+// If message is set, it is shown with the name of each value that the flag overrides.
+func override(values, flagValues map[string]string, message string) map[string]string {
+	for k, v := range flagValues {
+		if _, ok := values[k]; ok && message != "" {
+			fmt.Println(console.Yellow(fmt.Sprintf(message, k)))
+		}
+		values[k] = v
+	}
+
+	return values
+}
+
 // GetDeployConfig populates an instance of DeployConfig based on user-supplied values
 func GetDeployConfig(
 	tags []string,
@@ -217,49 +430,14 @@ func GetDeployConfig(
 	// Parse params
 	parsedParamFlag := ListToMap("param", params)
 
-	var combinedTags map[string]string
-	var combinedParameters map[string]string
-
-	if len(configFilePath) != 0 {
-		configFileContent, err := os.ReadFile(configFilePath)
-		if err != nil {
-			panic(ui.Errorf(err, "unable to read config file '%s'", configFilePath))
-		}
-
-		var configFile configFileFormat
-		err = yaml.Unmarshal([]byte(configFileContent), &configFile)
-		if err != nil {
-			panic(ui.Errorf(err, "unable to parse yaml in '%s'", configFilePath))
-		}
-
-		config.Debugf("Parsed config file struct: %+v", configFile)
-
-		combinedTags = configFile.Tags
-		if len(combinedTags) == 0 && len(configFile.LowerTags) > 0 {
-			combinedTags = configFile.LowerTags
-		}
-		combinedParameters = configFile.Parameters
-		if len(combinedParameters) == 0 && len(configFile.LowerParameters) > 0 {
-			combinedParameters = configFile.LowerParameters
-		}
-
-		for k, v := range parsedTagFlag {
-			if _, ok := combinedTags[k]; ok {
-				fmt.Println(console.Yellow(fmt.Sprintf("tags flag overrides tag in config file: %s", k)))
-			}
-			combinedTags[k] = v
-		}
-
-		for k, v := range parsedParamFlag {
-			if _, ok := combinedParameters[k]; ok {
-				fmt.Println(console.Yellow(fmt.Sprintf("params flag overrides parameter in config file: %s", k)))
-			}
-			combinedParameters[k] = v
-		}
-	} else {
-		combinedTags = parsedTagFlag
-		combinedParameters = parsedParamFlag
+	combinedParameters, combinedTags, stackPolicy, err := readConfigFile(configFilePath)
+	if err != nil {
+		return nil, err
 	}
+	dc.StackPolicy = stackPolicy
+
+	combinedTags = override(combinedTags, parsedTagFlag, "tags flag overrides tag in config file: %s")
+	combinedParameters = override(combinedParameters, parsedParamFlag, "params flag overrides parameter in config file: %s")
 
 	dc.Tags = combinedTags
 
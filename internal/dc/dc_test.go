@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/aws/smithy-go/ptr"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v2"
 )
 
 func TestListToMap(t *testing.T) {
@@ -94,3 +97,256 @@ func TestConfigFromStack(t *testing.T) {
 
 	}
 }
+
+func TestResolveStackOutputs(t *testing.T) {
+	content := []byte(`Parameters:
+  VpcId: !StackOutput network.VpcId
+  Name: test
+Tags:
+  Team: !StackOutput network.Team
+`)
+
+	lookups := 0
+	LookupStackOutput = func(stackName, outputKey string) (string, error) {
+		lookups++
+		if stackName != "network" {
+			return "", fmt.Errorf("unknown stack %s", stackName)
+		}
+		return "value-of-" + outputKey, nil
+	}
+	defer func() { LookupStackOutput = nil }()
+
+	params := map[string]string{"VpcId": "network.VpcId", "Name": "test"}
+	if err := ResolveStackOutputs(content, "Parameters", params); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"VpcId": "value-of-VpcId", "Name": "test"}
+	if d := cmp.Diff(expected, params); d != "" {
+		t.Errorf(d)
+	}
+
+	if lookups != 1 {
+		t.Errorf("expected 1 lookup, got %d", lookups)
+	}
+
+	err := ResolveStackOutputs([]byte("Parameters:\n  VpcId: !StackOutput network\n"), "Parameters", params)
+	if err == nil {
+		t.Error("expected an error for a value without an output key")
+	}
+
+	err = ResolveStackOutputs([]byte("Parameters:\n  VpcId: !StackOutput other.VpcId\n"), "Parameters", params)
+	if err == nil {
+		t.Error("expected an error for an unknown stack")
+	}
+}
+
+func TestWithRegion(t *testing.T) {
+	content := []byte(`Parameters:
+  InstanceType: t3.micro
+  Name: app
+Regions:
+  eu-west-1:
+    Parameters:
+      InstanceType: t3.small
+      VpcId: !StackOutput network.VpcId
+`)
+
+	var configFile configFileFormat
+	if err := yaml.Unmarshal(content, &configFile); err != nil {
+		t.Fatal(err)
+	}
+
+	LookupStackOutput = func(stackName, outputKey string) (string, error) {
+		return "vpc-eu", nil
+	}
+	defer func() { LookupStackOutput = nil }()
+
+	params, err := withRegion(content, "eu-west-1", "Parameters",
+		configFile.Parameters, configFile.Regions["eu-west-1"].Parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]string{"InstanceType": "t3.small", "Name": "app", "VpcId": "vpc-eu"}
+	if d := cmp.Diff(expected, params); d != "" {
+		t.Error(d)
+	}
+
+	// The file's own values are left alone for other regions
+	if configFile.Parameters["InstanceType"] != "t3.micro" {
+		t.Errorf("the top level parameters were changed: %v", configFile.Parameters)
+	}
+
+	params, err = withRegion(content, "us-east-1", "Parameters",
+		configFile.Parameters, configFile.Regions["us-east-1"].Parameters)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if d := cmp.Diff(configFile.Parameters, params); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestExportStack(t *testing.T) {
+	stack := types.Stack{
+		Parameters: []types.Parameter{
+			{ParameterKey: ptr.String("Name"), ParameterValue: ptr.String("app")},
+			{ParameterKey: ptr.String("Password"), ParameterValue: ptr.String("****")},
+		},
+		Tags: []types.Tag{
+			{Key: ptr.String("Team"), Value: ptr.String("platform")},
+		},
+	}
+
+	content, noEcho, err := ExportStack(stack)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "Parameters:\n  Name: app\nTags:\n  Team: platform\n"
+	if content != expected {
+		t.Errorf("expected '%s', got '%s'", expected, content)
+	}
+
+	if len(noEcho) != 1 || noEcho[0] != "Password" {
+		t.Errorf("expected Password to be left out: %v", noEcho)
+	}
+}
+
+func TestStackPolicyJSON(t *testing.T) {
+	var configFile configFileFormat
+	err := yaml.Unmarshal([]byte(`
+Parameters:
+  Name: test
+StackPolicy:
+  Statement:
+    - Effect: Deny
+      Action: Update:Replace
+      Principal: "*"
+      Resource: LogicalResourceId/Database
+`), &configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := stackPolicyJSON(configFile.StackPolicy)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"Statement":[{"Action":"Update:Replace","Effect":"Deny","Principal":"*","Resource":"LogicalResourceId/Database"}]}`
+	if d := cmp.Diff(expected, policy); d != "" {
+		t.Error(d)
+	}
+
+	if policy, err := stackPolicyJSON(`{"Statement": []}`); err != nil || policy != `{"Statement": []}` {
+		t.Errorf("unexpected result for a JSON string: %q, %v", policy, err)
+	}
+
+	if _, err := stackPolicyJSON("not json"); err == nil {
+		t.Error("expected an error for a string that isn't JSON")
+	}
+
+	if policy, err := stackPolicyJSON(nil); err != nil || policy != "" {
+		t.Errorf("unexpected result for no policy: %q, %v", policy, err)
+	}
+}
+
+func TestNormalizeConfigFile(t *testing.T) {
+	expected := map[string]string{"Environment": "prod", "Count": "3", "Subnets": "subnet-1,subnet-2"}
+
+	for _, content := range []string{
+		// aws cloudformation deploy --parameter-overrides
+		`["Environment=prod", "Count=3", "Subnets=subnet-1,subnet-2"]`,
+
+		// aws cloudformation create-stack --parameters
+		`[
+  {"ParameterKey": "Environment", "ParameterValue": "prod"},
+  {"ParameterKey": "Count", "ParameterValue": 3},
+  {"ParameterKey": "Subnets", "ParameterValue": "subnet-1,subnet-2"},
+  {"ParameterKey": "Version", "UsePreviousValue": true}
+]`,
+
+		// CodePipeline template configuration, which is rain's format
+		`{"Parameters": {"Environment": "prod", "Count": 3, "Subnets": "subnet-1,subnet-2"}, "Tags": {"Team": "platform"}}`,
+	} {
+		normalized, err := normalizeConfigFile([]byte(content))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var configFile configFileFormat
+		if err := yaml.Unmarshal(normalized, &configFile); err != nil {
+			t.Fatal(err)
+		}
+
+		if d := cmp.Diff(expected, configFile.Parameters); d != "" {
+			t.Errorf("%s: %s", content, d)
+		}
+	}
+
+	for _, content := range []string{
+		`["Environment"]`,
+		`[{"ParameterValue": "prod"}]`,
+		`["Environment=prod", "Environment=dev"]`,
+	} {
+		if _, err := normalizeConfigFile([]byte(content)); err == nil {
+			t.Errorf("expected an error for %s", content)
+		}
+	}
+}
+
+func TestTemplateConfiguration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	err := os.WriteFile(path, []byte(`
+Parameters:
+  Environment: dev
+  Password: rain-ssm://app/password
+Tags:
+  Team: platform
+StackPolicy:
+  Statement:
+    - Effect: Deny
+      Action: Update:Replace
+      Principal: "*"
+      Resource: LogicalResourceId/Database
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual, secrets, err := TemplateConfiguration(path, []string{"Environment=prod"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "Parameters": {
+    "Environment": "prod",
+    "Password": "rain-ssm://app/password"
+  },
+  "Tags": {
+    "Team": "platform"
+  },
+  "StackPolicy": {
+    "Statement": [
+      {
+        "Action": "Update:Replace",
+        "Effect": "Deny",
+        "Principal": "*",
+        "Resource": "LogicalResourceId/Database"
+      }
+    ]
+  }
+}
+`
+	if d := cmp.Diff(expected, actual); d != "" {
+		t.Error(d)
+	}
+
+	if len(secrets) != 1 || secrets[0] != "Password" {
+		t.Errorf("expected Password to refer to a secret: %v", secrets)
+	}
+}
@@ -0,0 +1,103 @@
+package dc
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// normalizeConfigFile returns the content of a config file in rain's format.
+// Besides rain's format, which is the same as a CodePipeline template configuration file,
+// config files can be written in the formats that the aws cli reads parameters from:
+//
+// A list of Key=Value strings, as used by aws cloudformation deploy --parameter-overrides:
+//
+//	["Environment=prod", "InstanceType=t3.micro"]
+//
+// A list of parameters, as used by aws cloudformation create-stack --parameters:
+//
+//	[{"ParameterKey": "Environment", "ParameterValue": "prod"}]
+//
+// Parameters that set UsePreviousValue instead of a value are left out,
+// so they keep their existing values as any other parameter that isn't set does.
+func normalizeConfigFile(content []byte) ([]byte, error) {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	if !isList(&doc) {
+		return content, nil
+	}
+
+	params := make(map[string]string)
+
+	for i, item := range doc.Content[0].Content {
+		var key, value string
+
+		switch item.Kind {
+		case yamlv3.ScalarNode:
+			var ok bool
+			key, value, ok = strings.Cut(item.Value, "=")
+			if !ok {
+				return nil, fmt.Errorf("parameter %d, '%s', should be in the form Key=Value", i+1, item.Value)
+			}
+		case yamlv3.MappingNode:
+			k, v := scalarValue(item, "ParameterKey"), scalarValue(item, "ParameterValue")
+			if k == nil {
+				return nil, fmt.Errorf("parameter %d has no ParameterKey", i+1)
+			}
+			key = k.Value
+
+			if v == nil {
+				if previous := scalarValue(item, "UsePreviousValue"); previous != nil && previous.Value == "true" {
+					continue
+				}
+				return nil, fmt.Errorf("parameter '%s' has no ParameterValue", key)
+			}
+			value = v.Value
+		default:
+			return nil, fmt.Errorf("parameter %d should be a Key=Value string or have a ParameterKey and ParameterValue", i+1)
+		}
+
+		key = strings.TrimSpace(key)
+		if _, ok := params[key]; ok {
+			return nil, fmt.Errorf("duplicate parameter: %s", key)
+		}
+		params[key] = value
+	}
+
+	return yaml.Marshal(&configFileFormat{
+		Parameters: params,
+		Tags:       make(map[string]string),
+	})
+}
+
+// IsParametersOnly returns true if the content of a config file is in one of the aws cli's
+// list formats, which only set parameters, so the file has no other sections to read.
+func IsParametersOnly(content []byte) bool {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+
+	return isList(&doc)
+}
+
+// isList returns true if the document's top level is a list
+func isList(doc *yamlv3.Node) bool {
+	return len(doc.Content) > 0 && doc.Content[0].Kind == yamlv3.SequenceNode
+}
+
+// scalarValue returns the value of a key in a mapping node, if it is a scalar
+func scalarValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key && node.Content[i+1].Kind == yamlv3.ScalarNode {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package dc
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// templateConfiguration is the format of a CodePipeline template configuration file
+type templateConfiguration struct {
+	Parameters  map[string]string `json:"Parameters"`
+	Tags        map[string]string `json:"Tags,omitempty"`
+	StackPolicy json.RawMessage   `json:"StackPolicy,omitempty"`
+}
+
+// TemplateConfiguration returns the parameters, tags and stack policy from a config file and flags
+// as a CodePipeline template configuration file, which the CloudFormation deploy action reads.
+// Values from the flags take precedence, and either can be empty.
+// The names of parameters that refer to secrets are returned too, because the deploy action
+// passes their values to CloudFormation as they are instead of reading the secrets.
+func TemplateConfiguration(configFilePath string, params, tags []string) (string, []string, error) {
+	parameters, tagValues, stackPolicy, err := readConfigFile(configFilePath)
+	if err != nil {
+		return "", nil, err
+	}
+
+	c := templateConfiguration{
+		Parameters: override(parameters, ListToMap("param", params), ""),
+		Tags:       override(tagValues, ListToMap("tag", tags), ""),
+	}
+
+	if stackPolicy != "" {
+		c.StackPolicy = json.RawMessage(stackPolicy)
+	}
+
+	secrets := make([]string, 0)
+	for k, v := range c.Parameters {
+		if isSecretRef(v) {
+			secrets = append(secrets, k)
+		}
+	}
+
+	out, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to write template configuration: %w", err)
+	}
+
+	sort.Strings(secrets)
+
+	return string(out) + "\n", secrets, nil
+}
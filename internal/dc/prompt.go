@@ -0,0 +1,255 @@
+package dc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/console"
+)
+
+// NoInput stops rain from asking for parameter values.
+// Deployment fails if a parameter has no value from the command line,
+// the config file, the existing stack, or its default.
+var NoInput = false
+
+// KeepParams keeps the existing value of every parameter of a stack that is updated,
+// unless a new value is supplied on the command line or in the config file
+var KeepParams = false
+
+// paramSpec is the definition of a template parameter
+type paramSpec struct {
+	Type                  string
+	Description           string
+	AllowedValues         []string
+	AllowedPattern        string
+	ConstraintDescription string
+	NoEcho                bool
+	MinLength             *int
+	MaxLength             *int
+	MinValue              *float64
+	MaxValue              *float64
+}
+
+func toNumber(v any) (float64, bool) {
+	f, err := strconv.ParseFloat(fmt.Sprint(v), 64)
+	return f, err == nil
+}
+
+// newParamSpec reads a parameter's definition from the decoded template
+func newParamSpec(param map[string]any) paramSpec {
+	spec := paramSpec{Type: "String"}
+
+	if t, ok := param["Type"]; ok {
+		spec.Type = fmt.Sprint(t)
+	}
+
+	if d, ok := param["Description"]; ok {
+		spec.Description = fmt.Sprint(d)
+	}
+
+	if values, ok := param["AllowedValues"].([]any); ok {
+		for _, v := range values {
+			spec.AllowedValues = append(spec.AllowedValues, fmt.Sprint(v))
+		}
+	}
+
+	if p, ok := param["AllowedPattern"]; ok {
+		spec.AllowedPattern = fmt.Sprint(p)
+	}
+
+	if c, ok := param["ConstraintDescription"]; ok {
+		spec.ConstraintDescription = fmt.Sprint(c)
+	}
+
+	if n, ok := param["NoEcho"]; ok {
+		spec.NoEcho = strings.EqualFold(fmt.Sprint(n), "true")
+	}
+
+	for key, field := range map[string]**int{"MinLength": &spec.MinLength, "MaxLength": &spec.MaxLength} {
+		if v, ok := param[key]; ok {
+			if n, ok := toNumber(v); ok {
+				i := int(n)
+				*field = &i
+			}
+		}
+	}
+
+	for key, field := range map[string]**float64{"MinValue": &spec.MinValue, "MaxValue": &spec.MaxValue} {
+		if v, ok := param[key]; ok {
+			if n, ok := toNumber(v); ok {
+				*field = &n
+			}
+		}
+	}
+
+	return spec
+}
+
+// validate checks the value against the parameter's constraints.
+// Only String and Number parameters are checked; CloudFormation checks the others.
+func (spec paramSpec) validate(value string) error {
+	if spec.Type != "String" && spec.Type != "Number" {
+		return nil
+	}
+
+	err := spec.check(value)
+	if err != nil && spec.ConstraintDescription != "" {
+		return fmt.Errorf("%w: %s", err, spec.ConstraintDescription)
+	}
+
+	return err
+}
+
+func (spec paramSpec) check(value string) error {
+	if len(spec.AllowedValues) > 0 {
+		allowed := false
+		for _, v := range spec.AllowedValues {
+			if v == value {
+				allowed = true
+				break
+			}
+		}
+
+		if !allowed {
+			return fmt.Errorf("'%s' is not one of %s", value, strings.Join(spec.AllowedValues, ", "))
+		}
+	}
+
+	if spec.Type == "Number" {
+		n, ok := toNumber(value)
+		if !ok {
+			return fmt.Errorf("'%s' is not a number", value)
+		}
+
+		if spec.MinValue != nil && n < *spec.MinValue {
+			return fmt.Errorf("%s is less than %v", value, *spec.MinValue)
+		}
+
+		if spec.MaxValue != nil && n > *spec.MaxValue {
+			return fmt.Errorf("%s is more than %v", value, *spec.MaxValue)
+		}
+
+		return nil
+	}
+
+	if spec.AllowedPattern != "" {
+		// CloudFormation matches the pattern against the whole value
+		re, err := regexp.Compile("^(?:" + spec.AllowedPattern + ")$")
+		if err != nil {
+			// Go does not support every pattern that CloudFormation does
+			return nil
+		}
+
+		if !re.MatchString(value) {
+			return fmt.Errorf("'%s' does not match the pattern %s", value, spec.AllowedPattern)
+		}
+	}
+
+	if spec.MinLength != nil && len(value) < *spec.MinLength {
+		return fmt.Errorf("the value is shorter than %d characters", *spec.MinLength)
+	}
+
+	if spec.MaxLength != nil && len(value) > *spec.MaxLength {
+		return fmt.Errorf("the value is longer than %d characters", *spec.MaxLength)
+	}
+
+	return nil
+}
+
+// choose returns the allowed value that the answer selects,
+// which is either the value itself or its number in the list
+func choose(answer string, allowed []string) (string, error) {
+	for _, v := range allowed {
+		if v == answer {
+			return v, nil
+		}
+	}
+
+	if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(allowed) {
+		return allowed[i-1], nil
+	}
+
+	return "", fmt.Errorf("choose a number from 1 to %d", len(allowed))
+}
+
+// parameterNames returns the names of the template's parameters in the order they are declared
+func parameterNames(template cft.Template) []string {
+	names := make([]string, 0)
+
+	params, err := template.GetSection(cft.Parameters)
+	if err != nil || params == nil {
+		return names
+	}
+
+	for i := 0; i < len(params.Content); i += 2 {
+		names = append(names, params.Content[i].Value)
+	}
+
+	return names
+}
+
+// askParameter asks for the value of a parameter until the answer is valid.
+// current is the value that is kept if the answer is empty, and is described by label.
+// It returns false if the current value should be kept.
+func askParameter(name string, spec paramSpec, current string, label string) (string, bool) {
+	header := fmt.Sprintf("%s (%s)", console.Yellow(name), spec.Type)
+	if spec.Description != "" {
+		header += " " + console.Grey(spec.Description)
+	}
+	console.Log(header)
+
+	if len(spec.AllowedValues) > 0 {
+		for i, v := range spec.AllowedValues {
+			marker := " "
+			if label != "" && v == current {
+				marker = "*"
+			}
+			console.Logf(" %s %d. %s", marker, i+1, v)
+		}
+	}
+
+	hint := ""
+	if label != "" {
+		hint = fmt.Sprintf(" (leave blank to keep the %s)", label)
+	}
+
+	for {
+		var answer string
+
+		switch {
+		case len(spec.AllowedValues) > 0:
+			answer = console.Ask(fmt.Sprintf("  Choose a value%s:", hint))
+		case spec.NoEcho:
+			answer = console.AskSecret(fmt.Sprintf("  Enter a value%s:", hint))
+		default:
+			// The current value is filled in so that it can be edited
+			answer = console.AskWithDefault("  Enter a value:", current)
+			if label != "" && answer == current {
+				return current, false
+			}
+		}
+
+		if answer == "" && label != "" {
+			return current, false
+		}
+
+		var err error
+		if len(spec.AllowedValues) > 0 {
+			answer, err = choose(answer, spec.AllowedValues)
+		}
+
+		if err == nil {
+			err = spec.validate(answer)
+		}
+
+		if err != nil {
+			console.Log(console.Red("  " + err.Error()))
+			continue
+		}
+
+		return answer, true
+	}
+}
@@ -0,0 +1,167 @@
+package dc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+const paramTemplate = `
+Parameters:
+  Size:
+    Type: Number
+    MinValue: 1
+    MaxValue: 10
+    Default: 2
+  Env:
+    Type: String
+    AllowedValues: [dev, prod]
+  Name:
+    Type: String
+    AllowedPattern: "[a-z]+"
+    MaxLength: 8
+    ConstraintDescription: lower case letters only
+  Password:
+    Type: String
+    NoEcho: true
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+`
+
+func TestParamSpecValidate(t *testing.T) {
+	tmpl, err := parse.String(paramTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := tmpl.Map()["Parameters"].(map[string]any)
+	spec := func(name string) paramSpec {
+		return newParamSpec(params[name].(map[string]any))
+	}
+
+	cases := []struct {
+		param string
+		value string
+		valid bool
+	}{
+		{"Size", "5", true},
+		{"Size", "11", false},
+		{"Size", "five", false},
+		{"Env", "prod", true},
+		{"Env", "test", false},
+		{"Name", "abc", true},
+		{"Name", "abc1", false},
+		{"Name", "abcdefghi", false},
+		{"Password", "anything", true},
+	}
+
+	for _, c := range cases {
+		err := spec(c.param).validate(c.value)
+		if (err == nil) != c.valid {
+			t.Errorf("%s=%s: expected valid to be %t, got %v", c.param, c.value, c.valid, err)
+		}
+	}
+
+	if err := spec("Name").validate("ABC"); err == nil || !strings.Contains(err.Error(), "lower case letters only") {
+		t.Errorf("expected the constraint description in the error: %v", err)
+	}
+
+	if !spec("Password").NoEcho {
+		t.Error("expected Password to be NoEcho")
+	}
+}
+
+func TestChoose(t *testing.T) {
+	allowed := []string{"dev", "prod"}
+
+	for answer, expected := range map[string]string{"prod": "prod", "1": "dev", "2": "prod"} {
+		actual, err := choose(answer, allowed)
+		if err != nil || actual != expected {
+			t.Errorf("%s: expected %s, got %s (%v)", answer, expected, actual, err)
+		}
+	}
+
+	if _, err := choose("3", allowed); err == nil {
+		t.Error("expected an error for a number out of range")
+	}
+}
+
+func TestGetParametersNoInput(t *testing.T) {
+	tmpl, err := parse.String(paramTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	NoInput = true
+	defer func() { NoInput = false }()
+
+	if names := parameterNames(tmpl); strings.Join(names, ",") != "Size,Env,Name,Password" {
+		t.Errorf("expected the parameters in template order: %v", names)
+	}
+
+	params := GetParameters(tmpl, map[string]string{"Env": "dev", "Name": "app", "Password": "secret"},
+		nil, false, false, false)
+
+	if len(params) != 4 || ptr.ToString(params[0].ParameterValue) != "2" {
+		t.Errorf("expected the default value for Size: %v", params)
+	}
+
+	func() {
+		defer func() {
+			r := recover()
+			if r == nil || !strings.Contains(r.(error).Error(), "Env, Password") {
+				t.Errorf("expected the missing parameters to be listed: %v", r)
+			}
+		}()
+
+		GetParameters(tmpl, map[string]string{"Name": "app"}, nil, false, false, false)
+	}()
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected an invalid value to be rejected")
+			}
+		}()
+
+		GetParameters(tmpl, map[string]string{"Env": "test", "Name": "app", "Password": "x"}, nil, false, false, false)
+	}()
+}
+
+func TestGetParametersKeepParams(t *testing.T) {
+	tmpl, err := parse.String(paramTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	KeepParams = true
+	defer func() { KeepParams = false }()
+
+	old := []types.Parameter{
+		{ParameterKey: ptr.String("Size"), ParameterValue: ptr.String("3")},
+		{ParameterKey: ptr.String("Env"), ParameterValue: ptr.String("prod")},
+		{ParameterKey: ptr.String("Name"), ParameterValue: ptr.String("app")},
+		{ParameterKey: ptr.String("Password"), ParameterValue: ptr.String("****")},
+	}
+
+	params := GetParameters(tmpl, map[string]string{"Name": "other"}, old, true, false, false)
+
+	if len(params) != 4 {
+		t.Fatalf("expected 4 parameters: %v", params)
+	}
+
+	for _, p := range params {
+		key := ptr.ToString(p.ParameterKey)
+		if key == "Name" {
+			if ptr.ToBool(p.UsePreviousValue) || ptr.ToString(p.ParameterValue) != "other" {
+				t.Errorf("expected the new value for Name: %v", p)
+			}
+		} else if !ptr.ToBool(p.UsePreviousValue) || p.ParameterValue != nil {
+			t.Errorf("expected the previous value for %s: %v", key, p)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package dc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/console"
+)
+
+// SecretsManagerScheme marks a parameter value that is read from Secrets Manager,
+// e.g. rain-secretsmanager://arn:aws:secretsmanager:us-east-1:123456789012:secret:db
+// A key can be added to read one field of a JSON secret, e.g. rain-secretsmanager://db#password
+const SecretsManagerScheme = "rain-secretsmanager://"
+
+// SSMScheme marks a parameter value that is read from the SSM Parameter Store,
+// e.g. rain-ssm:///app/db/password
+const SSMScheme = "rain-ssm://"
+
+// LookupSecret returns the value of a Secrets Manager secret.
+// It is used to resolve rain-secretsmanager:// parameter values.
+var LookupSecret func(secretId string) (string, error)
+
+// LookupSSMParameter returns the decrypted value of an SSM parameter.
+// It is used to resolve rain-ssm:// parameter values.
+var LookupSSMParameter func(name string) (string, error)
+
+// isSecretRef returns true if the value refers to a secret
+func isSecretRef(value string) bool {
+	return strings.HasPrefix(value, SecretsManagerScheme) || strings.HasPrefix(value, SSMScheme)
+}
+
+// resolveSecret returns the value of the secret that ref refers to.
+// The value is recorded so that it is masked in rain's output.
+func resolveSecret(ref string) (string, error) {
+	var value string
+	var err error
+
+	switch {
+	case strings.HasPrefix(ref, SSMScheme):
+		name := strings.TrimPrefix(ref, SSMScheme)
+		if name == "" {
+			return "", fmt.Errorf("%s has no parameter name", ref)
+		}
+
+		if LookupSSMParameter == nil {
+			return "", errors.New("SSM parameters can't be read")
+		}
+
+		value, err = LookupSSMParameter(name)
+	case strings.HasPrefix(ref, SecretsManagerScheme):
+		id, key, hasKey := strings.Cut(strings.TrimPrefix(ref, SecretsManagerScheme), "#")
+		if id == "" {
+			return "", fmt.Errorf("%s has no secret ID", ref)
+		}
+
+		if LookupSecret == nil {
+			return "", errors.New("secrets can't be read")
+		}
+
+		value, err = LookupSecret(id)
+		if err == nil && hasKey {
+			value, err = secretKey(value, key)
+		}
+	default:
+		return "", fmt.Errorf("'%s' is not a secret reference", ref)
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	config.AddSecret(value)
+
+	return value, nil
+}
+
+// secretKey returns one field of a secret that is stored as JSON
+func secretKey(secret string, key string) (string, error) {
+	fields := make(map[string]any)
+	if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+		return "", fmt.Errorf("the secret is not a JSON object, so key '%s' can't be read", key)
+	}
+
+	v, ok := fields[key]
+	if !ok {
+		return "", fmt.Errorf("the secret has no key '%s'", key)
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	out, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
+
+// secretParameter resolves the secret reference that is the value of a parameter
+// and checks the value against the parameter's constraints, without showing it
+func secretParameter(name string, spec paramSpec, ref string) string {
+	value, err := resolveSecret(ref)
+	if err != nil {
+		panic(fmt.Errorf("unable to read the value of parameter '%s' from %s: %w", name, ref, err))
+	}
+
+	if err := spec.validate(value); err != nil {
+		panic(fmt.Errorf("invalid value for parameter '%s' from %s: %s", name, ref, config.Mask(err.Error())))
+	}
+
+	if !spec.NoEcho {
+		fmt.Println(console.Yellow(fmt.Sprintf("parameter '%s' is read from %s but is not NoEcho, so CloudFormation will show its value", name, ref)))
+	}
+
+	return value
+}
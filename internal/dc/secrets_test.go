@@ -0,0 +1,89 @@
+package dc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws/smithy-go/ptr"
+)
+
+func TestResolveSecret(t *testing.T) {
+	LookupSSMParameter = func(name string) (string, error) {
+		if name == "/app/password" {
+			return "ssm-secret", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	LookupSecret = func(id string) (string, error) {
+		return `{"username": "admin", "password": "sm-secret", "port": 5432}`, nil
+	}
+
+	defer func() {
+		LookupSSMParameter = nil
+		LookupSecret = nil
+	}()
+
+	cases := map[string]string{
+		"rain-ssm:///app/password":          "ssm-secret",
+		"rain-secretsmanager://db#password": "sm-secret",
+		"rain-secretsmanager://db#port":     "5432",
+	}
+
+	for ref, expected := range cases {
+		actual, err := resolveSecret(ref)
+		if err != nil {
+			t.Errorf("%s: %v", ref, err)
+		} else if actual != expected {
+			t.Errorf("%s: expected %s, got %s", ref, expected, actual)
+		}
+	}
+
+	for _, ref := range []string{"rain-ssm://", "rain-ssm:///missing", "rain-secretsmanager://db#missing", "plain"} {
+		if _, err := resolveSecret(ref); err == nil {
+			t.Errorf("%s: expected an error", ref)
+		}
+	}
+
+	if masked := config.Mask("password is sm-secret"); masked != "password is ****" {
+		t.Errorf("expected the secret to be masked: %s", masked)
+	}
+}
+
+func TestGetParametersSecret(t *testing.T) {
+	tmpl, err := parse.String(paramTemplate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	LookupSSMParameter = func(name string) (string, error) {
+		return map[string]string{"/password": "hunter2", "/name": "NotValid"}[name], nil
+	}
+	defer func() { LookupSSMParameter = nil }()
+
+	params := GetParameters(tmpl, map[string]string{"Env": "dev", "Name": "app", "Password": "rain-ssm:///password"},
+		nil, false, true, false)
+
+	if ptr.ToString(params[3].ParameterValue) != "hunter2" {
+		t.Errorf("expected the secret value for Password: %v", ptr.ToString(params[3].ParameterValue))
+	}
+
+	func() {
+		defer func() {
+			r := recover()
+			if r == nil {
+				t.Fatal("expected an invalid secret to be rejected")
+			}
+
+			if msg := r.(error).Error(); strings.Contains(msg, "NotValid") || !strings.Contains(msg, "rain-ssm:///name") {
+				t.Errorf("expected the error to name the reference but not the value: %s", msg)
+			}
+		}()
+
+		GetParameters(tmpl, map[string]string{"Env": "dev", "Name": "rain-ssm:///name", "Password": "x"},
+			nil, false, true, false)
+	}()
+}
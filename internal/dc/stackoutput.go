@@ -0,0 +1,98 @@
+package dc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// StackOutputTag marks a config file value that is the output of another stack,
+// e.g. VpcId: !StackOutput network.VpcId
+const StackOutputTag = "!StackOutput"
+
+// LookupStackOutput returns the value of an output of a deployed stack.
+// It is used to resolve !StackOutput values in config files.
+var LookupStackOutput func(stackName, outputKey string) (string, error)
+
+// parseStackOutput splits a !StackOutput value into a stack name and output key
+func parseStackOutput(value string) (string, string, error) {
+	// Stack names can't contain dots
+	stackName, outputKey, ok := strings.Cut(value, ".")
+	if !ok || stackName == "" || outputKey == "" {
+		return "", "", fmt.Errorf("%s '%s' should be in the form StackName.OutputKey", StackOutputTag, value)
+	}
+
+	return stackName, outputKey, nil
+}
+
+// ResolveStackOutputs replaces the values in the named section of a config file
+// that are tagged with !StackOutput with the value of that output.
+// values is the section as parsed from content.
+// The outputs are read when the config file is used, so that stacks can share
+// values without coupling their templates with Fn::ImportValue.
+func ResolveStackOutputs(content []byte, section string, values map[string]string) error {
+	return resolveStackOutputsAt(content, []string{section}, values)
+}
+
+// resolveStackOutputsAt is ResolveStackOutputs for a section that is nested in other sections,
+// such as the Parameters for a region: Regions, us-east-1, Parameters
+func resolveStackOutputsAt(content []byte, path []string, values map[string]string) error {
+	var doc yamlv3.Node
+	if err := yamlv3.Unmarshal(content, &doc); err != nil {
+		return err
+	}
+
+	if len(doc.Content) == 0 {
+		return nil
+	}
+
+	node := doc.Content[0]
+	for _, key := range path {
+		node = mappingValue(node, key)
+		if node == nil {
+			return nil
+		}
+	}
+
+	for j := 0; j < len(node.Content)-1; j += 2 {
+		key, value := node.Content[j].Value, node.Content[j+1]
+		if value.Tag != StackOutputTag {
+			continue
+		}
+
+		stackName, outputKey, err := parseStackOutput(value.Value)
+		if err != nil {
+			return err
+		}
+
+		if LookupStackOutput == nil {
+			return errors.New("stack outputs can't be looked up")
+		}
+
+		v, err := LookupStackOutput(stackName, outputKey)
+		if err != nil {
+			return fmt.Errorf("unable to resolve %s for '%s': %w", StackOutputTag, key, err)
+		}
+
+		values[key] = v
+	}
+
+	return nil
+}
+
+// mappingValue returns the mapping that key is set to in node, or nil if there isn't one
+func mappingValue(node *yamlv3.Node, key string) *yamlv3.Node {
+	if node.Kind != yamlv3.MappingNode {
+		return nil
+	}
+
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key && node.Content[i+1].Kind == yamlv3.MappingNode {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
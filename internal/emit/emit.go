@@ -0,0 +1,118 @@
+// Package emit writes structured events and results to stdout as newline-delimited JSON
+// when rain is run with --output-format json (or RAIN_OUTPUT=json), so that other programs
+// can drive rain without reading its coloured, human output.
+//
+// Each line is an object with a type, the time it was written, and the event's data:
+//
+//	{"type":"stack_event","time":"2024-05-01T12:00:00Z","data":{"stackName":"app",...}}
+//
+// In this mode, rain's human output is written to stderr instead of stdout,
+// without colour and with progress as plain lines.
+package emit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+)
+
+// Output formats for --output-format
+const (
+	Text = "text"
+	JSON = "json"
+)
+
+// Format is the output format, set with --output-format or RAIN_OUTPUT
+var Format = defaultFormat()
+
+func defaultFormat() string {
+	if f := os.Getenv("RAIN_OUTPUT"); f != "" {
+		return f
+	}
+
+	return Text
+}
+
+// Types of event that are written by more than one command
+const (
+	// Error is written when a command fails, with the error's message
+	Error = "error"
+
+	// Exit is the last event that every command writes, with its exit code
+	Exit = "exit"
+)
+
+var out io.Writer = os.Stdout
+var stdout *os.File
+var mu sync.Mutex
+
+// now is replaced in tests
+var now = time.Now
+
+// Enabled returns true if structured output is turned on
+func Enabled() bool {
+	return Format == JSON
+}
+
+// Start checks the format and, for json, moves the human output to stderr
+// so that only events are written to stdout
+func Start() error {
+	switch Format {
+	case "", Text:
+		Format = Text
+		return nil
+	case JSON:
+	default:
+		return fmt.Errorf("unknown output format '%s'; use %s or %s", Format, Text, JSON)
+	}
+
+	if stdout == nil {
+		stdout = os.Stdout
+		out = stdout
+		os.Stdout = os.Stderr
+	}
+
+	return nil
+}
+
+// Stop puts stdout back once the command has finished
+func Stop() {
+	if stdout != nil {
+		os.Stdout = stdout
+		stdout = nil
+	}
+}
+
+type line struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data,omitempty"`
+}
+
+// Event writes an event of the given type, if structured output is turned on.
+// data is marshalled as JSON.
+func Event(eventType string, data any) {
+	if !Enabled() {
+		return
+	}
+
+	b, err := json.Marshal(line{eventType, now().UTC(), data})
+	if err != nil {
+		b, _ = json.Marshal(line{Error, now().UTC(), map[string]string{
+			"message": fmt.Sprintf("unable to write %s event: %s", eventType, err),
+		}})
+	}
+
+	// Events can carry parameter values and error messages that include secrets
+	b = []byte(config.Mask(string(b)))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	out.Write(append(b, '\n'))
+}
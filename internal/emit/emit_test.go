@@ -0,0 +1,102 @@
+package emit
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestEvent(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	defer func() { Format = Text; now = time.Now }()
+
+	Format = Text
+	Event("ignored", nil)
+
+	Format = JSON
+	Event("stack_event", map[string]string{"stackName": "app", "status": "CREATE_COMPLETE"})
+	Event(Exit, map[string]int{"code": 0})
+	Event("broken", map[string]any{"f": func() {}})
+
+	expected := `{"type":"stack_event","time":"2024-05-01T12:00:00Z","data":{"stackName":"app","status":"CREATE_COMPLETE"}}
+{"type":"exit","time":"2024-05-01T12:00:00Z","data":{"code":0}}
+{"type":"error","time":"2024-05-01T12:00:00Z","data":{"message":"unable to write broken event: json: unsupported type: func()"}}
+`
+
+	if d := cmp.Diff(expected, buf.String()); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestEventMasksSecrets(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	Format = JSON
+	defer func() { Format = Text; now = time.Now }()
+
+	config.AddSecret("hunter2")
+	Event(Error, map[string]string{"message": "invalid password hunter2"})
+
+	expected := `{"type":"error","time":"2024-05-01T12:00:00Z","data":{"message":"invalid password ****"}}
+`
+
+	if d := cmp.Diff(expected, buf.String()); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestStart(t *testing.T) {
+	defer func() { Format = Text }()
+
+	Format = ""
+	if err := Start(); err != nil || Format != Text || Enabled() {
+		t.Errorf("expected text output, got %q, %v", Format, err)
+	}
+
+	Format = "xml"
+	if err := Start(); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestStack(t *testing.T) {
+	var buf bytes.Buffer
+	out = &buf
+	now = func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }
+	Format = JSON
+	defer func() { Format = Text; now = time.Now }()
+
+	at := time.Date(2024, 5, 1, 11, 59, 0, 0, time.UTC)
+	StackEvent(types.StackEvent{
+		StackName:            ptr.String("app"),
+		LogicalResourceId:    ptr.String("Bucket"),
+		ResourceType:         ptr.String("AWS::S3::Bucket"),
+		ResourceStatus:       types.ResourceStatusCreateFailed,
+		ResourceStatusReason: ptr.String("already exists"),
+		Timestamp:            &at,
+	})
+
+	Stack(types.Stack{
+		StackName:   ptr.String("app"),
+		StackStatus: types.StackStatusCreateComplete,
+		Outputs: []types.Output{
+			{OutputKey: ptr.String("Url"), OutputValue: ptr.String("https://example.com")},
+		},
+	}, nil)
+
+	expected := `{"type":"stack_event","time":"2024-05-01T12:00:00Z","data":{"stackName":"app","logicalResourceId":"Bucket","resourceType":"AWS::S3::Bucket","status":"CREATE_FAILED","reason":"already exists","timestamp":"2024-05-01T11:59:00Z"}}
+{"type":"stack","time":"2024-05-01T12:00:00Z","data":{"stackName":"app","status":"CREATE_COMPLETE","outputs":{"Url":"https://example.com"}}}
+`
+
+	if d := cmp.Diff(expected, buf.String()); d != "" {
+		t.Error(d)
+	}
+}
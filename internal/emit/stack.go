@@ -0,0 +1,79 @@
+package emit
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// Event types for stacks
+const (
+	// StackEventType is an event from a stack's event log
+	StackEventType = "stack_event"
+
+	// StackType is the state of a stack once an operation on it has finished
+	StackType = "stack"
+)
+
+// StackEventData is the data of a stack_event event
+type StackEventData struct {
+	StackName          string    `json:"stackName"`
+	LogicalResourceId  string    `json:"logicalResourceId"`
+	PhysicalResourceId string    `json:"physicalResourceId,omitempty"`
+	ResourceType       string    `json:"resourceType"`
+	Status             string    `json:"status"`
+	Reason             string    `json:"reason,omitempty"`
+	Timestamp          time.Time `json:"timestamp"`
+}
+
+// StackData is the data of a stack event
+type StackData struct {
+	StackName string            `json:"stackName"`
+	StackId   string            `json:"stackId,omitempty"`
+	Region    string            `json:"region,omitempty"`
+	Status    string            `json:"status"`
+	Outputs   map[string]string `json:"outputs,omitempty"`
+	Messages  []string          `json:"messages,omitempty"`
+}
+
+// StackEvent writes an event from a stack's event log
+func StackEvent(e types.StackEvent) {
+	if !Enabled() {
+		return
+	}
+
+	Event(StackEventType, StackEventData{
+		StackName:          ptr.ToString(e.StackName),
+		LogicalResourceId:  ptr.ToString(e.LogicalResourceId),
+		PhysicalResourceId: ptr.ToString(e.PhysicalResourceId),
+		ResourceType:       ptr.ToString(e.ResourceType),
+		Status:             string(e.ResourceStatus),
+		Reason:             ptr.ToString(e.ResourceStatusReason),
+		Timestamp:          ptr.ToTime(e.Timestamp).UTC(),
+	})
+}
+
+// Stack writes the state of a stack once an operation on it has finished,
+// with any messages about resources that failed
+func Stack(stack types.Stack, messages []string) {
+	if !Enabled() {
+		return
+	}
+
+	data := StackData{
+		StackName: ptr.ToString(stack.StackName),
+		StackId:   ptr.ToString(stack.StackId),
+		Status:    string(stack.StackStatus),
+		Messages:  messages,
+	}
+
+	if len(stack.Outputs) > 0 {
+		data.Outputs = make(map[string]string, len(stack.Outputs))
+		for _, o := range stack.Outputs {
+			data.Outputs[ptr.ToString(o.OutputKey)] = ptr.ToString(o.OutputValue)
+		}
+	}
+
+	Event(StackType, data)
+}
@@ -0,0 +1,97 @@
+// Package exitcode defines the exit codes that rain stops with,
+// so that scripts and pipelines can tell what happened without parsing rain's output.
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Exit codes
+const (
+	// Success means the command did what it was asked to
+	Success = 0
+
+	// Usage is a usage error, such as an unknown flag or a missing argument,
+	// and any other error that doesn't have a more specific code
+	Usage = 1
+
+	// Invalid means a template failed validation, linting or a policy check
+	Invalid = 2
+
+	// DeployFailed means a stack operation failed or was rolled back
+	DeployFailed = 3
+
+	// NoChanges means there was nothing to deploy, with --fail-on-empty-changeset
+	NoChanges = 4
+
+	// Timeout means a stack operation took longer than its timeout
+	Timeout = 5
+
+	// Interrupted means rain was stopped with Ctrl-C, following the shell's convention of 128 + SIGINT
+	Interrupted = 130
+)
+
+// Help describes the exit codes, for commands' help text
+const Help = `Exit codes:
+  0    success
+  1    usage error, or any other error
+  2    validation, lint or policy failure
+  3    deployment failed
+  4    no changes to deploy, with --fail-on-empty-changeset
+  5    timed out
+  130  interrupted`
+
+// Error is an error that stops rain with a particular exit code
+type Error struct {
+	Code int
+
+	// Err is shown to the user; it is nil if the command has already reported what happened
+	Err error
+}
+
+func (e Error) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit code %d", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+func (e Error) Unwrap() error {
+	return e.Err
+}
+
+// Wrap returns err with the code that rain should stop with if it panics with it
+func Wrap(code int, err error) error {
+	return Error{Code: code, Err: err}
+}
+
+// Exit stops the command with code, for commands that have already shown why.
+// Unlike os.Exit, deferred calls are run.
+func Exit(code int) {
+	panic(Error{Code: code})
+}
+
+// Of returns the code that rain should stop with for the value it panicked with
+func Of(r interface{}) int {
+	if err, ok := r.(error); ok {
+		var e Error
+		if errors.As(err, &e) {
+			return e.Code
+		}
+
+		if errors.Is(err, context.Canceled) {
+			return Interrupted
+		}
+	}
+
+	return Usage
+}
+
+// IsSilent returns true if r is from Exit and has nothing to show the user
+func IsSilent(r interface{}) bool {
+	e, ok := r.(Error)
+	return ok && e.Err == nil
+}
@@ -0,0 +1,52 @@
+package exitcode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestOf(t *testing.T) {
+	cases := []struct {
+		r        interface{}
+		expected int
+	}{
+		{"something went wrong", Usage},
+		{errors.New("something went wrong"), Usage},
+		{Wrap(DeployFailed, errors.New("failed deploying stack 'app'")), DeployFailed},
+		{fmt.Errorf("manifest: %w", Wrap(Timeout, errors.New("timed out"))), Timeout},
+		{Error{Code: Invalid}, Invalid},
+		{fmt.Errorf("unable to get stack 'app': %w", context.Canceled), Interrupted},
+	}
+
+	for _, c := range cases {
+		if code := Of(c.r); code != c.expected {
+			t.Errorf("%v: expected %d, got %d", c.r, c.expected, code)
+		}
+	}
+}
+
+func TestExit(t *testing.T) {
+	defer func() {
+		r := recover()
+		if !IsSilent(r) || Of(r) != NoChanges {
+			t.Errorf("expected a silent exit with code %d, got %v", NoChanges, r)
+		}
+	}()
+
+	Exit(NoChanges)
+}
+
+func TestWrap(t *testing.T) {
+	err := errors.New("failed deploying stack 'app'")
+	wrapped := Wrap(DeployFailed, err)
+
+	if IsSilent(wrapped) {
+		t.Error("a wrapped error should be shown")
+	}
+
+	if wrapped.Error() != err.Error() || !errors.Is(wrapped, err) {
+		t.Errorf("expected %q, got %q", err, wrapped)
+	}
+}
@@ -0,0 +1,169 @@
+// Package gitinfo reads the state of the git repository that a template is in,
+// so that deployed stacks can be traced back to the source they came from.
+package gitinfo
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/node"
+	"gopkg.in/yaml.v3"
+)
+
+// Keys of the stack tags that Tags returns
+const (
+	CommitTag = "rain:GitCommit"
+	BranchTag = "rain:GitBranch"
+	DirtyTag  = "rain:GitDirty"
+	RemoteTag = "rain:GitRemote"
+)
+
+// MetadataKey is the key in the template's Metadata section that AddMetadata sets
+const MetadataKey = "Rain::Git"
+
+// Info is the state of a git repository
+type Info struct {
+	// Commit is the SHA of the commit that is checked out
+	Commit string
+
+	// Branch is the branch that is checked out, or "" if HEAD is detached
+	Branch string
+
+	// Dirty is set if there are uncommitted changes
+	Dirty bool
+
+	// Remote is the URL of the origin remote, without any credentials, or "" if there isn't one
+	Remote string
+}
+
+// ErrNotRepository is returned by Read if dir is not in a git repository
+var ErrNotRepository = errors.New("not in a git repository")
+
+// Read returns the state of the git repository that contains dir
+func Read(dir string) (Info, error) {
+	var info Info
+
+	commit, err := git(dir, "rev-parse", "HEAD")
+	if err != nil {
+		if _, err := git(dir, "rev-parse", "--is-inside-work-tree"); err != nil {
+			return info, ErrNotRepository
+		}
+		return info, err
+	}
+	info.Commit = commit
+
+	// symbolic-ref fails when HEAD is detached, as it is in many CI systems
+	if branch, err := git(dir, "symbolic-ref", "--short", "-q", "HEAD"); err == nil {
+		info.Branch = branch
+	}
+
+	status, err := git(dir, "status", "--porcelain")
+	if err != nil {
+		return info, err
+	}
+	info.Dirty = status != ""
+
+	if remote, err := git(dir, "remote", "get-url", "origin"); err == nil {
+		info.Remote = StripCredentials(remote)
+	}
+
+	return info, nil
+}
+
+// StripCredentials removes any user name and password from a remote URL,
+// such as a token that a CI system adds to an https URL
+func StripCredentials(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.Scheme == "" || u.User == nil {
+		return remote
+	}
+
+	// Keep the conventional git user of ssh URLs
+	if u.Scheme == "ssh" {
+		if _, hasPassword := u.User.Password(); !hasPassword {
+			return remote
+		}
+	}
+
+	u.User = nil
+	return u.String()
+}
+
+// Tags returns stack tags that record the repository's state
+func (i Info) Tags() map[string]string {
+	tags := map[string]string{
+		CommitTag: i.Commit,
+		DirtyTag:  strconv.FormatBool(i.Dirty),
+	}
+
+	if i.Branch != "" {
+		tags[BranchTag] = i.Branch
+	}
+
+	if i.Remote != "" {
+		tags[RemoteTag] = i.Remote
+	}
+
+	return tags
+}
+
+// AddMetadata records the repository's state in the template's Metadata section,
+// replacing anything that is already recorded there
+func (i Info) AddMetadata(t cft.Template) error {
+	metadata, err := t.GetSection(cft.Metadata)
+	if err != nil {
+		metadata, err = t.AddMapSection(cft.Metadata)
+		if err != nil {
+			return err
+		}
+	}
+
+	if metadata.Kind != yaml.MappingNode {
+		return fmt.Errorf("the template's %s section is not a mapping", cft.Metadata)
+	}
+
+	m := &yaml.Node{Kind: yaml.MappingNode}
+	node.Add(m, "Commit", i.Commit)
+	if i.Branch != "" {
+		node.Add(m, "Branch", i.Branch)
+	}
+	m.Content = append(m.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Value: "Dirty"},
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: strconv.FormatBool(i.Dirty)})
+	if i.Remote != "" {
+		node.Add(m, "Remote", i.Remote)
+	}
+
+	node.SetMapValue(metadata, MetadataKey, m)
+
+	return nil
+}
+
+// git runs git in dir and returns its trimmed standard output
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	config.Debugf("Running git %s", strings.Join(args, " "))
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,295 @@
+// Package gitmodule fetches rain modules from git repositories,
+// so that teams can share a library of modules.
+//
+// A module in a git repository is referenced with a source like:
+//
+//	git::https://github.com/example/modules.git//bucket/module.yaml?ref=v1.2.0
+//
+// The part after // is the path of the module in the repository,
+// and ref is a tag, branch or commit. Without a ref, the default branch is used.
+//
+// The commit that each ref resolves to is recorded in a lock file (rain.lock)
+// in the current directory, so that later builds use the same commits even if
+// a tag or branch moves. Delete an entry, or set Update, to resolve a ref again.
+// Checked out commits are cached on disk, so a commit is only fetched once.
+package gitmodule
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+)
+
+// Prefix marks a module source as a git repository
+const Prefix = "git::"
+
+// LockFileName is the name of the lock file
+const LockFileName = "rain.lock"
+
+// LockFilePath is the path of the lock file
+var LockFilePath = LockFileName
+
+// CacheDir is where checked out commits are kept.
+// If it is empty, a directory in the user's cache directory is used.
+var CacheDir = ""
+
+// Update resolves every ref again, ignoring the commits in the lock file
+var Update = false
+
+var commitRe = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// Source is a module in a git repository
+type Source struct {
+	// Repo is the URL of the repository
+	Repo string
+
+	// Path is the path of the module file within the repository
+	Path string
+
+	// Ref is the tag, branch or commit to use, or "" for the default branch
+	Ref string
+}
+
+// IsSource returns true if uri refers to a module in a git repository
+func IsSource(uri string) bool {
+	return strings.HasPrefix(uri, Prefix)
+}
+
+// ParseSource parses a git module source
+func ParseSource(uri string) (Source, error) {
+	if !IsSource(uri) {
+		return Source{}, fmt.Errorf("'%s' is not a git module source; it should start with %s", uri, Prefix)
+	}
+
+	rest := strings.TrimPrefix(uri, Prefix)
+
+	var s Source
+
+	if i := strings.LastIndex(rest, "?"); i >= 0 {
+		query, err := url.ParseQuery(rest[i+1:])
+		if err != nil {
+			return Source{}, fmt.Errorf("invalid git module source '%s': %w", uri, err)
+		}
+		s.Ref = query.Get("ref")
+		rest = rest[:i]
+	}
+
+	// Skip the :// of the scheme when looking for the path separator
+	start := 0
+	if i := strings.Index(rest, "://"); i >= 0 {
+		start = i + len("://")
+	}
+
+	i := strings.Index(rest[start:], "//")
+	if i < 0 {
+		return Source{}, fmt.Errorf("invalid git module source '%s': separate the repository and the module's path with //", uri)
+	}
+
+	s.Repo = rest[:start+i]
+	s.Path = path.Clean(rest[start+i+2:])
+
+	if s.Repo == "" || s.Path == "." || s.Path == ".." || strings.HasPrefix(s.Path, "../") {
+		return Source{}, fmt.Errorf("invalid git module source '%s'", uri)
+	}
+
+	// git would read a repository or ref that starts with - as an option
+	if strings.HasPrefix(s.Repo, "-") || strings.HasPrefix(s.Ref, "-") {
+		return Source{}, fmt.Errorf("invalid git module source '%s': the repository and ref can't start with -", uri)
+	}
+
+	return s, nil
+}
+
+// git runs git and returns its trimmed standard output
+func git(dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	config.Debugf("Running git %s", strings.Join(args, " "))
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return "", fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// resolve returns the commit that ref points to in the repository
+func resolve(repo, ref string) (string, error) {
+	pattern := ref
+	if pattern == "" {
+		pattern = "HEAD"
+	}
+
+	out, err := git("", "ls-remote", "--", repo, pattern, pattern+"^{}")
+	if err != nil {
+		return "", err
+	}
+
+	// Prefer the commit that an annotated tag points to, then tags, then branches
+	var commit string
+	rank := 0
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		r := 1
+		switch {
+		case strings.HasSuffix(fields[1], "^{}"):
+			r = 4
+		case strings.HasPrefix(fields[1], "refs/tags/"):
+			r = 3
+		case strings.HasPrefix(fields[1], "refs/heads/") || fields[1] == "HEAD":
+			r = 2
+		}
+
+		if r > rank {
+			commit, rank = fields[0], r
+		}
+	}
+
+	if commit == "" {
+		return "", fmt.Errorf("ref '%s' was not found in %s", ref, repo)
+	}
+
+	return commit, nil
+}
+
+// cacheRoot returns the directory that all checkouts are kept in
+func cacheRoot() (string, error) {
+	if CacheDir != "" {
+		return CacheDir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "rain", "modules"), nil
+}
+
+// cacheDir returns the directory that the commit of the repository is checked out in
+func cacheDir(repo, commit string) (string, error) {
+	root, err := cacheRoot()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(root, fmt.Sprintf("%x", sha256.Sum256([]byte(repo)))[:16], commit), nil
+}
+
+// InCache returns true if path is in a module that was fetched from a git repository
+func InCache(path string) bool {
+	root, err := cacheRoot()
+	if err != nil {
+		return false
+	}
+
+	rel, err := filepath.Rel(root, path)
+
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// checkout makes sure that the commit of the repository is in the cache,
+// and returns the directory it is checked out in
+func checkout(repo, commit string) (string, error) {
+	dir, err := cacheDir(repo, commit)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(dir); err == nil {
+		return dir, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return "", err
+	}
+
+	// Check out into a temporary directory first, so that an
+	// interrupted checkout doesn't leave a broken cache entry behind
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), commit+"-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmp)
+
+	if _, err := git(tmp, "clone", "--quiet", "--no-checkout", "--", repo, "."); err != nil {
+		return "", err
+	}
+
+	if _, err := git(tmp, "checkout", "--quiet", commit); err != nil {
+		return "", err
+	}
+
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// Fetch returns the local path of the module that uri refers to,
+// fetching it if it is not in the cache, and recording the commit in the lock file.
+// Relative paths in the module can be resolved from the directory the module is in.
+func Fetch(uri string) (string, error) {
+	s, err := ParseSource(uri)
+	if err != nil {
+		return "", err
+	}
+
+	// A commit can't move, so it doesn't need to be locked
+	commit := s.Ref
+	if !commitRe.MatchString(s.Ref) {
+		lock, err := LoadLockFile(LockFilePath)
+		if err != nil {
+			return "", err
+		}
+
+		var locked bool
+		commit, locked = lock.Get(s.Repo, s.Ref)
+
+		if !locked || Update {
+			commit, err = resolve(s.Repo, s.Ref)
+			if err != nil {
+				return "", err
+			}
+
+			if lock.Set(s.Repo, s.Ref, commit) {
+				if err := lock.Save(LockFilePath); err != nil {
+					return "", err
+				}
+			}
+		}
+	}
+
+	config.Debugf("Using commit %s of %s for ref '%s'", commit, s.Repo, s.Ref)
+
+	dir, err := checkout(s.Repo, commit)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, filepath.FromSlash(s.Path)), nil
+}
@@ -0,0 +1,164 @@
+package gitmodule
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseSource(t *testing.T) {
+	cases := map[string]Source{
+		"git::https://github.com/example/modules.git//bucket/module.yaml?ref=v1.2.0": {
+			Repo: "https://github.com/example/modules.git",
+			Path: "bucket/module.yaml",
+			Ref:  "v1.2.0",
+		},
+		"git::git@github.com:example/modules.git//module.yaml": {
+			Repo: "git@github.com:example/modules.git",
+			Path: "module.yaml",
+		},
+		"git::file:///tmp/modules//a/b/module.yaml?ref=main": {
+			Repo: "file:///tmp/modules",
+			Path: "a/b/module.yaml",
+			Ref:  "main",
+		},
+	}
+
+	for uri, expected := range cases {
+		actual, err := ParseSource(uri)
+		if err != nil {
+			t.Errorf("%s: %v", uri, err)
+			continue
+		}
+
+		if actual != expected {
+			t.Errorf("%s: expected %+v, got %+v", uri, expected, actual)
+		}
+	}
+
+	for _, uri := range []string{
+		"https://github.com/example/modules.git//module.yaml",
+		"git::https://github.com/example/modules.git",
+		"git::https://github.com/example/modules.git//",
+		"git::https://github.com/example/modules.git//../module.yaml",
+		"git::https://github.com/example/modules.git//..",
+		"git::--upload-pack=touch /tmp/pwned//module.yaml",
+		"git::https://github.com/example/modules.git//module.yaml?ref=--upload-pack=touch",
+	} {
+		if _, err := ParseSource(uri); err == nil {
+			t.Errorf("%s: expected an error", uri)
+		}
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LockFileName)
+
+	l, err := LoadLockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !l.Set("b", "v1", "2222") || !l.Set("a", "v1", "1111") {
+		t.Error("expected new locks to change the lock file")
+	}
+
+	if l.Set("a", "v1", "1111") {
+		t.Error("expected an unchanged lock not to change the lock file")
+	}
+
+	if err := l.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err = LoadLockFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if l.Modules[0].Repo != "a" {
+		t.Errorf("expected the locks to be sorted, got %+v", l.Modules)
+	}
+
+	if commit, ok := l.Get("b", "v1"); !ok || commit != "2222" {
+		t.Errorf("expected 2222, got %s", commit)
+	}
+
+	if _, ok := l.Get("b", "v2"); ok {
+		t.Error("expected v2 not to be locked")
+	}
+}
+
+func TestFetch(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	repo := t.TempDir()
+
+	run := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)
+		if _, err := git(repo, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(repo, "module.yaml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run("init", "--quiet")
+	write("v1")
+	run("add", ".")
+	run("commit", "--quiet", "-m", "v1")
+	run("tag", "-a", "v1", "-m", "v1")
+
+	CacheDir = t.TempDir()
+	LockFilePath = filepath.Join(t.TempDir(), LockFileName)
+	defer func() {
+		CacheDir = ""
+		LockFilePath = LockFileName
+		Update = false
+	}()
+
+	uri := "git::file://" + filepath.ToSlash(repo) + "//module.yaml?ref=v1"
+
+	check := func(expected string) {
+		t.Helper()
+
+		path, err := Fetch(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !InCache(path) {
+			t.Errorf("expected %s to be in the cache", path)
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(content) != expected {
+			t.Errorf("expected %s, got %s", expected, content)
+		}
+	}
+
+	check("v1")
+
+	// Move the tag; the lock file keeps the original commit
+	write("v2")
+	run("commit", "--quiet", "-am", "v2")
+	run("tag", "-f", "-a", "v1", "-m", "v2")
+
+	check("v1")
+
+	Update = true
+	check("v2")
+}
@@ -0,0 +1,88 @@
+package gitmodule
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Lock records the commit that a ref resolved to
+type Lock struct {
+	Repo   string `yaml:"Repo"`
+	Ref    string `yaml:"Ref,omitempty"`
+	Commit string `yaml:"Commit"`
+}
+
+// LockFile is the contents of rain.lock
+type LockFile struct {
+	Modules []Lock `yaml:"Modules"`
+}
+
+// LoadLockFile reads the lock file at path.
+// It returns an empty lock file if there isn't one.
+func LoadLockFile(path string) (*LockFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &LockFile{}, nil
+		}
+		return nil, err
+	}
+
+	var l LockFile
+	if err := yaml.Unmarshal(content, &l); err != nil {
+		return nil, fmt.Errorf("unable to parse lock file '%s': %w", path, err)
+	}
+
+	return &l, nil
+}
+
+// Get returns the commit that the ref of the repository is locked to
+func (l *LockFile) Get(repo, ref string) (string, bool) {
+	for _, m := range l.Modules {
+		if m.Repo == repo && m.Ref == ref {
+			return m.Commit, true
+		}
+	}
+
+	return "", false
+}
+
+// Set locks the ref of the repository to the commit,
+// and returns false if it was already locked to it
+func (l *LockFile) Set(repo, ref, commit string) bool {
+	for i, m := range l.Modules {
+		if m.Repo == repo && m.Ref == ref {
+			if m.Commit == commit {
+				return false
+			}
+			l.Modules[i].Commit = commit
+			return true
+		}
+	}
+
+	l.Modules = append(l.Modules, Lock{Repo: repo, Ref: ref, Commit: commit})
+
+	return true
+}
+
+// Save writes the lock file to path, sorted so that it diffs well
+func (l *LockFile) Save(path string) error {
+	sort.Slice(l.Modules, func(i, j int) bool {
+		if l.Modules[i].Repo != l.Modules[j].Repo {
+			return l.Modules[i].Repo < l.Modules[j].Repo
+		}
+		return l.Modules[i].Ref < l.Modules[j].Ref
+	})
+
+	content, err := yaml.Marshal(l)
+	if err != nil {
+		return err
+	}
+
+	header := "# Commits of the git modules used by templates; written by rain\n"
+
+	return os.WriteFile(path, append([]byte(header), content...), 0644)
+}
@@ -0,0 +1,211 @@
+// Package hooks runs the shell commands that a deploy config file sets to run
+// before and after a stack is deployed, such as smoke tests and notifications.
+//
+// An example config file:
+//
+//	Parameters:
+//	  Environment: prod
+//	Hooks:
+//	  pre_package: make build
+//	  pre_deploy:
+//	    - ./scripts/check-quota.sh
+//	  post_deploy:
+//	    - curl -fsS "$RAIN_OUTPUT_ApiUrl/health"
+//	  on_failure: ./scripts/notify.sh "$RAIN_STACK_NAME failed: $RAIN_ERROR"
+//
+// Each hook is a command or a list of commands, which run in order until one fails.
+// Commands are run by sh, or by cmd on Windows, in the current directory.
+package hooks
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"gopkg.in/yaml.v3"
+)
+
+// Hook names
+const (
+	// PrePackage runs before the template is packaged
+	PrePackage = "pre_package"
+
+	// PreDeploy runs once the change set has been created, before it is executed
+	PreDeploy = "pre_deploy"
+
+	// PostDeploy runs once the stack has been deployed successfully
+	PostDeploy = "post_deploy"
+
+	// OnFailure runs if the deployment fails, including if another hook fails
+	OnFailure = "on_failure"
+)
+
+var names = []string{PrePackage, PreDeploy, PostDeploy, OnFailure}
+
+// Hooks maps hook names to the commands they run
+type Hooks map[string][]string
+
+// Env is what a hook's commands are told about the stack, as environment variables
+type Env struct {
+	// StackName is set as RAIN_STACK_NAME
+	StackName string
+
+	// Region is set as RAIN_REGION
+	Region string
+
+	// Status is the stack's status, if it exists, set as RAIN_STACK_STATUS
+	Status string
+
+	// Outputs are the stack's outputs, each set as RAIN_OUTPUT_<key>
+	Outputs map[string]string
+
+	// Error is why the deployment failed, set as RAIN_ERROR for on_failure hooks
+	Error string
+}
+
+// Commands is a hook that is written as either a single command or a list of them
+type Commands []string
+
+func (c *Commands) UnmarshalYAML(n *yaml.Node) error {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		*c = []string{n.Value}
+		return nil
+	case yaml.SequenceNode:
+		var list []string
+		if err := n.Decode(&list); err != nil {
+			return err
+		}
+		*c = list
+		return nil
+	}
+
+	return fmt.Errorf("line %d: a hook must be a command or a list of commands", n.Line)
+}
+
+// Load reads the Hooks section of a deploy config file.
+// It returns no hooks if the file doesn't have a Hooks section.
+func Load(path string) (Hooks, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	h, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hooks in '%s': %w", path, err)
+	}
+
+	return h, nil
+}
+
+// Parse reads the Hooks section of a deploy config file in YAML or JSON
+func Parse(content []byte) (Hooks, error) {
+	var file struct {
+		Hooks map[string]Commands `yaml:"Hooks"`
+	}
+
+	// Config files that only list parameters, in the aws cli's formats, have no Hooks
+	if dc.IsParametersOnly(content) {
+		return nil, nil
+	}
+
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+
+	h := make(Hooks, len(file.Hooks))
+	for name, c := range file.Hooks {
+		if !contains(names, name) {
+			return nil, fmt.Errorf("unknown hook '%s'; use one of %s", name, strings.Join(names, ", "))
+		}
+
+		h[name] = c
+	}
+
+	return h, nil
+}
+
+// Has returns true if the hook has any commands to run
+func (h Hooks) Has(name string) bool {
+	return len(h[name]) > 0
+}
+
+// Run runs the hook's commands in order, stopping at the first that fails.
+// Their output goes to rain's own stdout and stderr.
+func (h Hooks) Run(name string, env Env) error {
+	for _, command := range h[name] {
+		fmt.Printf("Running %s hook: %s\n", name, command)
+		config.Debugf("Hook environment: %v", env.Vars(name))
+
+		cmd := shell(command)
+		cmd.Env = append(os.Environ(), env.Vars(name)...)
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				return fmt.Errorf("%s hook '%s' failed with exit code %d", name, command, exitErr.ExitCode())
+			}
+			return fmt.Errorf("%s hook '%s' failed: %w", name, command, err)
+		}
+	}
+
+	return nil
+}
+
+// Vars returns the environment variables that are set for the hook's commands,
+// with outputs sorted by key
+func (e Env) Vars(name string) []string {
+	vars := []string{
+		"RAIN_HOOK=" + name,
+		"RAIN_STACK_NAME=" + e.StackName,
+		"RAIN_REGION=" + e.Region,
+	}
+
+	if e.Status != "" {
+		vars = append(vars, "RAIN_STACK_STATUS="+e.Status)
+	}
+
+	if e.Error != "" {
+		vars = append(vars, "RAIN_ERROR="+e.Error)
+	}
+
+	keys := make([]string, 0, len(e.Outputs))
+	for k := range e.Outputs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		vars = append(vars, "RAIN_OUTPUT_"+k+"="+e.Outputs[k])
+	}
+
+	return vars
+}
+
+// shell returns a command that runs command in the platform's shell
+func shell(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+
+	return exec.Command("sh", "-c", command)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	h, err := Parse([]byte(`
+Parameters:
+  Environment: prod
+Hooks:
+  pre_package: make build
+  post_deploy:
+    - ./smoke-test.sh
+    - echo done
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Hooks{
+		PrePackage: {"make build"},
+		PostDeploy: {"./smoke-test.sh", "echo done"},
+	}
+
+	if d := cmp.Diff(expected, h); d != "" {
+		t.Error(d)
+	}
+
+	if !h.Has(PostDeploy) || h.Has(OnFailure) {
+		t.Errorf("unexpected hooks: %v", h)
+	}
+
+	h, err = Parse([]byte(`{"Parameters": {"A": "b"}}`))
+	if err != nil || len(h) != 0 {
+		t.Errorf("expected no hooks, got %v, %v", h, err)
+	}
+
+	if _, err := Parse([]byte("Hooks:\n  post_destroy: echo\n")); err == nil {
+		t.Error("expected an error for an unknown hook")
+	}
+
+	if _, err := Parse([]byte("Hooks:\n  pre_deploy:\n    cmd: echo\n")); err == nil {
+		t.Error("expected an error for a hook that is a mapping")
+	}
+}
+
+func TestVars(t *testing.T) {
+	env := Env{
+		StackName: "app",
+		Region:    "us-east-1",
+		Status:    "UPDATE_ROLLBACK_COMPLETE",
+		Outputs:   map[string]string{"Url": "https://example.com", "Bucket": "b"},
+		Error:     "failed deploying stack 'app'",
+	}
+
+	expected := []string{
+		"RAIN_HOOK=on_failure",
+		"RAIN_STACK_NAME=app",
+		"RAIN_REGION=us-east-1",
+		"RAIN_STACK_STATUS=UPDATE_ROLLBACK_COMPLETE",
+		"RAIN_ERROR=failed deploying stack 'app'",
+		"RAIN_OUTPUT_Bucket=b",
+		"RAIN_OUTPUT_Url=https://example.com",
+	}
+
+	if d := cmp.Diff(expected, env.Vars(OnFailure)); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the commands use sh")
+	}
+
+	out := filepath.Join(t.TempDir(), "out")
+
+	h := Hooks{
+		PostDeploy: {
+			`echo "$RAIN_HOOK $RAIN_STACK_NAME $RAIN_OUTPUT_Url" > ` + out,
+			"exit 3",
+			"echo not reached >> " + out,
+		},
+	}
+
+	err := h.Run(PostDeploy, Env{StackName: "app", Outputs: map[string]string{"Url": "https://example.com"}})
+	if err == nil || !strings.Contains(err.Error(), "exit code 3") {
+		t.Errorf("expected the second command to fail, got %v", err)
+	}
+
+	content, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "post_deploy app https://example.com\n" {
+		t.Errorf("unexpected output: %q", content)
+	}
+
+	if err := h.Run(PreDeploy, Env{}); err != nil {
+		t.Errorf("a hook with no commands should do nothing: %v", err)
+	}
+}
@@ -0,0 +1,144 @@
+// Package interrupt lets rain stop cleanly when it is interrupted with Ctrl-C.
+//
+// The first interrupt cancels Context, which aborts AWS calls and waits that are in flight,
+// so that the command can tidy up and decide what to do about operations that it started.
+// A second interrupt runs the cleanup function given to Start and exits straight away.
+package interrupt
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+)
+
+var (
+	mu          sync.Mutex
+	ctx         = context.Background()
+	cancel      = context.CancelFunc(func() {})
+	interrupted bool
+	handling    bool
+
+	// exit is replaced in tests
+	exit = os.Exit
+)
+
+// Context returns the context for AWS calls and waits, which is cancelled when rain is interrupted
+func Context() context.Context {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return ctx
+}
+
+// Interrupted returns true if rain has been interrupted since Start or the last Reset
+func Interrupted() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return interrupted
+}
+
+// Handling returns true if interrupts are being handled by this package,
+// rather than ending rain as usual
+func Handling() bool {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return handling
+}
+
+// Reset replaces a cancelled context with a fresh one, so that rain can make
+// the AWS calls that deal with the interruption, such as cancelling a stack update.
+// The next interrupt cancels the new context.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if interrupted {
+		ctx, cancel = context.WithCancel(context.Background())
+		interrupted = false
+	}
+}
+
+// Start handles interrupts until stop is called.
+// notify is called when rain is first interrupted, and cleanup before rain exits on a second interrupt.
+func Start(notify func(), cleanup func()) (stop func()) {
+	mu.Lock()
+	ctx, cancel = context.WithCancel(context.Background())
+	interrupted = false
+	handling = true
+	mu.Unlock()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-signals:
+				handle(notify, cleanup)
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+		<-finished
+
+		mu.Lock()
+		handling = false
+		mu.Unlock()
+	}
+}
+
+// handle deals with a single interrupt
+func handle(notify func(), cleanup func()) {
+	mu.Lock()
+	again := interrupted
+	interrupted = true
+	cancel()
+	mu.Unlock()
+
+	if !again {
+		if notify != nil {
+			notify()
+		}
+		return
+	}
+
+	if cleanup != nil {
+		cleanup()
+	}
+	exit(exitcode.Interrupted)
+}
+
+// Sleep pauses for d, or until rain is interrupted, in which case it returns the context's error
+func Sleep(d time.Duration) error {
+	return SleepContext(Context(), d)
+}
+
+// SleepContext is Sleep for callers that have their own context, which stops the wait if it is cancelled
+func SleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
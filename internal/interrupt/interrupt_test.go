@@ -0,0 +1,92 @@
+package interrupt
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/exitcode"
+)
+
+func TestHandle(t *testing.T) {
+	code := -1
+	exit = func(c int) { code = c }
+	defer func() { exit = os.Exit }()
+
+	stop := Start(nil, nil)
+	defer stop()
+
+	if !Handling() {
+		t.Fatal("expected interrupts to be handled")
+	}
+
+	notified := 0
+	cleaned := 0
+
+	handle(func() { notified++ }, func() { cleaned++ })
+
+	if !Interrupted() {
+		t.Error("expected rain to be interrupted")
+	}
+
+	if !errors.Is(Context().Err(), context.Canceled) {
+		t.Errorf("expected the context to be cancelled, got %v", Context().Err())
+	}
+
+	if notified != 1 || cleaned != 0 || code != -1 {
+		t.Errorf("the first interrupt should only notify: notified %d, cleaned %d, code %d", notified, cleaned, code)
+	}
+
+	handle(func() { notified++ }, func() { cleaned++ })
+
+	if notified != 1 || cleaned != 1 || code != exitcode.Interrupted {
+		t.Errorf("the second interrupt should clean up and exit: notified %d, cleaned %d, code %d", notified, cleaned, code)
+	}
+}
+
+func TestReset(t *testing.T) {
+	stop := Start(nil, nil)
+	defer stop()
+
+	handle(nil, nil)
+	Reset()
+
+	if Interrupted() {
+		t.Error("expected Reset to clear the interruption")
+	}
+
+	if err := Context().Err(); err != nil {
+		t.Errorf("expected a fresh context, got %v", err)
+	}
+}
+
+func TestSleep(t *testing.T) {
+	stop := Start(nil, nil)
+	defer stop()
+
+	if err := Sleep(time.Millisecond); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	go handle(nil, nil)
+
+	start := time.Now()
+	if err := Sleep(time.Minute); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the sleep to be cancelled, got %v", err)
+	}
+
+	if time.Since(start) > 10*time.Second {
+		t.Error("the sleep was not cut short")
+	}
+}
+
+func TestStop(t *testing.T) {
+	stop := Start(nil, nil)
+	stop()
+
+	if Handling() {
+		t.Error("expected interrupts not to be handled after stop")
+	}
+}
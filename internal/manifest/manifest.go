@@ -0,0 +1,391 @@
+// Package manifest reads rain deployment manifests (rain.yaml),
+// which describe a set of stacks that are deployed together
+// along with the dependencies between them.
+//
+// An example manifest:
+//
+//	Stacks:
+//	  - Name: network
+//	    Template: network.yaml
+//	    Config: network-config.yaml
+//	  - Name: app
+//	    Template: app.yaml
+//	    DependsOn:
+//	      - network
+//	    RoleArn: arn:aws:iam::123456789012:role/deploy
+//	    TerminationProtection: true
+//	    StackPolicy: app-policy.json
+//	    TimeoutInMinutes: 30
+//	Exports:
+//	  NameTemplate: ${StackName}:${OutputName}
+//	Features:
+//	  Directives:
+//	    - Rain::Embed
+//	LintRules:
+//	  - lint-rules/
+//	TagPolicy: tag-policy.yaml
+//
+// See package features for the Features section.
+package manifest
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/aws-cloudformation/rain/cft/exports"
+	"github.com/aws-cloudformation/rain/cft/features"
+	cftpkg "github.com/aws-cloudformation/rain/cft/pkg"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFileName is the name rain looks for when a manifest is not specified
+const DefaultFileName = "rain.yaml"
+
+// Stack is a single stack within a manifest
+type Stack struct {
+	// Name is the name of the CloudFormation stack
+	Name string `yaml:"Name"`
+
+	// Template is the path to the stack's template, relative to the manifest
+	Template string `yaml:"Template"`
+
+	// Config is the optional path to a deploy config file, relative to the manifest
+	Config string `yaml:"Config,omitempty"`
+
+	// DependsOn lists the names of stacks that must be deployed before this one
+	DependsOn []string `yaml:"DependsOn,omitempty"`
+
+	// Capabilities are acknowledged when the stack is deployed.
+	// If they are not set, rain acknowledges CAPABILITY_NAMED_IAM and CAPABILITY_AUTO_EXPAND.
+	Capabilities []string `yaml:"Capabilities,omitempty"`
+
+	// RoleArn is the IAM role that CloudFormation assumes to deploy the stack
+	RoleArn string `yaml:"RoleArn,omitempty"`
+
+	// NotificationArns are the SNS topics that the stack's events are sent to
+	NotificationArns []string `yaml:"NotificationArns,omitempty"`
+
+	// TerminationProtection is enabled on the stack once it has been deployed
+	TerminationProtection bool `yaml:"TerminationProtection,omitempty"`
+
+	// StackPolicy is the path to a JSON stack policy document, relative to the manifest,
+	// that is set on the stack once it has been deployed
+	StackPolicy string `yaml:"StackPolicy,omitempty"`
+
+	// TimeoutInMinutes is how long the stack can take to deploy before rain stops it
+	TimeoutInMinutes int `yaml:"TimeoutInMinutes,omitempty"`
+}
+
+// capabilities are the values CloudFormation accepts for Capabilities
+var capabilities = map[string]bool{
+	"CAPABILITY_IAM":         true,
+	"CAPABILITY_NAMED_IAM":   true,
+	"CAPABILITY_AUTO_EXPAND": true,
+}
+
+// validate checks the stack's settings
+func (s Stack) validate() error {
+	for _, c := range s.Capabilities {
+		if !capabilities[c] {
+			return fmt.Errorf("stack '%s' has unknown capability '%s'", s.Name, c)
+		}
+	}
+
+	if s.TimeoutInMinutes < 0 {
+		return fmt.Errorf("stack '%s' has a negative TimeoutInMinutes", s.Name)
+	}
+
+	return nil
+}
+
+// Manifest is the parsed contents of a rain.yaml file
+type Manifest struct {
+	Stacks []Stack `yaml:"Stacks"`
+
+	// Exports is the naming convention for exported outputs.
+	// Exports without a Name are named according to the convention
+	// when the stacks are deployed.
+	Exports *exports.Convention `yaml:"Exports,omitempty"`
+
+	// Features pins the rain features the project's templates can use
+	Features *features.Features `yaml:"Features,omitempty"`
+
+	// LintRules are files or directories of custom rules for rain lint
+	LintRules []string `yaml:"LintRules,omitempty"`
+
+	// TagPolicy is the path to a tagging policy file that the stacks' tags are checked against
+	TagPolicy string `yaml:"TagPolicy,omitempty"`
+
+	// Dir is the directory containing the manifest,
+	// used to resolve relative paths
+	Dir string `yaml:"-"`
+}
+
+// Load reads and validates the manifest at path
+func Load(path string) (*Manifest, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse manifest '%s': %w", path, err)
+	}
+
+	m.Dir = filepath.Dir(path)
+
+	return m, nil
+}
+
+// LoadFeatures reads the Features of the manifest at path.
+// Unlike Load, it does not require the manifest to list any stacks,
+// so that a project can pin its features without using rain deploy --manifest.
+// It returns nil if the manifest does not set any features.
+func LoadFeatures(path string) (*features.Features, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest '%s': %w", path, err)
+	}
+
+	// Local modules are relative to the manifest
+	if m.Features != nil {
+		m.Features.Dir = filepath.Dir(path)
+	}
+
+	return m.Features, nil
+}
+
+// LoadExports reads the export naming convention of the manifest at path.
+// Like LoadFeatures, it does not validate the rest of the manifest.
+// It returns nil if the manifest does not set a convention.
+func LoadExports(path string) (*exports.Convention, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest '%s': %w", path, err)
+	}
+
+	return m.Exports, nil
+}
+
+var (
+	projectOnce sync.Once
+	projectErr  error
+)
+
+// UseProject applies the Features and Exports of the rain.yaml in the current directory,
+// if there is one, to the templates that are packaged from now on.
+// Commands call it before they package a template rather than at startup,
+// so that a rain.yaml they don't need can't break them. It only reads the file once.
+func UseProject() error {
+	projectOnce.Do(func() {
+		if _, err := os.Stat(DefaultFileName); err != nil {
+			return
+		}
+
+		f, err := LoadFeatures(DefaultFileName)
+		if err != nil {
+			projectErr = err
+			return
+		}
+
+		e, err := LoadExports(DefaultFileName)
+		if err != nil {
+			projectErr = err
+			return
+		}
+
+		cftpkg.Features = f
+		cftpkg.Exports = e
+	})
+
+	return projectErr
+}
+
+// LoadTagPolicy returns the path to the TagPolicy of the manifest at path,
+// relative to the current directory, or "" if the manifest does not set one.
+// Like LoadFeatures, it does not validate the rest of the manifest.
+func LoadTagPolicy(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return "", fmt.Errorf("unable to parse manifest '%s': %w", path, err)
+	}
+
+	m.Dir = filepath.Dir(path)
+
+	return m.Path(m.TagPolicy), nil
+}
+
+// LoadStack reads the settings of the named stack from the manifest at path,
+// so that rain deploy can apply them to a single stack.
+// Like LoadFeatures, it does not validate the rest of the manifest.
+// It returns nil if the stack is not in the manifest.
+func LoadStack(path string, name string) (*Stack, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("unable to parse manifest '%s': %w", path, err)
+	}
+
+	s, ok := m.Get(name)
+	if !ok {
+		return nil, nil
+	}
+
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// Parse reads and validates a manifest from YAML or JSON source
+func Parse(source []byte) (*Manifest, error) {
+	var m Manifest
+
+	err := yaml.Unmarshal(source, &m)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
+
+// Validate checks that stack names are unique, all dependencies
+// refer to stacks in the manifest, and there are no cycles
+func (m *Manifest) Validate() error {
+	if len(m.Stacks) == 0 {
+		return errors.New("manifest does not contain any stacks")
+	}
+
+	names := make(map[string]bool)
+	for _, s := range m.Stacks {
+		if s.Name == "" {
+			return errors.New("every stack in the manifest must have a Name")
+		}
+
+		if s.Template == "" {
+			return fmt.Errorf("stack '%s' does not have a Template", s.Name)
+		}
+
+		if names[s.Name] {
+			return fmt.Errorf("duplicate stack name '%s'", s.Name)
+		}
+
+		if err := s.validate(); err != nil {
+			return err
+		}
+
+		names[s.Name] = true
+	}
+
+	for _, s := range m.Stacks {
+		for _, dep := range s.DependsOn {
+			if !names[dep] {
+				return fmt.Errorf("stack '%s' depends on unknown stack '%s'", s.Name, dep)
+			}
+		}
+	}
+
+	_, err := m.Waves()
+
+	return err
+}
+
+// Get returns the named stack
+func (m *Manifest) Get(name string) (Stack, bool) {
+	for _, s := range m.Stacks {
+		if s.Name == name {
+			return s, true
+		}
+	}
+
+	return Stack{}, false
+}
+
+// Path resolves a path from the manifest relative to the manifest's directory
+func (m *Manifest) Path(p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+
+	return filepath.Join(m.Dir, p)
+}
+
+// Waves returns the stacks in dependency order, grouped into waves.
+// Every stack in a wave only depends on stacks in earlier waves,
+// so the stacks within a wave can be deployed in parallel.
+// Within each wave, stacks are listed in the order they appear in the manifest.
+func (m *Manifest) Waves() ([][]Stack, error) {
+	done := make(map[string]bool)
+	waves := make([][]Stack, 0)
+
+	for len(done) < len(m.Stacks) {
+		wave := make([]Stack, 0)
+
+		for _, s := range m.Stacks {
+			if done[s.Name] {
+				continue
+			}
+
+			ready := true
+			for _, dep := range s.DependsOn {
+				if !done[dep] {
+					ready = false
+					break
+				}
+			}
+
+			if ready {
+				wave = append(wave, s)
+			}
+		}
+
+		if len(wave) == 0 {
+			remaining := make([]string, 0)
+			for _, s := range m.Stacks {
+				if !done[s.Name] {
+					remaining = append(remaining, s.Name)
+				}
+			}
+
+			return nil, fmt.Errorf("circular dependency between stacks: %s", strings.Join(remaining, ", "))
+		}
+
+		for _, s := range wave {
+			done[s.Name] = true
+		}
+
+		waves = append(waves, wave)
+	}
+
+	return waves, nil
+}
@@ -0,0 +1,219 @@
+package manifest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	cftpkg "github.com/aws-cloudformation/rain/cft/pkg"
+	"github.com/aws-cloudformation/rain/internal/manifest"
+)
+
+func names(waves [][]manifest.Stack) string {
+	out := make([]string, 0)
+	for _, wave := range waves {
+		wn := make([]string, 0)
+		for _, s := range wave {
+			wn = append(wn, s.Name)
+		}
+		out = append(out, strings.Join(wn, ","))
+	}
+	return strings.Join(out, " | ")
+}
+
+func TestWaves(t *testing.T) {
+	m, err := manifest.Parse([]byte(`
+Stacks:
+  - Name: app
+    Template: app.yaml
+    DependsOn: [network, data]
+  - Name: network
+    Template: network.yaml
+  - Name: data
+    Template: data.yaml
+    DependsOn: [network]
+  - Name: logging
+    Template: logging.yaml
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waves, err := m.Waves()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "network,logging | data | app"
+	if got := names(waves); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
+
+func TestInvalid(t *testing.T) {
+	cases := map[string]string{
+		"circular": `
+Stacks:
+  - Name: a
+    Template: a.yaml
+    DependsOn: [b]
+  - Name: b
+    Template: b.yaml
+    DependsOn: [a]
+`,
+		"unknown dependency": `
+Stacks:
+  - Name: a
+    Template: a.yaml
+    DependsOn: [c]
+`,
+		"duplicate": `
+Stacks:
+  - Name: a
+    Template: a.yaml
+  - Name: a
+    Template: b.yaml
+`,
+		"missing template": `
+Stacks:
+  - Name: a
+`,
+		"empty": `Stacks: []`,
+		"unknown capability": `
+Stacks:
+  - Name: a
+    Template: a.yaml
+    Capabilities: [CAPABILITY_EVERYTHING]
+`,
+	}
+
+	for name, source := range cases {
+		if _, err := manifest.Parse([]byte(source)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestExports(t *testing.T) {
+	m, err := manifest.Parse([]byte(`
+Stacks:
+  - Name: app
+    Template: app.yaml
+Exports:
+  NameTemplate: ${StackName}-${OutputName}
+  Exported: [Vpc*]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if m.Exports == nil || m.Exports.Name("app", "VpcId") != "app-VpcId" {
+		t.Errorf("unexpected exports convention: %v", m.Exports)
+	}
+}
+
+func TestLoadFeatures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), manifest.DefaultFileName)
+
+	err := os.WriteFile(path, []byte(`
+Features:
+  Directives: [Rain::Embed]
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := manifest.LoadFeatures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f == nil || len(f.Directives) != 1 || f.Directives[0] != "Rain::Embed" {
+		t.Errorf("unexpected features: %v", f)
+	}
+
+	if f.Modules != nil {
+		t.Errorf("expected modules not to be limited, got %v", f.Modules)
+	}
+
+	if f.Dir != filepath.Dir(path) {
+		t.Errorf("expected local modules to be relative to %s, got %s", filepath.Dir(path), f.Dir)
+	}
+}
+
+func TestUseProject(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, manifest.DefaultFileName), []byte(`
+Exports:
+  NameTemplate: ${StackName}-${OutputName}
+Features:
+  Directives: [Rain::Embed]
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		os.Chdir(wd)
+		cftpkg.Features = nil
+		cftpkg.Exports = nil
+	}()
+
+	if cftpkg.Features != nil || cftpkg.Exports != nil {
+		t.Fatal("expected nothing to be applied before UseProject")
+	}
+
+	if err := manifest.UseProject(); err != nil {
+		t.Fatal(err)
+	}
+
+	if cftpkg.Features == nil || len(cftpkg.Features.Directives) != 1 {
+		t.Errorf("unexpected features: %v", cftpkg.Features)
+	}
+
+	if cftpkg.Exports == nil || cftpkg.Exports.Name("app", "VpcId") != "app-VpcId" {
+		t.Errorf("unexpected exports convention: %v", cftpkg.Exports)
+	}
+}
+
+func TestLoadStack(t *testing.T) {
+	path := filepath.Join(t.TempDir(), manifest.DefaultFileName)
+
+	err := os.WriteFile(path, []byte(`
+Stacks:
+  - Name: app
+    Template: app.yaml
+    Capabilities: [CAPABILITY_IAM]
+    RoleArn: arn:aws:iam::123456789012:role/deploy
+    NotificationArns: [arn:aws:sns:us-east-1:123456789012:deploys]
+    TerminationProtection: true
+    TimeoutInMinutes: 30
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := manifest.LoadStack(path, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s == nil || s.RoleArn != "arn:aws:iam::123456789012:role/deploy" || !s.TerminationProtection ||
+		s.TimeoutInMinutes != 30 || len(s.Capabilities) != 1 || len(s.NotificationArns) != 1 {
+		t.Errorf("unexpected stack: %v", s)
+	}
+
+	s, err = manifest.LoadStack(path, "other")
+	if err != nil || s != nil {
+		t.Errorf("expected no stack, got %v, %v", s, err)
+	}
+}
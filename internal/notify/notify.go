@@ -0,0 +1,283 @@
+// Package notify sends summaries of deployments to Slack, SNS topics and HTTP endpoints,
+// when a deployment starts, succeeds and fails.
+//
+// Targets are set with flags or in the Notifications section of a deploy config file:
+//
+//	Notifications:
+//	  Slack:
+//	    - https://hooks.slack.com/services/T000/B000/XXXX
+//	  SNS:
+//	    - arn:aws:sns:us-east-1:123456789012:deployments
+//	  Webhooks:
+//	    - https://deploy-log.example.com/events
+//	  Events: [failed]
+//
+// Slack webhooks receive a message with the summary as text. SNS topics receive the summary,
+// and webhooks receive the Event as JSON.
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of event that are sent
+const (
+	Started   = "started"
+	Succeeded = "succeeded"
+	Failed    = "failed"
+)
+
+var kinds = []string{Started, Succeeded, Failed}
+
+// PublishSNS sends a message to an SNS topic.
+// It is set by the deploy command so that this package doesn't depend on the AWS clients.
+var PublishSNS func(topicArn, subject, message string) error
+
+// Client sends webhook requests
+var Client = &http.Client{Timeout: 10 * time.Second}
+
+// Config is where notifications are sent
+type Config struct {
+	// Slack are the URLs of Slack incoming webhooks
+	Slack []string `yaml:"Slack,omitempty"`
+
+	// SNS are the ARNs of SNS topics
+	SNS []string `yaml:"SNS,omitempty"`
+
+	// Webhooks are URLs that the event is posted to as JSON
+	Webhooks []string `yaml:"Webhooks,omitempty"`
+
+	// Events limits the kinds of event that are sent; all of them are sent if it is empty
+	Events []string `yaml:"Events,omitempty"`
+}
+
+// Change is a resource that a deployment changes
+type Change struct {
+	Action       string `json:"action"`
+	LogicalId    string `json:"logicalId"`
+	ResourceType string `json:"resourceType"`
+}
+
+// Event is a deployment that has started, succeeded or failed
+type Event struct {
+	Kind      string `json:"event"`
+	StackName string `json:"stackName"`
+	Region    string `json:"region"`
+
+	// Status is the stack's status once the deployment has finished
+	Status string `json:"status,omitempty"`
+
+	// Changes are what the change set does to the stack's resources
+	Changes []Change `json:"changes,omitempty"`
+
+	// Duration is how long the deployment took, once it has finished
+	Duration time.Duration `json:"-"`
+
+	// FailingResource is the resource that caused a deployment to fail, and Reason is why
+	FailingResource string `json:"failingResource,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+}
+
+// MarshalJSON adds the duration in seconds
+func (e Event) MarshalJSON() ([]byte, error) {
+	type event Event
+	return json.Marshal(struct {
+		event
+		DurationSeconds float64 `json:"durationSeconds,omitempty"`
+	}{event(e), e.Duration.Round(time.Second).Seconds()})
+}
+
+// Load reads the Notifications section of a deploy config file.
+// It returns an empty Config if the file doesn't have one.
+func Load(path string) (Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	c, err := Parse(content)
+	if err != nil {
+		return c, fmt.Errorf("invalid notifications in '%s': %w", path, err)
+	}
+
+	return c, nil
+}
+
+// Parse reads the Notifications section of a deploy config file in YAML or JSON
+func Parse(content []byte) (Config, error) {
+	var file struct {
+		Notifications Config `yaml:"Notifications"`
+	}
+
+	// Config files that only list parameters, in the aws cli's formats, have no Notifications
+	if dc.IsParametersOnly(content) {
+		return Config{}, nil
+	}
+
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return Config{}, err
+	}
+
+	c := file.Notifications
+
+	for _, kind := range c.Events {
+		if !contains(kinds, kind) {
+			return c, fmt.Errorf("unknown event '%s'; use one of %s", kind, strings.Join(kinds, ", "))
+		}
+	}
+
+	return c, nil
+}
+
+// Merge returns a config that sends to the targets of both configs
+func (c Config) Merge(other Config) Config {
+	return Config{
+		Slack:    append(append([]string{}, c.Slack...), other.Slack...),
+		SNS:      append(append([]string{}, c.SNS...), other.SNS...),
+		Webhooks: append(append([]string{}, c.Webhooks...), other.Webhooks...),
+		Events:   append(append([]string{}, c.Events...), other.Events...),
+	}
+}
+
+// IsEmpty returns true if there is nowhere to send notifications
+func (c Config) IsEmpty() bool {
+	return len(c.Slack) == 0 && len(c.SNS) == 0 && len(c.Webhooks) == 0
+}
+
+// Send sends the event to every target, unless the config leaves out its kind.
+// It tries every target and returns an error that describes each that failed.
+func (c Config) Send(e Event) error {
+	if c.IsEmpty() || (len(c.Events) > 0 && !contains(c.Events, e.Kind)) {
+		return nil
+	}
+
+	errs := make([]error, 0)
+
+	for _, url := range c.Slack {
+		body, _ := json.Marshal(map[string]string{"text": e.Summary()})
+		if err := post(url, body); err != nil {
+			errs = append(errs, fmt.Errorf("unable to notify Slack: %w", err))
+		}
+	}
+
+	for _, url := range c.Webhooks {
+		body, err := json.Marshal(e)
+		if err == nil {
+			err = post(url, body)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to notify %s: %w", redact(url), err))
+		}
+	}
+
+	for _, topic := range c.SNS {
+		var err error
+		if PublishSNS == nil {
+			err = errors.New("SNS notifications are not available")
+		} else {
+			err = PublishSNS(topic, e.Title(), e.Summary())
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to notify %s: %w", topic, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Title is a one line description of the event
+func (e Event) Title() string {
+	switch e.Kind {
+	case Started:
+		return fmt.Sprintf("Deploying stack %s in %s", e.StackName, e.Region)
+	case Succeeded:
+		return fmt.Sprintf("Deployed stack %s in %s", e.StackName, e.Region)
+	default:
+		return fmt.Sprintf("Failed to deploy stack %s in %s", e.StackName, e.Region)
+	}
+}
+
+// Summary describes the event in plain text
+func (e Event) Summary() string {
+	lines := []string{e.Title()}
+
+	if e.Status != "" {
+		lines = append(lines, "Status: "+e.Status)
+	}
+
+	if e.Duration > 0 {
+		lines = append(lines, "Duration: "+e.Duration.Round(time.Second).String())
+	}
+
+	if e.FailingResource != "" {
+		lines = append(lines, "Failing resource: "+e.FailingResource)
+	}
+
+	if e.Reason != "" {
+		lines = append(lines, "Reason: "+e.Reason)
+	}
+
+	if len(e.Changes) > 0 {
+		counts := make(map[string]int)
+		for _, c := range e.Changes {
+			counts[c.Action]++
+		}
+
+		lines = append(lines, fmt.Sprintf("Changes: %d to add, %d to modify, %d to remove",
+			counts["Add"], counts["Modify"], counts["Remove"]))
+
+		for _, c := range e.Changes {
+			lines = append(lines, fmt.Sprintf("  %s %s %s", c.Action, c.ResourceType, c.LogicalId))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// post sends body to url as JSON
+func post(url string, body []byte) error {
+	res, err := Client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		// The error includes the URL, which may contain a token
+		return errors.New(strings.ReplaceAll(err.Error(), url, redact(url)))
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("the endpoint returned %s", res.Status)
+	}
+
+	return nil
+}
+
+// redact removes the path and query from a URL, which can contain
+// the secret that authorises requests to a webhook
+func redact(url string) string {
+	scheme, rest, found := strings.Cut(url, "://")
+	if !found {
+		return "webhook"
+	}
+
+	host, _, _ := strings.Cut(rest, "/")
+	return scheme + "://" + host
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
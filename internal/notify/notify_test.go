@@ -0,0 +1,147 @@
+package notify
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func testEvent() Event {
+	return Event{
+		Kind:            Failed,
+		StackName:       "app",
+		Region:          "us-east-1",
+		Status:          "UPDATE_ROLLBACK_COMPLETE",
+		Duration:        95 * time.Second,
+		FailingResource: "Bucket (AWS::S3::Bucket)",
+		Reason:          "app-bucket already exists",
+		Changes: []Change{
+			{"Add", "Bucket", "AWS::S3::Bucket"},
+			{"Modify", "Role", "AWS::IAM::Role"},
+		},
+	}
+}
+
+func TestParse(t *testing.T) {
+	c, err := Parse([]byte(`
+Parameters:
+  A: b
+Notifications:
+  Slack:
+    - https://hooks.slack.com/services/T/B/X
+  SNS:
+    - arn:aws:sns:us-east-1:123456789012:deployments
+  Events: [failed]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := Config{
+		Slack:  []string{"https://hooks.slack.com/services/T/B/X"},
+		SNS:    []string{"arn:aws:sns:us-east-1:123456789012:deployments"},
+		Events: []string{Failed},
+	}
+
+	if d := cmp.Diff(expected, c); d != "" {
+		t.Error(d)
+	}
+
+	if _, err := Parse([]byte("Notifications:\n  Events: [finished]\n")); err == nil {
+		t.Error("expected an error for an unknown event")
+	}
+
+	c, err = Parse([]byte(`{"Parameters": {}}`))
+	if err != nil || !c.IsEmpty() {
+		t.Errorf("expected no notifications, got %v, %v", c, err)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	expected := `Failed to deploy stack app in us-east-1
+Status: UPDATE_ROLLBACK_COMPLETE
+Duration: 1m35s
+Failing resource: Bucket (AWS::S3::Bucket)
+Reason: app-bucket already exists
+Changes: 1 to add, 1 to modify, 0 to remove
+  Add AWS::S3::Bucket Bucket
+  Modify AWS::IAM::Role Role`
+
+	if d := cmp.Diff(expected, testEvent().Summary()); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestSend(t *testing.T) {
+	bodies := make(map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies[r.URL.Path] = string(body)
+
+		if r.URL.Path == "/broken/secret-token" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	published := make([]string, 0)
+	PublishSNS = func(topicArn, subject, message string) error {
+		published = append(published, topicArn+": "+subject)
+		return nil
+	}
+	defer func() { PublishSNS = nil }()
+
+	c := Config{
+		Slack:    []string{server.URL + "/slack"},
+		SNS:      []string{"arn:aws:sns:us-east-1:123456789012:deployments"},
+		Webhooks: []string{server.URL + "/hook", server.URL + "/broken/secret-token"},
+	}
+
+	err := c.Send(testEvent())
+	if err == nil {
+		t.Fatal("expected an error from the broken webhook")
+	}
+
+	if strings.Contains(err.Error(), "secret-token") {
+		t.Errorf("the error should not contain the webhook's path: %s", err)
+	}
+
+	var slack map[string]string
+	if err := json.Unmarshal([]byte(bodies["/slack"]), &slack); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(slack["text"], "Failed to deploy stack app") {
+		t.Errorf("unexpected Slack message: %s", bodies["/slack"])
+	}
+
+	var hook map[string]interface{}
+	if err := json.Unmarshal([]byte(bodies["/hook"]), &hook); err != nil {
+		t.Fatal(err)
+	}
+	if hook["event"] != Failed || hook["durationSeconds"] != 95.0 || hook["failingResource"] != "Bucket (AWS::S3::Bucket)" {
+		t.Errorf("unexpected webhook body: %s", bodies["/hook"])
+	}
+
+	if d := cmp.Diff([]string{"arn:aws:sns:us-east-1:123456789012:deployments: Failed to deploy stack app in us-east-1"}, published); d != "" {
+		t.Error(d)
+	}
+
+	// Events that are left out are not sent
+	bodies = make(map[string]string)
+	c.Events = []string{Failed}
+	e := testEvent()
+	e.Kind = Started
+	if err := c.Send(e); err != nil {
+		t.Error(err)
+	}
+	if len(bodies) != 0 {
+		t.Errorf("expected nothing to be sent, got %v", bodies)
+	}
+}
@@ -0,0 +1,111 @@
+package oci
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Bundle is a template, and its configuration file if it has one, pulled from a registry
+type Bundle struct {
+	// Digest is the digest of the bundle's manifest
+	Digest string
+
+	// Template is the path of the template
+	Template string
+
+	// Config is the path of the configuration file, or ""
+	Config string
+}
+
+// Files reads a template and an optional configuration file to push as a bundle
+func Files(template, configFile string) ([]File, error) {
+	files := make([]File, 0)
+
+	for _, f := range []struct {
+		path      string
+		mediaType string
+	}{
+		{template, TemplateMediaType},
+		{configFile, ConfigMediaType},
+	} {
+		if f.path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(f.path)
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, File{
+			Name:      filepath.Base(f.path),
+			MediaType: f.mediaType,
+			Content:   content,
+		})
+	}
+
+	if len(files) == 2 && files[0].Name == files[1].Name {
+		return nil, errors.New("the template and configuration file must have different names")
+	}
+
+	return files, nil
+}
+
+// Download pulls a bundle into dir. If key is not nil, the bundle must have a cosign signature made with it.
+func Download(uri string, dir string, key []byte) (Bundle, error) {
+	r, err := ParseReference(uri)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	c := NewClient()
+
+	digest, files, err := c.Pull(r)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	if key != nil {
+		pub, err := ParsePublicKey(key)
+		if err != nil {
+			return Bundle{}, err
+		}
+
+		if err := c.Verify(r, digest, pub); err != nil {
+			return Bundle{}, err
+		}
+	}
+
+	b := Bundle{Digest: digest}
+
+	for _, f := range files {
+		// Only the base name is used, so that a bundle can't write outside dir
+		name := filepath.Base(f.Name)
+		if name == "." || name == ".." || name == string(filepath.Separator) {
+			return Bundle{}, fmt.Errorf("%s has a file with an invalid name: '%s'", uri, f.Name)
+		}
+
+		path := filepath.Join(dir, name)
+
+		switch f.MediaType {
+		case TemplateMediaType:
+			b.Template = path
+		case ConfigMediaType:
+			b.Config = path
+		default:
+			continue
+		}
+
+		if err := os.WriteFile(path, f.Content, 0644); err != nil {
+			return Bundle{}, err
+		}
+	}
+
+	if b.Template == "" {
+		return Bundle{}, fmt.Errorf("%s is not a rain bundle; it has no template", uri)
+	}
+
+	return b, nil
+}
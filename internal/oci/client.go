@@ -0,0 +1,424 @@
+package oci
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+)
+
+// emptyConfig is the config blob of artifacts that don't need one
+var emptyConfig = []byte("{}")
+
+var challengeParamRe = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// Credentials returns the user name and password for a registry, or empty strings to connect anonymously
+type Credentials func(registry string) (string, string)
+
+// Client talks to OCI registries with the distribution API
+type Client struct {
+	HTTP *http.Client
+
+	Credentials Credentials
+
+	// auth is the Authorization header to send, by repository
+	auth map[string]string
+}
+
+// NewClient returns a client that reads credentials
+// from RAIN_OCI_USERNAME and RAIN_OCI_PASSWORD, or from docker's configuration file
+func NewClient() *Client {
+	return &Client{
+		HTTP:        http.DefaultClient,
+		Credentials: DockerCredentials,
+		auth:        make(map[string]string),
+	}
+}
+
+// DockerCredentials reads credentials from RAIN_OCI_USERNAME and RAIN_OCI_PASSWORD,
+// or from the auths that docker login saves in docker's configuration file.
+// Credential helpers are not supported.
+func DockerCredentials(registry string) (string, string) {
+	if user := os.Getenv("RAIN_OCI_USERNAME"); user != "" {
+		return user, os.Getenv("RAIN_OCI_PASSWORD")
+	}
+
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", ""
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return "", ""
+	}
+
+	var docker struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}
+	if err := json.Unmarshal(content, &docker); err != nil {
+		config.Debugf("Unable to read docker config: %v", err)
+		return "", ""
+	}
+
+	for _, key := range []string{registry, "https://" + registry, "http://" + registry} {
+		a, ok := docker.Auths[key]
+		if !ok {
+			continue
+		}
+
+		if a.Auth != "" {
+			decoded, err := base64.StdEncoding.DecodeString(a.Auth)
+			if err != nil {
+				return "", ""
+			}
+			user, password, _ := strings.Cut(string(decoded), ":")
+			return user, password
+		}
+
+		return a.Username, a.Password
+	}
+
+	return "", ""
+}
+
+// baseURL returns the URL of the repository's API.
+// Registries on this machine are reached without TLS, as test registries usually are.
+func baseURL(r Reference) string {
+	scheme := "https"
+	host := r.Registry
+	if h, _, ok := strings.Cut(host, ":"); ok {
+		host = h
+	}
+	if host == "localhost" || host == "127.0.0.1" {
+		scheme = "http"
+	}
+
+	return fmt.Sprintf("%s://%s/v2/%s", scheme, r.Registry, r.Repository)
+}
+
+// do sends a request to the registry, authenticating when the registry asks for it
+func (c *Client) do(r Reference, method, u string, header map[string]string, body []byte) (*http.Response, error) {
+	send := func(auth string) (*http.Response, error) {
+		req, err := http.NewRequest(method, u, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+
+		return c.HTTP.Do(req)
+	}
+
+	if c.auth == nil {
+		c.auth = make(map[string]string)
+	}
+
+	key := r.Registry + "/" + r.Repository
+
+	res, err := send(c.auth[key])
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+
+	challenge := res.Header.Get("WWW-Authenticate")
+	res.Body.Close()
+
+	auth, err := c.authorize(r, challenge)
+	if err != nil {
+		return nil, err
+	}
+	c.auth[key] = auth
+
+	return send(auth)
+}
+
+// authorize answers a registry's challenge with basic credentials or a bearer token
+func (c *Client) authorize(r Reference, challenge string) (string, error) {
+	user, password := "", ""
+	if c.Credentials != nil {
+		user, password = c.Credentials(r.Registry)
+	}
+
+	scheme, rest, _ := strings.Cut(challenge, " ")
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if user == "" {
+			return "", fmt.Errorf("%s requires credentials; run docker login or set RAIN_OCI_USERNAME and RAIN_OCI_PASSWORD", r.Registry)
+		}
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(user+":"+password)), nil
+	case "bearer":
+	default:
+		return "", fmt.Errorf("unsupported authentication challenge from %s: %s", r.Registry, challenge)
+	}
+
+	params := make(map[string]string)
+	for _, m := range challengeParamRe.FindAllStringSubmatch(rest, -1) {
+		params[m[1]] = m[2]
+	}
+
+	if params["realm"] == "" {
+		return "", fmt.Errorf("authentication challenge from %s has no realm", r.Registry)
+	}
+
+	q := url.Values{}
+	if params["service"] != "" {
+		q.Set("service", params["service"])
+	}
+	scope := params["scope"]
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull,push", r.Repository)
+	}
+	q.Set("scope", scope)
+
+	req, err := http.NewRequest(http.MethodGet, params["realm"]+"?"+q.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+
+	if user != "" {
+		req.SetBasicAuth(user, password)
+	}
+
+	res, err := c.HTTP.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to get a token for %s: %s", r.Registry, res.Status)
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&token); err != nil {
+		return "", err
+	}
+
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+
+	if token.Token == "" {
+		return "", fmt.Errorf("%s did not return a token", params["realm"])
+	}
+
+	return "Bearer " + token.Token, nil
+}
+
+// statusError reads the registry's explanation of a failed request
+func statusError(res *http.Response, action string) error {
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	msg := strings.TrimSpace(string(body))
+	if msg == "" {
+		return fmt.Errorf("unable to %s: %s", action, res.Status)
+	}
+	return fmt.Errorf("unable to %s: %s: %s", action, res.Status, msg)
+}
+
+// pushBlob uploads content, unless the registry already has it
+func (c *Client) pushBlob(r Reference, content []byte) (string, error) {
+	digest := Digest(content)
+
+	res, err := c.do(r, http.MethodHead, baseURL(r)+"/blobs/"+digest, nil, nil)
+	if err != nil {
+		return "", err
+	}
+	res.Body.Close()
+	if res.StatusCode == http.StatusOK {
+		config.Debugf("Registry already has %s", digest)
+		return digest, nil
+	}
+
+	res, err = c.do(r, http.MethodPost, baseURL(r)+"/blobs/uploads/", nil, nil)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusAccepted {
+		return "", statusError(res, "start upload")
+	}
+
+	location, err := res.Location()
+	if err != nil {
+		return "", err
+	}
+
+	q := location.Query()
+	q.Set("digest", digest)
+	location.RawQuery = q.Encode()
+
+	res, err = c.do(r, http.MethodPut, location.String(), map[string]string{"Content-Type": "application/octet-stream"}, content)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return "", statusError(res, "upload "+digest)
+	}
+
+	return digest, nil
+}
+
+// Push uploads the files as a bundle and tags it. It returns the digest of the bundle's manifest.
+func (c *Client) Push(r Reference, files []File) (string, error) {
+	if r.Tag == "" {
+		return "", errors.New("bundles can only be pushed to a tag")
+	}
+
+	configDigest, err := c.pushBlob(r, emptyConfig)
+	if err != nil {
+		return "", err
+	}
+
+	m := Manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestMediaType,
+		ArtifactType:  ArtifactType,
+		Config: Descriptor{
+			MediaType: EmptyMediaType,
+			Digest:    configDigest,
+			Size:      int64(len(emptyConfig)),
+		},
+		Layers: make([]Descriptor, 0),
+	}
+
+	for _, f := range files {
+		digest, err := c.pushBlob(r, f.Content)
+		if err != nil {
+			return "", err
+		}
+
+		m.Layers = append(m.Layers, Descriptor{
+			MediaType:   f.MediaType,
+			Digest:      digest,
+			Size:        int64(len(f.Content)),
+			Annotations: map[string]string{TitleAnnotation: f.Name},
+		})
+	}
+
+	content, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+
+	res, err := c.do(r, http.MethodPut, baseURL(r)+"/manifests/"+r.Tag, map[string]string{"Content-Type": ManifestMediaType}, content)
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusCreated {
+		return "", statusError(res, "push manifest")
+	}
+
+	return Digest(content), nil
+}
+
+// manifest downloads a manifest and returns it with its digest
+func (c *Client) manifest(r Reference) (Manifest, string, error) {
+	res, err := c.do(r, http.MethodGet, baseURL(r)+"/manifests/"+r.version(), map[string]string{"Accept": ManifestMediaType}, nil)
+	if err != nil {
+		return Manifest{}, "", err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return Manifest{}, "", statusError(res, "get "+r.String())
+	}
+
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return Manifest{}, "", err
+	}
+
+	digest := Digest(content)
+	if r.Digest != "" && r.Digest != digest {
+		return Manifest{}, "", fmt.Errorf("manifest of %s has digest %s", r, digest)
+	}
+
+	var m Manifest
+	if err := json.Unmarshal(content, &m); err != nil {
+		return Manifest{}, "", err
+	}
+
+	return m, digest, nil
+}
+
+// blob downloads content and checks that it matches its digest
+func (c *Client) blob(r Reference, digest string) ([]byte, error) {
+	res, err := c.do(r, http.MethodGet, baseURL(r)+"/blobs/"+digest, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, statusError(res, "get "+digest)
+	}
+
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if Digest(content) != digest {
+		return nil, fmt.Errorf("content of %s does not match its digest", digest)
+	}
+
+	return content, nil
+}
+
+// Pull downloads the files of a bundle. It returns the digest of the bundle's manifest.
+func (c *Client) Pull(r Reference) (string, []File, error) {
+	m, digest, err := c.manifest(r)
+	if err != nil {
+		return "", nil, err
+	}
+
+	files := make([]File, 0)
+	for _, layer := range m.Layers {
+		content, err := c.blob(r, layer.Digest)
+		if err != nil {
+			return "", nil, err
+		}
+
+		files = append(files, File{
+			Name:      layer.Annotations[TitleAnnotation],
+			MediaType: layer.MediaType,
+			Content:   content,
+		})
+	}
+
+	return digest, files, nil
+}
@@ -0,0 +1,86 @@
+package oci
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SignatureAnnotation holds the signature of a cosign signature layer
+const SignatureAnnotation = "dev.cosignproject.cosign/signature"
+
+// signatureTag returns the tag that cosign stores the signatures of a manifest under
+func signatureTag(digest string) string {
+	return strings.Replace(digest, ":", "-", 1) + ".sig"
+}
+
+// ParsePublicKey reads a PEM encoded ECDSA public key, as cosign generate-key-pair writes
+func ParsePublicKey(content []byte) (*ecdsa.PublicKey, error) {
+	block, _ := pem.Decode(content)
+	if block == nil {
+		return nil, errors.New("the key is not PEM encoded")
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	ecKey, ok := key.(*ecdsa.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("unsupported key type %T; only ECDSA keys are supported", key)
+	}
+
+	return ecKey, nil
+}
+
+// Verify checks that the manifest with the digest has a cosign signature made with the key
+func (c *Client) Verify(r Reference, digest string, key *ecdsa.PublicKey) error {
+	sigRef := Reference{Registry: r.Registry, Repository: r.Repository, Tag: signatureTag(digest)}
+
+	m, _, err := c.manifest(sigRef)
+	if err != nil {
+		return fmt.Errorf("no signature found for %s: %v", digest, err)
+	}
+
+	for _, layer := range m.Layers {
+		sig, err := base64.StdEncoding.DecodeString(layer.Annotations[SignatureAnnotation])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+
+		payload, err := c.blob(sigRef, layer.Digest)
+		if err != nil {
+			return err
+		}
+
+		hash := sha256.Sum256(payload)
+		if !ecdsa.VerifyASN1(key, hash[:], sig) {
+			continue
+		}
+
+		// The signed payload names the manifest that was signed
+		var simpleSigning struct {
+			Critical struct {
+				Image struct {
+					Digest string `json:"docker-manifest-digest"`
+				} `json:"image"`
+			} `json:"critical"`
+		}
+		if err := json.Unmarshal(payload, &simpleSigning); err != nil {
+			continue
+		}
+
+		if simpleSigning.Critical.Image.Digest == digest {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no signature of %s was made with the key", digest)
+}
@@ -0,0 +1,149 @@
+// Package oci pushes and pulls template bundles to and from OCI registries,
+// so that templates can be shared through the same registries as container images.
+//
+// A bundle is referenced like:
+//
+//	oci://registry.example.com/team/network:v1.2.0
+//	oci://registry.example.com/team/network@sha256:<digest>
+//
+// A bundle is an OCI artifact whose layers are a template and, optionally,
+// a deployment configuration file with parameters and tags. Each layer is
+// named with the standard org.opencontainers.image.title annotation, as
+// other artifact tools like oras do.
+//
+// Pulled bundles can be checked against a cosign signature made with a key pair
+// (cosign sign --key), so that only reviewed templates are deployed.
+package oci
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Prefix marks a template or module source as an OCI reference
+const Prefix = "oci://"
+
+// Media types of the parts of a bundle
+const (
+	ManifestMediaType = "application/vnd.oci.image.manifest.v1+json"
+	EmptyMediaType    = "application/vnd.oci.empty.v1+json"
+	ArtifactType      = "application/vnd.rain.bundle.v1"
+	TemplateMediaType = "application/vnd.rain.template.v1"
+	ConfigMediaType   = "application/vnd.rain.config.v1"
+)
+
+// TitleAnnotation names the file that a layer holds
+const TitleAnnotation = "org.opencontainers.image.title"
+
+// Reference is the location of a bundle in a registry
+type Reference struct {
+	// Registry is the host, and optionally the port, of the registry
+	Registry string
+
+	// Repository is the path of the repository in the registry
+	Repository string
+
+	// Tag is set unless the reference is by digest
+	Tag string
+
+	// Digest is set if the reference is by digest
+	Digest string
+}
+
+// IsReference returns true if uri refers to a bundle in an OCI registry
+func IsReference(uri string) bool {
+	return strings.HasPrefix(uri, Prefix)
+}
+
+// ParseReference parses an oci:// reference. Without a tag or digest, the tag is latest.
+func ParseReference(uri string) (Reference, error) {
+	if !IsReference(uri) {
+		return Reference{}, fmt.Errorf("'%s' is not an OCI reference; it should start with %s", uri, Prefix)
+	}
+
+	rest := strings.TrimPrefix(uri, Prefix)
+
+	var r Reference
+
+	registry, path, ok := strings.Cut(rest, "/")
+	if !ok || registry == "" || path == "" {
+		return Reference{}, fmt.Errorf("'%s' should be %sregistry/repository:tag", uri, Prefix)
+	}
+	r.Registry = registry
+
+	if name, digest, ok := strings.Cut(path, "@"); ok {
+		if !strings.HasPrefix(digest, "sha256:") {
+			return Reference{}, fmt.Errorf("unsupported digest in '%s'; only sha256 is supported", uri)
+		}
+		path = name
+		r.Digest = digest
+	} else if i := strings.LastIndex(path, ":"); i > strings.LastIndex(path, "/") {
+		r.Tag = path[i+1:]
+		path = path[:i]
+	}
+
+	if r.Tag == "" && r.Digest == "" {
+		r.Tag = "latest"
+	}
+
+	if path == "" || r.Tag == "" && r.Digest == "" {
+		return Reference{}, fmt.Errorf("'%s' should be %sregistry/repository:tag", uri, Prefix)
+	}
+
+	if path != strings.ToLower(path) {
+		return Reference{}, fmt.Errorf("repository names must be lowercase: '%s'", path)
+	}
+
+	r.Repository = path
+
+	return r, nil
+}
+
+// String returns the reference in its oci:// form
+func (r Reference) String() string {
+	if r.Digest != "" {
+		return fmt.Sprintf("%s%s/%s@%s", Prefix, r.Registry, r.Repository, r.Digest)
+	}
+	return fmt.Sprintf("%s%s/%s:%s", Prefix, r.Registry, r.Repository, r.Tag)
+}
+
+// version returns the tag or digest that the reference points to
+func (r Reference) version() string {
+	if r.Digest != "" {
+		return r.Digest
+	}
+	return r.Tag
+}
+
+// Descriptor describes content stored in a registry
+type Descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Manifest is an OCI image manifest
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	MediaType     string            `json:"mediaType"`
+	ArtifactType  string            `json:"artifactType,omitempty"`
+	Config        Descriptor        `json:"config"`
+	Layers        []Descriptor      `json:"layers"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// File is a file in a bundle
+type File struct {
+	Name      string
+	MediaType string
+	Content   []byte
+}
+
+// Digest returns the digest of content in the form registries use
+func Digest(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,260 @@
+package oci
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	cases := map[string]Reference{
+		"oci://registry.example.com/team/network:v1.2.0": {Registry: "registry.example.com", Repository: "team/network", Tag: "v1.2.0"},
+		"oci://localhost:5000/network":                   {Registry: "localhost:5000", Repository: "network", Tag: "latest"},
+		"oci://registry.example.com/network@sha256:abc":  {Registry: "registry.example.com", Repository: "network", Digest: "sha256:abc"},
+	}
+
+	for uri, expected := range cases {
+		actual, err := ParseReference(uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if actual != expected {
+			t.Errorf("%s: expected %+v, got %+v", uri, expected, actual)
+		}
+
+		if actual.String() != uri && expected.Tag != "latest" {
+			t.Errorf("expected %s, got %s", uri, actual.String())
+		}
+	}
+
+	for _, uri := range []string{
+		"registry.example.com/network:v1",
+		"oci://registry.example.com",
+		"oci://registry.example.com/Network:v1",
+		"oci://registry.example.com/network@md5:abc",
+	} {
+		if _, err := ParseReference(uri); err == nil {
+			t.Errorf("expected an error for %s", uri)
+		}
+	}
+}
+
+// registry is an in-memory registry that requires a bearer token
+type registry struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	manifests map[string][]byte
+	uploads   int
+}
+
+func newRegistry() *httptest.Server {
+	reg := &registry{
+		blobs:     make(map[string][]byte),
+		manifests: make(map[string][]byte),
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		reg.mu.Lock()
+		defer reg.mu.Unlock()
+
+		if req.URL.Path == "/token" {
+			if user, password, ok := req.BasicAuth(); !ok || user != "rain" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"token": "abc"}`)
+			return
+		}
+
+		if req.Header.Get("Authorization") != "Bearer abc" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		path := strings.TrimPrefix(req.URL.Path, "/v2/team/network")
+		body, _ := io.ReadAll(req.Body)
+
+		switch {
+		case req.Method == http.MethodPost && path == "/blobs/uploads/":
+			reg.uploads++
+			w.Header().Set("Location", fmt.Sprintf("/v2/team/network/blobs/uploads/%d?state=x", reg.uploads))
+			w.WriteHeader(http.StatusAccepted)
+		case req.Method == http.MethodPut && strings.HasPrefix(path, "/blobs/uploads/"):
+			digest := req.URL.Query().Get("digest")
+			if req.URL.Query().Get("state") != "x" || Digest(body) != digest {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			reg.blobs[digest] = body
+			w.WriteHeader(http.StatusCreated)
+		case strings.HasPrefix(path, "/blobs/"):
+			content, ok := reg.blobs[strings.TrimPrefix(path, "/blobs/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(content)
+		case req.Method == http.MethodPut && strings.HasPrefix(path, "/manifests/"):
+			reg.manifests[strings.TrimPrefix(path, "/manifests/")] = body
+			reg.manifests[Digest(body)] = body
+			w.WriteHeader(http.StatusCreated)
+		case strings.HasPrefix(path, "/manifests/"):
+			content, ok := reg.manifests[strings.TrimPrefix(path, "/manifests/")]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write(content)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	return server
+}
+
+func testReference(server *httptest.Server, version string) string {
+	return "oci://" + strings.TrimPrefix(server.URL, "http://") + "/team/network" + version
+}
+
+func TestPushAndDownload(t *testing.T) {
+	server := newRegistry()
+	defer server.Close()
+
+	t.Setenv("RAIN_OCI_USERNAME", "rain")
+	t.Setenv("RAIN_OCI_PASSWORD", "secret")
+
+	dir := t.TempDir()
+	template := filepath.Join(dir, "network.yaml")
+	configFile := filepath.Join(dir, "network-config.yaml")
+	os.WriteFile(template, []byte("Resources: {}\n"), 0644)
+	os.WriteFile(configFile, []byte("Parameters:\n  Name: test\n"), 0644)
+
+	files, err := Files(template, configFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, _ := ParseReference(testReference(server, ":v1"))
+
+	digest, err := NewClient().Push(r, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// By tag and by digest
+	for _, version := range []string{":v1", "@" + digest} {
+		out := t.TempDir()
+
+		b, err := Download(testReference(server, version), out, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if b.Digest != digest {
+			t.Errorf("expected digest %s, got %s", digest, b.Digest)
+		}
+
+		content, _ := os.ReadFile(b.Template)
+		if b.Template != filepath.Join(out, "network.yaml") || string(content) != "Resources: {}\n" {
+			t.Errorf("unexpected template %s: %q", b.Template, content)
+		}
+
+		content, _ = os.ReadFile(b.Config)
+		if b.Config != filepath.Join(out, "network-config.yaml") || string(content) != "Parameters:\n  Name: test\n" {
+			t.Errorf("unexpected config %s: %q", b.Config, content)
+		}
+	}
+
+	t.Setenv("RAIN_OCI_PASSWORD", "wrong")
+	if _, err := Download(testReference(server, ":v1"), t.TempDir(), nil); err == nil {
+		t.Error("expected an error with the wrong password")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	server := newRegistry()
+	defer server.Close()
+
+	t.Setenv("RAIN_OCI_USERNAME", "rain")
+	t.Setenv("RAIN_OCI_PASSWORD", "secret")
+
+	dir := t.TempDir()
+	template := filepath.Join(dir, "network.yaml")
+	os.WriteFile(template, []byte("Resources: {}\n"), 0644)
+
+	files, _ := Files(template, "")
+	r, _ := ParseReference(testReference(server, ":v1"))
+
+	c := NewClient()
+	digest, err := c.Push(r, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	pub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	der, _ = x509.MarshalPKIXPublicKey(&otherKey.PublicKey)
+	otherPub := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	if _, err := Download(testReference(server, ":v1"), t.TempDir(), pub); err == nil {
+		t.Error("expected an error for an unsigned bundle")
+	}
+
+	// Sign the manifest the way cosign does
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"team/network"},"image":{"docker-manifest-digest":"%s"},"type":"cosign container image signature"},"optional":null}`, digest))
+	hash := sha256.Sum256(payload)
+	sig, _ := ecdsa.SignASN1(rand.Reader, key, hash[:])
+
+	sigRef := Reference{Registry: r.Registry, Repository: r.Repository, Tag: signatureTag(digest)}
+	payloadDigest, err := c.pushBlob(sigRef, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	configDigest, _ := c.pushBlob(sigRef, emptyConfig)
+
+	m, _ := json.Marshal(Manifest{
+		SchemaVersion: 2,
+		MediaType:     ManifestMediaType,
+		Config:        Descriptor{MediaType: EmptyMediaType, Digest: configDigest, Size: 2},
+		Layers: []Descriptor{{
+			MediaType:   "application/vnd.dev.cosign.simplesigning.v1+json",
+			Digest:      payloadDigest,
+			Size:        int64(len(payload)),
+			Annotations: map[string]string{SignatureAnnotation: base64.StdEncoding.EncodeToString(sig)},
+		}},
+	})
+	res, err := c.do(sigRef, http.MethodPut, baseURL(sigRef)+"/manifests/"+sigRef.Tag, map[string]string{"Content-Type": ManifestMediaType}, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res.Body.Close()
+
+	if _, err := Download(testReference(server, ":v1"), t.TempDir(), pub); err != nil {
+		t.Errorf("expected the signature to verify: %v", err)
+	}
+
+	if _, err := Download(testReference(server, ":v1"), t.TempDir(), otherPub); err == nil {
+		t.Error("expected an error for a signature made with another key")
+	}
+}
@@ -0,0 +1,180 @@
+// Package plan saves a change set that rain deploy --plan-only has created,
+// along with the packaged template it was created from, so that it can be reviewed
+// and approved before rain deploy --apply executes exactly that change set.
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// Version is the version of the plan file format that this package writes
+const Version = 1
+
+// Plan is the contents of a plan file
+type Plan struct {
+	Version int `json:"version"`
+
+	// Region is the region the change set was created in
+	Region string `json:"region"`
+
+	StackName string `json:"stackName"`
+
+	// ChangeSetName and ChangeSetId are empty if the template and parameters
+	// would not change the stack, in which case NoChanges is set
+	ChangeSetName string `json:"changeSetName,omitempty"`
+	ChangeSetId   string `json:"changeSetId,omitempty"`
+	NoChanges     bool   `json:"noChanges,omitempty"`
+
+	CreatedAt time.Time `json:"createdAt"`
+
+	// TemplateSHA256 is the checksum of Template, so that reviewers
+	// can tell whether two plans were made from the same template
+	TemplateSHA256 string `json:"templateSha256"`
+
+	// Template is the packaged template that the change set was created from
+	Template string `json:"template"`
+
+	// StackPolicy is set on the stack once the change set has been executed
+	StackPolicy string `json:"stackPolicy,omitempty"`
+
+	// ChangeSet is the full description of the change set when the plan was made,
+	// with secret parameter values masked
+	ChangeSet *cloudformation.DescribeChangeSetOutput `json:"changeSet,omitempty"`
+}
+
+// New returns a plan for a change set. changeSet is nil if there are no changes.
+// Secret parameter values are masked so that they are not written to the plan file.
+func New(region, stackName, template, stackPolicy string,
+	changeSet *cloudformation.DescribeChangeSetOutput, now time.Time) Plan {
+
+	changeSet = maskParameters(changeSet)
+
+	sum := sha256.Sum256([]byte(template))
+
+	p := Plan{
+		Version:        Version,
+		Region:         region,
+		StackName:      stackName,
+		CreatedAt:      now.UTC(),
+		TemplateSHA256: hex.EncodeToString(sum[:]),
+		Template:       template,
+		StackPolicy:    stackPolicy,
+		ChangeSet:      changeSet,
+	}
+
+	if changeSet == nil {
+		p.NoChanges = true
+	} else {
+		p.ChangeSetName = ptr.ToString(changeSet.ChangeSetName)
+		p.ChangeSetId = ptr.ToString(changeSet.ChangeSetId)
+	}
+
+	return p
+}
+
+// maskParameters returns a copy of changeSet with config.Mask applied to its parameter values
+func maskParameters(changeSet *cloudformation.DescribeChangeSetOutput) *cloudformation.DescribeChangeSetOutput {
+	if changeSet == nil {
+		return nil
+	}
+
+	masked := *changeSet
+	masked.Parameters = make([]types.Parameter, len(changeSet.Parameters))
+
+	for i, param := range changeSet.Parameters {
+		if param.ParameterValue != nil {
+			param.ParameterValue = ptr.String(config.Mask(*param.ParameterValue))
+		}
+
+		if param.ResolvedValue != nil {
+			param.ResolvedValue = ptr.String(config.Mask(*param.ResolvedValue))
+		}
+
+		masked.Parameters[i] = param
+	}
+
+	return &masked
+}
+
+// Write saves the plan as JSON
+func (p Plan) Write(path string) error {
+	out, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(out, '\n'), 0644)
+}
+
+// Read loads a plan file and checks that it is complete and has not been edited
+func Read(path string) (*Plan, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var p Plan
+	if err := json.Unmarshal(content, &p); err != nil {
+		return nil, fmt.Errorf("invalid plan '%s': %w", path, err)
+	}
+
+	if err := p.validate(); err != nil {
+		return nil, fmt.Errorf("invalid plan '%s': %w", path, err)
+	}
+
+	return &p, nil
+}
+
+func (p Plan) validate() error {
+	if p.Version != Version {
+		return fmt.Errorf("unsupported version %d; expected %d", p.Version, Version)
+	}
+
+	if p.Region == "" || p.StackName == "" {
+		return errors.New("the region and stack name must be set")
+	}
+
+	if !p.NoChanges && p.ChangeSetId == "" {
+		return errors.New("there is no change set ID")
+	}
+
+	sum := sha256.Sum256([]byte(p.Template))
+	if hex.EncodeToString(sum[:]) != p.TemplateSHA256 {
+		return errors.New("the template does not match its checksum")
+	}
+
+	return nil
+}
+
+// Check returns an error unless current, the change set as it is now,
+// is the change set in the plan and can still be executed.
+// CloudFormation makes a change set unavailable if its stack has changed since it was created.
+func (p Plan) Check(current *cloudformation.DescribeChangeSetOutput) error {
+	if ptr.ToString(current.ChangeSetId) != p.ChangeSetId {
+		return fmt.Errorf("change set '%s' is not the one in the plan (%s)",
+			ptr.ToString(current.ChangeSetId), p.ChangeSetId)
+	}
+
+	if current.Status != types.ChangeSetStatusCreateComplete {
+		return fmt.Errorf("change set '%s' is %s: %s",
+			p.ChangeSetName, current.Status, ptr.ToString(current.StatusReason))
+	}
+
+	if current.ExecutionStatus != types.ExecutionStatusAvailable {
+		return fmt.Errorf("change set '%s' can't be executed because its execution status is %s; "+
+			"the stack may have changed since the plan was made", p.ChangeSetName, current.ExecutionStatus)
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package plan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/config"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+const changeSetId = "arn:aws:cloudformation:us-east-1:123456789012:changeSet/rain-1/abc"
+
+func testChangeSet() *cloudformation.DescribeChangeSetOutput {
+	return &cloudformation.DescribeChangeSetOutput{
+		ChangeSetName:   ptr.String("rain-1"),
+		ChangeSetId:     ptr.String(changeSetId),
+		StackName:       ptr.String("app"),
+		Status:          types.ChangeSetStatusCreateComplete,
+		ExecutionStatus: types.ExecutionStatusAvailable,
+		Changes: []types.Change{
+			{
+				Type: types.ChangeTypeResource,
+				ResourceChange: &types.ResourceChange{
+					Action:            types.ChangeActionAdd,
+					LogicalResourceId: ptr.String("Bucket"),
+					ResourceType:      ptr.String("AWS::S3::Bucket"),
+				},
+			},
+		},
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	p := New("us-east-1", "app", "Resources: {}\n", `{"Statement":[]}`, testChangeSet(), now)
+
+	if err := p.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if read.ChangeSetId != changeSetId || read.ChangeSetName != "rain-1" || read.NoChanges {
+		t.Errorf("unexpected change set in plan: %+v", read)
+	}
+
+	if !read.CreatedAt.Equal(now) || read.StackPolicy != `{"Statement":[]}` {
+		t.Errorf("unexpected plan: %+v", read)
+	}
+
+	if len(read.ChangeSet.Changes) != 1 || ptr.ToString(read.ChangeSet.Changes[0].ResourceChange.LogicalResourceId) != "Bucket" {
+		t.Errorf("the change set description was not saved: %+v", read.ChangeSet)
+	}
+}
+
+func TestMaskParameters(t *testing.T) {
+	config.AddSecret("hunter2")
+
+	changeSet := testChangeSet()
+	changeSet.Parameters = []types.Parameter{
+		{ParameterKey: ptr.String("Password"), ParameterValue: ptr.String("hunter2")},
+		{ParameterKey: ptr.String("Name"), ParameterValue: ptr.String("app")},
+	}
+
+	p := New("us-east-1", "app", "Resources: {}\n", "", changeSet, time.Now())
+
+	if v := ptr.ToString(p.ChangeSet.Parameters[0].ParameterValue); v != "****" {
+		t.Errorf("expected the secret to be masked, got %q", v)
+	}
+
+	if v := ptr.ToString(p.ChangeSet.Parameters[1].ParameterValue); v != "app" {
+		t.Errorf("expected 'app', got %q", v)
+	}
+
+	if v := ptr.ToString(changeSet.Parameters[0].ParameterValue); v != "hunter2" {
+		t.Errorf("the original change set was changed: %q", v)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	dir := t.TempDir()
+
+	p := New("us-east-1", "app", "Resources: {}\n", "", testChangeSet(), time.Now())
+	p.Template = "Resources:\n  Edited: {}\n"
+
+	path := filepath.Join(dir, "edited.json")
+	if err := p.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(path); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected a checksum error, got %v", err)
+	}
+
+	path = filepath.Join(dir, "version.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Read(path); err == nil || !strings.Contains(err.Error(), "version") {
+		t.Errorf("expected a version error, got %v", err)
+	}
+}
+
+func TestNoChanges(t *testing.T) {
+	p := New("us-east-1", "app", "Resources: {}\n", "", nil, time.Now())
+
+	if !p.NoChanges || p.ChangeSetId != "" {
+		t.Errorf("expected a plan with no changes: %+v", p)
+	}
+
+	if err := p.validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	p := New("us-east-1", "app", "Resources: {}\n", "", testChangeSet(), time.Now())
+
+	if err := p.Check(testChangeSet()); err != nil {
+		t.Error(err)
+	}
+
+	obsolete := testChangeSet()
+	obsolete.ExecutionStatus = types.ExecutionStatusObsolete
+	if err := p.Check(obsolete); err == nil || !strings.Contains(err.Error(), "OBSOLETE") {
+		t.Errorf("expected an error for an obsolete change set, got %v", err)
+	}
+
+	other := testChangeSet()
+	other.ChangeSetId = ptr.String("arn:aws:cloudformation:us-east-1:123456789012:changeSet/rain-2/def")
+	if err := p.Check(other); err == nil {
+		t.Error("expected an error for a different change set")
+	}
+}
@@ -0,0 +1,70 @@
+// Package ratelimit spaces out calls so that rain makes no more than a set number of them each second,
+// which keeps commands that poll many stacks, like rain ls and rain watch, clear of API throttling.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter lets calls through at a steady rate. A nil Limiter doesn't limit anything.
+type Limiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+
+	// now is replaced in tests
+	now func() time.Time
+}
+
+// New returns a Limiter that allows perSecond calls each second,
+// or nil if perSecond is not positive
+func New(perSecond float64) *Limiter {
+	if perSecond <= 0 {
+		return nil
+	}
+
+	return &Limiter{
+		interval: time.Duration(float64(time.Second) / perSecond),
+		now:      time.Now,
+	}
+}
+
+// reserve takes the next free slot and returns how long to wait for it
+func (l *Limiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+
+	wait := l.next.Sub(now)
+	l.next = l.next.Add(l.interval)
+
+	return wait
+}
+
+// Wait blocks until the next call is allowed, or returns ctx's error if ctx is done first
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+
+	wait := l.reserve()
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	t := time.NewTimer(wait)
+	defer t.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNew(t *testing.T) {
+	if New(0) != nil || New(-1) != nil {
+		t.Error("expected no limiter without a positive rate")
+	}
+
+	if l := New(4); l.interval != 250*time.Millisecond {
+		t.Errorf("expected an interval of 250ms, got %s", l.interval)
+	}
+}
+
+func TestReserve(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	l := New(2)
+	l.now = func() time.Time { return now }
+
+	expected := []time.Duration{0, 500 * time.Millisecond, time.Second}
+	for i, e := range expected {
+		if wait := l.reserve(); wait != e {
+			t.Errorf("call %d: expected to wait %s, got %s", i, e, wait)
+		}
+	}
+
+	// Slots that weren't used don't build up into a burst
+	now = now.Add(time.Minute)
+	if wait := l.reserve(); wait != 0 {
+		t.Errorf("expected no wait after a quiet spell, got %s", wait)
+	}
+	if wait := l.reserve(); wait != 500*time.Millisecond {
+		t.Errorf("expected to wait 500ms, got %s", wait)
+	}
+}
+
+func TestWait(t *testing.T) {
+	var l *Limiter
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("a nil limiter should not wait: %v", err)
+	}
+
+	l = New(0.001)
+	if err := l.Wait(context.Background()); err != nil {
+		t.Errorf("the first call should not wait: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := l.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the wait to end with the context, got %v", err)
+	}
+}
@@ -0,0 +1,246 @@
+// Package stacksetdiff compares the stack set that a rain stackset deploy config file describes
+// with the stack set that is deployed, so that an update can be reviewed before it is made.
+//
+// Settings that the config file leaves out are not compared, because rain stackset deploy
+// keeps their deployed values. Parameters are compared in the same way, one at a time.
+package stacksetdiff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft/diff"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// Desired is the stack set as the config file and flags describe it
+type Desired struct {
+	Description     string
+	PermissionModel string
+	Parameters      map[string]string
+
+	// Tags are compared only if there are any, as an update without tags leaves them as they are
+	Tags map[string]string
+
+	// Accounts or OrganizationalUnits are where instances should be, in each of Regions
+	Accounts            []string
+	OrganizationalUnits []string
+	Regions             []string
+}
+
+// Change is a setting, parameter or tag that would change
+type Change struct {
+	Mode diff.Mode `json:"mode"`
+	Name string    `json:"name"`
+	Old  string    `json:"old,omitempty"`
+	New  string    `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	switch c.Mode {
+	case diff.Added:
+		return fmt.Sprintf("%s %s: %s", c.Mode, c.Name, c.New)
+	case diff.Removed:
+		return fmt.Sprintf("%s %s: %s", c.Mode, c.Name, c.Old)
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", c.Mode, c.Name, c.Old, c.New)
+	}
+}
+
+// Instance is where a stack set instance is: an account, or an organizational unit, and a region
+type Instance struct {
+	Target string `json:"target"`
+	Region string `json:"region"`
+}
+
+func (i Instance) String() string {
+	return i.Target + " / " + i.Region
+}
+
+// Result is what would change
+type Result struct {
+	Settings   []Change `json:"settings,omitempty"`
+	Parameters []Change `json:"parameters,omitempty"`
+	Tags       []Change `json:"tags,omitempty"`
+
+	// Added are the instances that the config has but the stack set doesn't
+	Added []Instance `json:"added,omitempty"`
+
+	// Unlisted are the stack set's instances that the config doesn't list.
+	// rain stackset deploy leaves them as they are.
+	Unlisted []Instance `json:"unlisted,omitempty"`
+}
+
+// IsEmpty returns true if nothing would change
+func (r Result) IsEmpty() bool {
+	return len(r.Settings) == 0 && len(r.Parameters) == 0 && len(r.Tags) == 0 &&
+		len(r.Added) == 0 && len(r.Unlisted) == 0
+}
+
+// Compare returns what would change if the deployed stack set and its instances were updated to desired
+func Compare(desired Desired, current *types.StackSet, instances []types.StackInstanceSummary) Result {
+	r := Result{}
+
+	if desired.Description != "" && desired.Description != ptr.ToString(current.Description) {
+		r.Settings = append(r.Settings, Change{diff.Changed, "Description",
+			ptr.ToString(current.Description), desired.Description})
+	}
+
+	if desired.PermissionModel != "" && desired.PermissionModel != string(current.PermissionModel) {
+		r.Settings = append(r.Settings, Change{diff.Changed, "PermissionModel",
+			string(current.PermissionModel), desired.PermissionModel})
+	}
+
+	currentParams := make(map[string]string, len(current.Parameters))
+	for _, p := range current.Parameters {
+		currentParams[ptr.ToString(p.ParameterKey)] = ptr.ToString(p.ParameterValue)
+	}
+	r.Parameters = compareMaps(currentParams, desired.Parameters, false)
+
+	if len(desired.Tags) > 0 {
+		currentTags := make(map[string]string, len(current.Tags))
+		for _, t := range current.Tags {
+			currentTags[ptr.ToString(t.Key)] = ptr.ToString(t.Value)
+		}
+		r.Tags = compareMaps(currentTags, desired.Tags, true)
+	}
+
+	targets := desired.Accounts
+	byOU := len(desired.OrganizationalUnits) > 0
+	if byOU {
+		targets = desired.OrganizationalUnits
+	}
+
+	deployed := make(map[Instance]bool)
+	for _, i := range instances {
+		target := ptr.ToString(i.Account)
+		if byOU {
+			target = ptr.ToString(i.OrganizationalUnitId)
+		}
+		deployed[Instance{target, ptr.ToString(i.Region)}] = true
+	}
+
+	wanted := make(map[Instance]bool)
+	for _, target := range targets {
+		for _, region := range desired.Regions {
+			instance := Instance{target, region}
+			if wanted[instance] {
+				continue
+			}
+			wanted[instance] = true
+
+			if !deployed[instance] {
+				r.Added = append(r.Added, instance)
+			}
+		}
+	}
+
+	// Without any instances in the config, the instances aren't being managed with it
+	if len(wanted) > 0 {
+		for instance := range deployed {
+			if !wanted[instance] {
+				r.Unlisted = append(r.Unlisted, instance)
+			}
+		}
+	}
+
+	sortInstances(r.Added)
+	sortInstances(r.Unlisted)
+
+	return r
+}
+
+// compareMaps compares the values of the keys in desired.
+// If all is set, keys that are only in current are removed too.
+func compareMaps(current, desired map[string]string, all bool) []Change {
+	var changes []Change
+
+	for key, value := range desired {
+		old, ok := current[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{diff.Added, key, "", value})
+		case old != value:
+			changes = append(changes, Change{diff.Changed, key, old, value})
+		}
+	}
+
+	if all {
+		for key, value := range current {
+			if _, ok := desired[key]; !ok {
+				changes = append(changes, Change{diff.Removed, key, value, ""})
+			}
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
+
+func sortInstances(instances []Instance) {
+	sort.Slice(instances, func(i, j int) bool {
+		if instances[i].Target != instances[j].Target {
+			return instances[i].Target < instances[j].Target
+		}
+		return instances[i].Region < instances[j].Region
+	})
+}
+
+// String describes the changes, with a heading for each kind of change
+func (r Result) String() string {
+	out := strings.Builder{}
+
+	section := func(heading string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+
+		out.WriteString(console.Yellow(heading + ":\n"))
+		for _, line := range lines {
+			out.WriteString("  " + colourise(line) + "\n")
+		}
+	}
+
+	section("Settings", changeLines(r.Settings))
+	section("Parameters", changeLines(r.Parameters))
+	section("Tags", changeLines(r.Tags))
+	section("Instances to add (account or OU / region)", instanceLines(diff.Added, r.Added))
+	section("Instances that are not in the config, which deploy leaves as they are", instanceLines(diff.Removed, r.Unlisted))
+
+	return out.String()
+}
+
+func changeLines(changes []Change) []string {
+	lines := make([]string, len(changes))
+	for i, c := range changes {
+		lines[i] = c.String()
+	}
+	return lines
+}
+
+func instanceLines(mode diff.Mode, instances []Instance) []string {
+	lines := make([]string, len(instances))
+	for i, instance := range instances {
+		lines[i] = fmt.Sprintf("%s %s", mode, instance)
+	}
+	return lines
+}
+
+func colourise(line string) string {
+	switch {
+	case strings.HasPrefix(line, diff.Added.String()):
+		return console.Green(line)
+	case strings.HasPrefix(line, diff.Removed.String()):
+		return console.Red(line)
+	case strings.HasPrefix(line, diff.Changed.String()):
+		return console.Blue(line)
+	default:
+		return line
+	}
+}
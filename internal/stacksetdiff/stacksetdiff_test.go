@@ -0,0 +1,112 @@
+package stacksetdiff
+
+import (
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/diff"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/google/go-cmp/cmp"
+)
+
+func testStackSet() *types.StackSet {
+	return &types.StackSet{
+		Description:     ptr.String("Logging"),
+		PermissionModel: types.PermissionModelsSelfManaged,
+		Parameters: []types.Parameter{
+			{ParameterKey: ptr.String("Retention"), ParameterValue: ptr.String("30")},
+			{ParameterKey: ptr.String("Prefix"), ParameterValue: ptr.String("logs")},
+		},
+		Tags: []types.Tag{
+			{Key: ptr.String("Owner"), Value: ptr.String("platform")},
+			{Key: ptr.String("Team"), Value: ptr.String("ops")},
+		},
+	}
+}
+
+func testInstances() []types.StackInstanceSummary {
+	return []types.StackInstanceSummary{
+		{Account: ptr.String("111111111111"), Region: ptr.String("us-east-1")},
+		{Account: ptr.String("222222222222"), Region: ptr.String("us-east-1")},
+	}
+}
+
+func TestCompare(t *testing.T) {
+	desired := Desired{
+		Description: "Logging",
+		Parameters:  map[string]string{"Retention": "90", "Bucket": "central"},
+		Tags:        map[string]string{"Owner": "platform"},
+		Accounts:    []string{"111111111111"},
+		Regions:     []string{"us-east-1", "us-west-2"},
+	}
+
+	expected := Result{
+		Parameters: []Change{
+			{diff.Added, "Bucket", "", "central"},
+			{diff.Changed, "Retention", "30", "90"},
+		},
+		Tags: []Change{
+			{diff.Removed, "Team", "ops", ""},
+		},
+		Added:    []Instance{{"111111111111", "us-west-2"}},
+		Unlisted: []Instance{{"222222222222", "us-east-1"}},
+	}
+
+	if d := cmp.Diff(expected, Compare(desired, testStackSet(), testInstances())); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestCompareLeftOut(t *testing.T) {
+	// Settings, tags and instances that the config leaves out are kept as they are
+	r := Compare(Desired{Parameters: map[string]string{"Prefix": "logs"}}, testStackSet(), testInstances())
+
+	if !r.IsEmpty() {
+		t.Errorf("expected no changes, got %+v", r)
+	}
+}
+
+func TestCompareOrganizationalUnits(t *testing.T) {
+	instances := []types.StackInstanceSummary{
+		{Account: ptr.String("111111111111"), OrganizationalUnitId: ptr.String("ou-a"), Region: ptr.String("us-east-1")},
+		{Account: ptr.String("222222222222"), OrganizationalUnitId: ptr.String("ou-a"), Region: ptr.String("us-east-1")},
+	}
+
+	desired := Desired{
+		PermissionModel:     string(types.PermissionModelsServiceManaged),
+		OrganizationalUnits: []string{"ou-a", "ou-b"},
+		Regions:             []string{"us-east-1"},
+	}
+
+	r := Compare(desired, testStackSet(), instances)
+
+	expected := Result{
+		Settings: []Change{{diff.Changed, "PermissionModel", "SELF_MANAGED", "SERVICE_MANAGED"}},
+		Added:    []Instance{{"ou-b", "us-east-1"}},
+	}
+
+	if d := cmp.Diff(expected, r); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestString(t *testing.T) {
+	defer func(noColour bool) { console.NoColour = noColour }(console.NoColour)
+	console.NoColour = true
+
+	r := Result{
+		Parameters: []Change{{diff.Changed, "Retention", "30", "90"}},
+		Added:      []Instance{{"111111111111", "us-west-2"}},
+	}
+
+	expected := `Parameters:
+  (>) Retention: 30 -> 90
+Instances to add (account or OU / region):
+  (+) 111111111111 / us-west-2
+`
+
+	if d := cmp.Diff(expected, r.String()); d != "" {
+		t.Error(d)
+	}
+}
@@ -0,0 +1,118 @@
+// Package stateful knows which resource types hold data that is lost
+// when CloudFormation replaces or deletes a resource, such as databases, buckets and file systems,
+// and finds the changes in a change set that would do that to them.
+package stateful
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// Types are the resource types that hold data
+var Types = []string{
+	"AWS::Backup::BackupVault",
+	"AWS::Cognito::UserPool",
+	"AWS::DocDB::DBCluster",
+	"AWS::DocDB::DBInstance",
+	"AWS::DynamoDB::GlobalTable",
+	"AWS::DynamoDB::Table",
+	"AWS::EC2::Volume",
+	"AWS::ECR::Repository",
+	"AWS::EFS::FileSystem",
+	"AWS::ElastiCache::CacheCluster",
+	"AWS::ElastiCache::ReplicationGroup",
+	"AWS::ElastiCache::ServerlessCache",
+	"AWS::Elasticsearch::Domain",
+	"AWS::FSx::FileSystem",
+	"AWS::Kinesis::Stream",
+	"AWS::KMS::Key",
+	"AWS::Logs::LogGroup",
+	"AWS::MemoryDB::Cluster",
+	"AWS::Neptune::DBCluster",
+	"AWS::Neptune::DBInstance",
+	"AWS::OpenSearchService::Domain",
+	"AWS::RDS::DBCluster",
+	"AWS::RDS::DBInstance",
+	"AWS::Redshift::Cluster",
+	"AWS::S3::Bucket",
+	"AWS::SecretsManager::Secret",
+	"AWS::SQS::Queue",
+	"AWS::Timestream::Table",
+}
+
+// IsStateful returns true if resources of the type hold data
+func IsStateful(typeName string) bool {
+	return slices.Contains(Types, typeName)
+}
+
+// Change is a change set's replacement or deletion of a stateful resource
+type Change struct {
+	// StackName is the stack the resource is in
+	StackName string
+
+	// LogicalId is the resource's logical ID
+	LogicalId string
+
+	// Type is the resource's type
+	Type string
+
+	// Action is what happens to the resource, e.g. "replaced"
+	Action string
+}
+
+func (c Change) String() string {
+	return fmt.Sprintf("%s %s (%s) will be %s", c.StackName, c.LogicalId, c.Type, c.Action)
+}
+
+// Changes returns the changes that would replace or delete stateful resources in the stack.
+// Resources that are removed from the stack with a Retain policy are left alone, so they are not included.
+func Changes(stackName string, changes []types.Change) []Change {
+	out := make([]Change, 0)
+
+	for _, change := range changes {
+		rc := change.ResourceChange
+		if rc == nil || !IsStateful(ptr.ToString(rc.ResourceType)) {
+			continue
+		}
+
+		var action string
+
+		switch rc.Action {
+		case types.ChangeActionRemove:
+			if rc.PolicyAction == types.PolicyActionRetain {
+				continue
+			}
+			action = "deleted"
+		case types.ChangeActionModify:
+			switch rc.Replacement {
+			case types.ReplacementTrue:
+				action = "replaced"
+			case types.ReplacementConditional:
+				action = "replaced, depending on the values it resolves to"
+			default:
+				continue
+			}
+		default:
+			continue
+		}
+
+		switch rc.PolicyAction {
+		case types.PolicyActionSnapshot, types.PolicyActionReplaceAndSnapshot:
+			action += ", after a snapshot is taken"
+		case types.PolicyActionReplaceAndRetain:
+			action += ", and the old resource retained"
+		}
+
+		out = append(out, Change{
+			StackName: stackName,
+			LogicalId: ptr.ToString(rc.LogicalResourceId),
+			Type:      ptr.ToString(rc.ResourceType),
+			Action:    action,
+		})
+	}
+
+	return out
+}
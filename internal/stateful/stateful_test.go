@@ -0,0 +1,53 @@
+package stateful
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/google/go-cmp/cmp"
+)
+
+func change(id, typeName string, action types.ChangeAction, replacement types.Replacement, policy types.PolicyAction) types.Change {
+	return types.Change{
+		ResourceChange: &types.ResourceChange{
+			LogicalResourceId: ptr.String(id),
+			ResourceType:      ptr.String(typeName),
+			Action:            action,
+			Replacement:       replacement,
+			PolicyAction:      policy,
+		},
+	}
+}
+
+func TestChanges(t *testing.T) {
+	changes := []types.Change{
+		change("Table", "AWS::DynamoDB::Table", types.ChangeActionModify, types.ReplacementTrue, types.PolicyActionReplaceAndDelete),
+		change("Database", "AWS::RDS::DBInstance", types.ChangeActionModify, types.ReplacementConditional, types.PolicyActionReplaceAndSnapshot),
+		change("Bucket", "AWS::S3::Bucket", types.ChangeActionRemove, "", types.PolicyActionDelete),
+		change("Logs", "AWS::S3::Bucket", types.ChangeActionRemove, "", types.PolicyActionRetain),
+		change("Files", "AWS::EFS::FileSystem", types.ChangeActionModify, types.ReplacementFalse, ""),
+		change("Function", "AWS::Lambda::Function", types.ChangeActionModify, types.ReplacementTrue, types.PolicyActionReplaceAndDelete),
+		change("Queue", "AWS::SQS::Queue", types.ChangeActionAdd, "", ""),
+	}
+
+	expected := []Change{
+		{"app", "Table", "AWS::DynamoDB::Table", "replaced"},
+		{"app", "Database", "AWS::RDS::DBInstance", "replaced, depending on the values it resolves to, after a snapshot is taken"},
+		{"app", "Bucket", "AWS::S3::Bucket", "deleted"},
+	}
+
+	if d := cmp.Diff(expected, Changes("app", changes)); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestIsStateful(t *testing.T) {
+	if !IsStateful("AWS::RDS::DBCluster") {
+		t.Error("expected a database cluster to be stateful")
+	}
+
+	if IsStateful("AWS::IAM::Role") {
+		t.Error("expected a role not to be stateful")
+	}
+}
@@ -0,0 +1,193 @@
+// Package strategy reads the Strategy section of a deploy config file,
+// which sets how rain replaces a stack when it is deployed.
+//
+// The blue-green strategy deploys the template as a second stack alongside the live one,
+// named after the stack with a -blue or -green suffix. Once the new stack has deployed,
+// its Validate commands are run against it, an alias is pointed at one of its outputs,
+// and the old stack is deleted.
+//
+// An example config file:
+//
+//	Strategy:
+//	  Type: blue-green
+//	  Validate:
+//	    - curl -fsS "$RAIN_OUTPUT_ApiUrl/health"
+//	  Alias:
+//	    Output: ApiUrl
+//	    SSMParameter: /app/api-url
+//	    Route53:
+//	      HostedZoneId: Z0123456789ABCDEFGHIJ
+//	      Name: api.example.com
+//	    Export: app-api-url
+//
+// Validate commands are run like hooks, with the new stack's outputs in RAIN_OUTPUT_<key>.
+package strategy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws-cloudformation/rain/internal/hooks"
+	"gopkg.in/yaml.v3"
+)
+
+// BlueGreen is the Type of the blue-green strategy
+const BlueGreen = "blue-green"
+
+// Suffixes are added to the stack's name to name the two stacks that blue-green switches between
+var Suffixes = [2]string{"-blue", "-green"}
+
+// Strategy is how a stack is replaced when it is deployed
+type Strategy struct {
+	// Type is the strategy to use, which must be BlueGreen
+	Type string `yaml:"Type"`
+
+	// Validate are commands that check the new stack before the alias is pointed at it
+	Validate hooks.Commands `yaml:"Validate"`
+
+	// Alias is what is pointed at the new stack
+	Alias Alias `yaml:"Alias"`
+
+	// KeepOld leaves the old stack in place instead of deleting it, so that the alias can be pointed back at it
+	KeepOld bool `yaml:"KeepOld"`
+
+	// DeleteProtected turns off the old stack's termination protection to delete it, without asking first
+	DeleteProtected bool `yaml:"DeleteProtected"`
+}
+
+// Alias is how the rest of the world finds the live stack.
+// It is set to the value of one of the live stack's outputs,
+// in any of an SSM parameter, a Route 53 record, or an export.
+type Alias struct {
+	// Output is the new stack's output that the alias is set to
+	Output string `yaml:"Output"`
+
+	// SSMParameter is the name of a String parameter in the SSM Parameter Store
+	SSMParameter string `yaml:"SSMParameter"`
+
+	// Route53 is a DNS record
+	Route53 *Record `yaml:"Route53"`
+
+	// Export is the name of an export, which rain keeps in a stack of its own,
+	// named after the stack with an -alias suffix
+	Export string `yaml:"Export"`
+}
+
+// Record is a Route 53 record that is set to the output's value
+type Record struct {
+	HostedZoneId string `yaml:"HostedZoneId"`
+	Name         string `yaml:"Name"`
+
+	// Type is the record's type, CNAME by default
+	Type string `yaml:"Type"`
+
+	// TTL is how many seconds resolvers may cache the record for, 60 by default
+	TTL int64 `yaml:"TTL"`
+}
+
+// Load reads the Strategy section of a deploy config file.
+// It returns nil if the file doesn't have a Strategy section.
+func Load(path string) (*Strategy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid strategy in '%s': %w", path, err)
+	}
+
+	return s, nil
+}
+
+// Parse reads the Strategy section of a deploy config file in YAML or JSON
+func Parse(content []byte) (*Strategy, error) {
+	var file struct {
+		Strategy *Strategy `yaml:"Strategy"`
+	}
+
+	// Config files that only list parameters, in the aws cli's formats, have no Strategy
+	if dc.IsParametersOnly(content) {
+		return nil, nil
+	}
+
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return nil, err
+	}
+
+	s := file.Strategy
+	if s == nil {
+		return nil, nil
+	}
+
+	if s.Type != BlueGreen {
+		return nil, fmt.Errorf("unknown Type '%s'; use %s", s.Type, BlueGreen)
+	}
+
+	a := s.Alias
+	if a.SSMParameter == "" && a.Route53 == nil && a.Export == "" {
+		return nil, errors.New("the Alias needs an SSMParameter, Route53 record or Export to point at the new stack")
+	}
+
+	if a.Output == "" {
+		return nil, errors.New("the Alias needs the Output of the new stack to point at")
+	}
+
+	if r := a.Route53; r != nil {
+		if r.HostedZoneId == "" || r.Name == "" {
+			return nil, errors.New("the Route53 alias needs a HostedZoneId and a Name")
+		}
+
+		if r.Type == "" {
+			r.Type = "CNAME"
+		}
+
+		if r.TTL == 0 {
+			r.TTL = 60
+		}
+	}
+
+	return s, nil
+}
+
+// Stacks returns the names of the live stack, which is empty if there isn't one yet,
+// and of the stack to deploy next to it. deployed reports whether a stack has been deployed;
+// a stack that failed to be created is replaced.
+// Both stacks exist if the old one was kept with KeepOld, and then isLive reports
+// which of them the alias points at, so that the other is deployed again.
+func Stacks(name string, deployed func(stackName string) bool, isLive func(stackName string) (bool, error)) (live string, next string, err error) {
+	blue, green := name+Suffixes[0], name+Suffixes[1]
+
+	blueExists, greenExists := deployed(blue), deployed(green)
+
+	switch {
+	case blueExists && greenExists:
+		for _, pair := range [][2]string{{blue, green}, {green, blue}} {
+			ok, err := isLive(pair[0])
+			if err != nil {
+				return "", "", fmt.Errorf("unable to tell whether '%s' is live: %w", pair[0], err)
+			}
+
+			if ok {
+				return pair[0], pair[1], nil
+			}
+		}
+
+		return "", "", fmt.Errorf("both '%s' and '%s' exist, but the alias doesn't point at either; "+
+			"delete the one that is not live before deploying again", blue, green)
+	case blueExists:
+		return blue, green, nil
+	case greenExists:
+		return green, blue, nil
+	default:
+		return "", blue, nil
+	}
+}
+
+// AliasStack returns the name of the stack that holds the Export alias for the stack
+func AliasStack(name string) string {
+	return name + "-alias"
+}
@@ -0,0 +1,102 @@
+package strategy
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(`
+Parameters:
+  Name: app
+Strategy:
+  Type: blue-green
+  Validate: curl -fsS "$RAIN_OUTPUT_ApiUrl/health"
+  Alias:
+    Output: ApiUrl
+    Route53:
+      HostedZoneId: Z0123456789ABCDEFGHIJ
+      Name: api.example.com
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &Strategy{
+		Type:     BlueGreen,
+		Validate: []string{`curl -fsS "$RAIN_OUTPUT_ApiUrl/health"`},
+		Alias: Alias{
+			Output: "ApiUrl",
+			Route53: &Record{
+				HostedZoneId: "Z0123456789ABCDEFGHIJ",
+				Name:         "api.example.com",
+				Type:         "CNAME",
+				TTL:          60,
+			},
+		},
+	}
+
+	if d := cmp.Diff(expected, s); d != "" {
+		t.Error(d)
+	}
+}
+
+func TestParseNone(t *testing.T) {
+	s, err := Parse([]byte("Parameters:\n  Name: app\n"))
+	if err != nil || s != nil {
+		t.Errorf("expected no strategy, got %+v, %v", s, err)
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	cases := []string{
+		"Strategy:\n  Type: canary\n  Alias:\n    Output: Url\n    Export: url\n",
+		"Strategy:\n  Type: blue-green\n  Alias:\n    Output: Url\n",
+		"Strategy:\n  Type: blue-green\n  Alias:\n    Export: url\n",
+		"Strategy:\n  Type: blue-green\n  Alias:\n    Output: Url\n    Route53:\n      Name: api.example.com\n",
+	}
+
+	for _, c := range cases {
+		if _, err := Parse([]byte(c)); err == nil {
+			t.Errorf("expected an error for:\n%s", c)
+		}
+	}
+}
+
+func TestStacks(t *testing.T) {
+	cases := []struct {
+		existing []string
+		aliased  string
+		live     string
+		next     string
+		err      bool
+	}{
+		{nil, "", "", "app-blue", false},
+		{[]string{"app-blue"}, "app-blue", "app-blue", "app-green", false},
+		{[]string{"app-green"}, "app-green", "app-green", "app-blue", false},
+		{[]string{"app-blue", "app-green"}, "app-blue", "app-blue", "app-green", false},
+		{[]string{"app-blue", "app-green"}, "app-green", "app-green", "app-blue", false},
+		{[]string{"app-blue", "app-green"}, "", "", "", true},
+	}
+
+	for _, c := range cases {
+		exists := func(name string) bool {
+			for _, e := range c.existing {
+				if e == name {
+					return true
+				}
+			}
+			return false
+		}
+
+		isLive := func(name string) (bool, error) {
+			return name == c.aliased, nil
+		}
+
+		live, next, err := Stacks("app", exists, isLive)
+		if live != c.live || next != c.next || (err != nil) != c.err {
+			t.Errorf("%v: expected %q, %q, %t; got %q, %q, %v", c.existing, c.live, c.next, c.err, live, next, err)
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Package tagpolicy checks the tags of a stack against an organisation's tagging policy
+// before it is deployed, and adds standard tags that record where each deployment came from.
+// CloudFormation propagates stack tags to the resources in the stack that support tags.
+//
+// An example policy file:
+//
+//	Required:
+//	  - Key: CostCenter
+//	    Pattern: ^[0-9]{4}$
+//	  - Key: Environment
+//	    Values: [dev, staging, prod]
+//	  - Key: Owner
+//	StandardTags:
+//	  GitCommit: rain:GitCommit
+//	  Deployer: rain:DeployedBy
+//	  Timestamp: rain:DeployedAt
+//
+// The policy file is set with --tag-policy, the TagPolicy section of a manifest,
+// or the RAIN_TAG_POLICY environment variable.
+package tagpolicy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Path is the policy file that applies when one isn't set by a flag or a manifest
+var Path = os.Getenv("RAIN_TAG_POLICY")
+
+// Standard tag sources that can be used in StandardTags
+const (
+	GitCommit = "GitCommit"
+	Deployer  = "Deployer"
+	Timestamp = "Timestamp"
+)
+
+// Policy is the parsed contents of a tagging policy file
+type Policy struct {
+	// Required are the tags every stack must have
+	Required []Requirement `yaml:"Required,omitempty"`
+
+	// StandardTags maps a standard tag source (GitCommit, Deployer or Timestamp)
+	// to the key of the tag rain adds to every stack
+	StandardTags map[string]string `yaml:"StandardTags,omitempty"`
+}
+
+// Requirement is a tag that every stack must have
+type Requirement struct {
+	Key string `yaml:"Key"`
+
+	// Pattern is a regular expression the value must match
+	Pattern string `yaml:"Pattern,omitempty"`
+
+	// Values lists the values the tag can have
+	Values []string `yaml:"Values,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// Source describes the deployment that standard tags record
+type Source struct {
+	// GitCommit is the commit of the repository the template is in, if it is in one
+	GitCommit string
+
+	// Deployer is the ARN of the identity that deploys the stack
+	Deployer string
+
+	// Time is when the deployment started
+	Time time.Time
+}
+
+// Load reads a policy file
+func Load(path string) (*Policy, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag policy '%s': %w", path, err)
+	}
+
+	return p, nil
+}
+
+// Parse reads a policy from YAML or JSON
+func Parse(content []byte) (*Policy, error) {
+	var p Policy
+	if err := yaml.Unmarshal(content, &p); err != nil {
+		return nil, err
+	}
+
+	for i := range p.Required {
+		r := &p.Required[i]
+
+		if r.Key == "" {
+			return nil, errors.New("a required tag has no Key")
+		}
+
+		if r.Pattern != "" {
+			var err error
+			r.pattern, err = regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid Pattern for tag '%s': %w", r.Key, err)
+			}
+		}
+	}
+
+	for source := range p.StandardTags {
+		switch source {
+		case GitCommit, Deployer, Timestamp:
+		default:
+			return nil, fmt.Errorf("unknown standard tag '%s'; use %s, %s or %s",
+				source, GitCommit, Deployer, Timestamp)
+		}
+	}
+
+	return &p, nil
+}
+
+// Apply returns a copy of tags with the policy's standard tags added.
+// Tags that are already set are not replaced, and standard tags
+// with no value, such as GitCommit outside of a repository, are left out.
+func (p *Policy) Apply(tags map[string]string, src Source) map[string]string {
+	out := make(map[string]string, len(tags)+len(p.StandardTags))
+	for k, v := range tags {
+		out[k] = v
+	}
+
+	for source, key := range p.StandardTags {
+		if _, ok := out[key]; ok {
+			continue
+		}
+
+		var value string
+		switch source {
+		case GitCommit:
+			value = src.GitCommit
+		case Deployer:
+			value = src.Deployer
+		case Timestamp:
+			if !src.Time.IsZero() {
+				value = src.Time.UTC().Format(time.RFC3339)
+			}
+		}
+
+		if value != "" {
+			out[key] = value
+		}
+	}
+
+	return out
+}
+
+// Violation is a required tag that is missing or has a value the policy doesn't allow
+type Violation struct {
+	Key     string
+	Message string
+}
+
+// Check returns the ways in which tags break the policy, sorted by key
+func (p *Policy) Check(tags map[string]string) []Violation {
+	violations := make([]Violation, 0)
+
+	for _, r := range p.Required {
+		value, ok := tags[r.Key]
+
+		switch {
+		case !ok || value == "":
+			violations = append(violations, Violation{r.Key, "is missing"})
+		case len(r.Values) > 0 && !contains(r.Values, value):
+			violations = append(violations, Violation{r.Key,
+				fmt.Sprintf("is '%s' but must be one of %s", value, strings.Join(r.Values, ", "))})
+		case r.pattern != nil && !r.pattern.MatchString(value):
+			violations = append(violations, Violation{r.Key,
+				fmt.Sprintf("is '%s' but must match %s", value, r.Pattern)})
+		}
+	}
+
+	sort.SliceStable(violations, func(i, j int) bool {
+		return violations[i].Key < violations[j].Key
+	})
+
+	return violations
+}
+
+// ViolationError is returned when a stack's tags break the policy.
+// Its message lists every violation, one per line.
+type ViolationError struct {
+	StackName  string
+	Violations []Violation
+}
+
+func (e ViolationError) Error() string {
+	lines := make([]string, 0, len(e.Violations)+1)
+	lines = append(lines, fmt.Sprintf("stack '%s' does not meet the tag policy:", e.StackName))
+
+	for _, v := range e.Violations {
+		lines = append(lines, fmt.Sprintf("  - tag %s %s", v.Key, v.Message))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
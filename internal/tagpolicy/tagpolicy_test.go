@@ -0,0 +1,100 @@
+package tagpolicy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws-cloudformation/rain/internal/tagpolicy"
+	"github.com/google/go-cmp/cmp"
+)
+
+const policy = `
+Required:
+  - Key: CostCenter
+    Pattern: ^[0-9]{4}$
+  - Key: Environment
+    Values: [dev, staging, prod]
+  - Key: Owner
+StandardTags:
+  GitCommit: rain:GitCommit
+  Deployer: rain:DeployedBy
+  Timestamp: rain:DeployedAt
+`
+
+func TestCheck(t *testing.T) {
+	p, err := tagpolicy.Parse([]byte(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	violations := p.Check(map[string]string{
+		"CostCenter":  "12a4",
+		"Environment": "test",
+	})
+
+	expected := []tagpolicy.Violation{
+		{Key: "CostCenter", Message: "is '12a4' but must match ^[0-9]{4}$"},
+		{Key: "Environment", Message: "is 'test' but must be one of dev, staging, prod"},
+		{Key: "Owner", Message: "is missing"},
+	}
+
+	if d := cmp.Diff(expected, violations); d != "" {
+		t.Error(d)
+	}
+
+	violations = p.Check(map[string]string{
+		"CostCenter":  "1234",
+		"Environment": "prod",
+		"Owner":       "platform",
+	})
+	if len(violations) != 0 {
+		t.Errorf("expected no violations, got %v", violations)
+	}
+
+	err = tagpolicy.ViolationError{StackName: "app", Violations: expected[2:]}
+	if err.Error() != "stack 'app' does not meet the tag policy:\n  - tag Owner is missing" {
+		t.Errorf("unexpected error message: %s", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	p, err := tagpolicy.Parse([]byte(policy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := map[string]string{"Owner": "platform", "rain:DeployedBy": "pipeline"}
+
+	actual := p.Apply(tags, tagpolicy.Source{
+		Deployer: "arn:aws:iam::123456789012:user/someone",
+		Time:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+	})
+
+	expected := map[string]string{
+		"Owner":           "platform",
+		"rain:DeployedBy": "pipeline",
+		"rain:DeployedAt": "2024-05-01T12:00:00Z",
+	}
+
+	if d := cmp.Diff(expected, actual); d != "" {
+		t.Error(d)
+	}
+
+	if len(tags) != 2 {
+		t.Error("Apply modified its input")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []string{
+		"Required:\n  - Pattern: x\n",
+		"Required:\n  - Key: A\n    Pattern: '['\n",
+		"StandardTags:\n  Branch: rain:Branch\n",
+	}
+
+	for _, c := range cases {
+		if _, err := tagpolicy.Parse([]byte(c)); err == nil {
+			t.Errorf("expected an error for %q", c)
+		}
+	}
+}
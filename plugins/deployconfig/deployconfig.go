@@ -7,6 +7,9 @@ import "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 type DeployConfig struct {
 	Params []types.Parameter
 	Tags   map[string]string
+
+	// StackPolicy is the JSON stack policy document from the config file, if it has one
+	StackPolicy string
 }
 
 // GetParam gets the value of a supplied parameter
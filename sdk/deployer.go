@@ -0,0 +1,214 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// eventDrainTimeout is how long Execute waits for the last of the stack's events
+// once the stack has settled
+const eventDrainTimeout = time.Minute
+
+// Deployer deploys stacks with change sets, in the same way as rain deploy
+type Deployer struct {
+	// Params are parameter values. Parameters that aren't set keep their current value,
+	// or use their default, and it is an error if a parameter has neither.
+	Params map[string]string
+
+	// Tags are applied to the stack and its resources
+	Tags map[string]string
+
+	// RoleArn is the IAM role that CloudFormation assumes to deploy the stack
+	RoleArn string
+
+	// Capabilities are acknowledged by the change set.
+	// If they are not set, CAPABILITY_NAMED_IAM and CAPABILITY_AUTO_EXPAND are acknowledged.
+	Capabilities []string
+
+	// NotificationArns are the SNS topics that the stack's events are sent to
+	NotificationArns []string
+
+	// DisableRollback leaves resources as they are if the deployment fails
+	DisableRollback bool
+
+	// StackEvents is called with each event of the stack, and of its nested stacks, while a change set is executed.
+	// It is not called after Execute returns.
+	StackEvents func(types.StackEvent)
+}
+
+// ChangeSet is a change set that is ready to execute
+type ChangeSet struct {
+	StackName string
+	Name      string
+
+	// Changes are the changes to the stack's resources
+	Changes []types.Change
+}
+
+// Result is the outcome of a deployment
+type Result struct {
+	StackName string
+	Status    string
+
+	// Outputs are the stack's outputs after the deployment
+	Outputs map[string]string
+}
+
+// Deploy creates a change set for the stack and executes it.
+// It returns ErrNoChanges if the template and parameters would not change the stack.
+func (d Deployer) Deploy(ctx context.Context, stackName string, t cft.Template) (*Result, error) {
+	cs, err := d.CreateChangeSet(ctx, stackName, t)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.Execute(ctx, cs)
+}
+
+// CreateChangeSet creates a change set for the stack, so that it can be reviewed before it is executed.
+// It returns ErrNoChanges if the template and parameters would not change the stack.
+func (d Deployer) CreateChangeSet(ctx context.Context, stackName string, t cft.Template) (cs *ChangeSet, err error) {
+	defer catch(&err)
+	useDefaultEvents()
+
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	stack, err := cfn.Region("").GetStackContext(ctx, stackName)
+	exists := err == nil
+	if exists {
+		switch status := stack.StackStatus; {
+		case status == types.StackStatusRollbackComplete, status == types.StackStatusCreateFailed:
+			return nil, fmt.Errorf("stack '%s' is %s and can't be updated; delete it and deploy again", stackName, status)
+		case status != types.StackStatusReviewInProgress && !cfn.StackHasSettled(stack):
+			return nil, fmt.Errorf("stack '%s' is %s; wait for it to finish before deploying", stackName, status)
+		}
+	}
+
+	params := dc.GetParameters(t, d.Params, stack.Parameters, exists, true, false)
+
+	console.Status(fmt.Sprintf("Creating change set for stack '%s'", stackName))
+	name, err := cfn.CreateChangeSetContext(ctx, t, params, d.Tags, stackName, "", cfn.ChangeSetOptions{
+		RoleArn:          d.RoleArn,
+		Capabilities:     d.Capabilities,
+		NotificationArns: d.NotificationArns,
+	})
+	if err != nil {
+		if cfn.ChangeSetHasNoChanges(err) {
+			cfn.DeleteChangeSetContext(ctx, stackName, name)
+			return nil, ErrNoChanges
+		}
+
+		return nil, fmt.Errorf("unable to create a change set for stack '%s': %w", stackName, err)
+	}
+
+	full, err := cfn.GetFullChangeSetContext(ctx, stackName, name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to describe change set '%s': %w", name, err)
+	}
+
+	return &ChangeSet{
+		StackName: stackName,
+		Name:      name,
+		Changes:   full.Changes,
+	}, nil
+}
+
+// Execute executes a change set and waits for the stack to settle.
+// If ctx is cancelled, Execute stops waiting and returns ctx's error,
+// but the deployment carries on.
+func (d Deployer) Execute(ctx context.Context, cs *ChangeSet) (result *Result, err error) {
+	defer catch(&err)
+	useDefaultEvents()
+
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// settled is set once the stack has finished deploying
+	settled := false
+
+	if d.StackEvents != nil {
+		events, err := cfn.StreamStackEvents(ctx, cs.StackName)
+		if err != nil {
+			return nil, fmt.Errorf("unable to watch the events of stack '%s': %w", cs.StackName, err)
+		}
+
+		forwarded := make(chan struct{})
+		go func() {
+			defer close(forwarded)
+			for e := range events {
+				d.StackEvents(e)
+			}
+		}()
+
+		// Don't return while StackEvents can still be called.
+		// The stream ends by itself once it sees the stack settle,
+		// so it is only stopped early if the deployment didn't get that far.
+		defer func() {
+			if settled {
+				timer := time.AfterFunc(eventDrainTimeout, cancel)
+				defer timer.Stop()
+			} else {
+				cancel()
+			}
+
+			<-forwarded
+		}()
+	}
+
+	console.Status(fmt.Sprintf("Executing change set '%s'", cs.Name))
+	if err := cfn.ExecuteChangeSetContext(ctx, cs.StackName, cs.Name, d.DisableRollback); err != nil {
+		return nil, fmt.Errorf("unable to execute change set '%s': %w", cs.Name, err)
+	}
+
+	// Each change of status is reported as progress
+	last := ""
+	stack, err := cfn.WaitForStack(ctx, "", cs.StackName, func(stack types.Stack) {
+		if status := string(stack.StackStatus); status != last {
+			console.Status(fmt.Sprintf("Stack %s: %s", cs.StackName, status))
+			last = status
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	settled = true
+
+	result = &Result{
+		StackName: cs.StackName,
+		Status:    string(stack.StackStatus),
+		Outputs:   make(map[string]string),
+	}
+
+	if stack.StackStatus != types.StackStatusCreateComplete && stack.StackStatus != types.StackStatusUpdateComplete {
+		return result, fmt.Errorf("stack '%s' failed to deploy: %s", cs.StackName, stack.StackStatus)
+	}
+
+	for _, o := range stack.Outputs {
+		result.Outputs[ptr.ToString(o.OutputKey)] = ptr.ToString(o.OutputValue)
+	}
+
+	return result, nil
+}
+
+// DeleteChangeSet deletes a change set that isn't going to be executed
+func (d Deployer) DeleteChangeSet(ctx context.Context, cs *ChangeSet) error {
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	return cfn.DeleteChangeSetContext(ctx, cs.StackName, cs.Name)
+}
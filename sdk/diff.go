@@ -0,0 +1,40 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/diff"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+)
+
+// Diff compares two templates. The result's Mode is diff.Unchanged if they are the same,
+// and its String method describes the differences in the same way as rain diff.
+func Diff(ctx context.Context, old, new cft.Template) (diff.Diff, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return diff.New(old, new), nil
+}
+
+// DiffStack compares the template of a deployed stack with t
+func DiffStack(ctx context.Context, stackName string, t cft.Template) (diff.Diff, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	body, err := cfn.GetStackTemplate(stackName, false)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the template of stack '%s': %w", stackName, err)
+	}
+
+	deployed, err := parse.String(body)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the template of stack '%s': %w", stackName, err)
+	}
+
+	return diff.New(deployed, t), nil
+}
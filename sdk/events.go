@@ -0,0 +1,54 @@
+package sdk
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+)
+
+// Events receives the progress and output of long-running operations:
+// creating and executing change sets, waiting for stacks to settle,
+// and stack set operations. Implement it to show progress in your own UI.
+type Events = console.Events
+
+var defaultEvents sync.Once
+
+// SetEvents sends the progress and output of every operation to e.
+// Until it is called, operations report nothing, and passing nil stops reporting.
+func SetEvents(e Events) {
+	defaultEvents.Do(func() {})
+
+	if e == nil {
+		e = console.Discard
+	}
+
+	console.SetEvents(e)
+}
+
+// useDefaultEvents stops operations from drawing on the terminal of the program
+// that embeds rain if it hasn't called SetEvents
+func useDefaultEvents() {
+	defaultEvents.Do(func() {
+		if !console.HasEvents() {
+			console.SetEvents(console.Discard)
+		}
+	})
+}
+
+// StackEvents returns a channel that receives the events of the named stack,
+// and of its nested stacks, from now until the stack settles.
+// Call it before executing a change set so that none of its events are missed.
+// The channel is closed when the stack settles or ctx is cancelled.
+func StackEvents(ctx context.Context, stackName string) (events <-chan types.StackEvent, err error) {
+	defer catch(&err)
+	useDefaultEvents()
+
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	return cfn.StreamStackEvents(ctx, stackName)
+}
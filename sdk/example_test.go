@@ -0,0 +1,34 @@
+package sdk_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/sdk"
+)
+
+// actions reports progress as GitHub Actions workflow commands
+type actions struct{}
+
+func (actions) Progress(message string) {
+	fmt.Printf("::notice::%s\n", message)
+}
+
+func (actions) Log(line string) {
+	fmt.Println(line)
+}
+
+func (actions) Prompt(prompt string, defaultValue string, secret bool) (string, error) {
+	return "", errors.New("a workflow can't answer " + prompt)
+}
+
+func (actions) Confirm(prompt string, defaultYes bool) (bool, error) {
+	return false, errors.New("a workflow can't confirm " + prompt)
+}
+
+func ExampleSetEvents() {
+	sdk.SetEvents(actions{})
+	defer sdk.SetEvents(nil)
+
+	// Deployments now report their progress as workflow notices
+}
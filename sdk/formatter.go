@@ -0,0 +1,98 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/humanize"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/cft/rewrite"
+)
+
+// Formatter formats templates in the same way as rain fmt
+type Formatter struct {
+	// JSON formats templates as JSON instead of YAML
+	JSON bool
+
+	// Unsorted keeps the order of the template's elements instead of using rain's canonical order
+	Unsorted bool
+
+	// Humanize removes tool-specific metadata and simplifies expressions
+	// to make machine-generated templates easier to review
+	Humanize bool
+
+	// StringStyle rewrites Fn::Join and Fn::Sub expressions to use one style: "sub" or "join"
+	StringStyle string
+}
+
+// FormatResult is a formatted template
+type FormatResult struct {
+	Output string
+
+	// Formatted is true if the input was already formatted
+	Formatted bool
+
+	// Warnings describe values that YAML 1.1 reads differently from how they look,
+	// which the output writes unambiguously
+	Warnings []string
+}
+
+// Format parses and formats the template in input
+func (f Formatter) Format(ctx context.Context, input string) (FormatResult, error) {
+	if err := checkContext(ctx); err != nil {
+		return FormatResult{}, err
+	}
+
+	source, err := parse.String(input)
+	if err != nil {
+		return FormatResult{}, fmt.Errorf("unable to parse input: %w", err)
+	}
+
+	warnings, err := f.Rewrite(source)
+	if err != nil {
+		return FormatResult{}, err
+	}
+
+	output := format.String(source, format.Options{
+		JSON:     f.JSON,
+		Unsorted: f.Unsorted,
+	})
+
+	// Verify the output is valid
+	if err := parse.Verify(source, output); err != nil {
+		return FormatResult{}, err
+	}
+
+	return FormatResult{
+		Output:    output,
+		Formatted: strings.TrimSpace(input) == strings.TrimSpace(output),
+		Warnings:  warnings,
+	}, nil
+}
+
+// Rewrite makes the changes to t that the formatter's settings ask for, before it is written out,
+// and returns warnings about values that YAML 1.1 reads differently from how they look
+func (f Formatter) Rewrite(t cft.Template) ([]string, error) {
+	if f.Humanize {
+		humanize.Template(t)
+	}
+
+	warnings := make([]string, 0)
+	for _, p := range format.NormalizeScalars(t) {
+		warnings = append(warnings, fmt.Sprint(p))
+	}
+
+	if f.StringStyle != "" {
+		style, err := rewrite.ParseStyle(f.StringStyle)
+		if err != nil {
+			return warnings, err
+		}
+
+		rewrite.Template(t, style)
+	}
+
+	return warnings, nil
+}
@@ -0,0 +1,69 @@
+package sdk_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/sdk"
+)
+
+func TestFormatter(t *testing.T) {
+	input := `Resources:
+  Bucket:
+    Properties:
+      BucketName: !Join ["-", [!Ref AWS::StackName, logs]]
+      VersioningConfiguration: {Status: Enabled}
+    Type: AWS::S3::Bucket
+Outputs:
+  Enabled:
+    Value: yes
+`
+
+	result, err := sdk.Formatter{StringStyle: "sub"}.Format(context.Background(), input)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Formatted {
+		t.Error("expected the input to need formatting")
+	}
+
+	if !strings.Contains(result.Output, "BucketName: !Sub ${AWS::StackName}-logs") {
+		t.Errorf("expected Fn::Join to be rewritten as Fn::Sub:\n%s", result.Output)
+	}
+
+	if strings.Index(result.Output, "Type:") > strings.Index(result.Output, "Properties:") {
+		t.Errorf("expected Type before Properties:\n%s", result.Output)
+	}
+
+	if len(result.Warnings) != 1 {
+		t.Errorf("expected a warning about 'yes', got %v", result.Warnings)
+	}
+
+	again, err := sdk.Formatter{}.Format(context.Background(), result.Output)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !again.Formatted {
+		t.Errorf("expected formatted output to stay the same:\n%s", again.Output)
+	}
+}
+
+func TestFormatterErrors(t *testing.T) {
+	if _, err := (sdk.Formatter{}).Format(context.Background(), "Resources: ["); err == nil {
+		t.Error("expected an error for an invalid template")
+	}
+
+	if _, err := (sdk.Formatter{StringStyle: "concat"}).Format(context.Background(), "Resources: {}"); err == nil {
+		t.Error("expected an error for an unknown string style")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := (sdk.Formatter{}).Format(ctx, "Resources: {}"); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
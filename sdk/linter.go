@@ -0,0 +1,163 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/exports"
+	"github.com/aws-cloudformation/rain/cft/format"
+	"github.com/aws-cloudformation/rain/cft/langext"
+	"github.com/aws-cloudformation/rain/cft/lint"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+)
+
+// Linter checks templates against rain's lint rules, in the same way as rain lint.
+// Custom rules that are registered with lint.LoadRules or lint.Register are run too.
+type Linter struct {
+	// StackName is the name the template will be deployed as, if known
+	StackName string
+
+	// Exports is the export naming convention, if there is one
+	Exports *exports.Convention
+
+	// Schemas checks resource properties against the types' registry schemas,
+	// which are read from the registry if they aren't cached
+	Schemas bool
+
+	// ExpandForEach expands Fn::ForEach loops before linting, using Params
+	ExpandForEach bool
+
+	// Transform lints the resources that the template's transforms create.
+	// The transforms are run by creating a change set for a temporary stack, using Params.
+	Transform bool
+
+	// Params are the parameter values for ExpandForEach and Transform
+	Params map[string]string
+
+	// External are the names of external linters to run, such as cfn-lint,
+	// whose findings are merged with the rules' findings.
+	// See lint.ExternalLinters for the linters that can be run.
+	External []string
+}
+
+// Lint runs the lint rules against t and returns the findings.
+// Use lint.HasErrors to find out whether any of them are errors.
+// External linters are run on a copy of t that is written to a temporary file,
+// so use LintFile to have their findings refer to the lines of a template file.
+func (l Linter) Lint(ctx context.Context, t cft.Template) (findings []lint.Finding, err error) {
+	return l.lint(ctx, t, "")
+}
+
+// lint lints t, running external linters on the template in path,
+// or on a temporary copy of t if path is empty
+func (l Linter) lint(ctx context.Context, t cft.Template, path string) (findings []lint.Finding, err error) {
+	defer catch(&err)
+
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	if l.ExpandForEach && !l.Transform {
+		t, err = langext.Expand(t, l.Params)
+		if err != nil {
+			return nil, fmt.Errorf("unable to expand Fn::ForEach: %w", err)
+		}
+	}
+
+	if l.Transform {
+		t, err = cfn.TransformTemplate(t, l.Params)
+		if err != nil {
+			return nil, fmt.Errorf("unable to transform template: %w", err)
+		}
+	}
+
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	opts := l.Options()
+
+	if len(l.External) > 0 {
+		if path == "" {
+			path, err = writeTemp(t)
+			if err != nil {
+				return nil, err
+			}
+			defer os.Remove(path)
+		}
+
+		if opts.External, err = l.external(ctx, path); err != nil {
+			return nil, err
+		}
+	}
+
+	return lint.Template(t, opts), nil
+}
+
+// external runs the external linters on the template in path and returns their findings
+func (l Linter) external(ctx context.Context, path string) ([]lint.Finding, error) {
+	findings := make([]lint.Finding, 0)
+
+	for _, name := range l.External {
+		linter, err := lint.FindExternalLinter(name)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := linter.Run(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+
+		findings = append(findings, f...)
+	}
+
+	return findings, nil
+}
+
+// writeTemp writes t to a temporary file for external linters to read.
+// The caller must remove the file.
+func writeTemp(t cft.Template) (string, error) {
+	f, err := os.CreateTemp("", "rain-lint-*.yaml")
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(format.String(t, format.Options{})); err != nil {
+		os.Remove(f.Name())
+		return "", err
+	}
+
+	return f.Name(), nil
+}
+
+// LintFile parses the template in the file and lints it.
+// External linters check the file as it is written, even if the template is expanded or transformed.
+func (l Linter) LintFile(ctx context.Context, path string) ([]lint.Finding, error) {
+	t, err := parse.File(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse template '%s': %w", path, err)
+	}
+
+	return l.lint(ctx, t, path)
+}
+
+// Options returns the options that the lint rules are run with
+func (l Linter) Options() lint.Options {
+	opts := lint.Options{
+		StackName: l.StackName,
+		Exports:   l.Exports,
+	}
+
+	if l.Schemas {
+		opts.Schema = func(typeName string) (string, error) {
+			return cfn.GetTypeSchema(typeName, false)
+		}
+	}
+
+	return opts
+}
@@ -0,0 +1,37 @@
+package sdk_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws-cloudformation/rain/cft/lint"
+	"github.com/aws-cloudformation/rain/cft/parse"
+	"github.com/aws-cloudformation/rain/sdk"
+)
+
+func TestLinter(t *testing.T) {
+	tmpl, err := parse.String(`
+Resources:
+  Vpc:
+    Type: AWS::EC2::VPC
+    Properties:
+      CidrBlock: 10.0.0.0/16
+  Subnet:
+    Type: AWS::EC2::Subnet
+    Properties:
+      VpcId: !Ref Vpc
+      CidrBlock: 10.1.0.0/24
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings, err := sdk.Linter{}.Lint(context.Background(), tmpl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !lint.HasErrors(findings) {
+		t.Errorf("expected an error for a subnet outside its VPC, got %v", findings)
+	}
+}
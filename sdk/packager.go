@@ -0,0 +1,53 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/cft/pkg"
+	"github.com/aws-cloudformation/rain/internal/aws/s3"
+)
+
+// Packager packages templates in the same way as rain pkg:
+// local artifacts are uploaded to rain's artifact bucket and rain's directives are run
+type Packager struct {
+	// CreateBucket creates rain's artifact bucket, rain-artifacts-<account id>-<region>,
+	// if it doesn't exist. Without it, Package returns an error if the bucket doesn't exist.
+	CreateBucket bool
+}
+
+// Package packages the template in the file at path
+func (p Packager) Package(ctx context.Context, path string) (t cft.Template, err error) {
+	defer catch(&err)
+	useDefaultEvents()
+
+	if err := checkContext(ctx); err != nil {
+		return t, err
+	}
+
+	if p.CreateBucket {
+		s3.RainBucket(true)
+	} else {
+		bucket, err := s3.RainBucketName()
+		if err != nil {
+			return t, err
+		}
+
+		exists, err := s3.BucketExists(bucket)
+		if err != nil {
+			return t, fmt.Errorf("unable to confirm whether artifact bucket '%s' exists: %w", bucket, err)
+		}
+
+		if !exists {
+			return t, fmt.Errorf("artifact bucket '%s' does not exist; set CreateBucket or run rain bootstrap to create it", bucket)
+		}
+	}
+
+	t, err = pkg.File(path)
+	if err != nil {
+		return t, fmt.Errorf("unable to package template '%s': %w", path, err)
+	}
+
+	return t, nil
+}
@@ -0,0 +1,53 @@
+// Package sdk makes rain's core operations available to other Go programs:
+// formatting and linting templates, comparing them, packaging them,
+// and deploying stacks and stack sets.
+//
+// The types in this package don't print anything, ask for input, or exit the program.
+// Problems are returned as errors, and progress is reported to the Events set with SetEvents.
+// AWS calls use the same configuration as rain: the default credential chain,
+// or the profile and region set with the AWS_PROFILE and AWS_REGION environment variables.
+//
+//	t, err := sdk.Packager{}.Package(ctx, "template.yaml")
+//	if err != nil {
+//		return err
+//	}
+//
+//	result, err := sdk.Deployer{Params: map[string]string{"Env": "dev"}}.Deploy(ctx, "my-stack", t)
+//	if errors.Is(err, sdk.ErrNoChanges) {
+//		return nil
+//	}
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoChanges is returned when a deployment would not change the stack
+var ErrNoChanges = errors.New("no changes to deploy")
+
+// catch turns a panic in one of rain's internal packages into an error,
+// so that it reaches the caller instead of stopping the program
+func catch(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+
+	if e, ok := r.(error); ok {
+		*err = e
+	} else {
+		*err = fmt.Errorf("%v", r)
+	}
+}
+
+// checkContext returns the context's error if it has been cancelled
+func checkContext(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,306 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws-cloudformation/rain/cft"
+	"github.com/aws-cloudformation/rain/internal/aws/cfn"
+	"github.com/aws-cloudformation/rain/internal/console"
+	"github.com/aws-cloudformation/rain/internal/dc"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+)
+
+// StackSets manages stack sets and their instances, in the same way as rain stackset
+type StackSets struct {
+	// DelegatedAdmin calls as a delegated administrator for StackSets in the organization's member account
+	DelegatedAdmin bool
+
+	// Preferences control how operations roll out across accounts and regions
+	Preferences *types.StackSetOperationPreferences
+}
+
+// StackSetInput describes a stack set and where its instances are
+type StackSetInput struct {
+	Name        string
+	Template    cft.Template
+	Description string
+
+	// Params are parameter values. Parameters that aren't set keep their current value,
+	// or use their default, and it is an error if a parameter has neither.
+	Params map[string]string
+
+	Tags map[string]string
+
+	// PermissionModel is SELF_MANAGED, the default, or SERVICE_MANAGED
+	PermissionModel types.PermissionModels
+
+	// AutoDeployment deploys service-managed stack sets to accounts that are added to their organizational units
+	AutoDeployment *types.AutoDeployment
+
+	// Capabilities are acknowledged by the stack set
+	Capabilities []types.Capability
+
+	// Accounts or OrganizationalUnits get instances in each of Regions.
+	// Instances that already exist are updated; instances that are not listed are left as they are.
+	Accounts            []string
+	OrganizationalUnits []string
+	Regions             []string
+}
+
+// Deploy creates the stack set, or updates it if it exists, and creates any instances that it doesn't have yet
+func (s StackSets) Deploy(ctx context.Context, in StackSetInput) (err error) {
+	defer catch(&err)
+	useDefaultEvents()
+
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	existing, err := cfn.GetStackSet(in.Name, s.DelegatedAdmin)
+	exists := err == nil && existing.Status != types.StackSetStatusDeleted
+
+	var old []types.Parameter
+	if exists {
+		old = existing.Parameters
+	}
+
+	conf := cfn.StackSetConfig{
+		StackSetName:    in.Name,
+		Template:        in.Template,
+		Parameters:      dc.GetParameters(in.Template, in.Params, old, exists, true, false),
+		Tags:            dc.MakeTags(in.Tags),
+		AutoDeployment:  in.AutoDeployment,
+		Capabilities:    in.Capabilities,
+		PermissionModel: in.PermissionModel,
+		CallAs:          s.callAs(),
+	}
+	if in.Description != "" {
+		conf.Description = ptr.String(in.Description)
+	}
+
+	instances := cfn.StackSetInstancesConfig{
+		StackSetName:         in.Name,
+		Accounts:             in.Accounts,
+		Regions:              in.Regions,
+		OperationPreferences: s.Preferences,
+		CallAs:               s.callAs(),
+	}
+	if len(in.OrganizationalUnits) > 0 {
+		instances.Accounts = nil
+		instances.DeploymentTargets = &types.DeploymentTargets{
+			OrganizationalUnitIds: in.OrganizationalUnits,
+		}
+	}
+
+	hasTargets := len(instances.Regions) > 0 && (len(instances.Accounts) > 0 || instances.DeploymentTargets != nil)
+
+	if !exists {
+		if _, err := cfn.CreateStackSet(conf); err != nil {
+			return fmt.Errorf("unable to create stack set '%s': %w", in.Name, err)
+		}
+	} else {
+		// Update the existing instances, which are all of them unless targets are listed
+		update := cfn.StackSetInstancesConfig{OperationPreferences: s.Preferences}
+		if hasTargets {
+			update, err = s.existingTargets(in.Name, instances)
+			if err != nil {
+				return err
+			}
+		}
+
+		operationId, err := cfn.StartUpdateStackSet(conf, update)
+		if err != nil {
+			return fmt.Errorf("unable to update stack set '%s': %w", in.Name, err)
+		}
+
+		if err := s.wait(ctx, in.Name, operationId); err != nil {
+			return err
+		}
+	}
+
+	if !hasTargets {
+		return nil
+	}
+
+	missing, err := s.missingTargets(in.Name, instances, exists)
+	if err != nil || len(missing.Regions) == 0 {
+		return err
+	}
+
+	operationId, err := cfn.StartCreateStackSetInstances(missing)
+	if err != nil {
+		return fmt.Errorf("unable to create instances of stack set '%s': %w", in.Name, err)
+	}
+
+	return s.wait(ctx, in.Name, operationId)
+}
+
+// Delete deletes every instance of the stack set, and then the stack set.
+// If retainStacks is set, the instances' stacks are kept.
+func (s StackSets) Delete(ctx context.Context, name string, retainStacks bool) (err error) {
+	defer catch(&err)
+	useDefaultEvents()
+
+	if err := checkContext(ctx); err != nil {
+		return err
+	}
+
+	instances, err := s.Instances(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if len(instances) > 0 {
+		accounts := make([]string, 0)
+		ous := make([]string, 0)
+		regions := make([]string, 0)
+		for _, i := range instances {
+			accounts = append(accounts, ptr.ToString(i.Account))
+			if ou := ptr.ToString(i.OrganizationalUnitId); ou != "" {
+				ous = append(ous, ou)
+			}
+			regions = append(regions, ptr.ToString(i.Region))
+		}
+
+		var operationId string
+		if len(ous) > 0 {
+			operationId, err = cfn.StartDeleteStackSetInstancesFromOUs(name, ous, regions, s.Preferences, retainStacks, s.DelegatedAdmin)
+		} else {
+			operationId, err = cfn.StartDeleteStackSetInstances(name, accounts, regions, s.Preferences, retainStacks, s.DelegatedAdmin)
+		}
+		if err != nil {
+			return fmt.Errorf("unable to delete the instances of stack set '%s': %w", name, err)
+		}
+
+		if err := s.wait(ctx, name, operationId); err != nil {
+			return err
+		}
+	}
+
+	if err := cfn.DeleteStackSet(name, s.DelegatedAdmin); err != nil {
+		return fmt.Errorf("unable to delete stack set '%s': %w", name, err)
+	}
+
+	return nil
+}
+
+// Instances returns the stack set's instances
+func (s StackSets) Instances(ctx context.Context, name string) ([]types.StackInstanceSummary, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, err
+	}
+
+	instances, err := cfn.ListStackSetInstances(name, s.DelegatedAdmin)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the instances of stack set '%s': %w", name, err)
+	}
+
+	return instances, nil
+}
+
+func (s StackSets) callAs() types.CallAs {
+	if s.DelegatedAdmin {
+		return types.CallAsDelegatedAdmin
+	}
+
+	return types.CallAsSelf
+}
+
+// existingTargets limits the targets to the accounts and regions that already have instances,
+// as updating a stack set can't create instances
+func (s StackSets) existingTargets(name string, targets cfn.StackSetInstancesConfig) (cfn.StackSetInstancesConfig, error) {
+	instances, err := cfn.ListStackSetInstances(name, s.DelegatedAdmin)
+	if err != nil {
+		return targets, fmt.Errorf("unable to list the instances of stack set '%s': %w", name, err)
+	}
+
+	deployed := make(map[string]bool)
+	for _, i := range instances {
+		deployed[ptr.ToString(i.Region)] = true
+	}
+
+	regions := make([]string, 0)
+	for _, r := range targets.Regions {
+		if deployed[r] {
+			regions = append(regions, r)
+		}
+	}
+
+	targets.Regions = regions
+	if len(regions) == 0 {
+		// Nothing listed exists yet, so only the stack set itself is updated
+		return cfn.StackSetInstancesConfig{OperationPreferences: s.Preferences}, nil
+	}
+
+	return targets, nil
+}
+
+// missingTargets limits the targets to the regions that don't have instances yet.
+// As with rain stackset deploy, instances are created in every listed account in those regions.
+func (s StackSets) missingTargets(name string, targets cfn.StackSetInstancesConfig, exists bool) (cfn.StackSetInstancesConfig, error) {
+	if !exists {
+		return targets, nil
+	}
+
+	instances, err := cfn.ListStackSetInstances(name, s.DelegatedAdmin)
+	if err != nil {
+		return targets, fmt.Errorf("unable to list the instances of stack set '%s': %w", name, err)
+	}
+
+	deployed := make(map[string]bool)
+	for _, i := range instances {
+		deployed[ptr.ToString(i.Region)] = true
+	}
+
+	regions := make([]string, 0)
+	for _, r := range targets.Regions {
+		if !deployed[r] {
+			regions = append(regions, r)
+		}
+	}
+
+	targets.Regions = regions
+
+	return targets, nil
+}
+
+// wait polls a stack set operation until it finishes.
+// It returns an error that lists the failed instances if the operation failed or was stopped.
+func (s StackSets) wait(ctx context.Context, name string, operationId string) error {
+	for {
+		operation, err := cfn.GetStackSetOperation(name, operationId, s.DelegatedAdmin)
+		if err != nil {
+			return fmt.Errorf("unable to get stack set operation '%s': %w", operationId, err)
+		}
+
+		// The results only add detail, so the operation can still be waited for without them
+		results, _ := cfn.ListStackSetOperationResults(name, operationId)
+		if len(results) > 0 {
+			console.Status(fmt.Sprintf("Stack set %s: %s", name, cfn.SummarizeOperationResults(results)))
+		}
+
+		switch operation.Status {
+		case types.StackSetOperationStatusSucceeded:
+			return nil
+		case types.StackSetOperationStatusFailed, types.StackSetOperationStatusStopped:
+			failed := cfn.FailedOperationResults(results)
+			msg := fmt.Sprintf("stack set operation '%s' %s with %d failed instances",
+				operationId, strings.ToLower(string(operation.Status)), len(failed))
+			if len(failed) > 0 {
+				msg += ":\n  " + strings.Join(failed, "\n  ")
+			}
+			return errors.New(msg)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Second * cfn.WaitPeriodInSeconds):
+		}
+	}
+}